@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/app"
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"gorm.io/gorm"
+)
+
+const (
+	backupUsage  = "usage: server backup [--upload] [file|-]"
+	restoreUsage = "usage: server restore --yes [--from-storage] file|key"
+
+	// backupPrefix is where uploaded backups are kept in the storage backend
+	backupPrefix = "backups/"
+)
+
+// runBackup handles the "backup" subcommand: it dumps the database to a
+// file (a timestamped name by default, "-" for stdout) and with --upload
+// also stores it in the storage backend under backups/
+func runBackup(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	upload := fs.Bool("upload", false, "also store the backup in the storage backend under "+backupPrefix)
+	if err := fs.Parse(args); err != nil || fs.NArg() > 1 {
+		return errors.New(backupUsage)
+	}
+
+	name := fs.Arg(0)
+	if name == "" {
+		name = fmt.Sprintf("backup-%s-%s%s", cfg.Database.DBName, time.Now().UTC().Format("20060102T150405Z"), database.BackupExtension(cfg.Database.Driver))
+	}
+	if name == "-" && *upload {
+		return errors.New("--upload needs a backup file, not stdout")
+	}
+
+	ctx := context.Background()
+	if name == "-" {
+		return database.Backup(ctx, db, &cfg.Database, os.Stdout)
+	}
+
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := database.Backup(ctx, db, &cfg.Database, f); err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	slog.Info("Wrote backup", "file", name)
+
+	if !*upload {
+		return nil
+	}
+
+	fileStorage, _, err := app.NewStorage(&cfg.Storage)
+	if err != nil {
+		return err
+	}
+	f, err = os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	key := backupPrefix + filepath.Base(name)
+	if err := fileStorage.Put(ctx, key, f, "application/octet-stream"); err != nil {
+		return fmt.Errorf("failed to upload backup: %w", err)
+	}
+	slog.Info("Uploaded backup", "storage", cfg.Storage.Driver, "key", key)
+	return nil
+}
+
+// runRestore handles the "restore" subcommand: it replaces the database's
+// contents with a backup file or, with --from-storage, an uploaded backup.
+// --yes confirms that the current data may be overwritten.
+func runRestore(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fromStorage := fs.Bool("from-storage", false, "read the backup from the storage backend; the key may omit "+backupPrefix)
+	confirmed := fs.Bool("yes", false, "confirm that the current data is overwritten")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errors.New(restoreUsage)
+	}
+	if !*confirmed {
+		return fmt.Errorf("restore overwrites the current %s database; rerun with --yes", cfg.Database.Driver)
+	}
+
+	ctx := context.Background()
+	var r io.ReadCloser
+	if *fromStorage {
+		fileStorage, _, err := app.NewStorage(&cfg.Storage)
+		if err != nil {
+			return err
+		}
+		key := fs.Arg(0)
+		if path.Dir(key) == "." {
+			key = backupPrefix + key
+		}
+		if r, err = fileStorage.Get(ctx, key); err != nil {
+			return fmt.Errorf("failed to download backup: %w", err)
+		}
+	} else {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		r = f
+	}
+	defer r.Close()
+
+	if err := database.Restore(ctx, db, &cfg.Database, r); err != nil {
+		return err
+	}
+	slog.Info("Restored database", "driver", cfg.Database.Driver, "source", fs.Arg(0))
+	return nil
+}
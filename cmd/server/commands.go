@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/app"
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webpush"
+	"gorm.io/gorm"
+)
+
+// command is a subcommand of the server binary
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+// cli is what every command runs with: the loaded configuration and the
+// global flags
+type cli struct {
+	cfg         *config.Config
+	storageMode string
+}
+
+// commands are the subcommands, listed in this order by "server help"
+var commands = []command{
+	{"serve", "", "run the API (the default command)", runServe},
+	{"migrate", "up|down|status [tenant...]", "apply, roll back or list database migrations", withDB(func(db *gorm.DB, cfg *config.Config, args []string) error {
+		return runMigrate(db, &cfg.Database, args)
+	})},
+	{"seed", "[--file seed.yaml | --fake --count N]", "load sample data: built-in development accounts, a YAML or JSON file, or N fake users", withDB(runSeed)},
+	{"user", "create [--admin] --name NAME --email EMAIL [--password PASSWORD]", "manage user accounts", withDB(runUser)},
+	{"backup", "[--upload] [file|-]", "dump the database to a file", withDB(runBackup)},
+	{"restore", "--yes [--from-storage] file|key", "replace the database's contents with a backup", withDB(runRestore)},
+	{"routes", "", "print the HTTP route table", runRoutes},
+	{"vapid-keys", "", "generate a key pair for PUSH_VAPID_PUBLIC_KEY and PUSH_VAPID_PRIVATE_KEY", runVAPIDKeys},
+}
+
+// findCommand returns the command called name
+func findCommand(name string) (*command, bool) {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i], true
+		}
+	}
+	return nil, false
+}
+
+// usage prints how to run the server binary
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: server [flags] [command] [args]\n\nCommands:\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nFlags (before the command):\n")
+	flag.PrintDefaults()
+}
+
+// withDB adapts a command that works on the database, connecting to it first
+func withDB(run func(db *gorm.DB, cfg *config.Config, args []string) error) func(*cli, []string) error {
+	return func(c *cli, args []string) error {
+		if c.storageMode != storageDatabase {
+			return fmt.Errorf("command needs --storage=%s", storageDatabase)
+		}
+		// Records are written as the server would write them
+		if err := app.Configure(c.cfg); err != nil {
+			return err
+		}
+
+		// Wait for the database to come up (e.g. in docker compose)
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		db, err := database.ConnectWithRetry(ctx, &c.cfg.Database)
+		stop()
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return run(db, c.cfg, args)
+	}
+}
+
+// appOptions returns the options building the app with the selected storage
+func (c *cli) appOptions() ([]app.Option, error) {
+	switch c.storageMode {
+	case storageDatabase:
+		return nil, nil
+	case storageMemory:
+		return []app.Option{app.WithMemoryStorage()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported --storage %q, want %s or %s", c.storageMode, storageDatabase, storageMemory)
+	}
+}
+
+// runServe handles the "serve" command: it runs the API until interrupted
+func runServe(c *cli, args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: server serve")
+	}
+	opts, err := c.appOptions()
+	if err != nil {
+		return err
+	}
+	application, err := app.New(c.cfg, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Serve until interrupted, then shut down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// A restart signal starts the binary anew, handing it the listeners,
+	// so a deploy drops no connections
+	if len(restartSignals) > 0 {
+		restarts := make(chan os.Signal, 1)
+		signal.Notify(restarts, restartSignals...)
+		defer signal.Stop(restarts)
+		go func() {
+			for range restarts {
+				if err := application.Restart(); err != nil {
+					slog.Error("Failed to restart", "error", err)
+				}
+			}
+		}()
+	}
+
+	if err := application.Run(ctx); err != nil {
+		return err
+	}
+	slog.Info("Server stopped")
+	return nil
+}
+
+// runRoutes handles the "routes" command: it prints the routes the API
+// serves with the current configuration. The app is built with in-memory
+// storage, so no database is needed.
+func runRoutes(c *cli, args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: server routes")
+	}
+	cfg := *c.cfg
+	// Tenants are resolved by middleware, which doesn't change the routes
+	cfg.Database.TenantMode = ""
+	application, err := app.New(&cfg, app.WithMemoryStorage())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LISTENER\tMETHODS\tPATH")
+	for _, route := range application.Routes() {
+		fmt.Fprintf(w, "public\t%s\t%s\n", strings.Join(route.Methods, ","), route.Path)
+	}
+	for _, route := range application.OpsRoutes() {
+		fmt.Fprintf(w, "ops\t%s\t%s\n", strings.Join(route.Methods, ","), route.Path)
+	}
+	return w.Flush()
+}
+
+// runVAPIDKeys handles the "vapid-keys" command: it prints a new VAPID key
+// pair for browser push notifications as environment variables
+func runVAPIDKeys(c *cli, args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: server vapid-keys")
+	}
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("PUSH_VAPID_PUBLIC_KEY=%s\nPUSH_VAPID_PRIVATE_KEY=%s\n", publicKey, privateKey)
+	return nil
+}
@@ -1,96 +1,135 @@
 package main
 
 import (
-	"log"
-	"net/http"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/config"
-	"github.com/aungmyozaw92/go-api-setup/internal/handler"
-	"github.com/aungmyozaw92/go-api-setup/internal/repository"
-	"github.com/aungmyozaw92/go-api-setup/internal/routes"
-	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
-	"github.com/aungmyozaw92/go-api-setup/internal/worker"
-	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+)
+
+// Storage modes selected with --storage
+const (
+	storageDatabase = "database"
+	storageMemory   = "memory"
 )
 
 func main() {
-	// Load configuration
-	config := config.Load()
-	log.Println("Configuration loaded successfully")
+	// Route every log record, including those of the standard log package,
+	// through a structured logger
+	slog.SetDefault(logger.New(os.Stderr, slog.LevelInfo, logger.FormatText))
 
-	// Connect to database
-	db, err := database.NewMySQLConnection(&config.Database)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
+	// Command-line flags override the environment and config file
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	// --storage=memory runs without a database, keeping data in memory only
+	storageMode := flag.String("storage", storageDatabase, `where data is kept: "database", or "memory" to run without a database (data is lost on exit)`)
+	flag.Usage = usage
+	flag.Parse()
 
-	// Run migrations
-	if err := database.AutoMigrate(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// The command comes after the flags; without one the API is served
+	name := flag.Arg(0)
+	switch name {
+	case "":
+		name = "serve"
+	case "help":
+		flag.CommandLine.SetOutput(os.Stdout)
+		usage()
+		return
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(flag.CommandLine.Output(), "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
 	}
 
-	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-
-	// APPROACH A: Simple Worker (current - good for small apps)
-	userMonitor := worker.NewUserMonitor(userRepo)
-	go userMonitor.StartUserCountMonitoring()
-
-	// APPROACH B: Manager Pattern (better for scalable apps)
-	// Uncomment below and comment above to use manager pattern:
-	/*
-	workerManager := worker.SetupDefaultWorkers(userRepo)
-	workerManager.StartAll()
-	
-	// Optional: Graceful shutdown handling
-	// defer workerManager.StopAll()
-	*/
+	overrides, err := configFlags.Overrides()
+	if err != nil {
+		fatal("Invalid command-line flags", "error", err)
+	}
 
-	// Initialize use cases
-	userUsecase := usecase.NewUserUsecase(userRepo, config.JWT.SecretKey)
+	// Load configuration
+	config := config.LoadWithOverrides(overrides)
+	if err := config.Validate(); err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
 
-	// Initialize handlers
-	authHandler := handler.NewAuthHandler(userUsecase)
-	userHandler := handler.NewUserHandler(userUsecase)
+	// LOG_LEVEL, LOG_FORMAT and LOG_FILE replace the bootstrap logger's defaults
+	logLevel, err := logger.ParseLevel(config.Logging.Level)
+	if err != nil {
+		fatal("Invalid LOG_LEVEL", "error", err)
+	}
+	logFormat, err := logger.ParseFormat(config.Logging.Format)
+	if err != nil {
+		fatal("Invalid LOG_FORMAT", "error", err)
+	}
+	var logOutput io.Writer = os.Stderr
+	if config.Logging.File != "" {
+		logFile := logger.NewFile(logger.FileConfig{
+			Path:       config.Logging.File,
+			MaxSizeMB:  config.Logging.FileMaxSizeMB,
+			MaxAgeDays: config.Logging.FileMaxAgeDays,
+			MaxBackups: config.Logging.FileMaxBackups,
+			Compress:   config.Logging.FileCompress,
+		})
+		defer logFile.Close()
+		logOutput = logFile
+	}
+	slog.SetDefault(logger.New(logOutput, logLevel, logFormat))
+	slog.Info("Configuration loaded successfully")
 
-	// Setup routes using the routes package
-	router := routes.SetupRoutes(authHandler, userHandler, config.JWT.SecretKey)
+	// Report panics and unexpected errors to Sentry when configured
+	if config.Sentry.DSN != "" {
+		reporter, err := reporting.NewSentry(reporting.SentryConfig{
+			DSN:         config.Sentry.DSN,
+			Environment: config.Sentry.Environment,
+			Release:     config.Sentry.Release,
+			SampleRate:  config.Sentry.SampleRate,
+		})
+		if err != nil {
+			fatal("Invalid SENTRY_DSN", "error", err)
+		}
+		reporting.SetDefault(reporter)
+		defer reporter.Flush(5 * time.Second)
+	}
 
-	// Log server information
-	logServerInfo(config.Server.Port)
+	// Post operational alerts to Slack or Discord when configured
+	if config.Alert.Enabled() {
+		source := config.Alert.Source
+		if source == "" {
+			host, _ := os.Hostname()
+			source = fmt.Sprintf("%s (%s)", host, config.Server.Environment)
+		}
+		notifier, err := alert.NewWebhook(alert.WebhookConfig{
+			URL:      config.Alert.WebhookURL,
+			Format:   alert.Format(config.Alert.Format),
+			Cooldown: config.Alert.Cooldown,
+			Source:   source,
+		})
+		if err != nil {
+			fatal("Invalid ALERT_WEBHOOK_URL", "error", err)
+		}
+		alert.SetDefault(notifier)
+		defer notifier.Flush(5 * time.Second)
+	}
 
-	// Start server
-	if err := http.ListenAndServe(":"+config.Server.Port, router); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	var args []string
+	if flag.NArg() > 0 {
+		args = flag.Args()[1:]
+	}
+	if err := cmd.run(&cli{cfg: config, storageMode: *storageMode}, args); err != nil {
+		fatal("Command failed", "command", cmd.name, "error", err)
 	}
 }
 
-// logServerInfo logs the server startup information and available endpoints
-func logServerInfo(port string) {
-	log.Printf("Server starting on port %s", port)
-	log.Printf("🚀 Go REST API Server")
-	log.Printf("📍 Available endpoints:")
-	log.Printf("")
-	log.Printf("🌐 General:")
-	log.Printf("  GET    /                    - API welcome message")
-	log.Printf("  GET    /health              - Health check")
-	log.Printf("")
-	log.Printf("🔐 Authentication (Public):")
-	log.Printf("  POST   /api/auth/register   - Register a new user")
-	log.Printf("  POST   /api/auth/login      - Login user")
-	log.Printf("")
-	log.Printf("👤 User Profile (Protected):")
-	log.Printf("  GET    /api/profile         - Get current user profile")
-	log.Printf("  PUT    /api/profile         - Update current user profile")
-	log.Printf("  DELETE /api/profile         - Delete current user account")
-	log.Printf("")
-	log.Printf("👥 User Management (Protected):")
-	log.Printf("  POST   /api/users           - Create a new user")
-	log.Printf("  GET    /api/users           - Get all users (with pagination)")
-	log.Printf("  GET    /api/users/{id}      - Get user by ID")
-	log.Printf("  PUT    /api/users/{id}      - Update user by ID")
-	log.Printf("  DELETE /api/users/{id}      - Delete user by ID")
-	log.Printf("")
-	log.Printf("📖 Documentation: https://github.com/aungmyozaw92/go-api-setup")
-	log.Printf("🎯 Ready to accept requests!")
-} 
\ No newline at end of file
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
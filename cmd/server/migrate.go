@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: server migrate up|down|status [tenant...]"
+
+// runMigrate handles the "migrate" subcommand: up applies pending migrations,
+// down rolls back the latest one, and status lists every migration. Naming
+// tenants runs the command against their databases instead of the shared one.
+func runMigrate(db *gorm.DB, cfg *config.DatabaseConfig, args []string) error {
+	if len(args) < 1 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx := context.Background()
+	command, tenantIDs := args[0], args[1:]
+	if len(tenantIDs) == 0 {
+		return migrateDatabase(ctx, db, cfg.Driver, command)
+	}
+
+	if cfg.TenantMode != database.TenantModeDatabase {
+		return errors.New("tenant migrations require DB_TENANT_MODE=database")
+	}
+
+	// The command decides what to apply, so don't auto-migrate on connect
+	poolCfg := *cfg
+	poolCfg.AutoMigrate = false
+	pool := database.NewTenantPool(&poolCfg)
+	defer pool.Close()
+
+	for _, tenantID := range tenantIDs {
+		tenantDB, err := pool.DB(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Tenant %s:\n", tenantID)
+		if err := migrateDatabase(ctx, tenantDB, cfg.Driver, command); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// migrateDatabase runs one migrate command against db
+func migrateDatabase(ctx context.Context, db *gorm.DB, driver, command string) error {
+	switch command {
+	case "up":
+		return database.MigrateUp(ctx, db, driver)
+	case "down":
+		return database.MigrateDown(ctx, db, driver)
+	case "status":
+		statuses, err := database.MigrationStatus(ctx, db, driver)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tMIGRATION")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q; %s", command, migrateUsage)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/internal/seed"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"gorm.io/gorm"
+)
+
+const seedUsage = "usage: server seed [--file seed.yaml | --fake [--count 1000] [--batch-size 500] [--password password] [--random]]"
+
+// fakeSeed makes --fake generate the same users every run, so seeding again
+// skips them rather than adding more
+const fakeSeed = 42
+
+// runSeed handles the "seed" command: it loads the built-in sample data, or
+// that of --file, skipping records that already exist. --fake generates
+// --count realistic users instead. The built-in and fake data have
+// well-known passwords, so production refuses them.
+func runSeed(db *gorm.DB, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := fs.String("file", "", "YAML or JSON file with the data to load (default: built-in development accounts)")
+	fake := fs.Bool("fake", false, "generate fake users, for demos and load tests")
+	count := fs.Int("count", 1000, "number of fake users to generate")
+	batchSize := fs.Int("batch-size", seed.DefaultFakeBatchSize, "number of fake users inserted at once")
+	password := fs.String("password", "password", "password of the fake users")
+	random := fs.Bool("random", false, "generate different fake users on every run")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 || (*fake && *file != "") {
+		return errors.New(seedUsage)
+	}
+	if *fake {
+		if cfg.IsProduction() {
+			return errors.New("fake users have a well-known password and are refused in production")
+		}
+		if *count <= 0 || *batchSize <= 0 {
+			return errors.New("--count and --batch-size must be greater than zero")
+		}
+	}
+
+	var data *seed.Data
+	switch {
+	case *fake:
+		// Generated once the schema is there
+	case *file == "":
+		if cfg.IsProduction() {
+			return errors.New("the built-in seed data has well-known passwords and is refused in production; pass --file")
+		}
+		data = seed.Default()
+	default:
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if data, err = seed.Parse(f); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	if cfg.Database.AutoMigrate {
+		if err := database.MigrateUp(ctx, db, cfg.Database.Driver); err != nil {
+			return err
+		}
+	}
+
+	users := repository.NewRepositories(db).Users
+	if *fake {
+		opts := seed.FakeOptions{
+			Password:  *password,
+			Seed:      fakeSeed,
+			BatchSize: *batchSize,
+			Progress: func(done int) {
+				slog.Info("Generating fake users", "done", done, "total", *count)
+			},
+		}
+		if *random {
+			opts.Seed = 0
+		}
+		result, err := seed.Fake(ctx, users, *count, opts)
+		if err != nil {
+			return err
+		}
+		slog.Info("Seeded fake users", "users_created", result.Created, "users_skipped", result.Skipped)
+		return nil
+	}
+
+	result, err := seed.Apply(ctx, users, data)
+	if err != nil {
+		return err
+	}
+	slog.Info("Seeded database", "users_created", result.Created, "users_skipped", result.Skipped)
+	return nil
+}
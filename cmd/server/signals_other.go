@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// restartSignals is empty where listeners cannot be handed to a new process
+var restartSignals []os.Signal
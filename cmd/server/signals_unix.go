@@ -0,0 +1,12 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartSignals make a serving process hand its listeners to a new one
+// (kill -USR2 <pid>)
+var restartSignals = []os.Signal{syscall.SIGUSR2}
@@ -3,10 +3,14 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"time"
 
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
 	"github.com/aungmyozaw92/go-api-setup/internal/repository/mocks"
 	"github.com/aungmyozaw92/go-api-setup/internal/worker"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,10 +23,10 @@ func testSimpleApproach() {
 	mockRepo.On("Count", mock.MatchedBy(func(ctx context.Context) bool { return true })).Return(int64(42), nil)
 	
 	// Simple approach - each worker started manually
-	userMonitor := worker.NewUserMonitor(mockRepo)
+	userMonitor := worker.NewUserMonitor(mockRepo, 10*time.Second)
 	go userMonitor.StartUserCountMonitoring()
 	
-	emailWorker := worker.NewEmailWorker()
+	emailWorker := worker.NewEmailWorker(memory.NewEmailRepository(), email.NewLogSender(slog.Default()), 30*time.Second, 4, 5, time.Minute)
 	go emailWorker.Start()
 	
 	log.Println("✅ Started workers manually")
@@ -43,7 +47,10 @@ func testManagerApproach() {
 	mockRepo.On("Count", mock.MatchedBy(func(ctx context.Context) bool { return true })).Return(int64(42), nil)
 	
 	// Manager approach - all workers managed centrally
-	workerManager := worker.SetupDefaultWorkers(mockRepo)
+	workerManager, err := worker.SetupDefaultWorkers(config.Load(), mockRepo, memory.NewEmailRepository(), memory.NewWebhookRepository(), email.NewLogSender(slog.Default()))
+	if err != nil {
+		log.Fatalf("Failed to set up workers: %v", err)
+	}
 	workerManager.StartAll()
 	
 	// Let it run for a bit
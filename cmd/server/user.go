@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/internal/seed"
+	"gorm.io/gorm"
+)
+
+const userUsage = "usage: server user create [--admin] --name NAME --email EMAIL [--password PASSWORD]"
+
+// runUser handles the "user" command. "user create" adds an account, which
+// is how the first admin is made; without --password the password is read
+// from standard input, keeping it out of the shell history.
+func runUser(db *gorm.DB, cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return errors.New(userUsage)
+	}
+
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	admin := fs.Bool("admin", false, "give the user the admin role")
+	name := fs.String("name", "", "display name")
+	email := fs.String("email", "", "email address to log in with")
+	password := fs.String("password", "", "password (default: read from standard input)")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		return errors.New(userUsage)
+	}
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	user := seed.User{Name: *name, Email: *email, Password: *password, Role: domain.RoleUser}
+	if *admin {
+		user.Role = domain.RoleAdmin
+	}
+	if err := user.Validate(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	users := repository.NewRepositories(db).Users
+	existing, err := users.GetByEmail(ctx, user.Email)
+	if err != nil {
+		return fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if existing != nil {
+		return domain.ErrEmailTaken
+	}
+	if _, err := seed.Apply(ctx, users, &seed.Data{Users: []seed.User{user}}); err != nil {
+		return err
+	}
+	slog.Info("Created user", "email", user.Email, "role", user.Role)
+	return nil
+}
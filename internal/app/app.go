@@ -0,0 +1,971 @@
+// Package app wires the API together: it connects the database, builds the
+// repositories, usecases, handlers and background workers from a
+// configuration, and runs the HTTP and gRPC servers. cmd/server is a thin
+// command around it, and other programs and integration tests can boot the
+// full API in-process the same way.
+package app
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/graph"
+	"github.com/aungmyozaw92/go-api-setup/internal/grpcserver"
+	"github.com/aungmyozaw92/go-api-setup/internal/handler"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/middleware"
+	"github.com/aungmyozaw92/go-api-setup/internal/realtime"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/internal/routes"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/internal/worker"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/aungmyozaw92/go-api-setup/pkg/crypto"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/debug"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/aungmyozaw92/go-api-setup/pkg/health"
+	"github.com/aungmyozaw92/go-api-setup/pkg/ids"
+	"github.com/aungmyozaw92/go-api-setup/pkg/lock"
+	"github.com/aungmyozaw92/go-api-setup/pkg/restart"
+	"github.com/aungmyozaw92/go-api-setup/pkg/siem"
+	"github.com/aungmyozaw92/go-api-setup/pkg/systemd"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// shutdownTimeout bounds how long Run waits for requests in flight to finish
+const shutdownTimeout = 30 * time.Second
+
+// App is the fully wired API. Create it with New and start it with Run.
+type App struct {
+	cfg *config.Config
+
+	db         *gorm.DB
+	repos      repository.Repositories
+	tenantPool *database.TenantPool
+
+	health        *health.Registry
+	router        *mux.Router
+	server        *http.Server
+	redirect      *http.Server
+	debugServer   *http.Server
+	opsRouter     *mux.Router
+	opsServer     *http.Server
+	grpcServer    *grpc.Server
+	grpcHealth    *grpcserver.HealthServer
+	workerManager *worker.Manager
+
+	// closers release what New opened, in reverse order
+	closers []func() error
+
+	mu sync.Mutex
+	// listeners are those Run serves, by name, for Restart to hand over
+	listeners map[string]net.Listener
+	// successor is the process Restart started, until it exits
+	successor *os.Process
+}
+
+// Option configures how New builds the application
+type Option func(*options)
+
+type options struct {
+	memoryStorage bool
+	db            *gorm.DB
+}
+
+// WithMemoryStorage keeps data in memory instead of a database; it is lost
+// when the application stops
+func WithMemoryStorage() Option {
+	return func(o *options) {
+		o.memoryStorage = true
+	}
+}
+
+// WithDB uses an already connected database instead of connecting with the
+// database configuration. Migrations still run when DB_AUTO_MIGRATE is set.
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// New builds the application from cfg, which should already be validated.
+// Some settings are process-wide (see Configure), so a process runs one App
+// at a time.
+// Nothing is served and no background work starts until Run.
+func New(cfg *config.Config, opts ...Option) (_ *App, err error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	a := &App{cfg: cfg}
+	defer func() {
+		if err != nil {
+			a.close()
+		}
+	}()
+
+	if err := Configure(cfg); err != nil {
+		return nil, err
+	}
+	newPublicID := domain.NewPublicID
+	usePublicIDs := cfg.Server.UserIDFormat != ids.FormatInt
+
+	var workerLocker lock.Locker
+	// Components register the checks /readyz and startup run
+	healthChecks := health.NewRegistry(cfg.Server.ReadinessTimeout)
+	a.health = healthChecks
+	if o.memoryStorage {
+		if cfg.Database.TenantMode != "" {
+			return nil, errors.New("DB_TENANT_MODE needs database storage")
+		}
+		slog.Warn("Using in-memory storage: all data is lost when the server stops")
+		a.repos = memory.NewRepositories()
+
+		workerLocker, err = buildLocker(&cfg.Worker, &cfg.Cache, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure worker locks: %w", err)
+		}
+	} else {
+		if err := a.openDatabase(o.db, newPublicID, usePublicIDs); err != nil {
+			return nil, err
+		}
+		a.repos = repository.NewRepositories(a.db)
+		healthChecks.Register("database", func(ctx context.Context) error {
+			_, err := database.Ping(ctx, a.db)
+			return err
+		})
+		// An outdated schema breaks queries, so it counts as not ready
+		healthChecks.Register("migrations", func(ctx context.Context) error {
+			pending, err := database.PendingMigrations(ctx, a.db, cfg.Database.Driver)
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				return fmt.Errorf(`%d migrations pending; run "server migrate up"`, pending)
+			}
+			return nil
+		})
+
+		workerLocker, err = buildLocker(&cfg.Worker, &cfg.Cache, a.db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure worker locks: %w", err)
+		}
+	}
+
+	if pinger, ok := workerLocker.(interface{ Ping(context.Context) error }); ok {
+		healthChecks.Register("worker_locks", pinger.Ping)
+	}
+
+	// Initialize repositories
+	repos := a.repos
+	userRepo := repos.Users
+	webhookRepo := repos.Webhooks
+	fileRepo := repos.Files
+
+	// Cache hot user lookups such as the auth path
+	userCache, err := buildCache(&cfg.Cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure cache: %w", err)
+	}
+	if pinger, ok := userCache.(interface{ Ping(context.Context) error }); ok {
+		healthChecks.Register("cache", pinger.Ping)
+	}
+	var cachedUserRepo repository.CachedUserRepository
+	adminOpts := []handler.AdminHandlerOption{handler.WithConfig(cfg.Redacted())}
+	if userCache != nil {
+		instrumented := cache.NewInstrumentedCache(userCache)
+		publishExpvar("user_cache", expvar.Func(func() any { return instrumented.Stats() }))
+		adminOpts = append(adminOpts, handler.WithCacheStats(instrumented.Stats))
+
+		cachedUserRepo = repository.NewCachedUserRepository(userRepo, instrumented, cfg.Cache.UserTTL)
+		userRepo = cachedUserRepo
+	}
+
+	// Initialize file storage
+	fileStorage, storageHandler, err := NewStorage(&cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure storage: %w", err)
+	}
+
+	// Expose Prometheus metrics for request traffic, background workers and
+	// the Go runtime
+	httpMetrics, metricsHandler, workerOpts := buildMetrics(cfg)
+	workerOpts = append(workerOpts, worker.WithLocker(workerLocker, cfg.Worker.LockTTL))
+
+	// Deliver emails through EMAIL_PROVIDER
+	sender, err := buildMailer(&cfg.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure email provider: %w", err)
+	}
+
+	// Run the background workers under one manager, which reports their
+	// health and stops them gracefully at shutdown
+	if a.tenantPool != nil {
+		workerOpts = append(workerOpts, worker.WithTenants(a.tenantPool))
+	}
+	a.workerManager, err = worker.SetupDefaultWorkers(cfg, userRepo, repos.Emails, webhookRepo, sender, workerOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up workers: %w", err)
+	}
+
+	// Consume the persistent job queue
+	poolOpts := []jobs.PoolOption{
+		jobs.WithConcurrency(cfg.Jobs.Concurrency),
+		jobs.WithPollInterval(cfg.Jobs.PollInterval),
+		jobs.WithLockTimeout(cfg.Jobs.LockTimeout),
+		jobs.WithPriorityAging(cfg.Jobs.PriorityAging),
+		jobs.WithRetryPolicy(jobs.RetryPolicy{
+			MaxAttempts: cfg.Jobs.MaxAttempts,
+			BaseDelay:   cfg.Jobs.RetryDelay,
+			MaxDelay:    cfg.Jobs.MaxRetryDelay,
+		}),
+	}
+	if a.tenantPool != nil {
+		poolOpts = append(poolOpts, jobs.WithTenants(a.tenantPool))
+	}
+	jobPool := jobs.NewPool(repos.Jobs, poolOpts...)
+	a.workerManager.AddWorker(jobPool)
+
+	workerManager := a.workerManager
+	healthChecks.Register("workers", func(ctx context.Context) error { return workerManager.Healthy() })
+	adminOpts = append(adminOpts, handler.WithWorkers(workerManager))
+
+	// Initialize use cases
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, cfg.Events.Source)
+
+	// Fan notifications out to each user's enabled channels through the job queue
+	bundle, err := buildI18n(&cfg.I18n)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Loaded translations", "languages", bundle.Languages())
+	emailRenderer, err := email.NewRenderer(email.Brand{
+		Name:         cfg.Email.AppName,
+		URL:          cfg.Email.AppURL,
+		SupportEmail: cfg.Email.SupportAddress,
+	}, email.WithBundle(bundle))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email templates: %w", err)
+	}
+	emailOutbox := usecase.NewEmailOutbox(emailRenderer)
+	jobQueue := jobs.NewQueue(repos.Jobs)
+	// Initialize WebSocket connection hub
+	hub := realtime.NewHub()
+	notificationChannels := []usecase.NotificationChannel{
+		usecase.NewEmailNotificationChannel(emailOutbox, repos.Emails),
+		usecase.NewWebhookNotificationChannel(webhookUsecase),
+		usecase.NewInAppNotificationChannel(repos.Notifications, hub),
+	}
+	pushSender, err := buildPushSender(&cfg.Push)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up push notifications: %w", err)
+	}
+	if pushSender != nil {
+		notificationChannels = append(notificationChannels, usecase.NewPushNotificationChannel(pushSender, repos.PushSubscriptions, jobQueue))
+	}
+	notificationUsecase := usecase.NewNotificationUsecase(userRepo, repos.NotificationPreferences, repos.Notifications, jobQueue, notificationChannels...)
+	notificationUsecase.RegisterJobs(jobPool)
+
+	// Features reacting to user changes subscribe to the event bus rather
+	// than being wired into the user usecase
+	bus := events.NewBus()
+	usecase.SubscribeUserWebhooks(bus, webhookUsecase)
+
+	userOpts := []usecase.UserUsecaseOption{usecase.WithEventBus(bus), usecase.WithNotifications(notificationUsecase)}
+	if cachedUserRepo != nil {
+		userOpts = append(userOpts, usecase.WithCacheInvalidator(cachedUserRepo))
+	}
+	if usePublicIDs {
+		userOpts = append(userOpts, usecase.WithPublicIDs())
+	}
+	var verifier *usecase.EmailVerifier
+	if cfg.Email.VerificationEnabled {
+		verifier = usecase.NewEmailVerifier(cfg.JWT.SecretKey, cfg.Email.VerificationTTL, cfg.Email.VerificationURL)
+		userOpts = append(userOpts, usecase.WithEmailVerification(verifier))
+		usecase.NewVerificationEmail(emailOutbox, repos.Emails, verifier).Subscribe(bus)
+
+		// Remind users who haven't followed their verification link yet
+		if cfg.Worker.VerificationReminderEnabled {
+			reminders := usecase.NewVerificationReminders(userRepo, repos.Emails, emailOutbox, verifier, jobQueue,
+				cfg.Worker.VerificationReminderAfter, cfg.Worker.VerificationReminderMax)
+			reminders.RegisterJobs(jobPool)
+			if err := a.workerManager.Schedule("verification_reminders", "@every 15m", a.workerManager.ForEachDatabase(reminders.Run)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cfg.Email.WelcomeEnabled {
+		usecase.NewWelcomeEmail(emailOutbox, repos.Emails, cfg.Email.AppURL, verifier).Subscribe(bus)
+	}
+	smsSender, err := buildSMSSender(&cfg.SMS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up SMS: %w", err)
+	}
+	if smsSender != nil {
+		userOpts = append(userOpts, usecase.WithSMS(smsSender, repos.VerificationCodes, cfg.SMS.CodeTTL))
+	}
+	// Publish user lifecycle events to the message broker through the job queue
+	eventPublisher, err := buildEventPublisher(&cfg.Events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up event publishing: %w", err)
+	}
+	if eventPublisher != nil {
+		a.closers = append(a.closers, eventPublisher.Close)
+		eventOutbox := usecase.NewEventOutbox(jobQueue, eventPublisher, cfg.Events.Source)
+		eventOutbox.RegisterJobs(jobPool)
+		eventOutbox.Subscribe(bus)
+		slog.Info("Publishing events", "driver", cfg.Events.Driver)
+	}
+	// Ship the audit log and security events to the SIEM
+	siemSink, err := buildSIEMSink(&cfg.SIEM, &cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up SIEM export: %w", err)
+	}
+	var securityExport *usecase.SecurityExport
+	if siemSink != nil {
+		exporter := siem.NewExporter(siemSink, siem.ExporterConfig{
+			BufferSize:    cfg.SIEM.BufferSize,
+			BatchSize:     cfg.SIEM.BatchSize,
+			FlushInterval: cfg.SIEM.FlushInterval,
+			MaxAttempts:   cfg.SIEM.MaxAttempts,
+		})
+		a.closers = append(a.closers, exporter.Close)
+		securityExport = usecase.NewSecurityExport(exporter)
+		securityExport.Subscribe(bus)
+		slog.Info("Exporting security events", "sink", cfg.SIEM.Sink)
+	}
+	// Subscription billing creates a customer for each user who signs up
+	billingProvider, err := buildBillingProvider(&cfg.Billing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up billing: %w", err)
+	}
+	var billingHandler *handler.BillingHandler
+	if billingProvider != nil {
+		billingUsecase := usecase.NewBillingUsecase(billingProvider, userRepo, repos.BillingAccounts, jobQueue, usecase.BillingSettings{
+			Plans:           cfg.Billing.Plans,
+			WebhookSecret:   cfg.Billing.StripeWebhookSecret,
+			SuccessURL:      cfg.Billing.SuccessURL,
+			CancelURL:       cfg.Billing.CancelURL,
+			PortalReturnURL: cfg.Billing.PortalReturnURL,
+		})
+		billingUsecase.RegisterJobs(jobPool)
+		billingUsecase.Subscribe(bus)
+		billingHandler = handler.NewBillingHandler(billingUsecase)
+		slog.Info("Billing enabled", "plans", len(cfg.Billing.Plans))
+	}
+	// Receive other services' webhooks at /hooks/{provider}; integrations
+	// register the events they consume with hooks.Handle
+	var hookHandler *handler.HookHandler
+	if len(cfg.Hooks.Secrets) > 0 {
+		hooks := usecase.NewInboundWebhookUsecase(jobQueue, cfg.Hooks.Secrets, cfg.Hooks.Tolerance)
+		hooks.RegisterJobs(jobPool)
+		hookHandler = handler.NewHookHandler(hooks, cfg.Hooks.MaxBodySize)
+		slog.Info("Receiving webhooks", "providers", slices.Sorted(maps.Keys(cfg.Hooks.Secrets)))
+	}
+	// Sync users from the identity provider's directory
+	directorySource, err := buildDirectorySource(&cfg.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up directory sync: %w", err)
+	}
+	if directorySource != nil {
+		directorySync := usecase.NewDirectorySync(directorySource, userRepo, cfg.Directory.AdminGroup, cfg.Directory.DryRun)
+		if err := a.workerManager.Schedule("directory_sync", "@every 1h", directorySync.Run); err != nil {
+			return nil, err
+		}
+		adminOpts = append(adminOpts, handler.WithDirectorySync(directorySync))
+		slog.Info("Syncing users from directory", "source", cfg.Directory.Source, "dry_run", cfg.Directory.DryRun)
+	}
+	userUsecase := usecase.NewUserUsecase(userRepo, cfg.JWT.SecretKey, userOpts...)
+	fileUsecase := usecase.NewFileUsecase(fileRepo, fileStorage)
+
+	// Initialize handlers
+	authHandler := handler.NewAuthHandler(userUsecase)
+	userHandler := handler.NewUserHandler(userUsecase)
+	webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+	fileHandler := handler.NewFileHandler(fileUsecase, cfg.Storage.MaxUploadSize, cfg.Storage.PresignExpiry)
+	notificationHandler := handler.NewNotificationHandler(notificationUsecase)
+
+	wsHandler := handler.NewWebSocketHandler(hub, cfg.JWT.SecretKey)
+	// GraphQL subscriptions to the user events
+	userFeed := graph.NewUserFeed(bus)
+
+	// Initialize IP filtering
+	ipResolver, err := middleware.NewClientIPResolver(cfg.IPFilter.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure IP filter: %w", err)
+	}
+	ipFilter, err := middleware.NewIPFilter(cfg.IPFilter.Allowlist, cfg.IPFilter.AllowlistPrefixes, cfg.IPFilter.Denylist, ipResolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure IP filter: %w", err)
+	}
+
+	// Log each request when ACCESS_LOG_FORMAT is set
+	accessLog, accessLogFile, err := buildAccessLog(&cfg.AccessLog, ipResolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure access log: %w", err)
+	}
+	if accessLogFile != nil {
+		a.closers = append(a.closers, accessLogFile.Close)
+	}
+
+	// Initialize maintenance mode (health and admin routes stay reachable,
+	// and so does login, or admins couldn't get a token to turn it off)
+	maintenance := middleware.NewMaintenance(
+		cfg.Maintenance.Enabled,
+		cfg.Maintenance.RetryAfter,
+		cfg.Maintenance.Message,
+		"/health", "/readyz", "/api/admin", "/api/auth/login", cfg.Metrics.Path,
+	)
+	adminHandler := handler.NewAdminHandler(maintenance, adminOpts...)
+
+	// Resolve the tenant of every request except health checks, signed
+	// storage URLs, the gRPC gateway and received webhooks (Stripe's
+	// included), which serve the shared database
+	var tenants *middleware.TenantResolver
+	if a.tenantPool != nil {
+		tenants = middleware.NewTenantResolver(a.tenantPool, cfg.Database.TenantHeader,
+			"/health", "/readyz", "/storage/", "/api/v2/", "/hooks/", "/api/billing/webhook", "/api/admin/debug/", cfg.Metrics.Path,
+		)
+	}
+
+	// Build the deprecation policy for the legacy unversioned API
+	legacyDeprecation, err := buildLegacyDeprecation(&cfg.Deprecation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deprecation configuration: %w", err)
+	}
+
+	// Serve the gRPC services alongside the HTTP server, and as JSON over
+	// HTTP via grpc-gateway
+	var gatewayHandler http.Handler
+	if cfg.Server.GRPCEnabled {
+		a.grpcHealth = grpcserver.NewHealthServer(healthChecks)
+		grpcOpts := []grpcserver.ServerOption{grpcserver.WithHealth(a.grpcHealth)}
+		if cfg.Server.GRPCReflection {
+			grpcOpts = append(grpcOpts, grpcserver.WithReflection())
+		}
+		a.grpcServer = grpcserver.NewServer(userUsecase, cfg.JWT.SecretKey, grpcOpts...)
+		gatewayHandler, err = grpcserver.NewGateway(context.Background(), "localhost:"+cfg.Server.GRPCPort)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC gateway: %w", err)
+		}
+	}
+
+	// Warn about requests slower than SLOW_REQUEST_THRESHOLD
+	var slowRequests *middleware.SlowRequestLog
+	if cfg.Logging.SlowRequestThreshold > 0 {
+		slowRequests = middleware.NewSlowRequestLog(cfg.Logging.SlowRequestThreshold)
+	}
+
+	// Alert when the share of 5xx responses reaches ALERT_ERROR_RATE
+	var errorRate *middleware.ErrorRateAlert
+	if cfg.Alert.Enabled() && cfg.Alert.ErrorRate > 0 {
+		errorRate = middleware.NewErrorRateAlert(cfg.Alert.ErrorRate, cfg.Alert.ErrorRateMinRequests, cfg.Alert.ErrorRateWindow)
+	}
+
+	// Honor clients' X-Request-Timeout, up to REQUEST_MAX_TIMEOUT and no
+	// longer than a response may take to write
+	var requestDeadline *middleware.RequestDeadline
+	if maxTimeout := cfg.Server.RequestMaxTimeout; maxTimeout > 0 {
+		if cfg.Server.WriteTimeout > 0 {
+			maxTimeout = min(maxTimeout, cfg.Server.WriteTimeout)
+		}
+		requestDeadline = middleware.NewRequestDeadline(maxTimeout)
+	}
+
+	// Record mutating requests in the audit log
+	var auditLog *middleware.AuditLog
+	if cfg.Audit.Enabled {
+		var auditRepo repository.AuditRepository = repos.Audit
+		if securityExport != nil {
+			auditRepo = securityExport.AuditRepository(auditRepo)
+		}
+		auditLog = middleware.NewAuditLog(auditRepo, ipResolver)
+	}
+
+	// Serve pprof and expvar on an internal listener, or behind admin auth
+	var debugHandler http.Handler
+	if cfg.Debug.Enabled {
+		if cfg.Debug.Addr != "" {
+			a.debugServer = newHTTPServer(cfg.Debug.Addr, debug.Handler(), &cfg.Server)
+			// Profiles and traces stream for as long as requested
+			a.debugServer.WriteTimeout = 0
+		} else {
+			debugHandler = debug.Handler()
+		}
+	}
+
+	// Setup routes using the routes package
+	deps := routes.Dependencies{
+		AuthHandler:    authHandler,
+		UserHandler:    userHandler,
+		AdminHandler:   adminHandler,
+		WebhookHandler: webhookHandler,
+		FileHandler:    fileHandler,
+		StorageHandler: storageHandler,
+		WSHandler:      wsHandler,
+		GraphQLHandler: graph.NewHandler(userUsecase, graph.WithSubscriptions(userFeed, cfg.JWT.SecretKey)),
+		GatewayHandler: gatewayHandler,
+		JWTSecret:      cfg.JWT.SecretKey,
+		IPFilter:       ipFilter,
+		Maintenance:    maintenance,
+		Tenants:        tenants,
+		CORS:           middleware.NewCORS(cfg.CORS.AllowedOrigins),
+		Locale:         middleware.NewLocale(bundle),
+
+		NotificationHandler: notificationHandler,
+		BillingHandler:      billingHandler,
+		HookHandler:         hookHandler,
+
+		BatchMaxRequests: cfg.Server.BatchMaxRequests,
+		TenantHeader:     cfg.Database.TenantHeader,
+
+		LegacyDeprecation: legacyDeprecation,
+		Health:            healthChecks,
+
+		AccessLog:       accessLog,
+		ErrorRate:       errorRate,
+		SlowRequests:    slowRequests,
+		RequestDeadline: requestDeadline,
+		Audit:           auditLog,
+		Metrics:         httpMetrics,
+		MetricsHandler:  metricsHandler,
+		MetricsPath:     cfg.Metrics.Path,
+
+		DebugHandler: debugHandler,
+		SeparateOps:  cfg.Server.OpsAddr != "",
+	}
+	a.router = routes.SetupRoutes(deps)
+
+	// Serve the operational routes on the internal ops listener when configured
+	if deps.SeparateOps {
+		a.opsRouter = routes.SetupOpsRoutes(deps)
+		a.opsServer = newHTTPServer(cfg.Server.OpsAddr, a.opsRouter, &cfg.Server)
+		if debugHandler != nil {
+			// Profiles and traces stream for as long as requested
+			a.opsServer.WriteTimeout = 0
+		}
+	}
+
+	a.server = newHTTPServer(":"+cfg.Server.Port, a.router, &cfg.Server)
+	// Hijacked WebSocket connections are not tracked by Shutdown, so close them explicitly
+	a.server.RegisterOnShutdown(hub.Shutdown)
+	a.server.RegisterOnShutdown(userFeed.Close)
+
+	// With TLS configured the server terminates HTTPS itself, with a plain
+	// HTTP server beside it redirecting to HTTPS
+	if cfg.TLS.Enabled() {
+		tlsConfig, redirectHandler := buildTLS(&cfg.TLS, cfg.Server.Port)
+		a.server.TLSConfig = tlsConfig
+		if redirectHandler != nil {
+			a.redirect = newHTTPServer(":"+cfg.TLS.HTTPPort, redirectHandler, &cfg.Server)
+		}
+	}
+
+	return a, nil
+}
+
+// Configure applies the process-wide settings of cfg: the generator of user
+// public IDs, field encryption and the format of responses. New calls it;
+// commands that use the repositories without an App call it themselves.
+func Configure(cfg *config.Config) error {
+	// New users always get a public ID; USER_ID_FORMAT decides whether it
+	// replaces the numeric ID in URLs and responses
+	newPublicID, err := ids.NewGenerator(cfg.Server.UserIDFormat)
+	if err != nil {
+		return fmt.Errorf("invalid USER_ID_FORMAT: %w", err)
+	}
+	domain.NewPublicID = newPublicID
+
+	// Encrypt PII columns at rest; without keys they can only hold empty values
+	if len(cfg.Encryption.Keys) > 0 {
+		keyring, err := crypto.NewKeyring(cfg.Encryption.Keys)
+		if err != nil {
+			return fmt.Errorf("invalid FIELD_ENCRYPTION_KEYS: %w", err)
+		}
+		crypto.SetFieldEnvelope(crypto.NewEnvelope(keyring))
+	}
+
+	if err := domain.SetTimestampFormat(cfg.Server.TimeFormat, cfg.Server.TimeZone); err != nil {
+		return fmt.Errorf("invalid RESPONSE_TIME_FORMAT or RESPONSE_TIMEZONE: %w", err)
+	}
+	handler.SetErrorDetail(cfg.Server.ErrorDetail)
+	return nil
+}
+
+// openDatabase connects to the database, or adopts db when it is not nil,
+// then migrates it and backfills public IDs as configured
+func (a *App) openDatabase(db *gorm.DB, newPublicID func() string, usePublicIDs bool) error {
+	cfg := a.cfg
+	if db == nil {
+		var err error
+		// Wait for the database to come up (e.g. in docker compose)
+		db, err = database.ConnectWithRetry(context.Background(), &cfg.Database)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+	}
+	a.db = db
+
+	// Tenant databases are opened lazily as requests for them arrive
+	switch cfg.Database.TenantMode {
+	case "":
+	case database.TenantModeDatabase:
+		a.tenantPool = database.NewTenantPool(&cfg.Database)
+		a.closers = append(a.closers, a.tenantPool.Close)
+	default:
+		return fmt.Errorf("unsupported DB_TENANT_MODE %q", cfg.Database.TenantMode)
+	}
+
+	// Run migrations
+	if cfg.Database.AutoMigrate {
+		if err := database.MigrateUp(context.Background(), db, cfg.Database.Driver); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	// Users created before public IDs existed need one to be addressable
+	if usePublicIDs {
+		backfilled, err := repository.BackfillPublicIDs(context.Background(), db, newPublicID)
+		if err != nil {
+			return fmt.Errorf("failed to backfill user public IDs: %w", err)
+		}
+		if backfilled > 0 {
+			slog.Info("Assigned public IDs to existing users", "count", backfilled)
+		}
+	}
+
+	// Expose connection pool statistics through expvar
+	if expvar.Get("db") == nil {
+		database.PublishStats("db", db)
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler serving the API, for tests that drive it
+// without a listener
+func (a *App) Handler() http.Handler {
+	return a.router
+}
+
+// Routes returns the route table of the HTTP API
+func (a *App) Routes() []routes.Route {
+	return routes.Table(a.router)
+}
+
+// OpsRoutes returns the route table of the internal ops listener, or nil
+// when OPS_ADDR is not set
+func (a *App) OpsRoutes() []routes.Route {
+	if a.opsRouter == nil {
+		return nil
+	}
+	return routes.Table(a.opsRouter)
+}
+
+// DB returns the database the application uses, or nil with in-memory storage
+func (a *App) DB() *gorm.DB {
+	return a.db
+}
+
+// Repositories returns the application's repositories
+func (a *App) Repositories() repository.Repositories {
+	return a.repos
+}
+
+// Run starts the background workers and the servers, and serves until ctx
+// is cancelled or a server fails. It then shuts down gracefully, letting
+// requests and background work in progress finish, and releases what New
+// opened. The error is that of the failed server, if any.
+func (a *App) Run(ctx context.Context) error {
+	cfg := a.cfg
+	defer a.close()
+
+	if err := a.checkDependencies(ctx); err != nil {
+		return err
+	}
+
+	// Job handlers are registered, so the workers can start
+	a.workerManager.StartAll()
+	defer a.workerManager.StopAll()
+
+	if err := a.listen(); err != nil {
+		return err
+	}
+
+	errs := make(chan error, 4)
+	serve := func(name string, fn func() error) {
+		go func() {
+			if err := fn(); err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, grpc.ErrServerStopped) {
+				errs <- fmt.Errorf("%s failed: %w", name, err)
+			}
+		}()
+	}
+
+	if a.grpcServer != nil {
+		slog.Info("gRPC server starting", "port", cfg.Server.GRPCPort)
+		serve("gRPC server", func() error { return a.grpcServer.Serve(a.listeners["grpc"]) })
+	}
+	if a.opsServer != nil {
+		slog.Info("Ops endpoints starting", "addr", cfg.Server.OpsAddr)
+		serve("ops server", func() error { return a.opsServer.Serve(a.listeners["ops"]) })
+	}
+	if a.debugServer != nil {
+		slog.Info("Debug endpoints starting", "addr", cfg.Debug.Addr)
+		serve("debug server", func() error { return a.debugServer.Serve(a.listeners["debug"]) })
+	}
+	if a.redirect != nil {
+		slog.Info("Redirecting HTTP to HTTPS", "port", cfg.TLS.HTTPPort)
+		serve("HTTP redirect server", func() error { return a.redirect.Serve(a.listeners["redirect"]) })
+	}
+
+	logServerInfo(cfg.Server.Port, cfg.TLS.Enabled(), a.Routes())
+	lis := a.listeners["http"]
+	serve("server", func() error {
+		if a.server.TLSConfig != nil {
+			// Empty file names take certificates from autocert's GetCertificate
+			return a.server.ServeTLS(lis, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		}
+		return a.server.Serve(lis)
+	})
+
+	// Under systemd (Type=notify), report readiness now that the listeners
+	// accept connections, and ping the watchdog (WatchdogSec) while serving.
+	// A process taking over from another becomes the service's main process.
+	state := systemd.Ready
+	if restart.Inherited() {
+		state = fmt.Sprintf("MAINPID=%d\n%s", os.Getpid(), systemd.Ready)
+	}
+	if sent, err := systemd.Notify(state); err != nil {
+		slog.Warn("Failed to notify systemd", "error", err)
+	} else if sent {
+		slog.Info("Notified systemd of readiness")
+	}
+	// Serving the inherited listeners, the process this one replaces can stop
+	if err := restart.Ready(); err != nil {
+		slog.Error("Failed to stop the previous process", "error", err)
+	}
+	watchdogCtx, stopWatchdog := context.WithCancel(ctx)
+	defer stopWatchdog()
+	go func() {
+		if err := systemd.RunWatchdog(watchdogCtx); err != nil {
+			slog.Error("systemd watchdog stopped", "error", err)
+		}
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case runErr = <-errs:
+	}
+
+	slog.Info("Shutting down server")
+	stopWatchdog()
+	// After a restart the service lives on in the new process
+	if !a.restarting() {
+		if _, err := systemd.Notify(systemd.Stopping); err != nil {
+			slog.Warn("Failed to notify systemd", "error", err)
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := a.server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server forced to shutdown", "error", err)
+	}
+	if a.redirect != nil {
+		a.redirect.Shutdown(shutdownCtx)
+	}
+	if a.opsServer != nil {
+		a.opsServer.Shutdown(shutdownCtx)
+	}
+	if a.debugServer != nil {
+		a.debugServer.Shutdown(shutdownCtx)
+	}
+	if a.grpcServer != nil {
+		a.grpcHealth.Shutdown()
+		a.grpcServer.GracefulStop()
+	}
+	return runErr
+}
+
+// listen opens the listeners of every server up front, so a port in use
+// fails Run before anything is served. Listeners handed over by the process
+// this one replaces (see Restart) are taken over instead of opened.
+func (a *App) listen() error {
+	cfg := a.cfg
+	listeners := make(map[string]net.Listener)
+	open := func(name, addr, desc string) error {
+		lis, err := restart.Listen(name, "tcp", addr)
+		if err != nil {
+			for _, lis := range listeners {
+				lis.Close()
+			}
+			return fmt.Errorf("failed to listen on %s: %w", desc, err)
+		}
+		listeners[name] = lis
+		return nil
+	}
+
+	if err := open("http", a.server.Addr, "port "+cfg.Server.Port); err != nil {
+		return err
+	}
+	if a.grpcServer != nil {
+		if err := open("grpc", ":"+cfg.Server.GRPCPort, "gRPC port "+cfg.Server.GRPCPort); err != nil {
+			return err
+		}
+	}
+	if a.opsServer != nil {
+		if err := open("ops", cfg.Server.OpsAddr, "OPS_ADDR "+cfg.Server.OpsAddr); err != nil {
+			return err
+		}
+	}
+	if a.debugServer != nil {
+		if err := open("debug", cfg.Debug.Addr, "DEBUG_ADDR "+cfg.Debug.Addr); err != nil {
+			return err
+		}
+	}
+	if a.redirect != nil {
+		if err := open("redirect", a.redirect.Addr, "TLS_HTTP_PORT "+cfg.TLS.HTTPPort); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	a.listeners = listeners
+	a.mu.Unlock()
+	return nil
+}
+
+// Restart replaces the running binary without downtime: it starts a new
+// process of the binary, which a deploy has replaced, handing it the
+// listeners. This process keeps serving until the new one is ready and
+// stops it as SIGTERM would; if the new process exits instead, this one
+// carries on. Restart fails unless Run is serving.
+func (a *App) Restart() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.listeners == nil {
+		return errors.New("the server is not serving")
+	}
+	if a.successor != nil {
+		return errors.New("a restart is already in progress")
+	}
+
+	proc, err := restart.Start(a.listeners)
+	if err != nil {
+		return err
+	}
+	a.successor = proc
+	slog.Info("Started a new process to take over the listeners", "pid", proc.Pid)
+
+	go func() {
+		state, err := proc.Wait()
+		a.mu.Lock()
+		a.successor = nil
+		a.mu.Unlock()
+		slog.Error("New process exited before taking over; still serving", "pid", proc.Pid, "state", state, "error", err)
+	}()
+	return nil
+}
+
+// restarting reports whether a new process is taking over from this one
+func (a *App) restarting() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.successor != nil
+}
+
+// checkDependencies runs the readiness checks once before traffic is
+// accepted. Failures stop Run, or with STARTUP_CHECKS=degraded are logged
+// and left for /readyz to report until the dependencies recover.
+func (a *App) checkDependencies(ctx context.Context) error {
+	mode := a.cfg.Server.StartupChecks
+	if mode == config.StartupChecksOff {
+		return nil
+	}
+
+	report := a.health.Check(ctx)
+	var failed []error
+	for _, name := range a.health.Names() {
+		if result := report.Checks[name]; result.Status != health.StatusUp {
+			failed = append(failed, fmt.Errorf("%s: %s", name, result.Error))
+		}
+	}
+	if len(failed) == 0 {
+		slog.Info("Startup checks passed", "checks", len(report.Checks))
+		return nil
+	}
+
+	err := fmt.Errorf("startup checks failed: %w", errors.Join(failed...))
+	if mode != config.StartupChecksDegraded {
+		return err
+	}
+	slog.Warn("Starting in degraded mode; /readyz reports not ready until the failed checks recover", "error", err)
+	return nil
+}
+
+// Close releases what New opened, for an App that is never Run, such as one
+// serving tests through Handler. Run releases it itself.
+func (a *App) Close() {
+	a.close()
+}
+
+// close releases what New opened
+func (a *App) close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		if err := a.closers[i](); err != nil {
+			slog.Error("Failed to release resource", "error", err)
+		}
+	}
+	a.closers = nil
+}
+
+// publishExpvar publishes v under name unless a previous App already did;
+// expvar panics on duplicate names
+func publishExpvar(name string, v expvar.Var) {
+	if expvar.Get(name) == nil {
+		expvar.Publish(name, v)
+	}
+}
+
+// newHTTPServer returns a server for handler on addr with the connection
+// limits of cfg
+func newHTTPServer(addr string, handler http.Handler, cfg *config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// logServerInfo logs the server startup information, and the route table at
+// debug level
+func logServerInfo(port string, tls bool, table []routes.Route) {
+	slog.Info("Server starting", "port", port, "tls", tls)
+	for _, route := range table {
+		slog.Debug("Route", "methods", strings.Join(route.Methods, ","), "path", route.Path)
+	}
+	slog.Info("Ready to accept requests")
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/seed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig loads a configuration that keeps everything the app writes in a
+// temporary directory and needs no network services
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("APP_ENV", config.EnvTest)
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_SQLITE_PATH", filepath.Join(dir, "app.db"))
+	t.Setenv("DB_AUTO_MIGRATE", "true")
+	t.Setenv("STORAGE_LOCAL_DIR", filepath.Join(dir, "uploads"))
+	t.Setenv("GRPC_ENABLED", "false")
+	t.Setenv("SERVER_PORT", freePort(t))
+
+	cfg := config.Load()
+	require.NoError(t, cfg.Validate())
+	return cfg
+}
+
+// freePort returns a TCP port nothing listens on
+func freePort(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+	return port
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNew_Database(t *testing.T) {
+	a, err := New(testConfig(t))
+	require.NoError(t, err)
+	require.NotNil(t, a.DB())
+
+	h := a.Handler()
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/health", "").Code)
+
+	rec := serve(h, http.MethodPost, "/api/auth/register", `{"name":"Ada","email":"ada@example.com","password":"secret123"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = serve(h, http.MethodPost, "/api/auth/login", `{"email":"ada@example.com","password":"secret123"}`)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	users, err := a.Repositories().Users.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), users)
+}
+
+func TestNew_MemoryStorage(t *testing.T) {
+	a, err := New(testConfig(t), WithMemoryStorage())
+	require.NoError(t, err)
+	assert.Nil(t, a.DB())
+
+	rec := serve(a.Handler(), http.MethodPost, "/api/auth/register", `{"name":"Ada","email":"ada@example.com","password":"secret123"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+}
+
+func TestNew_MaintenanceKeepsLoginReachable(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Maintenance.Enabled = true
+	a, err := New(cfg, WithMemoryStorage())
+	require.NoError(t, err)
+	_, err = seed.Apply(context.Background(), a.Repositories().Users, &seed.Data{Users: []seed.User{
+		{Name: "Grace", Email: "grace@example.com", Password: "secret123", Role: domain.RoleAdmin},
+	}})
+	require.NoError(t, err)
+	h := a.Handler()
+
+	rec := serve(h, http.MethodPost, "/api/auth/register", `{"name":"Ada","email":"ada@example.com","password":"secret123"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	rec = serve(h, http.MethodPost, "/api/auth/login", `{"email":"grace@example.com","password":"secret123"}`)
+	assert.Equal(t, http.StatusOK, rec.Code, "admins can still get a token to turn maintenance off")
+}
+
+func TestNew_RejectsTenantModeWithMemoryStorage(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Database.TenantMode = "database"
+
+	_, err := New(cfg, WithMemoryStorage())
+	assert.EqualError(t, err, "DB_TENANT_MODE needs database storage")
+}
+
+func TestRun_ServesUntilCancelled(t *testing.T) {
+	cfg := testConfig(t)
+	a, err := New(cfg, WithMemoryStorage())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+
+	url := "http://127.0.0.1:" + cfg.Server.Port + "/health"
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
+
+func TestRun_FailsWhenPortIsTaken(t *testing.T) {
+	cfg := testConfig(t)
+	lis, err := net.Listen("tcp", ":"+cfg.Server.Port)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	a, err := New(cfg, WithMemoryStorage())
+	require.NoError(t, err)
+	assert.ErrorContains(t, a.Run(context.Background()), "failed to listen on port "+cfg.Server.Port)
+}
+
+func TestRun_StartupChecks(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Database.AutoMigrate = false
+
+	a, err := New(cfg)
+	require.NoError(t, err)
+	err = a.Run(context.Background())
+	assert.ErrorContains(t, err, "startup checks failed")
+	assert.ErrorContains(t, err, `migrations pending; run "server migrate up"`)
+
+	cfg.Server.StartupChecks = config.StartupChecksDegraded
+	a, err = New(cfg)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+
+	url := "http://127.0.0.1:" + cfg.Server.Port + "/readyz"
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusServiceUnavailable
+	}, 5*time.Second, 20*time.Millisecond, "degraded mode serves, but reports not ready")
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestNew_OpsListener(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Server.OpsAddr = "127.0.0.1:" + freePort(t)
+	a, err := New(cfg, WithMemoryStorage())
+	require.NoError(t, err)
+	require.NotNil(t, a.OpsRoutes())
+
+	for _, path := range []string{"/readyz", cfg.Metrics.Path} {
+		assert.Equal(t, http.StatusNotFound, serve(a.Handler(), http.MethodGet, path, "").Code, "public %s", path)
+		assert.Equal(t, http.StatusOK, serve(a.opsRouter, http.MethodGet, path, "").Code, "ops %s", path)
+	}
+	assert.Equal(t, http.StatusOK, serve(a.Handler(), http.MethodGet, "/health", "").Code, "load balancers keep the liveness check")
+	assert.Equal(t, http.StatusUnauthorized, serve(a.opsRouter, http.MethodGet, "/api/admin/config", "").Code, "admin routes still need a token")
+}
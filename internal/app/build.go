@@ -0,0 +1,349 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/middleware"
+	"github.com/aungmyozaw92/go-api-setup/internal/worker"
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/aungmyozaw92/go-api-setup/pkg/directory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/aungmyozaw92/go-api-setup/pkg/events"
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+	"github.com/aungmyozaw92/go-api-setup/pkg/lock"
+	"github.com/aungmyozaw92/go-api-setup/pkg/mailer"
+	"github.com/aungmyozaw92/go-api-setup/pkg/metrics"
+	"github.com/aungmyozaw92/go-api-setup/pkg/siem"
+	"github.com/aungmyozaw92/go-api-setup/pkg/sms"
+	"github.com/aungmyozaw92/go-api-setup/pkg/storage"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webpush"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// buildLegacyDeprecation converts the deprecation config into a policy for the
+// unversioned routes, returning nil when they are not deprecated
+func buildLegacyDeprecation(cfg *config.DeprecationConfig) (*middleware.DeprecationPolicy, error) {
+	if !cfg.LegacyDeprecated {
+		return nil, nil
+	}
+
+	policy := &middleware.DeprecationPolicy{
+		Successor: "/api/v1",
+		PolicyURL: cfg.PolicyURL,
+	}
+
+	if cfg.LegacyDeprecatedAt != "" {
+		deprecatedAt, err := time.Parse("2006-01-02", cfg.LegacyDeprecatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API_LEGACY_DEPRECATED_AT: %w", err)
+		}
+		policy.DeprecatedAt = deprecatedAt
+	}
+
+	if cfg.LegacySunset != "" {
+		sunset, err := time.Parse("2006-01-02", cfg.LegacySunset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API_LEGACY_SUNSET: %w", err)
+		}
+		policy.Sunset = sunset
+	}
+
+	return policy, nil
+}
+
+// NewStorage creates the configured storage backend. The returned handler
+// serves signed URLs for backends without their own public endpoint and is nil otherwise.
+func NewStorage(cfg *config.StorageConfig) (storage.Storage, http.Handler, error) {
+	switch cfg.Driver {
+	case "local":
+		local, err := storage.NewLocalStorage(cfg.LocalDir, cfg.PublicURL, cfg.SigningSecret, storage.WithMaxObjectSize(cfg.MaxUploadSize))
+		if err != nil {
+			return nil, nil, err
+		}
+		return local, local.Handler(), nil
+	case "s3":
+		s3, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:             cfg.S3.Endpoint,
+			Region:               cfg.S3.Region,
+			Bucket:               cfg.S3.Bucket,
+			AccessKeyID:          cfg.S3.AccessKeyID,
+			SecretAccessKey:      cfg.S3.SecretAccessKey,
+			UseSSL:               cfg.S3.UseSSL,
+			PathStyle:            cfg.S3.PathStyle,
+			ServerSideEncryption: cfg.S3.ServerSideEncryption,
+			KMSKeyID:             cfg.S3.KMSKeyID,
+			PartSize:             uint64(cfg.S3.PartSize),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return s3, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown STORAGE_DRIVER %q", cfg.Driver)
+	}
+}
+
+// buildMailer creates the sender of the configured EMAIL_PROVIDER
+func buildMailer(cfg *config.EmailConfig) (email.Sender, error) {
+	switch cfg.Provider {
+	case "", "log":
+		return email.NewLogSender(slog.Default()), nil
+	case "smtp":
+		return mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.From,
+		})
+	case "sendgrid":
+		return mailer.NewSendGrid(mailer.SendGridConfig{
+			APIKey: cfg.SendGrid.APIKey,
+			From:   cfg.From,
+		})
+	case "ses":
+		return mailer.NewSES(mailer.SESConfig{
+			Region:           cfg.SES.Region,
+			AccessKeyID:      cfg.SES.AccessKeyID,
+			SecretAccessKey:  cfg.SES.SecretAccessKey,
+			From:             cfg.From,
+			ConfigurationSet: cfg.SES.ConfigurationSet,
+		})
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_PROVIDER %q", cfg.Provider)
+	}
+}
+
+// buildSMSSender creates the sender of the configured SMS_PROVIDER,
+// returning nil when SMS is disabled
+func buildSMSSender(cfg *config.SMSConfig) (sms.Sender, error) {
+	switch cfg.Provider {
+	case "", "none":
+		return nil, nil
+	case "log":
+		return sms.NewLogSender(slog.Default()), nil
+	case "twilio":
+		return sms.NewTwilio(sms.TwilioConfig{
+			AccountSID:          cfg.Twilio.AccountSID,
+			AuthToken:           cfg.Twilio.AuthToken,
+			From:                cfg.Twilio.From,
+			MessagingServiceSID: cfg.Twilio.MessagingServiceSID,
+		})
+	default:
+		return nil, fmt.Errorf("unknown SMS_PROVIDER %q", cfg.Provider)
+	}
+}
+
+// buildEventPublisher creates the publisher of the configured EVENTS_DRIVER,
+// returning nil when events are not published
+func buildEventPublisher(cfg *config.EventsConfig) (events.Publisher, error) {
+	switch cfg.Driver {
+	case "", "none":
+		return nil, nil
+	case "log":
+		return events.NewLogPublisher(slog.Default()), nil
+	case "kafka":
+		return events.NewKafka(events.KafkaConfig{Brokers: cfg.Kafka.Brokers, Topic: cfg.Kafka.Topic})
+	case "nats":
+		return events.NewNATS(events.NATSConfig{URL: cfg.NATS.URL, SubjectPrefix: cfg.NATS.SubjectPrefix, Name: "go-api-setup"})
+	case "rabbitmq":
+		return events.NewRabbitMQ(events.RabbitMQConfig{URL: cfg.RabbitMQ.URL, Exchange: cfg.RabbitMQ.Exchange})
+	default:
+		return nil, fmt.Errorf("unknown EVENTS_DRIVER %q", cfg.Driver)
+	}
+}
+
+// buildSIEMSink creates the sink of the configured SIEM_SINK, returning nil
+// when events are not exported. The s3 sink uses the storage backend's S3
+// endpoint and credentials with a bucket of its own.
+func buildSIEMSink(cfg *config.SIEMConfig, storageCfg *config.StorageConfig) (siem.Sink, error) {
+	switch cfg.Sink {
+	case "", "none":
+		return nil, nil
+	case "syslog":
+		return siem.NewSyslog(siem.SyslogConfig{Network: cfg.Syslog.Network, Addr: cfg.Syslog.Addr})
+	case "http":
+		return siem.NewHTTP(siem.HTTPConfig{URL: cfg.HTTP.URL, Headers: cfg.HTTP.Headers})
+	case "s3":
+		bucketCfg := *storageCfg
+		bucketCfg.Driver = "s3"
+		bucketCfg.S3.Bucket = cfg.S3.Bucket
+		store, _, err := NewStorage(&bucketCfg)
+		if err != nil {
+			return nil, err
+		}
+		return siem.NewObject(store, cfg.S3.Prefix), nil
+	default:
+		return nil, fmt.Errorf("unknown SIEM_SINK %q", cfg.Sink)
+	}
+}
+
+// buildDirectorySource creates the directory users are synced from,
+// returning nil when DIRECTORY_SOURCE is "none"
+func buildDirectorySource(cfg *config.DirectoryConfig) (directory.Source, error) {
+	switch cfg.Source {
+	case "", "none":
+		return nil, nil
+	case "scim":
+		return directory.NewSCIM(directory.SCIMConfig{BaseURL: cfg.SCIM.URL, Token: cfg.SCIM.Token, Filter: cfg.SCIM.Filter})
+	case "ldap":
+		return directory.NewLDAP(directory.LDAPConfig{
+			URL:            cfg.LDAP.URL,
+			BindDN:         cfg.LDAP.BindDN,
+			BindPassword:   cfg.LDAP.BindPassword,
+			BaseDN:         cfg.LDAP.BaseDN,
+			Filter:         cfg.LDAP.Filter,
+			IDAttribute:    cfg.LDAP.IDAttribute,
+			EmailAttribute: cfg.LDAP.EmailAttribute,
+			NameAttribute:  cfg.LDAP.NameAttribute,
+			GroupAttribute: cfg.LDAP.GroupAttribute,
+			StartTLS:       cfg.LDAP.StartTLS,
+		})
+	default:
+		return nil, fmt.Errorf("unknown DIRECTORY_SOURCE %q", cfg.Source)
+	}
+}
+
+// buildPushSender creates the Web Push sender, returning nil when push is
+// not configured
+func buildPushSender(cfg *config.PushConfig) (*webpush.Sender, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	return webpush.NewSender(webpush.Config{
+		VAPIDPublicKey:  cfg.VAPIDPublicKey,
+		VAPIDPrivateKey: cfg.VAPIDPrivateKey,
+		Subject:         cfg.Subject,
+		TTL:             cfg.TTL,
+	})
+}
+
+// buildBillingProvider creates the Stripe client, returning nil when
+// billing is not configured
+func buildBillingProvider(cfg *config.BillingConfig) (billing.Provider, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	return billing.NewStripe(billing.StripeConfig{SecretKey: cfg.StripeSecretKey})
+}
+
+// buildI18n creates the bundle translating emails and error messages: the
+// built-in English texts, then the translations in I18N_DIR
+func buildI18n(cfg *config.I18nConfig) (*i18n.Bundle, error) {
+	bundle := i18n.NewBundle()
+	if err := bundle.LoadFS(email.Messages, "locales"); err != nil {
+		return nil, err
+	}
+	if cfg.Dir != "" {
+		if err := bundle.LoadDir(cfg.Dir); err != nil {
+			return nil, fmt.Errorf("failed to load translations from I18N_DIR: %w", err)
+		}
+	}
+	return bundle, nil
+}
+
+// buildCache creates the configured cache backend, returning nil when caching is disabled
+func buildCache(cfg *config.CacheConfig) (cache.Cache, error) {
+	switch cfg.Driver {
+	case "", "none":
+		return nil, nil
+	case "memory":
+		return cache.NewMemoryCache(), nil
+	case "redis":
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return cache.NewRedisCache(ctx, cache.RedisConfig{
+			Addr:      cfg.RedisAddr,
+			Password:  cfg.RedisPassword,
+			DB:        cfg.RedisDB,
+			KeyPrefix: cfg.KeyPrefix,
+		})
+	default:
+		return nil, fmt.Errorf("unknown CACHE_DRIVER %q", cfg.Driver)
+	}
+}
+
+// buildLocker creates the distributed locker scheduled jobs run under,
+// returning nil when locking is disabled. db is nil with in-memory storage.
+func buildLocker(cfg *config.WorkerConfig, cacheCfg *config.CacheConfig, db *gorm.DB) (lock.Locker, error) {
+	switch cfg.LockDriver {
+	case "", "none":
+		return nil, nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cacheCfg.RedisAddr,
+			Password: cacheCfg.RedisPassword,
+			DB:       cacheCfg.RedisDB,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to connect to redis at %s: %w", cacheCfg.RedisAddr, err)
+		}
+		return lock.NewRedisLocker(client, cacheCfg.KeyPrefix), nil
+	case "database":
+		if db == nil {
+			return nil, errors.New("WORKER_LOCK_DRIVER=database needs database storage")
+		}
+		return lock.NewDatabaseLocker(db), nil
+	default:
+		return nil, fmt.Errorf("unknown WORKER_LOCK_DRIVER %q", cfg.LockDriver)
+	}
+}
+
+// buildAccessLog creates the access log configured by cfg, opening its
+// output file when it writes to one; both are nil when it is disabled
+func buildAccessLog(cfg *config.AccessLogConfig, resolver *middleware.ClientIPResolver) (*middleware.AccessLog, *os.File, error) {
+	if cfg.Format == "" {
+		return nil, nil, nil
+	}
+
+	var out io.Writer
+	var file *os.File
+	switch cfg.Output {
+	case "", "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		f, err := os.OpenFile(cfg.Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, file = f, f
+	}
+
+	accessLog, err := middleware.NewAccessLog(cfg.Format, out, resolver)
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, nil, err
+	}
+	return accessLog, file, nil
+}
+
+// buildMetrics sets up the Prometheus metrics of request traffic, background
+// workers and the Go runtime, returning the middleware recording requests,
+// the handler serving the metrics and the worker manager options reporting
+// to them; all are empty when METRICS_ENABLED is off
+func buildMetrics(cfg *config.Config) (*middleware.HTTPMetrics, http.Handler, []worker.ManagerOption) {
+	if !cfg.Metrics.Enabled {
+		return nil, nil, nil
+	}
+	registry := metrics.NewRegistry()
+	httpMetrics := middleware.NewHTTPMetrics(registry, middleware.WithDurationBucket(cfg.Logging.SlowRequestThreshold))
+	workerOpts := []worker.ManagerOption{worker.WithMetrics(worker.NewMetrics(registry))}
+	return httpMetrics, metrics.Handler(registry), workerOpts
+}
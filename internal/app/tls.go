@@ -0,0 +1,52 @@
+package app
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// buildTLS returns the TLS configuration of the HTTPS server and the handler
+// of the plain HTTP server beside it, which redirects to HTTPS and, with
+// autocert, answers ACME challenges. The handler is nil when TLS_HTTP_PORT
+// is empty.
+func buildTLS(cfg *config.TLSConfig, httpsPort string) (*tls.Config, http.Handler) {
+	redirect := redirectToHTTPS(httpsPort)
+
+	if len(cfg.AutocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		tlsConfig := manager.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+		return tlsConfig, manager.HTTPHandler(redirect)
+	}
+
+	if cfg.HTTPPort == "" {
+		redirect = nil
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}, redirect
+}
+
+// redirectToHTTPS permanently redirects requests to the same URL on the
+// HTTPS server listening on httpsPort
+func redirectToHTTPS(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
@@ -1,68 +1,797 @@
 package config
 
 import (
-	"log"
-	"os"
+	"errors"
+	"log/slog"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
-// Config holds all configuration for our application
+// Config holds all configuration for our application. Secrets are tagged
+// `redact:"true"` so Redacted masks them.
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
+	Database    DatabaseConfig
+	Server      ServerConfig
+	JWT         JWTConfig
+	IPFilter    IPFilterConfig
+	Maintenance MaintenanceConfig
+	Deprecation DeprecationConfig
+	Webhook     WebhookConfig
+	Hooks       HooksConfig
+	Storage     StorageConfig
+	Cache       CacheConfig
+	Encryption  EncryptionConfig
+	Metrics     MetricsConfig
+	Logging     LoggingConfig
+	Debug       DebugConfig
+	Sentry      SentryConfig
+	Alert       AlertConfig
+	AccessLog   AccessLogConfig
+	Audit       AuditConfig
+	SIEM        SIEMConfig
+	Worker      WorkerConfig
+	Jobs        JobsConfig
+	Email       EmailConfig
+	SMS         SMSConfig
+	Push        PushConfig
+	Billing     BillingConfig
+	Directory   DirectoryConfig
+	Events      EventsConfig
+	I18n        I18nConfig
+	CORS        CORSConfig
+	TLS         TLSConfig
+
+	// loadErr is the error reading the config file, reported by Validate
+	loadErr error
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host     string `env:"DB_HOST" default:"localhost"`
+	Port     string `env:"DB_PORT" default:"3306"`
+	User     string `env:"DB_USER" default:"root"`
+	Password string `env:"DB_PASSWORD" redact:"true"`
+	DBName   string `env:"DB_NAME" default:"go_api_setup"`
+	SSLMode  string `env:"DB_SSLMODE" default:"disable"`
+
+	// Driver selects the database ("mysql", "postgres", or "sqlite")
+	Driver string `env:"DB_DRIVER" default:"mysql"`
+	// SQLitePath is the SQLite database file, or ":memory:" for an in-memory database
+	SQLitePath string `env:"DB_SQLITE_PATH" default:"go_api_setup.db"`
+	// AutoMigrate applies pending migrations at startup. It is off by default
+	// in staging and production, which run "server migrate up" as a release
+	// step instead.
+	AutoMigrate bool `env:"DB_AUTO_MIGRATE"`
+	// LogLevel is how much GORM logs: "silent", "error", "warn" (failed and
+	// slow queries) or "info" (every query)
+	LogLevel string `env:"DB_LOG_LEVEL"`
+	// ReplicaHosts lists read replicas as "host" or "host:port"; they share
+	// the primary's credentials and database name. Reads go to a replica and
+	// writes and transactions to the primary.
+	ReplicaHosts []string `env:"DB_REPLICA_HOSTS"`
+	// SlowQueryThreshold logs queries that take at least this long; zero
+	// logs only failed queries
+	SlowQueryThreshold time.Duration `env:"DB_SLOW_QUERY_THRESHOLD" default:"200ms"`
+
+	// PrepareStmt caches prepared statements for reuse across queries
+	PrepareStmt bool `env:"DB_PREPARE_STMT"`
+	// SkipDefaultTransaction stops GORM wrapping each single write in a transaction
+	SkipDefaultTransaction bool `env:"DB_SKIP_DEFAULT_TRANSACTION"`
+	// CreateBatchSize splits bulk inserts into batches of this size (0 = one
+	// statement for plain GORM creates, 100 rows for repository CreateBatch)
+	CreateBatchSize int `env:"DB_CREATE_BATCH_SIZE"`
+
+	// ConnectMaxWait is how long startup keeps retrying an unreachable
+	// database before giving up (0 = fail on the first attempt)
+	ConnectMaxWait time.Duration `env:"DB_CONNECT_MAX_WAIT" default:"1m"`
+	// ConnectRetryInterval is the first backoff delay; it doubles up to ConnectRetryMaxInterval
+	ConnectRetryInterval    time.Duration `env:"DB_CONNECT_RETRY_INTERVAL" default:"500ms"`
+	ConnectRetryMaxInterval time.Duration `env:"DB_CONNECT_RETRY_MAX_INTERVAL" default:"10s"`
+
+	// TenantMode "database" gives each tenant its own database, named
+	// TenantDBPrefix followed by the tenant ID (for SQLite, a file beside
+	// SQLitePath). Requests select their tenant with the TenantHeader header.
+	// Empty serves every request from the shared database.
+	TenantMode     string `env:"DB_TENANT_MODE"`
+	TenantHeader   string `env:"DB_TENANT_HEADER" default:"X-Tenant-ID"`
+	TenantDBPrefix string `env:"DB_TENANT_DB_PREFIX" default:"tenant_"`
+	// Tenants lists the tenant IDs requests may select; any other tenant is
+	// answered 404 without opening a database
+	Tenants []string `env:"DB_TENANTS"`
+	// TenantMaxOpen caps the tenant databases kept open; the least recently
+	// used one is closed to open another
+	TenantMaxOpen int `env:"DB_TENANT_MAX_OPEN" default:"100"`
+
+	// TimeZone is the IANA zone timestamps are written in and read back as
+	// (default UTC). MySQL DATETIME columns carry no zone, so only change it
+	// to match rows an older deployment wrote in server-local time.
+	TimeZone string `env:"DB_TIMEZONE" default:"UTC"`
+}
+
+// TLSConfig holds settings for terminating TLS in the server instead of at a
+// proxy. TLS is on when CertFile and KeyFile, or AutocertDomains, are set;
+// SERVER_PORT is then the HTTPS port.
+type TLSConfig struct {
+	// CertFile and KeyFile are PEM files holding the certificate chain and
+	// its private key
+	CertFile string `env:"TLS_CERT_FILE"`
+	KeyFile  string `env:"TLS_KEY_FILE"`
+	// AutocertDomains obtains and renews certificates for these domains from
+	// Let's Encrypt, caching them in AutocertCacheDir. ACME HTTP-01
+	// challenges are answered on HTTPPort, which must be reachable as port 80.
+	AutocertDomains  []string `env:"TLS_AUTOCERT_DOMAINS"`
+	AutocertCacheDir string   `env:"TLS_AUTOCERT_CACHE_DIR" default:"./certs"`
+	AutocertEmail    string   `env:"TLS_AUTOCERT_EMAIL"`
+	// HTTPPort serves plain HTTP, redirecting to HTTPS; empty disables it
+	// (autocert always needs it)
+	HTTPPort string `env:"TLS_HTTP_PORT" default:"80"`
+}
+
+// Enabled reports whether the server terminates TLS itself
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || len(c.AutocertDomains) > 0
+}
+
+// CORSConfig holds Cross-Origin Resource Sharing configuration
+type CORSConfig struct {
+	// AllowedOrigins are the origins browsers may call the API from; "*"
+	// allows any origin
+	AllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS"`
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
+	// Environment is the deployment environment: "development", "test",
+	// "staging" or "production". It switches the defaults of other settings,
+	// and production refuses unsafe ones.
+	Environment string `env:"APP_ENV" default:"development"`
+	// ErrorDetail adds the underlying error to 500 responses; it is on by
+	// default only in development and test, as errors can leak internals
+	ErrorDetail bool `env:"ERROR_DETAIL"`
+
+	Port        string `env:"SERVER_PORT" default:"8080"`
+	GRPCEnabled bool   `env:"GRPC_ENABLED" default:"true"`
+	GRPCPort    string `env:"GRPC_PORT" default:"9090"`
+	// GRPCReflection serves the gRPC reflection service for tools such as
+	// grpcurl; it is on by default outside production
+	GRPCReflection bool `env:"GRPC_REFLECTION"`
+
+	// UserIDFormat is how users are identified in REST URLs and responses:
+	// "int" (auto-increment), "uuidv7" or "ulid"
+	UserIDFormat string `env:"USER_ID_FORMAT" default:"int"`
+
+	// TimeFormat is how JSON responses write timestamps: "rfc3339",
+	// "rfc3339nano" or "unix" (seconds); TimeZone is the IANA zone they are
+	// converted to first
+	TimeFormat string `env:"RESPONSE_TIME_FORMAT" default:"rfc3339nano"`
+	TimeZone   string `env:"RESPONSE_TIMEZONE" default:"UTC"`
+
+	// ReadinessTimeout bounds each /readyz check
+	ReadinessTimeout time.Duration `env:"READINESS_CHECK_TIMEOUT" default:"2s"`
+	// StartupChecks decides what happens when the /readyz checks (database,
+	// pending migrations, cache, ...) fail before traffic is accepted:
+	// StartupChecksFail exits, StartupChecksDegraded serves anyway with
+	// /readyz reporting not ready, and StartupChecksOff skips them
+	StartupChecks string `env:"STARTUP_CHECKS" default:"fail"`
+
+	// OpsAddr is an internal address such as "localhost:9091" to serve the
+	// admin API, debug endpoints, metrics and /readyz on, taking them off
+	// the public port; empty serves everything on SERVER_PORT
+	OpsAddr string `env:"OPS_ADDR"`
+
+	// Limits on client connections, so slow or idle clients cannot hold
+	// connections open indefinitely. ReadHeaderTimeout bounds reading the
+	// request headers, ReadTimeout the whole request including the body,
+	// WriteTimeout the time from the end of the headers to the end of the
+	// response, and IdleTimeout how long a keep-alive connection waits for the
+	// next request. A zero ReadTimeout or WriteTimeout means no limit.
+	// WebSocket connections are not affected once upgraded.
+	ReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	ReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" default:"30s"`
+	WriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"60s"`
+	IdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"120s"`
+	MaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+
+	// RequestMaxTimeout caps the timeout clients ask for with the
+	// X-Request-Timeout or Grpc-Timeout header, which sets the deadline of
+	// the request's context; zero ignores the headers. The deadline is
+	// also capped at WriteTimeout, after which no response gets through.
+	RequestMaxTimeout time.Duration `env:"REQUEST_MAX_TIMEOUT" default:"60s"`
+
+	// BatchMaxRequests is how many sub-requests POST /api/batch runs at
+	// most; zero disables the endpoint
+	BatchMaxRequests int `env:"BATCH_MAX_REQUESTS" default:"20"`
 }
 
+// Values of STARTUP_CHECKS
+const (
+	StartupChecksFail     = "fail"
+	StartupChecksDegraded = "degraded"
+	StartupChecksOff      = "off"
+)
+
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	SecretKey string
+	SecretKey string `env:"JWT_SECRET" default:"your-secret-key-change-this-in-production" redact:"true"`
+}
+
+// IPFilterConfig holds client IP allowlist/denylist configuration
+type IPFilterConfig struct {
+	// Allowlist restricts the route groups in AllowlistPrefixes to these CIDRs/IPs
+	Allowlist []string `env:"IP_ALLOWLIST"`
+	// AllowlistPrefixes are the path prefixes guarded by Allowlist, such as
+	// /api/admin; each needs the other
+	AllowlistPrefixes []string `env:"IP_ALLOWLIST_PREFIXES"`
+	// Denylist blocks these CIDRs/IPs on every route
+	Denylist []string `env:"IP_DENYLIST"`
+	// TrustedProxies are the CIDRs/IPs whose X-Forwarded-For header is trusted
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+}
+
+// MaintenanceConfig holds maintenance mode configuration
+type MaintenanceConfig struct {
+	Enabled    bool          `env:"MAINTENANCE_MODE"`
+	RetryAfter time.Duration `env:"MAINTENANCE_RETRY_AFTER" default:"5m"`
+	Message    string        `env:"MAINTENANCE_MESSAGE" default:"The API is undergoing scheduled maintenance. Please try again shortly."`
+}
+
+// DeprecationConfig holds deprecation settings for the legacy unversioned API
+// (/api/auth/*), which is superseded by /api/v1
+type DeprecationConfig struct {
+	LegacyDeprecated bool `env:"API_LEGACY_DEPRECATED"`
+	// LegacyDeprecatedAt and LegacySunset are dates in YYYY-MM-DD format
+	LegacyDeprecatedAt string `env:"API_LEGACY_DEPRECATED_AT"`
+	LegacySunset       string `env:"API_LEGACY_SUNSET"`
+	PolicyURL          string `env:"API_DEPRECATION_POLICY_URL"`
+}
+
+// WebhookConfig holds outgoing webhook delivery configuration
+type WebhookConfig struct {
+	Timeout     time.Duration `env:"WEBHOOK_TIMEOUT" default:"10s"`
+	MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" default:"5"`
+	RetryDelay  time.Duration `env:"WEBHOOK_RETRY_DELAY" default:"30s"`
+	// DisableAfter is how many consecutive failed attempts disable a
+	// subscription (0 never disables)
+	DisableAfter int `env:"WEBHOOK_DISABLE_AFTER_FAILURES" default:"50"`
+}
+
+// HooksConfig holds settings for receiving other services' webhooks at
+// /hooks/{provider}
+type HooksConfig struct {
+	// Secrets maps the providers webhooks are accepted from to their
+	// signing secrets, as "github=abc;stripe=whsec_123". github and stripe
+	// requests are verified by their own schemes; any other provider must
+	// sign like this service's webhook deliveries.
+	Secrets map[string]string `env:"HOOKS_SECRETS" redact:"true"`
+	// Tolerance is how long after signing a timestamped request is accepted
+	Tolerance time.Duration `env:"HOOKS_TOLERANCE" default:"5m"`
+	// MaxBodySize is the largest request body accepted, in bytes
+	MaxBodySize int64 `env:"HOOKS_MAX_BODY_SIZE" default:"1048576"`
+}
+
+// StorageConfig holds file storage configuration
+type StorageConfig struct {
+	// Driver selects the storage backend ("local" or "s3")
+	Driver   string `env:"STORAGE_DRIVER" default:"local"`
+	LocalDir string `env:"STORAGE_LOCAL_DIR" default:"./uploads"`
+	// PublicURL is the externally reachable URL of the /storage endpoint used in signed URLs
+	PublicURL string `env:"STORAGE_PUBLIC_URL"`
+	// SigningSecret signs local storage URLs; defaults to the JWT secret
+	SigningSecret string `env:"STORAGE_SIGNING_SECRET" redact:"true"`
+	// MaxUploadSize is the maximum upload size in bytes
+	MaxUploadSize int64 `env:"STORAGE_MAX_UPLOAD_SIZE" default:"10485760"`
+	// PresignExpiry is how long pre-signed upload/download URLs stay valid
+	PresignExpiry time.Duration `env:"STORAGE_PRESIGN_EXPIRY" default:"15m"`
+	S3            S3Config
+}
+
+// CacheConfig holds caching configuration
+type CacheConfig struct {
+	// Driver selects the cache backend ("none", "memory", or "redis")
+	Driver        string `env:"CACHE_DRIVER" default:"none"`
+	RedisAddr     string `env:"CACHE_REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword string `env:"CACHE_REDIS_PASSWORD" redact:"true"`
+	RedisDB       int    `env:"CACHE_REDIS_DB"`
+	// KeyPrefix namespaces cache keys in a shared Redis
+	KeyPrefix string `env:"CACHE_KEY_PREFIX" default:"go_api_setup:"`
+	// UserTTL is how long user lookups stay cached
+	UserTTL time.Duration `env:"CACHE_USER_TTL" default:"5m"`
+}
+
+// EncryptionConfig holds the keys for field-level encryption at rest
+type EncryptionConfig struct {
+	// Keys are "<id>:<base64 32-byte key>" entries. The first encrypts new
+	// values; keep retired keys listed until their values are rewritten.
+	Keys []string `env:"FIELD_ENCRYPTION_KEYS" redact:"true"`
+}
+
+// MetricsConfig holds settings for the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool `env:"METRICS_ENABLED" default:"true"`
+	// Path is where metrics are served for scraping; it is unauthenticated,
+	// so restrict it with IP_ALLOWLIST or at the load balancer
+	Path string `env:"METRICS_PATH" default:"/metrics"`
+}
+
+// LoggingConfig holds settings for the structured logger
+type LoggingConfig struct {
+	// Level is the minimum level logged: debug, info, warn or error
+	Level string `env:"LOG_LEVEL" default:"info"`
+	// Format is "text" for human-readable logs or "json" for one JSON
+	// object per line, for log collectors
+	Format string `env:"LOG_FORMAT"`
+
+	// File is a path to write logs to instead of stderr; it is rotated when
+	// it reaches FileMaxSizeMB, keeping FileMaxBackups rotations for at most
+	// FileMaxAgeDays (0 keeps them all)
+	File           string `env:"LOG_FILE"`
+	FileMaxSizeMB  int    `env:"LOG_FILE_MAX_SIZE_MB" default:"100"`
+	FileMaxAgeDays int    `env:"LOG_FILE_MAX_AGE_DAYS" default:"28"`
+	FileMaxBackups int    `env:"LOG_FILE_MAX_BACKUPS" default:"10"`
+	FileCompress   bool   `env:"LOG_FILE_COMPRESS"`
+
+	// SlowRequestThreshold logs a warning for requests taking longer; zero
+	// disables the slow request log
+	SlowRequestThreshold time.Duration `env:"SLOW_REQUEST_THRESHOLD" default:"1s"`
+}
+
+// DebugConfig holds settings for the pprof and expvar debug endpoints
+type DebugConfig struct {
+	Enabled bool `env:"DEBUG_ENDPOINTS_ENABLED"`
+	// Addr is an internal address such as "localhost:6060" to serve the
+	// endpoints on; empty serves them under /api/admin/debug behind admin auth
+	Addr string `env:"DEBUG_ADDR"`
+}
+
+// AccessLogConfig holds settings for the per-request access log
+type AccessLogConfig struct {
+	// Format is "structured" for application log records, "combined" for
+	// Apache combined log format lines, or empty to disable the access log
+	Format string `env:"ACCESS_LOG_FORMAT"`
+	// Output is where combined lines are written: "stdout", "stderr" or a
+	// file path appended to
+	Output string `env:"ACCESS_LOG_OUTPUT" default:"stdout"`
+}
+
+// AuditConfig holds settings for the audit log of mutating requests
+type AuditConfig struct {
+	// Enabled records every POST, PUT, PATCH and DELETE request in the
+	// audit_logs table
+	Enabled bool `env:"AUDIT_LOG_ENABLED" default:"true"`
+}
+
+// SIEMConfig holds settings for exporting the audit log and security
+// events, such as failed logins, to a SIEM
+type SIEMConfig struct {
+	// Sink ships the events: "none" (not exported), "syslog", "http" (a log
+	// collector taking newline-delimited JSON) or "s3" (batches stored in a
+	// bucket)
+	Sink   string `env:"SIEM_SINK" default:"none"`
+	Syslog SIEMSyslogConfig
+	HTTP   SIEMHTTPConfig
+	S3     SIEMS3Config
+	// BufferSize is how many events can wait to be written before new ones
+	// are dropped
+	BufferSize int `env:"SIEM_BUFFER_SIZE" default:"10000"`
+	// BatchSize events are written at once, or those waiting after
+	// FlushInterval
+	BatchSize     int           `env:"SIEM_BATCH_SIZE" default:"100"`
+	FlushInterval time.Duration `env:"SIEM_FLUSH_INTERVAL" default:"5s"`
+	// MaxAttempts is how often a batch is written, backing off between
+	// attempts, before it is dropped
+	MaxAttempts int `env:"SIEM_MAX_ATTEMPTS" default:"5"`
+}
+
+// SIEMSyslogConfig holds settings for exporting events to a syslog server
+type SIEMSyslogConfig struct {
+	// Network is "udp" or "tcp"
+	Network string `env:"SIEM_SYSLOG_NETWORK" default:"udp"`
+	Addr    string `env:"SIEM_SYSLOG_ADDR"`
+}
+
+// SIEMHTTPConfig holds settings for posting events to a log collector
+type SIEMHTTPConfig struct {
+	URL string `env:"SIEM_HTTP_URL"`
+	// Headers are sent with every request, such as
+	// "Authorization=Bearer abc"
+	Headers map[string]string `env:"SIEM_HTTP_HEADERS" redact:"true"`
+}
+
+// SIEMS3Config holds settings for storing event batches in a bucket. The
+// endpoint and credentials are the storage backend's, STORAGE_S3_*.
+type SIEMS3Config struct {
+	Bucket string `env:"SIEM_S3_BUCKET"`
+	Prefix string `env:"SIEM_S3_PREFIX" default:"siem"`
+}
+
+// WorkerConfig holds settings for the background workers
+type WorkerConfig struct {
+	// Schedules overrides the cron specs of scheduled jobs, by job name
+	Schedules map[string]string `env:"WORKER_SCHEDULES"`
+
+	// UserMonitorEnabled logs the user count every UserMonitorInterval
+	UserMonitorEnabled  bool          `env:"WORKER_USER_MONITOR_ENABLED" default:"true"`
+	UserMonitorInterval time.Duration `env:"WORKER_USER_MONITOR_INTERVAL" default:"10s"`
+	// EmailEnabled sends the emails queued in the outbox, checking for due
+	// ones every EmailInterval
+	EmailEnabled  bool          `env:"WORKER_EMAIL_ENABLED" default:"true"`
+	EmailInterval time.Duration `env:"WORKER_EMAIL_INTERVAL" default:"30s"`
+	// EmailConcurrency is how many emails are sent at once per instance
+	EmailConcurrency int `env:"WORKER_EMAIL_CONCURRENCY" default:"4"`
+	// WebhookEnabled delivers queued webhook events, checking for due ones
+	// every WebhookInterval
+	WebhookEnabled  bool          `env:"WORKER_WEBHOOK_ENABLED" default:"true"`
+	WebhookInterval time.Duration `env:"WORKER_WEBHOOK_INTERVAL" default:"15s"`
+	// WebhookConcurrency is how many deliveries are sent at once per instance
+	WebhookConcurrency int `env:"WORKER_WEBHOOK_CONCURRENCY" default:"4"`
+	// UserCleanupEnabled permanently deletes users soft-deleted more than
+	// UserRetention ago, UserCleanupBatchSize per statement, on the
+	// user_cleanup schedule
+	UserCleanupEnabled   bool          `env:"WORKER_USER_CLEANUP_ENABLED"`
+	UserRetention        time.Duration `env:"USER_RETENTION_DAYS" default:"30" unit:"24h"`
+	UserCleanupBatchSize int           `env:"WORKER_USER_CLEANUP_BATCH_SIZE" default:"500"`
+	// VerificationReminderEnabled emails users who haven't verified their
+	// email address VerificationReminderAfter after registering and again
+	// every VerificationReminderAfter, at most VerificationReminderMax times,
+	// checking on the verification_reminders schedule. It needs
+	// EMAIL_VERIFICATION_ENABLED.
+	VerificationReminderEnabled bool          `env:"WORKER_VERIFICATION_REMINDER_ENABLED"`
+	VerificationReminderAfter   time.Duration `env:"VERIFICATION_REMINDER_AFTER_HOURS" default:"24" unit:"1h"`
+	VerificationReminderMax     int           `env:"VERIFICATION_REMINDER_MAX" default:"2"`
+	// LockDriver selects where scheduled jobs take their distributed locks
+	// so each runs on one replica at a time: "none", "redis" (using the
+	// CACHE_REDIS_* connection) or "database"
+	LockDriver string `env:"WORKER_LOCK_DRIVER" default:"none"`
+	// LockTTL is how long a lock outlives a replica that crashed holding it
+	LockTTL time.Duration `env:"WORKER_LOCK_TTL" default:"1m"`
 }
 
-// Load loads configuration from environment variables
+// JobsConfig holds settings for the persistent job queue consumers
+type JobsConfig struct {
+	// Concurrency is how many jobs run at once per instance
+	Concurrency int `env:"JOBS_CONCURRENCY" default:"4"`
+	// PollInterval is how often the queue is checked for due jobs
+	PollInterval time.Duration `env:"JOBS_POLL_INTERVAL" default:"1s"`
+	// LockTimeout is how long a job may run before another consumer
+	// reclaims it, presuming its consumer died
+	LockTimeout time.Duration `env:"JOBS_LOCK_TIMEOUT" default:"10m"`
+	// MaxAttempts is how many times a failing job runs before it is marked
+	// failed, unless its type sets its own limit
+	MaxAttempts int `env:"JOBS_MAX_ATTEMPTS" default:"5"`
+	// RetryDelay is the delay before the first retry, doubling per attempt
+	// up to MaxRetryDelay
+	RetryDelay    time.Duration `env:"JOBS_RETRY_DELAY" default:"10s"`
+	MaxRetryDelay time.Duration `env:"JOBS_MAX_RETRY_DELAY" default:"1h"`
+	// PriorityAging is how long a due job may wait before it is claimed
+	// ahead of higher priorities
+	PriorityAging time.Duration `env:"JOBS_PRIORITY_AGING" default:"5m"`
+}
+
+// EmailConfig holds settings for sending the emails queued in the outbox
+type EmailConfig struct {
+	// AppName, AppURL and SupportAddress brand the email layout
+	AppName        string `env:"EMAIL_APP_NAME" default:"Go API"`
+	AppURL         string `env:"EMAIL_APP_URL"`
+	SupportAddress string `env:"EMAIL_SUPPORT_ADDRESS"`
+	// MaxAttempts is how many times an email is tried before it is marked failed
+	MaxAttempts int `env:"EMAIL_MAX_ATTEMPTS" default:"5"`
+	// RetryDelay is the delay before the first retry, doubling per attempt
+	RetryDelay time.Duration `env:"EMAIL_RETRY_DELAY" default:"1m"`
+
+	// WelcomeEnabled queues a welcome email for every user who registers;
+	// the test environment turns it off by default
+	WelcomeEnabled bool `env:"EMAIL_WELCOME_ENABLED"`
+	// VerificationEnabled adds an email verification link to the welcome
+	// email, valid for VerificationTTL. VerificationURL is where the link
+	// points, with a token parameter added; it defaults to the API's
+	// /api/auth/verify-email.
+	VerificationEnabled bool          `env:"EMAIL_VERIFICATION_ENABLED"`
+	VerificationTTL     time.Duration `env:"EMAIL_VERIFICATION_TTL" default:"48h"`
+	VerificationURL     string        `env:"EMAIL_VERIFICATION_URL"`
+
+	// Provider delivers the emails: "log" (logs them instead), "smtp",
+	// "sendgrid" or "ses"
+	Provider string `env:"EMAIL_PROVIDER" default:"log"`
+	// From is the sender, such as "Go API <no-reply@example.com>"
+	From     string `env:"EMAIL_FROM"`
+	SMTP     SMTPConfig
+	SendGrid SendGridConfig
+	SES      SESConfig
+}
+
+// SMTPConfig holds the settings of the "smtp" email provider
+type SMTPConfig struct {
+	Host     string `env:"EMAIL_SMTP_HOST"`
+	Port     string `env:"EMAIL_SMTP_PORT" default:"587"`
+	Username string `env:"EMAIL_SMTP_USERNAME"`
+	Password string `env:"EMAIL_SMTP_PASSWORD" redact:"true"`
+}
+
+// SendGridConfig holds the settings of the "sendgrid" email provider
+type SendGridConfig struct {
+	APIKey string `env:"EMAIL_SENDGRID_API_KEY" redact:"true"`
+}
+
+// SESConfig holds the settings of the "ses" email provider
+type SESConfig struct {
+	Region string `env:"EMAIL_SES_REGION" default:"us-east-1"`
+	// AccessKeyID and SecretAccessKey are optional; when empty, credentials
+	// are read from the AWS environment, shared credentials file or IAM role
+	AccessKeyID      string `env:"EMAIL_SES_ACCESS_KEY_ID"`
+	SecretAccessKey  string `env:"EMAIL_SES_SECRET_ACCESS_KEY" redact:"true"`
+	ConfigurationSet string `env:"EMAIL_SES_CONFIGURATION_SET"`
+}
+
+// SMSConfig holds settings for texting the codes of phone verification and
+// two-factor login
+type SMSConfig struct {
+	// Provider sends the messages: "none" (SMS features disabled), "log"
+	// (logs them, codes included, instead of sending) or "twilio"
+	Provider string `env:"SMS_PROVIDER" default:"none"`
+	// CodeTTL is how long a texted code stays valid
+	CodeTTL time.Duration `env:"SMS_CODE_TTL" default:"10m"`
+	Twilio  TwilioConfig
+}
+
+// TwilioConfig holds the settings of the "twilio" SMS provider
+type TwilioConfig struct {
+	AccountSID string `env:"SMS_TWILIO_ACCOUNT_SID"`
+	AuthToken  string `env:"SMS_TWILIO_AUTH_TOKEN" redact:"true"`
+	// From is the sending number in E.164 format; MessagingServiceSID sends
+	// through a Twilio messaging service instead
+	From                string `env:"SMS_TWILIO_FROM"`
+	MessagingServiceSID string `env:"SMS_TWILIO_MESSAGING_SERVICE_SID"`
+}
+
+// PushConfig holds settings for sending browser push notifications. Push
+// is enabled when the VAPID key pair is set; "server vapid-keys" generates one.
+type PushConfig struct {
+	VAPIDPublicKey  string `env:"PUSH_VAPID_PUBLIC_KEY"`
+	VAPIDPrivateKey string `env:"PUSH_VAPID_PRIVATE_KEY" redact:"true"`
+	// Subject is a mailto: or https: URL push services can reach the
+	// operator at
+	Subject string `env:"PUSH_VAPID_SUBJECT"`
+	// TTL is how long push services hold messages for offline browsers
+	TTL time.Duration `env:"PUSH_TTL" default:"24h"`
+}
+
+// Enabled reports whether push notifications are configured
+func (c *PushConfig) Enabled() bool {
+	return c.VAPIDPublicKey != "" || c.VAPIDPrivateKey != ""
+}
+
+// BillingConfig holds settings for subscription billing with Stripe.
+// Billing is enabled when the secret key is set.
+type BillingConfig struct {
+	StripeSecretKey string `env:"BILLING_STRIPE_SECRET_KEY" redact:"true"`
+	// StripeWebhookSecret is the signing secret, whsec_..., of the webhook
+	// endpoint at /api/billing/webhook
+	StripeWebhookSecret string `env:"BILLING_STRIPE_WEBHOOK_SECRET" redact:"true"`
+	// Plans maps the plans users can subscribe to to their Stripe price
+	// IDs, as "pro=price_123;team=price_456"
+	Plans map[string]string `env:"BILLING_PLANS"`
+	// SuccessURL and CancelURL are where the checkout sends users after
+	// paying or going back
+	SuccessURL string `env:"BILLING_SUCCESS_URL"`
+	CancelURL  string `env:"BILLING_CANCEL_URL"`
+	// PortalReturnURL is where the billing portal sends users back to
+	PortalReturnURL string `env:"BILLING_PORTAL_RETURN_URL"`
+}
+
+// Enabled reports whether billing is configured
+func (c *BillingConfig) Enabled() bool {
+	return c.StripeSecretKey != ""
+}
+
+// DirectoryConfig holds settings for syncing users from an external
+// identity provider's directory. The sync runs as the "directory_sync"
+// scheduled job, hourly unless WORKER_SCHEDULES sets another spec.
+type DirectoryConfig struct {
+	// Source is the directory: "none" (no sync), "scim" or "ldap"
+	Source string `env:"DIRECTORY_SOURCE" default:"none"`
+	// DryRun makes the scheduled syncs only report the changes they would
+	// make, to review them before the first real sync
+	DryRun bool `env:"DIRECTORY_DRY_RUN" default:"false"`
+	// AdminGroup, when set, gives its members the admin role and everyone
+	// else synced the user role
+	AdminGroup string `env:"DIRECTORY_ADMIN_GROUP"`
+	SCIM       DirectorySCIMConfig
+	LDAP       DirectoryLDAPConfig
+}
+
+// DirectorySCIMConfig holds settings for reading users from a SCIM 2.0
+// service
+type DirectorySCIMConfig struct {
+	// URL is the service's base URL, such as
+	// https://idp.example.com/scim/v2
+	URL   string `env:"DIRECTORY_SCIM_URL"`
+	Token string `env:"DIRECTORY_SCIM_TOKEN" redact:"true"`
+	// Filter is an optional SCIM filter for the users to sync
+	Filter string `env:"DIRECTORY_SCIM_FILTER"`
+}
+
+// DirectoryLDAPConfig holds settings for reading users from an LDAP
+// directory
+type DirectoryLDAPConfig struct {
+	// URL is the server's address, such as ldaps://ldap.example.com:636
+	URL          string `env:"DIRECTORY_LDAP_URL"`
+	BindDN       string `env:"DIRECTORY_LDAP_BIND_DN"`
+	BindPassword string `env:"DIRECTORY_LDAP_BIND_PASSWORD" redact:"true"`
+	BaseDN       string `env:"DIRECTORY_LDAP_BASE_DN"`
+	Filter       string `env:"DIRECTORY_LDAP_FILTER" default:"(objectClass=person)"`
+	// IDAttribute holds each user's immutable ID; use objectGUID for
+	// Active Directory
+	IDAttribute    string `env:"DIRECTORY_LDAP_ID_ATTRIBUTE" default:"entryUUID"`
+	EmailAttribute string `env:"DIRECTORY_LDAP_EMAIL_ATTRIBUTE" default:"mail"`
+	NameAttribute  string `env:"DIRECTORY_LDAP_NAME_ATTRIBUTE" default:"cn"`
+	GroupAttribute string `env:"DIRECTORY_LDAP_GROUP_ATTRIBUTE" default:"memberOf"`
+	// StartTLS upgrades an ldap:// connection to TLS before binding
+	StartTLS bool `env:"DIRECTORY_LDAP_START_TLS" default:"false"`
+}
+
+// EventsConfig holds settings for publishing user lifecycle events, such as
+// user.created, to a message broker
+type EventsConfig struct {
+	// Driver publishes the events: "none" (not published), "log" (logs
+	// them instead), "kafka", "nats" or "rabbitmq"
+	Driver string `env:"EVENTS_DRIVER" default:"none"`
+	// Source is the CloudEvents source of published events and webhook
+	// deliveries, a URI reference identifying this service
+	Source   string `env:"EVENTS_SOURCE" default:"/go-api-setup"`
+	Kafka    KafkaConfig
+	NATS     NATSConfig
+	RabbitMQ RabbitMQConfig
+}
+
+// KafkaConfig holds settings for publishing events to Kafka
+type KafkaConfig struct {
+	// Brokers are host:port addresses of the cluster's bootstrap brokers
+	Brokers []string `env:"EVENTS_KAFKA_BROKERS"`
+	Topic   string   `env:"EVENTS_KAFKA_TOPIC" default:"user-events"`
+}
+
+// NATSConfig holds settings for publishing events to NATS
+type NATSConfig struct {
+	URL string `env:"EVENTS_NATS_URL" default:"nats://localhost:4222" redact:"true"`
+	// SubjectPrefix is prepended to event types, publishing user.created
+	// on <prefix>.user.created
+	SubjectPrefix string `env:"EVENTS_NATS_SUBJECT_PREFIX" default:"events"`
+}
+
+// RabbitMQConfig holds settings for publishing events to RabbitMQ
+type RabbitMQConfig struct {
+	URL string `env:"EVENTS_RABBITMQ_URL" redact:"true"`
+	// Exchange is the durable topic exchange events are routed through by
+	// type; it is declared at startup
+	Exchange string `env:"EVENTS_RABBITMQ_EXCHANGE" default:"events"`
+}
+
+// I18nConfig holds settings for translating emails and error messages
+type I18nConfig struct {
+	// Dir holds message files named after their language, such as es.yaml,
+	// loaded over the built-in English texts; empty serves English only
+	Dir string `env:"I18N_DIR"`
+}
+
+// SentryConfig holds settings for reporting errors and panics to Sentry
+type SentryConfig struct {
+	// DSN is the Sentry project's key; empty disables reporting
+	DSN         string `env:"SENTRY_DSN" redact:"true"`
+	Environment string `env:"SENTRY_ENVIRONMENT"`
+	Release     string `env:"SENTRY_RELEASE"`
+	// SampleRate is the fraction of errors sent, from 0 to 1
+	SampleRate float64 `env:"SENTRY_SAMPLE_RATE" default:"1"`
+}
+
+// AlertConfig holds settings for posting operational alerts, such as a
+// worker failing repeatedly, a spike in server errors or a new admin, to a
+// Slack or Discord channel
+type AlertConfig struct {
+	// WebhookURL is a Slack or Discord incoming webhook; empty disables alerts
+	WebhookURL string `env:"ALERT_WEBHOOK_URL" redact:"true"`
+	// Format is the webhook's message format: "auto" (detected from the
+	// URL), "slack" or "discord"
+	Format string `env:"ALERT_WEBHOOK_FORMAT" default:"auto"`
+	// Source names this instance in alerts; empty uses the host name and
+	// APP_ENV
+	Source string `env:"ALERT_SOURCE"`
+	// Cooldown is how long repeats of the same alert are dropped
+	Cooldown time.Duration `env:"ALERT_COOLDOWN" default:"15m"`
+	// ErrorRate alerts when at least this fraction of the requests in an
+	// ErrorRateWindow fail with a 5xx status, once the window has served
+	// ErrorRateMinRequests; 0 disables error rate alerts
+	ErrorRate            float64       `env:"ALERT_ERROR_RATE" default:"0.1"`
+	ErrorRateWindow      time.Duration `env:"ALERT_ERROR_RATE_WINDOW" default:"1m"`
+	ErrorRateMinRequests int           `env:"ALERT_ERROR_RATE_MIN_REQUESTS" default:"20"`
+}
+
+// Enabled reports whether alerts are posted
+func (c *AlertConfig) Enabled() bool {
+	return c.WebhookURL != ""
+}
+
+// S3Config holds settings for the S3-compatible storage backend
+type S3Config struct {
+	Endpoint        string `env:"STORAGE_S3_ENDPOINT" default:"s3.amazonaws.com"`
+	Region          string `env:"STORAGE_S3_REGION" default:"us-east-1"`
+	Bucket          string `env:"STORAGE_S3_BUCKET"`
+	AccessKeyID     string `env:"STORAGE_S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `env:"STORAGE_S3_SECRET_ACCESS_KEY" redact:"true"`
+	UseSSL          bool   `env:"STORAGE_S3_USE_SSL" default:"true"`
+	PathStyle       bool   `env:"STORAGE_S3_PATH_STYLE"`
+	// ServerSideEncryption is "", "AES256" (SSE-S3), or "aws:kms" (SSE-KMS)
+	ServerSideEncryption string `env:"STORAGE_S3_SSE"`
+	KMSKeyID             string `env:"STORAGE_S3_KMS_KEY_ID"`
+	// PartSize is the multipart upload part size in bytes
+	PartSize int `env:"STORAGE_S3_PART_SIZE" default:"16777216"`
+}
+
+// Load loads configuration from environment variables and the config file
+// named by CONFIG_FILE (default config.yaml, if present). Environment
+// variables, including those in .env, override the file.
 func Load() *Config {
+	return LoadWithOverrides(nil)
+}
+
+// LoadWithOverrides loads configuration like Load, with overrides, keyed by
+// environment variable, taking precedence over both the environment and the
+// config file. Command-line flags are passed this way.
+func LoadWithOverrides(overrides map[string]string) *Config {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+		slog.Info("No .env file found, using system environment variables")
 	}
 
-	return &Config{
-		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "3306"),
-			User:     getEnv("DB_USER", "root"),
-			Password: getEnv("DB_PASSWORD", ""),
-			DBName:   getEnv("DB_NAME", "go_api_setup"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-		},
-		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-		},
-		JWT: JWTConfig{
-			SecretKey: getEnv("JWT_SECRET", "your-secret-key-change-this-in-production"),
-		},
+	flagValues = overrides
+	path, required := lookupEnv("CONFIG_FILE")
+	if !required {
+		path = DefaultConfigFile
 	}
-}
+	var err error
+	file, err = readConfigFile(path, required)
+	if file != nil {
+		slog.Info("Loaded config file", "file", path)
+	}
+	defer func() {
+		if file != nil {
+			file.warnUnused()
+		}
+		file = nil
+		flagValues = nil
+	}()
 
-// getEnv gets an environment variable with a fallback value
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+	// APP_ENV picks the defaults of the settings that vary by environment,
+	// which the struct tags leave alone when unset
+	env, ok := lookupEnv("APP_ENV")
+	if !ok {
+		env = EnvDevelopment
+	}
+	defaults := profileFor(env)
+	cfg := &Config{
+		Database: DatabaseConfig{AutoMigrate: defaults.autoMigrate, LogLevel: defaults.dbLogLevel},
+		Email:    EmailConfig{WelcomeEnabled: defaults.welcomeEmail},
+		Server:   ServerConfig{ErrorDetail: defaults.errorDetail, GRPCReflection: defaults.grpcReflection},
+		Logging:  LoggingConfig{Format: defaults.logFormat},
+		CORS:     CORSConfig{AllowedOrigins: defaults.corsOrigins},
 	}
-	return fallback
-} 
\ No newline at end of file
+	cfg.loadErr = errors.Join(err, loadEnv(cfg))
+
+	// Defaults derived from other settings
+	if cfg.Storage.PublicURL == "" {
+		cfg.Storage.PublicURL = "http://localhost:" + cfg.Server.Port + "/storage"
+	}
+	if cfg.Storage.SigningSecret == "" {
+		cfg.Storage.SigningSecret = cfg.JWT.SecretKey
+	}
+	if cfg.Email.AppURL == "" {
+		cfg.Email.AppURL = "http://localhost:" + cfg.Server.Port
+	}
+	if cfg.Email.VerificationURL == "" {
+		cfg.Email.VerificationURL = "http://localhost:" + cfg.Server.Port + "/api/auth/verify-email"
+	}
+	if cfg.Sentry.Environment == "" {
+		cfg.Sentry.Environment = cfg.Server.Environment
+	}
+	return cfg
+}
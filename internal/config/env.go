@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadEnv sets the fields of the struct dst points to from their settings,
+// recursing into nested structs. Fields are described by struct tags:
+//
+//	env:"KEY"          the setting, looked up with lookupEnv
+//	default:"VALUE"    used when the setting is unset; without one the field
+//	                   keeps its current value
+//	required:"true"    the setting must be set to a non-empty value
+//	unit:"24h"         an integer setting counted in this unit, for durations
+//	                   configured in days or similar
+//
+// Supported types are string, bool, int, int64, float64, time.Duration,
+// []string (comma-separated) and map[string]string (semicolon-separated
+// key=value pairs, leaving values free to contain commas and spaces, as cron
+// specs do). Empty values of non-string settings count as unset. Every
+// invalid setting is reported, not just the first.
+func loadEnv(dst any) error {
+	return loadStruct(reflect.ValueOf(dst).Elem())
+}
+
+// loadStruct loads the fields of a struct value
+func loadStruct(v reflect.Value) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct {
+				errs = append(errs, loadStruct(v.Field(i)))
+			}
+			continue
+		}
+
+		value, exists := lookupEnv(key)
+		if exists && value == "" && field.Type.Kind() != reflect.String && field.Type.Kind() != reflect.Slice {
+			exists = false
+		}
+		if field.Tag.Get("required") == "true" && (!exists || value == "") {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+			continue
+		}
+		if !exists {
+			if value, exists = field.Tag.Lookup("default"); !exists {
+				continue
+			}
+		}
+
+		if err := setField(v.Field(i), value, field.Tag.Get("unit")); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", key, value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// durationType is the reflect.Type of time.Duration, which is an int64 kind
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField parses value into a field
+func setField(field reflect.Value, value, unit string) error {
+	if field.Type() == durationType {
+		if unit == "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return errors.New("expected a duration such as 30s or 5m")
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		perUnit, err := time.ParseDuration(unit)
+		if err != nil {
+			return fmt.Errorf("invalid unit %q", unit)
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("expected a whole number")
+		}
+		field.SetInt(n * int64(perUnit))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("expected true or false")
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("expected a whole number")
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("expected a number")
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(parseList(value)))
+	case reflect.Map:
+		field.Set(reflect.ValueOf(parseMap(value)))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// parseList splits a comma-separated list, dropping empty items
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseMap parses semicolon-separated key=value pairs
+func parseMap(value string) map[string]string {
+	items := make(map[string]string)
+	for _, item := range strings.Split(value, ";") {
+		k, v, ok := strings.Cut(item, "=")
+		if k = strings.TrimSpace(k); ok && k != "" {
+			items[k] = strings.TrimSpace(v)
+		}
+	}
+	return items
+}
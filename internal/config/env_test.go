@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSettings struct {
+	Name      string            `env:"TEST_NAME" required:"true"`
+	Port      int               `env:"TEST_PORT" default:"8080"`
+	Enabled   bool              `env:"TEST_ENABLED" default:"true"`
+	Rate      float64           `env:"TEST_RATE"`
+	Timeout   time.Duration     `env:"TEST_TIMEOUT" default:"30s"`
+	Retention time.Duration     `env:"TEST_RETENTION_DAYS" default:"7" unit:"24h"`
+	Hosts     []string          `env:"TEST_HOSTS" default:"a,b"`
+	Schedules map[string]string `env:"TEST_SCHEDULES"`
+	Kept      string            `env:"TEST_KEPT"`
+	Nested    struct {
+		Size int64 `env:"TEST_SIZE" default:"1024"`
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("TEST_NAME", "api")
+	t.Setenv("TEST_PORT", "")
+	t.Setenv("TEST_RATE", "0.5")
+	t.Setenv("TEST_RETENTION_DAYS", "30")
+	t.Setenv("TEST_HOSTS", "db-1, db-2,")
+	t.Setenv("TEST_SCHEDULES", "cleanup=0 3 * * *; report=@daily")
+
+	settings := testSettings{Kept: "from the caller"}
+	require.NoError(t, loadEnv(&settings))
+
+	assert.Equal(t, "api", settings.Name)
+	assert.Equal(t, 8080, settings.Port, "empty values count as unset")
+	assert.True(t, settings.Enabled)
+	assert.Equal(t, 0.5, settings.Rate)
+	assert.Equal(t, 30*time.Second, settings.Timeout)
+	assert.Equal(t, 30*24*time.Hour, settings.Retention)
+	assert.Equal(t, []string{"db-1", "db-2"}, settings.Hosts)
+	assert.Equal(t, map[string]string{"cleanup": "0 3 * * *", "report": "@daily"}, settings.Schedules)
+	assert.Equal(t, "from the caller", settings.Kept, "fields without a default keep their value")
+	assert.Equal(t, int64(1024), settings.Nested.Size)
+}
+
+func TestLoadEnv_ReportsEveryInvalidSetting(t *testing.T) {
+	t.Setenv("TEST_PORT", "http")
+	t.Setenv("TEST_ENABLED", "maybe")
+	t.Setenv("TEST_TIMEOUT", "30")
+
+	err := loadEnv(&testSettings{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "TEST_NAME is required")
+	assert.ErrorContains(t, err, `invalid TEST_PORT "http": expected a whole number`)
+	assert.ErrorContains(t, err, `invalid TEST_ENABLED "maybe": expected true or false`)
+	assert.ErrorContains(t, err, `invalid TEST_TIMEOUT "30": expected a duration such as 30s or 5m`)
+}
+
+func TestConfig_EverySettingHasAnEnvTag(t *testing.T) {
+	var check func(typ reflect.Type)
+	check = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if _, ok := field.Tag.Lookup("env"); !ok {
+				if assert.Equal(t, reflect.Struct, field.Type.Kind(), "%s.%s has no env tag", typ.Name(), field.Name) {
+					check(field.Type)
+				}
+			}
+		}
+	}
+	check(reflect.TypeOf(Config{}))
+}
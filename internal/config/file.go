@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is loaded when CONFIG_FILE is unset and it exists
+const DefaultConfigFile = "config.yaml"
+
+// fileSections maps config file sections to the prefix of their environment
+// variables where the two differ. Other keys are upper-cased and joined with
+// underscores, so server.port is SERVER_PORT and storage.s3.bucket is
+// STORAGE_S3_BUCKET.
+var fileSections = map[string]string{
+	"database": "DB",
+	"workers":  "WORKER",
+}
+
+// fileMapKeys are the settings whose values are maps rather than sections;
+// they are encoded the way getEnvMap reads them
+var fileMapKeys = map[string]bool{
+	"WORKER_SCHEDULES":  true,
+	"BILLING_PLANS":     true,
+	"SIEM_HTTP_HEADERS": true,
+}
+
+// configFile holds the settings read from the config file, keyed by their
+// environment variable. Environment variables override them.
+type configFile struct {
+	path   string
+	values map[string]string
+	used   map[string]bool
+}
+
+// file is the config file Load is reading; nil when there is none
+var file *configFile
+
+// flagValues are the overrides Load was given, which take precedence over
+// the environment
+var flagValues map[string]string
+
+// readConfigFile reads the YAML config file at path. A missing file is only
+// an error when it was asked for explicitly.
+func readConfigFile(path string, required bool) (*configFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var root map[string]any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	f := &configFile{path: path, values: make(map[string]string), used: make(map[string]bool)}
+	for key, node := range root {
+		prefix := strings.ToUpper(key)
+		if section, ok := fileSections[strings.ToLower(key)]; ok {
+			prefix = section
+		}
+		if err := f.flatten(prefix, node); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+	return f, nil
+}
+
+// flatten stores node under key, recursing into sections
+func (f *configFile) flatten(key string, node any) error {
+	switch v := node.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		if fileMapKeys[key] {
+			items := make([]string, 0, len(v))
+			for k, item := range v {
+				items = append(items, k+"="+fmt.Sprint(item))
+			}
+			sort.Strings(items)
+			f.values[key] = strings.Join(items, ";")
+			return nil
+		}
+		for k, child := range v {
+			if err := f.flatten(key+"_"+strings.ToUpper(k), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, ok := item.(map[string]any); ok {
+				return fmt.Errorf("%s: list items must be plain values", key)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		f.values[key] = strings.Join(items, ",")
+		return nil
+	default:
+		f.values[key] = fmt.Sprint(v)
+		return nil
+	}
+}
+
+// warnUnused logs the file's settings that no configuration value read,
+// which are usually misspelled
+func (f *configFile) warnUnused() {
+	var unused []string
+	for key := range f.values {
+		if !f.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		slog.Warn("Ignoring unknown config file settings", "file", f.path, "settings", unused)
+	}
+}
+
+// lookupEnv looks key up in the overrides, the environment, then the config
+// file
+func lookupEnv(key string) (string, bool) {
+	if file != nil {
+		file.used[key] = true
+	}
+	if value, exists := flagValues[key]; exists {
+		return value, true
+	}
+	if value, exists := os.LookupEnv(key); exists {
+		return value, true
+	}
+	if file == nil {
+		return "", false
+	}
+	value, exists := file.values[key]
+	return value, exists
+}
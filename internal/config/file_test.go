@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes a config file and points CONFIG_FILE at it
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	writeConfigFile(t, `
+server:
+  port: 9000
+database:
+  driver: postgres
+  host: db.internal
+  replica_hosts: [replica-1, replica-2:5433]
+jwt:
+  secret: from-the-config-file
+workers:
+  email_enabled: false
+  email_interval: 1m
+  schedules:
+    user_cleanup: "0 3 * * *"
+storage:
+  s3:
+    bucket: uploads
+cors:
+  allowed_origins:
+    - https://app.example.com
+`)
+	t.Setenv("SERVER_PORT", "8081")
+
+	cfg := Load()
+	require.NoError(t, cfg.loadErr)
+
+	assert.Equal(t, "8081", cfg.Server.Port, "environment variables override the file")
+	assert.Equal(t, "postgres", cfg.Database.Driver)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, []string{"replica-1", "replica-2:5433"}, cfg.Database.ReplicaHosts)
+	assert.Equal(t, "from-the-config-file", cfg.JWT.SecretKey)
+	assert.False(t, cfg.Worker.EmailEnabled)
+	assert.Equal(t, time.Minute, cfg.Worker.EmailInterval)
+	assert.Equal(t, map[string]string{"user_cleanup": "0 3 * * *"}, cfg.Worker.Schedules)
+	assert.Equal(t, "uploads", cfg.Storage.S3.Bucket)
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.CORS.AllowedOrigins)
+}
+
+func TestLoad_ConfigFileErrors(t *testing.T) {
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, Load().Validate(), "failed to read config file")
+
+	path := writeConfigFile(t, "server: [port")
+	assert.ErrorContains(t, Load().Validate(), "failed to parse config file "+path)
+}
@@ -0,0 +1,54 @@
+package config
+
+// Deployment environments selected with APP_ENV
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// profile holds the defaults an environment switches; each is overridden by
+// setting its variable
+type profile struct {
+	// logFormat is LOG_FORMAT: readable text locally, JSON for log collectors
+	logFormat string
+	// dbLogLevel is DB_LOG_LEVEL; development logs every query
+	dbLogLevel string
+	// autoMigrate is DB_AUTO_MIGRATE; deployed environments migrate as a
+	// release step instead
+	autoMigrate bool
+	// corsOrigins is CORS_ALLOWED_ORIGINS; deployed environments allow no
+	// cross-origin requests until their frontends are listed
+	corsOrigins []string
+	// errorDetail is ERROR_DETAIL, adding the underlying error to 500 responses
+	errorDetail bool
+	// welcomeEmail is EMAIL_WELCOME_ENABLED; tests register users freely
+	// without queueing emails
+	welcomeEmail bool
+	// grpcReflection is GRPC_REFLECTION; production doesn't describe its API
+	// to anyone who connects
+	grpcReflection bool
+}
+
+// profiles are the defaults of each environment
+var profiles = map[string]profile{
+	EnvDevelopment: {logFormat: "text", dbLogLevel: "info", autoMigrate: true, corsOrigins: []string{"*"}, errorDetail: true, welcomeEmail: true, grpcReflection: true},
+	EnvTest:        {logFormat: "text", dbLogLevel: "warn", autoMigrate: true, corsOrigins: []string{"*"}, errorDetail: true, welcomeEmail: false, grpcReflection: true},
+	EnvStaging:     {logFormat: "json", dbLogLevel: "warn", autoMigrate: false, corsOrigins: nil, errorDetail: false, welcomeEmail: true, grpcReflection: true},
+	EnvProduction:  {logFormat: "json", dbLogLevel: "warn", autoMigrate: false, corsOrigins: nil, errorDetail: false, welcomeEmail: true, grpcReflection: false},
+}
+
+// profileFor returns the defaults of env; unknown environments, which
+// Validate rejects, get the development defaults
+func profileFor(env string) profile {
+	if p, ok := profiles[env]; ok {
+		return p
+	}
+	return profiles[EnvDevelopment]
+}
+
+// IsProduction reports whether APP_ENV selects the production environment
+func (c *Config) IsProduction() bool {
+	return c.Server.Environment == EnvProduction
+}
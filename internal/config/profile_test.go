@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_EnvironmentProfiles(t *testing.T) {
+	for _, key := range []string{"LOG_FORMAT", "DB_AUTO_MIGRATE", "DB_LOG_LEVEL", "CORS_ALLOWED_ORIGINS", "ERROR_DETAIL", "EMAIL_WELCOME_ENABLED", "GRPC_REFLECTION"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	cfg := loadDefaults(t)
+	assert.Equal(t, "text", cfg.Logging.Format)
+	assert.True(t, cfg.Database.AutoMigrate)
+	assert.Equal(t, "info", cfg.Database.LogLevel)
+	assert.Equal(t, []string{"*"}, cfg.CORS.AllowedOrigins)
+	assert.True(t, cfg.Server.ErrorDetail)
+	assert.True(t, cfg.Email.WelcomeEnabled)
+	assert.True(t, cfg.Server.GRPCReflection)
+
+	t.Setenv("APP_ENV", EnvTest)
+	cfg = Load()
+	assert.False(t, cfg.Email.WelcomeEnabled, "tests register users without queueing emails")
+
+	t.Setenv("APP_ENV", EnvProduction)
+	cfg = Load()
+	assert.Equal(t, "json", cfg.Logging.Format)
+	assert.False(t, cfg.Database.AutoMigrate)
+	assert.Equal(t, "warn", cfg.Database.LogLevel)
+	assert.Empty(t, cfg.CORS.AllowedOrigins)
+	assert.False(t, cfg.Server.ErrorDetail)
+	assert.False(t, cfg.Server.GRPCReflection)
+
+	t.Setenv("DB_AUTO_MIGRATE", "true")
+	t.Setenv("LOG_FORMAT", "text")
+	cfg = Load()
+	assert.True(t, cfg.Database.AutoMigrate, "explicit settings override the profile")
+	assert.Equal(t, "text", cfg.Logging.Format)
+}
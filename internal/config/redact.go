@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// redactedValue replaces secrets in Redacted; empty secrets stay empty so it
+// still shows whether one is set
+const redactedValue = "********"
+
+// Redacted returns the configuration as nested maps keyed by snake_case field
+// names, with the fields tagged `redact:"true"` masked, for operators to
+// check what the running process loaded
+func (c *Config) Redacted() map[string]any {
+	return redactStruct(reflect.ValueOf(c).Elem())
+}
+
+// redactStruct converts the exported fields of a struct value
+func redactStruct(v reflect.Value) map[string]any {
+	out := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := v.Field(i)
+		key := snakeCase(field.Name)
+
+		switch {
+		case field.Tag.Get("redact") == "true":
+			if value.IsZero() || ((value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0) {
+				out[key] = ""
+			} else {
+				out[key] = redactedValue
+			}
+		case value.Type() == reflect.TypeOf(time.Duration(0)):
+			out[key] = time.Duration(value.Int()).String()
+		case value.Kind() == reflect.Struct:
+			out[key] = redactStruct(value)
+		default:
+			out[key] = value.Interface()
+		}
+	}
+	return out
+}
+
+// snakeCase converts a Go field name such as "DBName" or "FileMaxSizeMB" to
+// snake_case ("db_name", "file_max_size_mb")
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := loadDefaults(t)
+	cfg.Database.Password = "s3cret"
+	cfg.Encryption.Keys = []string{"k1:c2VjcmV0"}
+	cfg.Sentry.DSN = ""
+
+	redacted := cfg.Redacted()
+	database := redacted["database"].(map[string]any)
+	assert.Equal(t, "********", database["password"])
+	assert.Equal(t, cfg.Database.DBName, database["db_name"])
+	assert.Equal(t, "200ms", database["slow_query_threshold"])
+	assert.Equal(t, "********", redacted["jwt"].(map[string]any)["secret_key"])
+	assert.Equal(t, "********", redacted["encryption"].(map[string]any)["keys"])
+	assert.Equal(t, "", redacted["sentry"].(map[string]any)["dsn"], "unset secrets stay empty")
+	s3 := redacted["storage"].(map[string]any)["s3"].(map[string]any)
+	assert.Equal(t, cfg.Storage.S3.Region, s3["region"])
+	assert.NotContains(t, redacted, "load_err")
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"Port":          "port",
+		"DBName":        "db_name",
+		"GRPCEnabled":   "grpc_enabled",
+		"UserIDFormat":  "user_id_format",
+		"FileMaxSizeMB": "file_max_size_mb",
+		"S3":            "s3",
+		"KMSKeyID":      "kms_key_id",
+	} {
+		assert.Equal(t, want, snakeCase(name))
+	}
+}
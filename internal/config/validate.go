@@ -0,0 +1,391 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+	"github.com/aungmyozaw92/go-api-setup/pkg/sms"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webpush"
+)
+
+// DefaultJWTSecret is the placeholder JWT_SECRET used when none is set. It is
+// public, so tokens signed with it can be forged; production refuses it.
+const DefaultJWTSecret = "your-secret-key-change-this-in-production"
+
+// tenantIDPattern matches the tenant IDs database.TenantPool accepts
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,47}$`)
+
+// minProductionSecretLength is the shortest JWT secret accepted in production
+const minProductionSecretLength = 32
+
+// Validate checks the configuration for missing and invalid values, returning
+// every problem found rather than just the first. Unsafe defaults that are
+// refused in production are only warned about elsewhere.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.loadErr != nil {
+		errs = append(errs, c.loadErr)
+	}
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			fail("%s must be one of %v, got %q", key, allowed, value)
+		}
+	}
+	port := func(key, value string) {
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+			fail("%s must be a port number between 1 and 65535, got %q", key, value)
+		}
+	}
+	positive := func(key string, enabled bool, value int64) {
+		if enabled && value <= 0 {
+			fail("%s must be greater than zero", key)
+		}
+	}
+
+	oneOf("APP_ENV", c.Server.Environment, EnvDevelopment, EnvTest, EnvStaging, EnvProduction)
+	port("SERVER_PORT", c.Server.Port)
+	if c.Server.OpsAddr != "" {
+		host, opsPort, err := net.SplitHostPort(c.Server.OpsAddr)
+		switch {
+		case err != nil:
+			fail("OPS_ADDR must be host:port, got %q", c.Server.OpsAddr)
+		case opsPort == c.Server.Port:
+			fail("OPS_ADDR must not use SERVER_PORT, which listens on every interface")
+		default:
+			port("OPS_ADDR port", opsPort)
+			if host == "" || host == "0.0.0.0" || host == "::" {
+				slog.Warn("OPS_ADDR listens on every interface; bind it to localhost or an internal address", "addr", c.Server.OpsAddr)
+			}
+		}
+	}
+	oneOf("STARTUP_CHECKS", c.Server.StartupChecks, StartupChecksFail, StartupChecksDegraded, StartupChecksOff)
+	positive("SERVER_READ_HEADER_TIMEOUT", true, int64(c.Server.ReadHeaderTimeout))
+	positive("SERVER_IDLE_TIMEOUT", true, int64(c.Server.IdleTimeout))
+	positive("SERVER_MAX_HEADER_BYTES", true, int64(c.Server.MaxHeaderBytes))
+	if c.Server.ReadTimeout < 0 {
+		fail("SERVER_READ_TIMEOUT must not be negative")
+	}
+	if c.Server.WriteTimeout < 0 {
+		fail("SERVER_WRITE_TIMEOUT must not be negative")
+	}
+	if c.Server.RequestMaxTimeout < 0 {
+		fail("REQUEST_MAX_TIMEOUT must not be negative")
+	}
+	if c.Server.BatchMaxRequests < 0 {
+		fail("BATCH_MAX_REQUESTS must not be negative")
+	}
+	if c.Server.GRPCEnabled {
+		port("GRPC_PORT", c.Server.GRPCPort)
+	}
+
+	switch {
+	case c.JWT.SecretKey == "":
+		fail("JWT_SECRET is required")
+	case c.IsProduction() && c.JWT.SecretKey == DefaultJWTSecret:
+		fail("JWT_SECRET must be changed from the placeholder value in production")
+	case c.IsProduction() && len(c.JWT.SecretKey) < minProductionSecretLength:
+		fail("JWT_SECRET must be at least %d characters in production", minProductionSecretLength)
+	case c.JWT.SecretKey == DefaultJWTSecret:
+		slog.Warn("JWT_SECRET is the placeholder value; set a random secret before deploying", "env", c.Server.Environment)
+	}
+	if c.IsProduction() && c.Storage.Driver == "local" && c.Storage.SigningSecret == DefaultJWTSecret {
+		fail("STORAGE_SIGNING_SECRET must be changed from the placeholder value in production")
+	}
+
+	if c.TLS.Enabled() {
+		switch {
+		case len(c.TLS.AutocertDomains) > 0 && (c.TLS.CertFile != "" || c.TLS.KeyFile != ""):
+			fail("TLS_AUTOCERT_DOMAINS can't be combined with TLS_CERT_FILE and TLS_KEY_FILE")
+		case len(c.TLS.AutocertDomains) > 0:
+			if c.TLS.AutocertCacheDir == "" {
+				fail("TLS_AUTOCERT_CACHE_DIR is required for autocert")
+			}
+			if c.TLS.HTTPPort == "" {
+				fail("TLS_HTTP_PORT is required for autocert, to answer ACME challenges")
+			}
+		case c.TLS.CertFile == "" || c.TLS.KeyFile == "":
+			fail("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+		}
+		if c.TLS.HTTPPort != "" {
+			port("TLS_HTTP_PORT", c.TLS.HTTPPort)
+		}
+	}
+
+	switch {
+	case len(c.IPFilter.AllowlistPrefixes) > 0 && len(c.IPFilter.Allowlist) == 0:
+		fail("IP_ALLOWLIST is required when IP_ALLOWLIST_PREFIXES is set, or no one can reach those routes")
+	case len(c.IPFilter.Allowlist) > 0 && len(c.IPFilter.AllowlistPrefixes) == 0:
+		fail("IP_ALLOWLIST_PREFIXES is required when IP_ALLOWLIST is set")
+	}
+
+	oneOf("DB_DRIVER", c.Database.Driver, "mysql", "postgres", "sqlite")
+	switch c.Database.Driver {
+	case "mysql", "postgres":
+		if c.Database.Host == "" {
+			fail("DB_HOST is required for %s", c.Database.Driver)
+		}
+		port("DB_PORT", c.Database.Port)
+		if c.Database.User == "" {
+			fail("DB_USER is required for %s", c.Database.Driver)
+		}
+		if c.Database.DBName == "" {
+			fail("DB_NAME is required for %s", c.Database.Driver)
+		}
+		if c.IsProduction() && c.Database.Password == "" {
+			fail("DB_PASSWORD is required in production")
+		}
+	case "sqlite":
+		if c.Database.SQLitePath == "" {
+			fail("DB_SQLITE_PATH is required for sqlite")
+		}
+	}
+	oneOf("DB_TENANT_MODE", c.Database.TenantMode, "", "database")
+	if c.Database.TenantMode != "" {
+		if len(c.Database.Tenants) == 0 {
+			fail("DB_TENANTS is required in tenant mode")
+		}
+		for _, tenantID := range c.Database.Tenants {
+			if !tenantIDPattern.MatchString(tenantID) {
+				fail("DB_TENANTS must hold lowercase tenant IDs of letters, digits, \"_\" or \"-\", got %q", tenantID)
+			}
+		}
+		positive("DB_TENANT_MAX_OPEN", true, int64(c.Database.TenantMaxOpen))
+	}
+	oneOf("DB_LOG_LEVEL", c.Database.LogLevel, "", "silent", "error", "warn", "info")
+
+	oneOf("STORAGE_DRIVER", c.Storage.Driver, "local", "s3")
+	if c.Storage.Driver == "s3" && c.Storage.S3.Bucket == "" {
+		fail("STORAGE_S3_BUCKET is required for s3 storage")
+	}
+	positive("STORAGE_MAX_UPLOAD_SIZE", true, c.Storage.MaxUploadSize)
+
+	oneOf("CACHE_DRIVER", c.Cache.Driver, "", "none", "memory", "redis")
+	oneOf("WORKER_LOCK_DRIVER", c.Worker.LockDriver, "", "none", "redis", "database")
+	if c.Cache.Driver == "redis" || c.Worker.LockDriver == "redis" {
+		if c.Cache.RedisAddr == "" {
+			fail("CACHE_REDIS_ADDR is required when Redis is used")
+		}
+	}
+
+	positive("WORKER_USER_MONITOR_INTERVAL", c.Worker.UserMonitorEnabled, int64(c.Worker.UserMonitorInterval))
+	positive("WORKER_EMAIL_INTERVAL", c.Worker.EmailEnabled, int64(c.Worker.EmailInterval))
+	positive("WORKER_EMAIL_CONCURRENCY", c.Worker.EmailEnabled, int64(c.Worker.EmailConcurrency))
+	positive("WORKER_WEBHOOK_INTERVAL", c.Worker.WebhookEnabled, int64(c.Worker.WebhookInterval))
+	positive("WORKER_WEBHOOK_CONCURRENCY", c.Worker.WebhookEnabled, int64(c.Worker.WebhookConcurrency))
+	positive("WORKER_USER_CLEANUP_BATCH_SIZE", c.Worker.UserCleanupEnabled, int64(c.Worker.UserCleanupBatchSize))
+	positive("VERIFICATION_REMINDER_AFTER_HOURS", c.Worker.VerificationReminderEnabled, int64(c.Worker.VerificationReminderAfter))
+	positive("VERIFICATION_REMINDER_MAX", c.Worker.VerificationReminderEnabled, int64(c.Worker.VerificationReminderMax))
+	if c.Worker.VerificationReminderEnabled && !c.Email.VerificationEnabled {
+		fail("WORKER_VERIFICATION_REMINDER_ENABLED requires EMAIL_VERIFICATION_ENABLED")
+	}
+	positive("JOBS_CONCURRENCY", true, int64(c.Jobs.Concurrency))
+	positive("JOBS_POLL_INTERVAL", true, int64(c.Jobs.PollInterval))
+	positive("JOBS_LOCK_TIMEOUT", true, int64(c.Jobs.LockTimeout))
+	positive("WEBHOOK_TIMEOUT", true, int64(c.Webhook.Timeout))
+	positive("WEBHOOK_MAX_ATTEMPTS", true, int64(c.Webhook.MaxAttempts))
+	for _, provider := range slices.Sorted(maps.Keys(c.Hooks.Secrets)) {
+		// Provider names are a segment of the /hooks/{provider} path
+		if !isHookProvider(provider) || c.Hooks.Secrets[provider] == "" {
+			fail("HOOKS_SECRETS must map lowercase provider names to signing secrets, got %q", provider)
+		}
+	}
+	positive("HOOKS_TOLERANCE", len(c.Hooks.Secrets) > 0, int64(c.Hooks.Tolerance))
+	positive("HOOKS_MAX_BODY_SIZE", len(c.Hooks.Secrets) > 0, c.Hooks.MaxBodySize)
+	positive("EMAIL_MAX_ATTEMPTS", true, int64(c.Email.MaxAttempts))
+	positive("EMAIL_VERIFICATION_TTL", c.Email.VerificationEnabled, int64(c.Email.VerificationTTL))
+	if c.Email.VerificationEnabled {
+		if u, err := url.Parse(c.Email.VerificationURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("EMAIL_VERIFICATION_URL must be an absolute http(s) URL, got %q", c.Email.VerificationURL)
+		}
+	}
+	oneOf("EMAIL_PROVIDER", c.Email.Provider, "log", "smtp", "sendgrid", "ses")
+	if c.Email.Provider != "log" {
+		if _, err := mail.ParseAddress(c.Email.From); err != nil {
+			fail("EMAIL_FROM must be an email address for the %s provider, got %q", c.Email.Provider, c.Email.From)
+		}
+	}
+	switch c.Email.Provider {
+	case "log":
+		if c.IsProduction() {
+			slog.Warn("EMAIL_PROVIDER is log; emails are logged, not sent")
+		}
+	case "smtp":
+		if c.Email.SMTP.Host == "" {
+			fail("EMAIL_SMTP_HOST is required for the smtp provider")
+		}
+	case "sendgrid":
+		if c.Email.SendGrid.APIKey == "" {
+			fail("EMAIL_SENDGRID_API_KEY is required for the sendgrid provider")
+		}
+	case "ses":
+		if c.Email.SES.Region == "" {
+			fail("EMAIL_SES_REGION is required for the ses provider")
+		}
+	}
+
+	oneOf("SMS_PROVIDER", c.SMS.Provider, "none", "log", "twilio")
+	if c.SMS.Provider != "none" && c.SMS.CodeTTL < time.Minute {
+		fail("SMS_CODE_TTL must be at least 1m, got %s", c.SMS.CodeTTL)
+	}
+	switch c.SMS.Provider {
+	case "log":
+		if c.IsProduction() {
+			slog.Warn("SMS_PROVIDER is log; verification codes are logged, not sent")
+		}
+	case "twilio":
+		if c.SMS.Twilio.AccountSID == "" || c.SMS.Twilio.AuthToken == "" {
+			fail("SMS_TWILIO_ACCOUNT_SID and SMS_TWILIO_AUTH_TOKEN are required for the twilio provider")
+		}
+		if c.SMS.Twilio.From == "" && c.SMS.Twilio.MessagingServiceSID == "" {
+			fail("SMS_TWILIO_FROM or SMS_TWILIO_MESSAGING_SERVICE_SID is required for the twilio provider")
+		} else if c.SMS.Twilio.From != "" && sms.ValidateNumber(c.SMS.Twilio.From) != nil {
+			fail("SMS_TWILIO_FROM must be a phone number in E.164 format, such as +14155550100, got %q", c.SMS.Twilio.From)
+		}
+	}
+
+	oneOf("EVENTS_DRIVER", c.Events.Driver, "none", "log", "kafka", "nats", "rabbitmq")
+	if _, err := url.Parse(c.Events.Source); err != nil || c.Events.Source == "" {
+		fail("EVENTS_SOURCE must be a URI reference, such as /go-api-setup or https://api.example.com, got %q", c.Events.Source)
+	}
+	switch c.Events.Driver {
+	case "kafka":
+		if len(c.Events.Kafka.Brokers) == 0 || c.Events.Kafka.Topic == "" {
+			fail("EVENTS_KAFKA_BROKERS and EVENTS_KAFKA_TOPIC are required for the kafka driver")
+		}
+	case "nats":
+		if c.Events.NATS.URL == "" {
+			fail("EVENTS_NATS_URL is required for the nats driver")
+		}
+	case "rabbitmq":
+		if c.Events.RabbitMQ.URL == "" || c.Events.RabbitMQ.Exchange == "" {
+			fail("EVENTS_RABBITMQ_URL and EVENTS_RABBITMQ_EXCHANGE are required for the rabbitmq driver")
+		}
+	}
+
+	oneOf("SIEM_SINK", c.SIEM.Sink, "none", "syslog", "http", "s3")
+	switch c.SIEM.Sink {
+	case "syslog":
+		oneOf("SIEM_SYSLOG_NETWORK", c.SIEM.Syslog.Network, "udp", "tcp")
+		if _, _, err := net.SplitHostPort(c.SIEM.Syslog.Addr); err != nil {
+			fail("SIEM_SYSLOG_ADDR must be host:port for the syslog sink, got %q", c.SIEM.Syslog.Addr)
+		}
+	case "http":
+		if u, err := url.Parse(c.SIEM.HTTP.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("SIEM_HTTP_URL must be an absolute http(s) URL for the http sink, got %q", c.SIEM.HTTP.URL)
+		}
+	case "s3":
+		if c.SIEM.S3.Bucket == "" {
+			fail("SIEM_S3_BUCKET is required for the s3 sink")
+		}
+	}
+	exportsSIEM := c.SIEM.Sink != "none"
+	positive("SIEM_BUFFER_SIZE", exportsSIEM, int64(c.SIEM.BufferSize))
+	positive("SIEM_BATCH_SIZE", exportsSIEM, int64(c.SIEM.BatchSize))
+	positive("SIEM_FLUSH_INTERVAL", exportsSIEM, int64(c.SIEM.FlushInterval))
+	positive("SIEM_MAX_ATTEMPTS", exportsSIEM, int64(c.SIEM.MaxAttempts))
+
+	if c.Push.Enabled() {
+		if !strings.HasPrefix(c.Push.Subject, "mailto:") && !strings.HasPrefix(c.Push.Subject, "https://") {
+			fail("PUSH_VAPID_SUBJECT must be a mailto: or https: URL when push is enabled, got %q", c.Push.Subject)
+		} else if _, err := webpush.NewSender(webpush.Config{
+			VAPIDPublicKey:  c.Push.VAPIDPublicKey,
+			VAPIDPrivateKey: c.Push.VAPIDPrivateKey,
+			Subject:         c.Push.Subject,
+		}); err != nil {
+			fail("PUSH_VAPID_PUBLIC_KEY and PUSH_VAPID_PRIVATE_KEY must be a VAPID key pair: %v", err)
+		}
+		positive("PUSH_TTL", true, int64(c.Push.TTL))
+	}
+
+	if c.Billing.Enabled() {
+		if c.Billing.StripeWebhookSecret == "" {
+			fail("BILLING_STRIPE_WEBHOOK_SECRET is required when billing is enabled")
+		}
+		if len(c.Billing.Plans) == 0 {
+			fail("BILLING_PLANS must map at least one plan to a Stripe price when billing is enabled")
+		}
+		for _, plan := range slices.Sorted(maps.Keys(c.Billing.Plans)) {
+			// Users without a subscription are on the free plan
+			if plan == "free" || c.Billing.Plans[plan] == "" {
+				fail("BILLING_PLANS must map plans other than free to Stripe prices, got %q=%q", plan, c.Billing.Plans[plan])
+			}
+		}
+		for _, setting := range [][2]string{
+			{"BILLING_SUCCESS_URL", c.Billing.SuccessURL},
+			{"BILLING_CANCEL_URL", c.Billing.CancelURL},
+			{"BILLING_PORTAL_RETURN_URL", c.Billing.PortalReturnURL},
+		} {
+			if u, err := url.Parse(setting[1]); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				fail("%s must be an absolute http(s) URL when billing is enabled, got %q", setting[0], setting[1])
+			}
+		}
+	}
+
+	oneOf("DIRECTORY_SOURCE", c.Directory.Source, "none", "scim", "ldap")
+	switch c.Directory.Source {
+	case "scim":
+		if u, err := url.Parse(c.Directory.SCIM.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("DIRECTORY_SCIM_URL must be an absolute http(s) URL for the scim source, got %q", c.Directory.SCIM.URL)
+		}
+		if c.Directory.SCIM.Token == "" {
+			fail("DIRECTORY_SCIM_TOKEN is required for the scim source")
+		}
+	case "ldap":
+		if u, err := url.Parse(c.Directory.LDAP.URL); err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+			fail("DIRECTORY_LDAP_URL must be an ldap:// or ldaps:// URL for the ldap source, got %q", c.Directory.LDAP.URL)
+		} else if c.Directory.LDAP.StartTLS && u.Scheme == "ldaps" {
+			fail("DIRECTORY_LDAP_START_TLS only applies to ldap:// URLs")
+		}
+		if c.Directory.LDAP.BaseDN == "" {
+			fail("DIRECTORY_LDAP_BASE_DN is required for the ldap source")
+		}
+	}
+
+	if c.Sentry.SampleRate < 0 || c.Sentry.SampleRate > 1 {
+		fail("SENTRY_SAMPLE_RATE must be between 0 and 1, got %g", c.Sentry.SampleRate)
+	}
+
+	if c.Alert.Enabled() {
+		if _, err := alert.ResolveFormat(c.Alert.WebhookURL, alert.Format(c.Alert.Format)); err != nil {
+			fail("ALERT_WEBHOOK_URL: %v", err)
+		}
+		if c.Alert.ErrorRate < 0 || c.Alert.ErrorRate > 1 {
+			fail("ALERT_ERROR_RATE must be between 0 and 1, got %g", c.Alert.ErrorRate)
+		}
+		positive("ALERT_ERROR_RATE_WINDOW", c.Alert.ErrorRate > 0, int64(c.Alert.ErrorRateWindow))
+		positive("ALERT_ERROR_RATE_MIN_REQUESTS", c.Alert.ErrorRate > 0, int64(c.Alert.ErrorRateMinRequests))
+		if c.Alert.Cooldown < 0 {
+			fail("ALERT_COOLDOWN must not be negative, got %s", c.Alert.Cooldown)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isHookProvider reports whether name is a valid provider name in
+// HOOKS_SECRETS: lowercase letters, digits, '-' and '_'
+func isHookProvider(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
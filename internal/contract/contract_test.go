@@ -0,0 +1,98 @@
+package contract
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/testutil"
+)
+
+// step is one canonical request, whose response is compared with
+// testdata/<name>.json
+type step struct {
+	name   string
+	method string
+	path   string
+	// as is whose token the request is sent with: "user", "admin" or
+	// anonymous when empty
+	as   string
+	body any
+}
+
+// TestContract runs the steps in order against one server, so later steps
+// see what earlier ones created
+func TestContract(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.RegisterUser("Ada Lovelace", "ada@example.com", "secret123")
+	srv.CreateAdmin("Grace Hopper", "grace@example.com", "secret123")
+	tokens := map[string]string{
+		"user":  srv.Login("ada@example.com", "secret123"),
+		"admin": srv.Login("grace@example.com", "secret123"),
+	}
+
+	steps := []step{
+		{name: "root", method: http.MethodGet, path: "/"},
+		{name: "health", method: http.MethodGet, path: "/health"},
+		{name: "version", method: http.MethodGet, path: "/api/v1/version"},
+
+		{name: "auth_register", method: http.MethodPost, path: "/api/auth/register",
+			body: domain.UserRequest{Name: "Alan Turing", Email: "alan@example.com", Password: "secret123"}},
+		{name: "auth_register_duplicate", method: http.MethodPost, path: "/api/auth/register",
+			body: domain.UserRequest{Name: "Alan Turing", Email: "alan@example.com", Password: "secret123"}},
+		{name: "auth_register_invalid", method: http.MethodPost, path: "/api/auth/register",
+			body: domain.UserRequest{Name: "", Email: "not-an-email", Password: "abc"}},
+		{name: "auth_login", method: http.MethodPost, path: "/api/auth/login",
+			body: domain.LoginRequest{Email: "alan@example.com", Password: "secret123"}},
+		{name: "auth_login_wrong_password", method: http.MethodPost, path: "/api/auth/login",
+			body: domain.LoginRequest{Email: "alan@example.com", Password: "wrong-password"}},
+
+		{name: "profile", method: http.MethodGet, path: "/api/profile", as: "user"},
+		{name: "profile_unauthenticated", method: http.MethodGet, path: "/api/profile"},
+		{name: "profile_update", method: http.MethodPut, path: "/api/profile", as: "user",
+			body: map[string]string{"name": "Ada King"}},
+
+		{name: "users_list", method: http.MethodGet, path: "/api/users?limit=2", as: "admin"},
+		{name: "users_search", method: http.MethodGet, path: "/api/users/search?q=alan", as: "admin"},
+		{name: "users_get", method: http.MethodGet, path: "/api/users/1", as: "admin"},
+		{name: "users_get_not_found", method: http.MethodGet, path: "/api/users/999", as: "admin"},
+		{name: "users_create", method: http.MethodPost, path: "/api/users", as: "admin",
+			body: domain.UserRequest{Name: "Edsger Dijkstra", Email: "edsger@example.com", Password: "secret123"}},
+		{name: "users_update", method: http.MethodPut, path: "/api/users/3", as: "admin",
+			body: map[string]string{"name": "Alan M. Turing"}},
+		{name: "users_delete", method: http.MethodDelete, path: "/api/users/3", as: "admin"},
+		{name: "users_restore", method: http.MethodPost, path: "/api/users/3/restore", as: "admin"},
+
+		{name: "notification_preferences", method: http.MethodGet, path: "/api/profile/notification-preferences", as: "user"},
+		{name: "notification_preferences_update", method: http.MethodPut, path: "/api/profile/notification-preferences", as: "user",
+			body: domain.NotificationPreferencesRequest{Preferences: []domain.NotificationPreferenceUpdate{
+				{Type: domain.NotificationAccountActivity, Channel: domain.NotificationChannelInApp, Enabled: false},
+			}}},
+		{name: "notifications", method: http.MethodGet, path: "/api/notifications", as: "user"},
+		{name: "notifications_unread_count", method: http.MethodGet, path: "/api/notifications/unread-count", as: "user"},
+		{name: "push_subscriptions", method: http.MethodGet, path: "/api/profile/push-subscriptions", as: "user"},
+
+		{name: "webhooks_create", method: http.MethodPost, path: "/api/webhooks", as: "admin",
+			body: domain.WebhookSubscriptionRequest{URL: "https://hooks.example.com/users", Events: []string{domain.EventUserCreated}}},
+		{name: "webhooks_list", method: http.MethodGet, path: "/api/webhooks", as: "admin"},
+		{name: "webhooks_get", method: http.MethodGet, path: "/api/webhooks/1", as: "admin"},
+		{name: "webhooks_deliveries", method: http.MethodGet, path: "/api/webhooks/1/deliveries", as: "admin"},
+		{name: "webhooks_delete", method: http.MethodDelete, path: "/api/webhooks/1", as: "admin"},
+
+		{name: "files", method: http.MethodGet, path: "/api/files", as: "user"},
+
+		{name: "admin_maintenance", method: http.MethodGet, path: "/api/admin/maintenance", as: "admin"},
+		{name: "admin_workers", method: http.MethodGet, path: "/api/admin/workers", as: "admin"},
+		{name: "admin_forbidden", method: http.MethodGet, path: "/api/admin/maintenance", as: "user"},
+
+		{name: "batch", method: http.MethodPost, path: "/api/batch", as: "user",
+			body: []map[string]string{{"id": "me", "method": "GET", "path": "/api/profile"}}},
+	}
+	for _, s := range steps {
+		t.Run(s.name, func(t *testing.T) {
+			resp := srv.AuthedRequest(s.method, s.path, tokens[s.as], s.body)
+			resp.AssertGolden(t, filepath.Join("testdata", s.name+".json"))
+		})
+	}
+}
@@ -0,0 +1,8 @@
+// Package contract pins the shape of the API's responses. Its tests send a
+// canonical request to each endpoint through testutil.Server and compare
+// the response with a golden JSON file under testdata, so a change to a
+// response that clients would notice fails the build. When a change is
+// intended, regenerate the golden files and commit them with it:
+//
+//	UPDATE_GOLDEN=1 go test ./internal/contract/...
+package contract
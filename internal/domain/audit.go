@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// AuditLog records who made a mutating API request and its outcome. Entries
+// are append-only.
+type AuditLog struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// ActorID is the authenticated user; nil for anonymous requests such as login
+	ActorID *uint  `json:"actor_id" gorm:"index"`
+	Method  string `json:"method" gorm:"type:varchar(10);not null"`
+	Path    string `json:"path" gorm:"type:varchar(2048);not null"`
+	// Route is the matched route template, e.g. /api/users/{id}
+	Route  string `json:"route" gorm:"type:varchar(255);not null"`
+	Status int    `json:"status" gorm:"not null"`
+	// PayloadHash is the hex SHA-256 of the request body, empty when there was none
+	PayloadHash string    `json:"payload_hash" gorm:"type:varchar(64)"`
+	RequestID   string    `json:"request_id" gorm:"type:varchar(128)"`
+	ClientIP    string    `json:"client_ip" gorm:"type:varchar(45)"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
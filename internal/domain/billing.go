@@ -0,0 +1,77 @@
+package domain
+
+import "time"
+
+// PlanFree is the plan of users without a paid subscription
+const PlanFree = "free"
+
+// BillingAccount links a user to their customer at the payment provider and
+// mirrors the state of their subscription, as last reported by the
+// provider's webhook. A user has at most one account and one subscription.
+type BillingAccount struct {
+	ID         uint   `json:"-" gorm:"primaryKey"`
+	UserID     uint   `json:"-" gorm:"not null;uniqueIndex:idx_billing_accounts_user_id"`
+	CustomerID string `json:"-" gorm:"type:varchar(255);not null;uniqueIndex:idx_billing_accounts_customer_id"`
+	// SubscriptionID is empty until the user subscribes
+	SubscriptionID string `json:"-" gorm:"type:varchar(255)"`
+	// Plan is the plan of the subscription, which the user has while Status
+	// is one that grants it
+	Plan              string     `json:"plan" gorm:"type:varchar(50)"`
+	Status            string     `json:"status" gorm:"type:varchar(30)"`
+	CurrentPeriodEnd  *time.Time `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd bool       `json:"cancel_at_period_end" gorm:"not null;default:false"`
+	// SyncedAt is when the provider created the webhook event last applied,
+	// so events arriving out of order don't roll the subscription back
+	SyncedAt  *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
+
+// Subscription statuses reported by Stripe
+const (
+	SubscriptionActive   = "active"
+	SubscriptionTrialing = "trialing"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+)
+
+// SubscriptionGrantsPlan reports whether a subscription in status gives the
+// user its plan. Past-due subscriptions keep it while the provider retries
+// the payment.
+func SubscriptionGrantsPlan(status string) bool {
+	switch status {
+	case SubscriptionActive, SubscriptionTrialing, SubscriptionPastDue:
+		return true
+	}
+	return false
+}
+
+// GrantsPlan reports whether the user has the account's plan
+func (a *BillingAccount) GrantsPlan() bool {
+	return a.Plan != "" && SubscriptionGrantsPlan(a.Status)
+}
+
+// BillingResponse represents the response payload for the current user's
+// billing status
+type BillingResponse struct {
+	// Plan is the plan the user has now
+	Plan string `json:"plan"`
+	// Subscription is nil when the user never subscribed
+	Subscription *BillingAccount `json:"subscription,omitempty"`
+}
+
+// PlanResponse represents a plan users can subscribe to
+type PlanResponse struct {
+	Name string `json:"name"`
+}
+
+// CheckoutRequest represents the request payload for subscribing to a plan
+type CheckoutRequest struct {
+	Plan string `json:"plan" validate:"required"`
+}
+
+// BillingSessionResponse is a page of the payment provider to send the user
+// to, such as the checkout
+type BillingSessionResponse struct {
+	URL string `json:"url"`
+}
@@ -0,0 +1,58 @@
+package domain
+
+// Directory sync actions
+const (
+	DirectorySyncCreate     = "create"
+	DirectorySyncUpdate     = "update"
+	DirectorySyncDeactivate = "deactivate"
+	DirectorySyncReactivate = "reactivate"
+	DirectorySyncFail       = "fail"
+)
+
+// DirectorySyncReport is the outcome of reconciling the local users with an
+// external directory. A dry run reports the changes a sync would make
+// without making them.
+type DirectorySyncReport struct {
+	DryRun     bool      `json:"dry_run"`
+	StartedAt  Timestamp `json:"started_at"`
+	FinishedAt Timestamp `json:"finished_at"`
+	// DirectoryUsers is how many users the directory listed
+	DirectoryUsers int `json:"directory_users"`
+	Created        int `json:"created"`
+	Updated        int `json:"updated"`
+	Deactivated    int `json:"deactivated"`
+	Reactivated    int `json:"reactivated"`
+	Unchanged      int `json:"unchanged"`
+	Failed         int `json:"failed"`
+	// Changes lists every user created, updated, deactivated, reactivated
+	// or that failed to sync
+	Changes []DirectorySyncChange `json:"changes"`
+}
+
+// DirectorySyncChange is the change a sync made, or would make, to a user
+type DirectorySyncChange struct {
+	// Action is one of the DirectorySync* actions
+	Action      string `json:"action"`
+	Email       string `json:"email"`
+	DirectoryID string `json:"directory_id"`
+	// Fields are the fields an update changes, such as "name" and "role"
+	Fields []string `json:"fields,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Add records change and counts it in the report's totals
+func (r *DirectorySyncReport) Add(change DirectorySyncChange) {
+	switch change.Action {
+	case DirectorySyncCreate:
+		r.Created++
+	case DirectorySyncUpdate:
+		r.Updated++
+	case DirectorySyncDeactivate:
+		r.Deactivated++
+	case DirectorySyncReactivate:
+		r.Reactivated++
+	case DirectorySyncFail:
+		r.Failed++
+	}
+	r.Changes = append(r.Changes, change)
+}
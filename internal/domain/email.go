@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Email statuses
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
+
+// Email is a rendered message in the outbox. Usecases queue emails in the
+// same transaction as the change they report; the EmailWorker sends them and
+// records the outcome, so the table shows what was sent to whom.
+type Email struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	To            string     `json:"to" gorm:"column:recipient;type:varchar(255);index;not null"`
+	Template      string     `json:"template" gorm:"type:varchar(100);not null"`
+	Subject       string     `json:"subject" gorm:"type:varchar(255);not null"`
+	HTML          string     `json:"-" gorm:"column:html_body;type:text;not null"`
+	Text          string     `json:"-" gorm:"column:text_body;type:text;not null"`
+	Status        string     `json:"status" gorm:"type:varchar(20);index;not null"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	LastError     string     `json:"last_error,omitempty" gorm:"type:text"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of expected errors. Each error below wraps one, which decides how
+// it is reported to clients, so errors.Is(err, ErrNotFound) holds for any
+// missing record.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalid      = errors.New("invalid")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+	// ErrUnavailable is the kind of errors of features that aren't enabled
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// kinds lists the kinds of errors, for KindOf
+var kinds = []error{ErrNotFound, ErrConflict, ErrInvalid, ErrUnauthorized, ErrForbidden, ErrRateLimited, ErrUnavailable}
+
+// Error is an expected error of a kind, with a message fit for clients
+type Error struct {
+	Kind    error
+	Message string
+}
+
+// NewError returns an error of kind with message
+func NewError(kind error, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Errorf returns an error of kind with a message formatted as by fmt.Sprintf
+func Errorf(kind error, format string, args ...any) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the error's kind
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// KindOf returns the kind of the expected error in err's chain, or nil when
+// err isn't an expected error
+func KindOf(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// Errors for expected outcomes, returned by the usecases, possibly wrapped,
+// for callers to match with errors.Is. Their messages are shown to clients
+// and are the keys of their translations in locales.
+var (
+	ErrUserNotFound        = NewError(ErrNotFound, "user not found")
+	ErrDeletedUserNotFound = NewError(ErrNotFound, "deleted user not found")
+	ErrInvalidUserID       = NewError(ErrInvalid, "Invalid user ID")
+	ErrEmailTaken          = NewError(ErrConflict, "user with this email already exists")
+	// ErrDuplicateEmail is wrapped with the email that appears twice in a
+	// bulk request
+	ErrDuplicateEmail      = NewError(ErrConflict, "duplicate email in request")
+	ErrInvalidCredentials  = NewError(ErrUnauthorized, "invalid email or password")
+	ErrInvalidLocale       = NewError(ErrInvalid, "invalid locale")
+	ErrSearchQueryRequired = NewError(ErrInvalid, "search query is required")
+	ErrPhoneRequired       = NewError(ErrInvalid, "phone number is required")
+
+	ErrWebhookNotFound          = NewError(ErrNotFound, "webhook not found")
+	ErrNotificationNotFound     = NewError(ErrNotFound, "notification not found")
+	ErrPushSubscriptionNotFound = NewError(ErrNotFound, "push subscription not found")
+	ErrFileNotFound             = NewError(ErrNotFound, "file not found")
+	ErrFileNameRequired         = NewError(ErrInvalid, "file name is required")
+	// ErrAccessDenied is returned when a user asks for a record of someone
+	// else's
+	ErrAccessDenied = NewError(ErrForbidden, "access denied")
+
+	// ErrInvalidVerificationToken is returned for email verification tokens
+	// that are malformed, expired, or issued for another email address
+	ErrInvalidVerificationToken = NewError(ErrInvalid, "invalid or expired verification token")
+	// ErrSMSUnavailable is returned by the SMS features when no SMS sender
+	// is configured
+	ErrSMSUnavailable = NewError(ErrUnavailable, "SMS verification is not enabled")
+	// ErrInvalidCode is returned for texted codes that are wrong, expired or
+	// used up
+	ErrInvalidCode = NewError(ErrInvalid, "invalid or expired code")
+	// ErrInvalidLoginCode is ErrInvalidCode for the second step of a login,
+	// and for its challenge tokens: it fails the login rather than the request
+	ErrInvalidLoginCode = NewError(ErrUnauthorized, "invalid or expired code")
+	// ErrCodeRecentlySent is returned when a code is requested again within
+	// a minute of the last one
+	ErrCodeRecentlySent = NewError(ErrRateLimited, "a code was sent recently; wait a minute before requesting another")
+)
+
+// VersionConflictError is returned when an update was based on a stale copy
+// of a record: someone else changed it after it was read
+type VersionConflictError struct {
+	Entity string
+	ID     uint
+}
+
+// Error implements the error interface
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %d was modified by another request; reload it and try again", e.Entity, e.ID)
+}
+
+// Unwrap returns the error's kind, ErrConflict
+func (e *VersionConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// TwoFactorRequiredError is returned by a login with the right password for
+// a user with two-factor login turned on. A code has been texted to the
+// user; the login completes by sending it with ChallengeToken.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+}
+
+// Error implements the error interface
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor authentication required"
+}
+
+// Unwrap returns the error's kind, ErrUnauthorized
+func (e *TwoFactorRequiredError) Unwrap() error {
+	return ErrUnauthorized
+}
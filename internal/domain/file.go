@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// File represents an uploaded file. The content lives in object storage under Key.
+type File struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	OwnerID     uint           `json:"owner_id" gorm:"index;not null"`
+	Key         string         `json:"-" gorm:"type:varchar(255);uniqueIndex;not null"`
+	Name        string         `json:"name" gorm:"type:varchar(255);not null"`
+	ContentType string         `json:"content_type" gorm:"type:varchar(255);not null"`
+	Size        int64          `json:"size" gorm:"not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// FileResponse represents the file metadata returned to clients
+type FileResponse struct {
+	ID          uint      `json:"id"`
+	OwnerID     uint      `json:"owner_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   Timestamp `json:"created_at"`
+}
+
+// Presign actions
+const (
+	PresignActionUpload   = "upload"
+	PresignActionDownload = "download"
+)
+
+// PresignRequest represents the request payload for a pre-signed URL. Uploads
+// need Name (and optionally ContentType and Size); downloads need FileID.
+type PresignRequest struct {
+	Action      string `json:"action" validate:"required,oneof=upload download"`
+	FileID      uint   `json:"file_id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// PresignResponse contains a time-limited URL for direct access to object storage
+type PresignResponse struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Headers must be sent with an upload as given; they are part of its
+	// signature
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt Timestamp         `json:"expires_at"`
+	File      *FileResponse     `json:"file"`
+}
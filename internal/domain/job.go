@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+)
+
+// Job priorities; consumers claim higher priorities first
+const (
+	JobPriorityHigh    = 10
+	JobPriorityDefault = 0
+	JobPriorityLow     = -10
+)
+
+// Job is a unit of background work persisted in the job queue, so it
+// survives restarts. Payload is the JSON-encoded input of the job's handler.
+type Job struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Type      string `json:"type" gorm:"type:varchar(100);index;not null"`
+	Payload   string `json:"payload" gorm:"type:text;not null"`
+	Status    string `json:"status" gorm:"type:varchar(20);not null"`
+	Priority  int    `json:"priority" gorm:"not null;default:0"`
+	Attempts  int    `json:"attempts" gorm:"not null;default:0"`
+	LastError string `json:"last_error,omitempty" gorm:"type:text"`
+	// Tenant is the tenant the job was enqueued for in database-per-tenant
+	// mode, empty for the shared database
+	Tenant string `json:"tenant,omitempty" gorm:"type:varchar(48);not null;default:''"`
+	// RunAt is when the job is next due
+	RunAt time.Time `json:"run_at" gorm:"not null"`
+	// LockedAt is when a consumer claimed the running job
+	LockedAt   *time.Time `json:"locked_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,99 @@
+package domain
+
+import "time"
+
+// Notification channels a notification can be sent on
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelInApp   = "in_app"
+	NotificationChannelPush    = "push"
+)
+
+// Notification types
+const (
+	NotificationSecurityAlert   = "security_alert"
+	NotificationAccountActivity = "account_activity"
+)
+
+// NotificationTypes lists every notification type with the channels it is
+// sent on unless the user turns them off
+var NotificationTypes = map[string][]string{
+	NotificationSecurityAlert:   {NotificationChannelEmail, NotificationChannelWebhook, NotificationChannelInApp, NotificationChannelPush},
+	NotificationAccountActivity: {NotificationChannelWebhook, NotificationChannelInApp},
+}
+
+// NotificationMessage is a notification for one user. It is fanned out to
+// each channel the user has enabled for its type, and every channel renders
+// it its own way.
+type NotificationMessage struct {
+	Type    string                 `json:"type"`
+	UserID  uint                   `json:"user_id"`
+	Subject string                 `json:"subject"`
+	Message string                 `json:"message"`
+	URL     string                 `json:"url,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Notification is an entry in a user's in-app inbox. Payload holds the
+// JSON-encoded NotificationPayload of the message it was created from.
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"-" gorm:"not null;index:idx_notifications_user_id"`
+	Type      string     `json:"type" gorm:"type:varchar(100);not null"`
+	Payload   string     `json:"-" gorm:"type:text;not null"`
+	ReadAt    *time.Time `json:"read_at" gorm:"index:idx_notifications_user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationPayload is the content of an inbox notification
+type NotificationPayload struct {
+	Subject string                 `json:"subject"`
+	Message string                 `json:"message"`
+	URL     string                 `json:"url,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// NotificationResponse represents an inbox notification in API responses
+type NotificationResponse struct {
+	ID        uint                `json:"id"`
+	Type      string              `json:"type"`
+	Payload   NotificationPayload `json:"payload"`
+	Read      bool                `json:"read"`
+	ReadAt    *Timestamp          `json:"read_at"`
+	CreatedAt Timestamp           `json:"created_at"`
+}
+
+// NotificationPreference turns one channel on or off for one notification
+// type of a user. Without a preference the type's default channels apply.
+type NotificationPreference struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;uniqueIndex:idx_notification_preferences_user_type_channel"`
+	Type      string    `json:"type" gorm:"type:varchar(100);not null;uniqueIndex:idx_notification_preferences_user_type_channel"`
+	Channel   string    `json:"channel" gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_preferences_user_type_channel"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPreferencesRequest represents the request payload for updating
+// notification preferences; types and channels left out are unchanged
+type NotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceUpdate `json:"preferences" validate:"required,dive"`
+}
+
+// NotificationPreferenceUpdate turns one channel of a notification type on or off
+type NotificationPreferenceUpdate struct {
+	Type    string `json:"type" validate:"required"`
+	Channel string `json:"channel" validate:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+// NotificationPreferenceResponse represents whether a channel is enabled for
+// a notification type, whether set by the user or by default
+type NotificationPreferenceResponse struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+	Default bool   `json:"default"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PushSubscription is a browser push subscription of a user, as created by
+// the browser's PushManager.subscribe(). A browser's endpoint is unique, so
+// subscribing again replaces its keys and owner.
+type PushSubscription struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;index:idx_push_subscriptions_user_id"`
+	Endpoint  string    `json:"endpoint" gorm:"type:varchar(700);not null;uniqueIndex:idx_push_subscriptions_endpoint"`
+	P256dh    string    `json:"-" gorm:"type:varchar(255);not null"`
+	Auth      string    `json:"-" gorm:"type:varchar(255);not null"`
+	UserAgent string    `json:"user_agent" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MarshalJSON writes the subscription with its timestamps as Timestamps
+func (s PushSubscription) MarshalJSON() ([]byte, error) {
+	type plain PushSubscription
+	return json.Marshal(struct {
+		plain
+		CreatedAt Timestamp `json:"created_at"`
+		UpdatedAt Timestamp `json:"updated_at"`
+	}{plain(s), Timestamp(s.CreatedAt), Timestamp(s.UpdatedAt)})
+}
+
+// PushSubscriptionRequest represents the request payload for storing a push
+// subscription: the JSON of the browser's PushSubscription
+type PushSubscriptionRequest struct {
+	Endpoint string               `json:"endpoint" validate:"required,url,max=700"`
+	Keys     PushSubscriptionKeys `json:"keys" validate:"required"`
+}
+
+// PushSubscriptionKeys are the keys of a browser push subscription
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh" validate:"required,max=255"`
+	Auth   string `json:"auth" validate:"required,max=255"`
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Response timestamp formats
+const (
+	TimeFormatRFC3339     = "rfc3339"
+	TimeFormatRFC3339Nano = "rfc3339nano"
+	TimeFormatUnix        = "unix"
+)
+
+// timestampFormat is how Timestamps are written
+type timestampFormat struct {
+	format string
+	loc    *time.Location
+}
+
+// timestamps holds the active timestampFormat; the default keeps Go's
+// RFC 3339 output with fractional seconds, converted to UTC
+var timestamps atomic.Pointer[timestampFormat]
+
+func init() {
+	timestamps.Store(&timestampFormat{format: TimeFormatRFC3339Nano, loc: time.UTC})
+}
+
+// SetTimestampFormat sets how Timestamps are written: format is
+// TimeFormatRFC3339, TimeFormatRFC3339Nano or TimeFormatUnix, and zone the
+// IANA zone RFC 3339 timestamps are converted to
+func SetTimestampFormat(format, zone string) error {
+	switch format {
+	case TimeFormatRFC3339, TimeFormatRFC3339Nano, TimeFormatUnix:
+	default:
+		return fmt.Errorf("unsupported time format %q", format)
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return err
+	}
+
+	timestamps.Store(&timestampFormat{format: format, loc: loc})
+	return nil
+}
+
+// Timestamp is a time in API responses, written in the format set by
+// SetTimestampFormat whatever zone it was read in
+type Timestamp time.Time
+
+// MarshalJSON implements json.Marshaler
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	f := timestamps.Load()
+	switch f.format {
+	case TimeFormatUnix:
+		return strconv.AppendInt(nil, time.Time(t).Unix(), 10), nil
+	case TimeFormatRFC3339:
+		return json.Marshal(time.Time(t).In(f.loc).Format(time.RFC3339))
+	default:
+		return json.Marshal(time.Time(t).In(f.loc).Format(time.RFC3339Nano))
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting any of the formats
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if unix, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+		*t = Timestamp(time.Unix(unix, 0).UTC())
+		return nil
+	}
+	var parsed time.Time
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*t = Timestamp(parsed)
+	return nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestamp(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetTimestampFormat(TimeFormatRFC3339Nano, "UTC")) })
+
+	yangon := time.FixedZone("MMT", 6*3600+1800)
+	created := time.Date(2024, 3, 1, 16, 30, 0, 500_000_000, yangon)
+	user := UserResponse{ID: 1, Name: "Alice", CreatedAt: created}
+
+	write := func(v any) string {
+		encoded, err := json.Marshal(v)
+		require.NoError(t, err)
+		return string(encoded)
+	}
+
+	assert.Equal(t,
+		`{"id":1,"name":"Alice","email":"","role":"","created_at":"2024-03-01T10:00:00.5Z"}`,
+		write(user), "timestamps are converted to UTC")
+	assert.Equal(t, `{"id":0,"owner_id":0,"name":"","content_type":"","size":0,"created_at":"2024-03-01T10:00:00.5Z"}`,
+		write(FileResponse{CreatedAt: Timestamp(created)}))
+
+	require.NoError(t, SetTimestampFormat(TimeFormatRFC3339, "Asia/Yangon"))
+	assert.Contains(t, write(user), `"created_at":"2024-03-01T16:30:00+06:30"`)
+
+	require.NoError(t, SetTimestampFormat(TimeFormatUnix, "UTC"))
+	assert.Contains(t, write(user), `"created_at":1709287200`)
+
+	var decoded FileResponse
+	require.NoError(t, json.Unmarshal([]byte(write(FileResponse{CreatedAt: Timestamp(created)})), &decoded))
+	assert.True(t, created.Truncate(time.Second).Equal(time.Time(decoded.CreatedAt)), "every format reads back")
+
+	assert.Error(t, SetTimestampFormat("iso", "UTC"))
+	assert.Error(t, SetTimestampFormat(TimeFormatUnix, "Mars/Olympus"))
+}
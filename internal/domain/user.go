@@ -1,35 +1,116 @@
 package domain
 
 import (
+	"encoding/json"
+	"strconv"
 	"time"
 
+	"github.com/aungmyozaw92/go-api-setup/pkg/ids"
+	// Registers the "encrypted" serializer used by PII columns
+	_ "github.com/aungmyozaw92/go-api-setup/pkg/crypto"
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents the user entity
 type User struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
+	PublicID  string         `json:"-" gorm:"type:varchar(36);uniqueIndex"` // opaque ID exposed instead of ID, see USER_ID_FORMAT
 	Name      string         `json:"name" gorm:"type:varchar(255);not null"`
 	Email     string         `json:"email" gorm:"type:varchar(255);uniqueIndex;not null"`
 	Password  string         `json:"-" gorm:"type:varchar(255);not null"` // "-" excludes password from JSON responses
+	Role      string         `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
+	Phone     string         `json:"phone,omitempty" gorm:"type:text;serializer:encrypted"` // PII, encrypted at rest
+	Version   uint           `json:"version" gorm:"not null;default:1"` // optimistic locking, incremented by every update
+	EmailVerifiedAt *time.Time `json:"-"` // set once the user follows their verification link
+	VerificationReminders int `json:"-" gorm:"not null;default:0"` // reminders to verify the email address sent so far
+	VerificationRemindedAt *time.Time `json:"-"` // when the last of them was sent
+	PhoneVerifiedAt *time.Time `json:"-"` // set once the user enters the code texted to their phone
+	TwoFactorEnabled bool `json:"-" gorm:"not null;default:false"` // login also asks for a code texted to the verified phone
+	Locale    string         `json:"locale,omitempty" gorm:"type:varchar(35)"` // language of emails, such as "es"; empty for the Accept-Language default
+	Plan      string         `json:"plan" gorm:"type:varchar(50);not null;default:'free'"` // billing plan, kept in sync with the subscription by the Stripe webhook
+	DirectoryID *string `json:"-" gorm:"type:varchar(255);uniqueIndex"` // ID of the user in the external directory they are synced from; nil for local users
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// NewPublicID generates User.PublicID; main swaps it for the generator
+// matching USER_ID_FORMAT
+var NewPublicID = ids.NewUUIDv7
+
+// BeforeCreate starts every user at version 1 on the free plan and assigns
+// a public ID
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.Version == 0 {
+		u.Version = 1
+	}
+	if u.Plan == "" {
+		u.Plan = PlanFree
+	}
+	if u.PublicID == "" {
+		u.PublicID = NewPublicID()
+	}
+	return nil
+}
+
 // UserRequest represents the request payload for user registration
 type UserRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
+	Phone    string `json:"phone,omitempty"`
+	// Locale, when set, is the language of the user's emails, such as "es"
+	Locale string `json:"locale,omitempty"`
 }
 
 // UserResponse represents the response payload for user data
 type UserResponse struct {
-	ID        uint      `json:"id"`
+	ID uint `json:"id"`
+	// PublicID, when set, is rendered as "id" in place of ID
+	PublicID  string    `json:"-"`
 	Name      string    `json:"name"`
 	Email     string    `json:"email"`
+	Phone     string    `json:"phone,omitempty"`
+	Locale    string    `json:"locale,omitempty"`
+	Role      string    `json:"role"`
+	Plan      string    `json:"plan,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	// Version changes on every update; send it back in UpdateUserRequest to
+	// reject the update if someone else changed the user in the meantime
+	Version uint `json:"version,omitempty"`
+	// DeletedAt is only set when soft-deleted users are listed explicitly
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ExternalID returns the ID clients address the user by
+func (u UserResponse) ExternalID() string {
+	if u.PublicID != "" {
+		return u.PublicID
+	}
+	return strconv.FormatUint(uint64(u.ID), 10)
+}
+
+// MarshalJSON renders PublicID as "id" when it is set, keeping the
+// enumerable auto-increment key out of responses, and writes the timestamps
+// as Timestamps. They stay time.Time in the struct for GraphQL and gRPC.
+func (u UserResponse) MarshalJSON() ([]byte, error) {
+	type plain UserResponse
+	var id interface{} = u.ID
+	if u.PublicID != "" {
+		id = u.PublicID
+	}
+	return json.Marshal(struct {
+		ID interface{} `json:"id"`
+		plain
+		CreatedAt Timestamp  `json:"created_at"`
+		DeletedAt *Timestamp `json:"deleted_at,omitempty"`
+	}{ID: id, plain: plain(u), CreatedAt: Timestamp(u.CreatedAt), DeletedAt: (*Timestamp)(u.DeletedAt)})
 }
 
 // LoginRequest represents the login request payload
@@ -49,4 +130,8 @@ type UpdateUserRequest struct {
 	Name     string `json:"name,omitempty"`
 	Email    string `json:"email,omitempty" validate:"omitempty,email"`
 	Password string `json:"password,omitempty" validate:"omitempty,min=6"`
-} 
\ No newline at end of file
+	Phone    string `json:"phone,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	// Version, when set, must match the user's current version
+	Version uint `json:"version,omitempty"`
+}
\ No newline at end of file
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Purposes of the codes texted to users
+const (
+	VerificationPurposePhone = "phone_verification"
+	VerificationPurposeLogin = "login"
+)
+
+// VerificationCode is a one-time code texted to a user. Only a hash of the
+// code is stored; sending a new code for the same purpose replaces it.
+type VerificationCode struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index:idx_verification_codes_user_purpose"`
+	Purpose   string    `gorm:"type:varchar(50);not null;index:idx_verification_codes_user_purpose"`
+	CodeHash  string    `gorm:"type:varchar(255);not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// VerifyCodeRequest represents the request payload for entering a texted code
+type VerifyCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorLoginRequest represents the request payload completing a login
+// with the code texted for its challenge
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// TwoFactorRequest represents the request payload for turning two-factor
+// login on or off
+type TwoFactorRequest struct {
+	Enabled bool `json:"enabled"`
+}
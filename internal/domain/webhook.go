@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook event names
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+
+	EventNotificationCreated = "notification.created"
+
+	// EventWildcard subscribes to every event
+	EventWildcard = "*"
+)
+
+// WebhookEvents lists all events a subscription can register for
+var WebhookEvents = []string{EventUserCreated, EventUserUpdated, EventUserDeleted, EventNotificationCreated}
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookSubscription represents an external URL registered for events
+type WebhookSubscription struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	URL    string `json:"url" gorm:"type:varchar(2048);not null"`
+	Events string `json:"events" gorm:"type:varchar(1024);not null"` // comma-separated event names
+	Secret string `json:"-" gorm:"type:varchar(255);not null"`
+	Active bool   `json:"active" gorm:"not null"`
+
+	// Delivery policy; zero values use the webhook worker's defaults
+	MaxAttempts       int `json:"max_attempts" gorm:"not null;default:0"`
+	RetryDelaySeconds int `json:"retry_delay_seconds" gorm:"not null;default:0"`
+	TimeoutSeconds    int `json:"timeout_seconds" gorm:"not null;default:0"`
+
+	// ConsecutiveFailures counts failed delivery attempts since the last
+	// success; the webhook worker disables the subscription, setting
+	// DisabledAt, once it reaches the configured limit
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null;default:0"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+
+	CreatedBy uint           `json:"created_by" gorm:"index"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// WebhookDelivery represents an event queued for a subscription and its delivery attempts
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint       `json:"subscription_id" gorm:"index;not null"`
+	Event          string     `json:"event" gorm:"type:varchar(100);not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);index;not null"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error,omitempty" gorm:"type:text"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// MarshalJSON writes the delivery with its timestamps as Timestamps
+func (d WebhookDelivery) MarshalJSON() ([]byte, error) {
+	type plain WebhookDelivery
+	return json.Marshal(struct {
+		plain
+		NextAttemptAt Timestamp  `json:"next_attempt_at"`
+		DeliveredAt   *Timestamp `json:"delivered_at,omitempty"`
+		CreatedAt     Timestamp  `json:"created_at"`
+		UpdatedAt     Timestamp  `json:"updated_at"`
+	}{plain(d), Timestamp(d.NextAttemptAt), (*Timestamp)(d.DeliveredAt), Timestamp(d.CreatedAt), Timestamp(d.UpdatedAt)})
+}
+
+// WebhookSubscriptionRequest represents the request payload for creating or updating a subscription
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required"`
+	Active *bool    `json:"active,omitempty"`
+
+	MaxAttempts       *int `json:"max_attempts,omitempty"`
+	RetryDelaySeconds *int `json:"retry_delay_seconds,omitempty"`
+	TimeoutSeconds    *int `json:"timeout_seconds,omitempty"`
+}
+
+// WebhookSubscriptionResponse represents the response payload for a subscription
+type WebhookSubscriptionResponse struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"` // only returned on creation
+	CreatedAt Timestamp `json:"created_at"`
+
+	MaxAttempts         int        `json:"max_attempts"`
+	RetryDelaySeconds   int        `json:"retry_delay_seconds"`
+	TimeoutSeconds      int        `json:"timeout_seconds"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *Timestamp `json:"disabled_at,omitempty"`
+}
+
+// InboundWebhook is a webhook request another service sent to
+// /hooks/{provider}, queued for the handler of its event
+type InboundWebhook struct {
+	Provider string `json:"provider"`
+	Event    string `json:"event"`
+	// DeliveryID is the provider's unique ID of the delivery, when it sends
+	// one; providers retry deliveries, so handlers see an ID again
+	DeliveryID string          `json:"delivery_id,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
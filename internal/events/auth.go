@@ -0,0 +1,23 @@
+package events
+
+import "github.com/aungmyozaw92/go-api-setup/internal/domain"
+
+// Reasons a login failed
+const (
+	LoginUnknownEmail  = "unknown_email"
+	LoginWrongPassword = "wrong_password"
+	LoginWrongCode     = "wrong_code"
+)
+
+// LoginFailed is published when a login attempt is rejected
+type LoginFailed struct {
+	// Email is the address the attempt was for
+	Email string
+	// User is the account the attempt was for, nil when no user has Email
+	User *domain.User
+	// Reason is one of the Login* reasons
+	Reason string
+}
+
+// EventName returns "auth.login_failed" (implements Event)
+func (LoginFailed) EventName() string { return "auth.login_failed" }
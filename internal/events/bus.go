@@ -0,0 +1,127 @@
+// Package events is an in-process event bus. Usecases publish typed events,
+// such as UserCreated, once a change has succeeded; the features reacting to
+// them, such as the welcome email or webhook deliveries, subscribe when the
+// app is wired, so the usecase doesn't need to know about them.
+//
+// Subscribers run in the publisher's goroutine, in the order they
+// subscribed, unless they subscribe with SubscribeAsync. A subscriber that
+// fails or panics is logged and reported; it never fails the publisher or
+// keeps the other subscribers from running.
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+)
+
+// Event is something that happened, published on a Bus
+type Event interface {
+	// EventName names the event in logs, such as "user.created"
+	EventName() string
+}
+
+// Bus delivers published events to the subscribers of their type. Subscribe
+// before publishing; a Bus is safe for concurrent use. Publishing on a nil
+// Bus does nothing.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[reflect.Type][]subscriber
+	running     sync.WaitGroup
+}
+
+// subscriber is a handler registered for one event type
+type subscriber struct {
+	name   string
+	async  bool
+	handle func(ctx context.Context, event Event) error
+}
+
+// NewBus creates a bus without subscribers
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[reflect.Type][]subscriber)}
+}
+
+// Subscribe runs handler for every event of type E published on b, before
+// Publish returns. name identifies the subscriber in logs.
+func Subscribe[E Event](b *Bus, name string, handler func(ctx context.Context, event E) error) {
+	subscribe(b, name, false, handler)
+}
+
+// SubscribeAsync runs handler in a goroutine of its own for every event of
+// type E published on b, so slow work doesn't hold up the publisher. The
+// handler's context carries the publisher's values but is never canceled.
+func SubscribeAsync[E Event](b *Bus, name string, handler func(ctx context.Context, event E) error) {
+	subscribe(b, name, true, handler)
+}
+
+// subscribe registers handler for the events of type E
+func subscribe[E Event](b *Bus, name string, async bool, handler func(ctx context.Context, event E) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	eventType := reflect.TypeFor[E]()
+	b.subscribers[eventType] = append(b.subscribers[eventType], subscriber{
+		name:  name,
+		async: async,
+		handle: func(ctx context.Context, event Event) error {
+			return handler(ctx, event.(E))
+		},
+	})
+}
+
+// Publish delivers event to the subscribers of its type
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subscribers := b.subscribers[reflect.TypeOf(event)]
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		if !s.async {
+			b.run(ctx, s, event)
+			continue
+		}
+		b.running.Add(1)
+		go func() {
+			defer b.running.Done()
+			b.run(context.WithoutCancel(ctx), s, event)
+		}()
+	}
+}
+
+// Wait waits up to timeout for running async subscribers to finish, e.g. at
+// shutdown, and reports whether they did
+func (b *Bus) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		b.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// run calls one subscriber, logging and reporting its failure
+func (b *Bus) run(ctx context.Context, s subscriber, event Event) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logger.FromContext(ctx).Error("Event subscriber panicked", "event", event.EventName(), "subscriber", s.name, "panic", recovered)
+			reporting.ReportPanic(ctx, recovered)
+		}
+	}()
+	if err := s.handle(ctx, event); err != nil {
+		logger.FromContext(ctx).Error("Event subscriber failed", "event", event.EventName(), "subscriber", s.name, "error", err)
+		reporting.ReportError(ctx, fmt.Errorf("%s subscriber of %s: %w", s.name, event.EventName(), err))
+	}
+}
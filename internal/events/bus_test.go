@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := NewBus()
+	var calls []string
+	Subscribe(bus, "first", func(ctx context.Context, e UserCreated) error {
+		calls = append(calls, "first:"+e.User.Email)
+		return errors.New("smtp down")
+	})
+	Subscribe(bus, "panics", func(ctx context.Context, e UserCreated) error {
+		panic("boom")
+	})
+	Subscribe(bus, "second", func(ctx context.Context, e UserCreated) error {
+		calls = append(calls, "second:"+e.User.Email)
+		return nil
+	})
+	Subscribe(bus, "other", func(ctx context.Context, e UserDeleted) error {
+		calls = append(calls, "deleted")
+		return nil
+	})
+
+	bus.Publish(context.Background(), UserCreated{UserChange: UserChange{User: &domain.User{Email: "ada@example.com"}}})
+	assert.Equal(t, []string{"first:ada@example.com", "second:ada@example.com"}, calls,
+		"subscribers of the event's type run in order, even after one fails or panics")
+}
+
+func TestBus_SubscribeAsync(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	var done atomic.Bool
+	SubscribeAsync(bus, "slow", func(ctx context.Context, e UserUpdated) error {
+		<-release
+		assert.NoError(t, ctx.Err(), "the publisher's cancellation doesn't reach async subscribers")
+		done.Store(true)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.Publish(ctx, UserUpdated{})
+	cancel()
+	assert.False(t, bus.Wait(10*time.Millisecond), "Publish doesn't wait for async subscribers")
+
+	close(release)
+	require.True(t, bus.Wait(time.Second))
+	assert.True(t, done.Load())
+}
+
+func TestSubscribeUserEvents(t *testing.T) {
+	bus := NewBus()
+	var names []string
+	SubscribeUserEvents(bus, "webhooks", func(ctx context.Context, e UserEvent) error {
+		names = append(names, e.EventName()+":"+e.Change().Response.Email)
+		return nil
+	})
+
+	change := UserChange{Response: &domain.UserResponse{Email: "ada@example.com"}}
+	bus.Publish(context.Background(), UserCreated{UserChange: change, Registered: true})
+	bus.Publish(context.Background(), UserUpdated{UserChange: change})
+	bus.Publish(context.Background(), UserDeleted{UserChange: change})
+	assert.Equal(t, []string{"user.created:ada@example.com", "user.updated:ada@example.com", "user.deleted:ada@example.com"}, names)
+}
+
+func TestBus_Nil(t *testing.T) {
+	var bus *Bus
+	assert.NotPanics(t, func() { bus.Publish(context.Background(), UserCreated{}) })
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// UserChange is what the user events carry
+type UserChange struct {
+	// User is the stored user
+	User *domain.User
+	// Response is the user as API clients see it
+	Response *domain.UserResponse
+}
+
+// Change returns the change itself, so every user event satisfies
+// UserEvent
+func (c UserChange) Change() UserChange { return c }
+
+// UserEvent is any of the user lifecycle events
+type UserEvent interface {
+	Event
+	Change() UserChange
+}
+
+// UserCreated is published when a user account is created, by signing up
+// or by an admin
+type UserCreated struct {
+	UserChange
+	// Registered is true when the user signed up themselves
+	Registered bool
+}
+
+// EventName returns "user.created" (implements Event)
+func (UserCreated) EventName() string { return domain.EventUserCreated }
+
+// UserUpdated is published when a user's profile changes
+type UserUpdated struct {
+	UserChange
+	// PreviousEmail is the user's email address before the update, set
+	// only when the update changed it
+	PreviousEmail string
+}
+
+// EventName returns "user.updated" (implements Event)
+func (UserUpdated) EventName() string { return domain.EventUserUpdated }
+
+// UserDeleted is published when a user account is deleted
+type UserDeleted struct {
+	UserChange
+}
+
+// EventName returns "user.deleted" (implements Event)
+func (UserDeleted) EventName() string { return domain.EventUserDeleted }
+
+// SubscribeUserEvents runs handler for every user lifecycle event, for
+// subscribers that treat them alike, such as webhook deliveries
+func SubscribeUserEvents(b *Bus, name string, handler func(ctx context.Context, event UserEvent) error) {
+	Subscribe(b, name, func(ctx context.Context, e UserCreated) error { return handler(ctx, e) })
+	Subscribe(b, name, func(ctx context.Context, e UserUpdated) error { return handler(ctx, e) })
+	Subscribe(b, name, func(ctx context.Context, e UserDeleted) error { return handler(ctx, e) })
+}
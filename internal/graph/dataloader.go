@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// loadersKey is the context key for the per-request dataloaders
+type loadersKey struct{}
+
+// Loaders batches lookups made while resolving a single request
+type Loaders struct {
+	UserByID *dataloadgen.Loader[uint, *domain.UserResponse]
+}
+
+// NewLoaders creates a fresh set of dataloaders. Loaders cache results, so a
+// new set must be created for every request.
+func NewLoaders(userUsecase usecase.UserUsecase) *Loaders {
+	return &Loaders{
+		UserByID: dataloadgen.NewLoader(func(ctx context.Context, ids []uint) ([]*domain.UserResponse, []error) {
+			return fetchUsers(ctx, userUsecase, ids)
+		}, dataloadgen.WithWait(2*time.Millisecond)),
+	}
+}
+
+// LoaderMiddleware injects per-request dataloaders into the request context
+func LoaderMiddleware(userUsecase usecase.UserUsecase) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), loadersKey{}, NewLoaders(userUsecase))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// loadersFor returns the dataloaders for the current request
+func loadersFor(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+// fetchUsers loads users in a single batch, returning results in the order of ids.
+// Missing users resolve to nil without an error.
+func fetchUsers(ctx context.Context, userUsecase usecase.UserUsecase, ids []uint) ([]*domain.UserResponse, []error) {
+	users, err := userUsecase.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		errs := make([]error, len(ids))
+		for i := range errs {
+			errs[i] = err
+		}
+		return nil, errs
+	}
+
+	byID := make(map[uint]*domain.UserResponse, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	results := make([]*domain.UserResponse, len(ids))
+	for i, id := range ids {
+		results[i] = byID[id]
+	}
+	return results, nil
+}
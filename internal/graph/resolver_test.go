@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// withUser simulates the auth middleware by putting the user ID in the context
+func withUser(userID uint) client.Option {
+	return func(bd *client.Request) {
+		bd.HTTP = bd.HTTP.WithContext(context.WithValue(bd.HTTP.Context(), "user_id", userID))
+	}
+}
+
+func newTestClient(mockUsecase *mocks.MockUserUsecase) *client.Client {
+	return client.New(NewHandler(mockUsecase))
+}
+
+func TestQuery_UserLookupsAreBatched(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("ResolveUserID", mock.Anything, "1").Return(uint(1), nil)
+	mockUsecase.On("ResolveUserID", mock.Anything, "2").Return(uint(2), nil)
+	mockUsecase.On("GetUsersByIDs", mock.Anything, mock.MatchedBy(func(ids []uint) bool {
+		return assert.ElementsMatch(t, []uint{1, 2}, ids)
+	})).Return([]*domain.UserResponse{
+		{ID: 1, Name: "Alice", Email: "alice@example.com", Role: domain.RoleUser},
+		{ID: 2, Name: "Bob", Email: "bob@example.com", Role: domain.RoleAdmin},
+	}, nil).Once()
+
+	var resp struct {
+		A struct{ Name string }
+		B struct{ Name string }
+		C *struct{ Name string }
+	}
+	err := newTestClient(mockUsecase).Post(`{ a: user(id: 1) { name } b: user(id: 2) { name } c: user(id: 1) { name } }`, &resp, withUser(1))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", resp.A.Name)
+	assert.Equal(t, "Bob", resp.B.Name)
+	require.NotNil(t, resp.C)
+	assert.Equal(t, "Alice", resp.C.Name)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestQuery_UserByPublicID(t *testing.T) {
+	const publicID = "01J9ZQ4M8X6T2V3W5Y7A9B1C3D"
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("ResolveUserID", mock.Anything, publicID).Return(uint(7), nil)
+	mockUsecase.On("ResolveUserID", mock.Anything, "8").Return(uint(0), domain.ErrUserNotFound)
+	mockUsecase.On("GetUsersByIDs", mock.Anything, []uint{7}).Return([]*domain.UserResponse{
+		{ID: 7, PublicID: publicID, Name: "Alice", Email: "alice@example.com", Role: domain.RoleUser},
+	}, nil)
+
+	var resp struct {
+		Found   *struct{ ID, Name string }
+		Missing *struct{ ID string }
+	}
+	err := newTestClient(mockUsecase).Post(
+		`query($id: ID!) { found: user(id: $id) { id name } missing: user(id: "8") { id } }`,
+		&resp, client.Var("id", publicID), withUser(1))
+
+	require.NoError(t, err)
+	require.NotNil(t, resp.Found)
+	assert.Equal(t, publicID, resp.Found.ID, "the auto-increment ID isn't exposed")
+	assert.Nil(t, resp.Missing, "sequential IDs don't resolve once users have public IDs")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestMutation_LoginTwoFactor(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("Login", mock.Anything, mock.Anything).Return(nil, &domain.TwoFactorRequiredError{ChallengeToken: "challenge"})
+
+	resp, err := newTestClient(mockUsecase).RawPost(`mutation { login(input: {email: "john@example.com", password: "password123"}) { token } }`)
+
+	require.NoError(t, err)
+	var errs []gqlerror.Error
+	require.NoError(t, json.Unmarshal(resp.Errors, &errs))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "two-factor authentication required", errs[0].Message)
+	assert.Equal(t, "TWO_FACTOR_REQUIRED", errs[0].Extensions["code"])
+	assert.Equal(t, "challenge", errs[0].Extensions["challenge_token"], "the client can complete the login")
+}
+
+func TestQuery_RequiresAuthentication(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+
+	var resp map[string]interface{}
+	err := newTestClient(mockUsecase).Post(`{ me { id } }`, &resp)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Authentication required")
+	mockUsecase.AssertNotCalled(t, "GetUsersByIDs", mock.Anything, mock.Anything)
+}
+
+func TestMutation_Login(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	req := &domain.LoginRequest{Email: "john@example.com", Password: "wrongpassword"}
+	mockUsecase.On("Login", mock.Anything, req).Return(nil, domain.ErrInvalidCredentials)
+
+	var resp map[string]interface{}
+	err := newTestClient(mockUsecase).Post(
+		`mutation($input: LoginInput!) { login(input: $input) { token } }`,
+		&resp,
+		client.Var("input", map[string]interface{}{"email": req.Email, "password": req.Password}),
+	)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid email or password")
+	mockUsecase.AssertExpectations(t)
+}
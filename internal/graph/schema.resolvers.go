@@ -0,0 +1,202 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Register is the resolver for the register field.
+func (r *mutationResolver) Register(ctx context.Context, input domain.UserRequest) (*domain.UserResponse, error) {
+	if err := validateUserRequest(&input); err != nil {
+		return nil, err
+	}
+
+	user, err := r.UserUsecase.Register(ctx, &input)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to register user")
+	}
+	return user, nil
+}
+
+// Login is the resolver for the login field.
+func (r *mutationResolver) Login(ctx context.Context, input domain.LoginRequest) (*domain.LoginResponse, error) {
+	if input.Email == "" || input.Password == "" {
+		return nil, gqlerror.Errorf("Email and password are required")
+	}
+
+	loginResponse, err := r.UserUsecase.Login(ctx, &input)
+	if err != nil {
+		return nil, toGraphQLError(err, "Login failed")
+	}
+	return loginResponse, nil
+}
+
+// UpdateProfile is the resolver for the updateProfile field.
+func (r *mutationResolver) UpdateProfile(ctx context.Context, input domain.UpdateUserRequest) (*domain.UserResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateUpdateUserRequest(&input); err != nil {
+		return nil, err
+	}
+
+	user, err := r.UserUsecase.UpdateUser(ctx, userID, &input)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to update user")
+	}
+	return user, nil
+}
+
+// DeleteProfile is the resolver for the deleteProfile field.
+func (r *mutationResolver) DeleteProfile(ctx context.Context) (bool, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.UserUsecase.DeleteUser(ctx, userID); err != nil {
+		return false, toGraphQLError(err, "Failed to delete user")
+	}
+	return true, nil
+}
+
+// CreateUser is the resolver for the createUser field.
+func (r *mutationResolver) CreateUser(ctx context.Context, input domain.UserRequest) (*domain.UserResponse, error) {
+	if _, err := currentUserID(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateUserRequest(&input); err != nil {
+		return nil, err
+	}
+
+	user, err := r.UserUsecase.CreateUser(ctx, &input)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to create user")
+	}
+	return user, nil
+}
+
+// UpdateUser is the resolver for the updateUser field.
+func (r *mutationResolver) UpdateUser(ctx context.Context, id string, input domain.UpdateUserRequest) (*domain.UserResponse, error) {
+	if _, err := currentUserID(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateUpdateUserRequest(&input); err != nil {
+		return nil, err
+	}
+
+	userID, err := r.UserUsecase.ResolveUserID(ctx, id)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to update user")
+	}
+	user, err := r.UserUsecase.UpdateUser(ctx, userID, &input)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to update user")
+	}
+	return user, nil
+}
+
+// DeleteUser is the resolver for the deleteUser field.
+func (r *mutationResolver) DeleteUser(ctx context.Context, id string) (bool, error) {
+	if _, err := currentUserID(ctx); err != nil {
+		return false, err
+	}
+
+	userID, err := r.UserUsecase.ResolveUserID(ctx, id)
+	if err != nil {
+		return false, toGraphQLError(err, "Failed to delete user")
+	}
+	if err := r.UserUsecase.DeleteUser(ctx, userID); err != nil {
+		return false, toGraphQLError(err, "Failed to delete user")
+	}
+	return true, nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*domain.UserResponse, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := loadersFor(ctx).UserByID.Load(ctx, userID)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to get profile")
+	}
+	if user == nil {
+		return nil, gqlerror.Errorf("%s", domain.ErrUserNotFound)
+	}
+	return user, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id string) (*domain.UserResponse, error) {
+	if _, err := currentUserID(ctx); err != nil {
+		return nil, err
+	}
+
+	userID, err := r.UserUsecase.ResolveUserID(ctx, id)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to get user")
+	}
+	user, err := loadersFor(ctx).UserByID.Load(ctx, userID)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to get user")
+	}
+	return user, nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, limit *int, offset *int) ([]*domain.UserResponse, error) {
+	if _, err := currentUserID(ctx); err != nil {
+		return nil, err
+	}
+
+	l, o := 10, 0
+	if limit != nil && *limit > 0 {
+		l = *limit
+	}
+	if offset != nil && *offset >= 0 {
+		o = *offset
+	}
+
+	users, err := r.UserUsecase.GetAllUsers(ctx, l, o)
+	if err != nil {
+		return nil, toGraphQLError(err, "Failed to get users")
+	}
+	return users, nil
+}
+
+// UserCreated is the resolver for the userCreated field.
+func (r *subscriptionResolver) UserCreated(ctx context.Context) (<-chan *domain.UserResponse, error) {
+	return r.subscribe(ctx, domain.EventUserCreated)
+}
+
+// UserUpdated is the resolver for the userUpdated field.
+func (r *subscriptionResolver) UserUpdated(ctx context.Context) (<-chan *domain.UserResponse, error) {
+	return r.subscribe(ctx, domain.EventUserUpdated)
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/gorilla/websocket"
+)
+
+// websocketKeepAlive is how often idle subscription connections are pinged,
+// so proxies don't close them
+const websocketKeepAlive = 10 * time.Second
+
+// HandlerOption configures optional features of the GraphQL handler
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	feed      *UserFeed
+	jwtSecret string
+}
+
+// WithSubscriptions serves subscriptions from feed over WebSocket. Browsers
+// can't set headers on the handshake, so the connection_init payload may
+// carry the token signed with jwtSecret instead.
+func WithSubscriptions(feed *UserFeed, jwtSecret string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.feed = feed
+		o.jwtSecret = jwtSecret
+	}
+}
+
+// NewHandler creates the GraphQL HTTP handler with per-request dataloaders
+func NewHandler(userUsecase usecase.UserUsecase, opts ...HandlerOption) http.Handler {
+	var options handlerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	srv := handler.New(NewExecutableSchema(Config{
+		Resolvers: &Resolver{UserUsecase: userUsecase, UserFeed: options.feed},
+	}))
+
+	if options.feed != nil {
+		srv.AddTransport(transport.Websocket{
+			KeepAlivePingInterval: websocketKeepAlive,
+			Upgrader: websocket.Upgrader{
+				// Authentication uses an explicit token rather than cookies,
+				// so cross-origin connections are safe to accept
+				CheckOrigin: func(r *http.Request) bool { return true },
+			},
+			InitFunc: websocketAuth(options.jwtSecret),
+		})
+	}
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+
+	return LoaderMiddleware(userUsecase)(srv)
+}
+
+// websocketAuth authenticates a subscription connection with the token in
+// its connection_init payload, like OptionalAuthMiddleware does with the
+// Authorization header. Connections without one stay anonymous, unless the
+// handshake was authenticated, and the resolvers refuse to subscribe them.
+func websocketAuth(jwtSecret string) transport.WebsocketInitFunc {
+	return func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		token := strings.TrimPrefix(payload.Authorization(), "Bearer ")
+		if token == "" {
+			return ctx, nil, nil
+		}
+
+		claims, err := utils.ValidateJWT(token, jwtSecret)
+		if err != nil || claims.Tenant != database.TenantID(ctx) {
+			return nil, nil, errors.New("invalid token")
+		}
+
+		ctx = context.WithValue(ctx, "user_id", claims.UserID)
+		ctx = context.WithValue(ctx, "user_email", claims.Email)
+		ctx = context.WithValue(ctx, "user_role", claims.Role)
+		ctx = logger.With(ctx, "user_id", claims.UserID)
+		ctx = reporting.WithUser(ctx, strconv.FormatUint(uint64(claims.UserID), 10), claims.Email)
+		return ctx, nil, nil
+	}
+}
+
+// NewPlaygroundHandler serves the GraphQL playground UI for the given endpoint
+func NewPlaygroundHandler(endpoint string) http.Handler {
+	return playground.Handler("GraphQL Playground", endpoint)
+}
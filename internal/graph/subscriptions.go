@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+)
+
+// subscriptionBufferSize is how many users can wait to be sent to one
+// subscription before newer ones are dropped
+const subscriptionBufferSize = 16
+
+// UserFeed fans the user events published on the event bus out to the
+// GraphQL subscriptions. Subscriptions only see the users of their own
+// tenant, and one that falls behind misses users rather than holding up the
+// publisher.
+type UserFeed struct {
+	mu            sync.RWMutex
+	subscriptions map[*userSubscription]struct{}
+	closed        bool
+}
+
+// userSubscription is one client's subscription to a user event
+type userSubscription struct {
+	event  string
+	tenant string
+	users  chan *domain.UserResponse
+}
+
+// NewUserFeed creates a feed of the user events published on bus
+func NewUserFeed(bus *events.Bus) *UserFeed {
+	f := &UserFeed{subscriptions: make(map[*userSubscription]struct{})}
+	events.Subscribe(bus, "graphql_subscriptions", func(ctx context.Context, e events.UserCreated) error {
+		f.publish(ctx, e)
+		return nil
+	})
+	events.Subscribe(bus, "graphql_subscriptions", func(ctx context.Context, e events.UserUpdated) error {
+		f.publish(ctx, e)
+		return nil
+	})
+	return f
+}
+
+// Close ends every subscription and refuses new ones, e.g. at shutdown
+func (f *UserFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	for s := range f.subscriptions {
+		delete(f.subscriptions, s)
+		close(s.users)
+	}
+}
+
+// subscribe returns the users of the events named event, such as
+// "user.created", in the tenant of ctx. The channel is closed once ctx is
+// done or the feed is closed.
+func (f *UserFeed) subscribe(ctx context.Context, event string) <-chan *domain.UserResponse {
+	s := &userSubscription{
+		event:  event,
+		tenant: database.TenantID(ctx),
+		users:  make(chan *domain.UserResponse, subscriptionBufferSize),
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(s.users)
+		return s.users
+	}
+	f.subscriptions[s] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subscriptions[s]; ok {
+			delete(f.subscriptions, s)
+			close(s.users)
+		}
+	}()
+	return s.users
+}
+
+// publish sends the user of e to the subscriptions to its event
+func (f *UserFeed) publish(ctx context.Context, e events.UserEvent) {
+	tenant := database.TenantID(ctx)
+	user := e.Change().Response
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for s := range f.subscriptions {
+		if s.event != e.EventName() || s.tenant != tenant {
+			continue
+		}
+		select {
+		case s.users <- user:
+		default:
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase/mocks"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret"
+
+// subscribed waits until feed has n subscriptions
+func subscribed(t *testing.T, feed *UserFeed, n int) {
+	require.Eventually(t, func() bool {
+		feed.mu.RLock()
+		defer feed.mu.RUnlock()
+		return len(feed.subscriptions) == n
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSubscription_UserCreated(t *testing.T) {
+	bus := events.NewBus()
+	feed := NewUserFeed(bus)
+	c := client.New(NewHandler(new(mocks.MockUserUsecase), WithSubscriptions(feed, testSecret)))
+	token, err := utils.GenerateJWT(1, "john@example.com", testSecret)
+	require.NoError(t, err)
+
+	auth := map[string]any{"Authorization": "Bearer " + token}
+
+	created := c.WebsocketWithPayload(`subscription { userCreated { id name } }`, auth)
+	defer created.Close()
+	updated := c.WebsocketWithPayload(`subscription { userUpdated { id name } }`, auth)
+	defer updated.Close()
+	subscribed(t, feed, 2)
+
+	ctx := context.Background()
+	bus.Publish(ctx, events.UserUpdated{UserChange: events.UserChange{Response: &domain.UserResponse{ID: 7, Name: "Grace"}}})
+	bus.Publish(ctx, events.UserCreated{UserChange: events.UserChange{Response: &domain.UserResponse{ID: 8, Name: "Ada"}}})
+
+	var resp struct {
+		UserCreated struct {
+			ID   string
+			Name string
+		}
+		UserUpdated struct {
+			ID   string
+			Name string
+		}
+	}
+	require.NoError(t, created.Next(&resp))
+	assert.Equal(t, "8", resp.UserCreated.ID)
+	assert.Equal(t, "Ada", resp.UserCreated.Name)
+	require.NoError(t, updated.Next(&resp))
+	assert.Equal(t, "Grace", resp.UserUpdated.Name)
+
+	require.NoError(t, created.Close())
+	subscribed(t, feed, 1)
+}
+
+func TestSubscription_RequiresAuthentication(t *testing.T) {
+	feed := NewUserFeed(events.NewBus())
+	c := client.New(NewHandler(new(mocks.MockUserUsecase), WithSubscriptions(feed, testSecret)))
+
+	var resp map[string]any
+	anonymous := c.Websocket(`subscription { userCreated { id } }`)
+	defer anonymous.Close()
+	err := anonymous.Next(&resp)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Authentication required")
+
+	invalid := c.WebsocketWithPayload(`subscription { userCreated { id } }`, map[string]any{"Authorization": "Bearer nope"})
+	defer invalid.Close()
+	assert.Error(t, invalid.Next(&resp))
+	subscribed(t, feed, 0)
+}
+
+func TestUserFeed_Tenants(t *testing.T) {
+	bus := events.NewBus()
+	feed := NewUserFeed(bus)
+	ctx, cancel := context.WithCancel(context.Background())
+	users := feed.subscribe(ctx, domain.EventUserCreated)
+
+	// The shared database's subscription doesn't see a tenant's users
+	bus.Publish(database.WithTenant(context.Background(), "acme", nil), events.UserCreated{UserChange: events.UserChange{Response: &domain.UserResponse{ID: 1}}})
+	bus.Publish(context.Background(), events.UserCreated{UserChange: events.UserChange{Response: &domain.UserResponse{ID: 2}}})
+	assert.Equal(t, uint(2), (<-users).ID)
+
+	cancel()
+	_, open := <-users
+	assert.False(t, open, "the subscription ends with its context")
+
+	feed.Close()
+	_, open = <-feed.subscribe(context.Background(), domain.EventUserCreated)
+	assert.False(t, open, "a closed feed refuses subscriptions")
+}
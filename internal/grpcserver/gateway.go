@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	userv1 "github.com/aungmyozaw92/go-api-setup/api/gen/user/v1"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGateway creates an HTTP handler that translates the JSON API declared in
+// the proto HTTP annotations into calls against the gRPC server at grpcAddr.
+// The Authorization header is forwarded as metadata, so the gRPC interceptors
+// apply to both transports.
+func NewGateway(ctx context.Context, grpcAddr string) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithErrorHandler(errorHandler),
+	)
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if err := userv1.RegisterAuthServiceHandlerFromEndpoint(ctx, mux, grpcAddr, opts); err != nil {
+		return nil, err
+	}
+	if err := userv1.RegisterUserServiceHandlerFromEndpoint(ctx, mux, grpcAddr, opts); err != nil {
+		return nil, err
+	}
+
+	return mux, nil
+}
+
+// twoFactorBody is the error body of a login waiting for a texted code
+type twoFactorBody struct {
+	Error             string `json:"error"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+// errorHandler writes gRPC errors in the same {"error": "..."} shape as the
+// REST handlers, adding the challenge token of logins that need a texted code
+func errorHandler(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	st := status.Convert(err)
+	code := runtime.HTTPStatusFromCode(st.Code())
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == ReasonTwoFactorRequired {
+			response.JSON(w, twoFactorBody{
+				Error:             st.Message(),
+				TwoFactorRequired: true,
+				ChallengeToken:    info.Metadata["challenge_token"],
+			}, code)
+			return
+		}
+	}
+	response.JSON(w, response.ErrorBody{Error: st.Message()}, code)
+}
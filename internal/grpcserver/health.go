@@ -0,0 +1,130 @@
+package grpcserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/health"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWatchInterval is how often a Watch call reruns the checks
+const DefaultWatchInterval = 5 * time.Second
+
+// HealthServer implements the standard grpc.health.v1 service on top of the
+// readiness checks, so gRPC load balancers and probes see the same status as
+// /readyz. Every service of the server, and the server as a whole (the empty
+// service name), is serving when all checks pass.
+type HealthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	checks   *health.Registry
+	interval time.Duration
+
+	mu       sync.RWMutex
+	services map[string]bool
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// NewHealthServer creates a health service reporting the results of checks
+func NewHealthServer(checks *health.Registry) *HealthServer {
+	return &HealthServer{
+		checks:   checks,
+		interval: DefaultWatchInterval,
+		services: map[string]bool{"": true},
+		stopped:  make(chan struct{}),
+	}
+}
+
+// register adds the services of server, health included, to those reported
+func (h *HealthServer) register(server *grpc.Server) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for name := range server.GetServiceInfo() {
+		h.services[name] = true
+	}
+}
+
+// Shutdown reports every service as not serving and ends the Watch calls,
+// which would otherwise keep a graceful stop waiting. Call it before
+// GracefulStop.
+func (h *HealthServer) Shutdown() {
+	h.stopOnce.Do(func() { close(h.stopped) })
+}
+
+// Check runs the readiness checks (implements healthpb.HealthServer)
+func (h *HealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !h.known(req.GetService()) {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: h.status(ctx)}, nil
+}
+
+// List reports the status of every service (implements
+// healthpb.HealthServer)
+func (h *HealthServer) List(ctx context.Context, _ *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	serving := h.status(ctx)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	statuses := make(map[string]*healthpb.HealthCheckResponse, len(h.services))
+	for name := range h.services {
+		statuses[name] = &healthpb.HealthCheckResponse{Status: serving}
+	}
+	return &healthpb.HealthListResponse{Statuses: statuses}, nil
+}
+
+// Watch sends the status of a service, then again whenever it changes
+// (implements healthpb.HealthServer). Unknown services are reported as
+// SERVICE_UNKNOWN rather than failing the call, as the protocol requires.
+func (h *HealthServer) Watch(req *healthpb.HealthCheckRequest, stream grpc.ServerStreamingServer[healthpb.HealthCheckResponse]) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if h.known(req.GetService()) {
+			current = h.status(stream.Context())
+		}
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-ticker.C:
+		case <-h.stopped:
+			stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING})
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// known reports whether service is the server or one of its services
+func (h *HealthServer) known(service string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.services[service]
+}
+
+// status runs the checks; a server shutting down is not serving
+func (h *HealthServer) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	select {
+	case <-h.stopped:
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	default:
+	}
+	if !h.checks.Check(ctx).Ready() {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
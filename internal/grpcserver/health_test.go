@@ -0,0 +1,142 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	userv1 "github.com/aungmyozaw92/go-api-setup/api/gen/user/v1"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase/mocks"
+	"github.com/aungmyozaw92/go-api-setup/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newOptionsConn starts a server built with opts on an in-memory listener
+// and returns a client connection
+func newOptionsConn(t *testing.T, opts ...ServerOption) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	server := NewServer(new(mocks.MockUserUsecase), testSecret, opts...)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestHealthServer_Check(t *testing.T) {
+	var down atomic.Bool
+	checks := health.NewRegistry(time.Second)
+	checks.Register("database", func(context.Context) error {
+		if down.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	client := healthpb.NewHealthClient(newOptionsConn(t, WithHealth(NewHealthServer(checks))))
+	ctx := context.Background()
+
+	for _, service := range []string{"", userv1.UserService_ServiceDesc.ServiceName} {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err, "health checks need no token")
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	}
+
+	down.Store(true)
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	list, err := client.List(ctx, &healthpb.HealthListRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, list.Statuses, userv1.AuthService_ServiceDesc.ServiceName)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, list.Statuses[""].Status)
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "billing.v1.BillingService"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestHealthServer_Watch(t *testing.T) {
+	var down atomic.Bool
+	checks := health.NewRegistry(time.Second)
+	checks.Register("cache", func(context.Context) error {
+		if down.Load() {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+	healthServer := NewHealthServer(checks)
+	healthServer.interval = 10 * time.Millisecond
+	client := healthpb.NewHealthClient(newOptionsConn(t, WithHealth(healthServer)))
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	down.Store(true)
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	down.Store(false)
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	healthServer.Shutdown()
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err), "shutting down ends the watch")
+}
+
+func TestServer_Reflection(t *testing.T) {
+	listServices := func(conn *grpc.ClientConn) ([]string, error) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		defer stream.CloseSend()
+		if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}); err != nil {
+			return nil, err
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, service := range resp.GetListServicesResponse().GetService() {
+			names = append(names, service.Name)
+		}
+		return names, nil
+	}
+
+	names, err := listServices(newOptionsConn(t, WithReflection()))
+	require.NoError(t, err)
+	assert.Contains(t, names, userv1.UserService_ServiceDesc.ServiceName)
+
+	_, err = listServices(newOptionsConn(t))
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "reflection is off unless enabled")
+}
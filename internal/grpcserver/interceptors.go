@@ -0,0 +1,75 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// LoggingInterceptor logs every unary call with its status code and
+// duration. Health checks, which load balancers make every few seconds, are
+// logged at debug level.
+func LoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		level := slog.LevelInfo
+		if strings.HasPrefix(info.FullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+			level = slog.LevelDebug
+		}
+		logger.FromContext(ctx).Log(ctx, level, "gRPC request",
+			"method", info.FullMethod,
+			"code", status.Code(err).String(),
+			"duration", time.Since(start),
+		)
+		return resp, err
+	}
+}
+
+// AuthInterceptor validates the bearer token in the "authorization" metadata
+// and adds the user to the context, using the same keys as the HTTP
+// AuthMiddleware. Methods in publicMethods skip authentication.
+func AuthInterceptor(jwtSecret string, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok || len(md.Get("authorization")) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+		}
+
+		authHeader := md.Get("authorization")[0]
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization format")
+		}
+
+		// gRPC serves the shared database only, so tenant tokens are refused
+		claims, err := utils.ValidateJWT(strings.TrimPrefix(authHeader, "Bearer "), jwtSecret)
+		if err != nil || claims.Tenant != "" {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		ctx = context.WithValue(ctx, "user_id", claims.UserID)
+		ctx = context.WithValue(ctx, "user_email", claims.Email)
+		ctx = context.WithValue(ctx, "user_role", claims.Role)
+		ctx = logger.With(ctx, "user_id", claims.UserID)
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,66 @@
+package grpcserver
+
+import (
+	userv1 "github.com/aungmyozaw92/go-api-setup/api/gen/user/v1"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// ServerOption configures optional services of the gRPC server
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	health     *HealthServer
+	reflection bool
+}
+
+// WithHealth serves the grpc.health.v1 service, which needs no
+// authentication
+func WithHealth(health *HealthServer) ServerOption {
+	return func(o *serverOptions) {
+		o.health = health
+	}
+}
+
+// WithReflection serves the reflection service, so tools such as grpcurl
+// can list and call the services without their proto files. It describes
+// the whole API to anyone who can connect, so leave it off in production.
+func WithReflection() ServerOption {
+	return func(o *serverOptions) {
+		o.reflection = true
+	}
+}
+
+// NewServer creates a gRPC server exposing the user and auth services
+func NewServer(userUsecase usecase.UserUsecase, jwtSecret string, opts ...ServerOption) *grpc.Server {
+	var options serverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			LoggingInterceptor(),
+			AuthInterceptor(jwtSecret,
+				userv1.AuthService_Register_FullMethodName,
+				userv1.AuthService_Login_FullMethodName,
+				healthpb.Health_Check_FullMethodName,
+				healthpb.Health_List_FullMethodName,
+			),
+		),
+	)
+
+	userv1.RegisterAuthServiceServer(server, &authService{userUsecase: userUsecase})
+	userv1.RegisterUserServiceServer(server, &userService{userUsecase: userUsecase})
+	if options.health != nil {
+		healthpb.RegisterHealthServer(server, options.health)
+		options.health.register(server)
+	}
+	if options.reflection {
+		reflection.Register(server)
+	}
+
+	return server
+}
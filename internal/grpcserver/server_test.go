@@ -0,0 +1,221 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	userv1 "github.com/aungmyozaw92/go-api-setup/api/gen/user/v1"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase/mocks"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "test-secret"
+
+// newTestConn starts the server on an in-memory listener and returns a client connection
+func newTestConn(t *testing.T, mockUsecase *mocks.MockUserUsecase) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	server := NewServer(mockUsecase, testSecret)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestUserService_GetProfile(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockUsecase.On("GetProfile", mock.Anything, uint(1)).Return(&domain.UserResponse{
+		ID: 1, Name: "John Doe", Email: "john@example.com", Role: domain.RoleUser, CreatedAt: createdAt,
+	}, nil)
+
+	token, err := utils.GenerateJWT(1, "john@example.com", testSecret)
+	require.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+
+	resp, err := userv1.NewUserServiceClient(newTestConn(t, mockUsecase)).GetProfile(ctx, &userv1.GetProfileRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "1", resp.User.Id)
+	assert.Equal(t, "john@example.com", resp.User.Email)
+	assert.True(t, createdAt.Equal(resp.User.CreatedAt.AsTime()))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUserService_GetUserByPublicID(t *testing.T) {
+	const publicID = "01J9ZQ4M8X6T2V3W5Y7A9B1C3D"
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("ResolveUserID", mock.Anything, publicID).Return(uint(7), nil)
+	mockUsecase.On("ResolveUserID", mock.Anything, "8").Return(uint(0), domain.ErrUserNotFound)
+	mockUsecase.On("GetUserByID", mock.Anything, uint(7)).Return(&domain.UserResponse{
+		ID: 7, PublicID: publicID, Name: "John Doe", Email: "john@example.com", Role: domain.RoleUser,
+	}, nil)
+
+	token, err := utils.GenerateJWT(1, "admin@example.com", testSecret)
+	require.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	client := userv1.NewUserServiceClient(newTestConn(t, mockUsecase))
+
+	resp, err := client.GetUser(ctx, &userv1.GetUserRequest{Id: publicID})
+	require.NoError(t, err)
+	assert.Equal(t, publicID, resp.User.Id, "the auto-increment ID isn't exposed")
+
+	// Sequential IDs don't resolve once users have public IDs
+	_, err = client.GetUser(ctx, &userv1.GetUserRequest{Id: "8"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUserService_RequiresAuthentication(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+
+	_, err := userv1.NewUserServiceClient(newTestConn(t, mockUsecase)).GetUser(context.Background(), &userv1.GetUserRequest{Id: "1"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	mockUsecase.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_Login(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      *userv1.LoginRequest
+		setupMock    func(*mocks.MockUserUsecase)
+		expectedCode codes.Code
+	}{
+		{
+			name:    "successful login",
+			request: &userv1.LoginRequest{Email: "john@example.com", Password: "password123"},
+			setupMock: func(m *mocks.MockUserUsecase) {
+				m.On("Login", mock.Anything, &domain.LoginRequest{Email: "john@example.com", Password: "password123"}).
+					Return(&domain.LoginResponse{Token: "jwt-token", User: domain.UserResponse{ID: 1}}, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:    "invalid credentials",
+			request: &userv1.LoginRequest{Email: "john@example.com", Password: "wrongpassword"},
+			setupMock: func(m *mocks.MockUserUsecase) {
+				m.On("Login", mock.Anything, mock.Anything).Return(nil, domain.ErrInvalidCredentials)
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "missing fields",
+			request:      &userv1.LoginRequest{Email: "john@example.com"},
+			setupMock:    func(m *mocks.MockUserUsecase) {},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(mocks.MockUserUsecase)
+			tt.setupMock(mockUsecase)
+
+			resp, err := userv1.NewAuthServiceClient(newTestConn(t, mockUsecase)).Login(context.Background(), tt.request)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, "jwt-token", resp.Token)
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_LoginTwoFactor(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("Login", mock.Anything, mock.Anything).Return(nil, &domain.TwoFactorRequiredError{ChallengeToken: "challenge"})
+
+	_, err := userv1.NewAuthServiceClient(newTestConn(t, mockUsecase)).Login(context.Background(),
+		&userv1.LoginRequest{Email: "john@example.com", Password: "password123"})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, ReasonTwoFactorRequired, info.Reason)
+	assert.Equal(t, "challenge", info.Metadata["challenge_token"], "the client can complete the login")
+}
+
+func TestGateway_Login(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("Login", mock.Anything, &domain.LoginRequest{Email: "john@example.com", Password: "password123"}).
+		Return(&domain.LoginResponse{Token: "jwt-token", User: domain.UserResponse{ID: 1, Name: "John Doe"}}, nil)
+	mockUsecase.On("Login", mock.Anything, &domain.LoginRequest{Email: "sms@example.com", Password: "password123"}).
+		Return(nil, &domain.TwoFactorRequiredError{ChallengeToken: "challenge"})
+	mockUsecase.On("Login", mock.Anything, mock.Anything).Return(nil, domain.ErrInvalidCredentials)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewServer(mockUsecase, testSecret)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	gateway, err := NewGateway(ctx, lis.Addr().String())
+	require.NoError(t, err)
+
+	t.Run("success uses proto field names", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/auth/login", strings.NewReader(`{"email":"john@example.com","password":"password123"}`))
+		rr := httptest.NewRecorder()
+		gateway.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, "jwt-token", body["token"])
+		assert.Contains(t, body["user"], "created_at")
+	})
+
+	t.Run("errors match REST shape", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/auth/login", strings.NewReader(`{"email":"john@example.com","password":"wrong"}`))
+		rr := httptest.NewRecorder()
+		gateway.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.JSONEq(t, `{"error":"invalid email or password"}`, rr.Body.String())
+	})
+
+	t.Run("two-factor logins return the challenge token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/auth/login", strings.NewReader(`{"email":"sms@example.com","password":"password123"}`))
+		rr := httptest.NewRecorder()
+		gateway.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.JSONEq(t, `{"error":"two-factor authentication required","two_factor_required":true,"challenge_token":"challenge"}`, rr.Body.String())
+	})
+
+	t.Run("protected routes require token", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		gateway.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/users/1", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
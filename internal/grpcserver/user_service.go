@@ -0,0 +1,231 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	userv1 "github.com/aungmyozaw92/go-api-setup/api/gen/user/v1"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// authService implements userv1.AuthServiceServer on top of UserUsecase
+type authService struct {
+	userv1.UnimplementedAuthServiceServer
+	userUsecase usecase.UserUsecase
+}
+
+// Register handles user registration
+func (s *authService) Register(ctx context.Context, req *userv1.RegisterRequest) (*userv1.RegisterResponse, error) {
+	userReq := &domain.UserRequest{Name: req.GetName(), Email: req.GetEmail(), Password: req.GetPassword()}
+	if err := validateUserRequest(userReq); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userUsecase.Register(ctx, userReq)
+	if err != nil {
+		return nil, toStatusError(err, "failed to register user")
+	}
+	return &userv1.RegisterResponse{User: toProtoUser(user)}, nil
+}
+
+// Login handles user authentication
+func (s *authService) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.LoginResponse, error) {
+	if req.GetEmail() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and password are required")
+	}
+
+	loginResponse, err := s.userUsecase.Login(ctx, &domain.LoginRequest{Email: req.GetEmail(), Password: req.GetPassword()})
+	if err != nil {
+		return nil, toStatusError(err, "login failed")
+	}
+	return &userv1.LoginResponse{Token: loginResponse.Token, User: toProtoUser(&loginResponse.User)}, nil
+}
+
+// userService implements userv1.UserServiceServer on top of UserUsecase
+type userService struct {
+	userv1.UnimplementedUserServiceServer
+	userUsecase usecase.UserUsecase
+}
+
+// GetProfile returns the authenticated user's profile
+func (s *userService) GetProfile(ctx context.Context, _ *userv1.GetProfileRequest) (*userv1.GetProfileResponse, error) {
+	userID, ok := ctx.Value("user_id").(uint)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid user context")
+	}
+
+	user, err := s.userUsecase.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get profile")
+	}
+	return &userv1.GetProfileResponse{User: toProtoUser(user)}, nil
+}
+
+// GetUser returns a user by ID
+func (s *userService) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	userID, err := s.resolveUserID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userUsecase.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get user")
+	}
+	return &userv1.GetUserResponse{User: toProtoUser(user)}, nil
+}
+
+// ListUsers returns users with pagination
+func (s *userService) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 10 // default limit
+	}
+	offset := int(req.GetOffset())
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, err := s.userUsecase.GetAllUsers(ctx, limit, offset)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get users")
+	}
+
+	resp := &userv1.ListUsersResponse{Limit: int32(limit), Offset: int32(offset)}
+	for _, user := range users {
+		resp.Users = append(resp.Users, toProtoUser(user))
+	}
+	return resp, nil
+}
+
+// CreateUser creates a new user
+func (s *userService) CreateUser(ctx context.Context, req *userv1.CreateUserRequest) (*userv1.CreateUserResponse, error) {
+	userReq := &domain.UserRequest{Name: req.GetName(), Email: req.GetEmail(), Password: req.GetPassword()}
+	if err := validateUserRequest(userReq); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userUsecase.CreateUser(ctx, userReq)
+	if err != nil {
+		return nil, toStatusError(err, "failed to create user")
+	}
+	return &userv1.CreateUserResponse{User: toProtoUser(user)}, nil
+}
+
+// UpdateUser updates a user by ID
+func (s *userService) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.UpdateUserResponse, error) {
+	if req.GetPassword() != "" && len(req.GetPassword()) < 6 {
+		return nil, status.Error(codes.InvalidArgument, "password must be at least 6 characters")
+	}
+
+	userID, err := s.resolveUserID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userUsecase.UpdateUser(ctx, userID, &domain.UpdateUserRequest{
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, toStatusError(err, "failed to update user")
+	}
+	return &userv1.UpdateUserResponse{User: toProtoUser(user)}, nil
+}
+
+// DeleteUser deletes a user by ID
+func (s *userService) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*userv1.DeleteUserResponse, error) {
+	userID, err := s.resolveUserID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userUsecase.DeleteUser(ctx, userID); err != nil {
+		return nil, toStatusError(err, "failed to delete user")
+	}
+	return &userv1.DeleteUserResponse{}, nil
+}
+
+// resolveUserID maps the ID a client addressed a user by, public or numeric
+// like the REST API, to the internal user ID
+func (s *userService) resolveUserID(ctx context.Context, id string) (uint, error) {
+	userID, err := s.userUsecase.ResolveUserID(ctx, id)
+	if err != nil {
+		return 0, toStatusError(err, "failed to get user")
+	}
+	return userID, nil
+}
+
+// validateUserRequest applies the same basic validation as the REST handlers
+func validateUserRequest(req *domain.UserRequest) error {
+	if req.Name == "" || req.Email == "" || req.Password == "" {
+		return status.Error(codes.InvalidArgument, "name, email, and password are required")
+	}
+	if len(req.Password) < 6 {
+		return status.Error(codes.InvalidArgument, "password must be at least 6 characters")
+	}
+	return nil
+}
+
+// ReasonTwoFactorRequired is the ErrorInfo reason of a login that needs the
+// code texted to the user; the detail's "challenge_token" metadata completes
+// it over REST at /api/auth/login/two-factor
+const ReasonTwoFactorRequired = "TWO_FACTOR_REQUIRED"
+
+// kindCode maps the kinds of domain errors to the gRPC codes they are
+// reported with
+var kindCode = map[error]codes.Code{
+	domain.ErrNotFound:     codes.NotFound,
+	domain.ErrConflict:     codes.AlreadyExists,
+	domain.ErrInvalid:      codes.InvalidArgument,
+	domain.ErrUnauthorized: codes.Unauthenticated,
+	domain.ErrForbidden:    codes.PermissionDenied,
+	domain.ErrRateLimited:  codes.ResourceExhausted,
+	domain.ErrUnavailable:  codes.Unimplemented,
+}
+
+// toStatusError maps usecase errors to gRPC status codes, hiding internal details
+func toStatusError(err error, fallback string) error {
+	// Stale writes are retried after a read, which Aborted tells clients
+	var conflict *domain.VersionConflictError
+	if errors.As(err, &conflict) {
+		return status.Error(codes.Aborted, conflict.Error())
+	}
+	var twoFactor *domain.TwoFactorRequiredError
+	if errors.As(err, &twoFactor) {
+		return twoFactorStatus(twoFactor)
+	}
+	if code, ok := kindCode[domain.KindOf(err)]; ok {
+		return status.Error(code, err.Error())
+	}
+	return status.Error(codes.Internal, fallback)
+}
+
+// twoFactorStatus reports a login waiting for a texted code as
+// Unauthenticated, with the challenge token in an ErrorInfo detail
+func twoFactorStatus(err *domain.TwoFactorRequiredError) error {
+	st, detailErr := status.New(codes.Unauthenticated, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason:   ReasonTwoFactorRequired,
+		Domain:   "user.v1",
+		Metadata: map[string]string{"challenge_token": err.ChallengeToken},
+	})
+	if detailErr != nil {
+		return status.Error(codes.Internal, "login failed")
+	}
+	return st.Err()
+}
+
+// toProtoUser converts a user response to its protobuf representation,
+// identified by its public ID
+func toProtoUser(user *domain.UserResponse) *userv1.User {
+	return &userv1.User{
+		Id:        user.ExternalID(),
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}
+}
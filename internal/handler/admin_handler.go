@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/middleware"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/internal/worker"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler handles operational admin requests
+type AdminHandler struct {
+	maintenance *middleware.Maintenance
+	cacheStats  func() cache.Stats
+	workers     WorkerController
+	config      map[string]any
+	directory   DirectorySyncer
+}
+
+// WorkerController reports on and triggers background workers; implemented
+// by worker.Manager
+type WorkerController interface {
+	Statuses() []worker.Status
+	RunNow(name string) error
+}
+
+// DirectorySyncer syncs users from an external directory; implemented by
+// usecase.DirectorySync
+type DirectorySyncer interface {
+	Sync(ctx context.Context, dryRun bool) (*domain.DirectorySyncReport, error)
+	LastReport() *domain.DirectorySyncReport
+}
+
+// AdminHandlerOption configures optional dependencies of the admin handler
+type AdminHandlerOption func(*AdminHandler)
+
+// WithCacheStats reports the user cache's hit/miss counters
+func WithCacheStats(stats func() cache.Stats) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.cacheStats = stats
+	}
+}
+
+// WithWorkers reports on and triggers the workers of a worker manager
+func WithWorkers(workers WorkerController) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.workers = workers
+	}
+}
+
+// WithConfig serves the effective configuration, which must already have
+// its secrets masked (see config.Config.Redacted)
+func WithConfig(redacted map[string]any) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.config = redacted
+	}
+}
+
+// WithDirectorySync reports on and triggers the directory sync
+func WithDirectorySync(directory DirectorySyncer) AdminHandlerOption {
+	return func(h *AdminHandler) {
+		h.directory = directory
+	}
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(maintenance *middleware.Maintenance, opts ...AdminHandlerOption) *AdminHandler {
+	h := &AdminHandler{
+		maintenance: maintenance,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// MaintenanceRequest represents the request payload for toggling maintenance mode
+type MaintenanceRequest struct {
+	Enabled           *bool  `json:"enabled"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+	Message           string `json:"message,omitempty"`
+}
+
+// GetMaintenance returns the current maintenance mode state
+func (h *AdminHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message":     "Maintenance status retrieved successfully",
+		"maintenance": h.maintenance.Status(),
+	}, http.StatusOK)
+}
+
+// SetMaintenance turns maintenance mode on or off
+func (h *AdminHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Enabled == nil {
+		response.Error(w, r, "Enabled is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.RetryAfterSeconds < 0 {
+		response.Error(w, r, "Retry after must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	h.maintenance.Set(*req.Enabled, time.Duration(req.RetryAfterSeconds)*time.Second, req.Message)
+
+	response.JSON(w, map[string]interface{}{
+		"message":     "Maintenance status updated successfully",
+		"maintenance": h.maintenance.Status(),
+	}, http.StatusOK)
+}
+
+// GetCacheStats returns the user cache's hit/miss counters
+func (h *AdminHandler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.cacheStats == nil {
+		response.Error(w, r, "Caching is disabled", http.StatusNotFound)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Cache statistics retrieved successfully",
+		"cache":   h.cacheStats(),
+	}, http.StatusOK)
+}
+
+// GetConfig returns the configuration the process loaded, with secrets masked
+func (h *AdminHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.config == nil {
+		response.Error(w, r, "Configuration dump is not enabled", http.StatusNotFound)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Configuration retrieved successfully",
+		"config":  h.config,
+	}, http.StatusOK)
+}
+
+// WorkerRunResponse represents the runs a worker recorded under one name
+type WorkerRunResponse struct {
+	Name                string            `json:"name"`
+	Processed           uint64            `json:"processed"`
+	Errors              uint64            `json:"errors"`
+	ConsecutiveFailures int               `json:"consecutive_failures"`
+	LastRunAt           *domain.Timestamp `json:"last_run_at"`
+	LastDurationMs      int64             `json:"last_duration_ms"`
+	LastSuccessAt       *domain.Timestamp `json:"last_success_at"`
+	LastError           string            `json:"last_error,omitempty"`
+}
+
+// WorkerStatusResponse represents a registered worker in API responses
+type WorkerStatusResponse struct {
+	WorkerRunResponse
+	State string              `json:"state"`
+	Jobs  []WorkerRunResponse `json:"jobs,omitempty"`
+}
+
+// GetWorkers lists the registered workers with their state and runs
+func (h *AdminHandler) GetWorkers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.workers == nil {
+		response.Error(w, r, "Worker manager is not enabled", http.StatusNotFound)
+		return
+	}
+
+	statuses := h.workers.Statuses()
+	workers := make([]WorkerStatusResponse, 0, len(statuses))
+	for _, status := range statuses {
+		resp := WorkerStatusResponse{
+			WorkerRunResponse: toWorkerRunResponse(status.Stats),
+			State:             status.State,
+		}
+		for _, job := range status.Jobs {
+			resp.Jobs = append(resp.Jobs, toWorkerRunResponse(job))
+		}
+		workers = append(workers, resp)
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Workers retrieved successfully",
+		"workers": workers,
+	}, http.StatusOK)
+}
+
+// RunWorker triggers an immediate run of a worker or scheduled job
+func (h *AdminHandler) RunWorker(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.workers == nil {
+		response.Error(w, r, "Worker manager is not enabled", http.StatusNotFound)
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if name == "" {
+		response.Error(w, r, "Worker name is required", http.StatusBadRequest)
+		return
+	}
+
+	switch err := h.workers.RunNow(name); {
+	case errors.Is(err, worker.ErrUnknownWorker):
+		response.Error(w, r, "Worker not found", http.StatusNotFound)
+	case errors.Is(err, worker.ErrNotTriggerable):
+		response.Error(w, r, "Worker does not support immediate runs", http.StatusBadRequest)
+	case errors.Is(err, worker.ErrNotRunning):
+		response.Error(w, r, "Worker is not running", http.StatusConflict)
+	case errors.Is(err, worker.ErrAlreadyRunning):
+		response.Error(w, r, "Worker is already running", http.StatusConflict)
+	case err != nil:
+		response.Error(w, r, internalErrorMessage("Failed to trigger worker", err), http.StatusInternalServerError)
+	default:
+		response.JSON(w, map[string]interface{}{
+			"message": "Worker run triggered",
+			"worker":  name,
+		}, http.StatusAccepted)
+	}
+}
+
+// GetDirectorySync returns the report of the last directory sync, null
+// before the first
+func (h *AdminHandler) GetDirectorySync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.directory == nil {
+		response.Error(w, r, "Directory sync is not configured", http.StatusNotFound)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Directory sync report retrieved successfully",
+		"report":  h.directory.LastReport(),
+	}, http.StatusOK)
+}
+
+// RunDirectorySync syncs users from the directory and returns the report;
+// with ?dry_run=true it only reports the changes a sync would make
+func (h *AdminHandler) RunDirectorySync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.directory == nil {
+		response.Error(w, r, "Directory sync is not configured", http.StatusNotFound)
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			response.Error(w, r, "Dry run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Finish the sync even if the client gives up waiting for it
+	report, err := h.directory.Sync(context.WithoutCancel(r.Context()), dryRun)
+	switch {
+	case errors.Is(err, usecase.ErrDirectorySyncRunning):
+		response.Error(w, r, "Directory sync is already running", http.StatusConflict)
+	case err != nil:
+		response.Error(w, r, internalErrorMessage("Failed to sync directory", err), http.StatusInternalServerError)
+	default:
+		response.JSON(w, map[string]interface{}{
+			"message": "Directory synced successfully",
+			"report":  report,
+		}, http.StatusOK)
+	}
+}
+
+// toWorkerRunResponse converts worker stats for API responses; times of runs
+// that never happened are null
+func toWorkerRunResponse(stats worker.Stats) WorkerRunResponse {
+	return WorkerRunResponse{
+		Name:                stats.Name,
+		Processed:           stats.Runs,
+		Errors:              stats.Errors,
+		ConsecutiveFailures: stats.ConsecutiveFailures,
+		LastRunAt:           optionalTime(stats.LastRun),
+		LastDurationMs:      stats.LastDuration.Milliseconds(),
+		LastSuccessAt:       optionalTime(stats.LastSuccess),
+		LastError:           stats.LastError,
+	}
+}
+
+func optionalTime(t time.Time) *domain.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return (*domain.Timestamp)(&t)
+}
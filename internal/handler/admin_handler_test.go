@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/middleware"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/internal/worker"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorkers is a WorkerController with fixed statuses
+type fakeWorkers struct {
+	statuses  []worker.Status
+	triggered []string
+}
+
+func (f *fakeWorkers) Statuses() []worker.Status { return f.statuses }
+
+func (f *fakeWorkers) RunNow(name string) error {
+	switch name {
+	case "Scheduler":
+		return worker.ErrNotTriggerable
+	case "EmailWorker":
+		f.triggered = append(f.triggered, name)
+		return nil
+	}
+	return worker.ErrUnknownWorker
+}
+
+func TestAdminHandler_GetWorkers(t *testing.T) {
+	lastRun := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	workers := &fakeWorkers{statuses: []worker.Status{{
+		Stats: worker.Stats{Name: "EmailWorker", Runs: 3, Errors: 1, LastRun: lastRun, LastDuration: 1500 * time.Millisecond, LastError: "smtp down"},
+		State: worker.StateRunning,
+	}}}
+	h := NewAdminHandler(middleware.NewMaintenance(false, 0, ""), WithWorkers(workers))
+
+	rr := httptest.NewRecorder()
+	h.GetWorkers(rr, httptest.NewRequest(http.MethodGet, "/api/admin/workers", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Workers []map[string]interface{} `json:"workers"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.Workers, 1)
+	got := body.Workers[0]
+	assert.Equal(t, "EmailWorker", got["name"])
+	assert.Equal(t, "running", got["state"])
+	assert.Equal(t, 3.0, got["processed"])
+	assert.Equal(t, 1500.0, got["last_duration_ms"])
+	assert.Equal(t, "2024-05-01T12:00:00Z", got["last_run_at"])
+	assert.Nil(t, got["last_success_at"])
+	assert.Equal(t, "smtp down", got["last_error"])
+}
+
+func TestAdminHandler_RunWorker(t *testing.T) {
+	workers := &fakeWorkers{}
+	h := NewAdminHandler(middleware.NewMaintenance(false, 0, ""), WithWorkers(workers))
+
+	run := func(name string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/workers/"+name+"/run", nil)
+		rr := httptest.NewRecorder()
+		h.RunWorker(rr, mux.SetURLVars(req, map[string]string{"name": name}))
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, run("EmailWorker"))
+	assert.Equal(t, []string{"EmailWorker"}, workers.triggered)
+	assert.Equal(t, http.StatusBadRequest, run("Scheduler"))
+	assert.Equal(t, http.StatusNotFound, run("missing"))
+
+	rr := httptest.NewRecorder()
+	NewAdminHandler(middleware.NewMaintenance(false, 0, "")).RunWorker(rr, httptest.NewRequest(http.MethodPost, "/api/admin/workers/EmailWorker/run", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestAdminHandler_GetConfig(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewAdminHandler(middleware.NewMaintenance(false, 0, "")).GetConfig(rr, httptest.NewRequest(http.MethodGet, "/api/admin/config", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	h := NewAdminHandler(middleware.NewMaintenance(false, 0, ""), WithConfig(map[string]any{
+		"jwt": map[string]any{"secret_key": "********"},
+	}))
+	rr = httptest.NewRecorder()
+	h.GetConfig(rr, httptest.NewRequest(http.MethodGet, "/api/admin/config", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Config map[string]map[string]string `json:"config"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "********", body.Config["jwt"]["secret_key"])
+}
+
+// fakeDirectorySync records the syncs it is asked to run
+type fakeDirectorySync struct {
+	last    *domain.DirectorySyncReport
+	running bool
+}
+
+func (f *fakeDirectorySync) Sync(_ context.Context, dryRun bool) (*domain.DirectorySyncReport, error) {
+	if f.running {
+		return nil, usecase.ErrDirectorySyncRunning
+	}
+	f.last = &domain.DirectorySyncReport{DryRun: dryRun, DirectoryUsers: 2}
+	f.last.Add(domain.DirectorySyncChange{Action: domain.DirectorySyncCreate, Email: "ada@example.com", DirectoryID: "u1"})
+	return f.last, nil
+}
+
+func (f *fakeDirectorySync) LastReport() *domain.DirectorySyncReport { return f.last }
+
+func TestAdminHandler_DirectorySync(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewAdminHandler(middleware.NewMaintenance(false, 0, "")).GetDirectorySync(rr, httptest.NewRequest(http.MethodGet, "/api/admin/directory/sync", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	directory := &fakeDirectorySync{}
+	h := NewAdminHandler(middleware.NewMaintenance(false, 0, ""), WithDirectorySync(directory))
+	rr = httptest.NewRecorder()
+	h.GetDirectorySync(rr, httptest.NewRequest(http.MethodGet, "/api/admin/directory/sync", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"report":null`)
+
+	rr = httptest.NewRecorder()
+	h.RunDirectorySync(rr, httptest.NewRequest(http.MethodPost, "/api/admin/directory/sync?dry_run=true", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var body struct {
+		Report domain.DirectorySyncReport `json:"report"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.True(t, body.Report.DryRun)
+	assert.Equal(t, 1, body.Report.Created)
+	assert.Equal(t, "ada@example.com", body.Report.Changes[0].Email)
+
+	rr = httptest.NewRecorder()
+	h.RunDirectorySync(rr, httptest.NewRequest(http.MethodPost, "/api/admin/directory/sync?dry_run=maybe", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	directory.running = true
+	rr = httptest.NewRecorder()
+	h.RunDirectorySync(rr, httptest.NewRequest(http.MethodPost, "/api/admin/directory/sync", nil))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
 	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
 )
 
@@ -23,94 +25,146 @@ func NewAuthHandler(userUsecase usecase.UserUsecase) *AuthHandler {
 // Register handles user registration
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req domain.UserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Basic validation
 	if req.Name == "" || req.Email == "" || req.Password == "" {
-		writeErrorResponse(w, "Name, email, and password are required", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Name, email, and password are required", http.StatusBadRequest)
 		return
 	}
 
 	if len(req.Password) < 6 {
-		writeErrorResponse(w, "Password must be at least 6 characters", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Password must be at least 6 characters", http.StatusBadRequest)
 		return
 	}
 
 	user, err := h.userUsecase.Register(r.Context(), &req)
 	if err != nil {
-		if err.Error() == "user with this email already exists" {
-			writeErrorResponse(w, err.Error(), http.StatusConflict)
-			return
-		}
-		writeErrorResponse(w, "Failed to register user", http.StatusInternalServerError)
+		RespondError(w, r, err, "Failed to register user")
 		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "User registered successfully",
-		"user":    user,
-	}, http.StatusCreated)
+	writeUserResponse(w, r, "User registered successfully", user, http.StatusCreated)
 }
 
 // Login handles user authentication
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req domain.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Basic validation
 	if req.Email == "" || req.Password == "" {
-		writeErrorResponse(w, "Email and password are required", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Email and password are required", http.StatusBadRequest)
 		return
 	}
 
 	loginResponse, err := h.userUsecase.Login(r.Context(), &req)
 	if err != nil {
-		if err.Error() == "invalid email or password" {
-			writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
+		var twoFactor *domain.TwoFactorRequiredError
+		if errors.As(err, &twoFactor) {
+			// The password was right; the login completes at
+			// /api/auth/login/two-factor with the texted code
+			response.JSON(w, map[string]interface{}{
+				"message":             "Enter the code texted to your phone",
+				"two_factor_required": true,
+				"challenge_token":     twoFactor.ChallengeToken,
+			}, http.StatusOK)
 			return
 		}
-		writeErrorResponse(w, "Login failed", http.StatusInternalServerError)
+		RespondError(w, r, err, "Login failed")
 		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
+	writeLoginResponse(w, r, loginResponse)
+}
+
+// CompleteTwoFactorLogin finishes a login that required a texted code, given
+// the challenge token Login returned and the code
+func (h *AuthHandler) CompleteTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req domain.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ChallengeToken == "" || req.Code == "" {
+		writeNegotiatedError(w, r, "Challenge token and code are required", http.StatusBadRequest)
+		return
+	}
+
+	loginResponse, err := h.userUsecase.CompleteTwoFactorLogin(r.Context(), &req)
+	if err != nil {
+		RespondError(w, r, err, "Login failed")
+		return
+	}
+
+	writeLoginResponse(w, r, loginResponse)
+}
+
+// writeLoginResponse writes the token and user of a successful login
+func writeLoginResponse(w http.ResponseWriter, r *http.Request, loginResponse *domain.LoginResponse) {
+	if wantsJSONAPI(r) {
+		writeJSONAPIDocument(w, jsonAPIDocument{
+			Data: userResource(&loginResponse.User),
+			Meta: map[string]interface{}{
+				"message": "Login successful",
+				"token":   loginResponse.Token,
+			},
+		}, http.StatusOK)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
 		"message": "Login successful",
 		"token":   loginResponse.Token,
 		"user":    loginResponse.User,
 	}, http.StatusOK)
 }
 
-// writeErrorResponse writes an error response in JSON format
-func writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	
-	response := map[string]string{
-		"error": message,
+// VerifyEmail confirms a user's email address with the token of their
+// verification link: GET with a token query parameter, as the link in the
+// email does, or POST with {"token": ...} from a frontend
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if r.Method == http.MethodPost {
+		var req struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		token = req.Token
+	}
+	if token == "" {
+		writeNegotiatedError(w, r, "Token is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userUsecase.VerifyEmail(r.Context(), token); err != nil {
+		RespondError(w, r, err, "Failed to verify email")
+		return
 	}
-	
-	json.NewEncoder(w).Encode(response)
-}
 
-// writeSuccessResponse writes a success response in JSON format
-func writeSuccessResponse(w http.ResponseWriter, data interface{}, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(data)
-} 
\ No newline at end of file
+	response.Message(w, "Email verified", http.StatusOK)
+}
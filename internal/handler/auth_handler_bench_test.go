@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/middleware"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+)
+
+// benchSecret signs the benchmarks' tokens
+const benchSecret = "benchmark-secret-key"
+
+// benchUser signs up a user in memory and returns the handler for the
+// usecase it was created with, and a token of theirs. Run the benchmarks
+// with: go test -run='^$' -bench=. -benchmem ./internal/handler
+//
+// Baselines are recorded in TESTING.md.
+func benchUser(b *testing.B) (*AuthHandler, usecase.UserUsecase, string) {
+	b.Helper()
+	userUsecase := usecase.NewUserUsecase(memory.NewRepositories().Users, benchSecret)
+	ctx := context.Background()
+	if _, err := userUsecase.Register(ctx, &domain.UserRequest{Name: "Ada", Email: "ada@example.com", Password: "secret123"}); err != nil {
+		b.Fatal(err)
+	}
+	login, err := userUsecase.Login(ctx, &domain.LoginRequest{Email: "ada@example.com", Password: "secret123"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return NewAuthHandler(userUsecase), userUsecase, login.Token
+}
+
+// BenchmarkLogin measures a whole login: decoding and validating the
+// request, the bcrypt check, signing the token and writing the response
+func BenchmarkLogin(b *testing.B) {
+	handler, _, _ := benchUser(b)
+	body, _ := json.Marshal(domain.LoginRequest{Email: "ada@example.com", Password: "secret123"})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler.Login(rr, req)
+		if rr.Code != http.StatusOK {
+			b.Fatalf("status %d: %s", rr.Code, rr.Body)
+		}
+	}
+}
+
+// BenchmarkAuthenticatedRequest measures the cost authentication adds to
+// every request: the auth middleware validating the token in front of a
+// profile lookup
+func BenchmarkAuthenticatedRequest(b *testing.B) {
+	_, userUsecase, token := benchUser(b)
+	handler := middleware.AuthMiddleware(benchSecret)(http.HandlerFunc(NewUserHandler(userUsecase).GetProfile))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			b.Fatalf("status %d: %s", rr.Code, rr.Body)
+		}
+	}
+}
@@ -3,14 +3,15 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/domain"
 	"github.com/aungmyozaw92/go-api-setup/internal/usecase/mocks"
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -133,7 +134,7 @@ func (suite *AuthHandlerTestSuite) TestRegister_UsecaseError() {
 	}
 
 	// Setup mock to return error
-	suite.mockUsecase.On("Register", mock.Anything, reqBody).Return(nil, errors.New("user with this email already exists"))
+	suite.mockUsecase.On("Register", mock.Anything, reqBody).Return(nil, domain.ErrEmailTaken)
 
 	// Create request
 	body, _ := json.Marshal(reqBody)
@@ -253,7 +254,7 @@ func (suite *AuthHandlerTestSuite) TestLogin_InvalidCredentials() {
 	}
 
 	// Setup mock to return error
-	suite.mockUsecase.On("Login", mock.Anything, reqBody).Return(nil, errors.New("invalid email or password"))
+	suite.mockUsecase.On("Login", mock.Anything, reqBody).Return(nil, domain.ErrInvalidCredentials)
 
 	// Create request
 	body, _ := json.Marshal(reqBody)
@@ -276,7 +277,104 @@ func (suite *AuthHandlerTestSuite) TestLogin_InvalidCredentials() {
 	assert.Equal(suite.T(), "invalid email or password", response["error"])
 }
 
+// Test VerifyEmail Handler
+func (suite *AuthHandlerTestSuite) TestVerifyEmail_Link() {
+	suite.mockUsecase.On("VerifyEmail", mock.Anything, "42.1700000000.sig").Return(nil)
 
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/verify-email?token=42.1700000000.sig", nil)
+	rr := httptest.NewRecorder()
+	suite.handler.VerifyEmail(rr, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	assert.Contains(suite.T(), rr.Body.String(), "Email verified")
+}
+
+func (suite *AuthHandlerTestSuite) TestVerifyEmail_InvalidToken() {
+	suite.mockUsecase.On("VerifyEmail", mock.Anything, "expired").Return(domain.ErrInvalidVerificationToken)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/verify-email", bytes.NewBufferString(`{"token":"expired"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	suite.handler.VerifyEmail(rr, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
+	assert.Contains(suite.T(), rr.Body.String(), domain.ErrInvalidVerificationToken.Error())
+
+	rr = httptest.NewRecorder()
+	suite.handler.VerifyEmail(rr, httptest.NewRequest(http.MethodGet, "/api/auth/verify-email", nil))
+	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
+	assert.Contains(suite.T(), rr.Body.String(), "Token is required")
+}
+
+// Test two-factor login
+func (suite *AuthHandlerTestSuite) TestLogin_TwoFactorRequired() {
+	loginReq := &domain.LoginRequest{Email: "alice@example.com", Password: "password123"}
+	suite.mockUsecase.On("Login", mock.Anything, loginReq).Return(nil, &domain.TwoFactorRequiredError{ChallengeToken: "1.1700000000.sig"})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", bytes.NewBufferString(`{"email":"alice@example.com","password":"password123"}`))
+	rr := httptest.NewRecorder()
+	suite.handler.Login(rr, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	var response map[string]interface{}
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), true, response["two_factor_required"])
+	assert.Equal(suite.T(), "1.1700000000.sig", response["challenge_token"])
+	assert.NotContains(suite.T(), response, "token")
+}
+
+func (suite *AuthHandlerTestSuite) TestCompleteTwoFactorLogin() {
+	twoFactorReq := &domain.TwoFactorLoginRequest{ChallengeToken: "1.1700000000.sig", Code: "123456"}
+	suite.mockUsecase.On("CompleteTwoFactorLogin", mock.Anything, twoFactorReq).Return(&domain.LoginResponse{
+		Token: "jwt-token",
+		User:  domain.UserResponse{ID: 1, Email: "alice@example.com"},
+	}, nil)
+	suite.mockUsecase.On("CompleteTwoFactorLogin", mock.Anything, &domain.TwoFactorLoginRequest{ChallengeToken: "1.1700000000.sig", Code: "000000"}).Return(nil, domain.ErrInvalidLoginCode)
+
+	rr := httptest.NewRecorder()
+	suite.handler.CompleteTwoFactorLogin(rr, httptest.NewRequest(http.MethodPost, "/api/auth/login/two-factor", bytes.NewBufferString(`{"challenge_token":"1.1700000000.sig","code":"123456"}`)))
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	assert.Contains(suite.T(), rr.Body.String(), "jwt-token")
+
+	rr = httptest.NewRecorder()
+	suite.handler.CompleteTwoFactorLogin(rr, httptest.NewRequest(http.MethodPost, "/api/auth/login/two-factor", bytes.NewBufferString(`{"challenge_token":"1.1700000000.sig","code":"000000"}`)))
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+
+	rr = httptest.NewRecorder()
+	suite.handler.CompleteTwoFactorLogin(rr, httptest.NewRequest(http.MethodPost, "/api/auth/login/two-factor", bytes.NewBufferString(`{"code":"123456"}`)))
+	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
+}
+
+
+func (suite *AuthHandlerTestSuite) TestRegister_TranslatedErrors() {
+	bundle := i18n.NewBundle()
+	suite.Require().NoError(bundle.LoadFS(fstest.MapFS{"es.yaml": {Data: []byte(`
+"user with this email already exists": "ya existe un usuario con este correo"
+"invalid locale": "idioma no válido"
+`)}}, "."))
+	reqBody := &domain.UserRequest{Name: "John Doe", Email: "john@example.com", Password: "password123", Locale: "!!"}
+	suite.mockUsecase.On("Register", mock.Anything, reqBody).Return(nil, domain.ErrInvalidLocale).Once()
+	suite.mockUsecase.On("Register", mock.Anything, mock.Anything).Return(nil, domain.ErrEmailTaken)
+
+	register := func(body *domain.UserRequest, accept string) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/register", bytes.NewBuffer(data))
+		req.Header.Set("Accept", accept)
+		localizer := bundle.Localizer("es-ES")
+		rr := httptest.NewRecorder()
+		suite.handler.Register(rr, req.WithContext(i18n.NewContext(req.Context(), localizer)))
+		return rr
+	}
+
+	rr := register(reqBody, "")
+	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
+	assert.JSONEq(suite.T(), `{"error":"idioma no válido"}`, rr.Body.String())
+
+	rr = register(&domain.UserRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"}, JSONAPIMediaType)
+	assert.Equal(suite.T(), http.StatusConflict, rr.Code)
+	assert.Contains(suite.T(), rr.Body.String(), `"detail":"ya existe un usuario con este correo"`)
+}
 
 // Run the test suite
 func TestAuthHandlerTestSuite(t *testing.T) {
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+)
+
+// BatchPath is where the batch endpoint is served; sub-requests can't
+// target it
+const BatchPath = "/api/batch"
+
+// maxBatchBodySize bounds the body of a batch request, sub-request bodies
+// included
+const maxBatchBodySize = 1 << 20
+
+// maxBatchResponseSize bounds the body of each sub-request's response, which
+// is held in memory until the whole batch has run
+const maxBatchResponseSize = 1 << 20
+
+// errBatchResponseTooLarge is returned to handlers writing more than
+// maxBatchResponseSize to a sub-request's response
+var errBatchResponseTooLarge = errors.New("response is too large for a batch")
+
+// batchMethods are the methods sub-requests may use
+var batchMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// batchHeaders are the headers of the batch request every sub-request
+// carries, so they share its authentication, tenant and language
+var batchHeaders = []string{"Authorization", "Accept-Language", "X-Forwarded-For", "X-Real-IP"}
+
+// hopByHopHeaders apply to a single connection and mean nothing on a
+// sub-request
+var hopByHopHeaders = []string{"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade"}
+
+// BatchRequest is one sub-request of a batch
+type BatchRequest struct {
+	// ID is echoed in the sub-request's response, for the client to match them
+	ID     string `json:"id,omitempty"`
+	Method string `json:"method"`
+	// Path is an /api path, query string included
+	Path string `json:"path"`
+	// Headers are set on the sub-request in addition to the batch's own,
+	// such as If-Match. They can't replace the batch's own headers.
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResponse is the response to one sub-request of a batch
+type BatchResponse struct {
+	ID      string            `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is the response's JSON, its text when it isn't JSON, or its
+	// bytes in base64 when it isn't text either
+	Body any `json:"body,omitempty"`
+	// BodyEncoding is "base64" when Body holds the response's bytes in
+	// base64
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// BatchHandler runs several API requests sent in one, saving mobile clients
+// round trips. Each sub-request goes through the router like any other
+// request, middleware and authentication included, with the headers of the
+// batch request.
+type BatchHandler struct {
+	router      http.Handler
+	maxRequests int
+	headers     []string
+}
+
+// NewBatchHandler creates a batch handler running up to maxRequests
+// sub-requests through router. extraHeaders are passed from the batch
+// request to its sub-requests too, e.g. the tenant header.
+func NewBatchHandler(router http.Handler, maxRequests int, extraHeaders ...string) *BatchHandler {
+	return &BatchHandler{
+		router:      router,
+		maxRequests: maxRequests,
+		headers:     append(slices.Clone(batchHeaders), extraHeaders...),
+	}
+}
+
+// Batch runs the sub-requests in order and returns their responses. The
+// batch succeeds as a whole even when sub-requests fail; each response
+// carries its own status.
+func (h *BatchHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var requests []BatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize)).Decode(&requests); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(requests) == 0 || len(requests) > h.maxRequests {
+		response.Error(w, r, fmt.Sprintf("A batch must have between 1 and %d requests", h.maxRequests), http.StatusBadRequest)
+		return
+	}
+	for i := range requests {
+		if err := h.validate(&requests[i]); err != nil {
+			response.Error(w, r, fmt.Sprintf("Request %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	responses := make([]BatchResponse, 0, len(requests))
+	for i := range requests {
+		if r.Context().Err() != nil {
+			// The client is gone or the deadline passed; the remaining
+			// requests aren't run
+			return
+		}
+		responses = append(responses, h.run(r, &requests[i]))
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"responses": responses,
+	}, http.StatusOK)
+}
+
+// validate checks a sub-request's method, path and headers, normalizing the
+// method
+func (h *BatchHandler) validate(req *BatchRequest) error {
+	req.Method = strings.ToUpper(req.Method)
+	if !slices.Contains(batchMethods, req.Method) {
+		return fmt.Errorf("method must be one of %v", batchMethods)
+	}
+	u, err := url.Parse(req.Path)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/api/") {
+		return errors.New("path must be an /api path")
+	}
+	if u.Path == BatchPath {
+		return errors.New("batches can't be nested")
+	}
+	for name := range req.Headers {
+		// The batch's headers carry its identity, client IP and tenant,
+		// which a sub-request must not swap for its own
+		if slices.ContainsFunc(h.headers, func(h string) bool { return strings.EqualFold(h, name) }) ||
+			slices.ContainsFunc(hopByHopHeaders, func(h string) bool { return strings.EqualFold(h, name) }) {
+			return fmt.Errorf("header %s can't be set on sub-requests", name)
+		}
+	}
+	return nil
+}
+
+// run sends a sub-request through the router and records its response
+func (h *BatchHandler) run(r *http.Request, req *BatchRequest) BatchResponse {
+	sub, err := http.NewRequestWithContext(r.Context(), req.Method, req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		return BatchResponse{ID: req.ID, Status: http.StatusBadRequest, Body: err.Error()}
+	}
+	sub.RemoteAddr = r.RemoteAddr
+	sub.Host = r.Host
+	for _, name := range h.headers {
+		if value := r.Header.Get(name); value != "" {
+			sub.Header.Set(name, value)
+		}
+	}
+	for name, value := range req.Headers {
+		sub.Header.Set(name, value)
+	}
+	if len(req.Body) > 0 && sub.Header.Get("Content-Type") == "" {
+		sub.Header.Set("Content-Type", "application/json")
+	}
+
+	rec := &batchResponseWriter{header: make(http.Header)}
+	h.router.ServeHTTP(rec, sub)
+
+	if rec.truncated {
+		// The response isn't returned cut short; the client has to send
+		// this request on its own
+		return BatchResponse{ID: req.ID, Status: http.StatusBadGateway, Body: fmt.Sprintf("%v: send it on its own", errBatchResponseTooLarge)}
+	}
+
+	resp := BatchResponse{ID: req.ID, Status: rec.statusCode()}
+	for name := range rec.header {
+		// CORS applies to the batch request, not its sub-requests
+		if strings.HasPrefix(name, "Access-Control-") || name == "Vary" {
+			continue
+		}
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]string, len(rec.header))
+		}
+		resp.Headers[name] = rec.header.Get(name)
+	}
+	if body := rec.body.Bytes(); len(body) > 0 {
+		switch {
+		case json.Valid(body):
+			resp.Body = json.RawMessage(body)
+		case isText(rec.header.Get("Content-Type"), body):
+			resp.Body = string(body)
+		default:
+			// Binary bodies such as downloads can't go in a JSON string
+			// unchanged
+			resp.Body = base64.StdEncoding.EncodeToString(body)
+			resp.BodyEncoding = "base64"
+		}
+	}
+	return resp
+}
+
+// isText reports whether a body of the given content type can be returned
+// as a JSON string. Bodies without a content type are text when they're
+// valid UTF-8.
+func isText(contentType string, body []byte) bool {
+	if contentType != "" && !strings.HasPrefix(contentType, "text/") {
+		return false
+	}
+	return utf8.Valid(body)
+}
+
+// batchResponseWriter records the response to a sub-request
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	// truncated is set once the handler writes more than
+	// maxBatchResponseSize
+	truncated bool
+}
+
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.truncated || w.body.Len()+len(b) > maxBatchResponseSize {
+		w.truncated = true
+		return 0, errBatchResponseTooLarge
+	}
+	return w.body.Write(b)
+}
+
+// statusCode returns the recorded status, 200 when none was written
+func (w *batchResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
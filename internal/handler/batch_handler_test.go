@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+)
+
+func TestBatchHandler(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/profile", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			response.Error(w, r, "Authorization header required", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("ETag", `"v3"`)
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		response.JSON(w, map[string]interface{}{"name": "Ada", "tenant": r.Header.Get("X-Tenant-ID")}, http.StatusOK)
+	}).Methods("GET")
+	router.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "1", r.URL.Query().Get("notify"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}).Methods("POST")
+	router.HandleFunc("/api/ping", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "pong")
+	}).Methods("GET")
+	avatar := []byte{0x89, 'P', 'N', 'G', 0xff, 0x00, 0xfe}
+	router.HandleFunc("/api/avatar", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(avatar)
+	}).Methods("GET")
+	router.HandleFunc("/api/export", func(w http.ResponseWriter, r *http.Request) {
+		chunk := []byte(strings.Repeat("a,b,c\n", 1024))
+		for written := 0; written <= maxBatchResponseSize; written += len(chunk) {
+			if _, err := w.Write(chunk); err != nil {
+				assert.ErrorIs(t, err, errBatchResponseTooLarge)
+				return
+			}
+		}
+		t.Error("writes past the limit should fail")
+	}).Methods("GET")
+	router.HandleFunc(BatchPath, NewBatchHandler(router, 3, "X-Tenant-ID").Batch).Methods("POST")
+
+	batch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("X-Tenant-ID", "acme")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := batch(`[
+		{"id": "me", "method": "get", "path": "/api/profile"},
+		{"id": "new", "method": "POST", "path": "/api/users?notify=1", "body": {"name": "Grace"}},
+		{"method": "GET", "path": "/api/ping"}
+	]`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Responses []struct {
+			ID      string
+			Status  int
+			Headers map[string]string
+			Body    json.RawMessage
+		}
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Responses, 3)
+
+	assert.Equal(t, "me", resp.Responses[0].ID)
+	assert.Equal(t, http.StatusOK, resp.Responses[0].Status)
+	assert.Equal(t, `"v3"`, resp.Responses[0].Headers["Etag"])
+	assert.NotContains(t, resp.Responses[0].Headers, "Access-Control-Allow-Origin")
+	assert.JSONEq(t, `{"name":"Ada","tenant":"acme"}`, string(resp.Responses[0].Body), "sub-requests share the batch's headers")
+	assert.Equal(t, http.StatusCreated, resp.Responses[1].Status)
+	assert.JSONEq(t, `{"name":"Grace"}`, string(resp.Responses[1].Body))
+	assert.JSONEq(t, `"pong"`, string(resp.Responses[2].Body), "text bodies are returned as strings")
+
+	rr = batch(`[
+		{"id": "avatar", "method": "GET", "path": "/api/avatar"},
+		{"id": "export", "method": "GET", "path": "/api/export"}
+	]`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var binary struct {
+		Responses []BatchResponse
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &binary))
+	require.Len(t, binary.Responses, 2)
+	assert.Equal(t, "base64", binary.Responses[0].BodyEncoding)
+	decoded, err := base64.StdEncoding.DecodeString(binary.Responses[0].Body.(string))
+	require.NoError(t, err)
+	assert.Equal(t, avatar, decoded, "binary bodies are returned intact")
+	assert.Equal(t, http.StatusBadGateway, binary.Responses[1].Status)
+	assert.Contains(t, binary.Responses[1].Body, "too large for a batch")
+	assert.Less(t, rr.Body.Len(), maxBatchResponseSize, "oversized responses aren't buffered")
+
+	for name, tc := range map[string]struct {
+		body string
+		want string
+	}{
+		"not an array":    {`{"method":"GET","path":"/api/ping"}`, "Invalid request body"},
+		"empty":           {`[]`, "between 1 and 3 requests"},
+		"too many":        {"[" + strings.Repeat(`{"method":"GET","path":"/api/ping"},`, 3) + `{"method":"GET","path":"/api/ping"}]`, "between 1 and 3 requests"},
+		"unknown method":  {`[{"method":"TRACE","path":"/api/ping"}]`, "Request 0: method must be one of"},
+		"outside the api": {`[{"method":"GET","path":"/health"}]`, "Request 0: path must be an /api path"},
+		"absolute url":    {`[{"method":"GET","path":"https://example.com/api/ping"}]`, "path must be an /api path"},
+		"nested":          {`[{"method":"GET","path":"/api/ping"},{"method":"POST","path":"/api/batch"}]`, "Request 1: batches can't be nested"},
+		"client ip":       {`[{"method":"GET","path":"/api/ping","headers":{"x-forwarded-for":"10.0.0.1"}}]`, "Request 0: header x-forwarded-for can't be set on sub-requests"},
+		"tenant":          {`[{"method":"GET","path":"/api/ping","headers":{"X-Tenant-ID":"other"}}]`, "header X-Tenant-ID can't be set"},
+		"authorization":   {`[{"method":"GET","path":"/api/ping","headers":{"Authorization":"Bearer other"}}]`, "header Authorization can't be set"},
+		"hop-by-hop":      {`[{"method":"GET","path":"/api/ping","headers":{"Connection":"close"}}]`, "header Connection can't be set"},
+	} {
+		rr := batch(tc.body)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, name)
+		assert.Contains(t, rr.Body.String(), tc.want, name)
+	}
+}
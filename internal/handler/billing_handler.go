@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+)
+
+// maxBillingWebhookSize bounds the payment provider's webhook request bodies
+const maxBillingWebhookSize = 64 << 10
+
+// BillingHandler handles subscription billing requests and the payment
+// provider's webhook
+type BillingHandler struct {
+	billingUsecase usecase.BillingUsecase
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(billingUsecase usecase.BillingUsecase) *BillingHandler {
+	return &BillingHandler{
+		billingUsecase: billingUsecase,
+	}
+}
+
+// GetPlans lists the plans users can subscribe to
+func (h *BillingHandler) GetPlans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Plans retrieved successfully",
+		"plans":   h.billingUsecase.Plans(),
+	}, http.StatusOK)
+}
+
+// GetBilling returns the current user's plan and subscription
+func (h *BillingHandler) GetBilling(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	billingStatus, err := h.billingUsecase.GetBilling(r.Context(), userID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get billing")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Billing retrieved successfully",
+		"billing": billingStatus,
+	}, http.StatusOK)
+}
+
+// Checkout creates a checkout page subscribing the current user to a plan
+func (h *BillingHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.billingUsecase.Checkout(r.Context(), userID, &req)
+	if err != nil {
+		RespondError(w, r, err, "Failed to create checkout")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message":  "Checkout created successfully",
+		"checkout": session,
+	}, http.StatusCreated)
+}
+
+// Portal creates a billing portal page where the current user manages their
+// subscription
+func (h *BillingHandler) Portal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	session, err := h.billingUsecase.Portal(r.Context(), userID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to create billing portal session")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Billing portal session created successfully",
+		"portal":  session,
+	}, http.StatusCreated)
+}
+
+// CancelSubscription cancels the current user's subscription at the end of
+// the period paid for
+func (h *BillingHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	billingStatus, err := h.billingUsecase.CancelSubscription(r.Context(), userID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to cancel subscription")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Subscription canceled successfully",
+		"billing": billingStatus,
+	}, http.StatusOK)
+}
+
+// Webhook receives the payment provider's events. The request isn't
+// authenticated by a token but by its signature over the raw body.
+func (h *BillingHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBillingWebhookSize))
+	if err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.billingUsecase.HandleWebhook(r.Context(), payload, r.Header.Get(billing.SignatureHeader)); err != nil {
+		if errors.Is(err, billing.ErrInvalidSignature) {
+			response.Error(w, r, "Invalid signature", http.StatusBadRequest)
+			return
+		}
+		// Anything else is answered with a 5xx so the provider retries
+		response.Error(w, r, internalErrorMessage("Failed to handle billing event", err), http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"received": true,
+	}, http.StatusOK)
+}
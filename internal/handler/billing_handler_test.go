@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBillingProvider creates customer cus_1 and fixed pages
+type stubBillingProvider struct{}
+
+func (stubBillingProvider) CreateCustomer(context.Context, *billing.CustomerParams) (string, error) {
+	return "cus_1", nil
+}
+
+func (stubBillingProvider) CreateCheckoutSession(context.Context, *billing.CheckoutParams) (string, error) {
+	return "https://checkout.stripe.com/c/pay/cs_1", nil
+}
+
+func (stubBillingProvider) CreatePortalSession(context.Context, string, string) (string, error) {
+	return "https://billing.stripe.com/p/session/1", nil
+}
+
+func (stubBillingProvider) CancelSubscription(context.Context, string) (*billing.Subscription, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestBillingHandler(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	user := &domain.User{Name: "Ada", Email: "ada@example.com", Password: "hash", Role: domain.RoleUser}
+	require.NoError(t, repos.Users.Create(ctx, user))
+	h := NewBillingHandler(usecase.NewBillingUsecase(stubBillingProvider{}, repos.Users, repos.BillingAccounts, jobs.NewQueue(repos.Jobs), usecase.BillingSettings{
+		Plans:         map[string]string{"pro": "price_pro"},
+		WebhookSecret: "whsec_test",
+	}))
+
+	serve := func(handle http.HandlerFunc, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), "user_id", user.ID))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rr := httptest.NewRecorder()
+		handle(rr, req)
+		return rr
+	}
+
+	rr := serve(h.Checkout, `{"plan":"enterprise"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+	rr = serve(h.Checkout, `{"plan":"pro"}`, nil)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), "https://checkout.stripe.com/c/pay/cs_1")
+	rr = serve(h.CancelSubscription, "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	payload := fmt.Sprintf(`{"id":"evt_1","type":%q,"created":%d,"data":{"object":{"id":"sub_1","customer":"cus_1","status":"active",
+		"items":{"data":[{"price":{"id":"price_pro"}}]}}}}`, billing.EventSubscriptionCreated, time.Now().Unix())
+	rr = serve(h.Webhook, payload, http.Header{billing.SignatureHeader: {billing.SignPayload([]byte(payload), "whsec_other", time.Now())}})
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "events signed with another secret are rejected")
+	rr = serve(h.Webhook, payload, http.Header{billing.SignatureHeader: {billing.SignPayload([]byte(payload), "whsec_test", time.Now())}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = serve(h.Checkout, `{"plan":"pro"}`, nil)
+	assert.Equal(t, http.StatusConflict, rr.Code, "subscribed users change plans in the portal")
+	stored, err := repos.Users.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pro", stored.Plan)
+}
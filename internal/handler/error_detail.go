@@ -0,0 +1,22 @@
+package handler
+
+import "sync/atomic"
+
+// errorDetail reports whether 500 responses include the underlying error
+var errorDetail atomic.Bool
+
+// SetErrorDetail sets whether 500 responses include the underlying error.
+// It helps local debugging, but errors can leak internals such as SQL, so
+// keep it off in deployed environments.
+func SetErrorDetail(enabled bool) {
+	errorDetail.Store(enabled)
+}
+
+// internalErrorMessage returns the message of a 500 response, followed by
+// err when error detail is on
+func internalErrorMessage(message string, err error) string {
+	if err == nil || !errorDetail.Load() {
+		return message
+	}
+	return message + ": " + err.Error()
+}
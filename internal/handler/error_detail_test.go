@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternalErrorMessage(t *testing.T) {
+	err := errors.New("failed to get user: connection refused")
+
+	assert.Equal(t, "Failed to get user", internalErrorMessage("Failed to get user", err))
+
+	SetErrorDetail(true)
+	t.Cleanup(func() { SetErrorDetail(false) })
+	assert.Equal(t, "Failed to get user: failed to get user: connection refused", internalErrorMessage("Failed to get user", err))
+	assert.Equal(t, "Failed to get user", internalErrorMessage("Failed to get user", nil))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// kindStatus maps the kinds of domain errors to the status codes they are
+// reported with
+var kindStatus = map[error]int{
+	domain.ErrNotFound:     http.StatusNotFound,
+	domain.ErrConflict:     http.StatusConflict,
+	domain.ErrInvalid:      http.StatusBadRequest,
+	domain.ErrUnauthorized: http.StatusUnauthorized,
+	domain.ErrForbidden:    http.StatusForbidden,
+	domain.ErrRateLimited:  http.StatusTooManyRequests,
+	domain.ErrUnavailable:  http.StatusNotImplemented,
+}
+
+// ErrorStatus returns the status code err is reported with: the one of its
+// domain error kind, or 500 for unexpected errors
+func ErrorStatus(err error) int {
+	if status, ok := kindStatus[domain.KindOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// RespondError writes the error response for err returned by a usecase.
+// Domain errors are written with their message and the status of their
+// kind; anything else is a 500 with the fallback message, so internals
+// don't leak to clients.
+func RespondError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	status := ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		writeNegotiatedError(w, r, internalErrorMessage(fallback, err), status)
+		return
+	}
+	writeNegotiatedError(w, r, err.Error(), status)
+}
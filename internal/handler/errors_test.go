@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespondError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{"not found", domain.ErrUserNotFound, http.StatusNotFound, "user not found"},
+		{"wrapped", fmt.Errorf("get user 7: %w", domain.ErrUserNotFound), http.StatusNotFound, "get user 7: user not found"},
+		{"conflict", fmt.Errorf("%w: a@example.com", domain.ErrDuplicateEmail), http.StatusConflict, "duplicate email in request: a@example.com"},
+		{"version conflict", &domain.VersionConflictError{Entity: "user", ID: 1}, http.StatusConflict, "user 1 was modified by another request; reload it and try again"},
+		{"validation", domain.Errorf(domain.ErrInvalid, "unknown webhook event: %s", "nope"), http.StatusBadRequest, "unknown webhook event: nope"},
+		{"unauthorized", domain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid email or password"},
+		{"forbidden", domain.ErrAccessDenied, http.StatusForbidden, "access denied"},
+		{"rate limited", domain.ErrCodeRecentlySent, http.StatusTooManyRequests, domain.ErrCodeRecentlySent.Error()},
+		{"unavailable", usecase.ErrPushUnavailable, http.StatusNotImplemented, "push notifications are not enabled"},
+		{"internal", errors.New("connection refused"), http.StatusInternalServerError, "Failed to get user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RespondError(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.err, "Failed to get user")
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantStatus, ErrorStatus(tt.err))
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantMessage, body["error"])
+		})
+	}
+}
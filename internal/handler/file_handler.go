@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+)
+
+// FileHandler handles file upload and download requests
+type FileHandler struct {
+	fileUsecase   usecase.FileUsecase
+	maxUploadSize int64
+	presignExpiry time.Duration
+}
+
+// NewFileHandler creates a new file handler. Uploads larger than
+// maxUploadSize bytes are rejected and pre-signed URLs expire after presignExpiry.
+func NewFileHandler(fileUsecase usecase.FileUsecase, maxUploadSize int64, presignExpiry time.Duration) *FileHandler {
+	return &FileHandler{
+		fileUsecase:   fileUsecase,
+		maxUploadSize: maxUploadSize,
+		presignExpiry: presignExpiry,
+	}
+}
+
+// UploadFile stores the multipart "file" field for the current user
+func (h *FileHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		response.Error(w, r, "Request must be multipart/form-data", http.StatusBadRequest)
+		return
+	}
+
+	// Stream the first "file" part straight to storage instead of buffering it
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			response.Error(w, r, "File is required", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeUploadError(w, r, err)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		file, err := h.fileUsecase.Upload(r.Context(), userID, part.FileName(), part.Header.Get("Content-Type"), part)
+		part.Close()
+		if err != nil {
+			writeUploadError(w, r, err)
+			return
+		}
+
+		response.JSON(w, map[string]interface{}{
+			"message": "File uploaded successfully",
+			"file":    file,
+		}, http.StatusCreated)
+		return
+	}
+}
+
+// GetFiles returns the current user's files with pagination
+func (h *FileHandler) GetFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	limit, offset := parsePagination(r)
+	files, err := h.fileUsecase.ListFiles(r.Context(), userID, limit, offset)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get files")
+		return
+	}
+
+	response.List(w, "Files retrieved successfully", "files", files, response.Page{Limit: limit, Offset: offset}, nil)
+}
+
+// GetFile returns a file's metadata
+func (h *FileHandler) GetFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, role, ok := requester(w, r)
+	if !ok {
+		return
+	}
+	fileID, ok := parseIDParam(w, r, "File")
+	if !ok {
+		return
+	}
+
+	file, err := h.fileUsecase.GetFile(r.Context(), userID, role, fileID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get file")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "File retrieved successfully",
+		"file":    file,
+	}, http.StatusOK)
+}
+
+// DownloadFile streams a file's content
+func (h *FileHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, role, ok := requester(w, r)
+	if !ok {
+		return
+	}
+	fileID, ok := parseIDParam(w, r, "File")
+	if !ok {
+		return
+	}
+
+	file, content, err := h.fileUsecase.OpenFile(r.Context(), userID, role, fileID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to download file")
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to stream file", "file_id", file.ID, "error", err)
+	}
+}
+
+// DeleteFile deletes a file
+func (h *FileHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, role, ok := requester(w, r)
+	if !ok {
+		return
+	}
+	fileID, ok := parseIDParam(w, r, "File")
+	if !ok {
+		return
+	}
+
+	if err := h.fileUsecase.DeleteFile(r.Context(), userID, role, fileID); err != nil {
+		RespondError(w, r, err, "Failed to delete file")
+		return
+	}
+
+	response.Message(w, "File deleted successfully", http.StatusOK)
+}
+
+// PresignFile returns a time-limited URL for uploading or downloading a file
+// directly to or from object storage
+func (h *FileHandler) PresignFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, role, ok := requester(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.PresignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		presigned *domain.PresignResponse
+		err       error
+	)
+	switch req.Action {
+	case domain.PresignActionUpload:
+		if req.Size < 0 || req.Size > h.maxUploadSize {
+			response.Error(w, r, "File size exceeds the maximum upload size", http.StatusBadRequest)
+			return
+		}
+		presigned, err = h.fileUsecase.PresignUpload(r.Context(), userID, req.Name, req.ContentType, req.Size, h.presignExpiry)
+	case domain.PresignActionDownload:
+		if req.FileID == 0 {
+			response.Error(w, r, "File ID is required", http.StatusBadRequest)
+			return
+		}
+		presigned, err = h.fileUsecase.PresignDownload(r.Context(), userID, role, req.FileID, h.presignExpiry)
+	default:
+		response.Error(w, r, "Action must be \"upload\" or \"download\"", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		RespondError(w, r, err, "Failed to create pre-signed URL")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Pre-signed URL created successfully",
+		"presign": presigned,
+	}, http.StatusOK)
+}
+
+// requester returns the authenticated user's ID and role, writing a 401 response on failure
+func requester(w http.ResponseWriter, r *http.Request) (uint, string, bool) {
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return 0, "", false
+	}
+	role, _ := r.Context().Value("user_role").(string)
+	return userID, role, true
+}
+
+// writeUploadError reports oversized uploads as 413 and anything else as
+// RespondError does
+func writeUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		response.Error(w, r, "File is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	RespondError(w, r, err, "Failed to upload file")
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webhook"
+	"github.com/gorilla/mux"
+)
+
+// HookHandler receives other services' webhooks at /hooks/{provider}
+type HookHandler struct {
+	hooks       usecase.InboundWebhookUsecase
+	maxBodySize int64
+}
+
+// NewHookHandler creates a new handler of received webhooks, refusing
+// bodies larger than maxBodySize bytes
+func NewHookHandler(hooks usecase.InboundWebhookUsecase, maxBodySize int64) *HookHandler {
+	return &HookHandler{
+		hooks:       hooks,
+		maxBodySize: maxBodySize,
+	}
+}
+
+// Receive verifies a provider's webhook and queues it for its handler. The
+// request isn't authenticated by a token but by its signature over the raw
+// body.
+func (h *HookHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, r, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	queued, err := h.hooks.Receive(r.Context(), mux.Vars(r)["provider"], r.Header, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrUnknownWebhookProvider):
+			response.Error(w, r, "Unknown webhook provider", http.StatusNotFound)
+		case errors.Is(err, webhook.ErrInvalidSignature):
+			response.Error(w, r, "Invalid signature", http.StatusBadRequest)
+		case errors.Is(err, usecase.ErrInvalidWebhookPayload):
+			response.Error(w, r, err.Error(), http.StatusBadRequest)
+		default:
+			// Anything else is answered with a 5xx so the provider retries
+			response.Error(w, r, internalErrorMessage("Failed to receive webhook", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if queued {
+		status = http.StatusAccepted
+	}
+	response.JSON(w, map[string]interface{}{
+		"received": true,
+		"queued":   queued,
+	}, status)
+}
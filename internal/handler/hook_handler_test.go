@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webhook"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookHandler_Receive(t *testing.T) {
+	hooks := usecase.NewInboundWebhookUsecase(jobs.NewQueue(memory.NewRepositories().Jobs), map[string]string{"acme": "s3cret"}, 5*time.Minute)
+	hooks.Handle("acme", "order.paid", func(context.Context, *domain.InboundWebhook) error { return nil })
+	h := NewHookHandler(hooks, 64)
+
+	post := func(provider, secret, event, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/"+provider, strings.NewReader(body))
+		now := time.Now()
+		req.Header.Set(webhook.HeaderSignature, webhook.Sign(secret, now, []byte(body)))
+		req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(webhook.HeaderEvent, event)
+		req = mux.SetURLVars(req, map[string]string{"provider": provider})
+		rr := httptest.NewRecorder()
+		h.Receive(rr, req)
+		return rr
+	}
+
+	rr := post("acme", "s3cret", "order.paid", `{"id":1}`)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.JSONEq(t, `{"received":true,"queued":true}`, rr.Body.String())
+
+	rr = post("acme", "s3cret", "order.refunded", `{"id":1}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "events without a handler are acknowledged")
+	assert.JSONEq(t, `{"received":true,"queued":false}`, rr.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, post("github", "s3cret", "push", `{"id":1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("acme", "guess", "order.paid", `{"id":1}`).Code)
+
+	rr = post("acme", "s3cret", "order.paid", `"paid"`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid webhook payload")
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("acme", "s3cret", "order.paid", `{"note":"`+strings.Repeat("x", 64)+`"}`).Code)
+}
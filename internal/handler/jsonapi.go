@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+)
+
+// JSONAPIMediaType is the media type clients send in Accept to receive
+// JSON:API (https://jsonapi.org) documents instead of the default format
+const JSONAPIMediaType = "application/vnd.api+json"
+
+// jsonAPIResource is a JSON:API resource object
+type jsonAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]interface{}         `json:"attributes"`
+	Relationships map[string]jsonAPIRelationship `json:"relationships,omitempty"`
+	Links         map[string]string              `json:"links,omitempty"`
+}
+
+// jsonAPIRelationship is a JSON:API relationship object
+type jsonAPIRelationship struct {
+	Data  interface{}       `json:"data"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// jsonAPIError is a JSON:API error object
+type jsonAPIError struct {
+	Status string `json:"status"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// jsonAPIDocument is a top-level JSON:API document
+type jsonAPIDocument struct {
+	Data   interface{}            `json:"data,omitempty"`
+	Errors []jsonAPIError         `json:"errors,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// wantsJSONAPI reports whether the request's Accept header asks for JSON:API
+func wantsJSONAPI(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == JSONAPIMediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// userResource converts a user response into a JSON:API resource object
+func userResource(user *domain.UserResponse) jsonAPIResource {
+	id := user.ExternalID()
+	return jsonAPIResource{
+		Type: "users",
+		ID:   id,
+		Attributes: map[string]interface{}{
+			"name":       user.Name,
+			"email":      user.Email,
+			"phone":      user.Phone,
+			"role":       user.Role,
+			"created_at": domain.Timestamp(user.CreatedAt),
+			"version":    user.Version,
+		},
+		Links: map[string]string{
+			"self": "/api/users/" + id,
+		},
+	}
+}
+
+// writeJSONAPIDocument writes a JSON:API document with the JSON:API content type
+func writeJSONAPIDocument(w http.ResponseWriter, doc jsonAPIDocument, statusCode int) {
+	response.Write(w, JSONAPIMediaType, doc, statusCode)
+}
+
+// writeNegotiatedError writes an error as a JSON:API errors document when
+// requested, falling back to the default error format
+func writeNegotiatedError(w http.ResponseWriter, r *http.Request, message string, statusCode int) {
+	if !wantsJSONAPI(r) {
+		response.Error(w, r, message, statusCode)
+		return
+	}
+
+	writeJSONAPIDocument(w, jsonAPIDocument{
+		Errors: []jsonAPIError{{
+			Status: strconv.Itoa(statusCode),
+			Title:  http.StatusText(statusCode),
+			Detail: i18n.FromContext(r.Context()).T(message, nil),
+		}},
+	}, statusCode)
+}
+
+// writeUserResponse writes a single user in the format requested by the client
+func writeUserResponse(w http.ResponseWriter, r *http.Request, message string, user *domain.UserResponse, statusCode int) {
+	if !wantsJSONAPI(r) {
+		response.JSON(w, map[string]interface{}{
+			"message": message,
+			"user":    user,
+		}, statusCode)
+		return
+	}
+
+	writeJSONAPIDocument(w, jsonAPIDocument{
+		Data: userResource(user),
+		Meta: map[string]interface{}{"message": message},
+	}, statusCode)
+}
+
+// writeMessageResponse writes a message-only response in the format requested by the client
+func writeMessageResponse(w http.ResponseWriter, r *http.Request, message string, statusCode int) {
+	if !wantsJSONAPI(r) {
+		response.Message(w, message, statusCode)
+		return
+	}
+
+	writeJSONAPIDocument(w, jsonAPIDocument{
+		Meta: map[string]interface{}{"message": message},
+	}, statusCode)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWantsJSONAPI(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{JSONAPIMediaType, true},
+		{"application/json, application/vnd.api+json", true},
+		{"application/vnd.api+json; ext=bulk", true},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		assert.Equal(t, tt.expected, wantsJSONAPI(req), tt.accept)
+	}
+}
+
+func TestUserHandler_GetUser_JSONAPI(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("ResolveUserID", mock.Anything, "1").Return(uint(1), nil)
+	mockUsecase.On("GetUserByID", mock.Anything, uint(1)).Return(&domain.UserResponse{
+		ID: 1, Name: "John Doe", Email: "john@example.com", Role: domain.RoleUser, CreatedAt: time.Now(),
+	}, nil)
+	h := NewUserHandler(mockUsecase)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+	req.Header.Set("Accept", JSONAPIMediaType)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+
+	h.GetUser(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, JSONAPIMediaType, rr.Header().Get("Content-Type"))
+
+	var doc struct {
+		Data jsonAPIResource `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, "users", doc.Data.Type)
+	assert.Equal(t, "1", doc.Data.ID)
+	assert.Equal(t, "John Doe", doc.Data.Attributes["name"])
+	assert.Equal(t, "/api/users/1", doc.Data.Links["self"])
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUserHandler_GetProfile_JSONAPIError(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("GetProfile", mock.Anything, uint(1)).Return(nil, domain.ErrUserNotFound)
+	h := NewUserHandler(mockUsecase)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
+	req.Header.Set("Accept", JSONAPIMediaType)
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", uint(1)))
+	rr := httptest.NewRecorder()
+
+	h.GetProfile(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.JSONEq(t, `{"errors":[{"status":"404","title":"Not Found","detail":"user not found"}]}`, rr.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUserHandler_GetAllUsers_DefaultFormat(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("GetAllUsers", mock.Anything, 10, 0).Return([]*domain.UserResponse{{ID: 1}}, nil)
+	h := NewUserHandler(mockUsecase)
+
+	rr := httptest.NewRecorder()
+	h.GetAllUsers(rr, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "Users retrieved successfully", body["message"])
+	assert.Len(t, body["users"], 1)
+	mockUsecase.AssertExpectations(t)
+}
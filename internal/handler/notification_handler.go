@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+)
+
+// NotificationHandler handles the notification inbox and notification
+// preference requests
+type NotificationHandler struct {
+	notificationUsecase usecase.NotificationUsecase
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationUsecase usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUsecase: notificationUsecase,
+	}
+}
+
+// GetPreferences returns the current user's notification preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := h.notificationUsecase.GetPreferences(r.Context(), userID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get notification preferences")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message":     "Notification preferences retrieved successfully",
+		"preferences": prefs,
+	}, http.StatusOK)
+}
+
+// UpdatePreferences turns channels on or off for the current user's
+// notification types
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.NotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	prefs, err := h.notificationUsecase.UpdatePreferences(r.Context(), userID, &req)
+	if err != nil {
+		RespondError(w, r, err, "Failed to update notification preferences")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message":     "Notification preferences updated successfully",
+		"preferences": prefs,
+	}, http.StatusOK)
+}
+
+// GetNotifications returns a page of the current user's in-app
+// notifications, newest first, with their unread count. unread=true lists
+// only unread notifications.
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	limit, offset := parsePagination(r)
+	unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+
+	notifications, err := h.notificationUsecase.ListNotifications(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get notifications")
+		return
+	}
+	unread, err := h.notificationUsecase.UnreadCount(r.Context(), userID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get notifications")
+		return
+	}
+
+	response.List(w, "Notifications retrieved successfully", "notifications", notifications,
+		response.Page{Limit: limit, Offset: offset}, map[string]interface{}{"unread_count": unread})
+}
+
+// GetUnreadCount returns how many of the current user's in-app notifications
+// are unread, for badges that poll it
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	unread, err := h.notificationUsecase.UnreadCount(r.Context(), userID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to count unread notifications")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"unread_count": unread,
+	}, http.StatusOK)
+}
+
+// MarkRead marks one of the current user's in-app notifications as read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	notificationID, ok := parseIDParam(w, r, "Notification")
+	if !ok {
+		return
+	}
+
+	notification, err := h.notificationUsecase.MarkRead(r.Context(), userID, notificationID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to mark notification as read")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message":      "Notification marked as read",
+		"notification": notification,
+	}, http.StatusOK)
+}
+
+// GetPushPublicKey returns the VAPID public key browsers pass to
+// PushManager.subscribe() as applicationServerKey
+func (h *NotificationHandler) GetPushPublicKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key, err := h.notificationUsecase.PushPublicKey()
+	if err != nil {
+		RespondError(w, r, err, "Failed to get push public key")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"public_key": key,
+	}, http.StatusOK)
+}
+
+// GetPushSubscriptions returns the current user's browser push subscriptions
+func (h *NotificationHandler) GetPushSubscriptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	subs, err := h.notificationUsecase.ListPushSubscriptions(r.Context(), userID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get push subscriptions")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message":       "Push subscriptions retrieved successfully",
+		"subscriptions": subs,
+		"count":         len(subs),
+	}, http.StatusOK)
+}
+
+// CreatePushSubscription stores a browser push subscription for the current
+// user. The body is the JSON of the browser's PushSubscription.
+func (h *NotificationHandler) CreatePushSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.PushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.notificationUsecase.SubscribePush(r.Context(), userID, &req, r.UserAgent())
+	if err != nil {
+		RespondError(w, r, err, "Failed to store push subscription")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message":      "Push subscription stored successfully",
+		"subscription": sub,
+	}, http.StatusCreated)
+}
+
+// DeletePushSubscription removes one of the current user's push subscriptions
+func (h *NotificationHandler) DeletePushSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	subscriptionID, ok := parseIDParam(w, r, "Push subscription")
+	if !ok {
+		return
+	}
+
+	if err := h.notificationUsecase.DeletePushSubscription(r.Context(), userID, subscriptionID); err != nil {
+		RespondError(w, r, err, "Failed to delete push subscription")
+		return
+	}
+
+	response.Message(w, "Push subscription deleted successfully", http.StatusOK)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webpush"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationHandler_Inbox(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	for _, subject := range []string{"First", "Second"} {
+		require.NoError(t, repos.Notifications.Create(ctx, &domain.Notification{
+			UserID: 1, Type: domain.NotificationSecurityAlert, Payload: `{"subject":"` + subject + `","message":"hi"}`,
+		}))
+	}
+	h := NewNotificationHandler(usecase.NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, jobs.NewQueue(repos.Jobs)))
+	router := mux.NewRouter()
+	router.HandleFunc("/api/notifications", h.GetNotifications)
+	router.HandleFunc("/api/notifications/unread-count", h.GetUnreadCount)
+	router.HandleFunc("/api/notifications/{id}/read", h.MarkRead)
+
+	serve := func(method, target string, userID uint) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user_id", userID))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodPost, "/api/notifications/1/read", 1)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = serve(http.MethodGet, "/api/notifications", 1)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var list struct {
+		Notifications []domain.NotificationResponse `json:"notifications"`
+		UnreadCount   int64                         `json:"unread_count"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	require.Len(t, list.Notifications, 2)
+	assert.Equal(t, "Second", list.Notifications[0].Payload.Subject)
+	assert.False(t, list.Notifications[0].Read)
+	assert.True(t, list.Notifications[1].Read)
+	assert.Equal(t, int64(1), list.UnreadCount)
+
+	rr = serve(http.MethodGet, "/api/notifications?unread=true", 1)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	require.Len(t, list.Notifications, 1)
+
+	rr = serve(http.MethodGet, "/api/notifications/unread-count", 1)
+	assert.JSONEq(t, `{"unread_count":1}`, rr.Body.String())
+
+	rr = serve(http.MethodPost, "/api/notifications/2/read", 2)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "other users' notifications are not found")
+
+	rr = serve(http.MethodGet, "/api/notifications/1/read", 1)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestNotificationHandler_PushSubscriptions(t *testing.T) {
+	repos := memory.NewRepositories()
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	sender, err := webpush.NewSender(webpush.Config{VAPIDPublicKey: publicKey, VAPIDPrivateKey: privateKey, Subject: "mailto:ops@example.com"})
+	require.NoError(t, err)
+	queue := jobs.NewQueue(repos.Jobs)
+	h := NewNotificationHandler(usecase.NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, queue,
+		usecase.NewPushNotificationChannel(sender, repos.PushSubscriptions, queue)))
+	router := mux.NewRouter()
+	router.HandleFunc("/api/push/public-key", h.GetPushPublicKey)
+	router.HandleFunc("/api/profile/push-subscriptions", h.GetPushSubscriptions).Methods(http.MethodGet)
+	router.HandleFunc("/api/profile/push-subscriptions", h.CreatePushSubscription).Methods(http.MethodPost)
+	router.HandleFunc("/api/profile/push-subscriptions/{id}", h.DeletePushSubscription)
+
+	serve := func(method, target, body string, userID uint) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), "user_id", userID))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodGet, "/api/push/public-key", "", 0)
+	assert.JSONEq(t, `{"public_key":"`+publicKey+`"}`, rr.Body.String())
+
+	// A subscription from a browser; the p256dh key is any P-256 public key
+	browserKey, _, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	subscription := `{"endpoint":"https://push.example.com/abc","keys":{"p256dh":"` + browserKey + `","auth":"BTBZMqHH6r4Tts7J_aSIgg"}}`
+	rr = serve(http.MethodPost, "/api/profile/push-subscriptions", subscription, 1)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	assert.NotContains(t, rr.Body.String(), "BTBZMqHH6r4Tts7J_aSIgg", "keys are not echoed back")
+
+	rr = serve(http.MethodPost, "/api/profile/push-subscriptions", `{"endpoint":"https://push.example.com/abc","keys":{"p256dh":"x","auth":"y"}}`, 1)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serve(http.MethodGet, "/api/profile/push-subscriptions", "", 1)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var list struct {
+		Subscriptions []domain.PushSubscription `json:"subscriptions"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	require.Len(t, list.Subscriptions, 1)
+	assert.Equal(t, "https://push.example.com/abc", list.Subscriptions[0].Endpoint)
+
+	rr = serve(http.MethodDelete, "/api/profile/push-subscriptions/1", "", 2)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "other users' subscriptions are not found")
+	rr = serve(http.MethodDelete, "/api/profile/push-subscriptions/1", "", 1)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestNotificationHandler_PushUnavailable(t *testing.T) {
+	repos := memory.NewRepositories()
+	h := NewNotificationHandler(usecase.NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, jobs.NewQueue(repos.Jobs)))
+
+	rr := httptest.NewRecorder()
+	h.GetPushPublicKey(rr, httptest.NewRequest(http.MethodGet, "/api/push/public-key", nil))
+	assert.Equal(t, http.StatusNotImplemented, rr.Code)
+}
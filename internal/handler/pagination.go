@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultPageLimit is the page size when the request sets none
+	defaultPageLimit = 10
+	// maxPageLimit bounds the page size a request can ask for
+	maxPageLimit = 100
+)
+
+// parsePagination parses the limit and offset query parameters with
+// defaults. Limits above maxPageLimit are lowered to it.
+func parsePagination(r *http.Request) (int, int) {
+	limit := defaultPageLimit
+	offset := 0
+
+	if parsedLimit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsedLimit > 0 {
+		limit = min(parsedLimit, maxPageLimit)
+	}
+	if parsedOffset, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsedOffset >= 0 {
+		offset = parsedOffset
+	}
+	return limit, offset
+}
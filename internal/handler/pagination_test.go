@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePagination(t *testing.T) {
+	for query, want := range map[string][2]int{
+		"":                     {defaultPageLimit, 0},
+		"limit=25&offset=50":   {25, 50},
+		"limit=0&offset=-1":    {defaultPageLimit, 0},
+		"limit=abc&offset=xyz": {defaultPageLimit, 0},
+		"limit=101":            {maxPageLimit, 0},
+		"limit=9999999999999":  {maxPageLimit, 0},
+	} {
+		limit, offset := parsePagination(httptest.NewRequest(http.MethodGet, "/api/users?"+query, nil))
+		assert.Equal(t, want, [2]int{limit, offset}, query)
+	}
+}
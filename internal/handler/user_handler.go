@@ -2,10 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strconv"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
 	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
 	"github.com/gorilla/mux"
 )
@@ -25,310 +26,471 @@ func NewUserHandler(userUsecase usecase.UserUsecase) *UserHandler {
 // GetProfile returns the current user's profile
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Get user ID from JWT context
 	userID, ok := r.Context().Value("user_id").(uint)
 	if !ok {
-		writeErrorResponse(w, "Invalid user context", http.StatusUnauthorized)
+		writeNegotiatedError(w, r, "Invalid user context", http.StatusUnauthorized)
 		return
 	}
 
 	user, err := h.userUsecase.GetProfile(r.Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			writeErrorResponse(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		writeErrorResponse(w, "Failed to get profile", http.StatusInternalServerError)
+		RespondError(w, r, err, "Failed to get profile")
 		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "Profile retrieved successfully",
-		"user":    user,
-	}, http.StatusOK)
+	writeUserResponse(w, r, "Profile retrieved successfully", user, http.StatusOK)
 }
 
 // CreateUser creates a new user (admin function)
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req domain.UserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Basic validation
 	if req.Name == "" || req.Email == "" || req.Password == "" {
-		writeErrorResponse(w, "Name, email, and password are required", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Name, email, and password are required", http.StatusBadRequest)
 		return
 	}
 
 	if len(req.Password) < 6 {
-		writeErrorResponse(w, "Password must be at least 6 characters", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Password must be at least 6 characters", http.StatusBadRequest)
 		return
 	}
 
 	user, err := h.userUsecase.CreateUser(r.Context(), &req)
 	if err != nil {
-		if err.Error() == "user with this email already exists" {
-			writeErrorResponse(w, err.Error(), http.StatusConflict)
+		RespondError(w, r, err, "Failed to create user")
+		return
+	}
+
+	writeUserResponse(w, r, "User created successfully", user, http.StatusCreated)
+}
+
+// maxBulkCreateUsers caps how many users one bulk-create request may carry
+const maxBulkCreateUsers = 1000
+
+// BulkCreateUsersRequest is the body of a bulk-create request
+type BulkCreateUsersRequest struct {
+	Users []*domain.UserRequest `json:"users"`
+}
+
+// CreateUsers creates many users in one request (admin only). The batch is
+// inserted in chunks inside a single transaction, so it succeeds or fails as a whole.
+func (h *UserHandler) CreateUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if role, _ := r.Context().Value("user_role").(string); role != domain.RoleAdmin {
+		writeNegotiatedError(w, r, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	var req BulkCreateUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Users) == 0 {
+		writeNegotiatedError(w, r, "At least one user is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Users) > maxBulkCreateUsers {
+		writeNegotiatedError(w, r, fmt.Sprintf("At most %d users can be created per request", maxBulkCreateUsers), http.StatusBadRequest)
+		return
+	}
+
+	for i, user := range req.Users {
+		if user == nil || user.Name == "" || user.Email == "" || user.Password == "" {
+			writeNegotiatedError(w, r, fmt.Sprintf("users[%d]: name, email, and password are required", i), http.StatusBadRequest)
 			return
 		}
-		writeErrorResponse(w, "Failed to create user", http.StatusInternalServerError)
+		if len(user.Password) < 6 {
+			writeNegotiatedError(w, r, fmt.Sprintf("users[%d]: password must be at least 6 characters", i), http.StatusBadRequest)
+			return
+		}
+	}
+
+	users, err := h.userUsecase.CreateUsers(r.Context(), req.Users)
+	if err != nil {
+		RespondError(w, r, err, "Failed to create users")
 		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "User created successfully",
-		"user":    user,
+	if wantsJSONAPI(r) {
+		resources := make([]jsonAPIResource, 0, len(users))
+		for _, user := range users {
+			resources = append(resources, userResource(user))
+		}
+		writeJSONAPIDocument(w, jsonAPIDocument{
+			Data: resources,
+			Meta: map[string]interface{}{"count": len(users)},
+		}, http.StatusCreated)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Users created successfully",
+		"users":   users,
+		"count":   len(users),
 	}, http.StatusCreated)
 }
 
 // GetUser returns a specific user by ID
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	vars := mux.Vars(r)
-	userIDStr, exists := vars["id"]
-	if !exists {
-		writeErrorResponse(w, "User ID is required", http.StatusBadRequest)
-		return
-	}
-
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		writeErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+	userID, ok := h.resolveUserID(w, r)
+	if !ok {
 		return
 	}
 
-	user, err := h.userUsecase.GetUserByID(r.Context(), uint(userID))
+	user, err := h.userUsecase.GetUserByID(r.Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			writeErrorResponse(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		writeErrorResponse(w, "Failed to get user", http.StatusInternalServerError)
+		RespondError(w, r, err, "Failed to get user")
 		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "User retrieved successfully",
-		"user":    user,
-	}, http.StatusOK)
+	writeUserResponse(w, r, "User retrieved successfully", user, http.StatusOK)
 }
 
 // UpdateUser updates the current user's profile
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Get user ID from JWT context
 	userID, ok := r.Context().Value("user_id").(uint)
 	if !ok {
-		writeErrorResponse(w, "Invalid user context", http.StatusUnauthorized)
+		writeNegotiatedError(w, r, "Invalid user context", http.StatusUnauthorized)
 		return
 	}
 
 	var req domain.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Validate password length if provided
 	if req.Password != "" && len(req.Password) < 6 {
-		writeErrorResponse(w, "Password must be at least 6 characters", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Password must be at least 6 characters", http.StatusBadRequest)
 		return
 	}
 
 	user, err := h.userUsecase.UpdateUser(r.Context(), userID, &req)
 	if err != nil {
-		switch err.Error() {
-		case "user not found":
-			writeErrorResponse(w, err.Error(), http.StatusNotFound)
-			return
-		case "email already exists":
-			writeErrorResponse(w, err.Error(), http.StatusConflict)
-			return
-		default:
-			writeErrorResponse(w, "Failed to update user", http.StatusInternalServerError)
-			return
-		}
+		RespondError(w, r, err, "Failed to update user")
+		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "User updated successfully",
-		"user":    user,
-	}, http.StatusOK)
+	writeUserResponse(w, r, "User updated successfully", user, http.StatusOK)
 }
 
 // UpdateUserByID updates a specific user by ID (admin function)
 func (h *UserHandler) UpdateUserByID(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	vars := mux.Vars(r)
-	userIDStr, exists := vars["id"]
-	if !exists {
-		writeErrorResponse(w, "User ID is required", http.StatusBadRequest)
-		return
-	}
-
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		writeErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+	userID, ok := h.resolveUserID(w, r)
+	if !ok {
 		return
 	}
 
 	var req domain.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Validate password length if provided
 	if req.Password != "" && len(req.Password) < 6 {
-		writeErrorResponse(w, "Password must be at least 6 characters", http.StatusBadRequest)
+		writeNegotiatedError(w, r, "Password must be at least 6 characters", http.StatusBadRequest)
 		return
 	}
 
-	user, err := h.userUsecase.UpdateUser(r.Context(), uint(userID), &req)
+	user, err := h.userUsecase.UpdateUser(r.Context(), userID, &req)
 	if err != nil {
-		switch err.Error() {
-		case "user not found":
-			writeErrorResponse(w, err.Error(), http.StatusNotFound)
-			return
-		case "email already exists":
-			writeErrorResponse(w, err.Error(), http.StatusConflict)
-			return
-		default:
-			writeErrorResponse(w, "Failed to update user", http.StatusInternalServerError)
-			return
-		}
+		RespondError(w, r, err, "Failed to update user")
+		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "User updated successfully",
-		"user":    user,
-	}, http.StatusOK)
+	writeUserResponse(w, r, "User updated successfully", user, http.StatusOK)
 }
 
 // DeleteUser deletes the current user's account
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Get user ID from JWT context
 	userID, ok := r.Context().Value("user_id").(uint)
 	if !ok {
-		writeErrorResponse(w, "Invalid user context", http.StatusUnauthorized)
+		writeNegotiatedError(w, r, "Invalid user context", http.StatusUnauthorized)
 		return
 	}
 
 	err := h.userUsecase.DeleteUser(r.Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			writeErrorResponse(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		writeErrorResponse(w, "Failed to delete user", http.StatusInternalServerError)
+		RespondError(w, r, err, "Failed to delete user")
 		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "User deleted successfully",
-	}, http.StatusOK)
+	writeMessageResponse(w, r, "User deleted successfully", http.StatusOK)
 }
 
 // DeleteUserByID deletes a specific user by ID (admin function)
 func (h *UserHandler) DeleteUserByID(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	vars := mux.Vars(r)
-	userIDStr, exists := vars["id"]
-	if !exists {
-		writeErrorResponse(w, "User ID is required", http.StatusBadRequest)
+	userID, ok := h.resolveUserID(w, r)
+	if !ok {
 		return
 	}
 
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	err := h.userUsecase.DeleteUser(r.Context(), userID)
 	if err != nil {
-		writeErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+		RespondError(w, r, err, "Failed to delete user")
 		return
 	}
 
-	err = h.userUsecase.DeleteUser(r.Context(), uint(userID))
-	if err != nil {
-		if err.Error() == "user not found" {
-			writeErrorResponse(w, err.Error(), http.StatusNotFound)
+	writeMessageResponse(w, r, "User deleted successfully", http.StatusOK)
+}
+
+// GetAllUsers returns all users with pagination
+func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, offset := parsePagination(r)
+
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+	if includeDeleted {
+		if role, _ := r.Context().Value("user_role").(string); role != domain.RoleAdmin {
+			writeNegotiatedError(w, r, "Insufficient permissions", http.StatusForbidden)
 			return
 		}
-		writeErrorResponse(w, "Failed to delete user", http.StatusInternalServerError)
+	}
+
+	var users []*domain.UserResponse
+	var err error
+	if includeDeleted {
+		users, err = h.userUsecase.GetAllUsersIncludingDeleted(r.Context(), limit, offset)
+	} else {
+		users, err = h.userUsecase.GetAllUsers(r.Context(), limit, offset)
+	}
+	if err != nil {
+		RespondError(w, r, err, "Failed to get users")
 		return
 	}
+	page := response.Page{Limit: limit, Offset: offset}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "User deleted successfully",
-	}, http.StatusOK)
+	if wantsJSONAPI(r) {
+		resources := make([]jsonAPIResource, 0, len(users))
+		for _, user := range users {
+			resources = append(resources, userResource(user))
+		}
+		writeJSONAPIDocument(w, jsonAPIDocument{
+			Data: resources,
+			Meta: page.Meta(len(users)),
+		}, http.StatusOK)
+		return
+	}
+
+	response.List(w, "Users retrieved successfully", "users", users, page, nil)
 }
 
-// GetAllUsers returns all users with pagination
-func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+// SearchUsers finds users by name or email with pagination
+func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Parse query parameters for pagination
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	query := r.URL.Query().Get("q")
+	limit, offset := parsePagination(r)
 
-	limit := 10 // default limit
-	offset := 0 // default offset
+	users, err := h.userUsecase.SearchUsers(r.Context(), query, limit, offset)
+	if err != nil {
+		RespondError(w, r, err, "Failed to search users")
+		return
+	}
+	page := response.Page{Limit: limit, Offset: offset}
 
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
+	if wantsJSONAPI(r) {
+		resources := make([]jsonAPIResource, 0, len(users))
+		for _, user := range users {
+			resources = append(resources, userResource(user))
 		}
+		meta := page.Meta(len(users))
+		meta["query"] = query
+		writeJSONAPIDocument(w, jsonAPIDocument{
+			Data: resources,
+			Meta: meta,
+		}, http.StatusOK)
+		return
 	}
 
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
+	response.List(w, "Users retrieved successfully", "users", users, page, map[string]interface{}{"query": query})
+}
+
+// RestoreUserByID undeletes a soft-deleted user (admin function)
+func (h *UserHandler) RestoreUserByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if role, _ := r.Context().Value("user_role").(string); role != domain.RoleAdmin {
+		writeNegotiatedError(w, r, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+
+	userID, ok := h.resolveUserID(w, r)
+	if !ok {
+		return
 	}
 
-	users, err := h.userUsecase.GetAllUsers(r.Context(), limit, offset)
+	user, err := h.userUsecase.RestoreUser(r.Context(), userID)
 	if err != nil {
-		writeErrorResponse(w, "Failed to get users", http.StatusInternalServerError)
+		RespondError(w, r, err, "Failed to restore user")
 		return
 	}
 
-	writeSuccessResponse(w, map[string]interface{}{
-		"message": "Users retrieved successfully",
-		"users":   users,
-		"count":   len(users),
-		"limit":   limit,
-		"offset":  offset,
+	writeUserResponse(w, r, "User restored successfully", user, http.StatusOK)
+}
+
+// SendPhoneVerification texts a verification code to the current user's
+// phone number
+func (h *UserHandler) SendPhoneVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		writeNegotiatedError(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.userUsecase.SendPhoneVerification(r.Context(), userID); err != nil {
+		RespondError(w, r, err, "Failed to send verification code")
+		return
+	}
+
+	response.Message(w, "Verification code sent", http.StatusOK)
+}
+
+// VerifyPhone confirms the current user's phone number with the texted code
+func (h *UserHandler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		writeNegotiatedError(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.VerifyCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Code == "" {
+		writeNegotiatedError(w, r, "Code is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userUsecase.VerifyPhone(r.Context(), userID, req.Code); err != nil {
+		RespondError(w, r, err, "Failed to verify phone number")
+		return
+	}
+
+	response.Message(w, "Phone number verified", http.StatusOK)
+}
+
+// SetTwoFactor turns two-factor login by texted code on or off for the
+// current user
+func (h *UserHandler) SetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeNegotiatedError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		writeNegotiatedError(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeNegotiatedError(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userUsecase.SetTwoFactor(r.Context(), userID, req.Enabled); err != nil {
+		RespondError(w, r, err, "Failed to update two-factor login")
+		return
+	}
+
+	message := "Two-factor login turned off"
+	if req.Enabled {
+		message = "Two-factor login turned on"
+	}
+	response.JSON(w, map[string]interface{}{
+		"message": message,
+		"enabled": req.Enabled,
 	}, http.StatusOK)
-} 
\ No newline at end of file
+}
+
+// resolveUserID reads the {id} path parameter, a numeric or public ID
+// depending on USER_ID_FORMAT, and returns the internal user ID. It writes
+// the error response and reports false when the ID can't be resolved.
+func (h *UserHandler) resolveUserID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	externalID, exists := mux.Vars(r)["id"]
+	if !exists || externalID == "" {
+		writeNegotiatedError(w, r, "User ID is required", http.StatusBadRequest)
+		return 0, false
+	}
+
+	userID, err := h.userUsecase.ResolveUserID(r.Context(), externalID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get user")
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// withRole returns the request with the role set by the auth middleware
+func withRole(req *http.Request, role string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "user_role", role))
+}
+
+func TestUserHandler_GetAllUsers_IncludeDeleted(t *testing.T) {
+	t.Run("admin", func(t *testing.T) {
+		deletedAt := time.Now()
+		mockUsecase := new(mocks.MockUserUsecase)
+		mockUsecase.On("GetAllUsersIncludingDeleted", mock.Anything, 10, 0).Return([]*domain.UserResponse{
+			{ID: 1, Name: "Gone", DeletedAt: &deletedAt},
+		}, nil)
+		h := NewUserHandler(mockUsecase)
+
+		rr := httptest.NewRecorder()
+		h.GetAllUsers(rr, withRole(httptest.NewRequest(http.MethodGet, "/api/users?include_deleted=true", nil), domain.RoleAdmin))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"deleted_at"`)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("non-admin is forbidden", func(t *testing.T) {
+		mockUsecase := new(mocks.MockUserUsecase)
+		h := NewUserHandler(mockUsecase)
+
+		rr := httptest.NewRecorder()
+		h.GetAllUsers(rr, withRole(httptest.NewRequest(http.MethodGet, "/api/users?include_deleted=true", nil), domain.RoleUser))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockUsecase.AssertNotCalled(t, "GetAllUsersIncludingDeleted", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserHandler_GetAllUsers_CapsLimit(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("GetAllUsers", mock.Anything, maxPageLimit, 20).Return([]*domain.UserResponse{}, nil)
+	h := NewUserHandler(mockUsecase)
+
+	rr := httptest.NewRecorder()
+	h.GetAllUsers(rr, httptest.NewRequest(http.MethodGet, "/api/users?limit=1000000&offset=20", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUserHandler_RestoreUserByID(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("ResolveUserID", mock.Anything, "1").Return(uint(1), nil)
+	mockUsecase.On("ResolveUserID", mock.Anything, "2").Return(uint(2), nil)
+	mockUsecase.On("RestoreUser", mock.Anything, uint(1)).Return(&domain.UserResponse{ID: 1, Name: "Back"}, nil)
+	mockUsecase.On("RestoreUser", mock.Anything, uint(2)).Return(nil, domain.ErrDeletedUserNotFound)
+	h := NewUserHandler(mockUsecase)
+
+	restore := func(id, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/users/"+id+"/restore", nil)
+		req = mux.SetURLVars(withRole(req, role), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		h.RestoreUserByID(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, restore("1", domain.RoleAdmin).Code)
+	assert.Equal(t, http.StatusNotFound, restore("2", domain.RoleAdmin).Code)
+	assert.Equal(t, http.StatusForbidden, restore("1", domain.RoleUser).Code)
+}
+
+func TestUserHandler_CreateUsers(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("CreateUsers", mock.Anything, mock.MatchedBy(func(reqs []*domain.UserRequest) bool {
+		return len(reqs) == 2
+	})).Return([]*domain.UserResponse{{ID: 1}, {ID: 2}}, nil)
+	mockUsecase.On("CreateUsers", mock.Anything, mock.MatchedBy(func(reqs []*domain.UserRequest) bool {
+		return len(reqs) == 1
+	})).Return(nil, domain.ErrEmailTaken)
+	h := NewUserHandler(mockUsecase)
+
+	create := func(body, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/users/bulk", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		h.CreateUsers(rr, withRole(req, role))
+		return rr
+	}
+
+	two := `{"users":[{"name":"A","email":"a@example.com","password":"secret1"},{"name":"B","email":"b@example.com","password":"secret2"}]}`
+	rr := create(two, domain.RoleAdmin)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"count":2`)
+
+	assert.Equal(t, http.StatusConflict, create(`{"users":[{"name":"A","email":"a@example.com","password":"secret1"}]}`, domain.RoleAdmin).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"users":[{"name":"A","email":"a@example.com","password":"123"}]}`, domain.RoleAdmin).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"users":[]}`, domain.RoleAdmin).Code)
+	assert.Equal(t, http.StatusForbidden, create(two, domain.RoleUser).Code)
+}
+
+func TestUserHandler_UpdateUserByID_VersionConflict(t *testing.T) {
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("ResolveUserID", mock.Anything, "1").Return(uint(1), nil)
+	mockUsecase.On("UpdateUser", mock.Anything, uint(1), mock.Anything).
+		Return(nil, fmt.Errorf("failed to update user: %w", &domain.VersionConflictError{Entity: "user", ID: 1}))
+	h := NewUserHandler(mockUsecase)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/users/1", strings.NewReader(`{"name":"New","version":3}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+	h.UpdateUserByID(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "modified by another request")
+}
+
+func TestUserHandler_UpdateUserByID_DomainErrors(t *testing.T) {
+	for err, status := range map[error]int{
+		domain.ErrUserNotFound:  http.StatusNotFound,
+		domain.ErrEmailTaken:    http.StatusConflict,
+		domain.ErrInvalidLocale: http.StatusBadRequest,
+	} {
+		mockUsecase := new(mocks.MockUserUsecase)
+		mockUsecase.On("ResolveUserID", mock.Anything, "1").Return(uint(1), nil)
+		mockUsecase.On("UpdateUser", mock.Anything, uint(1), mock.Anything).
+			Return(nil, fmt.Errorf("failed to update user: %w", err))
+		h := NewUserHandler(mockUsecase)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/users/1", strings.NewReader(`{"name":"New"}`))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		rr := httptest.NewRecorder()
+		h.UpdateUserByID(rr, req)
+
+		assert.Equal(t, status, rr.Code, "wrapped %v", err)
+		assert.Contains(t, rr.Body.String(), err.Error())
+	}
+}
+
+func TestUserHandler_GetUser_PublicID(t *testing.T) {
+	publicID := "01920f3e-8a1b-7c2d-9e3f-4a5b6c7d8e9f"
+	mockUsecase := new(mocks.MockUserUsecase)
+	mockUsecase.On("ResolveUserID", mock.Anything, publicID).Return(uint(7), nil)
+	mockUsecase.On("ResolveUserID", mock.Anything, "7").Return(uint(0), domain.ErrUserNotFound)
+	mockUsecase.On("GetUserByID", mock.Anything, uint(7)).Return(&domain.UserResponse{ID: 7, PublicID: publicID, Name: "Opaque"}, nil)
+	h := NewUserHandler(mockUsecase)
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/users/"+id, nil), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		h.GetUser(rr, req)
+		return rr
+	}
+
+	rr := get(publicID)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":"`+publicID+`"`)
+	assert.NotContains(t, rr.Body.String(), `"id":7`)
+
+	assert.Equal(t, http.StatusNotFound, get("7").Code, "numeric IDs are not accepted alongside public IDs")
+}
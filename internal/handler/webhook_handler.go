@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/internal/usecase"
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler handles webhook subscription requests
+type WebhookHandler struct {
+	webhookUsecase usecase.WebhookUsecase
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookUsecase usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+// CreateWebhook registers a new webhook subscription
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user ID from JWT context
+	userID, ok := r.Context().Value("user_id").(uint)
+	if !ok {
+		response.Error(w, r, "Invalid user context", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.webhookUsecase.CreateSubscription(r.Context(), userID, &req)
+	if err != nil {
+		RespondError(w, r, err, "Failed to create webhook")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Webhook created successfully",
+		"webhook": webhook,
+	}, http.StatusCreated)
+}
+
+// GetAllWebhooks returns all webhook subscriptions with pagination
+func (h *WebhookHandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, offset := parsePagination(r)
+
+	webhooks, err := h.webhookUsecase.GetAllSubscriptions(r.Context(), limit, offset)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get webhooks")
+		return
+	}
+
+	response.List(w, "Webhooks retrieved successfully", "webhooks", webhooks, response.Page{Limit: limit, Offset: offset}, nil)
+}
+
+// GetWebhook returns a specific webhook subscription by ID
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "Webhook")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookUsecase.GetSubscription(r.Context(), webhookID)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get webhook")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Webhook retrieved successfully",
+		"webhook": webhook,
+	}, http.StatusOK)
+}
+
+// UpdateWebhook updates a specific webhook subscription by ID
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "Webhook")
+	if !ok {
+		return
+	}
+
+	var req domain.WebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.webhookUsecase.UpdateSubscription(r.Context(), webhookID, &req)
+	if err != nil {
+		RespondError(w, r, err, "Failed to update webhook")
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"message": "Webhook updated successfully",
+		"webhook": webhook,
+	}, http.StatusOK)
+}
+
+// DeleteWebhook deletes a specific webhook subscription by ID
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "Webhook")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookUsecase.DeleteSubscription(r.Context(), webhookID); err != nil {
+		RespondError(w, r, err, "Failed to delete webhook")
+		return
+	}
+
+	response.Message(w, "Webhook deleted successfully", http.StatusOK)
+}
+
+// GetWebhookDeliveries returns the delivery history of a webhook subscription
+func (h *WebhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	webhookID, ok := parseIDParam(w, r, "Webhook")
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePagination(r)
+
+	deliveries, err := h.webhookUsecase.GetDeliveries(r.Context(), webhookID, limit, offset)
+	if err != nil {
+		RespondError(w, r, err, "Failed to get deliveries")
+		return
+	}
+
+	response.List(w, "Deliveries retrieved successfully", "deliveries", deliveries, response.Page{Limit: limit, Offset: offset}, nil)
+}
+
+// parseIDParam parses the {id} route variable, writing a 400 response on failure
+func parseIDParam(w http.ResponseWriter, r *http.Request, resource string) (uint, bool) {
+	idStr, exists := mux.Vars(r)["id"]
+	if !exists {
+		response.Error(w, r, resource+" ID is required", http.StatusBadRequest)
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(w, r, "Invalid "+strings.ToLower(resource)+" ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
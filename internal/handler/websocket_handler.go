@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/realtime"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/gorilla/websocket"
+)
+
+// tokenSubprotocol is the WebSocket subprotocol used to pass the JWT in the
+// handshake: "Sec-WebSocket-Protocol: access_token, <jwt>"
+const tokenSubprotocol = "access_token"
+
+// WebSocketHandler handles WebSocket connection requests
+type WebSocketHandler struct {
+	hub       *realtime.Hub
+	jwtSecret string
+	upgrader  websocket.Upgrader
+}
+
+// NewWebSocketHandler creates a new WebSocket handler
+func NewWebSocketHandler(hub *realtime.Hub, jwtSecret string) *WebSocketHandler {
+	return &WebSocketHandler{
+		hub:       hub,
+		jwtSecret: jwtSecret,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			Subprotocols:    []string{tokenSubprotocol},
+			// Authentication uses an explicit token rather than cookies,
+			// so cross-origin connections are safe to accept
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// ServeWS authenticates the handshake and upgrades the connection
+func (h *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := websocketToken(r)
+	if token == "" {
+		response.Error(w, r, "Token required", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := utils.ValidateJWT(token, h.jwtSecret)
+	if err != nil || claims.Tenant != database.TenantID(r.Context()) {
+		response.Error(w, r, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	// Upgrade writes its own error response on failure
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("WebSocket upgrade failed", "error", err)
+		return
+	}
+
+	h.hub.Register(claims.Tenant, claims.UserID, conn)
+}
+
+// websocketToken extracts the JWT from the "token" query parameter or the
+// access_token subprotocol (browsers cannot set headers on WebSocket requests)
+func websocketToken(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if protocol == tokenSubprotocol && i+1 < len(protocols) {
+			return strings.TrimSpace(protocols[i+1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,350 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/internal/worker"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"gorm.io/gorm"
+)
+
+// Handler runs a job. The context is canceled when the pool stops; a job
+// interrupted that way is put back in the queue. Failed jobs are retried
+// following their type's RetryPolicy unless the error is Permanent.
+type Handler func(ctx context.Context, job *domain.Job) error
+
+// registration is a job type's handler and retry policy
+type registration struct {
+	handler Handler
+	retry   RetryPolicy
+}
+
+// HandlerOption configures how jobs of one type are run
+type HandlerOption func(*registration)
+
+// WithMaxAttempts sets how many times jobs of the type run before they fail
+// for good, overriding the pool's retry policy
+func WithMaxAttempts(n int) HandlerOption {
+	return func(r *registration) {
+		if n > 0 {
+			r.retry.MaxAttempts = n
+		}
+	}
+}
+
+// WithRetryDelays sets the first and maximum delay between attempts for jobs
+// of the type, overriding the pool's retry policy
+func WithRetryDelays(base, max time.Duration) HandlerOption {
+	return func(r *registration) {
+		r.retry.BaseDelay = base
+		r.retry.MaxDelay = max
+	}
+}
+
+// TenantDBProvider returns a tenant's database, connecting to it on first use
+type TenantDBProvider interface {
+	DB(ctx context.Context, tenantID string) (*gorm.DB, error)
+}
+
+// now is the queue's clock; swapped out in tests
+var now = func() time.Time { return time.Now().UTC() }
+
+// Pool consumes the job queue: it polls for due jobs and runs up to its
+// concurrency at once. It implements worker.Worker, and reports each run to
+// the worker Manager under the job's type.
+type Pool struct {
+	repo         repository.JobRepository
+	handlers     map[string]registration
+	retry        RetryPolicy
+	concurrency  int
+	pollInterval time.Duration
+	lockTimeout  time.Duration
+	aging        time.Duration
+	recorder     worker.RunRecorder
+	tenants      TenantDBProvider
+
+	slots  chan struct{}
+	wake   chan struct{}
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	logger *slog.Logger
+}
+
+// PoolOption configures optional Pool behaviour
+type PoolOption func(*Pool)
+
+// WithConcurrency sets how many jobs run at once (default 4)
+func WithConcurrency(n int) PoolOption {
+	return func(p *Pool) {
+		if n > 0 {
+			p.concurrency = n
+		}
+	}
+}
+
+// WithPollInterval sets how often the queue is polled for due jobs (default 1s)
+func WithPollInterval(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		if d > 0 {
+			p.pollInterval = d
+		}
+	}
+}
+
+// WithLockTimeout sets how long a job may run before it is presumed
+// abandoned and claimed again (default 10m). It must exceed the longest
+// job's running time.
+func WithLockTimeout(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		if d > 0 {
+			p.lockTimeout = d
+		}
+	}
+}
+
+// WithPriorityAging sets how long a job may wait past its RunAt before it is
+// claimed ahead of higher priorities, so a steady stream of high-priority
+// jobs can't starve the rest (default 5m)
+func WithPriorityAging(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		if d > 0 {
+			p.aging = d
+		}
+	}
+}
+
+// WithRetryPolicy sets the retry policy of job types registered without
+// their own (default DefaultRetryPolicy)
+func WithRetryPolicy(policy RetryPolicy) PoolOption {
+	return func(p *Pool) {
+		if policy.MaxAttempts > 0 {
+			p.retry = policy
+		}
+	}
+}
+
+// WithTenants runs jobs enqueued for a tenant routed to its database, opened
+// through provider. Without it such jobs fail.
+func WithTenants(provider TenantDBProvider) PoolOption {
+	return func(p *Pool) {
+		p.tenants = provider
+	}
+}
+
+// NewPool creates a pool consuming the jobs in repo
+func NewPool(repo repository.JobRepository, opts ...PoolOption) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		repo:         repo,
+		handlers:     make(map[string]registration),
+		retry:        DefaultRetryPolicy,
+		concurrency:  4,
+		pollInterval: time.Second,
+		lockTimeout:  10 * time.Minute,
+		aging:        5 * time.Minute,
+		ctx:          ctx,
+		cancel:       cancel,
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+		logger:       slog.Default().With("worker", "JobPool"),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.slots = make(chan struct{}, p.concurrency)
+	return p
+}
+
+// Register sets the handler for a job type. Register every handler before
+// starting the pool.
+func (p *Pool) Register(jobType string, handler Handler, opts ...HandlerOption) {
+	reg := registration{handler: handler, retry: p.retry}
+	for _, opt := range opts {
+		opt(&reg)
+	}
+	p.handlers[jobType] = reg
+}
+
+// retryPolicy returns the retry policy of a job type. Types without a
+// handler use the pool's, so an instance running a newer release can still
+// pick them up.
+func (p *Pool) retryPolicy(jobType string) RetryPolicy {
+	if reg, ok := p.handlers[jobType]; ok {
+		return reg.retry
+	}
+	return p.retry
+}
+
+// SetRunRecorder sets where job runs are reported (implements worker.Instrumented)
+func (p *Pool) SetRunRecorder(recorder worker.RunRecorder) {
+	p.recorder = recorder
+}
+
+// Start polls for and runs due jobs until Stop is called (implements worker.Worker)
+func (p *Pool) Start() {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	p.logger.Info("Starting job pool", "concurrency", p.concurrency, "interval", p.pollInterval)
+
+	for {
+		select {
+		case <-ticker.C:
+			p.poll()
+		case <-p.wake:
+			p.poll()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Stop stops polling, cancels running jobs and waits for them to be put back
+// in the queue (implements worker.Worker)
+func (p *Pool) Stop() {
+	close(p.done)
+	p.cancel()
+	p.wg.Wait()
+	p.logger.Info("Stopped job pool")
+}
+
+// RunNow polls for due jobs without waiting for the next tick (implements
+// worker.Triggerable)
+func (p *Pool) RunNow() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Name returns the worker name (implements worker.Worker)
+func (p *Pool) Name() string {
+	return "JobPool"
+}
+
+// poll claims as many due jobs as there are free slots and runs them
+func (p *Pool) poll() {
+	free := p.concurrency - len(p.slots)
+	if free == 0 || p.ctx.Err() != nil {
+		return
+	}
+
+	at := now()
+	claimed, err := p.repo.ClaimDue(p.ctx, at, at.Add(-p.lockTimeout), at.Add(-p.aging), free)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		p.logger.Error("Failed to claim jobs", "error", err)
+		reporting.ReportError(reporting.WithTag(p.ctx, "worker", p.Name()), err)
+	}
+
+	for _, job := range claimed {
+		p.slots <- struct{}{}
+		p.wg.Add(1)
+		go func(job *domain.Job) {
+			defer func() {
+				<-p.slots
+				p.wg.Done()
+			}()
+			p.process(job)
+		}(job)
+	}
+}
+
+// process runs a claimed job and records its outcome
+func (p *Pool) process(job *domain.Job) {
+	logger := p.logger.With("job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts)
+	ctx := reporting.WithTag(p.ctx, "job", job.Type)
+
+	start := time.Now()
+	err := p.run(ctx, job)
+	if p.recorder != nil {
+		p.recorder.RecordRun(job.Type, time.Since(start), err)
+	}
+
+	finished := now()
+	job.LockedAt = nil
+	retry := p.retryPolicy(job.Type)
+	switch {
+	case err != nil && p.ctx.Err() != nil:
+		// Interrupted by shutdown: run it again after the restart, without
+		// counting the attempt
+		logger.Info("Job interrupted, requeued")
+		job.Status = domain.JobStatusPending
+		job.Attempts--
+		job.RunAt = finished
+	case err != nil && !isPermanent(err) && job.Attempts < retry.MaxAttempts:
+		delay := retry.Backoff(job.Attempts)
+		logger.Warn("Job failed, will retry", "error", err, "retry_in", delay)
+		job.Status = domain.JobStatusPending
+		job.LastError = err.Error()
+		job.RunAt = finished.Add(delay)
+	case err != nil:
+		// Only the final failure is reported; retried errors are logged
+		logger.Error("Job failed", "error", err)
+		if !errors.As(err, new(*panicError)) {
+			reporting.ReportError(ctx, err)
+		}
+		job.Status = domain.JobStatusFailed
+		job.LastError = err.Error()
+		job.FinishedAt = &finished
+	default:
+		logger.Debug("Job succeeded", "duration", time.Since(start))
+		job.Status = domain.JobStatusSucceeded
+		job.LastError = ""
+		job.FinishedAt = &finished
+	}
+
+	// Record the outcome even when the pool is stopping
+	if err := p.repo.Update(context.WithoutCancel(ctx), job); err != nil {
+		logger.Error("Failed to update job", "error", err)
+		reporting.ReportError(ctx, err)
+	}
+}
+
+// run calls the job's handler, routed to the job's tenant, turning a panic
+// into an error
+func (p *Pool) run(ctx context.Context, job *domain.Job) (err error) {
+	reg, ok := p.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+	if job.Tenant != "" {
+		if p.tenants == nil {
+			return Permanent(fmt.Errorf("job is for tenant %q but tenant databases aren't configured", job.Tenant))
+		}
+		db, err := p.tenants.DB(ctx, job.Tenant)
+		if err != nil {
+			return fmt.Errorf("failed to open tenant database: %w", err)
+		}
+		ctx = database.WithTenant(ctx, job.Tenant, db)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			p.logger.Error("Job panicked", "job_id", job.ID, "job_type", job.Type, "panic", recovered, "stack", string(debug.Stack()))
+			reporting.ReportPanic(ctx, recovered)
+			err = &panicError{recovered: recovered}
+		}
+	}()
+	return reg.handler(ctx, job)
+}
+
+// panicError is the failure of a job whose handler panicked; the panic has
+// already been reported
+type panicError struct {
+	recovered interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("job panicked: %v", e.recovered)
+}
@@ -0,0 +1,377 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type emailPayload struct {
+	To string `json:"to"`
+}
+
+// runOnce claims and runs the due jobs once, waiting for them to finish
+func runOnce(p *Pool) {
+	p.poll()
+	p.wg.Wait()
+}
+
+func getJob(t *testing.T, repo *memory.JobRepository, id uint) *domain.Job {
+	job, err := repo.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	return job
+}
+
+func TestPool_RunsJobs(t *testing.T) {
+	repo := memory.NewJobRepository()
+	queue := NewQueue(repo)
+	// No retries: failures are final on the first run
+	pool := NewPool(repo, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	ctx := context.Background()
+
+	var sent []string
+	pool.Register("email.send", func(ctx context.Context, job *domain.Job) error {
+		var payload emailPayload
+		if err := DecodePayload(job, &payload); err != nil {
+			return err
+		}
+		sent = append(sent, payload.To)
+		return nil
+	})
+	pool.Register("export.users", func(ctx context.Context, job *domain.Job) error {
+		return errors.New("disk full")
+	})
+	pool.Register("report.build", func(ctx context.Context, job *domain.Job) error {
+		panic("nil map")
+	})
+
+	email, err := queue.Enqueue(ctx, "email.send", emailPayload{To: "alice@example.com"})
+	require.NoError(t, err)
+	export, err := queue.Enqueue(ctx, "export.users", nil)
+	require.NoError(t, err)
+	report, err := queue.Enqueue(ctx, "report.build", nil)
+	require.NoError(t, err)
+	unknown, err := queue.Enqueue(ctx, "unknown", nil)
+	require.NoError(t, err)
+
+	runOnce(pool)
+
+	assert.Equal(t, []string{"alice@example.com"}, sent)
+	job := getJob(t, repo, email.ID)
+	assert.Equal(t, domain.JobStatusSucceeded, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Nil(t, job.LockedAt)
+
+	job = getJob(t, repo, export.ID)
+	assert.Equal(t, domain.JobStatusFailed, job.Status)
+	assert.Equal(t, "disk full", job.LastError)
+
+	assert.Equal(t, "job panicked: nil map", getJob(t, repo, report.ID).LastError)
+	assert.Equal(t, `no handler registered for job type "unknown"`, getJob(t, repo, unknown.ID).LastError)
+
+	// Finished jobs are not run again
+	runOnce(pool)
+	assert.Len(t, sent, 1)
+}
+
+func TestPool_RespectsConcurrency(t *testing.T) {
+	repo := memory.NewJobRepository()
+	queue := NewQueue(repo)
+	pool := NewPool(repo, WithConcurrency(2))
+
+	release := make(chan struct{})
+	pool.Register("slow", func(ctx context.Context, job *domain.Job) error {
+		<-release
+		return nil
+	})
+	for range 3 {
+		_, err := queue.Enqueue(context.Background(), "slow", nil)
+		require.NoError(t, err)
+	}
+
+	pool.poll()
+	pool.poll()
+	assert.Len(t, pool.slots, 2)
+	close(release)
+	pool.wg.Wait()
+
+	runOnce(pool)
+	for id := uint(1); id <= 3; id++ {
+		assert.Equal(t, domain.JobStatusSucceeded, getJob(t, repo, id).Status)
+	}
+}
+
+func TestPool_StopRequeuesRunningJobs(t *testing.T) {
+	repo := memory.NewJobRepository()
+	pool := NewPool(repo, WithPollInterval(10*time.Millisecond))
+
+	started := make(chan struct{})
+	pool.Register("long", func(ctx context.Context, job *domain.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	job, err := NewQueue(repo).Enqueue(context.Background(), "long", nil)
+	require.NoError(t, err)
+
+	go pool.Start()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not start")
+	}
+	pool.Stop()
+
+	job = getJob(t, repo, job.ID)
+	assert.Equal(t, domain.JobStatusPending, job.Status)
+	assert.Zero(t, job.Attempts, "an interrupted run is not counted")
+	assert.Nil(t, job.LockedAt)
+}
+
+func TestQueue_Enqueue(t *testing.T) {
+	queue := NewQueue(memory.NewJobRepository())
+
+	_, err := queue.Enqueue(context.Background(), "", nil)
+	assert.EqualError(t, err, "job type is required")
+
+	_, err = queue.Enqueue(context.Background(), "bad", make(chan int))
+	assert.ErrorContains(t, err, "failed to encode job payload")
+
+	job, err := queue.Enqueue(context.Background(), "email.send", emailPayload{To: "bob@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"to":"bob@example.com"}`, job.Payload)
+	assert.Equal(t, domain.JobStatusPending, job.Status)
+}
+
+func TestPool_RetriesFailedJobs(t *testing.T) {
+	repo := memory.NewJobRepository()
+	queue := NewQueue(repo)
+	pool := NewPool(repo, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}))
+	ctx := context.Background()
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	jitter = func(n time.Duration) time.Duration { return 0 }
+	t.Cleanup(func() {
+		now = func() time.Time { return time.Now().UTC() }
+		jitter = func(n time.Duration) time.Duration { return time.Duration(rand.Int64N(int64(n))) }
+	})
+
+	pool.Register("flaky", func(ctx context.Context, job *domain.Job) error {
+		return fmt.Errorf("attempt %d failed", job.Attempts)
+	})
+	pool.Register("once", func(ctx context.Context, job *domain.Job) error {
+		return errors.New("gateway rejected")
+	}, WithMaxAttempts(1))
+	pool.Register("invalid", func(ctx context.Context, job *domain.Job) error {
+		var payload emailPayload
+		return DecodePayload(job, &payload)
+	})
+
+	flaky, err := queue.Enqueue(ctx, "flaky", nil)
+	require.NoError(t, err)
+	once, err := queue.Enqueue(ctx, "once", nil)
+	require.NoError(t, err)
+	invalid, err := queue.Enqueue(ctx, "invalid", "not an object")
+	require.NoError(t, err)
+
+	runOnce(pool)
+	job := getJob(t, repo, flaky.ID)
+	assert.Equal(t, domain.JobStatusPending, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "attempt 1 failed", job.LastError)
+	// Half of the one-minute delay is jitter, zero here
+	assert.Equal(t, clock.Add(30*time.Second), job.RunAt)
+	assert.Equal(t, domain.JobStatusFailed, getJob(t, repo, once.ID).Status, "per-type max attempts")
+	assert.Equal(t, domain.JobStatusFailed, getJob(t, repo, invalid.ID).Status, "permanent errors are not retried")
+
+	// Not due until the backoff has passed
+	runOnce(pool)
+	assert.Equal(t, 1, getJob(t, repo, flaky.ID).Attempts)
+
+	clock = clock.Add(time.Hour)
+	runOnce(pool)
+	clock = clock.Add(time.Hour)
+	runOnce(pool)
+	job = getJob(t, repo, flaky.ID)
+	assert.Equal(t, domain.JobStatusFailed, job.Status)
+	assert.Equal(t, 3, job.Attempts)
+	assert.Equal(t, "attempt 3 failed", job.LastError)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Second, MaxDelay: time.Minute}
+
+	for attempts, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 50: time.Minute} {
+		for range 20 {
+			delay := policy.Backoff(attempts)
+			assert.GreaterOrEqual(t, delay, want/2, "attempt %d", attempts)
+			assert.Less(t, delay, want, "attempt %d", attempts)
+		}
+	}
+	assert.Zero(t, RetryPolicy{}.Backoff(3))
+}
+
+func TestQueue_DelayedJobs(t *testing.T) {
+	repo := memory.NewJobRepository()
+	queue := NewQueue(repo)
+	pool := NewPool(repo)
+	ctx := context.Background()
+
+	var ran []string
+	pool.Register("reminder", func(ctx context.Context, job *domain.Job) error {
+		var payload emailPayload
+		if err := DecodePayload(job, &payload); err != nil {
+			return err
+		}
+		ran = append(ran, payload.To)
+		return nil
+	})
+
+	start := now()
+	later, err := queue.Enqueue(ctx, "reminder", emailPayload{To: "later@example.com"}, WithDelay(24*time.Hour))
+	require.NoError(t, err)
+	assert.WithinDuration(t, start.Add(24*time.Hour), later.RunAt, time.Second)
+	canceled, err := queue.Enqueue(ctx, "reminder", emailPayload{To: "canceled@example.com"}, WithRunAt(start.Add(time.Hour)))
+	require.NoError(t, err)
+
+	runOnce(pool)
+	assert.Empty(t, ran, "jobs aren't run before they are due")
+
+	require.NoError(t, queue.Cancel(ctx, canceled.ID))
+	assert.Equal(t, domain.JobStatusCanceled, getJob(t, repo, canceled.ID).Status)
+	assert.ErrorIs(t, queue.Cancel(ctx, canceled.ID), ErrJobNotPending)
+	assert.ErrorIs(t, queue.Cancel(ctx, 999), ErrJobNotFound)
+
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return start.Add(25 * time.Hour) }
+	runOnce(pool)
+	assert.Equal(t, []string{"later@example.com"}, ran, "canceled jobs never run")
+	assert.Equal(t, domain.JobStatusSucceeded, getJob(t, repo, later.ID).Status)
+	assert.ErrorIs(t, queue.Cancel(ctx, later.ID), ErrJobNotPending)
+}
+
+func TestPool_ClaimsHigherPrioritiesFirst(t *testing.T) {
+	repo := memory.NewJobRepository()
+	queue := NewQueue(repo)
+	pool := NewPool(repo, WithConcurrency(1), WithPriorityAging(time.Hour))
+	ctx := context.Background()
+
+	var ran []string
+	pool.Register("email.send", func(ctx context.Context, job *domain.Job) error {
+		var payload emailPayload
+		if err := DecodePayload(job, &payload); err != nil {
+			return err
+		}
+		ran = append(ran, payload.To)
+		return nil
+	})
+
+	start := now()
+	_, err := queue.Enqueue(ctx, "email.send", emailPayload{To: "export@example.com"}, WithPriority(domain.JobPriorityLow))
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "email.send", emailPayload{To: "digest@example.com"})
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "email.send", emailPayload{To: "reset@example.com"}, WithPriority(domain.JobPriorityHigh))
+	require.NoError(t, err)
+
+	runOnce(pool)
+	assert.Equal(t, []string{"reset@example.com"}, ran)
+
+	// Once a low-priority job has waited past the aging threshold, it goes
+	// ahead of newer high-priority jobs
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return start.Add(2 * time.Hour) }
+	_, err = queue.Enqueue(ctx, "email.send", emailPayload{To: "reset2@example.com"}, WithPriority(domain.JobPriorityHigh))
+	require.NoError(t, err)
+
+	runOnce(pool)
+	runOnce(pool)
+	runOnce(pool)
+	assert.Equal(t, []string{"reset@example.com", "digest@example.com", "export@example.com", "reset2@example.com"}, ran)
+}
+
+// tenantDBs is a TenantDBProvider over already open databases
+type tenantDBs map[string]*gorm.DB
+
+func (t tenantDBs) DB(ctx context.Context, tenantID string) (*gorm.DB, error) {
+	if db, ok := t[tenantID]; ok {
+		return db, nil
+	}
+	return nil, errors.New("unknown tenant")
+}
+
+// newSQLiteDB opens a migrated SQLite database private to the test
+func newSQLiteDB(t *testing.T, name string) *gorm.DB {
+	db, err := database.Connect(&config.DatabaseConfig{
+		Driver:     database.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), name+".db"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, database.MigrateUp(context.Background(), db, database.DriverSQLite))
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestPool_TenantJobs(t *testing.T) {
+	shared, acme := newSQLiteDB(t, "shared"), newSQLiteDB(t, "acme")
+	repo := repository.NewJobRepository(shared)
+	queue := NewQueue(repo)
+	pool := NewPool(repo, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithTenants(tenantDBs{"acme": acme}))
+
+	var ranFor string
+	var ranOn *gorm.DB
+	pool.Register("email.send", func(ctx context.Context, job *domain.Job) error {
+		ranFor, ranOn = database.TenantID(ctx), database.TenantDB(ctx)
+		return nil
+	})
+
+	// Enqueued during an acme request, the job still goes to the shared
+	// queue the pool polls
+	ctx := database.WithTenant(context.Background(), "acme", acme)
+	job, err := queue.Enqueue(ctx, "email.send", emailPayload{To: "alice@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", job.Tenant)
+
+	var count int64
+	require.NoError(t, acme.Model(&domain.Job{}).Count(&count).Error)
+	assert.Zero(t, count, "jobs aren't written to the tenant database")
+
+	runOnce(pool)
+
+	assert.Equal(t, "acme", ranFor, "the job runs for the tenant it was enqueued for")
+	assert.Same(t, acme, ranOn)
+	stored, err := repo.GetByID(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusSucceeded, stored.Status)
+
+	// A job for a tenant the pool can't open fails
+	gone, err := queue.Enqueue(database.WithTenant(context.Background(), "globex", acme), "email.send", nil)
+	require.NoError(t, err)
+	runOnce(pool)
+	stored, err = repo.GetByID(context.Background(), gone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusFailed, stored.Status)
+
+	// Cancel finds the job in the shared queue from a tenant request too
+	later, err := queue.Enqueue(ctx, "email.send", nil, WithDelay(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, queue.Cancel(ctx, later.ID))
+}
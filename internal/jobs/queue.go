@@ -0,0 +1,137 @@
+// Package jobs is a persistent background job queue. Usecases enqueue work
+// such as emails, webhook deliveries and exports through an Enqueuer; a Pool
+// claims due jobs from the jobs table and runs them with the handler
+// registered for their type. Jobs survive restarts: a job whose consumer died
+// mid-run is claimed again once its lock times out.
+//
+// Jobs can be scheduled for later with WithRunAt or WithDelay, and canceled
+// until a consumer claims them.
+//
+// The queue lives in the shared database. In database-per-tenant mode a job
+// remembers the tenant it was enqueued for, and the Pool runs it routed to
+// that tenant's database.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+)
+
+// Errors returned by Queue.Cancel
+var (
+	ErrJobNotFound   = errors.New("job not found")
+	ErrJobNotPending = errors.New("job has already started or finished")
+)
+
+// Enqueuer adds jobs to the queue and cancels them before they run. Usecases
+// depend on this interface rather than on the Queue.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*domain.Job, error)
+	Cancel(ctx context.Context, id uint) error
+}
+
+// EnqueueOption configures an enqueued job
+type EnqueueOption func(*domain.Job)
+
+// WithRunAt makes the job due at t rather than immediately, e.g. for a
+// reminder sent the next morning
+func WithRunAt(t time.Time) EnqueueOption {
+	return func(job *domain.Job) {
+		job.RunAt = t.UTC()
+	}
+}
+
+// WithPriority sets the job's priority, e.g. domain.JobPriorityHigh for a
+// password reset email that mustn't wait behind bulk exports (default
+// domain.JobPriorityDefault)
+func WithPriority(priority int) EnqueueOption {
+	return func(job *domain.Job) {
+		job.Priority = priority
+	}
+}
+
+// WithDelay makes the job due d from now rather than immediately
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(job *domain.Job) {
+		job.RunAt = now().Add(d)
+	}
+}
+
+// Queue enqueues jobs in the job repository
+type Queue struct {
+	repo repository.JobRepository
+}
+
+// NewQueue creates a queue storing jobs in repo
+func NewQueue(repo repository.JobRepository) *Queue {
+	return &Queue{repo: repo}
+}
+
+// Enqueue stores a job of the given type, due immediately unless an option
+// schedules it for later. The payload is stored as JSON and decoded by the
+// handler with DecodePayload. The job is stored in the shared database,
+// tagged with the tenant ctx is routed to.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*domain.Job, error) {
+	if jobType == "" {
+		return nil, errors.New("job type is required")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job := &domain.Job{
+		Type:    jobType,
+		Payload: string(data),
+		Status:  domain.JobStatusPending,
+		Tenant:  database.TenantID(ctx),
+		RunAt:   now(),
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := q.repo.Create(database.WithoutTenant(ctx), job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// Cancel stops a pending job from running; a job waiting to be retried can be
+// canceled too. It returns ErrJobNotPending once a consumer has claimed the
+// job or it has finished.
+func (q *Queue) Cancel(ctx context.Context, id uint) error {
+	ctx = database.WithoutTenant(ctx)
+	canceled, err := q.repo.Cancel(ctx, id, now())
+	if err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if canceled {
+		return nil
+	}
+
+	job, err := q.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if job == nil {
+		return ErrJobNotFound
+	}
+	return ErrJobNotPending
+}
+
+// DecodePayload decodes a job's JSON payload into v. Decoding errors are
+// Permanent, as retrying won't fix the payload.
+func DecodePayload(job *domain.Job, v interface{}) error {
+	if err := json.Unmarshal([]byte(job.Payload), v); err != nil {
+		return Permanent(fmt.Errorf("failed to decode %s job payload: %w", job.Type, err))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy decides whether and when a failed job runs again
+type RetryPolicy struct {
+	// MaxAttempts is the total number of runs, including the first; 1
+	// disables retries
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles with every
+	// further attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts; zero means one hour
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy runs a job up to 5 times, 10s, 20s, 40s and 80s apart
+// (before jitter)
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Second, MaxDelay: time.Hour}
+
+// jitter returns a random duration in [0, n); swapped out in tests
+var jitter = func(n time.Duration) time.Duration { return time.Duration(rand.Int64N(int64(n))) }
+
+// Backoff returns the delay before retrying a job that has run attempts
+// times: BaseDelay doubled per attempt after the first, capped at MaxDelay.
+// Half of the delay is random, so jobs that failed together, for example
+// during an outage, don't all retry at the same moment.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = time.Hour
+	}
+	delay := min(p.BaseDelay, maxDelay)
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay = min(delay*2, maxDelay)
+	}
+	if delay <= 1 {
+		return max(delay, 0)
+	}
+	half := delay / 2
+	return half + jitter(delay-half)
+}
+
+// permanentError marks a failure that retrying can't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails without further attempts, e.g. when
+// its payload is invalid
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isPermanent reports whether err was wrapped with Permanent
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
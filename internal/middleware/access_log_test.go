@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog_Combined(t *testing.T) {
+	var out bytes.Buffer
+	accessLog, err := NewAccessLog(AccessLogCombined, &out, nil)
+	require.NoError(t, err)
+	accessLog.now = func() time.Time {
+		return time.Date(2024, time.March, 5, 14, 7, 9, 0, time.FixedZone("", 7*3600))
+	}
+
+	handler := accessLog.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users?page=2", nil)
+	req.RemoteAddr = "203.0.113.9:52100"
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", `curl/8.0 "quoted"`)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t,
+		`203.0.113.9 - - [05/Mar/2024:14:07:09 +0700] "POST /api/users?page=2 HTTP/1.1" 201 8 "https://example.com/" "curl/8.0 \"quoted\""`+"\n",
+		out.String())
+}
+
+func TestAccessLog_CombinedRedactsCredentials(t *testing.T) {
+	var out bytes.Buffer
+	accessLog, err := NewAccessLog(AccessLogCombined, &out, nil)
+	require.NoError(t, err)
+
+	handler := accessLog.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, target := range []string{
+		"/ws?token=eyJhbGciOiJIUzI1NiJ9.secret-jwt",
+		"/api/auth/verify-email?Token=eyJhbGciOiJIUzI1NiJ9.secret-jwt",
+		"/storage/avatars/1.png?expires=1700000000&signature=secret-jwt",
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	assert.NotContains(t, out.String(), "secret-jwt")
+	assert.Contains(t, out.String(), `"GET /ws?token=REDACTED HTTP/1.1"`)
+	assert.Contains(t, out.String(), `"GET /storage/avatars/1.png?expires=1700000000&signature=REDACTED HTTP/1.1"`, "other parameters are kept")
+}
+
+func TestAccessLog_CombinedEmptyBody(t *testing.T) {
+	var out bytes.Buffer
+	accessLog, err := NewAccessLog(AccessLogCombined, &out, nil)
+	require.NoError(t, err)
+
+	handler := accessLog.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/1", nil)
+	req.SetBasicAuth("ops", "secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, out.String(), ` - ops [`)
+	assert.Contains(t, out.String(), `"DELETE /api/users/1 HTTP/1.1" 204 - "" ""`)
+}
+
+func TestAccessLog_Structured(t *testing.T) {
+	var out bytes.Buffer
+	accessLog, err := NewAccessLog(AccessLogStructured, nil, nil)
+	require.NoError(t, err)
+
+	handler := RequestID(accessLog.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	req = req.WithContext(logger.NewContext(req.Context(), logger.New(&out, slog.LevelInfo, logger.FormatText)))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, out.String(), `msg=request request_id=req-42 method=GET path=/health status=200 bytes=2`)
+}
+
+func TestNewAccessLog_UnsupportedFormat(t *testing.T) {
+	_, err := NewAccessLog("common", nil, nil)
+	assert.Error(t, err)
+}
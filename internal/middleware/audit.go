@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reqstats"
+)
+
+// AuditRecorder stores audit log entries; repository.AuditRepository
+// satisfies it
+type AuditRecorder interface {
+	Create(ctx context.Context, entry *domain.AuditLog) error
+}
+
+// AuditLog records every mutating request (POST, PUT, PATCH and DELETE) with
+// its actor, outcome and a hash of its payload, so auditing doesn't depend on
+// each usecase remembering to log
+type AuditLog struct {
+	recorder AuditRecorder
+	resolver *ClientIPResolver
+}
+
+// NewAuditLog creates an audit log writing to recorder. Client addresses are
+// resolved through resolver so proxied requests show the real client.
+func NewAuditLog(recorder AuditRecorder, resolver *ClientIPResolver) *AuditLog {
+	if resolver == nil {
+		resolver = &ClientIPResolver{}
+	}
+	return &AuditLog{recorder: recorder, resolver: resolver}
+}
+
+// Middleware records mutating requests once they have been handled. It must
+// be mounted outside the auth middleware, which reports the actor through
+// reqstats, and inside tenant resolution, so entries land in the tenant's
+// database. A nil AuditLog records nothing.
+func (a *AuditLog) Middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		stats := reqstats.FromContext(ctx)
+		if stats == nil {
+			ctx, stats = reqstats.NewContext(ctx)
+			r = r.WithContext(ctx)
+		}
+
+		// Hash the body as the handler reads it rather than buffering it,
+		// since uploads can be large
+		var body *hashingReader
+		if r.Body != nil && r.Body != http.NoBody {
+			body = &hashingReader{ReadCloser: r.Body, hash: sha256.New()}
+			r.Body = body
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		entry := &domain.AuditLog{
+			ActorID:     actorID(stats.User()),
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Route:       routeLabel(r),
+			Status:      rec.status,
+			PayloadHash: body.sum(),
+			RequestID:   w.Header().Get(RequestIDHeader),
+		}
+		if ip := a.resolver.ClientIP(r); ip != nil {
+			entry.ClientIP = ip.String()
+		}
+
+		// The client may already have gone; the record is still wanted
+		if err := a.recorder.Create(context.WithoutCancel(ctx), entry); err != nil {
+			logger.FromContext(ctx).Error("Failed to write audit log", "method", entry.Method, "path", entry.Path, "error", err)
+			reporting.ReportError(ctx, err)
+		}
+	})
+}
+
+// isMutating reports whether requests with the method change state
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// actorID parses the user ID reported by the auth middleware; anonymous
+// requests have none
+func actorID(user string) *uint {
+	id, err := strconv.ParseUint(user, 10, 64)
+	if err != nil {
+		return nil
+	}
+	actor := uint(id)
+	return &actor
+}
+
+// hashingReader hashes a request body as it is read
+type hashingReader struct {
+	io.ReadCloser
+	hash hash.Hash
+	n    int64
+}
+
+func (h *hashingReader) Read(p []byte) (int, error) {
+	n, err := h.ReadCloser.Read(p)
+	h.hash.Write(p[:n])
+	h.n += int64(n)
+	return n, err
+}
+
+// sum returns the hex SHA-256 of the bytes read, or "" when none were. Bodies
+// a handler rejects without reading, such as unauthenticated requests, are
+// recorded without a hash.
+func (h *hashingReader) sum() string {
+	if h == nil || h.n == 0 {
+		return ""
+	}
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
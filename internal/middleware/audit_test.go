@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuditRouter(repo *memory.AuditRepository) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(RequestID)
+	router.Use(NewAuditLog(repo, nil).Middleware)
+
+	router.HandleFunc("/api/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}).Methods(http.MethodPost)
+
+	users := router.PathPrefix("/api/users").Subrouter()
+	users.Use(AuthMiddleware("secret"))
+	users.HandleFunc("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+	}).Methods(http.MethodGet, http.MethodPut)
+	return router
+}
+
+func TestAuditLog(t *testing.T) {
+	repo := memory.NewAuditRepository()
+	router := newAuditRouter(repo)
+	token, err := utils.GenerateJWT(7, "alice@example.com", "secret")
+	require.NoError(t, err)
+
+	t.Run("authenticated update", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/users/42", strings.NewReader(`{"name":"Bob"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.RemoteAddr = "203.0.113.9:4321"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		entries, err := repo.List(context.Background(), 1, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		entry := entries[0]
+
+		sum := sha256.Sum256([]byte(`{"name":"Bob"}`))
+		require.NotNil(t, entry.ActorID)
+		assert.Equal(t, uint(7), *entry.ActorID)
+		assert.Equal(t, http.MethodPut, entry.Method)
+		assert.Equal(t, "/api/users/42", entry.Path)
+		assert.Equal(t, "/api/users/{id}", entry.Route)
+		assert.Equal(t, http.StatusOK, entry.Status)
+		assert.Equal(t, hex.EncodeToString(sum[:]), entry.PayloadHash)
+		assert.Equal(t, rec.Header().Get(RequestIDHeader), entry.RequestID)
+		assert.Equal(t, "203.0.113.9", entry.ClientIP)
+	})
+
+	t.Run("anonymous request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{}`))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		entries, err := repo.List(context.Background(), 1, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Nil(t, entries[0].ActorID)
+		assert.Equal(t, http.StatusUnauthorized, entries[0].Status)
+		// The handler never read the body
+		assert.Empty(t, entries[0].PayloadHash)
+	})
+
+	t.Run("reads are not audited", func(t *testing.T) {
+		before, err := repo.List(context.Background(), 0, 0)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/users/42", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		after, err := repo.List(context.Background(), 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, after, len(before))
+	})
+}
+
+func TestAuditLog_Nil(t *testing.T) {
+	var audit *AuditLog
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := audit.Middleware(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
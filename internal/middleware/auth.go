@@ -2,10 +2,16 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reqstats"
 	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
 )
 
@@ -16,33 +22,37 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 			// Get Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				writeErrorResponse(w, "Authorization header required", http.StatusUnauthorized)
+				response.Error(w, r, "Authorization header required", http.StatusUnauthorized)
 				return
 			}
 
 			// Check if it starts with "Bearer "
 			if !strings.HasPrefix(authHeader, "Bearer ") {
-				writeErrorResponse(w, "Invalid authorization header format", http.StatusUnauthorized)
+				response.Error(w, r, "Invalid authorization header format", http.StatusUnauthorized)
 				return
 			}
 
 			// Extract token
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 			if token == "" {
-				writeErrorResponse(w, "Token required", http.StatusUnauthorized)
+				response.Error(w, r, "Token required", http.StatusUnauthorized)
 				return
 			}
 
 			// Validate token
 			claims, err := utils.ValidateJWT(token, jwtSecret)
-			if err != nil {
-				writeErrorResponse(w, "Invalid token", http.StatusUnauthorized)
+			if err != nil || claims.Tenant != database.TenantID(r.Context()) {
+				response.Error(w, r, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
 			// Add user info to context
 			ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
 			ctx = context.WithValue(ctx, "user_email", claims.Email)
+			ctx = context.WithValue(ctx, "user_role", claims.Role)
+			ctx = logger.With(ctx, "user_id", claims.UserID)
+			ctx = reporting.WithUser(ctx, strconv.FormatUint(uint64(claims.UserID), 10), claims.Email)
+			reqstats.FromContext(ctx).SetUser(strconv.FormatUint(uint64(claims.UserID), 10))
 
 			// Call next handler with updated context
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -50,12 +60,90 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 	}
 }
 
+// OptionalAuthMiddleware adds the user to the context when a valid bearer token
+// is present, and lets the request through anonymously otherwise. Handlers are
+// responsible for rejecting anonymous access where required.
+func OptionalAuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if !strings.HasPrefix(authHeader, "Bearer ") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := utils.ValidateJWT(strings.TrimPrefix(authHeader, "Bearer "), jwtSecret)
+			if err != nil || claims.Tenant != database.TenantID(r.Context()) {
+				response.Error(w, r, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
+			ctx = context.WithValue(ctx, "user_email", claims.Email)
+			ctx = context.WithValue(ctx, "user_role", claims.Role)
+			ctx = logger.With(ctx, "user_id", claims.UserID)
+			ctx = reporting.WithUser(ctx, strconv.FormatUint(uint64(claims.UserID), 10), claims.Email)
+			reqstats.FromContext(ctx).SetUser(strconv.FormatUint(uint64(claims.UserID), 10))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole creates a middleware that only allows users with the given role.
+// It must be mounted after AuthMiddleware.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userRole, _ := r.Context().Value("user_role").(string)
+			if userRole != role {
+				response.Error(w, r, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PlanLookup returns the billing plan of a user
+type PlanLookup func(ctx context.Context, userID uint) (string, error)
+
+// RequirePlan creates a middleware that only allows users on one of the
+// given plans, gating paid features. The plan is looked up on every request
+// rather than read from the token, so upgrades take effect right away. It
+// must be mounted after AuthMiddleware.
+func RequirePlan(lookup PlanLookup, plans ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value("user_id").(uint)
+			if !ok {
+				response.Error(w, r, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+			plan, err := lookup(r.Context(), userID)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("Failed to look up plan", "error", err)
+				response.Error(w, r, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if !slices.Contains(plans, plan) {
+				response.Error(w, r, "Your plan doesn't include this feature", http.StatusPaymentRequired)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // CORSMiddleware handles Cross-Origin Resource Sharing
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -65,15 +153,3 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
-
-// writeErrorResponse writes an error response in JSON format
-func writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	
-	response := map[string]string{
-		"error": message,
-	}
-	
-	json.NewEncoder(w).Encode(response)
-} 
\ No newline at end of file
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -254,4 +256,82 @@ func TestAuthMiddleware_PreservesRequestMethod(t *testing.T) {
 			assert.Equal(t, method, capturedMethod)
 		})
 	}
-} 
\ No newline at end of file
+} 
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           interface{}
+		expectedStatus int
+	}{
+		{
+			name:           "matching role",
+			role:           "admin",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "different role",
+			role:           "user",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "missing role",
+			role:           nil,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin", nil)
+			if tt.role != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "user_role", tt.role))
+			}
+
+			rr := httptest.NewRecorder()
+			RequireRole("admin")(testHandler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRequirePlan(t *testing.T) {
+	plans := map[uint]string{1: "free", 2: "pro", 3: "team"}
+	lookup := func(ctx context.Context, userID uint) (string, error) {
+		plan, ok := plans[userID]
+		if !ok {
+			return "", errors.New("user not found")
+		}
+		return plan, nil
+	}
+	gated := RequirePlan(lookup, "pro", "team")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		userID         interface{}
+		expectedStatus int
+	}{
+		{name: "included plan", userID: uint(2), expectedStatus: http.StatusOK},
+		{name: "other included plan", userID: uint(3), expectedStatus: http.StatusOK},
+		{name: "free plan", userID: uint(1), expectedStatus: http.StatusPaymentRequired},
+		{name: "lookup failure", userID: uint(4), expectedStatus: http.StatusInternalServerError},
+		{name: "anonymous", userID: nil, expectedStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/reports", nil)
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "user_id", tt.userID))
+			}
+			rr := httptest.NewRecorder()
+			gated.ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+)
+
+// CacheControl lets clients opt out of server-side caching. Requests sent
+// with "Cache-Control: no-cache", "no-store" or "max-age=0", or the legacy
+// "Pragma: no-cache", read from the database and refresh any cached entry.
+func CacheControl(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wantsFreshData(r) {
+			r = r.WithContext(cache.WithBypass(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wantsFreshData reports whether the request's cache directives forbid
+// answering from a cache
+func wantsFreshData(r *http.Request) bool {
+	for _, value := range r.Header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "no-cache", "no-store", "max-age=0":
+				return true
+			}
+		}
+	}
+	return strings.EqualFold(strings.TrimSpace(r.Header.Get("Pragma")), "no-cache")
+}
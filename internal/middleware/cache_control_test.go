@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		bypass  bool
+	}{
+		{"no directives", nil, false},
+		{"no-cache", map[string]string{"Cache-Control": "no-cache"}, true},
+		{"no-store among others", map[string]string{"Cache-Control": "private, No-Store"}, true},
+		{"max-age=0", map[string]string{"Cache-Control": "max-age=0"}, true},
+		{"max-age=60", map[string]string{"Cache-Control": "max-age=60"}, false},
+		{"pragma", map[string]string{"Pragma": "no-cache"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bypassed bool
+			handler := CacheControl(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bypassed = cache.IsBypassed(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.bypass, bypassed)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+)
+
+// CORS answers Cross-Origin Resource Sharing requests from a configured set
+// of origins
+type CORS struct {
+	allowedOrigins []string
+}
+
+// NewCORS creates CORS middleware allowing allowedOrigins; "*" allows any origin
+func NewCORS(allowedOrigins []string) *CORS {
+	return &CORS{allowedOrigins: allowedOrigins}
+}
+
+// Middleware sets the CORS headers and answers preflight requests. A nil
+// CORS allows any origin, as CORSMiddleware does.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	if c == nil || slices.Contains(c.allowedOrigins, "*") {
+		return CORSMiddleware(next)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		if origin := r.Header.Get("Origin"); origin != "" && slices.Contains(c.allowedOrigins, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-Request-Timeout")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+		}
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
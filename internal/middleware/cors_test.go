@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name        string
+		cors        *CORS
+		method      string
+		origin      string
+		allowOrigin string
+		status      int
+	}{
+		{"nil allows any origin", nil, http.MethodGet, "https://evil.example", "*", http.StatusNoContent},
+		{"wildcard", NewCORS([]string{"*"}), http.MethodGet, "https://app.example.com", "*", http.StatusNoContent},
+		{"allowed origin", NewCORS([]string{"https://app.example.com"}), http.MethodGet, "https://app.example.com", "https://app.example.com", http.StatusNoContent},
+		{"other origin", NewCORS([]string{"https://app.example.com"}), http.MethodGet, "https://evil.example", "", http.StatusNoContent},
+		{"preflight", NewCORS([]string{"https://app.example.com"}), http.MethodOptions, "https://app.example.com", "https://app.example.com", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/profile", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			tt.cors.Middleware(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.allowOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"expvar"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// deprecatedRouteRequests counts requests to deprecated routes, keyed by
+// "METHOD /path/template". Exposed through expvar.
+var deprecatedRouteRequests = expvar.NewMap("deprecated_route_requests")
+
+// DeprecationPolicy describes how a deprecated route group is advertised
+type DeprecationPolicy struct {
+	// DeprecatedAt is when the routes were deprecated (zero means "now")
+	DeprecatedAt time.Time
+	// Sunset is when the routes stop working (zero means not scheduled)
+	Sunset time.Time
+	// Successor is the URL of the replacement API, if any
+	Successor string
+	// PolicyURL documents the deprecation, if any
+	PolicyURL string
+}
+
+// Deprecated creates a middleware that adds Deprecation, Sunset and Link
+// headers to every response and records usage of the deprecated routes
+func Deprecated(policy DeprecationPolicy) func(http.Handler) http.Handler {
+	deprecation := "true"
+	if !policy.DeprecatedAt.IsZero() {
+		deprecation = "@" + strconv.FormatInt(policy.DeprecatedAt.Unix(), 10)
+	}
+
+	var sunset string
+	if !policy.Sunset.IsZero() {
+		sunset = policy.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	var links []string
+	if policy.PolicyURL != "" {
+		links = append(links, "<"+policy.PolicyURL+`>; rel="deprecation"; type="text/html"`)
+	}
+	if policy.Successor != "" {
+		links = append(links, "<"+policy.Successor+`>; rel="successor-version"`)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			if sunset != "" {
+				w.Header().Set("Sunset", sunset)
+			}
+			for _, link := range links {
+				w.Header().Add("Link", link)
+			}
+
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if tmpl, err := current.GetPathTemplate(); err == nil {
+					route = tmpl
+				}
+			}
+			deprecatedRouteRequests.Add(r.Method+" "+route, 1)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecated_Headers(t *testing.T) {
+	policy := DeprecationPolicy{
+		DeprecatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+		Successor:    "/api/v1",
+		PolicyURL:    "https://example.com/deprecation",
+	}
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+	rr := httptest.NewRecorder()
+	Deprecated(policy)(testHandler).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "@1767225600", rr.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 31 Dec 2026 00:00:00 GMT", rr.Header().Get("Sunset"))
+	assert.Equal(t, []string{
+		`<https://example.com/deprecation>; rel="deprecation"; type="text/html"`,
+		`</api/v1>; rel="successor-version"`,
+	}, rr.Header().Values("Link"))
+	assert.Equal(t, "1", deprecatedRouteRequests.Get("POST /api/auth/login").String())
+}
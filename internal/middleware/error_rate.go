@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+)
+
+// ErrorRateAlert sends an alert when the share of requests answered with a
+// 5xx status spikes: when, within a window, at least minRequests were
+// served and the fraction of them that failed reaches the threshold. It
+// alerts at most once per window; the notifier's cooldown limits repeats
+// across windows.
+type ErrorRateAlert struct {
+	threshold   float64
+	minRequests int
+	window      time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	start    time.Time
+	requests int
+	failures int
+	alerted  bool
+}
+
+// NewErrorRateAlert creates an error rate alert for windows of the given
+// length
+func NewErrorRateAlert(threshold float64, minRequests int, window time.Duration) *ErrorRateAlert {
+	return &ErrorRateAlert{threshold: threshold, minRequests: minRequests, window: window, now: time.Now}
+}
+
+// Middleware counts each response's status. A nil ErrorRateAlert counts
+// nothing.
+func (e *ErrorRateAlert) Middleware(next http.Handler) http.Handler {
+	if e == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		e.record(r, rec.status)
+	})
+}
+
+// record counts one response and alerts when the window's error rate
+// crosses the threshold
+func (e *ErrorRateAlert) record(r *http.Request, status int) {
+	now := e.now()
+
+	e.mu.Lock()
+	if now.Sub(e.start) >= e.window {
+		e.start, e.requests, e.failures, e.alerted = now, 0, 0, false
+	}
+	e.requests++
+	if status >= http.StatusInternalServerError {
+		e.failures++
+	}
+	requests, failures := e.requests, e.failures
+	fire := !e.alerted && requests >= e.minRequests && float64(failures) >= e.threshold*float64(requests)
+	if fire {
+		e.alerted = true
+	}
+	e.mu.Unlock()
+
+	if !fire {
+		return
+	}
+	alert.Send(r.Context(), alert.Alert{
+		Key:   "http_error_rate",
+		Level: alert.LevelCritical,
+		Title: "Server error rate spike",
+		Text:  fmt.Sprintf("%d of the last %d requests failed with a 5xx status", failures, requests),
+		Fields: []alert.Field{
+			{Name: "Error rate", Value: fmt.Sprintf("%.0f%%", 100*float64(failures)/float64(requests))},
+			{Name: "Threshold", Value: fmt.Sprintf("%.0f%%", 100*e.threshold)},
+			{Name: "Window", Value: e.window.String()},
+			{Name: "Last route", Value: fmt.Sprintf("%s %s", r.Method, routeLabel(r))},
+		},
+	})
+}
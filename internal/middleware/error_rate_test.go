@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alertRecorder records the alerts it is sent
+type alertRecorder struct {
+	alerts []alert.Alert
+}
+
+func (r *alertRecorder) Notify(_ context.Context, a alert.Alert) { r.alerts = append(r.alerts, a) }
+func (r *alertRecorder) Flush(time.Duration) bool                { return true }
+
+func TestErrorRateAlert(t *testing.T) {
+	recorder := &alertRecorder{}
+	alert.SetDefault(recorder)
+	defer alert.SetDefault(nil)
+
+	errorRate := NewErrorRateAlert(0.5, 4, time.Minute)
+	now := time.Unix(0, 0)
+	errorRate.now = func() time.Time { return now }
+
+	router := mux.NewRouter()
+	router.Use(errorRate.Middleware)
+	router.HandleFunc("/api/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	serve := func(fail bool) {
+		target := "/api/users/7"
+		if fail {
+			target += "?fail=1"
+		}
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	// Failures below the minimum request count don't alert
+	serve(true)
+	serve(true)
+	serve(false)
+	assert.Empty(t, recorder.alerts)
+
+	// The fourth request reaches the minimum with half of them failing
+	serve(true)
+	require.Len(t, recorder.alerts, 1)
+	assert.Equal(t, "3 of the last 4 requests failed with a 5xx status", recorder.alerts[0].Text)
+	assert.Contains(t, recorder.alerts[0].Fields, alert.Field{Name: "Last route", Value: "GET /api/users/{id}"})
+
+	// Once per window
+	serve(true)
+	assert.Len(t, recorder.alerts, 1)
+
+	// A new window starts counting again
+	now = now.Add(time.Minute)
+	for range 4 {
+		serve(false)
+	}
+	serve(true)
+	assert.Len(t, recorder.alerts, 1, "one failure in five stays under the threshold")
+	for range 4 {
+		serve(true)
+	}
+	assert.Len(t, recorder.alerts, 2)
+}
+
+func TestErrorRateAlert_Nil(t *testing.T) {
+	var errorRate *ErrorRateAlert
+	handler := errorRate.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+)
+
+// ClientIPResolver extracts the real client IP from a request, honouring
+// X-Forwarded-For only when the direct peer is a trusted proxy
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+}
+
+// NewClientIPResolver creates a resolver that trusts the given proxy CIDRs/IPs
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	nets, err := parseCIDRs(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+	}
+	return &ClientIPResolver{trustedProxies: nets}, nil
+}
+
+// ClientIP returns the client IP for the request
+func (c *ClientIPResolver) ClientIP(r *http.Request) net.IP {
+	remoteIP := parseRemoteAddr(r.RemoteAddr)
+	if remoteIP == nil || !containsIP(c.trustedProxies, remoteIP) {
+		return remoteIP
+	}
+
+	// Walk X-Forwarded-For from right to left, skipping trusted proxies;
+	// the first untrusted hop is the client as seen by our own proxies
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !containsIP(c.trustedProxies, ip) {
+			return ip
+		}
+		remoteIP = ip
+	}
+
+	return remoteIP
+}
+
+// IPFilter blocks denylisted IPs everywhere and restricts configured
+// route groups to an allowlist. With an empty allowlist no one can reach
+// those route groups.
+type IPFilter struct {
+	allowlist         []*net.IPNet
+	allowlistPrefixes []string
+	denylist          []*net.IPNet
+	resolver          *ClientIPResolver
+}
+
+// NewIPFilter creates a new IP filter
+func NewIPFilter(allowlist, allowlistPrefixes, denylist []string, resolver *ClientIPResolver) (*IPFilter, error) {
+	allowNets, err := parseCIDRs(allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowlist entry: %w", err)
+	}
+
+	denyNets, err := parseCIDRs(denylist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denylist entry: %w", err)
+	}
+
+	return &IPFilter{
+		allowlist:         allowNets,
+		allowlistPrefixes: allowlistPrefixes,
+		denylist:          denyNets,
+		resolver:          resolver,
+	}, nil
+}
+
+// Middleware returns the IP filtering middleware
+func (f *IPFilter) Middleware(next http.Handler) http.Handler {
+	if f == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP := f.resolver.ClientIP(r)
+
+		if clientIP != nil && containsIP(f.denylist, clientIP) {
+			response.Error(w, r, "Access denied", http.StatusForbidden)
+			return
+		}
+
+		if f.isRestricted(r.URL.Path) && (clientIP == nil || !containsIP(f.allowlist, clientIP)) {
+			response.Error(w, r, "Access denied", http.StatusForbidden)
+			return
+		}
+
+		// Add client IP to context for downstream handlers
+		ctx := r.Context()
+		if clientIP != nil {
+			ctx = context.WithValue(ctx, "client_ip", clientIP.String())
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// isRestricted reports whether the path belongs to an allowlisted route group
+func (f *IPFilter) isRestricted(path string) bool {
+	for _, prefix := range f.allowlistPrefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCIDRs parses a list of CIDRs or bare IPs
+func parseCIDRs(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP or CIDR", entry)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP or CIDR", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// parseRemoteAddr extracts the IP from a host:port remote address
+func parseRemoteAddr(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// containsIP reports whether any of the networks contains the IP
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
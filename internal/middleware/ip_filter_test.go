@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		xForwardedFor string
+		expectedIP    string
+	}{
+		{
+			name:       "direct connection",
+			remoteAddr: "203.0.113.5:1234",
+			expectedIP: "203.0.113.5",
+		},
+		{
+			name:          "untrusted peer cannot spoof X-Forwarded-For",
+			remoteAddr:    "203.0.113.5:1234",
+			xForwardedFor: "198.51.100.1",
+			expectedIP:    "203.0.113.5",
+		},
+		{
+			name:          "trusted proxy forwards client IP",
+			remoteAddr:    "10.0.0.2:1234",
+			xForwardedFor: "198.51.100.1",
+			expectedIP:    "198.51.100.1",
+		},
+		{
+			name:          "chain of trusted proxies",
+			remoteAddr:    "10.0.0.2:1234",
+			xForwardedFor: "1.2.3.4, 198.51.100.1, 10.0.0.3",
+			expectedIP:    "198.51.100.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.xForwardedFor)
+			}
+
+			assert.Equal(t, tt.expectedIP, resolver.ClientIP(req).String())
+		})
+	}
+}
+
+func TestIPFilter_Middleware(t *testing.T) {
+	resolver, err := NewClientIPResolver(nil)
+	require.NoError(t, err)
+
+	filter, err := NewIPFilter([]string{"192.168.1.0/24"}, []string{"/api/admin"}, []string{"203.0.113.66"}, resolver)
+	require.NoError(t, err)
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		remoteAddr     string
+		expectedStatus int
+	}{
+		{
+			name:           "public route from any IP",
+			path:           "/api/users",
+			remoteAddr:     "203.0.113.5:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "denylisted IP on public route",
+			path:           "/health",
+			remoteAddr:     "203.0.113.66:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin route from allowlisted IP",
+			path:           "/api/admin/config",
+			remoteAddr:     "192.168.1.10:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "admin route from other IP",
+			path:           "/api/admin/config",
+			remoteAddr:     "203.0.113.5:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "prefix match requires path boundary",
+			path:           "/api/administrators",
+			remoteAddr:     "203.0.113.5:1234",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			rr := httptest.NewRecorder()
+
+			filter.Middleware(testHandler).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestIPFilter_EmptyAllowlistDeniesRestrictedRoutes(t *testing.T) {
+	resolver, err := NewClientIPResolver(nil)
+	require.NoError(t, err)
+	filter, err := NewIPFilter(nil, []string{"/api/admin"}, nil, resolver)
+	require.NoError(t, err)
+	handler := filter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/config", nil))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestIPFilter_Nil(t *testing.T) {
+	var filter *IPFilter
+	rr := httptest.NewRecorder()
+	filter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/config", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestNewIPFilter_InvalidEntry(t *testing.T) {
+	resolver, err := NewClientIPResolver(nil)
+	require.NoError(t, err)
+
+	_, err = NewIPFilter([]string{"not-an-ip"}, nil, nil, resolver)
+	assert.Error(t, err)
+}
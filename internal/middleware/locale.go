@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+)
+
+// Locale picks the language of each request's responses from its
+// Accept-Language header
+type Locale struct {
+	bundle *i18n.Bundle
+}
+
+// NewLocale creates a Locale translating with bundle
+func NewLocale(bundle *i18n.Bundle) *Locale {
+	return &Locale{bundle: bundle}
+}
+
+// Middleware puts the request's i18n.Localizer in its context, where
+// handlers translate error messages with it, and names the language in the
+// Content-Language header. A nil Locale leaves responses in English.
+func (l *Locale) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		localizer := l.bundle.Localizer(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", localizer.Language())
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.NewContext(r.Context(), localizer)))
+	})
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale(t *testing.T) {
+	bundle := i18n.NewBundle()
+	require.NoError(t, bundle.LoadFS(fstest.MapFS{"es.yaml": {Data: []byte(`"Authorization header required": "Se requiere la cabecera Authorization"`)}}, "."))
+	handler := NewLocale(bundle).Middleware(AuthMiddleware("secret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not be called without a token")
+	})))
+
+	tests := []struct {
+		name, acceptLanguage, language, message string
+	}{
+		{"translated", "es-AR,es;q=0.9,en;q=0.5", "es", "Se requiere la cabecera Authorization"},
+		{"unsupported language", "fr", "en", "Authorization header required"},
+		{"no preference", "", "en", "Authorization header required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, tt.language, rec.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+			assert.JSONEq(t, `{"error":"`+tt.message+`"}`, rec.Body.String())
+		})
+	}
+}
+
+func TestLocale_Nil(t *testing.T) {
+	var locale *Locale
+	handler := locale.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, i18n.FromContext(r.Context()))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Language"))
+}
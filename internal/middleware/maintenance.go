@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+)
+
+// Maintenance holds the runtime maintenance mode state. It is safe for
+// concurrent use so it can be toggled while the server is running.
+type Maintenance struct {
+	mu             sync.RWMutex
+	enabled        bool
+	retryAfter     time.Duration
+	message        string
+	exemptPrefixes []string
+}
+
+// MaintenanceStatus is a snapshot of the maintenance mode state
+type MaintenanceStatus struct {
+	Enabled    bool   `json:"enabled"`
+	RetryAfter int    `json:"retry_after_seconds"`
+	Message    string `json:"message"`
+}
+
+// NewMaintenance creates a new maintenance mode toggle. Requests whose path
+// starts with one of exemptPrefixes are always served.
+func NewMaintenance(enabled bool, retryAfter time.Duration, message string, exemptPrefixes ...string) *Maintenance {
+	return &Maintenance{
+		enabled:        enabled,
+		retryAfter:     retryAfter,
+		message:        message,
+		exemptPrefixes: exemptPrefixes,
+	}
+}
+
+// Status returns the current maintenance mode state
+func (m *Maintenance) Status() MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return MaintenanceStatus{
+		Enabled:    m.enabled,
+		RetryAfter: int(m.retryAfter.Seconds()),
+		Message:    m.message,
+	}
+}
+
+// Set updates the maintenance mode state. Zero retryAfter or empty message
+// keep the current values.
+func (m *Maintenance) Set(enabled bool, retryAfter time.Duration, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = enabled
+	if retryAfter > 0 {
+		m.retryAfter = retryAfter
+	}
+	if message != "" {
+		m.message = message
+	}
+}
+
+// Middleware returns 503 for all non-exempt routes while maintenance mode is on
+func (m *Maintenance) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := m.Status()
+		if !status.Enabled || m.isExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(status.RetryAfter))
+		response.JSON(w, map[string]interface{}{
+			"error":       "Service under maintenance",
+			"message":     status.Message,
+			"retry_after": status.RetryAfter,
+		}, http.StatusServiceUnavailable)
+	})
+}
+
+// isExempt reports whether the path is served during maintenance
+func (m *Maintenance) isExempt(path string) bool {
+	for _, prefix := range m.exemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenance_Middleware(t *testing.T) {
+	maintenance := NewMaintenance(false, 2*time.Minute, "Back soon", "/health", "/api/admin")
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := maintenance.Middleware(testHandler)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	// Disabled: everything is served
+	assert.Equal(t, http.StatusOK, serve("/api/users").Code)
+
+	// Enabled: non-exempt routes are rejected with Retry-After
+	maintenance.Set(true, 0, "")
+	rr := serve("/api/users")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "120", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), "Back soon")
+
+	// Exempt routes stay reachable
+	assert.Equal(t, http.StatusOK, serve("/health").Code)
+	assert.Equal(t, http.StatusOK, serve("/api/admin/maintenance").Code)
+
+	// Disabling restores normal service
+	maintenance.Set(false, 0, "")
+	assert.Equal(t, http.StatusOK, serve("/api/users").Code)
+}
+
+func TestMaintenance_Nil(t *testing.T) {
+	var maintenance *Maintenance
+	rr := httptest.NewRecorder()
+	maintenance.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/metrics"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTPMetrics records Prometheus metrics for the requests it serves:
+// counts, latency and response sizes by method, route and status, and the
+// number of requests in flight. A nil *HTTPMetrics records nothing.
+type HTTPMetrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+	inFlight     prometheus.Gauge
+}
+
+// httpMetricsConfig holds optional HTTPMetrics settings
+type httpMetricsConfig struct {
+	durationBucket float64
+}
+
+// HTTPMetricsOption configures optional HTTPMetrics behaviour
+type HTTPMetricsOption func(*httpMetricsConfig)
+
+// WithDurationBucket adds d as a request duration histogram bucket, so
+// alerts can count the requests slower than it, e.g. the slow request
+// threshold
+func WithDurationBucket(d time.Duration) HTTPMetricsOption {
+	return func(cfg *httpMetricsConfig) {
+		cfg.durationBucket = d.Seconds()
+	}
+}
+
+// withBucket returns the sorted buckets with bucket added; zero adds nothing
+func withBucket(buckets []float64, bucket float64) []float64 {
+	if bucket <= 0 || slices.Contains(buckets, bucket) {
+		return buckets
+	}
+	merged := append(slices.Clone(buckets), bucket)
+	slices.Sort(merged)
+	return merged
+}
+
+// NewHTTPMetrics creates the HTTP metrics and registers them with reg
+func NewHTTPMetrics(reg prometheus.Registerer, opts ...HTTPMetricsOption) *HTTPMetrics {
+	var cfg httpMetricsConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	labels := []string{"method", "route", "status"}
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route and status.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by method, route and status.",
+			Buckets:   withBucket(prometheus.DefBuckets, cfg.durationBucket),
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Name:      "http_response_size_bytes",
+			Help:      "HTTP response body size, by method, route and status.",
+			Buckets:   prometheus.ExponentialBuckets(128, 4, 8),
+		}, labels),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests currently being served.",
+		}),
+	}
+	reg.MustRegister(m.requests, m.duration, m.responseSize, m.inFlight)
+	return m
+}
+
+// Middleware records the metrics of each request. Routes are labeled by
+// their template, such as /api/users/{id}, to keep label values bounded.
+func (m *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		labels := prometheus.Labels{
+			"method": r.Method,
+			"route":  routeLabel(r),
+			"status": strconv.Itoa(rec.status),
+		}
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+		m.responseSize.With(labels).Observe(float64(rec.bytes))
+	})
+}
+
+// routePattern matches a route variable's pattern, e.g. {id:[0-9]+}
+var routePattern = regexp.MustCompile(`\{([^{}:]+):[^{}]*\}`)
+
+// routeLabel returns the matched route's path template with any variable
+// patterns removed, e.g. /api/users/{id}
+func routeLabel(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+
+	return routePattern.ReplaceAllString(tmpl, "{$1}")
+}
+
+// responseRecorder captures the status code and body size written by a
+// handler. It passes hijacking and flushing through so WebSocket upgrades
+// and streaming responses keep working.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of body bytes written
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Flush flushes the underlying writer when it supports flushing
+func (rec *responseRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack takes over the connection; the request is recorded with 101
+// Switching Protocols
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	rec.status, rec.wroteHeader = http.StatusSwitchingProtocols, true
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
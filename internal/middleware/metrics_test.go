@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/metrics"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMetrics_Middleware(t *testing.T) {
+	registry := metrics.NewRegistry()
+	httpMetrics := NewHTTPMetrics(registry)
+
+	router := mux.NewRouter()
+	router.Use(httpMetrics.Middleware)
+	router.HandleFunc("/api/users/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["id"] == "0" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id":1}`))
+	})
+	router.Handle("/metrics", metrics.Handler(registry))
+
+	for _, path := range []string{"/api/users/1", "/api/users/2", "/api/users/0"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(httpMetrics.requests.WithLabelValues("GET", "/api/users/{id}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpMetrics.requests.WithLabelValues("GET", "/api/users/{id}", "404")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(httpMetrics.inFlight))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	assert.Contains(t, body, `go_api_http_request_duration_seconds_count{method="GET",route="/api/users/{id}",status="200"} 2`)
+	assert.Contains(t, body, `go_api_http_response_size_bytes_sum{method="GET",route="/api/users/{id}",status="200"} 16`)
+	assert.True(t, strings.Contains(body, "go_goroutines"), "runtime metrics are included")
+}
+
+func TestHTTPMetrics_Nil(t *testing.T) {
+	var httpMetrics *HTTPMetrics
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	assert.NotNil(t, httpMetrics.Middleware(next))
+}
+
+func TestHTTPMetrics_DurationBucket(t *testing.T) {
+	registry := metrics.NewRegistry()
+	httpMetrics := NewHTTPMetrics(registry, WithDurationBucket(750*time.Millisecond))
+
+	router := mux.NewRouter()
+	router.Use(httpMetrics.Middleware)
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	router.Handle("/metrics", metrics.Handler(registry))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `go_api_http_request_duration_seconds_bucket{method="GET",route="/health",status="200",le="0.75"} 1`)
+}
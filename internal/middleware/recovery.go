@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+)
+
+// Recovery turns a panicking handler into a 500 response instead of a
+// dropped connection, logging the panic and reporting it to the error
+// tracker with the request it happened for
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// ErrAbortHandler deliberately aborts the response
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			ctx := r.Context()
+			logger.FromContext(ctx).Error("Panic serving request",
+				"method", r.Method, "path", r.URL.Path, "panic", recovered, "stack", string(debug.Stack()))
+			reporting.ReportPanic(reporting.WithRequest(ctx, r), recovered)
+
+			response.Error(w, r, "Internal server error", http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
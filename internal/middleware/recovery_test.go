@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReporter collects reported panics
+type recordingReporter struct {
+	panics []any
+	scopes []reporting.Scope
+}
+
+func (r *recordingReporter) ReportError(context.Context, error) {}
+
+func (r *recordingReporter) ReportPanic(ctx context.Context, recovered any) {
+	r.panics = append(r.panics, recovered)
+	r.scopes = append(r.scopes, reporting.ScopeFromContext(ctx))
+}
+
+func (r *recordingReporter) Flush(time.Duration) bool { return true }
+
+func TestRecovery(t *testing.T) {
+	reporter := &recordingReporter{}
+	reporting.SetDefault(reporter)
+	t.Cleanup(func() { reporting.SetDefault(nil) })
+
+	handler := RequestID(Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error":"Internal server error"}`, rec.Body.String())
+	require.Equal(t, []any{"boom"}, reporter.panics)
+	assert.Equal(t, rec.Header().Get(RequestIDHeader), reporter.scopes[0].Tags["request_id"])
+	assert.Equal(t, "/api/users", reporter.scopes[0].Request.URL.Path)
+}
+
+func TestRecovery_AbortHandler(t *testing.T) {
+	handler := Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
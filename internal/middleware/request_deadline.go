@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+)
+
+// Headers a client sets to bound how long it waits for a request
+const (
+	// RequestTimeoutHeader holds a duration such as "2.5s" or "500ms", or a
+	// number of seconds
+	RequestTimeoutHeader = "X-Request-Timeout"
+	// GRPCTimeoutHeader holds a gRPC-style timeout such as "500m" (an integer
+	// and a unit: H, M, S, m, u or n)
+	GRPCTimeoutHeader = "Grpc-Timeout"
+)
+
+// RequestDeadline derives a deadline for each request's context from the
+// timeout its client asks for, so handlers and the database stop working on
+// requests the client has given up on. Timeouts are capped at a maximum.
+type RequestDeadline struct {
+	max time.Duration
+}
+
+// NewRequestDeadline creates a request deadline policy capping the timeouts
+// clients ask for at max
+func NewRequestDeadline(max time.Duration) *RequestDeadline {
+	return &RequestDeadline{max: max}
+}
+
+// Middleware sets the deadline of requests carrying X-Request-Timeout or
+// Grpc-Timeout, answering 400 to timeouts it cannot parse. Requests without
+// either header are served as before. A nil RequestDeadline sets no deadlines.
+func (d *RequestDeadline) Middleware(next http.Handler) http.Handler {
+	if d == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout, ok, err := requestTimeout(r.Header)
+		if err != nil {
+			response.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		timeout = min(timeout, d.max)
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestTimeout returns the timeout the request's headers ask for, and
+// false when they ask for none. X-Request-Timeout takes precedence.
+func requestTimeout(h http.Header) (time.Duration, bool, error) {
+	if v := h.Get(RequestTimeoutHeader); v != "" {
+		timeout, err := parseRequestTimeout(v)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %s header: %w", RequestTimeoutHeader, err)
+		}
+		return timeout, true, nil
+	}
+	if v := h.Get(GRPCTimeoutHeader); v != "" {
+		timeout, err := parseGRPCTimeout(v)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %s header: %w", GRPCTimeoutHeader, err)
+		}
+		return timeout, true, nil
+	}
+	return 0, false, nil
+}
+
+// parseRequestTimeout parses a positive duration, or a number of seconds
+func parseRequestTimeout(v string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(v)
+	if err != nil {
+		seconds, serr := strconv.ParseFloat(v, 64)
+		if serr != nil || math.IsNaN(seconds) {
+			return 0, fmt.Errorf("%q is not a duration", v)
+		}
+		if seconds <= 0 {
+			return 0, fmt.Errorf("%q is not positive", v)
+		}
+		// Beyond what a Duration holds is as good as no limit; the maximum
+		// applies either way
+		timeout = time.Duration(math.MaxInt64)
+		if seconds < math.MaxInt64/float64(time.Second) {
+			timeout = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("%q is not positive", v)
+	}
+	return timeout, nil
+}
+
+// grpcTimeoutUnits are the units of the gRPC timeout format
+var grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseGRPCTimeout parses the gRPC timeout format: at most 8 digits and a unit
+func parseGRPCTimeout(v string) (time.Duration, error) {
+	if len(v) < 2 || len(v) > 9 {
+		return 0, fmt.Errorf("%q is not a gRPC timeout", v)
+	}
+	unit, ok := grpcTimeoutUnits[v[len(v)-1]]
+	if !ok {
+		return 0, fmt.Errorf("%q is not a gRPC timeout", v)
+	}
+	n, err := strconv.ParseUint(v[:len(v)-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a gRPC timeout", v)
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("%q is not positive", v)
+	}
+	if n > uint64(math.MaxInt64/unit) {
+		return time.Duration(math.MaxInt64), nil
+	}
+	return time.Duration(n) * unit, nil
+}
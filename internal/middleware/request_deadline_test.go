@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDeadline(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		timeout time.Duration // zero when no deadline is expected
+	}{
+		{name: "no header"},
+		{name: "duration", header: RequestTimeoutHeader, value: "2.5s", timeout: 2500 * time.Millisecond},
+		{name: "seconds", header: RequestTimeoutHeader, value: "3", timeout: 3 * time.Second},
+		{name: "capped", header: RequestTimeoutHeader, value: "10m", timeout: time.Minute},
+		{name: "huge", header: RequestTimeoutHeader, value: "1e300", timeout: time.Minute},
+		{name: "grpc millis", header: GRPCTimeoutHeader, value: "500m", timeout: 500 * time.Millisecond},
+		{name: "grpc hours capped", header: GRPCTimeoutHeader, value: "99999999H", timeout: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadline time.Time
+			var hasDeadline bool
+			handler := NewRequestDeadline(time.Minute).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deadline, hasDeadline = r.Context().Deadline()
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			start := time.Now()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			if tt.timeout == 0 {
+				assert.False(t, hasDeadline)
+				return
+			}
+			require.True(t, hasDeadline)
+			assert.WithinDuration(t, start.Add(tt.timeout), deadline, time.Second)
+		})
+	}
+}
+
+func TestRequestDeadline_PrefersRequestTimeoutHeader(t *testing.T) {
+	var deadline time.Time
+	handler := NewRequestDeadline(time.Hour).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ = r.Context().Deadline()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestTimeoutHeader, "5s")
+	req.Header.Set(GRPCTimeoutHeader, "30M")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+}
+
+func TestRequestDeadline_RejectsInvalidTimeouts(t *testing.T) {
+	handler := NewRequestDeadline(time.Minute).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called for an invalid timeout")
+	}))
+
+	for header, values := range map[string][]string{
+		RequestTimeoutHeader: {"soon", "0", "-1s", "-1e300", "NaN"},
+		GRPCTimeoutHeader:    {"5", "5s", "0S", "123456789S", "m"},
+	} {
+		for _, value := range values {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(header, value)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, "%s: %s", header, value)
+			assert.Contains(t, rec.Body.String(), "invalid "+header+" header", "%s: %s", header, value)
+		}
+	}
+}
+
+func TestRequestDeadline_Nil(t *testing.T) {
+	var d *RequestDeadline
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestTimeoutHeader, "soon")
+	rec := httptest.NewRecorder()
+	d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/ids"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern bounds the request IDs accepted from clients, keeping
+// log lines and response headers safe
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID tags each request with an ID, taken from a valid X-Request-ID
+// header (e.g. set by a load balancer) or generated, echoes it in the
+// response, and attaches it to the request's logger and error reports
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = ids.NewUUIDv7()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := logger.With(r.Context(), "request_id", requestID)
+		ctx = reporting.WithTag(ctx, "request_id", requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
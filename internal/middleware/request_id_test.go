@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/ids"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"missing", "", false},
+		{"valid", "lb-1234:abcd", true},
+		{"invalid", "bad id\nwith newline", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logger.FromContext(r.Context()).Info("handled")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			req = req.WithContext(logger.NewContext(req.Context(), logger.New(&buf, slog.LevelInfo, logger.FormatText)))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(RequestIDHeader)
+			if tt.keep {
+				assert.Equal(t, tt.header, requestID)
+			} else {
+				assert.True(t, ids.Valid(ids.FormatUUIDv7, requestID), "generated ID %q", requestID)
+			}
+			assert.Contains(t, buf.String(), "request_id="+requestID)
+		})
+	}
+}
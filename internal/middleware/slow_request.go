@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reqstats"
+)
+
+// SlowRequestLog logs a warning for every request slower than a threshold,
+// with its route, user and where the time went
+type SlowRequestLog struct {
+	threshold time.Duration
+	now       func() time.Time
+}
+
+// NewSlowRequestLog creates a slow request log for requests taking longer
+// than threshold
+func NewSlowRequestLog(threshold time.Duration) *SlowRequestLog {
+	return &SlowRequestLog{threshold: threshold, now: time.Now}
+}
+
+// Middleware times each request and logs it when it is slow. A nil
+// SlowRequestLog logs nothing.
+func (s *SlowRequestLog) Middleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := s.now()
+		ctx, stats := reqstats.NewContext(r.Context())
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		// WebSocket connections, /ws and GraphQL subscriptions, last as long
+		// as the client stays; their duration says nothing about latency
+		if rec.status == http.StatusSwitchingProtocols || isWebSocketUpgrade(r) {
+			return
+		}
+		elapsed := s.now().Sub(start)
+		if elapsed < s.threshold {
+			return
+		}
+
+		// The breakdown lists each component's time and calls; "other" is
+		// the time spent outside of them, in handlers and middleware
+		breakdown := []any{}
+		other := elapsed
+		for _, span := range stats.Spans() {
+			breakdown = append(breakdown, slog.Group(span.Component, "duration", span.Duration, "calls", span.Calls))
+			other -= span.Duration
+		}
+		breakdown = append(breakdown, slog.Duration("other", other))
+
+		user := stats.User()
+		if user == "" {
+			user = "anonymous"
+		}
+		logger.FromContext(ctx).Warn("Slow request",
+			"method", r.Method,
+			"route", routeLabel(r),
+			"path", r.URL.Path,
+			"status", rec.status,
+			"user_id", user,
+			"duration", elapsed,
+			"threshold", s.threshold,
+			slog.Group("breakdown", breakdown...),
+		)
+	})
+}
+
+// isWebSocketUpgrade reports whether r asks to upgrade to a WebSocket
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reqstats"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances by step on every reading
+type fakeClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+func newSlowRequestRouter(threshold, elapsed time.Duration, out *bytes.Buffer) *mux.Router {
+	slowLog := NewSlowRequestLog(threshold)
+	slowLog.now = (&fakeClock{now: time.Unix(0, 0), step: elapsed}).Now
+
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := logger.NewContext(r.Context(), logger.New(out, slog.LevelInfo, logger.FormatText))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	router.Use(slowLog.Middleware)
+
+	users := router.PathPrefix("/api/users").Subrouter()
+	users.Use(AuthMiddleware("secret"))
+	users.HandleFunc("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		reqstats.FromContext(r.Context()).Add("db", 300*time.Millisecond)
+		reqstats.FromContext(r.Context()).Add("db", 200*time.Millisecond)
+		reqstats.FromContext(r.Context()).Add("cache", 5*time.Millisecond)
+	})
+	router.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	})
+	return router
+}
+
+// hijackRecorder is a response recorder whose connection can be hijacked
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (rec hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func TestSlowRequestLog(t *testing.T) {
+	var out bytes.Buffer
+	router := newSlowRequestRouter(time.Second, 2*time.Second, &out)
+
+	token, err := utils.GenerateJWT(7, "alice@example.com", "secret")
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/users/42", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := out.String()
+	assert.Contains(t, line, `level=WARN msg="Slow request" method=GET route=/api/users/{id} path=/api/users/42 status=200 user_id=7 duration=2s threshold=1s`)
+	assert.Contains(t, line, `breakdown.cache.duration=5ms breakdown.cache.calls=1 breakdown.db.duration=500ms breakdown.db.calls=2 breakdown.other=1.495s`)
+}
+
+func TestSlowRequestLog_FastRequest(t *testing.T) {
+	var out bytes.Buffer
+	router := newSlowRequestRouter(time.Second, 10*time.Millisecond, &out)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	assert.Empty(t, out.String())
+}
+
+func TestSlowRequestLog_SkipsWebSockets(t *testing.T) {
+	var out bytes.Buffer
+	router := newSlowRequestRouter(time.Second, time.Hour, &out)
+
+	// A hijacked connection is skipped whether or not the upgrade header
+	// made it through
+	router.ServeHTTP(hijackRecorder{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	router.ServeHTTP(hijackRecorder{httptest.NewRecorder()}, req)
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// TenantDBProvider returns a tenant's database, connecting to it on first use
+type TenantDBProvider interface {
+	DB(ctx context.Context, tenantID string) (*gorm.DB, error)
+}
+
+// TenantResolver routes each request to its tenant's database, chosen by a
+// request header. A nil *TenantResolver lets every request through to the
+// shared database.
+type TenantResolver struct {
+	provider       TenantDBProvider
+	header         string
+	exemptPrefixes []string
+}
+
+// NewTenantResolver creates a resolver reading the tenant ID from header.
+// Requests whose path starts with one of exemptPrefixes need no tenant.
+func NewTenantResolver(provider TenantDBProvider, header string, exemptPrefixes ...string) *TenantResolver {
+	return &TenantResolver{
+		provider:       provider,
+		header:         header,
+		exemptPrefixes: exemptPrefixes,
+	}
+}
+
+// Middleware stores the tenant's database in the request context, where
+// repositories pick it up. Requests without a usable tenant are rejected.
+func (t *TenantResolver) Middleware(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || t.isExempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tenantID := strings.TrimSpace(r.Header.Get(t.header))
+		if tenantID == "" {
+			response.Error(w, r, t.header+" header required", http.StatusBadRequest)
+			return
+		}
+
+		db, err := t.provider.DB(r.Context(), tenantID)
+		if err != nil {
+			if errors.Is(err, database.ErrInvalidTenant) {
+				response.Error(w, r, "Invalid tenant ID", http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, database.ErrUnknownTenant) {
+				response.Error(w, r, "Unknown tenant", http.StatusNotFound)
+				return
+			}
+			logger.FromContext(r.Context()).Error("Failed to open tenant database", "tenant", tenantID, "error", err)
+			response.Error(w, r, "Tenant database unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(database.WithTenant(r.Context(), tenantID, db)))
+	})
+}
+
+// isExempt reports whether the path is served without a tenant
+func (t *TenantResolver) isExempt(path string) bool {
+	for _, prefix := range t.exemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
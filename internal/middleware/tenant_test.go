@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakeTenantDBs hands out a distinct *gorm.DB per tenant
+type fakeTenantDBs map[string]*gorm.DB
+
+func (f fakeTenantDBs) DB(ctx context.Context, tenantID string) (*gorm.DB, error) {
+	switch tenantID {
+	case "Bad":
+		return nil, fmt.Errorf("%w: %q", database.ErrInvalidTenant, tenantID)
+	case "initech":
+		return nil, fmt.Errorf("%w: %q", database.ErrUnknownTenant, tenantID)
+	}
+	db, ok := f[tenantID]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return db, nil
+}
+
+func TestTenantResolver_Middleware(t *testing.T) {
+	acme := &gorm.DB{}
+	resolver := NewTenantResolver(fakeTenantDBs{"acme": acme}, "X-Tenant-ID", "/health")
+
+	var gotTenant string
+	var gotDB *gorm.DB
+	handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant, gotDB = database.TenantID(r.Context()), database.TenantDB(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(path, tenant string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if tenant != "" {
+			req.Header.Set("X-Tenant-ID", tenant)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/users", "acme"))
+	assert.Equal(t, "acme", gotTenant)
+	assert.Same(t, acme, gotDB)
+
+	assert.Equal(t, http.StatusBadRequest, serve("/api/users", ""))
+	assert.Equal(t, http.StatusBadRequest, serve("/api/users", "Bad"))
+	assert.Equal(t, http.StatusNotFound, serve("/api/users", "initech"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/api/users", "globex"))
+	assert.Equal(t, http.StatusOK, serve("/health", ""))
+}
+
+func TestTenantResolver_NilPassesThrough(t *testing.T) {
+	var resolver *TenantResolver
+	handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestAuthMiddleware_RejectsOtherTenantsToken(t *testing.T) {
+	token, err := utils.GenerateTenantJWT(1, "a@example.com", "user", "acme", "secret")
+	assert.NoError(t, err)
+
+	handler := AuthMiddleware("secret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(tenant string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req = req.WithContext(database.WithTenant(req.Context(), tenant, &gorm.DB{}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("acme"))
+	assert.Equal(t, http.StatusUnauthorized, serve("globex"))
+}
@@ -0,0 +1,226 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait is the time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next pong message from the peer
+	pongWait = 60 * time.Second
+	// pingPeriod sends pings to the peer with this period; must be less than pongWait
+	pingPeriod = (pongWait * 9) / 10
+	// maxMessageSize is the maximum message size allowed from the peer
+	maxMessageSize = 4096
+	// sendBufferSize is the number of outgoing messages buffered per connection
+	sendBufferSize = 32
+)
+
+// Message is a server-push notification sent to clients
+type Message struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// clientKey identifies a user across tenants, whose user IDs overlap
+type clientKey struct {
+	tenant string
+	userID uint
+}
+
+// Hub keeps a registry of WebSocket connections per user and pushes messages
+// to them. Users are identified by tenant and ID; the tenant is empty
+// outside multi-tenant mode.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[clientKey]map[*Client]struct{}
+	closed  bool
+}
+
+// NewHub creates a new connection hub
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[clientKey]map[*Client]struct{}),
+	}
+}
+
+// Register adds a connection for the user of tenant and starts its
+// read/write loops. The connection is closed immediately if the hub is
+// shutting down.
+func (h *Hub) Register(tenant string, userID uint, conn *websocket.Conn) *Client {
+	key := clientKey{tenant: tenant, userID: userID}
+	client := &Client{
+		hub:  h,
+		key:  key,
+		conn: conn,
+		send: make(chan []byte, sendBufferSize),
+		done: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(writeWait))
+		conn.Close()
+		return nil
+	}
+	if h.clients[key] == nil {
+		h.clients[key] = make(map[*Client]struct{})
+	}
+	h.clients[key][client] = struct{}{}
+	h.mu.Unlock()
+
+	go client.writePump()
+	go client.readPump()
+
+	return client
+}
+
+// unregister removes a connection from the registry
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if conns, ok := h.clients[client.key]; ok {
+		delete(conns, client)
+		if len(conns) == 0 {
+			delete(h.clients, client.key)
+		}
+	}
+}
+
+// SendToUser pushes a message to every connection of the user of tenant on
+// this instance and returns the number of connections it was queued for. A
+// nil hub sends nothing.
+func (h *Hub) SendToUser(tenant string, userID uint, msg Message) int {
+	if h == nil {
+		return 0
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("Failed to encode websocket message", "error", err)
+		return 0
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	sent := 0
+	for client := range h.clients[clientKey{tenant: tenant, userID: userID}] {
+		if client.enqueue(payload) {
+			sent++
+		}
+	}
+	return sent
+}
+
+// ConnectionCount returns the number of open connections
+func (h *Hub) ConnectionCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	count := 0
+	for _, conns := range h.clients {
+		count += len(conns)
+	}
+	return count
+}
+
+// Shutdown sends a close frame to every connection and rejects new ones
+func (h *Hub) Shutdown() {
+	h.mu.Lock()
+	h.closed = true
+	var clients []*Client
+	for _, conns := range h.clients {
+		for client := range conns {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.Unlock()
+
+	slog.Info("Closing websocket connections", "count", len(clients))
+	for _, client := range clients {
+		client.close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+// Client is a single WebSocket connection belonging to a user
+type Client struct {
+	hub       *Hub
+	key       clientKey
+	conn      *websocket.Conn
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// enqueue queues a message without blocking; slow clients drop messages
+func (c *Client) enqueue(payload []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	case c.send <- payload:
+		return true
+	default:
+		return false
+	}
+}
+
+// close sends a close frame and tears the connection down once
+func (c *Client) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+		c.conn.Close()
+		c.hub.unregister(c)
+	})
+}
+
+// readPump reads from the connection to process pongs and detect closure.
+// Clients are push-only, so incoming data messages are discarded.
+func (c *Client) readPump() {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump writes queued messages and periodic pings to the connection
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.close(websocket.CloseNormalClosure, "")
+	}()
+
+	for {
+		select {
+		case payload := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package realtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer starts a server that registers every connection for the user
+// of tenant
+func newTestServer(t *testing.T, hub *Hub, tenant string, userID uint) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		hub.Register(tenant, userID, conn)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dial(t *testing.T, server *httptest.Server) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func waitForConnections(t *testing.T, hub *Hub, expected int) {
+	assert.Eventually(t, func() bool {
+		return hub.ConnectionCount() == expected
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHub_SendToUser(t *testing.T) {
+	hub := NewHub()
+	conn := dial(t, newTestServer(t, hub, "acme", 42))
+	waitForConnections(t, hub, 1)
+
+	// Messages for other users, or the same user ID in another tenant, are not delivered
+	assert.Equal(t, 0, hub.SendToUser("acme", 7, Message{Type: "ignored"}))
+	assert.Equal(t, 0, hub.SendToUser("globex", 42, Message{Type: "ignored"}))
+	assert.Equal(t, 1, hub.SendToUser("acme", 42, Message{Type: "notification", Data: "hello"}))
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var msg Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "notification", msg.Type)
+	assert.Equal(t, "hello", msg.Data)
+}
+
+func TestHub_Nil(t *testing.T) {
+	var hub *Hub
+	assert.Equal(t, 0, hub.SendToUser("", 42, Message{Type: "notification"}))
+}
+
+func TestHub_UnregistersClosedConnections(t *testing.T) {
+	hub := NewHub()
+	conn := dial(t, newTestServer(t, hub, "", 42))
+	waitForConnections(t, hub, 1)
+
+	conn.Close()
+
+	waitForConnections(t, hub, 0)
+}
+
+func TestHub_Shutdown(t *testing.T) {
+	hub := NewHub()
+	server := newTestServer(t, hub, "", 42)
+	conn := dial(t, server)
+	waitForConnections(t, hub, 1)
+
+	hub.Shutdown()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	assert.Equal(t, 0, hub.ConnectionCount())
+}
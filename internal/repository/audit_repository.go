@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// AuditRepository defines the interface for audit log operations. Entries
+// are only ever appended.
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditLog) error
+	List(ctx context.Context, limit, offset int) ([]*domain.AuditLog, error)
+}
+
+// auditRepository implements AuditRepository interface. Create comes from
+// the embedded Repository.
+type auditRepository struct {
+	*Repository[domain.AuditLog]
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{
+		Repository: NewRepository[domain.AuditLog](db),
+	}
+}
+
+// List retrieves audit log entries with pagination, newest first
+func (r *auditRepository) List(ctx context.Context, limit, offset int) ([]*domain.AuditLog, error) {
+	var entries []*domain.AuditLog
+	query := dbFor(ctx, r.db).Order("id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	err := query.Find(&entries).Error
+	return entries, err
+}
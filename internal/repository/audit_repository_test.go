@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository_SQLite(t *testing.T) {
+	repo := NewAuditRepository(newTestDB(t))
+	ctx := context.Background()
+
+	actor := uint(7)
+	require.NoError(t, repo.Create(ctx, &domain.AuditLog{Method: "POST", Path: "/api/auth/login", Route: "/api/auth/login", Status: 401}))
+	require.NoError(t, repo.Create(ctx, &domain.AuditLog{ActorID: &actor, Method: "DELETE", Path: "/api/users/3", Route: "/api/users/{id}", Status: 200}))
+
+	entries, err := repo.List(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "DELETE", entries[0].Method, "newest first")
+	require.NotNil(t, entries[0].ActorID)
+	assert.Equal(t, actor, *entries[0].ActorID)
+	assert.Nil(t, entries[1].ActorID)
+	assert.False(t, entries[1].CreatedAt.IsZero())
+
+	entries, err = repo.List(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "POST", entries[0].Method)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// BillingAccountRepository defines the interface for billing account data
+// operations
+type BillingAccountRepository interface {
+	// Create stores a new account; a user has at most one
+	Create(ctx context.Context, account *domain.BillingAccount) error
+	// GetByUserID retrieves the user's account, or nil when there is none
+	GetByUserID(ctx context.Context, userID uint) (*domain.BillingAccount, error)
+	// GetByCustomerID retrieves the account of a payment provider customer,
+	// or nil when there is none
+	GetByCustomerID(ctx context.Context, customerID string) (*domain.BillingAccount, error)
+	// Update saves every field of the account
+	Update(ctx context.Context, account *domain.BillingAccount) error
+}
+
+// billingAccountRepository implements BillingAccountRepository interface
+type billingAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewBillingAccountRepository creates a new billing account repository
+func NewBillingAccountRepository(db *gorm.DB) BillingAccountRepository {
+	return &billingAccountRepository{
+		db: db,
+	}
+}
+
+// Create inserts the account
+func (r *billingAccountRepository) Create(ctx context.Context, account *domain.BillingAccount) error {
+	return dbFor(ctx, r.db).Create(account).Error
+}
+
+// GetByUserID retrieves an account by user ID
+func (r *billingAccountRepository) GetByUserID(ctx context.Context, userID uint) (*domain.BillingAccount, error) {
+	return r.first(ctx, "user_id = ?", userID)
+}
+
+// GetByCustomerID retrieves an account by customer ID
+func (r *billingAccountRepository) GetByCustomerID(ctx context.Context, customerID string) (*domain.BillingAccount, error) {
+	return r.first(ctx, "customer_id = ?", customerID)
+}
+
+// Update saves the account
+func (r *billingAccountRepository) Update(ctx context.Context, account *domain.BillingAccount) error {
+	return dbFor(ctx, r.db).Save(account).Error
+}
+
+// first retrieves the account matching the condition, or nil
+func (r *billingAccountRepository) first(ctx context.Context, query string, arg interface{}) (*domain.BillingAccount, error) {
+	var account domain.BillingAccount
+	err := dbFor(ctx, r.db).Where(query, arg).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
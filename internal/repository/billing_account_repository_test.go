@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBillingAccountRepository(t *testing.T) {
+	repo := NewBillingAccountRepository(newTestDB(t))
+	ctx := context.Background()
+
+	account := &domain.BillingAccount{UserID: 1, CustomerID: "cus_123"}
+	require.NoError(t, repo.Create(ctx, account))
+	require.NotZero(t, account.ID)
+	assert.Error(t, repo.Create(ctx, &domain.BillingAccount{UserID: 1, CustomerID: "cus_456"}), "a user has one account")
+	assert.Error(t, repo.Create(ctx, &domain.BillingAccount{UserID: 2, CustomerID: "cus_123"}), "a customer has one user")
+
+	periodEnd := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	account.SubscriptionID = "sub_123"
+	account.Plan = "pro"
+	account.Status = domain.SubscriptionActive
+	account.CurrentPeriodEnd = &periodEnd
+	require.NoError(t, repo.Update(ctx, account))
+
+	found, err := repo.GetByCustomerID(ctx, "cus_123")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "pro", found.Plan)
+	assert.True(t, found.GrantsPlan())
+	assert.True(t, periodEnd.Equal(*found.CurrentPeriodEnd))
+
+	found, err = repo.GetByUserID(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, account.ID, found.ID)
+
+	found, err = repo.GetByUserID(ctx, 2)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+	found, err = repo.GetByCustomerID(ctx, "cus_unknown")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+)
+
+// CachedUserRepository is a UserRepository whose lookups are cached
+type CachedUserRepository interface {
+	UserRepository
+	// InvalidateUser drops the cached entries for the user's ID and emails
+	InvalidateUser(ctx context.Context, id uint, emails ...string)
+}
+
+// cachedUserRepository decorates a UserRepository with a read-through cache
+// for GetByID and GetByEmail. Cache failures are logged and fall through to
+// the wrapped repository, so an unavailable cache never fails a request.
+type cachedUserRepository struct {
+	UserRepository
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewCachedUserRepository wraps next so single-user lookups are cached for ttl
+// and invalidated by Update and Delete
+func NewCachedUserRepository(next UserRepository, c cache.Cache, ttl time.Duration) CachedUserRepository {
+	return &cachedUserRepository{
+		UserRepository: next,
+		cache:          c,
+		ttl:            ttl,
+	}
+}
+
+// tenantCacheScope namespaces cache keys by tenant, since tenant databases
+// reuse the same user IDs and emails
+func tenantCacheScope(ctx context.Context) string {
+	if tenantID := database.TenantID(ctx); tenantID != "" {
+		return "tenant:" + tenantID + ":"
+	}
+	return ""
+}
+
+func userIDCacheKey(ctx context.Context, id uint) string {
+	return fmt.Sprintf("%suser:id:%d", tenantCacheScope(ctx), id)
+}
+
+func userEmailCacheKey(ctx context.Context, email string) string {
+	return tenantCacheScope(ctx) + "user:email:" + email
+}
+
+// GetByID retrieves a user by ID, consulting the cache first
+func (r *cachedUserRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+	return r.readThrough(ctx, userIDCacheKey(ctx, id), func() (*domain.User, error) {
+		return r.UserRepository.GetByID(ctx, id)
+	})
+}
+
+// GetByEmail retrieves a user by email, consulting the cache first
+func (r *cachedUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.readThrough(ctx, userEmailCacheKey(ctx, email), func() (*domain.User, error) {
+		return r.UserRepository.GetByEmail(ctx, email)
+	})
+}
+
+// Update updates a user and invalidates its cached entries, including the
+// entry for the previous email when it changed
+func (r *cachedUserRepository) Update(ctx context.Context, user *domain.User) error {
+	keys := []string{userIDCacheKey(ctx, user.ID), userEmailCacheKey(ctx, user.Email)}
+	if previous, err := r.UserRepository.GetByID(ctx, user.ID); err == nil && previous != nil {
+		keys = append(keys, userEmailCacheKey(ctx, previous.Email))
+	}
+
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, keys...)
+	return nil
+}
+
+// Delete deletes a user and invalidates its cached entries
+func (r *cachedUserRepository) Delete(ctx context.Context, id uint) error {
+	keys := []string{userIDCacheKey(ctx, id)}
+	if previous, err := r.UserRepository.GetByID(ctx, id); err == nil && previous != nil {
+		keys = append(keys, userEmailCacheKey(ctx, previous.Email))
+	}
+
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, keys...)
+	return nil
+}
+
+// MarkVerificationReminded counts a verification reminder and invalidates
+// the user's cached entries
+func (r *cachedUserRepository) MarkVerificationReminded(ctx context.Context, user *domain.User, at time.Time) (bool, error) {
+	marked, err := r.UserRepository.MarkVerificationReminded(ctx, user, at)
+	if marked {
+		r.invalidate(ctx, userIDCacheKey(ctx, user.ID), userEmailCacheKey(ctx, user.Email))
+	}
+	return marked, err
+}
+
+// Unscoped bypasses the cache: soft-deleted users are never cached
+func (r *cachedUserRepository) Unscoped() UserRepository {
+	return r.UserRepository.Unscoped()
+}
+
+// readThrough returns the cached user under key, or loads and caches it.
+// Missing users are not cached so a later registration is seen immediately.
+func (r *cachedUserRepository) readThrough(ctx context.Context, key string, load func() (*domain.User, error)) (*domain.User, error) {
+	if cache.IsBypassed(ctx) {
+		return r.load(ctx, key, load)
+	}
+
+	data, err := r.cache.Get(ctx, key)
+	if err == nil {
+		// gob rather than JSON: the JSON tags hide the password hash, which
+		// the login path needs
+		var user domain.User
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&user); err == nil {
+			return &user, nil
+		}
+		logger.FromContext(ctx).Warn("Discarding undecodable cache entry", "key", key)
+	} else if !errors.Is(err, cache.ErrMiss) {
+		logger.FromContext(ctx).Warn("Cache get failed", "key", key, "error", err)
+	}
+
+	return r.load(ctx, key, load)
+}
+
+// load fetches the user from the wrapped repository and caches it under key
+func (r *cachedUserRepository) load(ctx context.Context, key string, load func() (*domain.User, error)) (*domain.User, error) {
+	user, err := load()
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(user); err != nil {
+		logger.FromContext(ctx).Error("Failed to encode user for cache", "user_id", user.ID, "error", err)
+		return user, nil
+	}
+	if err := r.cache.Set(ctx, key, buf.Bytes(), r.ttl); err != nil {
+		logger.FromContext(ctx).Warn("Cache set failed", "key", key, "error", err)
+	}
+	return user, nil
+}
+
+// InvalidateUser drops the cached entries for the user's ID and each email.
+// Usecases call it after writes that may not have gone through this
+// decorator, such as writes inside a TxManager transaction.
+func (r *cachedUserRepository) InvalidateUser(ctx context.Context, id uint, emails ...string) {
+	keys := []string{userIDCacheKey(ctx, id)}
+	for _, email := range emails {
+		keys = append(keys, userEmailCacheKey(ctx, email))
+	}
+	r.invalidate(ctx, keys...)
+}
+
+// invalidate removes keys from the cache, logging rather than failing the write
+func (r *cachedUserRepository) invalidate(ctx context.Context, keys ...string) {
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		logger.FromContext(ctx).Error("Cache invalidation failed", "keys", keys, "error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedUserRepository(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewCachedUserRepository(NewUserRepository(db), cache.NewMemoryCache(), time.Minute)
+
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed", Role: domain.RoleUser}
+	require.NoError(t, repo.Create(ctx, alice))
+
+	// Prime the cache, then change the row behind its back
+	_, err := repo.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	_, err = repo.GetByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&domain.User{}).Where("id = ?", alice.ID).Update("name", "Changed").Error)
+
+	t.Run("serves cached reads", func(t *testing.T) {
+		cached, err := repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", cached.Name)
+
+		cached, err = repo.GetByEmail(ctx, "alice@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", cached.Name)
+		assert.Equal(t, "hashed", cached.Password, "the password hash survives caching")
+	})
+
+	t.Run("bypass reloads and refreshes the entry", func(t *testing.T) {
+		fresh, err := repo.GetByID(cache.WithBypass(ctx), alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Changed", fresh.Name)
+
+		cached, err := repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Changed", cached.Name)
+	})
+
+	t.Run("update invalidates old and new email", func(t *testing.T) {
+		alice.Name = "Alice Updated"
+		alice.Email = "alice2@example.com"
+		require.NoError(t, repo.Update(ctx, alice))
+
+		fresh, err := repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice Updated", fresh.Name)
+
+		old, err := repo.GetByEmail(ctx, "alice@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, old)
+	})
+
+	t.Run("delete invalidates", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, alice.ID))
+
+		deleted, err := repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Nil(t, deleted)
+
+		deleted, err = repo.GetByEmail(ctx, "alice2@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, deleted)
+	})
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// EmailRepository defines the interface for email outbox operations
+type EmailRepository interface {
+	Create(ctx context.Context, email *domain.Email) error
+	GetByID(ctx context.Context, id uint) (*domain.Email, error)
+	Update(ctx context.Context, email *domain.Email) error
+	GetDue(ctx context.Context, now time.Time, limit int) ([]*domain.Email, error)
+	// Claim takes a due email for sending, reporting false when another
+	// caller claimed it first. The claim counts an attempt and moves
+	// NextAttemptAt to leaseUntil, when the email is due again should its
+	// sender die before recording the outcome.
+	Claim(ctx context.Context, email *domain.Email, now, leaseUntil time.Time) (bool, error)
+}
+
+// emailRepository implements EmailRepository interface. Create, GetByID and
+// Update come from the embedded Repository.
+type emailRepository struct {
+	*Repository[domain.Email]
+}
+
+// NewEmailRepository creates a new email repository
+func NewEmailRepository(db *gorm.DB) EmailRepository {
+	return &emailRepository{
+		Repository: NewRepository[domain.Email](db),
+	}
+}
+
+// GetDue retrieves pending emails whose next attempt is due, oldest first
+func (r *emailRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]*domain.Email, error) {
+	var emails []*domain.Email
+	err := dbFor(ctx, r.db).
+		Where("status = ? AND next_attempt_at <= ?", domain.EmailStatusPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&emails).Error
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// Claim claims an email with a conditional update on its status and
+// attempts, so when several instances poll the outbox only one of them sends
+// each email
+func (r *emailRepository) Claim(ctx context.Context, email *domain.Email, now, leaseUntil time.Time) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&domain.Email{}).
+		Where("id = ? AND status = ? AND attempts = ?", email.ID, domain.EmailStatusPending, email.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        email.Attempts + 1,
+			"next_attempt_at": leaseUntil,
+			"updated_at":      now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	email.Attempts++
+	email.NextAttemptAt = leaseUntil
+	email.UpdatedAt = now
+	return true, nil
+}
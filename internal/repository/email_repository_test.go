@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailRepository_Claim(t *testing.T) {
+	repo := NewEmailRepository(newTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	msg := &domain.Email{To: "alice@example.com", Template: "welcome", Subject: "Welcome", Status: domain.EmailStatusPending, NextAttemptAt: now}
+	require.NoError(t, repo.Create(ctx, msg))
+
+	// Two instances load the email; only the first claim wins
+	first, second := *msg, *msg
+	claimed, err := repo.Claim(ctx, &first, now, now.Add(5*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, 1, first.Attempts)
+	claimed, err = repo.Claim(ctx, &second, now, now.Add(5*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// The claimed email isn't due again until its lease ends
+	due, err := repo.GetDue(ctx, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = repo.GetDue(ctx, now.Add(10*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// FileRepository defines the interface for file metadata operations
+type FileRepository interface {
+	Create(ctx context.Context, file *domain.File) error
+	GetByID(ctx context.Context, id uint) (*domain.File, error)
+	GetByOwner(ctx context.Context, ownerID uint, limit, offset int) ([]*domain.File, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+// fileRepository implements FileRepository interface. Create, GetByID and
+// Delete come from the embedded Repository.
+type fileRepository struct {
+	*Repository[domain.File]
+}
+
+// NewFileRepository creates a new file repository
+func NewFileRepository(db *gorm.DB) FileRepository {
+	return &fileRepository{
+		Repository: NewRepository[domain.File](db),
+	}
+}
+
+// GetByOwner retrieves an owner's files with pagination, newest first
+func (r *fileRepository) GetByOwner(ctx context.Context, ownerID uint, limit, offset int) ([]*domain.File, error) {
+	var files []*domain.File
+	err := dbFor(ctx, r.db).
+		Where("owner_id = ?", ownerID).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&files).Error
+	return files, err
+}
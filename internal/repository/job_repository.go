@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepository defines the interface for job queue operations
+type JobRepository interface {
+	Create(ctx context.Context, job *domain.Job) error
+	GetByID(ctx context.Context, id uint) (*domain.Job, error)
+	Update(ctx context.Context, job *domain.Job) error
+	// ClaimDue marks up to limit due jobs as running and returns them. Pending
+	// jobs are due at their RunAt; running jobs locked before staleBefore are
+	// reclaimed, as their consumer is presumed dead. Jobs are claimed by
+	// priority, highest first, except that reclaimed jobs and jobs due before
+	// agedBefore go first so low priorities aren't starved. Every claim
+	// increments the job's attempts, and a job is only ever claimed by one
+	// caller.
+	ClaimDue(ctx context.Context, now, staleBefore, agedBefore time.Time, limit int) ([]*domain.Job, error)
+	// Cancel marks a pending job canceled so it never runs, reporting
+	// whether it was still pending
+	Cancel(ctx context.Context, id uint, now time.Time) (bool, error)
+}
+
+// jobRepository implements JobRepository interface. Create, GetByID and
+// Update come from the embedded Repository.
+type jobRepository struct {
+	*Repository[domain.Job]
+}
+
+// NewJobRepository creates a new job repository
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{
+		Repository: NewRepository[domain.Job](db),
+	}
+}
+
+// Cancel cancels a pending job with a conditional update, so a job claimed by
+// a consumer in the meantime is left running
+func (r *jobRepository) Cancel(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&domain.Job{}).
+		Where("id = ? AND status = ?", id, domain.JobStatusPending).
+		Updates(map[string]interface{}{
+			"status":      domain.JobStatusCanceled,
+			"finished_at": now,
+			"updated_at":  now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimDue claims due jobs: reclaimed and aged ones first, then by priority,
+// oldest due first within each. Candidates are claimed with a conditional
+// update on their status and attempts, so when several instances poll the
+// queue only one of them wins each job.
+func (r *jobRepository) ClaimDue(ctx context.Context, now, staleBefore, agedBefore time.Time, limit int) ([]*domain.Job, error) {
+	db := dbFor(ctx, r.db)
+
+	var candidates []*domain.Job
+	err := db.
+		Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+			domain.JobStatusPending, now, domain.JobStatusRunning, staleBefore).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN status = ? OR run_at < ? THEN 0 ELSE 1 END, priority DESC, run_at",
+			Vars:               []interface{}{domain.JobStatusRunning, agedBefore},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]*domain.Job, 0, len(candidates))
+	for _, job := range candidates {
+		result := db.Model(&domain.Job{}).
+			Where("id = ? AND status = ? AND attempts = ?", job.ID, job.Status, job.Attempts).
+			Updates(map[string]interface{}{
+				"status":     domain.JobStatusRunning,
+				"attempts":   job.Attempts + 1,
+				"locked_at":  now,
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		lockedAt := now
+		job.Status = domain.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &lockedAt
+		job.UpdatedAt = now
+		claimed = append(claimed, job)
+	}
+	return claimed, nil
+}
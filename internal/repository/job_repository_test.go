@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRepository_ClaimDue(t *testing.T) {
+	repo := NewJobRepository(newTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	due := &domain.Job{Type: "email.send", Payload: "{}", Status: domain.JobStatusPending, RunAt: now.Add(-time.Minute)}
+	later := &domain.Job{Type: "email.send", Payload: "{}", Status: domain.JobStatusPending, RunAt: now.Add(time.Hour)}
+	lockedAt := now.Add(-time.Hour)
+	stale := &domain.Job{Type: "export.users", Payload: "{}", Status: domain.JobStatusRunning, Attempts: 1, RunAt: now.Add(-2 * time.Hour), LockedAt: &lockedAt}
+	for _, job := range []*domain.Job{due, later, stale} {
+		require.NoError(t, repo.Create(ctx, job))
+	}
+
+	claimed, err := repo.ClaimDue(ctx, now, now.Add(-10*time.Minute), now.Add(-5*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, stale.ID, claimed[0].ID, "oldest due first")
+	assert.Equal(t, 2, claimed[0].Attempts)
+	assert.Equal(t, due.ID, claimed[1].ID)
+	assert.Equal(t, domain.JobStatusRunning, claimed[1].Status)
+
+	stored, err := repo.GetByID(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusRunning, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+	require.NotNil(t, stored.LockedAt)
+
+	// Claimed jobs are not handed out again until their lock goes stale
+	claimed, err = repo.ClaimDue(ctx, now, now.Add(-10*time.Minute), now.Add(-5*time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestJobRepository_Cancel(t *testing.T) {
+	repo := NewJobRepository(newTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	pending := &domain.Job{Type: "reminder", Payload: "{}", Status: domain.JobStatusPending, RunAt: now.Add(time.Hour)}
+	running := &domain.Job{Type: "reminder", Payload: "{}", Status: domain.JobStatusRunning, Attempts: 1, RunAt: now, LockedAt: &now}
+	for _, job := range []*domain.Job{pending, running} {
+		require.NoError(t, repo.Create(ctx, job))
+	}
+
+	canceled, err := repo.Cancel(ctx, pending.ID, now)
+	require.NoError(t, err)
+	assert.True(t, canceled)
+
+	stored, err := repo.GetByID(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusCanceled, stored.Status)
+	require.NotNil(t, stored.FinishedAt)
+
+	claimed, err := repo.ClaimDue(ctx, now.Add(2*time.Hour), now.Add(-time.Hour), now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "canceled jobs are never claimed")
+
+	canceled, err = repo.Cancel(ctx, running.ID, now)
+	require.NoError(t, err)
+	assert.False(t, canceled, "running jobs can't be canceled")
+}
+
+func TestJobRepository_ClaimDue_Priority(t *testing.T) {
+	repo := NewJobRepository(newTestDB(t))
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	aged := &domain.Job{Type: "export.users", Payload: "{}", Status: domain.JobStatusPending, Priority: domain.JobPriorityLow, RunAt: now.Add(-time.Hour)}
+	low := &domain.Job{Type: "export.users", Payload: "{}", Status: domain.JobStatusPending, Priority: domain.JobPriorityLow, RunAt: now.Add(-2 * time.Minute)}
+	normal := &domain.Job{Type: "email.send", Payload: "{}", Status: domain.JobStatusPending, RunAt: now.Add(-3 * time.Minute)}
+	high := &domain.Job{Type: "email.send", Payload: "{}", Status: domain.JobStatusPending, Priority: domain.JobPriorityHigh, RunAt: now.Add(-time.Minute)}
+	for _, job := range []*domain.Job{aged, low, normal, high} {
+		require.NoError(t, repo.Create(ctx, job))
+	}
+
+	claimed, err := repo.ClaimDue(ctx, now, now.Add(-10*time.Minute), now.Add(-5*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 4)
+	var ids []uint
+	for _, job := range claimed {
+		ids = append(ids, job.ID)
+	}
+	assert.Equal(t, []uint{aged.ID, high.ID, normal.ID, low.ID}, ids, "aged jobs first, then by priority")
+}
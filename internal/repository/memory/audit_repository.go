@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// AuditRepository is a thread-safe in-memory repository.AuditRepository
+type AuditRepository struct {
+	mu      sync.RWMutex
+	entries []*domain.AuditLog
+}
+
+// NewAuditRepository creates an empty in-memory audit repository
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+// Create appends an audit log entry
+func (r *AuditRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = uint(len(r.entries) + 1)
+	entry.CreatedAt = now()
+	stored := *entry
+	r.entries = append(r.entries, &stored)
+	return nil
+}
+
+// List retrieves audit log entries with pagination, newest first
+func (r *AuditRepository) List(ctx context.Context, limit, offset int) ([]*domain.AuditLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	start, end := page(len(r.entries), limit, offset)
+	entries := make([]*domain.AuditLog, 0, end-start)
+	for i := len(r.entries) - 1 - start; i >= len(r.entries)-end; i-- {
+		found := *r.entries[i]
+		entries = append(entries, &found)
+	}
+	return entries, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// BillingAccountRepository is a thread-safe in-memory
+// repository.BillingAccountRepository
+type BillingAccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[uint]*domain.BillingAccount
+	nextID   uint
+}
+
+// NewBillingAccountRepository creates an empty in-memory billing account
+// repository
+func NewBillingAccountRepository() *BillingAccountRepository {
+	return &BillingAccountRepository{accounts: make(map[uint]*domain.BillingAccount), nextID: 1}
+}
+
+// Create stores the account, rejecting a second account of a user or
+// customer
+func (r *BillingAccountRepository) Create(ctx context.Context, account *domain.BillingAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.accounts {
+		if existing.UserID == account.UserID || existing.CustomerID == account.CustomerID {
+			return ErrDuplicate
+		}
+	}
+	account.ID = r.nextID
+	r.nextID++
+	account.CreatedAt = now()
+	account.UpdatedAt = account.CreatedAt
+	stored := *account
+	r.accounts[account.ID] = &stored
+	return nil
+}
+
+// GetByUserID retrieves an account by user ID
+func (r *BillingAccountRepository) GetByUserID(ctx context.Context, userID uint) (*domain.BillingAccount, error) {
+	return r.find(func(a *domain.BillingAccount) bool { return a.UserID == userID }), nil
+}
+
+// GetByCustomerID retrieves an account by customer ID
+func (r *BillingAccountRepository) GetByCustomerID(ctx context.Context, customerID string) (*domain.BillingAccount, error) {
+	return r.find(func(a *domain.BillingAccount) bool { return a.CustomerID == customerID }), nil
+}
+
+// Update saves the account
+func (r *BillingAccountRepository) Update(ctx context.Context, account *domain.BillingAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account.UpdatedAt = now()
+	stored := *account
+	r.accounts[account.ID] = &stored
+	return nil
+}
+
+// find returns a copy of the account matching match, or nil
+func (r *BillingAccountRepository) find(match func(*domain.BillingAccount) bool) *domain.BillingAccount {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if match(account) {
+			found := *account
+			return &found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// EmailRepository is a thread-safe in-memory repository.EmailRepository
+type EmailRepository struct {
+	mu     sync.RWMutex
+	emails map[uint]*domain.Email
+	nextID uint
+}
+
+// NewEmailRepository creates an empty in-memory email repository
+func NewEmailRepository() *EmailRepository {
+	return &EmailRepository{emails: make(map[uint]*domain.Email), nextID: 1}
+}
+
+// Create queues a new email
+func (r *EmailRepository) Create(ctx context.Context, email *domain.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	email.ID = r.nextID
+	r.nextID++
+	email.CreatedAt, email.UpdatedAt = now(), now()
+	stored := *email
+	r.emails[email.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves an email, returning nil when there is none
+func (r *EmailRepository) GetByID(ctx context.Context, id uint) (*domain.Email, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	email, ok := r.emails[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *email
+	return &found, nil
+}
+
+// Update saves every field of an existing email
+func (r *EmailRepository) Update(ctx context.Context, email *domain.Email) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.emails[email.ID]; !ok {
+		return nil
+	}
+	email.UpdatedAt = now()
+	stored := *email
+	r.emails[email.ID] = &stored
+	return nil
+}
+
+// GetDue retrieves pending emails whose next attempt is due, oldest first
+func (r *EmailRepository) GetDue(ctx context.Context, due time.Time, limit int) ([]*domain.Email, error) {
+	r.mu.RLock()
+	emails := []*domain.Email{}
+	for _, email := range r.emails {
+		if email.Status == domain.EmailStatusPending && !email.NextAttemptAt.After(due) {
+			found := *email
+			emails = append(emails, &found)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(emails, func(a, b *domain.Email) int {
+		return cmp.Or(a.NextAttemptAt.Compare(b.NextAttemptAt), cmp.Compare(a.ID, b.ID))
+	})
+	_, end := page(len(emails), limit, 0)
+	return emails[:end], nil
+}
+
+// Claim counts an attempt on a pending email and leases it until
+// leaseUntil, unless another caller claimed it first
+func (r *EmailRepository) Claim(ctx context.Context, email *domain.Email, at, leaseUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.emails[email.ID]
+	if !ok || stored.Status != domain.EmailStatusPending || stored.Attempts != email.Attempts {
+		return false, nil
+	}
+	stored.Attempts++
+	stored.NextAttemptAt = leaseUntil
+	stored.UpdatedAt = at
+	email.Attempts, email.NextAttemptAt, email.UpdatedAt = stored.Attempts, stored.NextAttemptAt, stored.UpdatedAt
+	return true, nil
+}
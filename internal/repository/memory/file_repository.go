@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// FileRepository is a thread-safe in-memory repository.FileRepository
+type FileRepository struct {
+	mu     sync.RWMutex
+	files  map[uint]*domain.File
+	nextID uint
+}
+
+// NewFileRepository creates an empty in-memory file repository
+func NewFileRepository() *FileRepository {
+	return &FileRepository{files: make(map[uint]*domain.File), nextID: 1}
+}
+
+// Create stores new file metadata; keys are unique, as in the database
+func (r *FileRepository) Create(ctx context.Context, file *domain.File) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.files {
+		if existing.Key == file.Key {
+			return ErrDuplicate
+		}
+	}
+
+	file.ID = r.nextID
+	r.nextID++
+	file.CreatedAt, file.UpdatedAt = now(), now()
+	stored := *file
+	r.files[file.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves file metadata, returning nil when there is none
+func (r *FileRepository) GetByID(ctx context.Context, id uint) (*domain.File, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	file, ok := r.files[id]
+	if !ok || file.DeletedAt.Valid {
+		return nil, nil
+	}
+	found := *file
+	return &found, nil
+}
+
+// GetByOwner retrieves an owner's files with pagination, newest first
+func (r *FileRepository) GetByOwner(ctx context.Context, ownerID uint, limit, offset int) ([]*domain.File, error) {
+	r.mu.RLock()
+	files := []*domain.File{}
+	for _, file := range r.files {
+		if file.OwnerID == ownerID && !file.DeletedAt.Valid {
+			found := *file
+			files = append(files, &found)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(files, func(a, b *domain.File) int { return cmp.Compare(b.ID, a.ID) })
+	start, end := page(len(files), limit, offset)
+	return files[start:end], nil
+}
+
+// Delete soft-deletes file metadata
+func (r *FileRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if file, ok := r.files[id]; ok && !file.DeletedAt.Valid {
+		file.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	}
+	return nil
+}
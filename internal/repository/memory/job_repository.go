@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// JobRepository is a thread-safe in-memory repository.JobRepository
+type JobRepository struct {
+	mu     sync.Mutex
+	jobs   map[uint]*domain.Job
+	nextID uint
+}
+
+// NewJobRepository creates an empty in-memory job repository
+func NewJobRepository() *JobRepository {
+	return &JobRepository{jobs: make(map[uint]*domain.Job), nextID: 1}
+}
+
+// Create stores a new job
+func (r *JobRepository) Create(ctx context.Context, job *domain.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.ID = r.nextID
+	r.nextID++
+	job.CreatedAt, job.UpdatedAt = now(), now()
+	stored := *job
+	r.jobs[job.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a job, returning nil when there is none
+func (r *JobRepository) GetByID(ctx context.Context, id uint) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *job
+	return &found, nil
+}
+
+// Update saves every field of an existing job
+func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.ID]; !ok {
+		return nil
+	}
+	job.UpdatedAt = now()
+	stored := *job
+	r.jobs[job.ID] = &stored
+	return nil
+}
+
+// Cancel marks a pending job canceled, reporting whether it was pending
+func (r *JobRepository) Cancel(ctx context.Context, id uint, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok || job.Status != domain.JobStatusPending {
+		return false, nil
+	}
+	finishedAt := at
+	job.Status = domain.JobStatusCanceled
+	job.FinishedAt = &finishedAt
+	job.UpdatedAt = at
+	return true, nil
+}
+
+// ClaimDue claims up to limit due jobs: reclaimed and aged ones first, then
+// by priority, oldest due first within each
+func (r *JobRepository) ClaimDue(ctx context.Context, at, staleBefore, agedBefore time.Time, limit int) ([]*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := []*domain.Job{}
+	for _, job := range r.jobs {
+		pending := job.Status == domain.JobStatusPending && !job.RunAt.After(at)
+		stale := job.Status == domain.JobStatusRunning && job.LockedAt != nil && job.LockedAt.Before(staleBefore)
+		if pending || stale {
+			due = append(due, job)
+		}
+	}
+	urgent := func(job *domain.Job) int {
+		if job.Status == domain.JobStatusRunning || job.RunAt.Before(agedBefore) {
+			return 0
+		}
+		return 1
+	}
+	slices.SortFunc(due, func(a, b *domain.Job) int {
+		return cmp.Or(
+			cmp.Compare(urgent(a), urgent(b)),
+			cmp.Compare(b.Priority, a.Priority),
+			a.RunAt.Compare(b.RunAt),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*domain.Job, 0, len(due))
+	for _, job := range due {
+		lockedAt := at
+		job.Status = domain.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &lockedAt
+		job.UpdatedAt = at
+		found := *job
+		claimed = append(claimed, &found)
+	}
+	return claimed, nil
+}
@@ -0,0 +1,68 @@
+// Package memory implements the repository interfaces with maps guarded by
+// mutexes. It is meant for demos, for tests that want real repository
+// behaviour without mocks, and for running the server with --storage=memory,
+// which needs no database. Data is lost when the process exits.
+//
+// Records are copied on the way in and out, so callers never share state
+// with the store, just as with a database.
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+)
+
+// ErrDuplicate mirrors a unique index violation
+var ErrDuplicate = errors.New("duplicate key value violates unique constraint")
+
+// NewRepositories creates an empty in-memory store for every repository
+func NewRepositories() repository.Repositories {
+	return repository.Repositories{
+		Users:    NewUserRepository(),
+		Webhooks: NewWebhookRepository(),
+		Files:    NewFileRepository(),
+		Audit:    NewAuditRepository(),
+		Jobs:     NewJobRepository(),
+		Emails:   NewEmailRepository(),
+
+		NotificationPreferences: NewNotificationPreferenceRepository(),
+		Notifications:           NewNotificationRepository(),
+		VerificationCodes:       NewVerificationCodeRepository(),
+		PushSubscriptions:       NewPushSubscriptionRepository(),
+		BillingAccounts:         NewBillingAccountRepository(),
+	}
+}
+
+// now stamps records in UTC like the SQL repositories; swapped out in tests
+var now = func() time.Time { return time.Now().UTC() }
+
+// page applies limit and offset to n items the way the SQL repositories do:
+// non-positive values are ignored. It returns the [start, end) range.
+func page(n, limit, offset int) (int, int) {
+	start := 0
+	if offset > 0 {
+		start = min(offset, n)
+	}
+	end := n
+	if limit > 0 {
+		end = min(start+limit, n)
+	}
+	return start, end
+}
+
+// Compile-time checks that the in-memory repositories satisfy the interfaces
+var (
+	_ repository.UserRepository    = (*UserRepository)(nil)
+	_ repository.WebhookRepository = (*WebhookRepository)(nil)
+	_ repository.FileRepository    = (*FileRepository)(nil)
+	_ repository.AuditRepository   = (*AuditRepository)(nil)
+	_ repository.JobRepository     = (*JobRepository)(nil)
+	_ repository.EmailRepository   = (*EmailRepository)(nil)
+
+	_ repository.NotificationRepository     = (*NotificationRepository)(nil)
+	_ repository.VerificationCodeRepository = (*VerificationCodeRepository)(nil)
+	_ repository.PushSubscriptionRepository = (*PushSubscriptionRepository)(nil)
+	_ repository.BillingAccountRepository   = (*BillingAccountRepository)(nil)
+)
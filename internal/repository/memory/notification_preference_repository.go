@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// preferenceKey identifies a preference like the SQL unique index
+type preferenceKey struct {
+	userID  uint
+	typ     string
+	channel string
+}
+
+// NotificationPreferenceRepository is a thread-safe in-memory
+// repository.NotificationPreferenceRepository
+type NotificationPreferenceRepository struct {
+	mu     sync.RWMutex
+	prefs  map[preferenceKey]*domain.NotificationPreference
+	nextID uint
+}
+
+// NewNotificationPreferenceRepository creates an empty in-memory notification
+// preference repository
+func NewNotificationPreferenceRepository() *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{prefs: make(map[preferenceKey]*domain.NotificationPreference), nextID: 1}
+}
+
+// GetByUser retrieves a user's preferences ordered by type and channel
+func (r *NotificationPreferenceRepository) GetByUser(ctx context.Context, userID uint) ([]*domain.NotificationPreference, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs := []*domain.NotificationPreference{}
+	for key, pref := range r.prefs {
+		if key.userID == userID {
+			found := *pref
+			prefs = append(prefs, &found)
+		}
+	}
+	slices.SortFunc(prefs, func(a, b *domain.NotificationPreference) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Channel, b.Channel))
+	})
+	return prefs, nil
+}
+
+// Upsert stores the preferences, replacing existing ones for the same type
+// and channel
+func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, prefs []*domain.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, pref := range prefs {
+		key := preferenceKey{userID: pref.UserID, typ: pref.Type, channel: pref.Channel}
+		if existing, ok := r.prefs[key]; ok {
+			existing.Enabled = pref.Enabled
+			existing.UpdatedAt = now()
+			*pref = *existing
+			continue
+		}
+		pref.ID = r.nextID
+		r.nextID++
+		pref.CreatedAt, pref.UpdatedAt = now(), now()
+		stored := *pref
+		r.prefs[key] = &stored
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// NotificationRepository is a thread-safe in-memory
+// repository.NotificationRepository
+type NotificationRepository struct {
+	mu            sync.RWMutex
+	notifications []*domain.Notification
+}
+
+// NewNotificationRepository creates an empty in-memory notification repository
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{}
+}
+
+// Create appends a notification
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notification.ID = uint(len(r.notifications) + 1)
+	notification.CreatedAt = now()
+	stored := *notification
+	r.notifications = append(r.notifications, &stored)
+	return nil
+}
+
+// ListByUser retrieves a user's notifications with pagination, newest first
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matching []*domain.Notification
+	for i := len(r.notifications) - 1; i >= 0; i-- {
+		n := r.notifications[i]
+		if n.UserID == userID && (!unreadOnly || n.ReadAt == nil) {
+			matching = append(matching, n)
+		}
+	}
+	start, end := page(len(matching), limit, offset)
+	notifications := make([]*domain.Notification, 0, end-start)
+	for _, n := range matching[start:end] {
+		found := *n
+		notifications = append(notifications, &found)
+	}
+	return notifications, nil
+}
+
+// CountUnread counts a user's notifications without a read time
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MarkRead stamps the user's notification as read unless it already is
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id uint, at time.Time) (*domain.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id == 0 || int(id) > len(r.notifications) {
+		return nil, nil
+	}
+	n := r.notifications[id-1]
+	if n.UserID != userID {
+		return nil, nil
+	}
+	if n.ReadAt == nil {
+		readAt := at.UTC()
+		n.ReadAt = &readAt
+	}
+	found := *n
+	return &found, nil
+}
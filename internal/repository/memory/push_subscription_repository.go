@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// PushSubscriptionRepository is a thread-safe in-memory
+// repository.PushSubscriptionRepository
+type PushSubscriptionRepository struct {
+	mu     sync.RWMutex
+	subs   map[uint]*domain.PushSubscription
+	nextID uint
+}
+
+// NewPushSubscriptionRepository creates an empty in-memory push subscription
+// repository
+func NewPushSubscriptionRepository() *PushSubscriptionRepository {
+	return &PushSubscriptionRepository{subs: make(map[uint]*domain.PushSubscription), nextID: 1}
+}
+
+// Upsert stores the subscription, replacing the one with the same endpoint
+func (r *PushSubscriptionRepository) Upsert(ctx context.Context, sub *domain.PushSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.subs {
+		if existing.Endpoint == sub.Endpoint {
+			sub.ID = existing.ID
+			sub.CreatedAt = existing.CreatedAt
+			sub.UpdatedAt = now()
+			stored := *sub
+			r.subs[sub.ID] = &stored
+			return nil
+		}
+	}
+	sub.ID = r.nextID
+	r.nextID++
+	sub.CreatedAt = now()
+	sub.UpdatedAt = sub.CreatedAt
+	stored := *sub
+	r.subs[sub.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a subscription by ID
+func (r *PushSubscriptionRepository) GetByID(ctx context.Context, id uint) (*domain.PushSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *sub
+	return &found, nil
+}
+
+// ListByUser returns the user's subscriptions, oldest first
+func (r *PushSubscriptionRepository) ListByUser(ctx context.Context, userID uint) ([]*domain.PushSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subs []*domain.PushSubscription
+	for _, sub := range r.subs {
+		if sub.UserID == userID {
+			found := *sub
+			subs = append(subs, &found)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs, nil
+}
+
+// Delete removes the subscription
+func (r *PushSubscriptionRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.subs, id)
+	return nil
+}
@@ -0,0 +1,318 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"gorm.io/gorm"
+)
+
+// userStore holds the users shared by a repository and its Unscoped views
+type userStore struct {
+	mu     sync.RWMutex
+	users  map[uint]*domain.User
+	nextID uint
+}
+
+// UserRepository is a thread-safe in-memory repository.UserRepository
+type UserRepository struct {
+	store    *userStore
+	unscoped bool
+}
+
+// NewUserRepository creates an empty in-memory user repository
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		store: &userStore{users: make(map[uint]*domain.User), nextID: 1},
+	}
+}
+
+// Unscoped returns a view of the same users that includes soft-deleted ones
+func (r *UserRepository) Unscoped() repository.UserRepository {
+	return &UserRepository{store: r.store, unscoped: true}
+}
+
+// Create stores a new user, assigning its ID and timestamps
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	return r.CreateBatch(ctx, []*domain.User{user})
+}
+
+// CreateBatch stores all users or, if any email is taken, none of them
+func (r *UserRepository) CreateBatch(ctx context.Context, users []*domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	emails := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		if _, dup := emails[user.Email]; dup || r.emailTaken(user.Email, 0) {
+			return ErrDuplicate
+		}
+		emails[user.Email] = struct{}{}
+	}
+
+	createdAt := now()
+	for _, user := range users {
+		user.ID = r.store.nextID
+		r.store.nextID++
+		// Applies the same defaults as the GORM hook: version and public ID
+		if err := user.BeforeCreate(nil); err != nil {
+			return err
+		}
+		if user.Role == "" {
+			user.Role = domain.RoleUser
+		}
+		user.CreatedAt, user.UpdatedAt = createdAt, createdAt
+
+		stored := *user
+		r.store.users[user.ID] = &stored
+	}
+	return nil
+}
+
+// GetByEmail retrieves a user by email, returning nil when there is none
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email && r.visible(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetByPublicID retrieves a user by public ID, returning nil when there is
+// none
+func (r *UserRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.PublicID == publicID && r.visible(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// ExistingEmails returns which of the given emails are already registered,
+// including by soft-deleted users
+func (r *UserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	existing := []string{}
+	for _, email := range emails {
+		if r.emailTaken(email, 0) {
+			existing = append(existing, email)
+		}
+	}
+	return existing, nil
+}
+
+// GetByID retrieves a user by ID, returning nil when there is none
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok || !r.visible(user) {
+		return nil, nil
+	}
+	found := *user
+	return &found, nil
+}
+
+// GetByIDs retrieves the users with the given IDs, ordered by ID
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []uint) ([]*domain.User, error) {
+	wanted := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		wanted[id] = struct{}{}
+	}
+	return r.filter(func(user *domain.User) bool {
+		_, ok := wanted[user.ID]
+		return ok
+	}), nil
+}
+
+// Update saves every field of the user if it is still at the version that
+// was read, and increments the version
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.users[user.ID]
+	if !ok || !r.visible(current) || current.Version != user.Version {
+		return &domain.VersionConflictError{Entity: "user", ID: user.ID}
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return ErrDuplicate
+	}
+
+	user.Version++
+	user.UpdatedAt = now()
+	stored := *user
+	r.store.users[user.ID] = &stored
+	return nil
+}
+
+// Delete soft-deletes a user; deleting a missing user is not an error
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[id]; ok && !user.DeletedAt.Valid {
+		user.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	}
+	return nil
+}
+
+// GetAll retrieves users ordered by ID with pagination
+func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	users := r.filter(func(*domain.User) bool { return true })
+	start, end := page(len(users), limit, offset)
+	return users[start:end], nil
+}
+
+// Search finds users whose lowercased name or email contains every word of
+// query, like the SQL repository's LIKE fallback
+func (r *UserRepository) Search(ctx context.Context, query string, limit, offset int) ([]*domain.User, error) {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	users := r.filter(func(user *domain.User) bool {
+		name, email := strings.ToLower(user.Name), strings.ToLower(user.Email)
+		for _, word := range words {
+			if !strings.Contains(name, word) && !strings.Contains(email, word) {
+				return false
+			}
+		}
+		return true
+	})
+	start, end := page(len(users), limit, offset)
+	return users[start:end], nil
+}
+
+// Count returns the number of users
+func (r *UserRepository) Count(ctx context.Context) (int64, error) {
+	return int64(len(r.filter(func(*domain.User) bool { return true }))), nil
+}
+
+// Restore undeletes a soft-deleted user, reporting whether one was found
+func (r *UserRepository) Restore(ctx context.Context, id uint) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || !user.DeletedAt.Valid {
+		return false, nil
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	return true, nil
+}
+
+// PurgeDeleted permanently deletes up to limit users soft-deleted before
+// deletedBefore, lowest IDs first
+func (r *UserRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var ids []uint
+	for id, user := range r.store.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(deletedBefore) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	for _, id := range ids {
+		delete(r.store.users, id)
+	}
+	return int64(len(ids)), nil
+}
+
+// ListUnverified returns unverified users due a verification reminder
+func (r *UserRepository) ListUnverified(ctx context.Context, registeredAfter, remindBefore time.Time, maxReminders int, afterID uint, limit int) ([]*domain.User, error) {
+	users := r.filter(func(user *domain.User) bool {
+		last := user.CreatedAt
+		if user.VerificationRemindedAt != nil {
+			last = *user.VerificationRemindedAt
+		}
+		return user.EmailVerifiedAt == nil && user.VerificationReminders < maxReminders && user.ID > afterID &&
+			user.CreatedAt.After(registeredAfter) && last.Before(remindBefore)
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// MarkVerificationReminded counts a reminder unless one was counted since
+// user was read
+func (r *UserRepository) MarkVerificationReminded(ctx context.Context, user *domain.User, at time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok || !r.visible(stored) || stored.EmailVerifiedAt != nil || stored.VerificationReminders != user.VerificationReminders {
+		return false, nil
+	}
+	stored.VerificationReminders++
+	stored.VerificationRemindedAt = &at
+	user.VerificationReminders = stored.VerificationReminders
+	user.VerificationRemindedAt = &at
+	return true, nil
+}
+
+// ListByDirectory returns the users synced from an external directory
+func (r *UserRepository) ListByDirectory(ctx context.Context) ([]*domain.User, error) {
+	return r.filter(func(user *domain.User) bool { return user.DirectoryID != nil }), nil
+}
+
+// filter returns copies of the visible users matching keep, ordered by ID
+func (r *UserRepository) filter(keep func(user *domain.User) bool) []*domain.User {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := []*domain.User{}
+	for _, user := range r.store.users {
+		if r.visible(user) && keep(user) {
+			found := *user
+			users = append(users, &found)
+		}
+	}
+	slices.SortFunc(users, func(a, b *domain.User) int { return cmp.Compare(a.ID, b.ID) })
+	return users
+}
+
+// visible reports whether reads through this view see the user
+func (r *UserRepository) visible(user *domain.User) bool {
+	return r.unscoped || !user.DeletedAt.Valid
+}
+
+// emailTaken reports whether a user other than exceptID has the email; like
+// the unique index, soft-deleted users count. Callers hold the lock.
+func (r *UserRepository) emailTaken(email string, exceptID uint) bool {
+	for _, user := range r.store.users {
+		if user.Email == email && user.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUser(email string) *domain.User {
+	return &domain.User{Name: "User", Email: email, Password: "hashed", Role: domain.RoleUser}
+}
+
+func TestUserRepository_CRUD(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	alice := newUser("alice@example.com")
+	require.NoError(t, repo.Create(ctx, alice))
+	assert.Equal(t, uint(1), alice.ID)
+	assert.Equal(t, uint(1), alice.Version)
+	assert.ErrorIs(t, repo.Create(ctx, newUser("alice@example.com")), ErrDuplicate)
+
+	found, err := repo.GetByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	found.Name = "Changed without saving"
+
+	again, err := repo.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "User", again.Name, "callers get copies, not the stored record")
+
+	missing, err := repo.GetByID(ctx, 99)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	again.Name = "Alice"
+	require.NoError(t, repo.Update(ctx, again))
+	assert.Equal(t, uint(2), again.Version)
+
+	var conflict *domain.VersionConflictError
+	assert.ErrorAs(t, repo.Update(ctx, found), &conflict, "found was read at version 1")
+
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestUserRepository_SoftDeleteAndRestore(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	user := newUser("bob@example.com")
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, repo.Delete(ctx, user.ID))
+
+	found, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	found, err = repo.Unscoped().GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.True(t, found.DeletedAt.Valid)
+
+	existing, err := repo.ExistingEmails(ctx, []string{"bob@example.com", "new@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob@example.com"}, existing, "deleted users keep their email")
+
+	restored, err := repo.Restore(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, restored)
+
+	restored, err = repo.Restore(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, restored)
+}
+
+func TestUserRepository_CreateBatchIsAllOrNothing(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, newUser("taken@example.com")))
+
+	err := repo.CreateBatch(ctx, []*domain.User{newUser("fresh@example.com"), newUser("taken@example.com")})
+	assert.ErrorIs(t, err, ErrDuplicate)
+
+	found, err := repo.GetByEmail(ctx, "fresh@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestUserRepository_ListAndSearch(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		user := newUser(fmt.Sprintf("user%d@example.com", i))
+		user.Name = fmt.Sprintf("Person %d", i)
+		require.NoError(t, repo.Create(ctx, user))
+	}
+
+	users, err := repo.GetAll(ctx, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, []uint{2, 3}, []uint{users[0].ID, users[1].ID})
+
+	users, err = repo.GetByIDs(ctx, []uint{4, 1, 42})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, uint(1), users[0].ID)
+
+	users, err = repo.Search(ctx, "PERSON 3", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "user3@example.com", users[0].Email)
+}
+
+func TestUserRepository_ConcurrentCreates(t *testing.T) {
+	repo := NewUserRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, repo.Create(ctx, newUser(fmt.Sprintf("user%d@example.com", i))))
+		}(i)
+	}
+	wg.Wait()
+
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), count)
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// VerificationCodeRepository is a thread-safe in-memory
+// repository.VerificationCodeRepository
+type VerificationCodeRepository struct {
+	mu     sync.RWMutex
+	codes  map[uint]*domain.VerificationCode
+	nextID uint
+}
+
+// NewVerificationCodeRepository creates an empty in-memory verification code
+// repository
+func NewVerificationCodeRepository() *VerificationCodeRepository {
+	return &VerificationCodeRepository{codes: make(map[uint]*domain.VerificationCode), nextID: 1}
+}
+
+// Replace deletes the user's codes for the purpose and stores code
+func (r *VerificationCodeRepository) Replace(ctx context.Context, code *domain.VerificationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.codes {
+		if existing.UserID == code.UserID && existing.Purpose == code.Purpose {
+			delete(r.codes, id)
+		}
+	}
+	code.ID = r.nextID
+	r.nextID++
+	code.CreatedAt = now()
+	stored := *code
+	r.codes[code.ID] = &stored
+	return nil
+}
+
+// Get retrieves the user's code for purpose
+func (r *VerificationCodeRepository) Get(ctx context.Context, userID uint, purpose string) (*domain.VerificationCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, code := range r.codes {
+		if code.UserID == userID && code.Purpose == purpose {
+			found := *code
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// IncrementAttempts adds one to the code's attempt count while it is below
+// maxAttempts
+func (r *VerificationCodeRepository) IncrementAttempts(ctx context.Context, id uint, maxAttempts int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[id]
+	if !ok || code.Attempts >= maxAttempts {
+		return false, nil
+	}
+	code.Attempts++
+	return true, nil
+}
+
+// Delete removes the code
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.codes, id)
+	return nil
+}
@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository is a thread-safe in-memory repository.WebhookRepository
+type WebhookRepository struct {
+	mu             sync.RWMutex
+	subscriptions  map[uint]*domain.WebhookSubscription
+	deliveries     map[uint]*domain.WebhookDelivery
+	nextSubID      uint
+	nextDeliveryID uint
+}
+
+// NewWebhookRepository creates an empty in-memory webhook repository
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		subscriptions:  make(map[uint]*domain.WebhookSubscription),
+		deliveries:     make(map[uint]*domain.WebhookDelivery),
+		nextSubID:      1,
+		nextDeliveryID: 1,
+	}
+}
+
+// CreateSubscription stores a new webhook subscription
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub.ID = r.nextSubID
+	r.nextSubID++
+	sub.CreatedAt, sub.UpdatedAt = now(), now()
+	stored := *sub
+	r.subscriptions[sub.ID] = &stored
+	return nil
+}
+
+// GetSubscriptionByID retrieves a subscription, returning nil when there is none
+func (r *WebhookRepository) GetSubscriptionByID(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, ok := r.subscriptions[id]
+	if !ok || sub.DeletedAt.Valid {
+		return nil, nil
+	}
+	found := *sub
+	return &found, nil
+}
+
+// UpdateSubscription saves every field of the subscription
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub.UpdatedAt = now()
+	stored := *sub
+	r.subscriptions[sub.ID] = &stored
+	return nil
+}
+
+// DeleteSubscription soft-deletes a subscription
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sub, ok := r.subscriptions[id]; ok && !sub.DeletedAt.Valid {
+		sub.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	}
+	return nil
+}
+
+// GetAllSubscriptions retrieves subscriptions ordered by ID with pagination
+func (r *WebhookRepository) GetAllSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	subs := r.filterSubscriptions(func(*domain.WebhookSubscription) bool { return true })
+	start, end := page(len(subs), limit, offset)
+	return subs[start:end], nil
+}
+
+// GetActiveSubscriptions retrieves every active subscription
+func (r *WebhookRepository) GetActiveSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return r.filterSubscriptions(func(sub *domain.WebhookSubscription) bool { return sub.Active }), nil
+}
+
+// CreateDelivery stores a new delivery record
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.ID = r.nextDeliveryID
+	r.nextDeliveryID++
+	delivery.CreatedAt, delivery.UpdatedAt = now(), now()
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+// UpdateDelivery saves every field of the delivery record
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.UpdatedAt = now()
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+// GetDueDeliveries retrieves pending deliveries whose next attempt is due,
+// oldest first
+func (r *WebhookRepository) GetDueDeliveries(ctx context.Context, due time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	deliveries := r.filterDeliveries(func(d *domain.WebhookDelivery) bool {
+		return d.Status == domain.DeliveryStatusPending && !d.NextAttemptAt.After(due)
+	})
+	slices.SortStableFunc(deliveries, func(a, b *domain.WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(b.NextAttemptAt)
+	})
+	_, end := page(len(deliveries), limit, 0)
+	return deliveries[:end], nil
+}
+
+// ClaimDelivery counts an attempt on a pending delivery and leases it until
+// leaseUntil, unless another caller claimed it first
+func (r *WebhookRepository) ClaimDelivery(ctx context.Context, delivery *domain.WebhookDelivery, at, leaseUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[delivery.ID]
+	if !ok || stored.Status != domain.DeliveryStatusPending || stored.Attempts != delivery.Attempts {
+		return false, nil
+	}
+	stored.Attempts++
+	stored.NextAttemptAt = leaseUntil
+	stored.UpdatedAt = at
+	delivery.Attempts, delivery.NextAttemptAt, delivery.UpdatedAt = stored.Attempts, stored.NextAttemptAt, stored.UpdatedAt
+	return true, nil
+}
+
+// GetDeliveriesBySubscription retrieves a subscription's deliveries, newest first
+func (r *WebhookRepository) GetDeliveriesBySubscription(ctx context.Context, subscriptionID uint, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	deliveries := r.filterDeliveries(func(d *domain.WebhookDelivery) bool {
+		return d.SubscriptionID == subscriptionID
+	})
+	slices.Reverse(deliveries)
+	start, end := page(len(deliveries), limit, offset)
+	return deliveries[start:end], nil
+}
+
+// RecordDeliveryResult updates the subscription's failure count, disabling
+// it once the count reaches disableAfter
+func (r *WebhookRepository) RecordDeliveryResult(ctx context.Context, subscriptionID uint, succeeded bool, disableAfter int, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, ok := r.subscriptions[subscriptionID]
+	if !ok {
+		return false, nil
+	}
+	if succeeded {
+		sub.ConsecutiveFailures = 0
+		return false, nil
+	}
+
+	sub.ConsecutiveFailures++
+	if disableAfter <= 0 || !sub.Active || sub.ConsecutiveFailures < disableAfter {
+		return false, nil
+	}
+	sub.Active = false
+	sub.DisabledAt = &at
+	sub.UpdatedAt = at
+	return true, nil
+}
+
+// filterSubscriptions returns copies of the live subscriptions matching keep, ordered by ID
+func (r *WebhookRepository) filterSubscriptions(keep func(sub *domain.WebhookSubscription) bool) []*domain.WebhookSubscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := []*domain.WebhookSubscription{}
+	for _, sub := range r.subscriptions {
+		if !sub.DeletedAt.Valid && keep(sub) {
+			found := *sub
+			subs = append(subs, &found)
+		}
+	}
+	slices.SortFunc(subs, func(a, b *domain.WebhookSubscription) int { return cmp.Compare(a.ID, b.ID) })
+	return subs
+}
+
+// filterDeliveries returns copies of the deliveries matching keep, ordered by ID
+func (r *WebhookRepository) filterDeliveries(keep func(d *domain.WebhookDelivery) bool) []*domain.WebhookDelivery {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if keep(delivery) {
+			found := *delivery
+			deliveries = append(deliveries, &found)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b *domain.WebhookDelivery) int { return cmp.Compare(a.ID, b.ID) })
+	return deliveries
+}
@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFileRepository is a mock implementation of FileRepository interface
+type MockFileRepository struct {
+	mock.Mock
+}
+
+// Create mocks the Create method
+func (m *MockFileRepository) Create(ctx context.Context, file *domain.File) error {
+	args := m.Called(ctx, file)
+	return args.Error(0)
+}
+
+// GetByID mocks the GetByID method
+func (m *MockFileRepository) GetByID(ctx context.Context, id uint) (*domain.File, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+// GetByOwner mocks the GetByOwner method
+func (m *MockFileRepository) GetByOwner(ctx context.Context, ownerID uint, limit, offset int) ([]*domain.File, error) {
+	args := m.Called(ctx, ownerID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.File), args.Error(1)
+}
+
+// Delete mocks the Delete method
+func (m *MockFileRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTxManager is a mock implementation of TxManager interface. When the
+// expectation returns nil, fn runs against Repos (typically other mocks) and
+// its error is returned; otherwise the configured error is returned without
+// calling fn, as if the transaction could not be started.
+type MockTxManager struct {
+	mock.Mock
+	Repos repository.Repositories
+}
+
+// WithinTransaction mocks the WithinTransaction method
+func (m *MockTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context, repos repository.Repositories) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx, m.Repos)
+}
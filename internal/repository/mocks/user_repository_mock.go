@@ -2,8 +2,10 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -27,6 +29,15 @@ func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*dom
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+// GetByPublicID mocks the GetByPublicID method
+func (m *MockUserRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.User, error) {
+	args := m.Called(ctx, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
 // GetByID mocks the GetByID method
 func (m *MockUserRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
 	args := m.Called(ctx, id)
@@ -36,6 +47,15 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uint) (*domain.User
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+// GetByIDs mocks the GetByIDs method
+func (m *MockUserRepository) GetByIDs(ctx context.Context, ids []uint) ([]*domain.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
 // Update mocks the Update method
 func (m *MockUserRepository) Update(ctx context.Context, user *domain.User) error {
 	args := m.Called(ctx, user)
@@ -61,4 +81,70 @@ func (m *MockUserRepository) GetAll(ctx context.Context, limit, offset int) ([]*
 func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
-} 
\ No newline at end of file
+}
+
+// Restore mocks the Restore method
+func (m *MockUserRepository) Restore(ctx context.Context, id uint) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// Unscoped mocks the Unscoped method
+func (m *MockUserRepository) Unscoped() repository.UserRepository {
+	args := m.Called()
+	return args.Get(0).(repository.UserRepository)
+}
+
+// PurgeDeleted mocks the PurgeDeleted method
+func (m *MockUserRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, deletedBefore, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// ListUnverified mocks the ListUnverified method
+func (m *MockUserRepository) ListUnverified(ctx context.Context, registeredAfter, remindBefore time.Time, maxReminders int, afterID uint, limit int) ([]*domain.User, error) {
+	args := m.Called(ctx, registeredAfter, remindBefore, maxReminders, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
+// MarkVerificationReminded mocks the MarkVerificationReminded method
+func (m *MockUserRepository) MarkVerificationReminded(ctx context.Context, user *domain.User, at time.Time) (bool, error) {
+	args := m.Called(ctx, user, at)
+	return args.Bool(0), args.Error(1)
+}
+
+// ListByDirectory mocks the ListByDirectory method
+func (m *MockUserRepository) ListByDirectory(ctx context.Context) ([]*domain.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
+// Search mocks the Search method
+func (m *MockUserRepository) Search(ctx context.Context, query string, limit, offset int) ([]*domain.User, error) {
+	args := m.Called(ctx, query, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
+// CreateBatch mocks the CreateBatch method
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*domain.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
+// ExistingEmails mocks the ExistingEmails method
+func (m *MockUserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
@@ -0,0 +1,101 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepository interface
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+// CreateSubscription mocks the CreateSubscription method
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+// GetSubscriptionByID mocks the GetSubscriptionByID method
+func (m *MockWebhookRepository) GetSubscriptionByID(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+// UpdateSubscription mocks the UpdateSubscription method
+func (m *MockWebhookRepository) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+// DeleteSubscription mocks the DeleteSubscription method
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// GetAllSubscriptions mocks the GetAllSubscriptions method
+func (m *MockWebhookRepository) GetAllSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookSubscription), args.Error(1)
+}
+
+// GetActiveSubscriptions mocks the GetActiveSubscriptions method
+func (m *MockWebhookRepository) GetActiveSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookSubscription), args.Error(1)
+}
+
+// CreateDelivery mocks the CreateDelivery method
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+// UpdateDelivery mocks the UpdateDelivery method
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+// GetDueDeliveries mocks the GetDueDeliveries method
+func (m *MockWebhookRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+// ClaimDelivery mocks the ClaimDelivery method
+func (m *MockWebhookRepository) ClaimDelivery(ctx context.Context, delivery *domain.WebhookDelivery, now, leaseUntil time.Time) (bool, error) {
+	args := m.Called(ctx, delivery, now, leaseUntil)
+	return args.Bool(0), args.Error(1)
+}
+
+// GetDeliveriesBySubscription mocks the GetDeliveriesBySubscription method
+func (m *MockWebhookRepository) GetDeliveriesBySubscription(ctx context.Context, subscriptionID uint, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+// RecordDeliveryResult mocks the RecordDeliveryResult method
+func (m *MockWebhookRepository) RecordDeliveryResult(ctx context.Context, subscriptionID uint, succeeded bool, disableAfter int, now time.Time) (bool, error) {
+	args := m.Called(ctx, subscriptionID, succeeded, disableAfter, now)
+	return args.Bool(0), args.Error(1)
+}
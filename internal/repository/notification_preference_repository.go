@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPreferenceRepository defines the interface for notification
+// preference data operations
+type NotificationPreferenceRepository interface {
+	// GetByUser retrieves every preference a user has set
+	GetByUser(ctx context.Context, userID uint) ([]*domain.NotificationPreference, error)
+	// Upsert stores the preferences, replacing the user's existing choice for
+	// each type and channel
+	Upsert(ctx context.Context, prefs []*domain.NotificationPreference) error
+}
+
+// notificationPreferenceRepository implements NotificationPreferenceRepository interface
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationPreferenceRepository creates a new notification preference repository
+func NewNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{
+		db: db,
+	}
+}
+
+// GetByUser retrieves a user's preferences ordered by type and channel
+func (r *notificationPreferenceRepository) GetByUser(ctx context.Context, userID uint) ([]*domain.NotificationPreference, error) {
+	var prefs []*domain.NotificationPreference
+	err := dbFor(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("type, channel").
+		Find(&prefs).Error
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// Upsert inserts the preferences, updating the enabled flag of those that exist
+func (r *notificationPreferenceRepository) Upsert(ctx context.Context, prefs []*domain.NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return dbFor(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).
+		Create(prefs).Error
+}
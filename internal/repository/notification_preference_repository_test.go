@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationPreferenceRepository_Upsert(t *testing.T) {
+	repo := NewNotificationPreferenceRepository(newTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Upsert(ctx, []*domain.NotificationPreference{
+		{UserID: 1, Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelWebhook, Enabled: false},
+		{UserID: 1, Type: domain.NotificationAccountActivity, Channel: domain.NotificationChannelEmail, Enabled: true},
+		{UserID: 2, Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelEmail, Enabled: false},
+	}))
+	require.NoError(t, repo.Upsert(ctx, []*domain.NotificationPreference{
+		{UserID: 1, Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelWebhook, Enabled: true},
+	}))
+
+	prefs, err := repo.GetByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, prefs, 2, "the existing preference is updated in place")
+	assert.Equal(t, domain.NotificationAccountActivity, prefs[0].Type)
+	assert.Equal(t, domain.NotificationSecurityAlert, prefs[1].Type)
+	assert.True(t, prefs[1].Enabled)
+}
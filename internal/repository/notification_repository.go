@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository defines the interface for in-app notification data
+// operations. Every query is scoped to one user.
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *domain.Notification) error
+	// ListByUser retrieves a user's notifications with pagination, newest
+	// first, optionally only the unread ones
+	ListByUser(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]*domain.Notification, error)
+	// CountUnread counts a user's unread notifications
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	// MarkRead sets the read time of a user's notification unless it is
+	// already read. It returns the notification, or nil when the user has
+	// no notification with that ID.
+	MarkRead(ctx context.Context, userID, id uint, at time.Time) (*domain.Notification, error)
+}
+
+// notificationRepository implements NotificationRepository interface. Create
+// comes from the embedded Repository.
+type notificationRepository struct {
+	*Repository[domain.Notification]
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{
+		Repository: NewRepository[domain.Notification](db),
+	}
+}
+
+// ListByUser retrieves a user's notifications with pagination, newest first
+func (r *notificationRepository) ListByUser(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	var notifications []*domain.Notification
+	query := dbFor(ctx, r.db).Where("user_id = ?", userID).Order("id DESC")
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	err := query.Find(&notifications).Error
+	return notifications, err
+}
+
+// CountUnread counts a user's notifications without a read time
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := dbFor(ctx, r.db).Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead stamps the notification as read and reloads it
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id uint, at time.Time) (*domain.Notification, error) {
+	err := dbFor(ctx, r.db).Model(&domain.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", at).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var notification domain.Notification
+	err = dbFor(ctx, r.db).Where("id = ? AND user_id = ?", id, userID).First(&notification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
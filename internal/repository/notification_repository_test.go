@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationRepository_Inbox(t *testing.T) {
+	repo := NewNotificationRepository(newTestDB(t))
+	ctx := context.Background()
+
+	first := &domain.Notification{UserID: 1, Type: domain.NotificationSecurityAlert, Payload: `{"subject":"one"}`}
+	second := &domain.Notification{UserID: 1, Type: domain.NotificationAccountActivity, Payload: `{"subject":"two"}`}
+	other := &domain.Notification{UserID: 2, Type: domain.NotificationSecurityAlert, Payload: `{"subject":"other"}`}
+	for _, n := range []*domain.Notification{first, second, other} {
+		require.NoError(t, repo.Create(ctx, n))
+	}
+
+	count, err := repo.CountUnread(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	found, err := repo.MarkRead(ctx, 2, first.ID, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, found, "users can't mark other users' notifications")
+
+	readAt := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	found, err = repo.MarkRead(ctx, 1, first.ID, readAt)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	require.NotNil(t, found.ReadAt)
+	assert.True(t, readAt.Equal(*found.ReadAt))
+
+	found, err = repo.MarkRead(ctx, 1, first.ID, time.Now())
+	require.NoError(t, err)
+	assert.True(t, readAt.Equal(*found.ReadAt), "marking again keeps the first read time")
+
+	all, err := repo.ListByUser(ctx, 1, false, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, second.ID, all[0].ID, "newest first")
+
+	unread, err := repo.ListByUser(ctx, 1, true, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, unread, 1)
+	assert.Equal(t, second.ID, unread[0].ID)
+
+	count, err = repo.CountUnread(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
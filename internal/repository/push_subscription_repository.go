@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PushSubscriptionRepository defines the interface for browser push
+// subscription data operations
+type PushSubscriptionRepository interface {
+	// Upsert stores sub, replacing the subscription with the same endpoint
+	Upsert(ctx context.Context, sub *domain.PushSubscription) error
+	// GetByID retrieves a subscription, or nil when there is none
+	GetByID(ctx context.Context, id uint) (*domain.PushSubscription, error)
+	// ListByUser returns the user's subscriptions, oldest first
+	ListByUser(ctx context.Context, userID uint) ([]*domain.PushSubscription, error)
+	// Delete removes a subscription
+	Delete(ctx context.Context, id uint) error
+}
+
+// pushSubscriptionRepository implements PushSubscriptionRepository interface
+type pushSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewPushSubscriptionRepository creates a new push subscription repository
+func NewPushSubscriptionRepository(db *gorm.DB) PushSubscriptionRepository {
+	return &pushSubscriptionRepository{
+		db: db,
+	}
+}
+
+// Upsert inserts the subscription or, when its endpoint is already stored,
+// moves that row to the new user and keys. sub's ID is set either way.
+func (r *pushSubscriptionRepository) Upsert(ctx context.Context, sub *domain.PushSubscription) error {
+	db := dbFor(ctx, r.db)
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "user_agent", "updated_at"}),
+	}).Create(sub).Error
+	if err != nil {
+		return err
+	}
+	// Not every driver returns the ID of an updated row
+	var stored domain.PushSubscription
+	if err := db.Where("endpoint = ?", sub.Endpoint).First(&stored).Error; err != nil {
+		return err
+	}
+	*sub = stored
+	return nil
+}
+
+// GetByID retrieves a subscription by ID
+func (r *pushSubscriptionRepository) GetByID(ctx context.Context, id uint) (*domain.PushSubscription, error) {
+	var sub domain.PushSubscription
+	err := dbFor(ctx, r.db).First(&sub, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListByUser returns the user's subscriptions
+func (r *pushSubscriptionRepository) ListByUser(ctx context.Context, userID uint) ([]*domain.PushSubscription, error) {
+	var subs []*domain.PushSubscription
+	err := dbFor(ctx, r.db).Where("user_id = ?", userID).Order("id").Find(&subs).Error
+	return subs, err
+}
+
+// Delete removes the subscription
+func (r *pushSubscriptionRepository) Delete(ctx context.Context, id uint) error {
+	return dbFor(ctx, r.db).Delete(&domain.PushSubscription{}, id).Error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushSubscriptionRepository_Upsert(t *testing.T) {
+	repo := NewPushSubscriptionRepository(newTestDB(t))
+	ctx := context.Background()
+
+	first := &domain.PushSubscription{UserID: 1, Endpoint: "https://push.example.com/a", P256dh: "key-a", Auth: "auth-a"}
+	require.NoError(t, repo.Upsert(ctx, first))
+	require.NotZero(t, first.ID)
+	second := &domain.PushSubscription{UserID: 1, Endpoint: "https://push.example.com/b", P256dh: "key-b", Auth: "auth-b"}
+	require.NoError(t, repo.Upsert(ctx, second))
+
+	// The same browser subscribing again, now signed in as another user
+	again := &domain.PushSubscription{UserID: 2, Endpoint: "https://push.example.com/a", P256dh: "key-c", Auth: "auth-c"}
+	require.NoError(t, repo.Upsert(ctx, again))
+	assert.Equal(t, first.ID, again.ID)
+
+	subs, err := repo.ListByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, second.ID, subs[0].ID)
+
+	found, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, uint(2), found.UserID)
+	assert.Equal(t, "key-c", found.P256dh)
+
+	require.NoError(t, repo.Delete(ctx, first.ID))
+	found, err = repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"gorm.io/gorm"
+)
+
+// Repository implements the CRUD operations shared by every entity. Entity
+// repositories embed it and add only their entity-specific queries.
+type Repository[T any] struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new generic repository for entity type T
+func NewRepository[T any](db *gorm.DB) *Repository[T] {
+	return &Repository[T]{
+		db: db,
+	}
+}
+
+// dbFor returns the session a repository should query: the request's tenant
+// database in multi-tenant mode, otherwise db. Scopes set on db, such as
+// Unscoped, carry over to the tenant database.
+func dbFor(ctx context.Context, db *gorm.DB) *gorm.DB {
+	tenantDB := database.TenantDB(ctx)
+	if tenantDB == nil {
+		return db.WithContext(ctx)
+	}
+	if db.Statement.Unscoped {
+		tenantDB = tenantDB.Unscoped()
+	}
+	return tenantDB.WithContext(ctx)
+}
+
+// Unscoped returns a copy of the repository whose queries include
+// soft-deleted rows
+func (r *Repository[T]) Unscoped() *Repository[T] {
+	return &Repository[T]{
+		db: r.db.Unscoped(),
+	}
+}
+
+// Restore clears the soft-delete marker of an entity, reporting whether a
+// deleted row was found
+func (r *Repository[T]) Restore(ctx context.Context, id uint) (bool, error) {
+	var model T
+	result := dbFor(ctx, r.db).Unscoped().Model(&model).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Create creates a new entity in the database
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	return dbFor(ctx, r.db).Create(entity).Error
+}
+
+// defaultCreateBatchSize is used by CreateBatch when DB_CREATE_BATCH_SIZE is unset
+const defaultCreateBatchSize = 100
+
+// CreateBatch inserts entities in chunks of the configured CreateBatchSize.
+// All chunks share one transaction unless SkipDefaultTransaction is set.
+func (r *Repository[T]) CreateBatch(ctx context.Context, entities []*T) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	batchSize := r.db.CreateBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCreateBatchSize
+	}
+	return dbFor(ctx, r.db).CreateInBatches(entities, batchSize).Error
+}
+
+// GetByID retrieves an entity by ID, returning nil when it does not exist
+func (r *Repository[T]) GetByID(ctx context.Context, id uint) (*T, error) {
+	var entity T
+	err := dbFor(ctx, r.db).Where("id = ?", id).First(&entity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // Return nil instead of error for not found
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// GetByIDs retrieves the entities matching the given IDs; missing IDs are skipped
+func (r *Repository[T]) GetByIDs(ctx context.Context, ids []uint) ([]*T, error) {
+	var entities []*T
+	if len(ids) == 0 {
+		return entities, nil
+	}
+
+	err := dbFor(ctx, r.db).Where("id IN ?", ids).Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Update saves every field of the entity
+func (r *Repository[T]) Update(ctx context.Context, entity *T) error {
+	return dbFor(ctx, r.db).Save(entity).Error
+}
+
+// Delete deletes an entity by ID (soft delete when T has a DeletedAt field)
+func (r *Repository[T]) Delete(ctx context.Context, id uint) error {
+	var entity T
+	return dbFor(ctx, r.db).Delete(&entity, id).Error
+}
+
+// List retrieves entities with pagination; a non-positive limit or offset is ignored
+func (r *Repository[T]) List(ctx context.Context, limit, offset int) ([]*T, error) {
+	var entities []*T
+	var model T
+	query := dbFor(ctx, r.db).Model(&model)
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Count returns the total number of entities
+func (r *Repository[T]) Count(ctx context.Context) (int64, error) {
+	var count int64
+	var model T
+	err := dbFor(ctx, r.db).Model(&model).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
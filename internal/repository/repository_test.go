@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_CRUD(t *testing.T) {
+	repo := NewRepository[domain.File](newTestDB(t))
+	ctx := context.Background()
+
+	file := &domain.File{OwnerID: 1, Key: "files/1/a", Name: "a.txt", ContentType: "text/plain", Size: 3}
+	require.NoError(t, repo.Create(ctx, file))
+	require.NotZero(t, file.ID)
+
+	file.Name = "b.txt"
+	require.NoError(t, repo.Update(ctx, file))
+
+	found, err := repo.GetByID(ctx, file.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "b.txt", found.Name)
+
+	require.NoError(t, repo.Create(ctx, &domain.File{OwnerID: 1, Key: "files/1/c", Name: "c.txt", ContentType: "text/plain"}))
+
+	page, err := repo.List(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+
+	require.NoError(t, repo.Delete(ctx, file.ID))
+
+	found, err = repo.GetByID(ctx, file.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found, "soft-deleted rows are hidden")
+
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestSearchWords(t *testing.T) {
+	assert.Equal(t, []string{"alice", "example", "com"}, searchWords(" Alice@Example.com "))
+	assert.Equal(t, []string{"o", "brien"}, searchWords(`+"O'Brien"*`))
+	assert.Empty(t, searchWords("-+*()~<>"))
+}
+
+func TestRepository_TenantRouting(t *testing.T) {
+	shared, tenantDB := newTestDB(t), newTestDB(t)
+	repo := NewRepository[domain.File](shared)
+	ctx := database.WithTenant(context.Background(), "acme", tenantDB)
+
+	file := &domain.File{OwnerID: 1, Key: "files/1/a", Name: "a.txt", ContentType: "text/plain"}
+	require.NoError(t, repo.Create(ctx, file))
+
+	found, err := repo.GetByID(context.Background(), file.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found, "tenant rows are not written to the shared database")
+
+	require.NoError(t, repo.Delete(ctx, file.ID))
+	found, err = repo.Unscoped().GetByID(ctx, file.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, found, "Unscoped carries over to the tenant database")
+}
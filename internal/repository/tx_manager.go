@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories is the set of repositories sharing one database handle. Inside
+// TxManager.WithinTransaction every repository runs on the same transaction.
+type Repositories struct {
+	Users    UserRepository
+	Webhooks WebhookRepository
+	Files    FileRepository
+	Audit    AuditRepository
+	Jobs     JobRepository
+	Emails   EmailRepository
+
+	NotificationPreferences NotificationPreferenceRepository
+	Notifications           NotificationRepository
+	VerificationCodes       VerificationCodeRepository
+	PushSubscriptions       PushSubscriptionRepository
+	BillingAccounts         BillingAccountRepository
+}
+
+// NewRepositories creates every repository on the given database handle
+func NewRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:    NewUserRepository(db),
+		Webhooks: NewWebhookRepository(db),
+		Files:    NewFileRepository(db),
+		Audit:    NewAuditRepository(db),
+		Jobs:     NewJobRepository(db),
+		Emails:   NewEmailRepository(db),
+
+		NotificationPreferences: NewNotificationPreferenceRepository(db),
+		Notifications:           NewNotificationRepository(db),
+		VerificationCodes:       NewVerificationCodeRepository(db),
+		PushSubscriptions:       NewPushSubscriptionRepository(db),
+		BillingAccounts:         NewBillingAccountRepository(db),
+	}
+}
+
+// TxManager runs multi-step operations atomically
+type TxManager interface {
+	// WithinTransaction calls fn with repositories bound to a new transaction.
+	// The transaction commits when fn returns nil and rolls back when fn
+	// returns an error or panics.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+// txManager implements TxManager interface
+type txManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager creates a new transaction manager
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn inside a database transaction
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	return dbFor(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(ctx, NewRepositories(tx))
+	})
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxManager_CommitsOnSuccess(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context, repos Repositories) error {
+		user := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed", Role: domain.RoleUser}
+		if err := repos.Users.Create(ctx, user); err != nil {
+			return err
+		}
+		return repos.Files.Create(ctx, &domain.File{OwnerID: user.ID, Key: "files/1/a", Name: "a.txt", ContentType: "text/plain"})
+	})
+	require.NoError(t, err)
+
+	count, err := NewUserRepository(db).Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestTxManager_RollsBackOnError(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	err := NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context, repos Repositories) error {
+		user := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed", Role: domain.RoleUser}
+		if err := repos.Users.Create(ctx, user); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	user, err := NewUserRepository(db).GetByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, user, "the insert is rolled back")
+}
@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/domain"
 	"gorm.io/gorm"
@@ -11,38 +14,67 @@ import (
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
+	CreateBatch(ctx context.Context, users []*domain.User) error
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	// GetByPublicID retrieves a user by the opaque ID exposed in URLs
+	GetByPublicID(ctx context.Context, publicID string) (*domain.User, error)
+	// ExistingEmails returns which of the given emails already belong to a user
+	ExistingEmails(ctx context.Context, emails []string) ([]string, error)
 	GetByID(ctx context.Context, id uint) (*domain.User, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]*domain.User, error)
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id uint) error
 	GetAll(ctx context.Context, limit, offset int) ([]*domain.User, error)
+	// Search finds users whose name or email contains words starting with
+	// each word of query
+	Search(ctx context.Context, query string, limit, offset int) ([]*domain.User, error)
 	Count(ctx context.Context) (int64, error)
+	// Restore undeletes a soft-deleted user, reporting whether one was found
+	Restore(ctx context.Context, id uint) (bool, error)
+	// Unscoped returns a repository whose reads include soft-deleted users
+	Unscoped() UserRepository
+	// PurgeDeleted permanently deletes up to limit users soft-deleted before
+	// deletedBefore, lowest IDs first, and returns how many were deleted; a
+	// non-positive limit deletes them all
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+	// ListUnverified returns up to limit users with IDs above afterID, lowest
+	// first, whose email address is unverified: those who registered after
+	// registeredAfter, were sent fewer than maxReminders verification
+	// reminders, and were last reminded, or registered, before remindBefore
+	ListUnverified(ctx context.Context, registeredAfter, remindBefore time.Time, maxReminders int, afterID uint, limit int) ([]*domain.User, error)
+	// MarkVerificationReminded counts a verification reminder sent to user
+	// at at, reporting false when the user verified their address or was
+	// reminded since user was read. It leaves the version alone.
+	MarkVerificationReminded(ctx context.Context, user *domain.User, at time.Time) (bool, error)
+	// ListByDirectory returns the users synced from an external directory,
+	// those with a DirectoryID, ordered by ID
+	ListByDirectory(ctx context.Context) ([]*domain.User, error)
 }
 
-// userRepository implements UserRepository interface
+// userRepository implements UserRepository interface. Create, GetByID,
+// GetByIDs, Update, Delete and Count come from the embedded Repository.
 type userRepository struct {
-	db *gorm.DB
+	*Repository[domain.User]
 }
 
 // NewUserRepository creates a new user repository
 func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{
-		db: db,
+		Repository: NewRepository[domain.User](db),
 	}
 }
 
-// Create creates a new user in the database
-func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
-	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
-		return err
+// Unscoped returns a user repository that includes soft-deleted users
+func (r *userRepository) Unscoped() UserRepository {
+	return &userRepository{
+		Repository: r.Repository.Unscoped(),
 	}
-	return nil
 }
 
 // GetByEmail retrieves a user by email
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := dbFor(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // Return nil instead of error for not found
@@ -52,60 +84,199 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 	return &user, nil
 }
 
-// GetByID retrieves a user by ID
-func (r *userRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+// GetByPublicID retrieves a user by public ID
+func (r *userRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
+	err := dbFor(ctx, r.db).Where("public_id = ?", publicID).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil // Return nil instead of error for not found
+			return nil, nil
 		}
 		return nil, err
 	}
 	return &user, nil
 }
 
-// Update updates a user in the database
+// BackfillPublicIDs assigns a public ID from generate to every user created
+// before the public_id column existed, soft-deleted ones included, and
+// returns how many were updated
+func BackfillPublicIDs(ctx context.Context, db *gorm.DB, generate func() string) (int, error) {
+	const batchSize = 500
+
+	updated := 0
+	for {
+		var userIDs []uint
+		err := db.WithContext(ctx).Unscoped().Model(&domain.User{}).
+			Where("public_id IS NULL OR public_id = ''").
+			Order("id").Limit(batchSize).
+			Pluck("id", &userIDs).Error
+		if err != nil {
+			return updated, err
+		}
+
+		for _, id := range userIDs {
+			err := db.WithContext(ctx).Unscoped().Model(&domain.User{}).
+				Where("id = ?", id).
+				UpdateColumn("public_id", generate()).Error
+			if err != nil {
+				return updated, err
+			}
+			updated++
+		}
+
+		if len(userIDs) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+// ExistingEmails returns which of the given emails are already registered,
+// including by soft-deleted users since the unique index still covers them
+func (r *userRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	existing := []string{}
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	err := dbFor(ctx, r.db).Unscoped().Model(&domain.User{}).
+		Where("email IN ?", emails).
+		Pluck("email", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// Update saves every field of the user if it is still at the version that
+// was read, and increments the version. It returns a
+// *domain.VersionConflictError when another update got there first.
 func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
-	if err := r.db.WithContext(ctx).Save(user).Error; err != nil {
-		return err
+	readVersion := user.Version
+	user.Version++
+
+	result := dbFor(ctx, r.db).Model(user).
+		Where("version = ?", readVersion).
+		Select("*").
+		Updates(user)
+	if result.Error != nil {
+		user.Version = readVersion
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		user.Version = readVersion
+		return &domain.VersionConflictError{Entity: "user", ID: user.ID}
 	}
 	return nil
 }
 
-// Delete deletes a user from the database (soft delete)
-func (r *userRepository) Delete(ctx context.Context, id uint) error {
-	if err := r.db.WithContext(ctx).Delete(&domain.User{}, id).Error; err != nil {
-		return err
+// PurgeDeleted permanently deletes up to limit users soft-deleted before
+// deletedBefore. The IDs are selected first so the DELETE only locks the
+// rows it removes.
+func (r *userRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	query := dbFor(ctx, r.db).Unscoped().Model(&domain.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Order("id")
+	if limit > 0 {
+		query = query.Limit(limit)
 	}
-	return nil
+
+	var userIDs []uint
+	if err := query.Pluck("id", &userIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	result := dbFor(ctx, r.db).Unscoped().
+		Where("id IN ? AND deleted_at IS NOT NULL", userIDs).
+		Delete(&domain.User{})
+	return result.RowsAffected, result.Error
+}
+
+// ListUnverified returns unverified users due a verification reminder
+func (r *userRepository) ListUnverified(ctx context.Context, registeredAfter, remindBefore time.Time, maxReminders int, afterID uint, limit int) ([]*domain.User, error) {
+	var users []*domain.User
+	err := dbFor(ctx, r.db).
+		Where("email_verified_at IS NULL AND verification_reminders < ? AND id > ?", maxReminders, afterID).
+		Where("created_at > ? AND COALESCE(verification_reminded_at, created_at) < ?", registeredAfter, remindBefore).
+		Order("id").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// MarkVerificationReminded counts a reminder unless one was counted since
+// user was read, updating only the reminder columns
+func (r *userRepository) MarkVerificationReminded(ctx context.Context, user *domain.User, at time.Time) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&domain.User{}).
+		Where("id = ? AND email_verified_at IS NULL AND verification_reminders = ?", user.ID, user.VerificationReminders).
+		UpdateColumns(map[string]interface{}{
+			"verification_reminders":   user.VerificationReminders + 1,
+			"verification_reminded_at": at,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	user.VerificationReminders++
+	user.VerificationRemindedAt = &at
+	return true, nil
+}
+
+// ListByDirectory returns the users synced from an external directory
+func (r *userRepository) ListByDirectory(ctx context.Context) ([]*domain.User, error) {
+	var users []*domain.User
+	err := dbFor(ctx, r.db).Where("directory_id IS NOT NULL").Order("id").Find(&users).Error
+	return users, err
 }
 
 // GetAll retrieves all users with pagination
 func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]*domain.User, error) {
-	var users []*domain.User
-	query := r.db.WithContext(ctx).Model(&domain.User{})
-	
-	if limit > 0 {
-		query = query.Limit(limit)
+	return r.List(ctx, limit, offset)
+}
+
+// Search finds users matching every word of query using the driver's
+// full-text index: MySQL FULLTEXT in boolean mode or a Postgres tsvector.
+// SQLite, used for development and tests, falls back to LIKE.
+func (r *userRepository) Search(ctx context.Context, query string, limit, offset int) ([]*domain.User, error) {
+	words := searchWords(query)
+	if len(words) == 0 {
+		return []*domain.User{}, nil
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
+
+	db := dbFor(ctx, r.db)
+	switch db.Dialector.Name() {
+	case "mysql":
+		terms := make([]string, len(words))
+		for i, word := range words {
+			terms[i] = "+" + word + "*"
+		}
+		db = db.Where("MATCH(name, email) AGAINST (? IN BOOLEAN MODE)", strings.Join(terms, " "))
+	case "postgres":
+		terms := make([]string, len(words))
+		for i, word := range words {
+			terms[i] = word + ":*"
+		}
+		db = db.Where("to_tsvector('simple', name || ' ' || email) @@ to_tsquery('simple', ?)", strings.Join(terms, " & "))
+	default:
+		for _, word := range words {
+			pattern := "%" + word + "%"
+			db = db.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+		}
 	}
-	
-	err := query.Find(&users).Error
+
+	var users []*domain.User
+	err := db.Order("id").Limit(limit).Offset(offset).Find(&users).Error
 	if err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
-// Count returns the total number of users in the database
-func (r *userRepository) Count(ctx context.Context) (int64, error) {
-	var count int64
-	err := r.db.WithContext(ctx).Model(&domain.User{}).Count(&count).Error
-	if err != nil {
-		return 0, err
-	}
-	return count, nil
-} 
\ No newline at end of file
+// searchWords lowercases query and splits it into letter/digit words, which
+// also strips every full-text operator character
+func searchWords(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
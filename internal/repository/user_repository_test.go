@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/crypto"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a migrated SQLite database private to the test
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := database.Connect(&config.DatabaseConfig{
+		Driver:     database.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, database.MigrateUp(context.Background(), db, database.DriverSQLite))
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestUserRepository_SQLite(t *testing.T) {
+	repo := NewUserRepository(newTestDB(t))
+	ctx := context.Background()
+
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed", Role: domain.RoleUser}
+	bob := &domain.User{Name: "Bob", Email: "bob@example.com", Password: "hashed", Role: domain.RoleAdmin}
+	require.NoError(t, repo.Create(ctx, alice))
+	require.NoError(t, repo.Create(ctx, bob))
+
+	t.Run("unique email", func(t *testing.T) {
+		err := repo.Create(ctx, &domain.User{Name: "Other", Email: "alice@example.com", Password: "hashed"})
+		assert.Error(t, err)
+	})
+
+	t.Run("lookups", func(t *testing.T) {
+		found, err := repo.GetByEmail(ctx, "bob@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, bob.ID, found.ID)
+
+		users, err := repo.GetByIDs(ctx, []uint{alice.ID, bob.ID})
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+
+		missing, err := repo.GetByID(ctx, 999)
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		found, err := repo.Search(ctx, "ALI", 10, 0)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, alice.ID, found[0].ID)
+
+		found, err = repo.Search(ctx, "example bob", 10, 0)
+		require.NoError(t, err)
+		require.Len(t, found, 1, "every word must match")
+		assert.Equal(t, bob.ID, found[0].ID)
+
+		found, err = repo.Search(ctx, "%_*", 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, found, "operator characters are stripped")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, alice.ID))
+
+		count, err := repo.Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("unscoped and restore", func(t *testing.T) {
+		all, err := repo.Unscoped().GetAll(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		restored, err := repo.Restore(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.True(t, restored)
+
+		found, err := repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+
+		restored, err = repo.Restore(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.False(t, restored, "only deleted users can be restored")
+	})
+}
+
+func TestUserRepository_PurgeDeleted(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	users := make([]*domain.User, 0, 4)
+	for i := 0; i < 4; i++ {
+		user := &domain.User{Name: "User", Email: fmt.Sprintf("user-%d@example.com", i), Password: "hashed", Role: domain.RoleUser}
+		require.NoError(t, repo.Create(ctx, user))
+		users = append(users, user)
+	}
+	// users[0] and users[1] were deleted long ago, users[2] just now
+	for _, user := range users[:3] {
+		require.NoError(t, repo.Delete(ctx, user.ID))
+	}
+	longAgo := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, db.Unscoped().Model(&domain.User{}).Where("id IN ?", []uint{users[0].ID, users[1].ID}).Update("deleted_at", longAgo).Error)
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	purged, err := repo.PurgeDeleted(ctx, cutoff, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged, "limited to the batch size")
+
+	purged, err = repo.PurgeDeleted(ctx, cutoff, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	purged, err = repo.PurgeDeleted(ctx, cutoff, 10)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	remaining, err := repo.Unscoped().GetAll(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, remaining, 2, "recently deleted and live users are kept")
+	assert.Equal(t, users[2].ID, remaining[0].ID)
+	assert.Equal(t, users[3].ID, remaining[1].ID)
+}
+
+func TestUserRepository_VerificationReminders(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	users := make([]*domain.User, 0, 4)
+	for i := 0; i < 4; i++ {
+		user := &domain.User{Name: "User", Email: fmt.Sprintf("user-%d@example.com", i), Password: "hashed", Role: domain.RoleUser}
+		require.NoError(t, repo.Create(ctx, user))
+		users = append(users, user)
+	}
+	// Everyone registered a day ago, but users[1] is verified and users[2]
+	// registered long ago
+	require.NoError(t, db.Model(&domain.User{}).Where("1 = 1").Update("created_at", time.Now().Add(-25*time.Hour)).Error)
+	verified := time.Now()
+	require.NoError(t, db.Model(&domain.User{}).Where("id = ?", users[1].ID).Update("email_verified_at", &verified).Error)
+	require.NoError(t, db.Model(&domain.User{}).Where("id = ?", users[2].ID).Update("created_at", time.Now().Add(-30*24*time.Hour)).Error)
+
+	registeredAfter, remindBefore := time.Now().Add(-72*time.Hour), time.Now().Add(-24*time.Hour)
+	due, err := repo.ListUnverified(ctx, registeredAfter, remindBefore, 2, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, users[0].ID, due[0].ID)
+	assert.Equal(t, users[3].ID, due[1].ID)
+
+	due, err = repo.ListUnverified(ctx, registeredAfter, remindBefore, 2, users[0].ID, 1)
+	require.NoError(t, err)
+	require.Len(t, due, 1, "pages by ID")
+	assert.Equal(t, users[3].ID, due[0].ID)
+
+	// A reminder counts once, and isn't due again until the interval passes
+	stale := *due[0]
+	marked, err := repo.MarkVerificationReminded(ctx, due[0], time.Now())
+	require.NoError(t, err)
+	assert.True(t, marked)
+	assert.Equal(t, 1, due[0].VerificationReminders)
+	marked, err = repo.MarkVerificationReminded(ctx, &stale, time.Now())
+	require.NoError(t, err)
+	assert.False(t, marked, "already reminded since read")
+
+	due, err = repo.ListUnverified(ctx, registeredAfter, remindBefore, 2, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, users[0].ID, due[0].ID)
+
+	reloaded, err := repo.GetByID(ctx, users[3].ID)
+	require.NoError(t, err)
+	assert.Equal(t, users[3].Version, reloaded.Version, "the version is unchanged")
+}
+
+func TestUserRepository_CreateBatch(t *testing.T) {
+	db := newTestDB(t)
+	db.CreateBatchSize = 2
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	users := make([]*domain.User, 0, 5)
+	for i := 0; i < 5; i++ {
+		users = append(users, &domain.User{Name: "User", Email: fmt.Sprintf("user-%d@example.com", i), Password: "hashed", Role: domain.RoleUser})
+	}
+	require.NoError(t, repo.CreateBatch(ctx, users))
+	for _, user := range users {
+		assert.NotZero(t, user.ID, "IDs are populated across chunks")
+	}
+
+	existing, err := repo.ExistingEmails(ctx, []string{"user-1@example.com", "new@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1@example.com"}, existing)
+
+	t.Run("conflict rolls back the whole batch", func(t *testing.T) {
+		err := repo.CreateBatch(ctx, []*domain.User{
+			{Name: "Fresh", Email: "fresh@example.com", Password: "hashed", Role: domain.RoleUser},
+			{Name: "Dup", Email: "user-0@example.com", Password: "hashed", Role: domain.RoleUser},
+		})
+		assert.Error(t, err)
+
+		found, err := repo.GetByEmail(ctx, "fresh@example.com")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	assert.NoError(t, repo.CreateBatch(ctx, nil))
+}
+
+func TestUserRepository_UpdateOptimisticLocking(t *testing.T) {
+	repo := NewUserRepository(newTestDB(t))
+	ctx := context.Background()
+
+	user := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed", Role: domain.RoleUser}
+	require.NoError(t, repo.Create(ctx, user))
+	assert.Equal(t, uint(1), user.Version)
+
+	first, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	second, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	first.Name = "Alice A."
+	require.NoError(t, repo.Update(ctx, first))
+	assert.Equal(t, uint(2), first.Version)
+
+	second.Name = "Alice B."
+	err = repo.Update(ctx, second)
+	var conflict *domain.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, user.ID, conflict.ID)
+	assert.Equal(t, uint(1), second.Version, "a rejected update leaves the version as read")
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice A.", stored.Name)
+	assert.Equal(t, uint(2), stored.Version)
+}
+
+func TestUserRepository_PhoneEncryptedAtRest(t *testing.T) {
+	keyring, err := crypto.NewKeyring([]string{"k1:" + base64.StdEncoding.EncodeToString(make([]byte, 32))})
+	require.NoError(t, err)
+	crypto.SetFieldEnvelope(crypto.NewEnvelope(keyring))
+	t.Cleanup(func() { crypto.SetFieldEnvelope(nil) })
+
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed", Role: domain.RoleUser, Phone: "+1 555 0100"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	var stored string
+	require.NoError(t, db.Raw("SELECT phone FROM users WHERE id = ?", user.ID).Scan(&stored).Error)
+	assert.True(t, strings.HasPrefix(stored, "enc:v1:k1:"))
+	assert.NotContains(t, stored, "555")
+
+	found, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "+1 555 0100", found.Phone)
+}
+
+func TestUserRepository_PublicIDs(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	user := &domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed", Role: domain.RoleUser}
+	require.NoError(t, repo.Create(ctx, user))
+	require.NotEmpty(t, user.PublicID, "public IDs are assigned on create")
+
+	found, err := repo.GetByPublicID(ctx, user.PublicID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, user.ID, found.ID)
+
+	missing, err := repo.GetByPublicID(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	t.Run("backfill", func(t *testing.T) {
+		legacy := &domain.User{Name: "Legacy", Email: "legacy@example.com", Password: "hashed", Role: domain.RoleUser}
+		require.NoError(t, repo.Create(ctx, legacy))
+		require.NoError(t, repo.Delete(ctx, legacy.ID))
+		require.NoError(t, db.Exec("UPDATE users SET public_id = NULL WHERE id = ?", legacy.ID).Error)
+
+		backfilled, err := BackfillPublicIDs(ctx, db, func() string { return "backfilled" })
+		require.NoError(t, err)
+		assert.Equal(t, 1, backfilled, "soft-deleted users are backfilled too")
+
+		found, err := repo.Unscoped().GetByPublicID(ctx, "backfilled")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, legacy.ID, found.ID)
+
+		backfilled, err = BackfillPublicIDs(ctx, db, func() string { return "again" })
+		require.NoError(t, err)
+		assert.Zero(t, backfilled)
+	})
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// VerificationCodeRepository defines the interface for texted one-time code
+// data operations. A user has at most one code per purpose.
+type VerificationCodeRepository interface {
+	// Replace stores code, deleting the user's earlier codes for its purpose
+	Replace(ctx context.Context, code *domain.VerificationCode) error
+	// Get retrieves the user's code for purpose, or nil when there is none
+	Get(ctx context.Context, userID uint, purpose string) (*domain.VerificationCode, error)
+	// IncrementAttempts counts a guess against the code before it is
+	// checked, reporting false without counting it once the code has had
+	// maxAttempts guesses
+	IncrementAttempts(ctx context.Context, id uint, maxAttempts int) (bool, error)
+	// Delete removes a code once it is used up
+	Delete(ctx context.Context, id uint) error
+}
+
+// verificationCodeRepository implements VerificationCodeRepository interface
+type verificationCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationCodeRepository creates a new verification code repository
+func NewVerificationCodeRepository(db *gorm.DB) VerificationCodeRepository {
+	return &verificationCodeRepository{
+		db: db,
+	}
+}
+
+// Replace deletes the user's codes for the purpose and creates code, in one transaction
+func (r *verificationCodeRepository) Replace(ctx context.Context, code *domain.VerificationCode) error {
+	return dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ?", code.UserID, code.Purpose).Delete(&domain.VerificationCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+}
+
+// Get retrieves the user's newest code for purpose
+func (r *verificationCodeRepository) Get(ctx context.Context, userID uint, purpose string) (*domain.VerificationCode, error) {
+	var code domain.VerificationCode
+	err := dbFor(ctx, r.db).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("id DESC").
+		First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// IncrementAttempts adds one to the code's attempt count with a conditional
+// update, so concurrent guesses can't get past the limit together
+func (r *verificationCodeRepository) IncrementAttempts(ctx context.Context, id uint, maxAttempts int) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&domain.VerificationCode{}).
+		Where("id = ? AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete removes the code
+func (r *verificationCodeRepository) Delete(ctx context.Context, id uint) error {
+	return dbFor(ctx, r.db).Delete(&domain.VerificationCode{}, id).Error
+}
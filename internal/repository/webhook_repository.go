@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository defines the interface for webhook data operations
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error
+	GetSubscriptionByID(ctx context.Context, id uint) (*domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id uint) error
+	GetAllSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error)
+	GetActiveSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error)
+	// ClaimDelivery takes a due delivery for sending, reporting false when
+	// another caller claimed it first. The claim counts an attempt and moves
+	// NextAttemptAt to leaseUntil, when the delivery is due again should its
+	// sender die before recording the outcome.
+	ClaimDelivery(ctx context.Context, delivery *domain.WebhookDelivery, now, leaseUntil time.Time) (bool, error)
+	GetDeliveriesBySubscription(ctx context.Context, subscriptionID uint, limit, offset int) ([]*domain.WebhookDelivery, error)
+
+	// RecordDeliveryResult resets the subscription's consecutive failures
+	// after a successful attempt, or increments them after a failed one.
+	// When the failures reach disableAfter (0 never disables), an active
+	// subscription is deactivated at now and true is returned.
+	RecordDeliveryResult(ctx context.Context, subscriptionID uint, succeeded bool, disableAfter int, now time.Time) (bool, error)
+}
+
+// webhookRepository implements WebhookRepository interface
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// CreateSubscription creates a new webhook subscription
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	return dbFor(ctx, r.db).Create(sub).Error
+}
+
+// GetSubscriptionByID retrieves a webhook subscription by ID
+func (r *webhookRepository) GetSubscriptionByID(ctx context.Context, id uint) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	err := dbFor(ctx, r.db).Where("id = ?", id).First(&sub).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // Return nil instead of error for not found
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// UpdateSubscription updates a webhook subscription
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) error {
+	return dbFor(ctx, r.db).Save(sub).Error
+}
+
+// DeleteSubscription deletes a webhook subscription (soft delete)
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	return dbFor(ctx, r.db).Delete(&domain.WebhookSubscription{}, id).Error
+}
+
+// GetAllSubscriptions retrieves all webhook subscriptions with pagination
+func (r *webhookRepository) GetAllSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscription, error) {
+	var subs []*domain.WebhookSubscription
+	query := dbFor(ctx, r.db).Model(&domain.WebhookSubscription{}).Order("id")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// GetActiveSubscriptions retrieves all active webhook subscriptions
+func (r *webhookRepository) GetActiveSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	var subs []*domain.WebhookSubscription
+	if err := dbFor(ctx, r.db).Where("active = ?", true).Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// CreateDelivery creates a new webhook delivery record
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return dbFor(ctx, r.db).Create(delivery).Error
+}
+
+// UpdateDelivery updates a webhook delivery record
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return dbFor(ctx, r.db).Save(delivery).Error
+}
+
+// GetDueDeliveries retrieves pending deliveries whose next attempt is due
+func (r *webhookRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := dbFor(ctx, r.db).
+		Where("status = ? AND next_attempt_at <= ?", domain.DeliveryStatusPending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery claims a delivery with a conditional update on its status
+// and attempts, so when several instances poll for due deliveries only one
+// of them sends each
+func (r *webhookRepository) ClaimDelivery(ctx context.Context, delivery *domain.WebhookDelivery, now, leaseUntil time.Time) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&domain.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, domain.DeliveryStatusPending, delivery.Attempts).
+		Updates(map[string]interface{}{
+			"attempts":        delivery.Attempts + 1,
+			"next_attempt_at": leaseUntil,
+			"updated_at":      now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	delivery.Attempts++
+	delivery.NextAttemptAt = leaseUntil
+	delivery.UpdatedAt = now
+	return true, nil
+}
+
+// RecordDeliveryResult updates the subscription's failure count in place, so
+// deliveries sent concurrently don't overwrite each other's results
+func (r *webhookRepository) RecordDeliveryResult(ctx context.Context, subscriptionID uint, succeeded bool, disableAfter int, now time.Time) (bool, error) {
+	db := dbFor(ctx, r.db).Model(&domain.WebhookSubscription{}).Where("id = ?", subscriptionID)
+	if succeeded {
+		return false, db.Where("consecutive_failures <> 0").Update("consecutive_failures", 0).Error
+	}
+
+	if err := db.Update("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error; err != nil {
+		return false, err
+	}
+	if disableAfter <= 0 {
+		return false, nil
+	}
+
+	result := dbFor(ctx, r.db).Model(&domain.WebhookSubscription{}).
+		Where("id = ? AND active = ? AND consecutive_failures >= ?", subscriptionID, true, disableAfter).
+		Updates(map[string]interface{}{
+			"active":      false,
+			"disabled_at": now,
+			"updated_at":  now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDeliveriesBySubscription retrieves the delivery history of a subscription, newest first
+func (r *webhookRepository) GetDeliveriesBySubscription(ctx context.Context, subscriptionID uint, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	query := dbFor(ctx, r.db).Where("subscription_id = ?", subscriptionID).Order("id DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
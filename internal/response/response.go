@@ -0,0 +1,80 @@
+// Package response writes the JSON responses of the API: success bodies,
+// error envelopes translated into the request's language, and pages of
+// lists.
+package response
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+)
+
+// ContentTypeJSON is the content type of JSON responses
+const ContentTypeJSON = "application/json"
+
+// ErrorBody is the envelope of error responses
+type ErrorBody struct {
+	Error string `json:"error"`
+}
+
+// MessageBody is the body of success responses that carry only a message
+type MessageBody struct {
+	Message string `json:"message"`
+}
+
+// Page is the window of a list that a request asked for
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// Meta returns the pagination fields of a page holding count items
+func (p Page) Meta(count int) map[string]interface{} {
+	return map[string]interface{}{
+		"count":  count,
+		"limit":  p.Limit,
+		"offset": p.Offset,
+	}
+}
+
+// JSON writes data as a JSON response
+func JSON(w http.ResponseWriter, data interface{}, statusCode int) {
+	Write(w, ContentTypeJSON, data, statusCode)
+}
+
+// Write writes data as JSON with contentType, for JSON media types such as
+// JSON:API
+func Write(w http.ResponseWriter, contentType string, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
+
+// Error writes the error envelope, translating the message into the
+// request's language
+func Error(w http.ResponseWriter, r *http.Request, message string, statusCode int) {
+	JSON(w, ErrorBody{Error: i18n.FromContext(r.Context()).T(message, nil)}, statusCode)
+}
+
+// Message writes a success response carrying only message
+func Message(w http.ResponseWriter, message string, statusCode int) {
+	JSON(w, MessageBody{Message: message}, statusCode)
+}
+
+// List writes a page of items under key, with message and the page's
+// count, limit and offset. fields adds more top-level fields, such as the
+// search query, and may be nil.
+func List[T any](w http.ResponseWriter, message, key string, items []T, page Page, fields map[string]interface{}) {
+	body := page.Meta(len(items))
+	for name, value := range fields {
+		body[name] = value
+	}
+	body["message"] = message
+	body[key] = items
+	JSON(w, body, http.StatusOK)
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Error(rr, httptest.NewRequest(http.MethodGet, "/", nil), "user not found", http.StatusNotFound)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, ContentTypeJSON, rr.Header().Get("Content-Type"))
+	assert.Equal(t, `{"error":"user not found"}`+"\n", rr.Body.String())
+}
+
+func TestMessage(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Message(rr, "User deleted successfully", http.StatusOK)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"message":"User deleted successfully"}`+"\n", rr.Body.String())
+}
+
+func TestList(t *testing.T) {
+	rr := httptest.NewRecorder()
+	List(rr, "Users retrieved successfully", "users", []string{"ada", "grace"},
+		Page{Limit: 2, Offset: 4}, map[string]interface{}{"query": "a"})
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t,
+		`{"message":"Users retrieved successfully","users":["ada","grace"],"count":2,"limit":2,"offset":4,"query":"a"}`,
+		rr.Body.String())
+}
+
+func TestWrite_ContentType(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Write(rr, "application/vnd.api+json", map[string]interface{}{"meta": map[string]int{"count": 0}}, http.StatusOK)
+
+	assert.Equal(t, "application/vnd.api+json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `{"meta":{"count":0}}`+"\n", rr.Body.String())
+}
@@ -1,39 +1,319 @@
 package routes
 
 import (
-	"encoding/json"
+	"expvar"
 	"net/http"
 
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/graph"
 	"github.com/aungmyozaw92/go-api-setup/internal/handler"
 	"github.com/aungmyozaw92/go-api-setup/internal/middleware"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
+	"github.com/aungmyozaw92/go-api-setup/pkg/health"
 	"github.com/gorilla/mux"
 )
 
+// Dependencies holds the handlers and middleware used to build the router
+type Dependencies struct {
+	AuthHandler    *handler.AuthHandler
+	UserHandler    *handler.UserHandler
+	AdminHandler   *handler.AdminHandler
+	WebhookHandler *handler.WebhookHandler
+	FileHandler    *handler.FileHandler
+	// NotificationHandler serves the notification inbox and preferences; nil
+	// disables the endpoints
+	NotificationHandler *handler.NotificationHandler
+	// BillingHandler serves subscriptions and the payment provider's
+	// webhook; nil when billing is disabled
+	BillingHandler *handler.BillingHandler
+	// HookHandler receives other services' webhooks; nil when no provider
+	// is configured
+	HookHandler *handler.HookHandler
+	// StorageHandler serves signed storage URLs; nil when the backend serves them itself
+	StorageHandler http.Handler
+	WSHandler      *handler.WebSocketHandler
+	GraphQLHandler http.Handler
+	// BatchMaxRequests is how many sub-requests /api/batch runs at most;
+	// zero disables the endpoint
+	BatchMaxRequests int
+	// TenantHeader is passed from batch requests to their sub-requests in
+	// multi-tenant mode
+	TenantHeader string
+	// GatewayHandler serves the proto-derived /api/v2 JSON API; nil when gRPC is disabled
+	GatewayHandler http.Handler
+	JWTSecret      string
+	IPFilter       *middleware.IPFilter
+	Maintenance    *middleware.Maintenance
+	// CORS restricts cross-origin requests; nil allows any origin
+	CORS *middleware.CORS
+	// Locale translates error messages into the client's Accept-Language;
+	// nil answers in English
+	Locale *middleware.Locale
+	// Tenants routes requests to per-tenant databases; nil serves every
+	// request from the shared database
+	Tenants *middleware.TenantResolver
+	// LegacyDeprecation marks the unversioned auth routes as deprecated when set
+	LegacyDeprecation *middleware.DeprecationPolicy
+	// Health holds the checks /readyz runs; nil reports ready with no checks
+	Health *health.Registry
+	// AccessLog logs every request; nil disables the access log
+	AccessLog *middleware.AccessLog
+	// ErrorRate alerts on spikes of 5xx responses; nil disables it
+	ErrorRate *middleware.ErrorRateAlert
+	// SlowRequests logs requests over a latency threshold; nil disables it
+	SlowRequests *middleware.SlowRequestLog
+	// RequestDeadline sets deadlines from clients' timeout headers; nil ignores them
+	RequestDeadline *middleware.RequestDeadline
+	// Audit records mutating requests; nil disables the audit log
+	Audit *middleware.AuditLog
+	// Metrics records HTTP request metrics; nil disables them
+	Metrics *middleware.HTTPMetrics
+	// MetricsHandler serves Prometheus metrics at MetricsPath; nil disables the endpoint
+	MetricsHandler http.Handler
+	MetricsPath    string
+	// DebugHandler serves pprof and expvar under /api/admin/debug/; nil
+	// disables the endpoints
+	DebugHandler http.Handler
+
+	// SeparateOps leaves the operational routes (admin, debug, metrics and
+	// readiness) off the router SetupRoutes builds; SetupOpsRoutes serves
+	// them on an internal listener instead
+	SeparateOps bool
+}
+
 // SetupRoutes configures and returns the main router with all routes
-func SetupRoutes(authHandler *handler.AuthHandler, userHandler *handler.UserHandler, jwtSecret string) *mux.Router {
+func SetupRoutes(deps Dependencies) *mux.Router {
 	// Create main router
 	router := mux.NewRouter()
 
+	// Record request metrics first so latency covers every other middleware
+	router.Use(deps.Metrics.Middleware)
+
+	// Tag each request with an ID that its log records carry
+	router.Use(middleware.RequestID)
+
+	// Answer in the client's language, including errors of later middleware
+	router.Use(deps.Locale.Middleware)
+
+	// Log every request, including ones answered by a recovered panic
+	router.Use(deps.AccessLog.Middleware)
+
+	// Alert on spikes of server errors, including recovered panics
+	router.Use(deps.ErrorRate.Middleware)
+
+	// Warn about slow requests with a breakdown of where the time went
+	router.Use(deps.SlowRequests.Middleware)
+
+	// Turn handler panics into 500 responses and report them
+	router.Use(middleware.Recovery)
+
 	// Apply CORS middleware to all routes
-	router.Use(middleware.CORSMiddleware)
+	router.Use(deps.CORS.Middleware)
+
+	// Let clients bypass server-side caches with Cache-Control: no-cache
+	router.Use(middleware.CacheControl)
+
+	// Cancel requests when the timeout their client asked for runs out
+	router.Use(deps.RequestDeadline.Middleware)
+
+	// Apply IP allowlist/denylist to all routes
+	router.Use(deps.IPFilter.Middleware)
+
+	// Apply maintenance mode to all non-exempt routes
+	router.Use(deps.Maintenance.Middleware)
+
+	// Route each request to its tenant's database in multi-tenant mode
+	router.Use(deps.Tenants.Middleware)
+
+	// Record mutating requests in the audit log, in the tenant's database
+	router.Use(deps.Audit.Middleware)
 
 	// Setup route groups
-	setupPublicRoutes(router, authHandler)
-	setupProtectedRoutes(router, userHandler, jwtSecret)
+	setupPublicRoutes(router, deps.AuthHandler, deps.LegacyDeprecation)
+	if !deps.SeparateOps {
+		setupAdminRoutes(router, deps.AdminHandler, deps.DebugHandler, deps.JWTSecret)
+	}
+	setupWebhookRoutes(router, deps.WebhookHandler, deps.JWTSecret)
+	setupGatewayRoutes(router, deps.GatewayHandler)
+	setupFileRoutes(router, deps.FileHandler, deps.StorageHandler, deps.JWTSecret)
+	setupNotificationRoutes(router, deps.NotificationHandler, deps.JWTSecret)
+	setupBillingRoutes(router, deps.BillingHandler, deps.JWTSecret)
+	setupHookRoutes(router, deps.HookHandler)
+	setupBatchRoutes(router, deps)
+	setupProtectedRoutes(router, deps.UserHandler, deps.JWTSecret)
+	setupRealtimeRoutes(router, deps.WSHandler)
+	setupGraphQLRoutes(router, deps.GraphQLHandler, deps.JWTSecret)
 	setupHealthRoutes(router)
+	router.HandleFunc("/", rootHandler).Methods("GET", "OPTIONS")
+	if !deps.SeparateOps {
+		setupOpsEndpoints(router, deps)
+	}
 
 	// Setup versioned API routes (for future expansion)
-	SetupV1Routes(router, authHandler, userHandler, jwtSecret)
+	SetupV1Routes(router, deps.AuthHandler, deps.UserHandler, deps.JWTSecret)
 
 	return router
 }
 
+// SetupOpsRoutes returns the router of the internal ops listener: the admin
+// API, debug endpoints, metrics, and health and readiness checks. Admin
+// routes still require an admin token.
+func SetupOpsRoutes(deps Dependencies) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(deps.AccessLog.Middleware)
+	router.Use(middleware.Recovery)
+	router.Use(deps.Tenants.Middleware)
+	router.Use(deps.Audit.Middleware)
+
+	setupAdminRoutes(router, deps.AdminHandler, deps.DebugHandler, deps.JWTSecret)
+	setupHealthRoutes(router)
+	setupOpsEndpoints(router, deps)
+	return router
+}
+
+// setupOpsEndpoints configures the readiness check and metrics endpoints
+func setupOpsEndpoints(router *mux.Router, deps Dependencies) {
+	checks := deps.Health
+	if checks == nil {
+		checks = health.NewRegistry(0)
+	}
+	router.Handle("/readyz", checks.Handler()).Methods("GET", "OPTIONS")
+	if deps.MetricsHandler != nil {
+		router.Handle(deps.MetricsPath, deps.MetricsHandler).Methods("GET")
+	}
+}
+
 // setupPublicRoutes configures routes that don't require authentication
-func setupPublicRoutes(router *mux.Router, authHandler *handler.AuthHandler) {
+func setupPublicRoutes(router *mux.Router, authHandler *handler.AuthHandler, deprecation *middleware.DeprecationPolicy) {
 	// Authentication routes (current/default version)
 	auth := router.PathPrefix("/api/auth").Subrouter()
+	if deprecation != nil {
+		auth.Use(middleware.Deprecated(*deprecation))
+	}
 	auth.HandleFunc("/register", authHandler.Register).Methods("POST", "OPTIONS")
 	auth.HandleFunc("/login", authHandler.Login).Methods("POST", "OPTIONS")
+	auth.HandleFunc("/login/two-factor", authHandler.CompleteTwoFactorLogin).Methods("POST", "OPTIONS")
+	auth.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods("GET", "POST", "OPTIONS")
+}
+
+// setupAdminRoutes configures routes restricted to admin users
+func setupAdminRoutes(router *mux.Router, adminHandler *handler.AdminHandler, debugHandler http.Handler, jwtSecret string) {
+	admin := router.PathPrefix("/api/admin").Subrouter()
+	admin.Use(middleware.AuthMiddleware(jwtSecret))
+	admin.Use(middleware.RequireRole(domain.RoleAdmin))
+
+	// Maintenance mode routes
+	admin.HandleFunc("/maintenance", adminHandler.GetMaintenance).Methods("GET", "OPTIONS")
+	admin.HandleFunc("/maintenance", adminHandler.SetMaintenance).Methods("PUT", "OPTIONS")
+
+	// Cache effectiveness
+	admin.HandleFunc("/cache/stats", adminHandler.GetCacheStats).Methods("GET", "OPTIONS")
+
+	// Background workers
+	admin.HandleFunc("/workers", adminHandler.GetWorkers).Methods("GET", "OPTIONS")
+	admin.HandleFunc("/workers/{name}/run", adminHandler.RunWorker).Methods("POST", "OPTIONS")
+
+	// Effective configuration, secrets masked
+	admin.HandleFunc("/config", adminHandler.GetConfig).Methods("GET", "OPTIONS")
+
+	// Directory sync
+	admin.HandleFunc("/directory/sync", adminHandler.GetDirectorySync).Methods("GET", "OPTIONS")
+	admin.HandleFunc("/directory/sync", adminHandler.RunDirectorySync).Methods("POST", "OPTIONS")
+
+	// Runtime metrics published through expvar (DB pool, cache, deprecated routes)
+	admin.Handle("/metrics", expvar.Handler()).Methods("GET", "OPTIONS")
+
+	// pprof profiles and expvar, when served on the API port
+	if debugHandler != nil {
+		admin.PathPrefix("/debug/").Handler(http.StripPrefix("/api/admin", debugHandler)).Methods("GET", "OPTIONS")
+	}
+}
+
+// setupWebhookRoutes configures webhook subscription routes (admin only)
+func setupWebhookRoutes(router *mux.Router, webhookHandler *handler.WebhookHandler, jwtSecret string) {
+	webhooks := router.PathPrefix("/api/webhooks").Subrouter()
+	webhooks.Use(middleware.AuthMiddleware(jwtSecret))
+	webhooks.Use(middleware.RequireRole(domain.RoleAdmin))
+
+	// Webhook collection routes
+	webhooks.HandleFunc("", webhookHandler.CreateWebhook).Methods("POST", "OPTIONS")
+	webhooks.HandleFunc("", webhookHandler.GetAllWebhooks).Methods("GET", "OPTIONS")
+
+	// Individual webhook routes
+	webhooks.HandleFunc("/{id:[0-9]+}", webhookHandler.GetWebhook).Methods("GET", "OPTIONS")
+	webhooks.HandleFunc("/{id:[0-9]+}", webhookHandler.UpdateWebhook).Methods("PUT", "OPTIONS")
+	webhooks.HandleFunc("/{id:[0-9]+}", webhookHandler.DeleteWebhook).Methods("DELETE", "OPTIONS")
+	webhooks.HandleFunc("/{id:[0-9]+}/deliveries", webhookHandler.GetWebhookDeliveries).Methods("GET", "OPTIONS")
+}
+
+// setupNotificationRoutes configures the current user's notification inbox,
+// preferences and push subscriptions
+func setupNotificationRoutes(router *mux.Router, notificationHandler *handler.NotificationHandler, jwtSecret string) {
+	if notificationHandler == nil {
+		return
+	}
+	prefs := router.PathPrefix("/api/profile/notification-preferences").Subrouter()
+	prefs.Use(middleware.AuthMiddleware(jwtSecret))
+	prefs.HandleFunc("", notificationHandler.GetPreferences).Methods("GET", "OPTIONS")
+	prefs.HandleFunc("", notificationHandler.UpdatePreferences).Methods("PUT", "OPTIONS")
+
+	inbox := router.PathPrefix("/api/notifications").Subrouter()
+	inbox.Use(middleware.AuthMiddleware(jwtSecret))
+	inbox.HandleFunc("", notificationHandler.GetNotifications).Methods("GET", "OPTIONS")
+	inbox.HandleFunc("/unread-count", notificationHandler.GetUnreadCount).Methods("GET", "OPTIONS")
+	inbox.HandleFunc("/{id:[0-9]+}/read", notificationHandler.MarkRead).Methods("POST", "OPTIONS")
+
+	// Browser push subscriptions; the VAPID public key is public, as
+	// browsers need it before the user has anything to subscribe with
+	router.HandleFunc("/api/push/public-key", notificationHandler.GetPushPublicKey).Methods("GET", "OPTIONS")
+	push := router.PathPrefix("/api/profile/push-subscriptions").Subrouter()
+	push.Use(middleware.AuthMiddleware(jwtSecret))
+	push.HandleFunc("", notificationHandler.GetPushSubscriptions).Methods("GET", "OPTIONS")
+	push.HandleFunc("", notificationHandler.CreatePushSubscription).Methods("POST", "OPTIONS")
+	push.HandleFunc("/{id:[0-9]+}", notificationHandler.DeletePushSubscription).Methods("DELETE", "OPTIONS")
+}
+
+// setupBillingRoutes configures the current user's subscription routes and
+// the payment provider's webhook
+func setupBillingRoutes(router *mux.Router, billingHandler *handler.BillingHandler, jwtSecret string) {
+	if billingHandler == nil {
+		return
+	}
+	// The webhook authenticates requests by their signature; it is
+	// registered before the subrouter so the auth middleware doesn't apply
+	router.HandleFunc("/api/billing/webhook", billingHandler.Webhook).Methods("POST")
+
+	billing := router.PathPrefix("/api/billing").Subrouter()
+	billing.Use(middleware.AuthMiddleware(jwtSecret))
+	billing.HandleFunc("", billingHandler.GetBilling).Methods("GET", "OPTIONS")
+	billing.HandleFunc("/plans", billingHandler.GetPlans).Methods("GET", "OPTIONS")
+	billing.HandleFunc("/checkout", billingHandler.Checkout).Methods("POST", "OPTIONS")
+	billing.HandleFunc("/portal", billingHandler.Portal).Methods("POST", "OPTIONS")
+	billing.HandleFunc("/cancel", billingHandler.CancelSubscription).Methods("POST", "OPTIONS")
+}
+
+// setupHookRoutes configures the receiver of other services' webhooks. The
+// requests are authenticated by their provider's signature, not a token.
+func setupHookRoutes(router *mux.Router, hookHandler *handler.HookHandler) {
+	if hookHandler == nil {
+		return
+	}
+	router.HandleFunc("/hooks/{provider:[a-z0-9_-]+}", hookHandler.Receive).Methods("POST")
+}
+
+// setupBatchRoutes configures the batch endpoint, which runs its
+// sub-requests through router itself
+func setupBatchRoutes(router *mux.Router, deps Dependencies) {
+	if deps.BatchMaxRequests <= 0 {
+		return
+	}
+	var headers []string
+	if deps.Tenants != nil && deps.TenantHeader != "" {
+		headers = append(headers, deps.TenantHeader)
+	}
+	batchHandler := handler.NewBatchHandler(router, deps.BatchMaxRequests, headers...)
+	router.Handle(handler.BatchPath, middleware.AuthMiddleware(deps.JWTSecret)(http.HandlerFunc(batchHandler.Batch))).Methods("POST", "OPTIONS")
 }
 
 // setupProtectedRoutes configures routes that require JWT authentication
@@ -54,6 +334,11 @@ func setupProfileRoutes(router *mux.Router, userHandler *handler.UserHandler) {
 	router.HandleFunc("/profile", userHandler.GetProfile).Methods("GET", "OPTIONS")
 	router.HandleFunc("/profile", userHandler.UpdateUser).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/profile", userHandler.DeleteUser).Methods("DELETE", "OPTIONS")
+
+	// Phone verification and two-factor login by texted codes
+	router.HandleFunc("/profile/phone/verification", userHandler.SendPhoneVerification).Methods("POST", "OPTIONS")
+	router.HandleFunc("/profile/phone/verify", userHandler.VerifyPhone).Methods("POST", "OPTIONS")
+	router.HandleFunc("/profile/two-factor", userHandler.SetTwoFactor).Methods("PUT", "OPTIONS")
 }
 
 // setupUserManagementRoutes configures routes for user CRUD operations
@@ -61,49 +346,93 @@ func setupUserManagementRoutes(router *mux.Router, userHandler *handler.UserHand
 	// User collection routes
 	router.HandleFunc("/users", userHandler.CreateUser).Methods("POST", "OPTIONS")
 	router.HandleFunc("/users", userHandler.GetAllUsers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/users/bulk", userHandler.CreateUsers).Methods("POST", "OPTIONS")
+	router.HandleFunc("/users/search", userHandler.SearchUsers).Methods("GET", "OPTIONS")
+
+	// Individual user routes; {id} is numeric or a UUIDv7/ULID public ID
+	// depending on USER_ID_FORMAT. Literal routes above take precedence.
+	router.HandleFunc("/users/{id:[0-9A-Za-z-]+}", userHandler.GetUser).Methods("GET", "OPTIONS")
+	router.HandleFunc("/users/{id:[0-9A-Za-z-]+}", userHandler.UpdateUserByID).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/users/{id:[0-9A-Za-z-]+}", userHandler.DeleteUserByID).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/users/{id:[0-9A-Za-z-]+}/restore", userHandler.RestoreUserByID).Methods("POST", "OPTIONS")
+}
+
+// setupRealtimeRoutes configures WebSocket routes. The handshake authenticates
+// the token itself since browsers cannot send an Authorization header.
+func setupRealtimeRoutes(router *mux.Router, wsHandler *handler.WebSocketHandler) {
+	router.HandleFunc("/ws", wsHandler.ServeWS).Methods("GET")
+}
+
+// setupGraphQLRoutes configures the GraphQL endpoint alongside REST. Anonymous
+// requests are allowed so register/login work; resolvers enforce authentication.
+func setupGraphQLRoutes(router *mux.Router, graphqlHandler http.Handler, jwtSecret string) {
+	gql := router.PathPrefix("/graphql").Subrouter()
+	gql.Use(middleware.OptionalAuthMiddleware(jwtSecret))
+	gql.Handle("", graphqlHandler).Methods("GET", "POST", "OPTIONS")
+	gql.Handle("/playground", graph.NewPlaygroundHandler("/graphql")).Methods("GET")
+}
 
-	// Individual user routes
-	router.HandleFunc("/users/{id:[0-9]+}", userHandler.GetUser).Methods("GET", "OPTIONS")
-	router.HandleFunc("/users/{id:[0-9]+}", userHandler.UpdateUserByID).Methods("PUT", "OPTIONS")
-	router.HandleFunc("/users/{id:[0-9]+}", userHandler.DeleteUserByID).Methods("DELETE", "OPTIONS")
+// setupFileRoutes configures file upload/download routes. Ownership is
+// enforced by the file usecase. Signed storage URLs carry their own
+// authorization, so the storage handler is mounted without auth.
+func setupFileRoutes(router *mux.Router, fileHandler *handler.FileHandler, storageHandler http.Handler, jwtSecret string) {
+	files := router.PathPrefix("/api/files").Subrouter()
+	files.Use(middleware.AuthMiddleware(jwtSecret))
+	files.HandleFunc("", fileHandler.UploadFile).Methods("POST", "OPTIONS")
+	files.HandleFunc("", fileHandler.GetFiles).Methods("GET", "OPTIONS")
+	files.HandleFunc("/presign", fileHandler.PresignFile).Methods("POST", "OPTIONS")
+	files.HandleFunc("/{id:[0-9]+}", fileHandler.GetFile).Methods("GET", "OPTIONS")
+	files.HandleFunc("/{id:[0-9]+}/download", fileHandler.DownloadFile).Methods("GET", "OPTIONS")
+	files.HandleFunc("/{id:[0-9]+}", fileHandler.DeleteFile).Methods("DELETE", "OPTIONS")
+
+	if storageHandler != nil {
+		router.PathPrefix("/storage/").Handler(http.StripPrefix("/storage", storageHandler)).Methods("GET", "PUT")
+	}
 }
 
-// setupHealthRoutes configures health check and utility routes
+// setupGatewayRoutes mounts the grpc-gateway handler. Authentication is
+// enforced by the gRPC interceptors, so no auth middleware is applied here.
+func setupGatewayRoutes(router *mux.Router, gatewayHandler http.Handler) {
+	if gatewayHandler == nil {
+		return
+	}
+	router.PathPrefix("/api/v2/").Handler(gatewayHandler)
+}
+
+// setupHealthRoutes configures the liveness check, which reveals nothing
+// about dependencies and stays on the public router for load balancers
 func setupHealthRoutes(router *mux.Router) {
 	router.HandleFunc("/health", healthCheckHandler).Methods("GET", "OPTIONS")
-	router.HandleFunc("/", rootHandler).Methods("GET", "OPTIONS")
 }
 
 // healthCheckHandler handles health check requests
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"status":  "healthy",
 		"service": "go-api-setup",
 		"version": "1.0.0",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	}, http.StatusOK)
 }
 
 // rootHandler handles requests to the root path
 func rootHandler(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"message": "Welcome to Go REST API",
 		"version": "1.0.0",
 		"endpoints": map[string]interface{}{
 			"health":        "/health",
+			"readiness":     "/readyz",
 			"auth":          "/api/auth/*",
 			"profile":       "/api/profile",
 			"users":         "/api/users",
+			"files":         "/api/files",
+			"batch":         "/api/batch",
+			"websocket":     "/ws",
+			"graphql":       "/graphql",
 			"versioned_api": "/api/v1/*",
+			"gateway_api":   "/api/v2/*",
 			"api_version":   "/api/v1/version",
 			"documentation": "https://github.com/aungmyozaw92/go-api-setup",
 		},
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-} 
\ No newline at end of file
+	}, http.StatusOK)
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"slices"
+
+	"github.com/gorilla/mux"
+)
+
+// Route is an entry of the route table: a path template and the methods it
+// serves. Path prefixes, such as the gRPC gateway's, end in "/".
+type Route struct {
+	Methods []string
+	Path    string
+}
+
+// Table lists the routes registered on router in registration order.
+// Subrouter prefixes without handlers of their own are left out, and so is
+// OPTIONS, which every route answers for CORS preflight.
+func Table(router *mux.Router) []Route {
+	var table []Route
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"ANY"}
+		}
+		methods = slices.DeleteFunc(slices.Clone(methods), func(m string) bool { return m == "OPTIONS" })
+		table = append(table, Route{Methods: methods, Path: path})
+		return nil
+	})
+	return table
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request) {}
+	router := mux.NewRouter()
+	router.HandleFunc("/health", noop).Methods("GET", "OPTIONS")
+	users := router.PathPrefix("/api/users").Subrouter()
+	users.HandleFunc("", noop).Methods("GET", "POST", "OPTIONS")
+	users.HandleFunc("/{id}", noop).Methods("DELETE")
+	router.PathPrefix("/api/v2/").HandlerFunc(noop)
+
+	assert.Equal(t, []Route{
+		{Methods: []string{"GET"}, Path: "/health"},
+		{Methods: []string{"GET", "POST"}, Path: "/api/users"},
+		{Methods: []string{"DELETE"}, Path: "/api/users/{id}"},
+		{Methods: []string{"ANY"}, Path: "/api/v2/"},
+	}, Table(router))
+}
@@ -1,10 +1,10 @@
 package routes
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/handler"
+	"github.com/aungmyozaw92/go-api-setup/internal/response"
 	"github.com/gorilla/mux"
 )
 
@@ -27,7 +27,7 @@ func SetupV1Routes(router *mux.Router, authHandler *handler.AuthHandler, userHan
 
 // versionHandler returns API version information
 func versionHandler(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"api_version": "1.0.0",
 		"service":     "go-api-setup",
 		"status":      "active",
@@ -37,11 +37,7 @@ func versionHandler(w http.ResponseWriter, r *http.Request) {
 			"crud_operations",
 		},
 		"documentation": "https://github.com/aungmyozaw92/go-api-setup",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	}, http.StatusOK)
 }
 
 // Future: setupV1ProtectedRoutes would configure protected routes for V1
@@ -0,0 +1,123 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// DefaultFakeBatchSize is how many fake users Fake inserts per statement
+const DefaultFakeBatchSize = 500
+
+// fakeDomains are reserved for examples, so no fake address can reach
+// anyone
+var fakeDomains = []string{"example.com", "example.org", "example.net"}
+
+// FakeOptions configures the users Fake generates
+type FakeOptions struct {
+	// Password is every fake user's password
+	Password string
+	// Seed makes the generated users the same on every run, so seeding
+	// again skips them; zero generates different users each time
+	Seed uint64
+	// BatchSize is how many users are inserted at once (default
+	// DefaultFakeBatchSize)
+	BatchSize int
+	// Progress, when set, is called after each batch with the users
+	// generated so far
+	Progress func(done int)
+}
+
+// Fake creates count users with realistic names, spread over the past year,
+// for demoing pagination and search and for load tests. Every user gets
+// the user role and the same password, which is hashed once. Users whose
+// email is already registered are skipped.
+func Fake(ctx context.Context, users repository.UserRepository, count int, opts FakeOptions) (Result, error) {
+	var result Result
+	if count <= 0 {
+		return result, errors.New("count must be greater than zero")
+	}
+	if len(opts.Password) < 6 {
+		return result, errors.New("password must be at least 6 characters")
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultFakeBatchSize
+	}
+
+	hashedPassword, err := utils.HashPassword(opts.Password)
+	if err != nil {
+		return result, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	faker := gofakeit.New(opts.Seed)
+	end := time.Now().UTC()
+	start := end.AddDate(-1, 0, 0)
+	for n := 0; n < count; {
+		batch := make([]*domain.User, 0, min(batchSize, count-n))
+		emails := make([]string, 0, cap(batch))
+		for ; n < count && len(batch) < batchSize; n++ {
+			first, last := faker.FirstName(), faker.LastName()
+			createdAt := faker.DateRange(start, end)
+			user := &domain.User{
+				Name:      first + " " + last,
+				Email:     fakeEmail(first, last, n+1, fakeDomains[faker.Number(0, len(fakeDomains)-1)]),
+				Password:  hashedPassword,
+				Role:      domain.RoleUser,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			}
+			batch = append(batch, user)
+			emails = append(emails, user.Email)
+		}
+
+		existing, err := users.ExistingEmails(ctx, emails)
+		if err != nil {
+			return result, fmt.Errorf("failed to check existing users: %w", err)
+		}
+		if len(existing) > 0 {
+			taken := make(map[string]bool, len(existing))
+			for _, email := range existing {
+				taken[strings.ToLower(email)] = true
+			}
+			fresh := batch[:0]
+			for _, user := range batch {
+				if !taken[user.Email] {
+					fresh = append(fresh, user)
+				}
+			}
+			result.Skipped += len(batch) - len(fresh)
+			batch = fresh
+		}
+
+		if err := users.CreateBatch(ctx, batch); err != nil {
+			return result, fmt.Errorf("failed to create fake users: %w", err)
+		}
+		result.Created += len(batch)
+		if opts.Progress != nil {
+			opts.Progress(n)
+		}
+	}
+	return result, nil
+}
+
+// fakeEmail returns the address of the nth fake user, unique by n
+func fakeEmail(first, last string, n int, domain string) string {
+	local := func(name string) string {
+		return strings.Map(func(r rune) rune {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return -1
+			}
+			return unicode.ToLower(r)
+		}, name)
+	}
+	return fmt.Sprintf("%s.%s.%d@%s", local(first), local(last), n, domain)
+}
@@ -0,0 +1,126 @@
+// Package seed loads sample data into a database, so development and demo
+// environments start with accounts to log in with. Seeding is idempotent:
+// records that already exist are left alone.
+package seed
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed seed.yaml
+var defaultData []byte
+
+// Data is a set of records to seed
+type Data struct {
+	Users []User `yaml:"users"`
+}
+
+// User is a seeded account; Role defaults to domain.RoleUser
+type User struct {
+	Name     string `yaml:"name"`
+	Email    string `yaml:"email"`
+	Password string `yaml:"password"`
+	Phone    string `yaml:"phone"`
+	Role     string `yaml:"role"`
+}
+
+// Result counts the records Apply created and skipped
+type Result struct {
+	Created int
+	Skipped int
+}
+
+// Default returns the built-in sample data
+func Default() *Data {
+	data, err := Parse(bytes.NewReader(defaultData))
+	if err != nil {
+		panic("seed: invalid built-in seed data: " + err.Error())
+	}
+	return data
+}
+
+// Parse reads seed data in YAML (or JSON) and validates it
+func Parse(r io.Reader) (*Data, error) {
+	var data Data
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid seed data: %w", err)
+	}
+	for i, user := range data.Users {
+		if err := user.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid seed user %d: %w", i+1, err)
+		}
+	}
+	return &data, nil
+}
+
+// Validate checks that the user has the fields an account needs
+func (u *User) Validate() error {
+	switch {
+	case u.Name == "" || u.Email == "" || u.Password == "":
+		return errors.New("name, email, and password are required")
+	case len(u.Password) < 6:
+		return errors.New("password must be at least 6 characters")
+	case u.Role != "" && !slices.Contains([]string{domain.RoleUser, domain.RoleAdmin}, u.Role):
+		return fmt.Errorf("unknown role %q", u.Role)
+	}
+	return nil
+}
+
+// Apply creates the users of data whose email is not registered yet
+func Apply(ctx context.Context, users repository.UserRepository, data *Data) (Result, error) {
+	var result Result
+	for _, seed := range data.Users {
+		existing, err := users.GetByEmail(ctx, seed.Email)
+		if err != nil {
+			return result, fmt.Errorf("failed to check existing user %s: %w", seed.Email, err)
+		}
+		if existing != nil {
+			result.Skipped++
+			continue
+		}
+
+		hashedPassword, err := utils.HashPassword(seed.Password)
+		if err != nil {
+			return result, fmt.Errorf("failed to hash password: %w", err)
+		}
+		role := seed.Role
+		if role == "" {
+			role = domain.RoleUser
+		}
+		user := &domain.User{
+			Name:     seed.Name,
+			Email:    seed.Email,
+			Phone:    seed.Phone,
+			Password: hashedPassword,
+			Role:     role,
+		}
+		if err := users.Create(ctx, user); err != nil {
+			return result, fmt.Errorf("failed to create user %s: %w", seed.Email, err)
+		}
+		result.Created++
+		if role == domain.RoleAdmin {
+			alert.Send(ctx, alert.Alert{
+				Key:    "admin_created:" + user.Email,
+				Level:  alert.LevelWarning,
+				Title:  "Admin user created",
+				Text:   fmt.Sprintf("%s was created with the admin role", user.Email),
+				Fields: []alert.Field{{Name: "Name", Value: user.Name}, {Name: "Email", Value: user.Email}},
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package seed
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	data := Default()
+	require.NotEmpty(t, data.Users)
+	assert.Equal(t, domain.RoleAdmin, data.Users[0].Role)
+}
+
+func TestParse_RejectsInvalidData(t *testing.T) {
+	for name, input := range map[string]string{
+		"unknown field":  "users:\n  - name: A\n    email: a@example.com\n    password: secret123\n    admin: true\n",
+		"short password": "users:\n  - name: A\n    email: a@example.com\n    password: abc\n",
+		"unknown role":   "users:\n  - name: A\n    email: a@example.com\n    password: secret123\n    role: owner\n",
+		"missing email":  `{"users": [{"name": "A", "password": "secret123"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// alertRecorder records the alerts it is sent
+type alertRecorder struct {
+	alerts []alert.Alert
+}
+
+func (r *alertRecorder) Notify(_ context.Context, a alert.Alert) { r.alerts = append(r.alerts, a) }
+func (r *alertRecorder) Flush(time.Duration) bool                { return true }
+
+func TestApply(t *testing.T) {
+	recorder := &alertRecorder{}
+	alert.SetDefault(recorder)
+	defer alert.SetDefault(nil)
+
+	ctx := context.Background()
+	users := memory.NewRepositories().Users
+	data, err := Parse(strings.NewReader(`{"users": [
+		{"name": "Admin", "email": "admin@example.com", "password": "secret123", "role": "admin"},
+		{"name": "Ada", "email": "ada@example.com", "password": "secret123"}
+	]}`))
+	require.NoError(t, err)
+
+	result, err := Apply(ctx, users, data)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 2}, result)
+
+	admin, err := users.GetByEmail(ctx, "admin@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, admin)
+	assert.Equal(t, domain.RoleAdmin, admin.Role)
+	assert.NoError(t, utils.CheckPassword("secret123", admin.Password))
+
+	ada, err := users.GetByEmail(ctx, "ada@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, ada)
+	assert.Equal(t, domain.RoleUser, ada.Role)
+
+	require.Len(t, recorder.alerts, 1, "creating an admin alerts the operators")
+	assert.Equal(t, "admin@example.com was created with the admin role", recorder.alerts[0].Text)
+
+	result, err = Apply(ctx, users, data)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Skipped: 2}, result, "seeding again leaves existing users alone")
+	assert.Len(t, recorder.alerts, 1)
+}
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewRepositories().Users
+	var progress []int
+	opts := FakeOptions{Password: "secret123", Seed: 7, BatchSize: 10, Progress: func(done int) {
+		progress = append(progress, done)
+	}}
+
+	result, err := Fake(ctx, users, 25, opts)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 25}, result)
+	assert.Equal(t, []int{10, 20, 25}, progress, "users are inserted in batches")
+
+	all, err := users.GetAll(ctx, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 25)
+	emails := make(map[string]bool)
+	for _, user := range all {
+		assert.Equal(t, domain.RoleUser, user.Role)
+		assert.Regexp(t, `^[a-z0-9]+\.[a-z0-9]+\.\d+@example\.(com|org|net)$`, user.Email)
+		emails[user.Email] = true
+	}
+	assert.Len(t, emails, 25, "emails are unique")
+	assert.NoError(t, utils.CheckPassword("secret123", all[0].Password))
+
+	result, err = Fake(ctx, users, 30, opts)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 5, Skipped: 25}, result, "the same seed generates the same users")
+
+	_, err = Fake(ctx, users, 0, opts)
+	assert.Error(t, err)
+	_, err = Fake(ctx, users, 1, FakeOptions{Password: "abc"})
+	assert.Error(t, err)
+}
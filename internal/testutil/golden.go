@@ -0,0 +1,92 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateGoldenEnv, set to a non-empty value, makes AssertGolden rewrite the
+// golden files rather than compare against them:
+//
+//	UPDATE_GOLDEN=1 go test ./internal/contract/...
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// Values that change from run to run, replaced by placeholders so golden
+// files hold only what clients may rely on
+var (
+	goldenTimestamp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`)
+	goldenUUID      = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	goldenJWT       = regexp.MustCompile(`^eyJ[\w-]*\.[\w-]+\.[\w-]+$`)
+	goldenSecret    = regexp.MustCompile(`^whsec_[0-9a-f]+$`)
+)
+
+// golden is what a golden file records of a response
+type golden struct {
+	Status int `json:"status"`
+	Body   any `json:"body,omitempty"`
+}
+
+// AssertGolden compares the response's status and JSON body against the
+// golden file at path, failing t when they differ. Timestamps, UUIDs,
+// tokens and secrets are compared by kind rather than value. With UpdateGoldenEnv
+// set the golden file is written instead, for reviewing in the diff.
+func (r *Response) AssertGolden(t testing.TB, path string) {
+	t.Helper()
+	got := golden{Status: r.StatusCode}
+	if len(r.Body) > 0 {
+		var body any
+		require.NoError(t, json.Unmarshal(r.Body, &body), "response body: %s", r.Body)
+		got.Body = scrubGolden(body)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	require.NoError(t, enc.Encode(got))
+	data := buf.Bytes()
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file; run the test with %s=1 to create it", UpdateGoldenEnv)
+	if !bytes.Equal(want, data) {
+		assert.JSONEq(t, string(want), string(data),
+			"response doesn't match %s; if the change is intended, run the test with %s=1 and commit the diff", path, UpdateGoldenEnv)
+	}
+}
+
+// scrubGolden replaces the run-specific values in a decoded JSON value
+func scrubGolden(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = scrubGolden(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = scrubGolden(value)
+		}
+	case string:
+		switch {
+		case goldenTimestamp.MatchString(v):
+			return "<timestamp>"
+		case goldenUUID.MatchString(v):
+			return "<uuid>"
+		case goldenJWT.MatchString(v):
+			return "<token>"
+		case goldenSecret.MatchString(v):
+			return "<secret>"
+		}
+	}
+	return v
+}
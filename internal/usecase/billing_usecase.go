@@ -0,0 +1,393 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+)
+
+// BillingCustomerJob creates the payment provider customer of a user who
+// signed up
+const BillingCustomerJob = "billing.create_customer"
+
+var (
+	// ErrUnknownPlan is returned for checkouts of plans that aren't configured
+	ErrUnknownPlan = domain.NewError(domain.ErrInvalid, "unknown plan")
+	// ErrAlreadySubscribed is returned for checkouts by users who have a
+	// subscription; they change plans in the billing portal
+	ErrAlreadySubscribed = domain.NewError(domain.ErrConflict, "already subscribed; change plans in the billing portal")
+	// ErrNoSubscription is returned for canceling without a subscription
+	ErrNoSubscription = domain.NewError(domain.ErrNotFound, "no active subscription")
+)
+
+// BillingSettings configures the billing usecase
+type BillingSettings struct {
+	// Plans maps the names of the plans users can subscribe to onto the
+	// provider's price IDs
+	Plans map[string]string
+	// WebhookSecret verifies the signatures of the provider's webhook events
+	WebhookSecret string
+	// SuccessURL and CancelURL are where checkouts send the user after
+	// paying or going back
+	SuccessURL string
+	CancelURL  string
+	// PortalReturnURL is where the billing portal's back link goes
+	PortalReturnURL string
+}
+
+// BillingUsecase defines the interface for subscription billing business
+// logic. The user's plan is what their subscription grants, kept in sync by
+// the provider's webhook events, or domain.PlanFree.
+type BillingUsecase interface {
+	// Plans lists the plans users can subscribe to
+	Plans() []*domain.PlanResponse
+	// GetBilling returns the user's plan and subscription
+	GetBilling(ctx context.Context, userID uint) (*domain.BillingResponse, error)
+	// Checkout creates a checkout page subscribing the user to a plan
+	Checkout(ctx context.Context, userID uint, req *domain.CheckoutRequest) (*domain.BillingSessionResponse, error)
+	// Portal creates a billing portal page where the user manages their
+	// subscription and payment methods
+	Portal(ctx context.Context, userID uint) (*domain.BillingSessionResponse, error)
+	// CancelSubscription cancels the user's subscription at the end of the
+	// period paid for
+	CancelSubscription(ctx context.Context, userID uint) (*domain.BillingResponse, error)
+	// HandleWebhook verifies and applies a webhook event of the provider;
+	// payload is the request body exactly as received
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	// UserPlan returns the user's current plan
+	UserPlan(ctx context.Context, userID uint) (string, error)
+	// RegisterJobs registers the customer creation job handler with pool
+	RegisterJobs(pool *jobs.Pool)
+	// Subscribe creates the customers of users who sign up on bus
+	Subscribe(bus *events.Bus)
+}
+
+// billingUsecase implements BillingUsecase interface
+type billingUsecase struct {
+	provider billing.Provider
+	userRepo repository.UserRepository
+	accounts repository.BillingAccountRepository
+	queue    jobs.Enqueuer
+	settings BillingSettings
+	// planByPrice maps price IDs back onto plan names
+	planByPrice map[string]string
+}
+
+// NewBillingUsecase creates a new billing usecase managing subscriptions
+// with provider
+func NewBillingUsecase(provider billing.Provider, userRepo repository.UserRepository, accounts repository.BillingAccountRepository, queue jobs.Enqueuer, settings BillingSettings) BillingUsecase {
+	planByPrice := make(map[string]string, len(settings.Plans))
+	for plan, price := range settings.Plans {
+		planByPrice[price] = plan
+	}
+	return &billingUsecase{
+		provider:    provider,
+		userRepo:    userRepo,
+		accounts:    accounts,
+		queue:       queue,
+		settings:    settings,
+		planByPrice: planByPrice,
+	}
+}
+
+// billingCustomer is the payload of a customer creation job
+type billingCustomer struct {
+	UserID uint `json:"user_id"`
+}
+
+// RegisterJobs registers the customer creation job handler
+func (u *billingUsecase) RegisterJobs(pool *jobs.Pool) {
+	pool.Register(BillingCustomerJob, u.createCustomer)
+}
+
+// Subscribe queues the creation of a customer for every user who signs up,
+// so the provider's API isn't called while they wait
+func (u *billingUsecase) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "billing_customer", func(ctx context.Context, e events.UserCreated) error {
+		if !e.Registered {
+			return nil
+		}
+		if _, err := u.queue.Enqueue(ctx, BillingCustomerJob, billingCustomer{UserID: e.User.ID}); err != nil {
+			return fmt.Errorf("failed to queue billing customer: %w", err)
+		}
+		return nil
+	})
+}
+
+// createCustomer creates the customer of a customer creation job's user
+func (u *billingUsecase) createCustomer(ctx context.Context, job *domain.Job) error {
+	var payload billingCustomer
+	if err := jobs.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+	user, err := u.userRepo.GetByID(ctx, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	_, err = u.account(ctx, user)
+	var apiErr *billing.APIError
+	if errors.As(err, &apiErr) && !apiErr.Temporary() {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// account returns the user's billing account, creating their customer at
+// the provider first when they have none yet
+func (u *billingUsecase) account(ctx context.Context, user *domain.User) (*domain.BillingAccount, error) {
+	account, err := u.accounts.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing account: %w", err)
+	}
+	if account != nil {
+		return account, nil
+	}
+
+	// The key is the same for the job and a checkout racing it, so the
+	// provider returns the customer the first of them created
+	customerID, err := u.provider.CreateCustomer(ctx, &billing.CustomerParams{
+		Email:          user.Email,
+		Name:           user.Name,
+		Metadata:       map[string]string{"user_id": strconv.FormatUint(uint64(user.ID), 10)},
+		IdempotencyKey: fmt.Sprintf("customer-%d-%d", user.ID, user.CreatedAt.UnixNano()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	account = &domain.BillingAccount{UserID: user.ID, CustomerID: customerID}
+	if err := u.accounts.Create(ctx, account); err != nil {
+		if existing, getErr := u.accounts.GetByUserID(ctx, user.ID); getErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("failed to create billing account: %w", err)
+	}
+	logger.FromContext(ctx).Info("Created billing customer", "user_id", user.ID, "customer_id", customerID)
+	return account, nil
+}
+
+// Plans lists the configured plans by name
+func (u *billingUsecase) Plans() []*domain.PlanResponse {
+	plans := make([]*domain.PlanResponse, 0, len(u.settings.Plans))
+	for name := range u.settings.Plans {
+		plans = append(plans, &domain.PlanResponse{Name: name})
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].Name < plans[j].Name })
+	return plans
+}
+
+// GetBilling returns the user's plan and, once they subscribed, their
+// subscription
+func (u *billingUsecase) GetBilling(ctx context.Context, userID uint) (*domain.BillingResponse, error) {
+	user, err := u.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := u.accounts.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get billing account: %w", err))
+	}
+	return billingResponse(user, account), nil
+}
+
+// Checkout creates a checkout page for the plan's price
+func (u *billingUsecase) Checkout(ctx context.Context, userID uint, req *domain.CheckoutRequest) (*domain.BillingSessionResponse, error) {
+	price, ok := u.settings.Plans[req.Plan]
+	if !ok {
+		return nil, ErrUnknownPlan
+	}
+	user, err := u.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := u.account(ctx, user)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	if account.SubscriptionID != "" && account.GrantsPlan() {
+		return nil, ErrAlreadySubscribed
+	}
+
+	checkoutURL, err := u.provider.CreateCheckoutSession(ctx, &billing.CheckoutParams{
+		CustomerID: account.CustomerID,
+		PriceID:    price,
+		SuccessURL: u.settings.SuccessURL,
+		CancelURL:  u.settings.CancelURL,
+		Metadata:   map[string]string{"user_id": strconv.FormatUint(uint64(user.ID), 10)},
+	})
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to create checkout: %w", err))
+	}
+	return &domain.BillingSessionResponse{URL: checkoutURL}, nil
+}
+
+// Portal creates a billing portal page for the user's customer
+func (u *billingUsecase) Portal(ctx context.Context, userID uint) (*domain.BillingSessionResponse, error) {
+	user, err := u.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := u.account(ctx, user)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+	portalURL, err := u.provider.CreatePortalSession(ctx, account.CustomerID, u.settings.PortalReturnURL)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to create billing portal session: %w", err))
+	}
+	return &domain.BillingSessionResponse{URL: portalURL}, nil
+}
+
+// CancelSubscription cancels the user's subscription at the end of its
+// period. The user keeps their plan until then, when the provider's webhook
+// reports the subscription deleted.
+func (u *billingUsecase) CancelSubscription(ctx context.Context, userID uint) (*domain.BillingResponse, error) {
+	user, err := u.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := u.accounts.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get billing account: %w", err))
+	}
+	if account == nil || account.SubscriptionID == "" || !account.GrantsPlan() {
+		return nil, ErrNoSubscription
+	}
+
+	sub, err := u.provider.CancelSubscription(ctx, account.SubscriptionID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to cancel subscription: %w", err))
+	}
+	u.applySubscription(account, sub)
+	if err := u.accounts.Update(ctx, account); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to update billing account: %w", err))
+	}
+	return billingResponse(user, account), nil
+}
+
+// HandleWebhook applies the subscription events; other event types are
+// acknowledged and ignored
+func (u *billingUsecase) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := billing.ParseWebhook(payload, signature, u.settings.WebhookSecret)
+	if err != nil {
+		return err
+	}
+	switch event.Type {
+	case billing.EventSubscriptionCreated, billing.EventSubscriptionUpdated, billing.EventSubscriptionDeleted:
+	default:
+		return nil
+	}
+	sub, err := event.Subscription()
+	if err != nil {
+		return err
+	}
+
+	log := logger.FromContext(ctx).With("event_id", event.ID, "event_type", event.Type, "customer_id", sub.Customer)
+	account, err := u.accounts.GetByCustomerID(ctx, sub.Customer)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get billing account: %w", err))
+	}
+	if account == nil {
+		log.Warn("Ignoring subscription event of unknown customer")
+		return nil
+	}
+	// Stripe doesn't deliver events in order: skip events older than the
+	// one last applied, and the end of a subscription the user replaced
+	createdAt := event.CreatedAt()
+	if account.SyncedAt != nil && createdAt.Before(*account.SyncedAt) {
+		log.Info("Ignoring stale subscription event")
+		return nil
+	}
+	if account.SubscriptionID != "" && account.SubscriptionID != sub.ID && !domain.SubscriptionGrantsPlan(sub.Status) {
+		log.Info("Ignoring event of a replaced subscription", "subscription_id", sub.ID)
+		return nil
+	}
+
+	u.applySubscription(account, sub)
+	account.SyncedAt = &createdAt
+	if account.Plan == "" {
+		log.Warn("Subscription is for a price of no configured plan", "price_id", sub.PriceID())
+	}
+	if err := u.accounts.Update(ctx, account); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to update billing account: %w", err))
+	}
+	return u.syncPlan(ctx, account)
+}
+
+// applySubscription copies the state of sub onto account
+func (u *billingUsecase) applySubscription(account *domain.BillingAccount, sub *billing.Subscription) {
+	account.SubscriptionID = sub.ID
+	account.Plan = u.planByPrice[sub.PriceID()]
+	account.Status = sub.Status
+	account.CancelAtPeriodEnd = sub.CancelAtPeriodEnd
+	account.CurrentPeriodEnd = nil
+	if end := sub.PeriodEnd(); !end.IsZero() {
+		account.CurrentPeriodEnd = &end
+	}
+}
+
+// syncPlan sets the plan of the account's user to what their subscription
+// grants
+func (u *billingUsecase) syncPlan(ctx context.Context, account *domain.BillingAccount) error {
+	plan := domain.PlanFree
+	if account.GrantsPlan() {
+		plan = account.Plan
+	}
+	user, err := u.userRepo.GetByID(ctx, account.UserID)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil || user.Plan == plan {
+		return nil
+	}
+
+	previous := user.Plan
+	user.Plan = plan
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to update user plan: %w", err))
+	}
+	logger.FromContext(ctx).Info("User plan changed", "user_id", user.ID, "from", previous, "to", plan)
+	return nil
+}
+
+// UserPlan returns the plan stored on the user
+func (u *billingUsecase) UserPlan(ctx context.Context, userID uint) (string, error) {
+	user, err := u.user(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Plan, nil
+}
+
+// user retrieves a user, failing when they don't exist
+func (u *billingUsecase) user(ctx context.Context, userID uint) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// billingResponse builds the billing status of user
+func billingResponse(user *domain.User, account *domain.BillingAccount) *domain.BillingResponse {
+	response := &domain.BillingResponse{Plan: user.Plan}
+	if account != nil && account.SubscriptionID != "" {
+		response.Subscription = account
+	}
+	return response
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBillingProvider records the calls made to it
+type fakeBillingProvider struct {
+	customers []*billing.CustomerParams
+	checkouts []*billing.CheckoutParams
+	canceled  []string
+}
+
+func (p *fakeBillingProvider) CreateCustomer(_ context.Context, params *billing.CustomerParams) (string, error) {
+	p.customers = append(p.customers, params)
+	return fmt.Sprintf("cus_%d", len(p.customers)), nil
+}
+
+func (p *fakeBillingProvider) CreateCheckoutSession(_ context.Context, params *billing.CheckoutParams) (string, error) {
+	p.checkouts = append(p.checkouts, params)
+	return "https://checkout.stripe.com/c/pay/cs_1", nil
+}
+
+func (p *fakeBillingProvider) CreatePortalSession(_ context.Context, customerID, _ string) (string, error) {
+	return "https://billing.stripe.com/p/session/" + customerID, nil
+}
+
+func (p *fakeBillingProvider) CancelSubscription(_ context.Context, subscriptionID string) (*billing.Subscription, error) {
+	p.canceled = append(p.canceled, subscriptionID)
+	var sub billing.Subscription
+	err := json.Unmarshal([]byte(subscriptionJSON(subscriptionID, "active", "price_pro", true)), &sub)
+	return &sub, err
+}
+
+// subscriptionJSON is a subscription of customer cus_1 as Stripe sends it
+func subscriptionJSON(id, status, price string, cancelAtPeriodEnd bool) string {
+	return fmt.Sprintf(`{"id":%q,"customer":"cus_1","status":%q,"cancel_at_period_end":%t,"current_period_end":1719835200,
+		"items":{"data":[{"price":{"id":%q}}]}}`, id, status, cancelAtPeriodEnd, price)
+}
+
+// signedEvent builds a webhook event about a subscription, created at
+// created, and its signature
+func signedEvent(eventType string, created time.Time, subscription string) ([]byte, string) {
+	payload := []byte(fmt.Sprintf(`{"id":"evt_%d","type":%q,"created":%d,"data":{"object":%s}}`,
+		created.UnixNano(), eventType, created.Unix(), subscription))
+	return payload, billing.SignPayload(payload, "whsec_test", time.Now())
+}
+
+func TestBillingUsecase(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	provider := &fakeBillingProvider{}
+	pool := jobs.NewPool(repos.Jobs, jobs.WithPollInterval(10*time.Millisecond))
+	billingUsecase := NewBillingUsecase(provider, repos.Users, repos.BillingAccounts, jobs.NewQueue(repos.Jobs), BillingSettings{
+		Plans:         map[string]string{"pro": "price_pro", "team": "price_team"},
+		WebhookSecret: "whsec_test",
+		SuccessURL:    "https://app.example/billing?done=1",
+		CancelURL:     "https://app.example/billing",
+	})
+	billingUsecase.RegisterJobs(pool)
+	bus := events.NewBus()
+	billingUsecase.Subscribe(bus)
+	go pool.Start()
+	defer pool.Stop()
+	users := NewUserUsecase(repos.Users, "secret", WithEventBus(bus))
+
+	assert.Equal(t, []*domain.PlanResponse{{Name: "pro"}, {Name: "team"}}, billingUsecase.Plans())
+
+	// Signing up creates the customer in the background
+	ada, err := users.Register(ctx, &domain.UserRequest{Name: "Ada", Email: "ada@example.com", Password: "secret123"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlanFree, ada.Plan)
+	require.Eventually(t, func() bool {
+		account, err := repos.BillingAccounts.GetByUserID(ctx, ada.ID)
+		return err == nil && account != nil
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Len(t, provider.customers, 1)
+	assert.Equal(t, "ada@example.com", provider.customers[0].Email)
+	assert.NotEmpty(t, provider.customers[0].IdempotencyKey)
+
+	_, err = billingUsecase.Checkout(ctx, ada.ID, &domain.CheckoutRequest{Plan: "enterprise"})
+	assert.ErrorIs(t, err, ErrUnknownPlan)
+	session, err := billingUsecase.Checkout(ctx, ada.ID, &domain.CheckoutRequest{Plan: "pro"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://checkout.stripe.com/c/pay/cs_1", session.URL)
+	require.Len(t, provider.checkouts, 1)
+	assert.Equal(t, "cus_1", provider.checkouts[0].CustomerID)
+	assert.Equal(t, "price_pro", provider.checkouts[0].PriceID)
+	assert.Len(t, provider.customers, 1, "the customer is reused")
+
+	_, err = billingUsecase.CancelSubscription(ctx, ada.ID)
+	assert.ErrorIs(t, err, ErrNoSubscription)
+
+	// Paying creates the subscription, which the webhook reports
+	start := time.Now().Add(-time.Hour)
+	payload, signature := signedEvent(billing.EventSubscriptionCreated, start, subscriptionJSON("sub_1", "active", "price_pro", false))
+	require.NoError(t, billingUsecase.HandleWebhook(ctx, payload, signature))
+	plan, err := billingUsecase.UserPlan(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pro", plan)
+	status, err := billingUsecase.GetBilling(ctx, ada.ID)
+	require.NoError(t, err)
+	require.NotNil(t, status.Subscription)
+	assert.Equal(t, domain.SubscriptionActive, status.Subscription.Status)
+	assert.Equal(t, time.Unix(1719835200, 0).UTC(), *status.Subscription.CurrentPeriodEnd)
+
+	_, err = billingUsecase.Checkout(ctx, ada.ID, &domain.CheckoutRequest{Plan: "team"})
+	assert.ErrorIs(t, err, ErrAlreadySubscribed)
+
+	// An event delivered late doesn't roll the subscription back
+	payload, signature = signedEvent(billing.EventSubscriptionUpdated, start.Add(-time.Minute), subscriptionJSON("sub_1", "incomplete", "price_pro", false))
+	require.NoError(t, billingUsecase.HandleWebhook(ctx, payload, signature))
+	plan, err = billingUsecase.UserPlan(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pro", plan)
+
+	payload[len(payload)-2] = ' '
+	assert.ErrorIs(t, billingUsecase.HandleWebhook(ctx, payload, signature), billing.ErrInvalidSignature)
+
+	// Canceling keeps the plan until the period ends
+	status, err = billingUsecase.CancelSubscription(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub_1"}, provider.canceled)
+	assert.Equal(t, "pro", status.Plan)
+	assert.True(t, status.Subscription.CancelAtPeriodEnd)
+
+	payload, signature = signedEvent(billing.EventSubscriptionDeleted, start.Add(time.Minute), subscriptionJSON("sub_1", "canceled", "price_pro", true))
+	require.NoError(t, billingUsecase.HandleWebhook(ctx, payload, signature))
+	plan, err = billingUsecase.UserPlan(ctx, ada.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PlanFree, plan)
+
+	// Events of other types and of unknown customers are acknowledged
+	payload, signature = signedEvent("invoice.paid", start.Add(2*time.Minute), `{"id":"in_1"}`)
+	assert.NoError(t, billingUsecase.HandleWebhook(ctx, payload, signature))
+	payload, signature = signedEvent(billing.EventSubscriptionCreated, start.Add(2*time.Minute),
+		`{"id":"sub_9","customer":"cus_unknown","status":"active","items":{"data":[{"price":{"id":"price_pro"}}]}}`)
+	assert.NoError(t, billingUsecase.HandleWebhook(ctx, payload, signature))
+}
@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/directory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
+)
+
+// ErrDirectorySyncRunning is returned when a sync is started while another
+// is running
+var ErrDirectorySyncRunning = domain.NewError(domain.ErrConflict, "a directory sync is already running")
+
+// DirectorySync reconciles the local users with an external directory: it
+// creates the directory's active users, updates the name, email and role of
+// those it created or linked by email before, and deactivates (soft-deletes)
+// those the directory disabled or no longer lists. Local users who never
+// matched a directory user are left alone. Run syncs on a schedule.
+//
+// Synced users get a random password, so they sign in through the
+// directory's SSO or reset it. Changes are saved through the repository
+// directly rather than published as user events, since a first sync can
+// create thousands of users.
+type DirectorySync struct {
+	source     directory.Source
+	users      repository.UserRepository
+	adminGroup string
+	dryRun     bool
+	now        func() time.Time
+	logger     *slog.Logger
+
+	// running is held for the duration of a sync
+	running sync.Mutex
+
+	mu   sync.RWMutex
+	last *domain.DirectorySyncReport
+}
+
+// NewDirectorySync creates a sync of users with source. Members of
+// adminGroup are given the admin role and everyone else the user role;
+// with no adminGroup roles are left alone. With dryRun the scheduled syncs
+// only report the changes they would make.
+func NewDirectorySync(source directory.Source, users repository.UserRepository, adminGroup string, dryRun bool) *DirectorySync {
+	return &DirectorySync{
+		source:     source,
+		users:      users,
+		adminGroup: adminGroup,
+		dryRun:     dryRun,
+		now:        time.Now,
+		logger:     slog.Default().With("worker", "DirectorySync"),
+	}
+}
+
+// Run syncs the users, or reports what a sync would change when the sync
+// was created with dryRun. It is a scheduled job; a run starting while an
+// admin-triggered sync is in progress is skipped.
+func (s *DirectorySync) Run(ctx context.Context) error {
+	report, err := s.Sync(ctx, s.dryRun)
+	if errors.Is(err, ErrDirectorySyncRunning) {
+		s.logger.Info("Skipped directory sync, one is already running")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Synced users with directory",
+		"dry_run", report.DryRun,
+		"directory_users", report.DirectoryUsers,
+		"created", report.Created,
+		"updated", report.Updated,
+		"deactivated", report.Deactivated,
+		"reactivated", report.Reactivated,
+		"failed", report.Failed,
+	)
+	return nil
+}
+
+// LastReport returns the report of the last sync, or nil before the first
+func (s *DirectorySync) LastReport() *domain.DirectorySyncReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last
+}
+
+// Sync reconciles the local users with the directory and reports the
+// changes; with dryRun nothing is saved. A change failing for one user,
+// such as an email address another user has, is reported and the sync goes
+// on. The sync aborts when the directory can't be read or lists no users,
+// since that is more likely an outage than everyone leaving.
+func (s *DirectorySync) Sync(ctx context.Context, dryRun bool) (*domain.DirectorySyncReport, error) {
+	if !s.running.TryLock() {
+		return nil, ErrDirectorySyncRunning
+	}
+	defer s.running.Unlock()
+
+	report := &domain.DirectorySyncReport{DryRun: dryRun, StartedAt: domain.Timestamp(s.now()), Changes: []domain.DirectorySyncChange{}}
+	entries, err := s.source.Users(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory users: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("directory listed no users; refusing to deactivate every synced user")
+	}
+	report.DirectoryUsers = len(entries)
+
+	// Deactivated users are soft-deleted, so read them too to reactivate
+	// them when they return
+	users := s.users.Unscoped()
+	synced, err := users.ListByDirectory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list synced users: %w", err)
+	}
+	byDirectoryID := make(map[string]*domain.User, len(synced))
+	for _, user := range synced {
+		byDirectoryID[*user.DirectoryID] = user
+	}
+
+	listed := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if listed[entry.ID] {
+			continue
+		}
+		listed[entry.ID] = true
+		s.syncUser(ctx, report, byDirectoryID[entry.ID], entry, dryRun)
+	}
+
+	for _, user := range synced {
+		if listed[*user.DirectoryID] || user.DeletedAt.Valid {
+			continue
+		}
+		change := domain.DirectorySyncChange{Action: domain.DirectorySyncDeactivate, Email: user.Email, DirectoryID: *user.DirectoryID}
+		if !dryRun {
+			if err := s.users.Delete(ctx, user.ID); err != nil {
+				change = failedChange(change, fmt.Errorf("failed to deactivate user: %w", err))
+			}
+		}
+		report.Add(change)
+	}
+
+	report.FinishedAt = domain.Timestamp(s.now())
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+	return report, nil
+}
+
+// syncUser reconciles one directory entry with user, the local user synced
+// from it before, or nil when there is none
+func (s *DirectorySync) syncUser(ctx context.Context, report *domain.DirectorySyncReport, user *domain.User, entry directory.User, dryRun bool) {
+	change := domain.DirectorySyncChange{Email: entry.Email, DirectoryID: entry.ID}
+
+	if user == nil {
+		// Link a local user with the same email address, such as one who
+		// registered before the directory was connected
+		existing, err := s.users.Unscoped().GetByEmail(ctx, entry.Email)
+		if err != nil {
+			change.Action = domain.DirectorySyncUpdate
+			report.Add(failedChange(change, fmt.Errorf("failed to get user: %w", err)))
+			return
+		}
+		if existing != nil && existing.DirectoryID != nil {
+			change.Action = domain.DirectorySyncUpdate
+			report.Add(failedChange(change, fmt.Errorf("email address belongs to directory user %s", *existing.DirectoryID)))
+			return
+		}
+		user = existing
+	}
+
+	if user == nil {
+		if !entry.Active {
+			report.Unchanged++
+			return
+		}
+		change.Action = domain.DirectorySyncCreate
+		if !dryRun {
+			if err := s.create(ctx, entry); err != nil {
+				change = failedChange(change, err)
+			}
+		}
+		report.Add(change)
+		return
+	}
+
+	if !entry.Active {
+		if user.DeletedAt.Valid {
+			report.Unchanged++
+			return
+		}
+		change.Action = domain.DirectorySyncDeactivate
+		if !dryRun {
+			if err := s.users.Delete(ctx, user.ID); err != nil {
+				change = failedChange(change, fmt.Errorf("failed to deactivate user: %w", err))
+			}
+		}
+		report.Add(change)
+		return
+	}
+
+	change.Fields = s.apply(user, entry)
+	switch {
+	case user.DeletedAt.Valid:
+		change.Action = domain.DirectorySyncReactivate
+	case len(change.Fields) > 0:
+		change.Action = domain.DirectorySyncUpdate
+	default:
+		report.Unchanged++
+		return
+	}
+	if !dryRun {
+		if err := s.save(ctx, user, change.Action == domain.DirectorySyncReactivate, len(change.Fields) > 0); err != nil {
+			change = failedChange(change, err)
+		}
+	}
+	report.Add(change)
+}
+
+// create creates an active user for entry
+func (s *DirectorySync) create(ctx context.Context, entry directory.User) error {
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := utils.HashPassword(hex.EncodeToString(password))
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	directoryID := entry.ID
+	// The directory vouches for the address, so it isn't verified by email
+	verifiedAt := s.now()
+	user := &domain.User{
+		Name:            entry.Name,
+		Email:           entry.Email,
+		Password:        hashedPassword,
+		Role:            domain.RoleUser,
+		DirectoryID:     &directoryID,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if s.adminGroup != "" && entry.InGroup(s.adminGroup) {
+		user.Role = domain.RoleAdmin
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// save saves the changes to user and reactivates it if it was deactivated.
+// A deactivated user is updated before it is restored, since reads and
+// updates through the repository skip deleted users and an update saves
+// every field, deleted_at included.
+func (s *DirectorySync) save(ctx context.Context, user *domain.User, reactivate, changed bool) error {
+	if changed {
+		users := s.users
+		if reactivate {
+			users = s.users.Unscoped()
+		}
+		if err := users.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+	if reactivate {
+		if _, err := s.users.Restore(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to reactivate user: %w", err)
+		}
+	}
+	return nil
+}
+
+// apply copies the fields of entry that differ to user and returns their
+// names
+func (s *DirectorySync) apply(user *domain.User, entry directory.User) []string {
+	var fields []string
+	if user.DirectoryID == nil || *user.DirectoryID != entry.ID {
+		directoryID := entry.ID
+		user.DirectoryID = &directoryID
+		fields = append(fields, "directory_id")
+	}
+	if entry.Name != "" && user.Name != entry.Name {
+		user.Name = entry.Name
+		fields = append(fields, "name")
+	}
+	if user.Email != entry.Email {
+		user.Email = entry.Email
+		fields = append(fields, "email")
+	}
+	if s.adminGroup != "" {
+		role := domain.RoleUser
+		if entry.InGroup(s.adminGroup) {
+			role = domain.RoleAdmin
+		}
+		if user.Role != role {
+			user.Role = role
+			fields = append(fields, "role")
+		}
+	}
+	return fields
+}
+
+// failedChange turns change into a failure because of err
+func failedChange(change domain.DirectorySyncChange, err error) domain.DirectorySyncChange {
+	change.Action = domain.DirectorySyncFail
+	change.Error = err.Error()
+	return change
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/directory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirectory lists a fixed set of users
+type fakeDirectory struct {
+	users []directory.User
+	err   error
+}
+
+func (d *fakeDirectory) Users(context.Context) ([]directory.User, error) {
+	return d.users, d.err
+}
+
+func TestDirectorySync(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	local := &domain.User{Name: "Ada", Email: "ada@example.com", Role: domain.RoleUser}
+	require.NoError(t, repos.Users.Create(ctx, local))
+	untouched := &domain.User{Name: "Local", Email: "local@example.com", Role: domain.RoleAdmin}
+	require.NoError(t, repos.Users.Create(ctx, untouched))
+
+	source := &fakeDirectory{users: []directory.User{
+		{ID: "u1", Email: "ada@example.com", Name: "Ada Lovelace", Active: true, Groups: []string{"Admins"}},
+		{ID: "u2", Email: "grace@example.com", Name: "Grace Hopper", Active: true},
+		{ID: "u3", Email: "alan@example.com", Name: "Alan Turing", Active: false},
+	}}
+	sync := NewDirectorySync(source, repos.Users, "admins", false)
+
+	// A dry run reports the changes without making them
+	report, err := sync.Sync(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 1, report.Unchanged)
+	assert.Equal(t, []string{"directory_id", "name", "role"}, report.Changes[0].Fields)
+	grace, err := repos.Users.GetByEmail(ctx, "grace@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, grace)
+
+	require.NoError(t, sync.Run(ctx))
+	assert.False(t, sync.LastReport().DryRun)
+	ada, err := repos.Users.GetByID(ctx, local.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", ada.Name)
+	assert.Equal(t, domain.RoleAdmin, ada.Role)
+	require.NotNil(t, ada.DirectoryID)
+	assert.Equal(t, "u1", *ada.DirectoryID)
+	grace, err = repos.Users.GetByEmail(ctx, "grace@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, grace)
+	assert.Equal(t, domain.RoleUser, grace.Role)
+	assert.NotNil(t, grace.EmailVerifiedAt)
+	alan, err := repos.Users.GetByEmail(ctx, "alan@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, alan, "inactive directory users are not created")
+
+	// Users the directory disables or stops listing are deactivated, and
+	// come back when it lists them again
+	source.users = []directory.User{
+		{ID: "u1", Email: "ada@example.com", Name: "Ada Lovelace", Active: false},
+		{ID: "u3", Email: "alan@example.com", Name: "Alan Turing", Active: false},
+	}
+	report, err = sync.Sync(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Deactivated)
+	ada, err = repos.Users.GetByID(ctx, local.ID)
+	require.NoError(t, err)
+	assert.Nil(t, ada)
+	grace, err = repos.Users.GetByEmail(ctx, "grace@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, grace)
+	other, err := repos.Users.GetByID(ctx, untouched.ID)
+	require.NoError(t, err)
+	require.NotNil(t, other, "local users are left alone")
+	assert.Equal(t, domain.RoleAdmin, other.Role)
+
+	source.users = []directory.User{{ID: "u1", Email: "ada@lovelace.example", Name: "Ada Lovelace", Active: true}}
+	report, err = sync.Sync(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Reactivated)
+	assert.Equal(t, []string{"email", "role"}, report.Changes[0].Fields)
+	ada, err = repos.Users.GetByID(ctx, local.ID)
+	require.NoError(t, err)
+	require.NotNil(t, ada)
+	assert.Equal(t, "ada@lovelace.example", ada.Email)
+	assert.Equal(t, domain.RoleUser, ada.Role)
+}
+
+func TestDirectorySync_Failures(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	linked := "u1"
+	require.NoError(t, repos.Users.Create(ctx, &domain.User{Name: "Ada", Email: "ada@example.com", DirectoryID: &linked}))
+
+	source := &fakeDirectory{users: []directory.User{
+		{ID: "u1", Email: "ada@example.com", Name: "Ada", Active: true},
+		{ID: "u2", Email: "ada@example.com", Name: "Impostor", Active: true},
+	}}
+	sync := NewDirectorySync(source, repos.Users, "", false)
+	report, err := sync.Sync(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Unchanged)
+	require.Equal(t, 1, report.Failed)
+	assert.Equal(t, "email address belongs to directory user u1", report.Changes[0].Error)
+
+	source.users = nil
+	_, err = sync.Sync(ctx, false)
+	assert.ErrorContains(t, err, "directory listed no users")
+	source.err = errors.New("connection refused")
+	_, err = sync.Sync(ctx, false)
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, report, sync.LastReport(), "failed syncs keep the last report")
+
+	sync.running.Lock()
+	_, err = sync.Sync(ctx, false)
+	assert.ErrorIs(t, err, ErrDirectorySyncRunning)
+	assert.NoError(t, sync.Run(ctx))
+	sync.running.Unlock()
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+)
+
+// EmailOutbox renders emails and queues them for the EmailWorker
+type EmailOutbox struct {
+	renderer *email.Renderer
+}
+
+// NewEmailOutbox creates an outbox rendering with renderer
+func NewEmailOutbox(renderer *email.Renderer) *EmailOutbox {
+	return &EmailOutbox{renderer: renderer}
+}
+
+// Queue renders data in the recipient's locale, English when it is empty or
+// has no translations, and stores the email in repo. Inside
+// TxManager.WithinTransaction, pass the transaction's repos.Emails so the
+// email is only sent if the transaction commits.
+func (o *EmailOutbox) Queue(ctx context.Context, repo repository.EmailRepository, to, locale string, data email.Data) (*domain.Email, error) {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return nil, errors.New("email recipient is required")
+	}
+
+	msg, err := o.renderer.Render(data, locale)
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+
+	queued := &domain.Email{
+		To:            to,
+		Template:      data.Template(),
+		Subject:       msg.Subject,
+		HTML:          msg.HTML,
+		Text:          msg.Text,
+		Status:        domain.EmailStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := repo.Create(ctx, queued); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to queue email: %w", err))
+	}
+	return queued, nil
+}
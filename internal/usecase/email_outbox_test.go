@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailOutbox_Queue(t *testing.T) {
+	renderer, err := email.NewRenderer(email.Brand{Name: "Acme", URL: "https://acme.example"})
+	require.NoError(t, err)
+	outbox := NewEmailOutbox(renderer)
+	repo := memory.NewEmailRepository()
+	ctx := context.Background()
+
+	queued, err := outbox.Queue(ctx, repo, " alice@example.com ", "", email.WelcomeData{Name: "Alice", LoginURL: "https://acme.example/login"})
+	require.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", stored.To)
+	assert.Equal(t, "welcome", stored.Template)
+	assert.Equal(t, "Welcome to Acme", stored.Subject)
+	assert.Contains(t, stored.HTML, "https://acme.example/login")
+	assert.Contains(t, stored.Text, "Hi Alice,")
+	assert.Equal(t, domain.EmailStatusPending, stored.Status)
+
+	_, err = outbox.Queue(ctx, repo, "", "", email.WelcomeData{Name: "Alice", LoginURL: "https://acme.example/login"})
+	assert.EqualError(t, err, "email recipient is required")
+
+	_, err = outbox.Queue(ctx, repo, "bob@example.com", "", email.WelcomeData{LoginURL: "https://acme.example/login"})
+	assert.EqualError(t, err, "invalid welcome email data: name is required")
+}
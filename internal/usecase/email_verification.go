@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+)
+
+// EmailVerifier issues and checks the tokens of email verification links.
+// Tokens are "<user ID>.<expiry>.<signature>", signed over the user's email
+// address as well, so changing the address invalidates earlier links.
+type EmailVerifier struct {
+	secret  []byte
+	ttl     time.Duration
+	linkURL string
+	now     func() time.Time
+}
+
+// NewEmailVerifier creates a verifier signing with secret, whose links to
+// linkURL stay valid for ttl
+func NewEmailVerifier(secret string, ttl time.Duration, linkURL string) *EmailVerifier {
+	return &EmailVerifier{secret: []byte(secret), ttl: ttl, linkURL: linkURL, now: time.Now}
+}
+
+// TTL returns how long verification links stay valid
+func (v *EmailVerifier) TTL() time.Duration {
+	return v.ttl
+}
+
+// Link returns the verification link of user: linkURL with a token parameter
+func (v *EmailVerifier) Link(user *domain.User) string {
+	token := v.Token(user)
+	sep := "?"
+	if strings.Contains(v.linkURL, "?") {
+		sep = "&"
+	}
+	return v.linkURL + sep + "token=" + url.QueryEscape(token)
+}
+
+// Token returns a verification token for user's current email address
+func (v *EmailVerifier) Token(user *domain.User) string {
+	payload := strconv.FormatUint(uint64(user.ID), 10) + "." + strconv.FormatInt(v.now().Add(v.ttl).Unix(), 10)
+	return payload + "." + v.sign(payload, user.Email)
+}
+
+// UserID returns the ID of the user token was issued to, without checking
+// its signature, which Check does once the user is loaded
+func (v *EmailVerifier) UserID(token string) (uint, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, domain.ErrInvalidVerificationToken
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || id == 0 {
+		return 0, domain.ErrInvalidVerificationToken
+	}
+	return uint(id), nil
+}
+
+// Check verifies that token was issued for user's current email address and
+// has not expired
+func (v *EmailVerifier) Check(token string, user *domain.User) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return domain.ErrInvalidVerificationToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(v.sign(payload, user.Email))) {
+		return domain.ErrInvalidVerificationToken
+	}
+	if parts[0] != strconv.FormatUint(uint64(user.ID), 10) {
+		return domain.ErrInvalidVerificationToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || v.now().Unix() > expires {
+		return domain.ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+// sign returns the signature of payload for email. The purpose prefix keeps
+// these signatures apart from anything else signed with the same secret.
+func (v *EmailVerifier) sign(payload, email string) string {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte("email-verification\n" + payload + "\n" + strings.ToLower(email)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerifier(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	v := NewEmailVerifier("secret", time.Hour, "https://acme.example/verify?source=email")
+	v.now = func() time.Time { return now }
+	user := &domain.User{ID: 42, Email: "Alice@example.com"}
+
+	link, err := url.Parse(v.Link(user))
+	require.NoError(t, err)
+	assert.Equal(t, "email", link.Query().Get("source"))
+	token := link.Query().Get("token")
+
+	id, err := v.UserID(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), id)
+	assert.NoError(t, v.Check(token, user))
+	assert.NoError(t, v.Check(token, &domain.User{ID: 42, Email: "alice@example.com"}), "addresses compare case-insensitively")
+
+	assert.ErrorIs(t, v.Check(token, &domain.User{ID: 42, Email: "mallory@example.com"}), domain.ErrInvalidVerificationToken, "the address changed")
+	assert.ErrorIs(t, v.Check(token, &domain.User{ID: 43, Email: "Alice@example.com"}), domain.ErrInvalidVerificationToken, "another user")
+	assert.ErrorIs(t, NewEmailVerifier("other", time.Hour, "").Check(token, user), domain.ErrInvalidVerificationToken, "another secret")
+
+	now = now.Add(time.Hour + time.Second)
+	assert.ErrorIs(t, v.Check(token, user), domain.ErrInvalidVerificationToken, "expired")
+
+	for _, token := range []string{"", "42", "x.1.sig", "0.1.sig"} {
+		_, err := v.UserID(token)
+		assert.ErrorIs(t, err, domain.ErrInvalidVerificationToken, token)
+	}
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+)
+
+// internalError reports an unexpected failure, such as a database error, to
+// the error tracker and returns it. Conflicts and canceled requests are
+// returned without a report, as are expected outcomes like ErrUserNotFound,
+// which never pass through here.
+func internalError(ctx context.Context, err error) error {
+	var conflict *domain.VersionConflictError
+	if !errors.As(err, &conflict) && !errors.Is(err, context.Canceled) {
+		reporting.ReportError(ctx, err)
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingReporter collects reported errors
+type recordingReporter struct {
+	errs []error
+}
+
+func (r *recordingReporter) ReportError(_ context.Context, err error) { r.errs = append(r.errs, err) }
+func (r *recordingReporter) ReportPanic(context.Context, any)         {}
+func (r *recordingReporter) Flush(time.Duration) bool                 { return true }
+
+func TestInternalError(t *testing.T) {
+	reporter := &recordingReporter{}
+	reporting.SetDefault(reporter)
+	t.Cleanup(func() { reporting.SetDefault(nil) })
+
+	ctx := context.Background()
+	dbErr := fmt.Errorf("failed to get user: %w", errors.New("connection refused"))
+	conflict := fmt.Errorf("failed to update user: %w", &domain.VersionConflictError{Entity: "user", ID: 1})
+	canceled := fmt.Errorf("failed to get users: %w", context.Canceled)
+
+	assert.Same(t, dbErr, internalError(ctx, dbErr))
+	assert.Same(t, conflict, internalError(ctx, conflict))
+	assert.Same(t, canceled, internalError(ctx, canceled))
+
+	assert.Equal(t, []error{dbErr}, reporter.errs, "only unexpected failures are reported")
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	eventbus "github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/pkg/events"
+)
+
+// EventPublishJob publishes one domain event to the message broker
+const EventPublishJob = "event.publish"
+
+// EventOutbox queues domain events in the job queue and publishes them to a
+// message broker from EventPublishJob jobs. Queuing never waits on the
+// broker, and an event the broker couldn't take is retried with the job.
+type EventOutbox struct {
+	queue     jobs.Enqueuer
+	publisher events.Publisher
+	source    string
+}
+
+// NewEventOutbox creates an outbox queuing events in queue and publishing
+// them with publisher; source is the CloudEvents source of the events
+func NewEventOutbox(queue jobs.Enqueuer, publisher events.Publisher, source string) *EventOutbox {
+	return &EventOutbox{queue: queue, publisher: publisher, source: source}
+}
+
+// RegisterJobs registers the publish job handler with pool
+func (o *EventOutbox) RegisterJobs(pool *jobs.Pool) {
+	pool.Register(EventPublishJob, o.publish)
+}
+
+// Subscribe queues the user lifecycle events published on bus, keyed by
+// the ID clients know the user by
+func (o *EventOutbox) Subscribe(bus *eventbus.Bus) {
+	eventbus.SubscribeUserEvents(bus, "event_outbox", func(ctx context.Context, e eventbus.UserEvent) error {
+		user := e.Change().Response
+		return o.Queue(ctx, e.EventName(), user.ExternalID(), user)
+	})
+}
+
+// Queue queues an event of type eventType about subject, with data as its
+// JSON payload
+func (o *EventOutbox) Queue(ctx context.Context, eventType, subject string, data interface{}) error {
+	event, err := events.New(o.source, eventType, subject, data)
+	if err != nil {
+		return err
+	}
+	if _, err := o.queue.Enqueue(ctx, EventPublishJob, event); err != nil {
+		return fmt.Errorf("failed to queue event: %w", err)
+	}
+	return nil
+}
+
+// publish publishes the event of a job; the job is retried when the broker
+// fails
+func (o *EventOutbox) publish(ctx context.Context, job *domain.Job) error {
+	var event events.Event
+	if err := jobs.DecodePayload(job, &event); err != nil {
+		return err
+	}
+	if err := o.publisher.Publish(ctx, &event); err != nil {
+		return fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	eventbus "github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserUsecase_PublishesEvents(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	recorder := &events.Recorder{}
+	outbox := NewEventOutbox(jobs.NewQueue(repos.Jobs), recorder, "/go-api-setup")
+	pool := jobs.NewPool(repos.Jobs, jobs.WithPollInterval(10*time.Millisecond))
+	outbox.RegisterJobs(pool)
+	go pool.Start()
+	defer pool.Stop()
+
+	bus := eventbus.NewBus()
+	outbox.Subscribe(bus)
+	u := NewUserUsecase(repos.Users, "secret", WithEventBus(bus))
+	user, err := u.Register(ctx, &domain.UserRequest{Name: "Ada", Email: "ada@example.com", Password: "secret123"})
+	require.NoError(t, err)
+	_, err = u.UpdateUser(ctx, user.ID, &domain.UpdateUserRequest{Name: "Ada L."})
+	require.NoError(t, err)
+	require.NoError(t, u.DeleteUser(ctx, user.ID))
+
+	require.Eventually(t, func() bool { return len(recorder.Events()) == 3 }, 2*time.Second, 10*time.Millisecond)
+	published := recorder.Events()
+	var types []string
+	for _, event := range published {
+		types = append(types, event.Type)
+		assert.Equal(t, "1", event.Subject)
+		assert.NotEmpty(t, event.ID)
+	}
+	assert.ElementsMatch(t, []string{domain.EventUserCreated, domain.EventUserUpdated, domain.EventUserDeleted}, types)
+	assert.NotEqual(t, published[0].ID, published[1].ID)
+	for _, event := range published {
+		if event.Type == domain.EventUserUpdated {
+			assert.Contains(t, string(event.Data), `"name":"Ada L."`)
+		}
+	}
+}
+
+func TestEventOutbox_RetriesFailedPublishes(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	recorder := &events.Recorder{Err: errors.New("broker down")}
+	outbox := NewEventOutbox(jobs.NewQueue(repos.Jobs), recorder, "/go-api-setup")
+	require.NoError(t, outbox.Queue(ctx, domain.EventUserCreated, "7", map[string]int{"id": 7}))
+
+	job, err := repos.Jobs.GetByID(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, EventPublishJob, job.Type)
+
+	assert.ErrorContains(t, outbox.publish(ctx, job), "broker down", "the job is retried")
+
+	recorder.Err = nil
+	require.NoError(t, outbox.publish(ctx, job))
+	require.Len(t, recorder.Events(), 1)
+	assert.Equal(t, "7", recorder.Events()[0].Subject)
+	assert.JSONEq(t, `{"id":7}`, string(recorder.Events()[0].Data))
+}
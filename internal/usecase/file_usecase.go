@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/storage"
+)
+
+// FileUsecase defines the interface for file business logic. Files are only
+// accessible to their owner and to admins.
+type FileUsecase interface {
+	Upload(ctx context.Context, ownerID uint, name, contentType string, content io.Reader) (*domain.FileResponse, error)
+	GetFile(ctx context.Context, requesterID uint, requesterRole string, id uint) (*domain.FileResponse, error)
+	OpenFile(ctx context.Context, requesterID uint, requesterRole string, id uint) (*domain.FileResponse, io.ReadCloser, error)
+	ListFiles(ctx context.Context, ownerID uint, limit, offset int) ([]*domain.FileResponse, error)
+	DeleteFile(ctx context.Context, requesterID uint, requesterRole string, id uint) error
+	PresignUpload(ctx context.Context, ownerID uint, name, contentType string, size int64, expiry time.Duration) (*domain.PresignResponse, error)
+	PresignDownload(ctx context.Context, requesterID uint, requesterRole string, id uint, expiry time.Duration) (*domain.PresignResponse, error)
+}
+
+// fileUsecase implements FileUsecase interface
+type fileUsecase struct {
+	fileRepo repository.FileRepository
+	storage  storage.Storage
+}
+
+// NewFileUsecase creates a new file usecase
+func NewFileUsecase(fileRepo repository.FileRepository, store storage.Storage) FileUsecase {
+	return &fileUsecase{
+		fileRepo: fileRepo,
+		storage:  store,
+	}
+}
+
+// Upload stores the content and records its metadata
+func (u *fileUsecase) Upload(ctx context.Context, ownerID uint, name, contentType string, content io.Reader) (*domain.FileResponse, error) {
+	name, contentType, err := normalizeFileMeta(name, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newFileKey(ownerID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to generate file key: %w", err))
+	}
+
+	counter := &countingReader{r: content}
+	if err := u.storage.Put(ctx, key, counter, contentType); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to store file: %w", err))
+	}
+
+	file := &domain.File{
+		OwnerID:     ownerID,
+		Key:         key,
+		Name:        name,
+		ContentType: contentType,
+		Size:        counter.n,
+	}
+	if err := u.fileRepo.Create(ctx, file); err != nil {
+		// Don't leave orphaned objects behind when the metadata can't be saved
+		if delErr := u.storage.Delete(ctx, key); delErr != nil {
+			logger.FromContext(ctx).Error("Failed to clean up stored file", "key", key, "error", delErr)
+		}
+		return nil, internalError(ctx, fmt.Errorf("failed to save file: %w", err))
+	}
+
+	return toFileResponse(file), nil
+}
+
+// GetFile returns a file's metadata
+func (u *fileUsecase) GetFile(ctx context.Context, requesterID uint, requesterRole string, id uint) (*domain.FileResponse, error) {
+	file, err := u.getAccessibleFile(ctx, requesterID, requesterRole, id)
+	if err != nil {
+		return nil, err
+	}
+	return toFileResponse(file), nil
+}
+
+// OpenFile returns a file's metadata and content. Callers must close the reader.
+func (u *fileUsecase) OpenFile(ctx context.Context, requesterID uint, requesterRole string, id uint) (*domain.FileResponse, io.ReadCloser, error) {
+	file, err := u.getAccessibleFile(ctx, requesterID, requesterRole, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := u.storage.Get(ctx, file.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, domain.ErrFileNotFound
+		}
+		return nil, nil, internalError(ctx, fmt.Errorf("failed to read file: %w", err))
+	}
+	return toFileResponse(file), content, nil
+}
+
+// ListFiles returns the owner's files with pagination
+func (u *fileUsecase) ListFiles(ctx context.Context, ownerID uint, limit, offset int) ([]*domain.FileResponse, error) {
+	files, err := u.fileRepo.GetByOwner(ctx, ownerID, limit, offset)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get files: %w", err))
+	}
+
+	responses := make([]*domain.FileResponse, 0, len(files))
+	for _, file := range files {
+		responses = append(responses, toFileResponse(file))
+	}
+	return responses, nil
+}
+
+// DeleteFile removes a file's metadata and content
+func (u *fileUsecase) DeleteFile(ctx context.Context, requesterID uint, requesterRole string, id uint) error {
+	file, err := u.getAccessibleFile(ctx, requesterID, requesterRole, id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.fileRepo.Delete(ctx, id); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to delete file: %w", err))
+	}
+	if err := u.storage.Delete(ctx, file.Key); err != nil {
+		logger.FromContext(ctx).Error("Failed to delete stored file", "key", file.Key, "error", err)
+	}
+	return nil
+}
+
+// PresignUpload records the file and returns a URL the client can PUT the
+// content to directly. The file is listed immediately; downloads report it as
+// not found until the upload completes. The URL only accepts a body of the
+// declared size, so the recorded size is the stored object's.
+func (u *fileUsecase) PresignUpload(ctx context.Context, ownerID uint, name, contentType string, size int64, expiry time.Duration) (*domain.PresignResponse, error) {
+	name, contentType, err := normalizeFileMeta(name, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newFileKey(ownerID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to generate file key: %w", err))
+	}
+
+	signed, err := u.storage.SignedUploadURL(ctx, key, contentType, size, expiry)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to sign upload url: %w", err))
+	}
+
+	file := &domain.File{
+		OwnerID:     ownerID,
+		Key:         key,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+	}
+	if err := u.fileRepo.Create(ctx, file); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to save file: %w", err))
+	}
+
+	return &domain.PresignResponse{
+		Method:    http.MethodPut,
+		URL:       signed.URL,
+		Headers:   signed.Headers,
+		ExpiresAt: domain.Timestamp(time.Now().Add(expiry)),
+		File:      toFileResponse(file),
+	}, nil
+}
+
+// PresignDownload returns a URL the client can GET the file content from directly
+func (u *fileUsecase) PresignDownload(ctx context.Context, requesterID uint, requesterRole string, id uint, expiry time.Duration) (*domain.PresignResponse, error) {
+	file, err := u.getAccessibleFile(ctx, requesterID, requesterRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	signedURL, err := u.storage.SignedURL(ctx, file.Key, expiry)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to sign download url: %w", err))
+	}
+
+	return &domain.PresignResponse{
+		Method:    http.MethodGet,
+		URL:       signedURL,
+		ExpiresAt: domain.Timestamp(time.Now().Add(expiry)),
+		File:      toFileResponse(file),
+	}, nil
+}
+
+// getAccessibleFile loads a file and checks the requester may access it
+func (u *fileUsecase) getAccessibleFile(ctx context.Context, requesterID uint, requesterRole string, id uint) (*domain.File, error) {
+	file, err := u.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get file: %w", err))
+	}
+	if file == nil {
+		return nil, domain.ErrFileNotFound
+	}
+	if file.OwnerID != requesterID && requesterRole != domain.RoleAdmin {
+		return nil, domain.ErrAccessDenied
+	}
+	return file, nil
+}
+
+// normalizeFileMeta strips directory components from the file name and
+// defaults the content type
+func normalizeFileMeta(name, contentType string) (string, string, error) {
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return "", "", domain.ErrFileNameRequired
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return name, contentType, nil
+}
+
+// newFileKey generates a unique, unguessable storage key scoped to the owner
+func newFileKey(ownerID uint) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("files/%d/%s", ownerID, hex.EncodeToString(b)), nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// toFileResponse converts a file to its response representation
+func toFileResponse(file *domain.File) *domain.FileResponse {
+	return &domain.FileResponse{
+		ID:          file.ID,
+		OwnerID:     file.OwnerID,
+		Name:        file.Name,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		CreatedAt:   domain.Timestamp(file.CreatedAt),
+	}
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/mocks"
+	"github.com/aungmyozaw92/go-api-setup/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileUsecaseTestSuite struct {
+	suite.Suite
+	mockRepo *mocks.MockFileRepository
+	storage  *storage.LocalStorage
+	usecase  FileUsecase
+	ctx      context.Context
+}
+
+func (suite *FileUsecaseTestSuite) SetupTest() {
+	store, err := storage.NewLocalStorage(suite.T().TempDir(), "http://localhost/storage", "secret")
+	require.NoError(suite.T(), err)
+
+	suite.mockRepo = new(mocks.MockFileRepository)
+	suite.storage = store
+	suite.usecase = NewFileUsecase(suite.mockRepo, store)
+	suite.ctx = context.Background()
+}
+
+func (suite *FileUsecaseTestSuite) TearDownTest() {
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *FileUsecaseTestSuite) TestUpload_Success() {
+	var saved *domain.File
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*domain.File")).Return(nil).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*domain.File)
+		saved.ID = 1
+	})
+
+	result, err := suite.usecase.Upload(suite.ctx, 7, "../report.txt", "", strings.NewReader("hello"))
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(1), result.ID)
+	assert.Equal(suite.T(), "report.txt", result.Name, "directory components are stripped")
+	assert.Equal(suite.T(), "application/octet-stream", result.ContentType)
+	assert.Equal(suite.T(), int64(5), result.Size)
+	assert.True(suite.T(), strings.HasPrefix(saved.Key, "files/7/"))
+
+	rc, err := suite.storage.Get(suite.ctx, saved.Key)
+	require.NoError(suite.T(), err)
+	defer rc.Close()
+	content, _ := io.ReadAll(rc)
+	assert.Equal(suite.T(), "hello", string(content))
+}
+
+func (suite *FileUsecaseTestSuite) TestUpload_CleansUpOnRepositoryError() {
+	var key string
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*domain.File")).Return(errors.New("db down")).Run(func(args mock.Arguments) {
+		key = args.Get(1).(*domain.File).Key
+	})
+
+	_, err := suite.usecase.Upload(suite.ctx, 7, "report.txt", "text/plain", strings.NewReader("hello"))
+
+	assert.Error(suite.T(), err)
+	_, err = suite.storage.Get(suite.ctx, key)
+	assert.ErrorIs(suite.T(), err, storage.ErrNotFound)
+}
+
+func (suite *FileUsecaseTestSuite) TestGetFile_Ownership() {
+	file := &domain.File{ID: 1, OwnerID: 7, Key: "files/7/abc", Name: "report.txt"}
+	suite.mockRepo.On("GetByID", suite.ctx, uint(1)).Return(file, nil)
+
+	_, err := suite.usecase.GetFile(suite.ctx, 7, domain.RoleUser, 1)
+	assert.NoError(suite.T(), err, "owner can access")
+
+	_, err = suite.usecase.GetFile(suite.ctx, 8, domain.RoleAdmin, 1)
+	assert.NoError(suite.T(), err, "admin can access")
+
+	_, err = suite.usecase.GetFile(suite.ctx, 8, domain.RoleUser, 1)
+	assert.EqualError(suite.T(), err, "access denied")
+}
+
+func (suite *FileUsecaseTestSuite) TestGetFile_NotFound() {
+	suite.mockRepo.On("GetByID", suite.ctx, uint(1)).Return(nil, nil)
+
+	_, err := suite.usecase.GetFile(suite.ctx, 7, domain.RoleUser, 1)
+
+	assert.EqualError(suite.T(), err, "file not found")
+}
+
+func (suite *FileUsecaseTestSuite) TestDeleteFile_RemovesContent() {
+	require.NoError(suite.T(), suite.storage.Put(suite.ctx, "files/7/abc", strings.NewReader("hello"), "text/plain"))
+	suite.mockRepo.On("GetByID", suite.ctx, uint(1)).Return(&domain.File{ID: 1, OwnerID: 7, Key: "files/7/abc"}, nil)
+	suite.mockRepo.On("Delete", suite.ctx, uint(1)).Return(nil)
+
+	err := suite.usecase.DeleteFile(suite.ctx, 7, domain.RoleUser, 1)
+
+	assert.NoError(suite.T(), err)
+	_, err = suite.storage.Get(suite.ctx, "files/7/abc")
+	assert.ErrorIs(suite.T(), err, storage.ErrNotFound)
+}
+
+func (suite *FileUsecaseTestSuite) TestPresignUpload_RecordsFile() {
+	suite.mockRepo.On("Create", suite.ctx, mock.AnythingOfType("*domain.File")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.File).ID = 1
+	})
+
+	result, err := suite.usecase.PresignUpload(suite.ctx, 7, "video.mp4", "video/mp4", 1024, time.Minute)
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.MethodPut, result.Method)
+	assert.True(suite.T(), strings.HasPrefix(result.URL, "http://localhost/storage/files/7/"))
+	assert.Contains(suite.T(), result.URL, "size=1024")
+	assert.Equal(suite.T(), map[string]string{"Content-Type": "video/mp4"}, result.Headers)
+	assert.Equal(suite.T(), uint(1), result.File.ID)
+	assert.Equal(suite.T(), int64(1024), result.File.Size)
+}
+
+func (suite *FileUsecaseTestSuite) TestPresignDownload_ChecksOwnership() {
+	suite.mockRepo.On("GetByID", suite.ctx, uint(1)).Return(&domain.File{ID: 1, OwnerID: 7, Key: "files/7/abc"}, nil)
+
+	result, err := suite.usecase.PresignDownload(suite.ctx, 7, domain.RoleUser, 1, time.Minute)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.MethodGet, result.Method)
+	assert.Contains(suite.T(), result.URL, "files/7/abc?")
+
+	_, err = suite.usecase.PresignDownload(suite.ctx, 8, domain.RoleUser, 1, time.Minute)
+	assert.EqualError(suite.T(), err, "access denied")
+}
+
+func TestFileUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(FileUsecaseTestSuite))
+}
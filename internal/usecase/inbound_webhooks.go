@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webhook"
+)
+
+// InboundWebhookJob is the job type of received webhooks waiting for their
+// handler
+const InboundWebhookJob = "webhook.inbound"
+
+// Errors returned by InboundWebhookUsecase.Receive
+var (
+	ErrUnknownWebhookProvider = errors.New("unknown webhook provider")
+	ErrInvalidWebhookPayload  = errors.New("invalid webhook payload")
+)
+
+// InboundWebhookHandler handles a received webhook from the job queue.
+// Providers deliver at least once, so handlers must tolerate seeing a
+// delivery twice.
+type InboundWebhookHandler func(ctx context.Context, hook *domain.InboundWebhook) error
+
+// InboundWebhookOption configures how the webhooks of one event are handled
+type InboundWebhookOption func(*inboundRoute)
+
+// WithRequiredFields rejects webhooks whose payload lacks a value at one of
+// the dotted paths, such as "data.object.id", before they are queued
+func WithRequiredFields(paths ...string) InboundWebhookOption {
+	return func(r *inboundRoute) {
+		r.required = append(r.required, paths...)
+	}
+}
+
+// InboundWebhookUsecase receives other services' webhooks: it verifies
+// their signatures, validates their payloads and queues them for the
+// handlers integrations register for their events
+type InboundWebhookUsecase interface {
+	// Handle registers handler for the event of provider; domain.EventWildcard
+	// handles the provider's events without a handler of their own
+	Handle(provider, event string, handler InboundWebhookHandler, opts ...InboundWebhookOption)
+	// Receive verifies and queues a request to /hooks/{provider}. Events
+	// without a handler are acknowledged and dropped, returning false.
+	Receive(ctx context.Context, provider string, header http.Header, body []byte) (bool, error)
+	// RegisterJobs registers the job handler running the queued webhooks
+	RegisterJobs(pool *jobs.Pool)
+}
+
+// inboundRoute is the handler of a provider's event
+type inboundRoute struct {
+	handler  InboundWebhookHandler
+	required []string
+}
+
+type inboundWebhookUsecase struct {
+	queue     jobs.Enqueuer
+	secrets   map[string]string
+	tolerance time.Duration
+
+	mu     sync.RWMutex
+	routes map[string]map[string]*inboundRoute
+}
+
+// NewInboundWebhookUsecase creates the receiver of the webhooks of the
+// providers in secrets, keyed by name, signed with their secret no longer
+// than tolerance ago
+func NewInboundWebhookUsecase(queue jobs.Enqueuer, secrets map[string]string, tolerance time.Duration) InboundWebhookUsecase {
+	return &inboundWebhookUsecase{
+		queue:     queue,
+		secrets:   secrets,
+		tolerance: tolerance,
+		routes:    make(map[string]map[string]*inboundRoute),
+	}
+}
+
+// Handle registers handler for the event of provider
+func (u *inboundWebhookUsecase) Handle(provider, event string, handler InboundWebhookHandler, opts ...InboundWebhookOption) {
+	route := &inboundRoute{handler: handler}
+	for _, opt := range opts {
+		opt(route)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.routes[provider] == nil {
+		u.routes[provider] = make(map[string]*inboundRoute)
+	}
+	u.routes[provider][event] = route
+}
+
+// route returns the handler of the event of provider, or nil
+func (u *inboundWebhookUsecase) route(provider, event string) *inboundRoute {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if route, ok := u.routes[provider][event]; ok {
+		return route
+	}
+	return u.routes[provider][domain.EventWildcard]
+}
+
+// Receive verifies the request's signature with the provider's secret,
+// validates its payload and queues it
+func (u *inboundWebhookUsecase) Receive(ctx context.Context, name string, header http.Header, body []byte) (bool, error) {
+	secret, ok := u.secrets[name]
+	if !ok || secret == "" {
+		return false, ErrUnknownWebhookProvider
+	}
+	provider := webhook.LookupProvider(name)
+	if err := provider.Verify(header, body, secret, u.tolerance); err != nil {
+		return false, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return false, fmt.Errorf("%w: the body must be a JSON object", ErrInvalidWebhookPayload)
+	}
+	hook := &domain.InboundWebhook{
+		Provider:   name,
+		Event:      headerOrField(header, provider.EventHeader, fields, provider.EventField),
+		DeliveryID: headerOrField(header, provider.DeliveryHeader, fields, provider.DeliveryField),
+		Payload:    body,
+		ReceivedAt: time.Now().UTC(),
+	}
+	if hook.Event == "" {
+		return false, fmt.Errorf("%w: missing event type", ErrInvalidWebhookPayload)
+	}
+
+	log := logger.FromContext(ctx).With("provider", name, "event", hook.Event, "delivery_id", hook.DeliveryID)
+	route := u.route(name, hook.Event)
+	if route == nil {
+		log.Debug("Ignoring webhook event without a handler")
+		return false, nil
+	}
+	if err := validateRequiredFields(body, route.required); err != nil {
+		return false, err
+	}
+
+	if _, err := u.queue.Enqueue(ctx, InboundWebhookJob, hook); err != nil {
+		return false, internalError(ctx, fmt.Errorf("failed to queue webhook: %w", err))
+	}
+	log.Info("Queued webhook")
+	return true, nil
+}
+
+// RegisterJobs registers the job handler running the queued webhooks
+func (u *inboundWebhookUsecase) RegisterJobs(pool *jobs.Pool) {
+	pool.Register(InboundWebhookJob, u.handleJob)
+}
+
+// handleJob runs the handler of a queued webhook's event
+func (u *inboundWebhookUsecase) handleJob(ctx context.Context, job *domain.Job) error {
+	var hook domain.InboundWebhook
+	if err := jobs.DecodePayload(job, &hook); err != nil {
+		return err
+	}
+	route := u.route(hook.Provider, hook.Event)
+	if route == nil {
+		// The handler was removed since the webhook was queued
+		return jobs.Permanent(fmt.Errorf("no handler for %s webhook event %s", hook.Provider, hook.Event))
+	}
+	ctx = logger.With(ctx, "provider", hook.Provider, "event", hook.Event, "delivery_id", hook.DeliveryID)
+	return route.handler(ctx, &hook)
+}
+
+// headerOrField returns the header named header, or the top-level string
+// field named field when header is empty
+func headerOrField(h http.Header, header string, fields map[string]json.RawMessage, field string) string {
+	if header != "" {
+		return h.Get(header)
+	}
+	var value string
+	if field != "" {
+		json.Unmarshal(fields[field], &value)
+	}
+	return value
+}
+
+// validateRequiredFields checks that the JSON object body has a non-null
+// value at each dotted path
+func validateRequiredFields(body []byte, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	var payload any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	for _, path := range paths {
+		value := payload
+		for _, key := range strings.Split(path, ".") {
+			object, _ := value.(map[string]any)
+			value = object[key]
+		}
+		if value == nil {
+			return fmt.Errorf("%w: missing %s", ErrInvalidWebhookPayload, path)
+		}
+	}
+	return nil
+}
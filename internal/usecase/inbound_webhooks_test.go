@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedHeader signs body like this service's webhook deliveries
+func signedHeader(secret, event string, body []byte) http.Header {
+	now := time.Now()
+	return http.Header{
+		webhook.HeaderSignature: {webhook.Sign(secret, now, body)},
+		webhook.HeaderTimestamp: {strconv.FormatInt(now.Unix(), 10)},
+		webhook.HeaderEvent:     {event},
+		webhook.HeaderDelivery:  {"d-1"},
+	}
+}
+
+func TestInboundWebhooks_Dispatch(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	hooks := NewInboundWebhookUsecase(jobs.NewQueue(repos.Jobs), map[string]string{"acme": "s3cret", "stripe": "whsec_test"}, 5*time.Minute)
+	pool := jobs.NewPool(repos.Jobs, jobs.WithPollInterval(10*time.Millisecond))
+	hooks.RegisterJobs(pool)
+	go pool.Start()
+	defer pool.Stop()
+
+	var mu sync.Mutex
+	var handled []*domain.InboundWebhook
+	record := func(_ context.Context, hook *domain.InboundWebhook) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, hook)
+		return nil
+	}
+	hooks.Handle("acme", "order.paid", record, WithRequiredFields("order.id"))
+	hooks.Handle("stripe", domain.EventWildcard, record)
+
+	body := []byte(`{"order":{"id":42}}`)
+	queued, err := hooks.Receive(ctx, "acme", signedHeader("s3cret", "order.paid", body), body)
+	require.NoError(t, err)
+	assert.True(t, queued)
+
+	stripeBody := []byte(`{"id":"evt_1","type":"invoice.paid","data":{"object":{}}}`)
+	queued, err = hooks.Receive(ctx, "stripe", http.Header{billing.SignatureHeader: {billing.SignPayload(stripeBody, "whsec_test", time.Now())}}, stripeBody)
+	require.NoError(t, err)
+	assert.True(t, queued, "the wildcard handles every stripe event")
+
+	queued, err = hooks.Receive(ctx, "acme", signedHeader("s3cret", "order.refunded", body), body)
+	require.NoError(t, err)
+	assert.False(t, queued, "events without a handler are dropped")
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	byProvider := map[string]*domain.InboundWebhook{}
+	for _, hook := range handled {
+		byProvider[hook.Provider] = hook
+	}
+	assert.Equal(t, "order.paid", byProvider["acme"].Event)
+	assert.Equal(t, "d-1", byProvider["acme"].DeliveryID)
+	assert.JSONEq(t, string(body), string(byProvider["acme"].Payload))
+	assert.Equal(t, "invoice.paid", byProvider["stripe"].Event)
+	assert.Equal(t, "evt_1", byProvider["stripe"].DeliveryID)
+}
+
+func TestInboundWebhooks_Rejects(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	hooks := NewInboundWebhookUsecase(jobs.NewQueue(repos.Jobs), map[string]string{"acme": "s3cret"}, 5*time.Minute)
+	hooks.Handle("acme", "order.paid", func(context.Context, *domain.InboundWebhook) error { return nil }, WithRequiredFields("order.id"))
+
+	body := []byte(`{"order":{"id":42}}`)
+	_, err := hooks.Receive(ctx, "github", signedHeader("s3cret", "order.paid", body), body)
+	assert.ErrorIs(t, err, ErrUnknownWebhookProvider)
+
+	_, err = hooks.Receive(ctx, "acme", signedHeader("guess", "order.paid", body), body)
+	assert.ErrorIs(t, err, webhook.ErrInvalidSignature)
+
+	for name, tc := range map[string]struct {
+		event string
+		body  string
+		want  string
+	}{
+		"not an object":   {"order.paid", `[1,2]`, "must be a JSON object"},
+		"no event type":   {"", `{"order":{"id":42}}`, "missing event type"},
+		"required absent": {"order.paid", `{"order":{"total":10}}`, "missing order.id"},
+		"required null":   {"order.paid", `{"order":{"id":null}}`, "missing order.id"},
+	} {
+		_, err := hooks.Receive(ctx, "acme", signedHeader("s3cret", tc.event, []byte(tc.body)), []byte(tc.body))
+		assert.ErrorIs(t, err, ErrInvalidWebhookPayload, name)
+		assert.ErrorContains(t, err, tc.want, name)
+	}
+
+	job, err := repos.Jobs.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, job, "rejected webhooks aren't queued")
+}
@@ -79,4 +79,88 @@ func (m *MockUserUsecase) GetUserByID(ctx context.Context, userID uint) (*domain
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.UserResponse), args.Error(1)
-} 
\ No newline at end of file
+}
+
+// GetUsersByIDs mocks the GetUsersByIDs method
+func (m *MockUserUsecase) GetUsersByIDs(ctx context.Context, userIDs []uint) ([]*domain.UserResponse, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.UserResponse), args.Error(1)
+}
+
+// GetAllUsersIncludingDeleted mocks the GetAllUsersIncludingDeleted method
+func (m *MockUserUsecase) GetAllUsersIncludingDeleted(ctx context.Context, limit, offset int) ([]*domain.UserResponse, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.UserResponse), args.Error(1)
+}
+
+// RestoreUser mocks the RestoreUser method
+func (m *MockUserUsecase) RestoreUser(ctx context.Context, userID uint) (*domain.UserResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserResponse), args.Error(1)
+}
+
+// SearchUsers mocks the SearchUsers method
+func (m *MockUserUsecase) SearchUsers(ctx context.Context, query string, limit, offset int) ([]*domain.UserResponse, error) {
+	args := m.Called(ctx, query, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.UserResponse), args.Error(1)
+}
+
+// CreateUsers mocks the CreateUsers method
+func (m *MockUserUsecase) CreateUsers(ctx context.Context, reqs []*domain.UserRequest) ([]*domain.UserResponse, error) {
+	args := m.Called(ctx, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.UserResponse), args.Error(1)
+}
+
+// ResolveUserID mocks the ResolveUserID method
+func (m *MockUserUsecase) ResolveUserID(ctx context.Context, externalID string) (uint, error) {
+	args := m.Called(ctx, externalID)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+// VerifyEmail mocks the VerifyEmail method
+func (m *MockUserUsecase) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+// SendPhoneVerification mocks the SendPhoneVerification method
+func (m *MockUserUsecase) SendPhoneVerification(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// VerifyPhone mocks the VerifyPhone method
+func (m *MockUserUsecase) VerifyPhone(ctx context.Context, userID uint, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+// SetTwoFactor mocks the SetTwoFactor method
+func (m *MockUserUsecase) SetTwoFactor(ctx context.Context, userID uint, enabled bool) error {
+	args := m.Called(ctx, userID, enabled)
+	return args.Error(0)
+}
+
+// CompleteTwoFactorLogin mocks the CompleteTwoFactorLogin method
+func (m *MockUserUsecase) CompleteTwoFactorLogin(ctx context.Context, req *domain.TwoFactorLoginRequest) (*domain.LoginResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginResponse), args.Error(1)
+}
@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/realtime"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+)
+
+// Job types run by the notification usecase. A dispatch job fans a
+// notification out into one send job per enabled channel, so each channel is
+// retried on its own.
+const (
+	NotificationDispatchJob = "notification.dispatch"
+	NotificationSendJob     = "notification.send"
+)
+
+// NotificationChannel sends notifications one way, such as by email. Send is
+// called from job handlers and may be retried, so channels should hand the
+// notification to an outbox rather than deliver it inline.
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, user *domain.User, msg *domain.NotificationMessage) error
+}
+
+// Notifier sends notifications to users
+type Notifier interface {
+	// Notify queues the notification; it is fanned out to the user's
+	// channels asynchronously
+	Notify(ctx context.Context, msg *domain.NotificationMessage) error
+}
+
+// NotificationUsecase defines the interface for notification business logic
+type NotificationUsecase interface {
+	Notifier
+	GetPreferences(ctx context.Context, userID uint) ([]*domain.NotificationPreferenceResponse, error)
+	UpdatePreferences(ctx context.Context, userID uint, req *domain.NotificationPreferencesRequest) ([]*domain.NotificationPreferenceResponse, error)
+	// ListNotifications returns the user's in-app notifications, newest first
+	ListNotifications(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]*domain.NotificationResponse, error)
+	// UnreadCount counts the user's unread in-app notifications
+	UnreadCount(ctx context.Context, userID uint) (int64, error)
+	// MarkRead marks one of the user's in-app notifications as read
+	MarkRead(ctx context.Context, userID, id uint) (*domain.NotificationResponse, error)
+	// SubscribePush stores a browser push subscription of the user
+	SubscribePush(ctx context.Context, userID uint, req *domain.PushSubscriptionRequest, userAgent string) (*domain.PushSubscription, error)
+	// ListPushSubscriptions returns the user's browser push subscriptions
+	ListPushSubscriptions(ctx context.Context, userID uint) ([]*domain.PushSubscription, error)
+	// DeletePushSubscription removes one of the user's push subscriptions
+	DeletePushSubscription(ctx context.Context, userID, id uint) error
+	// PushPublicKey returns the VAPID public key browsers subscribe with
+	PushPublicKey() (string, error)
+	// RegisterJobs registers the dispatch and send job handlers with the
+	// pool, and the push send job handler when push is configured
+	RegisterJobs(pool *jobs.Pool)
+}
+
+// notificationUsecase implements NotificationUsecase interface
+type notificationUsecase struct {
+	userRepo  repository.UserRepository
+	prefRepo  repository.NotificationPreferenceRepository
+	inboxRepo repository.NotificationRepository
+	queue     jobs.Enqueuer
+	channels  map[string]NotificationChannel
+	// push is the push channel, when configured
+	push *pushNotificationChannel
+}
+
+// NewNotificationUsecase creates a new notification usecase sending on the
+// given channels. Preferences for channels that aren't configured are kept
+// but ignored. The inbox is read from inboxRepo, which the in-app channel
+// writes to.
+func NewNotificationUsecase(userRepo repository.UserRepository, prefRepo repository.NotificationPreferenceRepository, inboxRepo repository.NotificationRepository, queue jobs.Enqueuer, channels ...NotificationChannel) NotificationUsecase {
+	u := &notificationUsecase{
+		userRepo:  userRepo,
+		prefRepo:  prefRepo,
+		inboxRepo: inboxRepo,
+		queue:     queue,
+		channels:  make(map[string]NotificationChannel, len(channels)),
+	}
+	for _, channel := range channels {
+		u.channels[channel.Name()] = channel
+		if push, ok := channel.(*pushNotificationChannel); ok {
+			u.push = push
+		}
+	}
+	return u
+}
+
+// notificationSend is the payload of a send job
+type notificationSend struct {
+	Channel string                      `json:"channel"`
+	Message *domain.NotificationMessage `json:"message"`
+}
+
+// Notify validates the notification and queues its dispatch job
+func (u *notificationUsecase) Notify(ctx context.Context, msg *domain.NotificationMessage) error {
+	if _, ok := domain.NotificationTypes[msg.Type]; !ok {
+		return fmt.Errorf("unknown notification type: %s", msg.Type)
+	}
+	if msg.UserID == 0 {
+		return errors.New("notification recipient is required")
+	}
+
+	if _, err := u.queue.Enqueue(ctx, NotificationDispatchJob, msg); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to queue notification: %w", err))
+	}
+	return nil
+}
+
+// RegisterJobs registers the notification job handlers
+func (u *notificationUsecase) RegisterJobs(pool *jobs.Pool) {
+	pool.Register(NotificationDispatchJob, u.dispatch)
+	pool.Register(NotificationSendJob, u.send)
+	if u.push != nil {
+		pool.Register(PushSendJob, u.push.deliver)
+	}
+}
+
+// dispatch queues a send job for every channel the user has enabled for the
+// notification's type
+func (u *notificationUsecase) dispatch(ctx context.Context, job *domain.Job) error {
+	var msg domain.NotificationMessage
+	if err := jobs.DecodePayload(job, &msg); err != nil {
+		return err
+	}
+
+	enabled, err := u.enabledChannels(ctx, msg.UserID, msg.Type)
+	if err != nil {
+		return err
+	}
+	for _, channel := range enabled {
+		if _, ok := u.channels[channel]; !ok {
+			continue
+		}
+		if _, err := u.queue.Enqueue(ctx, NotificationSendJob, notificationSend{Channel: channel, Message: &msg}); err != nil {
+			return fmt.Errorf("failed to queue %s notification: %w", channel, err)
+		}
+	}
+	return nil
+}
+
+// send sends the notification on one channel. Notifications for users that
+// have since been deleted are dropped.
+func (u *notificationUsecase) send(ctx context.Context, job *domain.Job) error {
+	var payload notificationSend
+	if err := jobs.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+	if payload.Message == nil {
+		return jobs.Permanent(errors.New("notification message is missing"))
+	}
+	channel, ok := u.channels[payload.Channel]
+	if !ok {
+		return jobs.Permanent(fmt.Errorf("unknown notification channel: %s", payload.Channel))
+	}
+
+	user, err := u.userRepo.GetByID(ctx, payload.Message.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		logger.FromContext(ctx).Info("Dropping notification for deleted user", "user_id", payload.Message.UserID, "type", payload.Message.Type)
+		return nil
+	}
+	return channel.Send(ctx, user, payload.Message)
+}
+
+// enabledChannels returns the channels a notification type is sent on for the
+// user: its defaults, adjusted by the user's preferences
+func (u *notificationUsecase) enabledChannels(ctx context.Context, userID uint, notificationType string) ([]string, error) {
+	prefs, err := u.prefRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	enabled := slices.Clone(domain.NotificationTypes[notificationType])
+	for _, pref := range prefs {
+		if pref.Type != notificationType {
+			continue
+		}
+		i := slices.Index(enabled, pref.Channel)
+		switch {
+		case pref.Enabled && i < 0:
+			enabled = append(enabled, pref.Channel)
+		case !pref.Enabled && i >= 0:
+			enabled = slices.Delete(enabled, i, i+1)
+		}
+	}
+	return enabled, nil
+}
+
+// GetPreferences returns whether each channel is enabled for each notification type
+func (u *notificationUsecase) GetPreferences(ctx context.Context, userID uint) ([]*domain.NotificationPreferenceResponse, error) {
+	prefs, err := u.prefRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get notification preferences: %w", err))
+	}
+	return u.toPreferenceResponses(prefs), nil
+}
+
+// UpdatePreferences stores the user's choices and returns the resulting preferences
+func (u *notificationUsecase) UpdatePreferences(ctx context.Context, userID uint, req *domain.NotificationPreferencesRequest) ([]*domain.NotificationPreferenceResponse, error) {
+	if len(req.Preferences) == 0 {
+		return nil, domain.NewError(domain.ErrInvalid, "at least one preference is required")
+	}
+
+	prefs := make([]*domain.NotificationPreference, 0, len(req.Preferences))
+	for _, update := range req.Preferences {
+		if _, ok := domain.NotificationTypes[update.Type]; !ok {
+			return nil, domain.Errorf(domain.ErrInvalid, "unknown notification type: %s", update.Type)
+		}
+		if _, ok := u.channels[update.Channel]; !ok {
+			return nil, domain.Errorf(domain.ErrInvalid, "unknown notification channel: %s", update.Channel)
+		}
+		prefs = append(prefs, &domain.NotificationPreference{
+			UserID:  userID,
+			Type:    update.Type,
+			Channel: update.Channel,
+			Enabled: update.Enabled,
+		})
+	}
+
+	if err := u.prefRepo.Upsert(ctx, prefs); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to update notification preferences: %w", err))
+	}
+	return u.GetPreferences(ctx, userID)
+}
+
+// ListNotifications returns a page of the user's inbox
+func (u *notificationUsecase) ListNotifications(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]*domain.NotificationResponse, error) {
+	notifications, err := u.inboxRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get notifications: %w", err))
+	}
+	responses := make([]*domain.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, toNotificationResponse(notification))
+	}
+	return responses, nil
+}
+
+// UnreadCount counts the user's unread notifications
+func (u *notificationUsecase) UnreadCount(ctx context.Context, userID uint) (int64, error) {
+	count, err := u.inboxRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, internalError(ctx, fmt.Errorf("failed to count unread notifications: %w", err))
+	}
+	return count, nil
+}
+
+// MarkRead marks the notification as read; marking it again keeps the time
+// it was first read
+func (u *notificationUsecase) MarkRead(ctx context.Context, userID, id uint) (*domain.NotificationResponse, error) {
+	notification, err := u.inboxRepo.MarkRead(ctx, userID, id, time.Now())
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to mark notification as read: %w", err))
+	}
+	if notification == nil {
+		return nil, domain.ErrNotificationNotFound
+	}
+	return toNotificationResponse(notification), nil
+}
+
+// toNotificationResponse decodes the stored payload of an inbox notification.
+// Payloads are written by the in-app channel, so one that fails to decode is
+// returned empty rather than failing the whole inbox.
+func toNotificationResponse(notification *domain.Notification) *domain.NotificationResponse {
+	response := &domain.NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    (*domain.Timestamp)(notification.ReadAt),
+		CreatedAt: domain.Timestamp(notification.CreatedAt),
+	}
+	_ = json.Unmarshal([]byte(notification.Payload), &response.Payload)
+	return response
+}
+
+// toPreferenceResponses lists every type and configured channel, marking the
+// ones the user hasn't chosen as defaults
+func (u *notificationUsecase) toPreferenceResponses(prefs []*domain.NotificationPreference) []*domain.NotificationPreferenceResponse {
+	chosen := make(map[[2]string]bool, len(prefs))
+	for _, pref := range prefs {
+		chosen[[2]string{pref.Type, pref.Channel}] = pref.Enabled
+	}
+
+	types := make([]string, 0, len(domain.NotificationTypes))
+	for notificationType := range domain.NotificationTypes {
+		types = append(types, notificationType)
+	}
+	sort.Strings(types)
+	channels := make([]string, 0, len(u.channels))
+	for channel := range u.channels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	responses := make([]*domain.NotificationPreferenceResponse, 0, len(types)*len(channels))
+	for _, notificationType := range types {
+		for _, channel := range channels {
+			enabled, ok := chosen[[2]string{notificationType, channel}]
+			if !ok {
+				enabled = slices.Contains(domain.NotificationTypes[notificationType], channel)
+			}
+			responses = append(responses, &domain.NotificationPreferenceResponse{
+				Type:    notificationType,
+				Channel: channel,
+				Enabled: enabled,
+				Default: !ok,
+			})
+		}
+	}
+	return responses
+}
+
+// emailNotificationChannel queues notifications in the email outbox
+type emailNotificationChannel struct {
+	outbox *EmailOutbox
+	repo   repository.EmailRepository
+}
+
+// NewEmailNotificationChannel sends notifications as emails queued in repo
+func NewEmailNotificationChannel(outbox *EmailOutbox, repo repository.EmailRepository) NotificationChannel {
+	return &emailNotificationChannel{outbox: outbox, repo: repo}
+}
+
+// Name returns the channel name (implements NotificationChannel)
+func (c *emailNotificationChannel) Name() string { return domain.NotificationChannelEmail }
+
+// Send queues the notification email (implements NotificationChannel)
+func (c *emailNotificationChannel) Send(ctx context.Context, user *domain.User, msg *domain.NotificationMessage) error {
+	_, err := c.outbox.Queue(ctx, c.repo, user.Email, user.Locale, email.NotificationData{
+		Name:      user.Name,
+		Subject:   msg.Subject,
+		Message:   msg.Message,
+		ActionURL: msg.URL,
+	})
+	return err
+}
+
+// webhookNotificationChannel dispatches notifications to webhook subscribers
+type webhookNotificationChannel struct {
+	webhooks WebhookUsecase
+}
+
+// NewWebhookNotificationChannel sends notifications as notification.created
+// webhook events
+func NewWebhookNotificationChannel(webhooks WebhookUsecase) NotificationChannel {
+	return &webhookNotificationChannel{webhooks: webhooks}
+}
+
+// Name returns the channel name (implements NotificationChannel)
+func (c *webhookNotificationChannel) Name() string { return domain.NotificationChannelWebhook }
+
+// Send queues the webhook deliveries (implements NotificationChannel)
+func (c *webhookNotificationChannel) Send(ctx context.Context, user *domain.User, msg *domain.NotificationMessage) error {
+	return c.webhooks.Dispatch(ctx, domain.EventNotificationCreated, msg)
+}
+
+// NotificationMessageType is the realtime message type pushed for new in-app
+// notifications
+const NotificationMessageType = "notification"
+
+// inAppNotificationChannel stores notifications in the user's inbox
+type inAppNotificationChannel struct {
+	repo repository.NotificationRepository
+	hub  *realtime.Hub
+}
+
+// NewInAppNotificationChannel sends notifications to the in-app inbox stored
+// in repo and pushes them to the user's open WebSocket connections on hub,
+// which may be nil. The push is best effort: connections held by other
+// instances miss it, so clients still reconcile from the inbox.
+func NewInAppNotificationChannel(repo repository.NotificationRepository, hub *realtime.Hub) NotificationChannel {
+	return &inAppNotificationChannel{repo: repo, hub: hub}
+}
+
+// Name returns the channel name (implements NotificationChannel)
+func (c *inAppNotificationChannel) Name() string { return domain.NotificationChannelInApp }
+
+// Send adds the notification to the user's inbox (implements NotificationChannel)
+func (c *inAppNotificationChannel) Send(ctx context.Context, user *domain.User, msg *domain.NotificationMessage) error {
+	payload, err := json.Marshal(domain.NotificationPayload{
+		Subject: msg.Subject,
+		Message: msg.Message,
+		URL:     msg.URL,
+		Data:    msg.Data,
+	})
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("failed to encode notification: %w", err))
+	}
+	notification := &domain.Notification{
+		UserID:  user.ID,
+		Type:    msg.Type,
+		Payload: string(payload),
+	}
+	if err := c.repo.Create(ctx, notification); err != nil {
+		return err
+	}
+	c.hub.SendToUser(database.TenantID(ctx), user.ID, realtime.Message{
+		Type: NotificationMessageType,
+		Data: toNotificationResponse(notification),
+	})
+	return nil
+}
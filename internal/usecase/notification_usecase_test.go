@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/realtime"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingChannel records the notifications sent on it
+type recordingChannel struct {
+	name string
+	mu   sync.Mutex
+	sent []string
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(ctx context.Context, user *domain.User, msg *domain.NotificationMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, user.Email+" "+msg.Type)
+	return nil
+}
+
+func (c *recordingChannel) Sent() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.sent...)
+}
+
+func TestNotificationUsecase_FansOutToEnabledChannels(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com"}
+	require.NoError(t, repos.Users.Create(ctx, alice))
+
+	emailChannel := &recordingChannel{name: domain.NotificationChannelEmail}
+	webhookChannel := &recordingChannel{name: domain.NotificationChannelWebhook}
+	notifications := NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, jobs.NewQueue(repos.Jobs), emailChannel, webhookChannel)
+
+	// Alice turns off webhooks for security alerts and turns on email for account activity
+	_, err := notifications.UpdatePreferences(ctx, alice.ID, &domain.NotificationPreferencesRequest{Preferences: []domain.NotificationPreferenceUpdate{
+		{Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelWebhook, Enabled: false},
+		{Type: domain.NotificationAccountActivity, Channel: domain.NotificationChannelEmail, Enabled: true},
+	}})
+	require.NoError(t, err)
+
+	require.NoError(t, notifications.Notify(ctx, &domain.NotificationMessage{Type: domain.NotificationSecurityAlert, UserID: alice.ID, Subject: "New sign-in", Message: "New sign-in from Firefox"}))
+	require.NoError(t, notifications.Notify(ctx, &domain.NotificationMessage{Type: domain.NotificationAccountActivity, UserID: alice.ID, Subject: "Export ready", Message: "Your export is ready"}))
+	assert.Empty(t, emailChannel.Sent(), "notifications are sent asynchronously")
+
+	pool := jobs.NewPool(repos.Jobs, jobs.WithPollInterval(10*time.Millisecond))
+	notifications.RegisterJobs(pool)
+	go pool.Start()
+	defer pool.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(emailChannel.Sent()) == 2 && len(webhookChannel.Sent()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{"alice@example.com security_alert", "alice@example.com account_activity"}, emailChannel.Sent())
+	assert.Equal(t, []string{"alice@example.com account_activity"}, webhookChannel.Sent())
+}
+
+func TestNotificationUsecase_Preferences(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	notifications := NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, jobs.NewQueue(repos.Jobs),
+		&recordingChannel{name: domain.NotificationChannelEmail})
+
+	prefs, err := notifications.GetPreferences(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.NotificationPreferenceResponse{
+		{Type: domain.NotificationAccountActivity, Channel: domain.NotificationChannelEmail, Enabled: false, Default: true},
+		{Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelEmail, Enabled: true, Default: true},
+	}, prefs, "only configured channels are listed")
+
+	prefs, err = notifications.UpdatePreferences(ctx, 1, &domain.NotificationPreferencesRequest{Preferences: []domain.NotificationPreferenceUpdate{
+		{Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelEmail, Enabled: false},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, &domain.NotificationPreferenceResponse{Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelEmail}, prefs[1])
+
+	_, err = notifications.UpdatePreferences(ctx, 1, &domain.NotificationPreferencesRequest{Preferences: []domain.NotificationPreferenceUpdate{
+		{Type: domain.NotificationSecurityAlert, Channel: domain.NotificationChannelWebhook, Enabled: true},
+	}})
+	assert.EqualError(t, err, "unknown notification channel: webhook")
+
+	err = notifications.Notify(ctx, &domain.NotificationMessage{Type: "newsletter", UserID: 1})
+	assert.EqualError(t, err, "unknown notification type: newsletter")
+}
+
+func TestEmailNotificationChannel_Send(t *testing.T) {
+	renderer, err := email.NewRenderer(email.Brand{Name: "Acme", URL: "https://acme.example"})
+	require.NoError(t, err)
+	repo := memory.NewEmailRepository()
+	channel := NewEmailNotificationChannel(NewEmailOutbox(renderer), repo)
+
+	user := &domain.User{Name: "Alice", Email: "alice@example.com"}
+	require.NoError(t, channel.Send(context.Background(), user, &domain.NotificationMessage{
+		Type:    domain.NotificationSecurityAlert,
+		Subject: "Your password was changed",
+		Message: "The password of your account was just changed.",
+	}))
+
+	queued, err := repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	require.NotNil(t, queued)
+	assert.Equal(t, "alice@example.com", queued.To)
+	assert.Equal(t, "notification", queued.Template)
+	assert.Equal(t, "Your password was changed", queued.Subject)
+}
+
+func TestNotificationUsecase_Inbox(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com"}
+	require.NoError(t, repos.Users.Create(ctx, alice))
+
+	notifications := NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, jobs.NewQueue(repos.Jobs),
+		NewInAppNotificationChannel(repos.Notifications, nil))
+	pool := jobs.NewPool(repos.Jobs, jobs.WithPollInterval(10*time.Millisecond))
+	notifications.RegisterJobs(pool)
+	go pool.Start()
+	defer pool.Stop()
+
+	require.NoError(t, notifications.Notify(ctx, &domain.NotificationMessage{
+		Type: domain.NotificationSecurityAlert, UserID: alice.ID, Subject: "Your password was changed", Message: "Reset it if this wasn't you",
+		Data: map[string]interface{}{"ip": "203.0.113.7"},
+	}))
+	require.NoError(t, notifications.Notify(ctx, &domain.NotificationMessage{Type: domain.NotificationAccountActivity, UserID: alice.ID, Subject: "Export ready"}))
+	require.Eventually(t, func() bool {
+		count, err := notifications.UnreadCount(ctx, alice.ID)
+		return err == nil && count == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	inbox, err := notifications.ListNotifications(ctx, alice.ID, false, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, inbox, 2)
+	alert := inbox[0]
+	if alert.Type != domain.NotificationSecurityAlert {
+		alert = inbox[1]
+	}
+	assert.Equal(t, "Your password was changed", alert.Payload.Subject)
+	assert.Equal(t, "203.0.113.7", alert.Payload.Data["ip"])
+	assert.False(t, alert.Read)
+
+	read, err := notifications.MarkRead(ctx, alice.ID, alert.ID)
+	require.NoError(t, err)
+	assert.True(t, read.Read)
+	assert.NotNil(t, read.ReadAt)
+
+	unread, err := notifications.ListNotifications(ctx, alice.ID, true, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, unread, 1)
+	assert.Equal(t, domain.NotificationAccountActivity, unread[0].Type)
+
+	_, err = notifications.MarkRead(ctx, alice.ID+1, alert.ID)
+	assert.EqualError(t, err, "notification not found")
+}
+
+func TestInAppNotificationChannel_PushesToTenantUser(t *testing.T) {
+	repos := memory.NewRepositories()
+	hub := realtime.NewHub()
+	t.Cleanup(hub.Shutdown)
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		hub.Register("acme", 42, conn)
+	}))
+	t.Cleanup(server.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.Eventually(t, func() bool { return hub.ConnectionCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	channel := NewInAppNotificationChannel(repos.Notifications, hub)
+	user := &domain.User{ID: 42}
+	msg := &domain.NotificationMessage{Type: domain.NotificationAccountActivity, UserID: 42, Subject: "Export ready"}
+
+	// User 42 of another tenant is a different user
+	require.NoError(t, channel.Send(database.WithTenant(context.Background(), "globex", nil), user, msg))
+	require.NoError(t, channel.Send(database.WithTenant(context.Background(), "acme", nil), user, msg))
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var pushed struct {
+		Type string                      `json:"type"`
+		Data domain.NotificationResponse `json:"data"`
+	}
+	require.NoError(t, conn.ReadJSON(&pushed))
+	assert.Equal(t, NotificationMessageType, pushed.Type)
+	assert.Equal(t, "Export ready", pushed.Data.Payload.Subject)
+
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	assert.Error(t, conn.ReadJSON(&pushed), "only one notification is pushed")
+}
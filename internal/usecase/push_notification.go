@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webpush"
+)
+
+// PushSendJob sends a push notification to one subscription, so a slow or
+// failing push service only holds up the browsers it serves
+const PushSendJob = "notification.push"
+
+// ErrPushUnavailable is returned by the push subscription methods when no
+// push channel is configured
+var ErrPushUnavailable = domain.NewError(domain.ErrUnavailable, "push notifications are not enabled")
+
+// PushSender sends Web Push messages; *webpush.Sender implements it
+type PushSender interface {
+	Send(ctx context.Context, sub *webpush.Subscription, payload []byte) error
+	// PublicKey returns the VAPID public key browsers subscribe with
+	PublicKey() string
+}
+
+// pushNotificationChannel sends notifications to the user's browsers
+type pushNotificationChannel struct {
+	sender PushSender
+	repo   repository.PushSubscriptionRepository
+	queue  jobs.Enqueuer
+}
+
+// NewPushNotificationChannel sends notifications as Web Push messages to the
+// subscriptions stored in repo, one PushSendJob per subscription. Passing it
+// to NewNotificationUsecase also enables the push subscription methods.
+func NewPushNotificationChannel(sender PushSender, repo repository.PushSubscriptionRepository, queue jobs.Enqueuer) NotificationChannel {
+	return &pushNotificationChannel{sender: sender, repo: repo, queue: queue}
+}
+
+// pushMessage is the JSON payload service workers receive
+type pushMessage struct {
+	Type string `json:"type"`
+	domain.NotificationPayload
+}
+
+// pushSend is the payload of a push send job
+type pushSend struct {
+	SubscriptionID uint            `json:"subscription_id"`
+	Message        json.RawMessage `json:"message"`
+}
+
+// Name returns the channel name (implements NotificationChannel)
+func (c *pushNotificationChannel) Name() string { return domain.NotificationChannelPush }
+
+// Send queues a push job for each of the user's subscriptions (implements
+// NotificationChannel)
+func (c *pushNotificationChannel) Send(ctx context.Context, user *domain.User, msg *domain.NotificationMessage) error {
+	subs, err := c.repo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get push subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	message, err := json.Marshal(pushMessage{
+		Type: msg.Type,
+		NotificationPayload: domain.NotificationPayload{
+			Subject: msg.Subject,
+			Message: msg.Message,
+			URL:     msg.URL,
+			Data:    msg.Data,
+		},
+	})
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("failed to encode push notification: %w", err))
+	}
+	if len(message) > webpush.MaxPayloadSize {
+		return jobs.Permanent(fmt.Errorf("push notification is %d bytes, more than %d", len(message), webpush.MaxPayloadSize))
+	}
+	for _, sub := range subs {
+		if _, err := c.queue.Enqueue(ctx, PushSendJob, pushSend{SubscriptionID: sub.ID, Message: message}); err != nil {
+			return fmt.Errorf("failed to queue push notification: %w", err)
+		}
+	}
+	return nil
+}
+
+// deliver sends one push message. Subscriptions the push service no longer
+// knows are deleted, and messages for deleted subscriptions are dropped.
+func (c *pushNotificationChannel) deliver(ctx context.Context, job *domain.Job) error {
+	var payload pushSend
+	if err := jobs.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+	sub, err := c.repo.GetByID(ctx, payload.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get push subscription: %w", err)
+	}
+	if sub == nil {
+		return nil
+	}
+
+	err = c.sender.Send(ctx, &webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		Keys:     webpush.Keys{P256dh: sub.P256dh, Auth: sub.Auth},
+	}, payload.Message)
+	var apiErr *webpush.APIError
+	switch {
+	case errors.Is(err, webpush.ErrGone):
+		logger.FromContext(ctx).Info("Deleting expired push subscription", "subscription_id", sub.ID, "user_id", sub.UserID)
+		if err := c.repo.Delete(ctx, sub.ID); err != nil {
+			return fmt.Errorf("failed to delete push subscription: %w", err)
+		}
+		return nil
+	case errors.As(err, &apiErr) && !apiErr.Temporary():
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// SubscribePush stores the browser's push subscription for the user. A
+// browser subscribing again, even for another user, replaces its earlier
+// subscription.
+func (u *notificationUsecase) SubscribePush(ctx context.Context, userID uint, req *domain.PushSubscriptionRequest, userAgent string) (*domain.PushSubscription, error) {
+	if u.push == nil {
+		return nil, ErrPushUnavailable
+	}
+	endpoint, err := url.Parse(req.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" || len(req.Endpoint) > 700 {
+		return nil, domain.NewError(domain.ErrInvalid, "invalid push subscription endpoint")
+	}
+	keys := webpush.Keys{P256dh: req.Keys.P256dh, Auth: req.Keys.Auth}
+	if len(keys.P256dh) > 255 || len(keys.Auth) > 255 || keys.Validate() != nil {
+		return nil, domain.NewError(domain.ErrInvalid, "invalid push subscription keys")
+	}
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	sub := &domain.PushSubscription{
+		UserID:    userID,
+		Endpoint:  req.Endpoint,
+		P256dh:    keys.P256dh,
+		Auth:      keys.Auth,
+		UserAgent: userAgent,
+	}
+	if err := u.push.repo.Upsert(ctx, sub); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to store push subscription: %w", err))
+	}
+	return sub, nil
+}
+
+// ListPushSubscriptions returns the user's push subscriptions
+func (u *notificationUsecase) ListPushSubscriptions(ctx context.Context, userID uint) ([]*domain.PushSubscription, error) {
+	if u.push == nil {
+		return nil, ErrPushUnavailable
+	}
+	subs, err := u.push.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get push subscriptions: %w", err))
+	}
+	return subs, nil
+}
+
+// DeletePushSubscription removes one of the user's push subscriptions
+func (u *notificationUsecase) DeletePushSubscription(ctx context.Context, userID, id uint) error {
+	if u.push == nil {
+		return ErrPushUnavailable
+	}
+	sub, err := u.push.repo.GetByID(ctx, id)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get push subscription: %w", err))
+	}
+	if sub == nil || sub.UserID != userID {
+		return domain.ErrPushSubscriptionNotFound
+	}
+	if err := u.push.repo.Delete(ctx, id); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to delete push subscription: %w", err))
+	}
+	return nil
+}
+
+// PushPublicKey returns the VAPID public key browsers subscribe with
+func (u *notificationUsecase) PushPublicKey() (string, error) {
+	if u.push == nil {
+		return "", ErrPushUnavailable
+	}
+	return u.push.sender.PublicKey(), nil
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webpush"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPushSender records push messages by endpoint; endpoints in gone
+// answer webpush.ErrGone
+type recordingPushSender struct {
+	mu   sync.Mutex
+	sent map[string][]byte
+	gone map[string]bool
+}
+
+func (s *recordingPushSender) Send(ctx context.Context, sub *webpush.Subscription, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gone[sub.Endpoint] {
+		return webpush.ErrGone
+	}
+	s.sent[sub.Endpoint] = payload
+	return nil
+}
+
+func (s *recordingPushSender) PublicKey() string { return "vapid-public-key" }
+
+func (s *recordingPushSender) Sent() map[string][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent := make(map[string][]byte, len(s.sent))
+	for endpoint, payload := range s.sent {
+		sent[endpoint] = payload
+	}
+	return sent
+}
+
+// pushSubscriptionRequest returns a subscription with valid keys
+func pushSubscriptionRequest(t *testing.T, endpoint string) *domain.PushSubscriptionRequest {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &domain.PushSubscriptionRequest{Endpoint: endpoint, Keys: domain.PushSubscriptionKeys{
+		P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:   base64.RawURLEncoding.EncodeToString(auth),
+	}}
+}
+
+func TestNotificationUsecase_Push(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com"}
+	require.NoError(t, repos.Users.Create(ctx, alice))
+
+	queue := jobs.NewQueue(repos.Jobs)
+	sender := &recordingPushSender{sent: map[string][]byte{}, gone: map[string]bool{"https://push.example.com/old": true}}
+	notifications := NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, queue,
+		NewPushNotificationChannel(sender, repos.PushSubscriptions, queue))
+
+	key, err := notifications.PushPublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, "vapid-public-key", key)
+
+	laptop, err := notifications.SubscribePush(ctx, alice.ID, pushSubscriptionRequest(t, "https://push.example.com/laptop"), "Firefox")
+	require.NoError(t, err)
+	assert.Equal(t, "Firefox", laptop.UserAgent)
+	old, err := notifications.SubscribePush(ctx, alice.ID, pushSubscriptionRequest(t, "https://push.example.com/old"), "")
+	require.NoError(t, err)
+
+	_, err = notifications.SubscribePush(ctx, alice.ID, pushSubscriptionRequest(t, "http://push.example.com/insecure"), "")
+	assert.EqualError(t, err, "invalid push subscription endpoint")
+	bad := pushSubscriptionRequest(t, "https://push.example.com/bad")
+	bad.Keys.Auth = "c2hvcnQ"
+	_, err = notifications.SubscribePush(ctx, alice.ID, bad, "")
+	assert.EqualError(t, err, "invalid push subscription keys")
+
+	require.NoError(t, notifications.Notify(ctx, &domain.NotificationMessage{Type: domain.NotificationSecurityAlert, UserID: alice.ID, Subject: "New sign-in", Message: "New sign-in from Firefox", URL: "https://app.example.com/security"}))
+
+	pool := jobs.NewPool(repos.Jobs, jobs.WithPollInterval(10*time.Millisecond))
+	notifications.RegisterJobs(pool)
+	go pool.Start()
+	defer pool.Stop()
+
+	require.Eventually(t, func() bool {
+		subs, err := notifications.ListPushSubscriptions(ctx, alice.ID)
+		return err == nil && len(subs) == 1 && len(sender.Sent()) == 1
+	}, 2*time.Second, 10*time.Millisecond, "the gone subscription is deleted")
+
+	var message map[string]interface{}
+	require.NoError(t, json.Unmarshal(sender.Sent()["https://push.example.com/laptop"], &message))
+	assert.Equal(t, map[string]interface{}{
+		"type":    domain.NotificationSecurityAlert,
+		"subject": "New sign-in",
+		"message": "New sign-in from Firefox",
+		"url":     "https://app.example.com/security",
+	}, message)
+
+	assert.EqualError(t, notifications.DeletePushSubscription(ctx, alice.ID, old.ID), "push subscription not found")
+	assert.EqualError(t, notifications.DeletePushSubscription(ctx, alice.ID+1, laptop.ID), "push subscription not found")
+	require.NoError(t, notifications.DeletePushSubscription(ctx, alice.ID, laptop.ID))
+}
+
+func TestNotificationUsecase_PushUnavailable(t *testing.T) {
+	repos := memory.NewRepositories()
+	notifications := NewNotificationUsecase(repos.Users, repos.NotificationPreferences, repos.Notifications, jobs.NewQueue(repos.Jobs))
+
+	_, err := notifications.PushPublicKey()
+	assert.ErrorIs(t, err, ErrPushUnavailable)
+	_, err = notifications.SubscribePush(context.Background(), 1, pushSubscriptionRequest(t, "https://push.example.com/a"), "")
+	assert.ErrorIs(t, err, ErrPushUnavailable)
+}
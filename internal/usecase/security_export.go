@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/siem"
+)
+
+// SIEM event types
+const (
+	SIEMAuditRequest    = "audit.request"
+	SIEMAuthLoginFailed = "auth.login_failed"
+)
+
+// SecurityExport ships the audit log and security events, such as failed
+// logins, to a SIEM through an exporter
+type SecurityExport struct {
+	exporter *siem.Exporter
+}
+
+// NewSecurityExport creates an export writing to exporter
+func NewSecurityExport(exporter *siem.Exporter) *SecurityExport {
+	return &SecurityExport{exporter: exporter}
+}
+
+// AuditRepository wraps audit so the entries it appends are exported too
+func (s *SecurityExport) AuditRepository(audit repository.AuditRepository) repository.AuditRepository {
+	return &exportedAuditRepository{AuditRepository: audit, export: s}
+}
+
+// Subscribe exports the security events published on bus
+func (s *SecurityExport) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "siem_export", func(ctx context.Context, e events.LoginFailed) error {
+		attributes := map[string]any{"email": e.Email, "reason": e.Reason}
+		if e.User != nil {
+			attributes["user_id"] = e.User.ID
+		}
+		s.export(ctx, siem.Event{
+			Type:       SIEMAuthLoginFailed,
+			Severity:   siem.SeverityWarning,
+			Message:    "Failed login for " + e.Email,
+			Attributes: attributes,
+		})
+		return nil
+	})
+}
+
+// exportAudit exports an audit log entry. Requests refused for lack of
+// authentication or permission are warnings.
+func (s *SecurityExport) exportAudit(ctx context.Context, entry *domain.AuditLog) {
+	severity := siem.SeverityInfo
+	if entry.Status == http.StatusUnauthorized || entry.Status == http.StatusForbidden {
+		severity = siem.SeverityWarning
+	}
+	event := siem.Event{
+		Time:      entry.CreatedAt,
+		Type:      SIEMAuditRequest,
+		Severity:  severity,
+		Message:   fmt.Sprintf("%s %s %d", entry.Method, entry.Route, entry.Status),
+		ClientIP:  entry.ClientIP,
+		RequestID: entry.RequestID,
+		Attributes: map[string]any{
+			"method":       entry.Method,
+			"path":         entry.Path,
+			"route":        entry.Route,
+			"status":       entry.Status,
+			"payload_hash": entry.PayloadHash,
+		},
+	}
+	if entry.ActorID != nil {
+		event.ActorID = strconv.FormatUint(uint64(*entry.ActorID), 10)
+	}
+	s.export(ctx, event)
+}
+
+// export adds the request's tenant to event and queues it
+func (s *SecurityExport) export(ctx context.Context, event siem.Event) {
+	if tenant := database.TenantID(ctx); tenant != "" {
+		if event.Attributes == nil {
+			event.Attributes = make(map[string]any)
+		}
+		event.Attributes["tenant"] = tenant
+	}
+	s.exporter.Export(event)
+}
+
+// exportedAuditRepository exports the entries appended to an audit
+// repository
+type exportedAuditRepository struct {
+	repository.AuditRepository
+	export *SecurityExport
+}
+
+// Create appends entry and exports it. It is exported even when storing it
+// fails, since the SIEM copy is the one that matters then.
+func (r *exportedAuditRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	err := r.AuditRepository.Create(ctx, entry)
+	r.export.exportAudit(ctx, entry)
+	return err
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/siem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink collects the events written to it
+type recordingSink struct {
+	mu     sync.Mutex
+	events []siem.Event
+}
+
+func (s *recordingSink) Write(_ context.Context, events []siem.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func TestSecurityExport(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	sink := &recordingSink{}
+	exporter := siem.NewExporter(sink, siem.ExporterConfig{Source: "api-1", Logger: logger.Discard()})
+	export := NewSecurityExport(exporter)
+	bus := events.NewBus()
+	export.Subscribe(bus)
+	users := NewUserUsecase(repos.Users, "secret", WithEventBus(bus))
+
+	_, err := users.Register(ctx, &domain.UserRequest{Name: "Ada", Email: "ada@example.com", Password: "secret123"})
+	require.NoError(t, err)
+	_, err = users.Login(ctx, &domain.LoginRequest{Email: "ada@example.com", Password: "wrong"})
+	require.Error(t, err)
+	_, err = users.Login(ctx, &domain.LoginRequest{Email: "bob@example.com", Password: "wrong"})
+	require.Error(t, err)
+	_, err = users.Login(ctx, &domain.LoginRequest{Email: "ada@example.com", Password: "secret123"})
+	require.NoError(t, err)
+
+	actor := uint(7)
+	audit := export.AuditRepository(repos.Audit)
+	require.NoError(t, audit.Create(ctx, &domain.AuditLog{
+		ActorID: &actor, Method: "DELETE", Path: "/api/users/3", Route: "/api/users/{id}", Status: 403, ClientIP: "203.0.113.9",
+	}))
+	stored, err := repos.Audit.List(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, stored, 1, "entries are still stored")
+
+	require.NoError(t, exporter.Close())
+	require.Len(t, sink.events, 3)
+	assert.Equal(t, SIEMAuthLoginFailed, sink.events[0].Type)
+	assert.Equal(t, siem.SeverityWarning, sink.events[0].Severity)
+	assert.Equal(t, events.LoginWrongPassword, sink.events[0].Attributes["reason"])
+	assert.NotNil(t, sink.events[0].Attributes["user_id"])
+	assert.Equal(t, events.LoginUnknownEmail, sink.events[1].Attributes["reason"])
+	assert.NotContains(t, sink.events[1].Attributes, "user_id")
+
+	assert.Equal(t, SIEMAuditRequest, sink.events[2].Type)
+	assert.Equal(t, "DELETE /api/users/{id} 403", sink.events[2].Message)
+	assert.Equal(t, siem.SeverityWarning, sink.events[2].Severity)
+	assert.Equal(t, "7", sink.events[2].ActorID)
+	assert.Equal(t, "203.0.113.9", sink.events[2].ClientIP)
+	assert.Equal(t, "api-1", sink.events[2].Source)
+}
@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/aungmyozaw92/go-api-setup/pkg/sms"
+)
+
+const (
+	// smsCodeDigits is the length of texted codes
+	smsCodeDigits = 6
+	// smsMaxAttempts is how many wrong guesses use up a code
+	smsMaxAttempts = 5
+	// smsResendInterval is how long a user waits before another code is
+	// texted for the same purpose, so the endpoints can't be used to flood
+	// a phone with messages
+	smsResendInterval = time.Minute
+)
+
+// smsVerification is how codes are texted and stored
+type smsVerification struct {
+	sender sms.Sender
+	codes  repository.VerificationCodeRepository
+	ttl    time.Duration
+}
+
+// WithSMS texts one-time codes through sender for phone verification and
+// two-factor login. Codes are stored hashed in codes and expire after ttl.
+func WithSMS(sender sms.Sender, codes repository.VerificationCodeRepository, ttl time.Duration) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.sms = &smsVerification{sender: sender, codes: codes, ttl: ttl}
+	}
+}
+
+// SendPhoneVerification texts a code to the user's phone number, which must
+// be in E.164 format
+func (u *userUsecase) SendPhoneVerification(ctx context.Context, userID uint) error {
+	if u.sms == nil {
+		return domain.ErrSMSUnavailable
+	}
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return domain.ErrUserNotFound
+	}
+	if user.Phone == "" {
+		return domain.ErrPhoneRequired
+	}
+	if err := sms.ValidateNumber(user.Phone); err != nil {
+		return domain.NewError(domain.ErrInvalid, "phone number must be in E.164 format, such as +14155550100")
+	}
+
+	sent, err := u.sendCode(ctx, user, domain.VerificationPurposePhone, "Your verification code is %s. It expires in %d minutes.")
+	if err != nil {
+		return err
+	}
+	if !sent {
+		return domain.ErrCodeRecentlySent
+	}
+	return nil
+}
+
+// VerifyPhone checks the code texted by SendPhoneVerification and records
+// the user's phone number as verified
+func (u *userUsecase) VerifyPhone(ctx context.Context, userID uint, code string) error {
+	if u.sms == nil {
+		return domain.ErrSMSUnavailable
+	}
+	user, err := u.userRepo.GetByID(cache.WithBypass(ctx), userID)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return domain.ErrUserNotFound
+	}
+	if err := u.checkCode(ctx, user, domain.VerificationPurposePhone, code); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to verify phone: %w", err))
+	}
+	u.invalidateUser(ctx, user.ID, user.Email)
+	return nil
+}
+
+// SetTwoFactor turns two-factor login on or off. Turning it on needs a
+// verified phone number, which the codes are texted to.
+func (u *userUsecase) SetTwoFactor(ctx context.Context, userID uint, enabled bool) error {
+	user, err := u.userRepo.GetByID(cache.WithBypass(ctx), userID)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return domain.ErrUserNotFound
+	}
+	if enabled {
+		if u.sms == nil {
+			return domain.ErrSMSUnavailable
+		}
+		if user.PhoneVerifiedAt == nil {
+			return domain.NewError(domain.ErrInvalid, "verify your phone number before turning on two-factor login")
+		}
+	}
+	if user.TwoFactorEnabled == enabled {
+		return nil
+	}
+
+	user.TwoFactorEnabled = enabled
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to update two-factor login: %w", err))
+	}
+	u.invalidateUser(ctx, user.ID, user.Email)
+
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	u.notify(ctx, &domain.NotificationMessage{
+		Type:    domain.NotificationSecurityAlert,
+		UserID:  user.ID,
+		Subject: "Two-factor login was turned " + state,
+		Message: "Two-factor login for your account was just turned " + state + ". If you didn't do this, reset your password right away.",
+	})
+	return nil
+}
+
+// startTwoFactorLogin texts a login code to the user, unless one was sent
+// moments ago and is still valid. It returns a TwoFactorRequiredError with
+// the challenge the client completes the login with.
+func (u *userUsecase) startTwoFactorLogin(ctx context.Context, user *domain.User) error {
+	if u.sms == nil || user.PhoneVerifiedAt == nil {
+		// Failing closed: without SMS the second factor can't be checked
+		return internalError(ctx, fmt.Errorf("two-factor login is on for user %d but no code can be texted", user.ID))
+	}
+	if _, err := u.sendCode(ctx, user, domain.VerificationPurposeLogin, "Your login code is %s. It expires in %d minutes. Don't share it with anyone."); err != nil {
+		return err
+	}
+	return &domain.TwoFactorRequiredError{ChallengeToken: u.loginChallenge(user.ID, time.Now().Add(u.sms.ttl))}
+}
+
+// CompleteTwoFactorLogin finishes a login that returned a
+// TwoFactorRequiredError, given its challenge token and the texted code
+func (u *userUsecase) CompleteTwoFactorLogin(ctx context.Context, req *domain.TwoFactorLoginRequest) (*domain.LoginResponse, error) {
+	if u.sms == nil {
+		return nil, domain.ErrSMSUnavailable
+	}
+	userID, err := u.checkLoginChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, domain.ErrInvalidLoginCode
+	}
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil || !user.TwoFactorEnabled {
+		return nil, domain.ErrInvalidLoginCode
+	}
+	if err := u.checkCode(ctx, user, domain.VerificationPurposeLogin, req.Code); err != nil {
+		if errors.Is(err, domain.ErrInvalidCode) {
+			u.bus.Publish(ctx, events.LoginFailed{Email: user.Email, User: user, Reason: events.LoginWrongCode})
+			return nil, domain.ErrInvalidLoginCode
+		}
+		return nil, err
+	}
+	return u.issueToken(ctx, user)
+}
+
+// sendCode stores a new code for purpose and texts it to the user; format
+// gets the code and the minutes it is valid. It reports false without sending
+// when the previous code was sent less than smsResendInterval ago.
+func (u *userUsecase) sendCode(ctx context.Context, user *domain.User, purpose, format string) (bool, error) {
+	previous, err := u.sms.codes.Get(ctx, user.ID, purpose)
+	if err != nil {
+		return false, internalError(ctx, fmt.Errorf("failed to get verification code: %w", err))
+	}
+	if previous != nil && time.Since(previous.CreatedAt) < smsResendInterval && time.Now().Before(previous.ExpiresAt) {
+		return false, nil
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return false, internalError(ctx, fmt.Errorf("failed to generate verification code: %w", err))
+	}
+	stored := &domain.VerificationCode{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		CodeHash:  u.hashCode(user.ID, purpose, code),
+		ExpiresAt: time.Now().Add(u.sms.ttl),
+	}
+	if err := u.sms.codes.Replace(ctx, stored); err != nil {
+		return false, internalError(ctx, fmt.Errorf("failed to store verification code: %w", err))
+	}
+
+	msg := &sms.Message{To: user.Phone, Body: fmt.Sprintf(format, code, int(u.sms.ttl.Minutes()))}
+	if err := u.sms.sender.Send(ctx, msg); err != nil {
+		// Nobody received the code, so don't hold off a retry
+		if err := u.sms.codes.Delete(ctx, stored.ID); err != nil {
+			return false, internalError(ctx, fmt.Errorf("failed to delete verification code: %w", err))
+		}
+		return false, internalError(ctx, fmt.Errorf("failed to send SMS: %w", err))
+	}
+	return true, nil
+}
+
+// checkCode compares code with the user's stored code for purpose. A match
+// uses the code up; wrong guesses count against it until smsMaxAttempts.
+func (u *userUsecase) checkCode(ctx context.Context, user *domain.User, purpose, code string) error {
+	stored, err := u.sms.codes.Get(ctx, user.ID, purpose)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get verification code: %w", err))
+	}
+	if stored == nil {
+		return domain.ErrInvalidCode
+	}
+	// The guess is counted before it is compared, so concurrent guesses
+	// can't all slip in under the limit
+	counted := false
+	if !time.Now().After(stored.ExpiresAt) {
+		counted, err = u.sms.codes.IncrementAttempts(ctx, stored.ID, smsMaxAttempts)
+		if err != nil {
+			return internalError(ctx, fmt.Errorf("failed to count verification attempt: %w", err))
+		}
+	}
+	if !counted {
+		if err := u.sms.codes.Delete(ctx, stored.ID); err != nil {
+			return internalError(ctx, fmt.Errorf("failed to delete verification code: %w", err))
+		}
+		return domain.ErrInvalidCode
+	}
+
+	code = strings.TrimSpace(code)
+	if !hmac.Equal([]byte(stored.CodeHash), []byte(u.hashCode(user.ID, purpose, code))) {
+		return domain.ErrInvalidCode
+	}
+	if err := u.sms.codes.Delete(ctx, stored.ID); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to delete verification code: %w", err))
+	}
+	return nil
+}
+
+// hashCode returns the stored form of a code: an HMAC keyed with the JWT
+// secret, as six digits are too few to survive plain hashing
+func (u *userUsecase) hashCode(userID uint, purpose, code string) string {
+	return u.sign("sms-code\n" + strconv.FormatUint(uint64(userID), 10) + "\n" + purpose + "\n" + code)
+}
+
+// loginChallenge returns a token naming the user a login code was texted
+// to: "<user ID>.<expiry>.<signature>"
+func (u *userUsecase) loginChallenge(userID uint, expires time.Time) string {
+	payload := strconv.FormatUint(uint64(userID), 10) + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + u.sign("login-challenge\n"+payload)
+}
+
+// checkLoginChallenge returns the user ID of a valid, unexpired login challenge
+func (u *userUsecase) checkLoginChallenge(token string) (uint, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, domain.ErrInvalidCode
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(u.sign("login-challenge\n"+payload))) {
+		return 0, domain.ErrInvalidCode
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return 0, domain.ErrInvalidCode
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil || id == 0 {
+		return 0, domain.ErrInvalidCode
+	}
+	return uint(id), nil
+}
+
+// sign returns the base64url HMAC-SHA256 of data keyed with the JWT secret
+func (u *userUsecase) sign(data string) string {
+	mac := hmac.New(sha256.New, []byte(u.jwtSecret))
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomCode returns a uniformly random code of smsCodeDigits digits
+func randomCode() (string, error) {
+	limit := big.NewInt(1)
+	for range smsCodeDigits {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", smsCodeDigits, n), nil
+}
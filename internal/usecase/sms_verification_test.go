@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/sms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lastCode returns the code in the newest message texted to the recorder
+func lastCode(t *testing.T, texts *sms.Recorder) string {
+	t.Helper()
+	messages := texts.Messages()
+	require.NotEmpty(t, messages)
+	code := regexp.MustCompile(`\b[0-9]{6}\b`).FindString(messages[len(messages)-1].Body)
+	require.NotEmpty(t, code)
+	return code
+}
+
+// wrongCode returns a code other than code
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func TestUserUsecase_PhoneVerificationAndTwoFactorLogin(t *testing.T) {
+	repos := memory.NewRepositories()
+	texts := &sms.Recorder{}
+	uc := NewUserUsecase(repos.Users, "test-secret", WithSMS(texts, repos.VerificationCodes, 10*time.Minute))
+	ctx := context.Background()
+
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123", Phone: "+14155550100"})
+	require.NoError(t, err)
+
+	assert.EqualError(t, uc.SetTwoFactor(ctx, registered.ID, true), "verify your phone number before turning on two-factor login")
+
+	require.NoError(t, uc.SendPhoneVerification(ctx, registered.ID))
+	require.Len(t, texts.Messages(), 1)
+	assert.Equal(t, "+14155550100", texts.Messages()[0].To)
+	assert.Contains(t, texts.Messages()[0].Body, "expires in 10 minutes")
+	assert.ErrorIs(t, uc.SendPhoneVerification(ctx, registered.ID), domain.ErrCodeRecentlySent)
+
+	code := lastCode(t, texts)
+	assert.ErrorIs(t, uc.VerifyPhone(ctx, registered.ID, wrongCode(code)), domain.ErrInvalidCode)
+	require.NoError(t, uc.VerifyPhone(ctx, registered.ID, code))
+	assert.ErrorIs(t, uc.VerifyPhone(ctx, registered.ID, code), domain.ErrInvalidCode, "codes are used once")
+
+	require.NoError(t, uc.SetTwoFactor(ctx, registered.ID, true))
+
+	_, err = uc.Login(ctx, &domain.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	var twoFactor *domain.TwoFactorRequiredError
+	require.True(t, errors.As(err, &twoFactor))
+	require.Len(t, texts.Messages(), 2)
+	code = lastCode(t, texts)
+
+	_, err = uc.CompleteTwoFactorLogin(ctx, &domain.TwoFactorLoginRequest{ChallengeToken: "1.99999999999.forged", Code: code})
+	assert.ErrorIs(t, err, domain.ErrInvalidLoginCode)
+
+	login, err := uc.CompleteTwoFactorLogin(ctx, &domain.TwoFactorLoginRequest{ChallengeToken: twoFactor.ChallengeToken, Code: code})
+	require.NoError(t, err)
+	assert.NotEmpty(t, login.Token)
+	assert.Equal(t, registered.ID, login.User.ID)
+
+	_, err = uc.Login(ctx, &domain.LoginRequest{Email: "alice@example.com", Password: "wrong"})
+	assert.EqualError(t, err, "invalid email or password", "the password is checked before any code is texted")
+	assert.Len(t, texts.Messages(), 2)
+
+	// A new phone number is unverified and turns two-factor login off
+	_, err = uc.UpdateUser(ctx, registered.ID, &domain.UpdateUserRequest{Phone: "+14155550199"})
+	require.NoError(t, err)
+	login, err = uc.Login(ctx, &domain.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, login.Token)
+}
+
+func TestUserUsecase_VerificationCodeAttempts(t *testing.T) {
+	repos := memory.NewRepositories()
+	texts := &sms.Recorder{}
+	uc := NewUserUsecase(repos.Users, "test-secret", WithSMS(texts, repos.VerificationCodes, 10*time.Minute))
+	ctx := context.Background()
+
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123", Phone: "+14155550100"})
+	require.NoError(t, err)
+	require.NoError(t, uc.SendPhoneVerification(ctx, registered.ID))
+	code := lastCode(t, texts)
+
+	for range smsMaxAttempts {
+		assert.ErrorIs(t, uc.VerifyPhone(ctx, registered.ID, wrongCode(code)), domain.ErrInvalidCode)
+	}
+	assert.ErrorIs(t, uc.VerifyPhone(ctx, registered.ID, code), domain.ErrInvalidCode, "too many wrong guesses use the code up")
+}
+
+// staleCodes returns every code as if no guess had been counted yet, like a
+// read racing concurrent guesses
+type staleCodes struct {
+	repository.VerificationCodeRepository
+}
+
+func (s staleCodes) Get(ctx context.Context, userID uint, purpose string) (*domain.VerificationCode, error) {
+	code, err := s.VerificationCodeRepository.Get(ctx, userID, purpose)
+	if code != nil {
+		code.Attempts = 0
+	}
+	return code, err
+}
+
+func TestUserUsecase_VerificationCodeAttemptsAreCountedAtomically(t *testing.T) {
+	repos := memory.NewRepositories()
+	texts := &sms.Recorder{}
+	uc := NewUserUsecase(repos.Users, "test-secret", WithSMS(texts, staleCodes{repos.VerificationCodes}, 10*time.Minute))
+	ctx := context.Background()
+
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123", Phone: "+14155550100"})
+	require.NoError(t, err)
+	require.NoError(t, uc.SendPhoneVerification(ctx, registered.ID))
+	code := lastCode(t, texts)
+
+	for range smsMaxAttempts {
+		assert.ErrorIs(t, uc.VerifyPhone(ctx, registered.ID, wrongCode(code)), domain.ErrInvalidCode)
+	}
+	assert.ErrorIs(t, uc.VerifyPhone(ctx, registered.ID, code), domain.ErrInvalidCode, "guesses read before the limit was reached don't get past it")
+}
+
+func TestUserUsecase_SMSErrors(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+
+	uc := NewUserUsecase(repos.Users, "test-secret")
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123", Phone: "555-0100"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, uc.SendPhoneVerification(ctx, registered.ID), domain.ErrSMSUnavailable)
+	assert.ErrorIs(t, uc.SetTwoFactor(ctx, registered.ID, true), domain.ErrSMSUnavailable)
+	assert.NoError(t, uc.SetTwoFactor(ctx, registered.ID, false), "turning it off needs no SMS")
+
+	texts := &sms.Recorder{}
+	uc = NewUserUsecase(repos.Users, "test-secret", WithSMS(texts, repos.VerificationCodes, 10*time.Minute))
+	assert.EqualError(t, uc.SendPhoneVerification(ctx, registered.ID), "phone number must be in E.164 format, such as +14155550100")
+
+	_, err = uc.UpdateUser(ctx, registered.ID, &domain.UpdateUserRequest{Phone: "+14155550100"})
+	require.NoError(t, err)
+	texts.Err = errors.New("provider down")
+	assert.ErrorContains(t, uc.SendPhoneVerification(ctx, registered.ID), "provider down")
+	texts.Err = nil
+	assert.NoError(t, uc.SendPhoneVerification(ctx, registered.ID), "a failed send doesn't hold off the next one")
+}
@@ -2,11 +2,18 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
 	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
 	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
 )
 
@@ -15,25 +22,102 @@ type UserUsecase interface {
 	Register(ctx context.Context, req *domain.UserRequest) (*domain.UserResponse, error)
 	Login(ctx context.Context, req *domain.LoginRequest) (*domain.LoginResponse, error)
 	CreateUser(ctx context.Context, req *domain.UserRequest) (*domain.UserResponse, error)
+	CreateUsers(ctx context.Context, reqs []*domain.UserRequest) ([]*domain.UserResponse, error)
 	GetProfile(ctx context.Context, userID uint) (*domain.UserResponse, error)
 	GetUserByID(ctx context.Context, userID uint) (*domain.UserResponse, error)
+	GetUsersByIDs(ctx context.Context, userIDs []uint) ([]*domain.UserResponse, error)
 	UpdateUser(ctx context.Context, userID uint, req *domain.UpdateUserRequest) (*domain.UserResponse, error)
 	DeleteUser(ctx context.Context, userID uint) error
 	GetAllUsers(ctx context.Context, limit, offset int) ([]*domain.UserResponse, error)
+	GetAllUsersIncludingDeleted(ctx context.Context, limit, offset int) ([]*domain.UserResponse, error)
+	RestoreUser(ctx context.Context, userID uint) (*domain.UserResponse, error)
+	SearchUsers(ctx context.Context, query string, limit, offset int) ([]*domain.UserResponse, error)
+	// ResolveUserID maps the ID a client addressed a user by to the internal
+	// user ID
+	ResolveUserID(ctx context.Context, externalID string) (uint, error)
+	// VerifyEmail marks the email address of the user a verification token
+	// was issued to as verified
+	VerifyEmail(ctx context.Context, token string) error
+	// SendPhoneVerification texts a verification code to the user's phone
+	SendPhoneVerification(ctx context.Context, userID uint) error
+	// VerifyPhone marks the user's phone number as verified given the texted code
+	VerifyPhone(ctx context.Context, userID uint, code string) error
+	// SetTwoFactor turns texting a code at every login on or off
+	SetTwoFactor(ctx context.Context, userID uint, enabled bool) error
+	// CompleteTwoFactorLogin finishes a login that required a texted code
+	CompleteTwoFactorLogin(ctx context.Context, req *domain.TwoFactorLoginRequest) (*domain.LoginResponse, error)
 }
 
 // userUsecase implements UserUsecase interface
 type userUsecase struct {
 	userRepo  repository.UserRepository
 	jwtSecret string
+	bus       *events.Bus
+	notifier  Notifier
+	cache     UserCacheInvalidator
+	publicIDs bool
+	verifier  *EmailVerifier
+	sms       *smsVerification
+}
+
+// UserUsecaseOption configures optional dependencies of the user usecase
+type UserUsecaseOption func(*userUsecase)
+
+// WithEventBus publishes user lifecycle events, such as events.UserCreated,
+// on bus once the change has been stored
+func WithEventBus(bus *events.Bus) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.bus = bus
+	}
+}
+
+// WithNotifications sends users security alerts, such as when their password
+// or email address changes
+func WithNotifications(notifier Notifier) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.notifier = notifier
+	}
+}
+
+// UserCacheInvalidator drops cached copies of a user after a write
+type UserCacheInvalidator interface {
+	InvalidateUser(ctx context.Context, id uint, emails ...string)
+}
+
+// WithCacheInvalidator invalidates cached users once writes have succeeded,
+// covering writes that bypass the caching repository
+func WithCacheInvalidator(invalidator UserCacheInvalidator) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.cache = invalidator
+	}
+}
+
+// WithPublicIDs identifies users by their opaque public ID rather than the
+// auto-increment key in responses, tokens and ResolveUserID
+func WithPublicIDs() UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.publicIDs = true
+	}
+}
+
+// WithEmailVerification adds a verification link to the welcome email and
+// accepts its tokens in VerifyEmail
+func WithEmailVerification(verifier *EmailVerifier) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.verifier = verifier
+	}
 }
 
 // NewUserUsecase creates a new user usecase
-func NewUserUsecase(userRepo repository.UserRepository, jwtSecret string) UserUsecase {
-	return &userUsecase{
+func NewUserUsecase(userRepo repository.UserRepository, jwtSecret string, opts ...UserUsecaseOption) UserUsecase {
+	u := &userUsecase{
 		userRepo:  userRepo,
 		jwtSecret: jwtSecret,
 	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
 
 // Register handles user registration
@@ -41,36 +125,100 @@ func (u *userUsecase) Register(ctx context.Context, req *domain.UserRequest) (*d
 	// Check if user already exists
 	existingUser, err := u.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to check existing user: %w", err))
 	}
 	if existingUser != nil {
-		return nil, errors.New("user with this email already exists")
+		return nil, domain.ErrEmailTaken
+	}
+
+	locale, err := userLocale(req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	if locale == "" {
+		// Default to the language the user registered in, when supported
+		if l := i18n.FromContext(ctx); l != nil && l.Language() != i18n.DefaultLanguage.String() {
+			locale = l.Language()
+		}
 	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to hash password: %w", err))
 	}
 
 	// Create user
 	user := &domain.User{
 		Name:     req.Name,
 		Email:    req.Email,
+		Phone:    req.Phone,
+		Locale:   locale,
 		Password: hashedPassword,
+		Role:     domain.RoleUser,
 	}
 
 	if err := u.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to create user: %w", err))
 	}
 
 	// Return user response
-	return &domain.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}, nil
+	response := u.toUserResponse(user)
+	u.bus.Publish(ctx, events.UserCreated{UserChange: events.UserChange{User: user, Response: response}, Registered: true})
+
+	return response, nil
+}
+
+// userLocale returns the canonical form of a requested locale, such as
+// "pt-BR" for "pt_br"
+func userLocale(locale string) (string, error) {
+	if locale == "" {
+		return "", nil
+	}
+	canonical, err := i18n.Canonical(locale)
+	if err != nil || len(canonical) > 35 {
+		return "", domain.ErrInvalidLocale
+	}
+	return canonical, nil
+}
+
+// VerifyEmail checks a verification token and records the user's email
+// address as verified; verifying an address again succeeds
+func (u *userUsecase) VerifyEmail(ctx context.Context, token string) error {
+	if u.verifier == nil {
+		return domain.ErrInvalidVerificationToken
+	}
+	userID, err := u.verifier.UserID(token)
+	if err != nil {
+		return err
+	}
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return domain.ErrInvalidVerificationToken
+	}
+	if err := u.verifier.Check(token, user); err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to verify email: %w", err))
+	}
+	u.invalidateUser(ctx, user.ID, user.Email)
+	u.notify(ctx, &domain.NotificationMessage{
+		Type:    domain.NotificationAccountActivity,
+		UserID:  user.ID,
+		Subject: "Your email address was verified",
+		Message: "The email address " + user.Email + " is now verified.",
+	})
+	return nil
 }
 
 // Login handles user authentication
@@ -78,32 +226,49 @@ func (u *userUsecase) Login(ctx context.Context, req *domain.LoginRequest) (*dom
 	// Get user by email
 	user, err := u.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
 	}
 	if user == nil {
-		return nil, errors.New("invalid email or password")
+		u.bus.Publish(ctx, events.LoginFailed{Email: req.Email, Reason: events.LoginUnknownEmail})
+		return nil, domain.ErrInvalidCredentials
 	}
 
 	// Check password
 	if err := utils.CheckPassword(req.Password, user.Password); err != nil {
-		return nil, errors.New("invalid email or password")
+		u.bus.Publish(ctx, events.LoginFailed{Email: req.Email, User: user, Reason: events.LoginWrongPassword})
+		return nil, domain.ErrInvalidCredentials
 	}
+	if user.TwoFactorEnabled {
+		return nil, u.startTwoFactorLogin(ctx, user)
+	}
+
+	return u.issueToken(ctx, user)
+}
 
+// issueToken generates the JWT of a user who has logged in
+func (u *userUsecase) issueToken(ctx context.Context, user *domain.User) (*domain.LoginResponse, error) {
 	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, u.jwtSecret)
+	claims := utils.JWTClaims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   user.Role,
+		Tenant: database.TenantID(ctx),
+	}
+	// Clients identify the user by the "sub" claim, which matches the ID
+	// in their responses and URLs
+	claims.Subject = u.publicID(user)
+	if claims.Subject == "" {
+		claims.Subject = strconv.FormatUint(uint64(user.ID), 10)
+	}
+	token, err := utils.GenerateClaimsJWT(claims, u.jwtSecret)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to generate token: %w", err))
 	}
 
 	// Return login response
 	return &domain.LoginResponse{
 		Token: token,
-		User: domain.UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-		},
+		User:  *u.toUserResponse(user),
 	}, nil
 }
 
@@ -112,122 +277,233 @@ func (u *userUsecase) CreateUser(ctx context.Context, req *domain.UserRequest) (
 	// Check if user already exists
 	existingUser, err := u.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to check existing user: %w", err))
 	}
 	if existingUser != nil {
-		return nil, errors.New("user with this email already exists")
+		return nil, domain.ErrEmailTaken
+	}
+
+	locale, err := userLocale(req.Locale)
+	if err != nil {
+		return nil, err
 	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to hash password: %w", err))
 	}
 
 	// Create user
 	user := &domain.User{
 		Name:     req.Name,
 		Email:    req.Email,
+		Phone:    req.Phone,
+		Locale:   locale,
 		Password: hashedPassword,
+		Role:     domain.RoleUser,
 	}
 
 	if err := u.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to create user: %w", err))
 	}
 
 	// Return user response
-	return &domain.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}, nil
+	response := u.toUserResponse(user)
+	u.bus.Publish(ctx, events.UserCreated{UserChange: events.UserChange{User: user, Response: response}})
+
+	return response, nil
+}
+
+// CreateUsers creates several users at once. The batch is all-or-nothing:
+// any email that is repeated or already registered rejects the whole request.
+func (u *userUsecase) CreateUsers(ctx context.Context, reqs []*domain.UserRequest) ([]*domain.UserResponse, error) {
+	if len(reqs) == 0 {
+		return []*domain.UserResponse{}, nil
+	}
+
+	emails := make([]string, 0, len(reqs))
+	seen := make(map[string]struct{}, len(reqs))
+	for _, req := range reqs {
+		if _, dup := seen[req.Email]; dup {
+			return nil, fmt.Errorf("%w: %s", domain.ErrDuplicateEmail, req.Email)
+		}
+		seen[req.Email] = struct{}{}
+		emails = append(emails, req.Email)
+	}
+
+	existing, err := u.userRepo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to check existing users: %w", err))
+	}
+	if len(existing) > 0 {
+		return nil, domain.ErrEmailTaken
+	}
+
+	users := make([]*domain.User, 0, len(reqs))
+	for _, req := range reqs {
+		locale, err := userLocale(req.Locale)
+		if err != nil {
+			return nil, err
+		}
+		hashedPassword, err := utils.HashPassword(req.Password)
+		if err != nil {
+			return nil, internalError(ctx, fmt.Errorf("failed to hash password: %w", err))
+		}
+		users = append(users, &domain.User{
+			Name:     req.Name,
+			Email:    req.Email,
+			Phone:    req.Phone,
+			Locale:   locale,
+			Password: hashedPassword,
+			Role:     domain.RoleUser,
+		})
+	}
+
+	if err := u.userRepo.CreateBatch(ctx, users); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to create users: %w", err))
+	}
+
+	responses := make([]*domain.UserResponse, 0, len(users))
+	for _, user := range users {
+		response := u.toUserResponse(user)
+		u.bus.Publish(ctx, events.UserCreated{UserChange: events.UserChange{User: user, Response: response}})
+		responses = append(responses, response)
+	}
+
+	return responses, nil
 }
 
 // GetProfile gets the current user's profile
 func (u *userUsecase) GetProfile(ctx context.Context, userID uint) (*domain.UserResponse, error) {
 	user, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 
-	return &domain.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}, nil
+	return u.toUserResponse(user), nil
 }
 
 // GetUserByID gets a user by ID
 func (u *userUsecase) GetUserByID(ctx context.Context, userID uint) (*domain.UserResponse, error) {
 	user, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 
-	return &domain.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}, nil
+	return u.toUserResponse(user), nil
+}
+
+// GetUsersByIDs gets the users matching the given IDs in a single lookup
+func (u *userUsecase) GetUsersByIDs(ctx context.Context, userIDs []uint) ([]*domain.UserResponse, error) {
+	users, err := u.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get users: %w", err))
+	}
+
+	userResponses := make([]*domain.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, u.toUserResponse(user))
+	}
+
+	return userResponses, nil
 }
 
 // UpdateUser updates a user's information
 func (u *userUsecase) UpdateUser(ctx context.Context, userID uint, req *domain.UpdateUserRequest) (*domain.UserResponse, error) {
-	// Get existing user
-	user, err := u.userRepo.GetByID(ctx, userID)
+	// Get existing user, bypassing the cache: every field is saved back, so a
+	// stale cached copy would overwrite newer data
+	user, err := u.userRepo.GetByID(cache.WithBypass(ctx), userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
+	}
+	if req.Version != 0 && req.Version != user.Version {
+		return nil, &domain.VersionConflictError{Entity: "user", ID: user.ID}
 	}
+	previousEmail := user.Email
 
 	// Check if email is being changed and if it's already taken
 	if req.Email != "" && req.Email != user.Email {
 		existingUser, err := u.userRepo.GetByEmail(ctx, req.Email)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check existing email: %w", err)
+			return nil, internalError(ctx, fmt.Errorf("failed to check existing email: %w", err))
 		}
 		if existingUser != nil {
-			return nil, errors.New("email already exists")
+			return nil, domain.ErrEmailTaken
 		}
+		// A new address is unverified until its own link is followed
 		user.Email = req.Email
+		user.EmailVerifiedAt = nil
 	}
 
 	// Update fields if provided
 	if req.Name != "" {
 		user.Name = req.Name
 	}
+	if req.Locale != "" {
+		locale, err := userLocale(req.Locale)
+		if err != nil {
+			return nil, err
+		}
+		user.Locale = locale
+	}
+	if req.Phone != "" && req.Phone != user.Phone {
+		// A new number is unverified, and login codes can't go to it
+		user.Phone = req.Phone
+		user.PhoneVerifiedAt = nil
+		user.TwoFactorEnabled = false
+	}
 
 	// Update password if provided
 	if req.Password != "" {
 		hashedPassword, err := utils.HashPassword(req.Password)
 		if err != nil {
-			return nil, fmt.Errorf("failed to hash password: %w", err)
+			return nil, internalError(ctx, fmt.Errorf("failed to hash password: %w", err))
 		}
 		user.Password = hashedPassword
 	}
 
 	// Save updated user
 	if err := u.userRepo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to update user: %w", err))
 	}
+	u.invalidateUser(ctx, user.ID, previousEmail, user.Email)
 
-	return &domain.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}, nil
+	if req.Password != "" {
+		u.notify(ctx, &domain.NotificationMessage{
+			Type:    domain.NotificationSecurityAlert,
+			UserID:  user.ID,
+			Subject: "Your password was changed",
+			Message: "The password of your account was just changed. If you didn't do this, reset your password right away.",
+		})
+	}
+	if user.Email != previousEmail {
+		u.notify(ctx, &domain.NotificationMessage{
+			Type:    domain.NotificationSecurityAlert,
+			UserID:  user.ID,
+			Subject: "Your email address was changed",
+			Message: "The email address of your account was changed to " + user.Email + ". If you didn't do this, contact support right away.",
+			Data:    map[string]interface{}{"previous_email": previousEmail},
+		})
+	}
+
+	response := u.toUserResponse(user)
+	updated := events.UserUpdated{UserChange: events.UserChange{User: user, Response: response}}
+	if user.Email != previousEmail {
+		updated.PreviousEmail = previousEmail
+	}
+	u.bus.Publish(ctx, updated)
+
+	return response, nil
 }
 
 // DeleteUser deletes a user
@@ -235,16 +511,19 @@ func (u *userUsecase) DeleteUser(ctx context.Context, userID uint) error {
 	// Check if user exists
 	user, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		return internalError(ctx, fmt.Errorf("failed to get user: %w", err))
 	}
 	if user == nil {
-		return errors.New("user not found")
+		return domain.ErrUserNotFound
 	}
 
 	// Delete user
 	if err := u.userRepo.Delete(ctx, userID); err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		return internalError(ctx, fmt.Errorf("failed to delete user: %w", err))
 	}
+	u.invalidateUser(ctx, user.ID, user.Email)
+
+	u.bus.Publish(ctx, events.UserDeleted{UserChange: events.UserChange{User: user, Response: u.toUserResponse(user)}})
 
 	return nil
 }
@@ -253,18 +532,147 @@ func (u *userUsecase) DeleteUser(ctx context.Context, userID uint) error {
 func (u *userUsecase) GetAllUsers(ctx context.Context, limit, offset int) ([]*domain.UserResponse, error) {
 	users, err := u.userRepo.GetAll(ctx, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get users: %w", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to get users: %w", err))
 	}
 
 	var userResponses []*domain.UserResponse
 	for _, user := range users {
-		userResponses = append(userResponses, &domain.UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-		})
+		userResponses = append(userResponses, u.toUserResponse(user))
 	}
 
 	return userResponses, nil
-} 
\ No newline at end of file
+}
+
+// GetAllUsersIncludingDeleted gets all users, soft-deleted ones included, with pagination
+func (u *userUsecase) GetAllUsersIncludingDeleted(ctx context.Context, limit, offset int) ([]*domain.UserResponse, error) {
+	users, err := u.userRepo.Unscoped().GetAll(ctx, limit, offset)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get users: %w", err))
+	}
+
+	userResponses := make([]*domain.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, u.toUserResponse(user))
+	}
+
+	return userResponses, nil
+}
+
+// SearchUsers finds users by name or email
+func (u *userUsecase) SearchUsers(ctx context.Context, query string, limit, offset int) ([]*domain.UserResponse, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, domain.ErrSearchQueryRequired
+	}
+
+	users, err := u.userRepo.Search(ctx, query, limit, offset)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to search users: %w", err))
+	}
+
+	userResponses := make([]*domain.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, u.toUserResponse(user))
+	}
+
+	return userResponses, nil
+}
+
+// RestoreUser undeletes a soft-deleted user
+func (u *userUsecase) RestoreUser(ctx context.Context, userID uint) (*domain.UserResponse, error) {
+	restored, err := u.userRepo.Restore(ctx, userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to restore user: %w", err))
+	}
+	if !restored {
+		return nil, domain.ErrDeletedUserNotFound
+	}
+
+	user, err := u.userRepo.GetByID(cache.WithBypass(ctx), userID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return nil, domain.ErrDeletedUserNotFound
+	}
+	u.notify(ctx, &domain.NotificationMessage{
+		Type:    domain.NotificationAccountActivity,
+		UserID:  user.ID,
+		Subject: "Your account was restored",
+		Message: "Your deleted account was restored by an administrator.",
+	})
+
+	return u.toUserResponse(user), nil
+}
+
+// ResolveUserID maps a public ID, or a numeric ID when public IDs are not in
+// use, to the internal user ID. Soft-deleted users resolve too, so they can
+// be restored.
+func (u *userUsecase) ResolveUserID(ctx context.Context, externalID string) (uint, error) {
+	if !u.publicIDs {
+		id, err := strconv.ParseUint(externalID, 10, 32)
+		if err != nil {
+			return 0, domain.ErrInvalidUserID
+		}
+		return uint(id), nil
+	}
+
+	user, err := u.userRepo.Unscoped().GetByPublicID(ctx, externalID)
+	if err != nil {
+		return 0, internalError(ctx, fmt.Errorf("failed to get user: %w", err))
+	}
+	if user == nil {
+		return 0, domain.ErrUserNotFound
+	}
+	return user.ID, nil
+}
+
+// toUserResponse converts user to the response every user endpoint returns;
+// the deletion time is only set for soft-deleted users
+func (u *userUsecase) toUserResponse(user *domain.User) *domain.UserResponse {
+	response := &domain.UserResponse{
+		ID:        user.ID,
+		PublicID:  u.publicID(user),
+		Name:      user.Name,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		Locale:    user.Locale,
+		Plan:      user.Plan,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		Version:   user.Version,
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		response.DeletedAt = &deletedAt
+	}
+	return response
+}
+
+// publicID returns the ID to expose for user, or "" when users are
+// identified by their numeric ID
+func (u *userUsecase) publicID(user *domain.User) string {
+	if !u.publicIDs {
+		return ""
+	}
+	return user.PublicID
+}
+
+// notify queues a notification for the user when notifications are
+// configured. Failures are logged rather than returned so they never fail the
+// user operation.
+func (u *userUsecase) notify(ctx context.Context, msg *domain.NotificationMessage) {
+	if u.notifier == nil {
+		return
+	}
+	if err := u.notifier.Notify(ctx, msg); err != nil {
+		logger.FromContext(ctx).Error("Failed to queue notification", "type", msg.Type, "user_id", msg.UserID, "error", err)
+	}
+}
+
+// invalidateUser drops cached copies of the user when a cache is configured
+func (u *userUsecase) invalidateUser(ctx context.Context, id uint, emails ...string) {
+	if u.cache == nil {
+		return
+	}
+	u.cache.InvalidateUser(ctx, id, emails...)
+}
@@ -4,13 +4,20 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
 	"github.com/aungmyozaw92/go-api-setup/internal/repository/mocks"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
 	"github.com/aungmyozaw92/go-api-setup/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -29,6 +36,9 @@ func (suite *UserUsecaseTestSuite) SetupTest() {
 	suite.ctx = context.Background()
 }
 
+// bypassedCtx matches contexts that skip the user cache
+var bypassedCtx = mock.MatchedBy(func(ctx context.Context) bool { return cache.IsBypassed(ctx) })
+
 func (suite *UserUsecaseTestSuite) TearDownTest() {
 	suite.mockRepo.AssertExpectations(suite.T())
 }
@@ -236,7 +246,7 @@ func (suite *UserUsecaseTestSuite) TestUpdateUser_Success() {
 	}
 
 	// Mock expectations
-	suite.mockRepo.On("GetByID", suite.ctx, userID).Return(existingUser, nil)
+	suite.mockRepo.On("GetByID", bypassedCtx, userID).Return(existingUser, nil)
 	suite.mockRepo.On("GetByEmail", suite.ctx, updateReq.Email).Return(nil, nil)
 	suite.mockRepo.On("Update", suite.ctx, mock.AnythingOfType("*domain.User")).Return(nil)
 
@@ -267,7 +277,7 @@ func (suite *UserUsecaseTestSuite) TestUpdateUser_EmailAlreadyExists() {
 	}
 
 	// Mock expectations
-	suite.mockRepo.On("GetByID", suite.ctx, userID).Return(existingUser, nil)
+	suite.mockRepo.On("GetByID", bypassedCtx, userID).Return(existingUser, nil)
 	suite.mockRepo.On("GetByEmail", suite.ctx, updateReq.Email).Return(conflictUser, nil)
 
 	// Execute
@@ -280,6 +290,20 @@ func (suite *UserUsecaseTestSuite) TestUpdateUser_EmailAlreadyExists() {
 }
 
 // Test DeleteUser
+func (suite *UserUsecaseTestSuite) TestUpdateUser_StaleVersion() {
+	userID := uint(1)
+	existingUser := &domain.User{ID: userID, Name: "John Doe", Email: "john@example.com", Version: 4}
+
+	suite.mockRepo.On("GetByID", bypassedCtx, userID).Return(existingUser, nil)
+
+	result, err := suite.usecase.UpdateUser(suite.ctx, userID, &domain.UpdateUserRequest{Name: "Jane", Version: 3})
+
+	var conflict *domain.VersionConflictError
+	assert.ErrorAs(suite.T(), err, &conflict)
+	assert.Nil(suite.T(), result)
+	suite.mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
 func (suite *UserUsecaseTestSuite) TestDeleteUser_Success() {
 	userID := uint(1)
 	user := &domain.User{
@@ -299,6 +323,21 @@ func (suite *UserUsecaseTestSuite) TestDeleteUser_Success() {
 	assert.NoError(suite.T(), err)
 }
 
+func (suite *UserUsecaseTestSuite) TestUpdateUser_InvalidatesCache() {
+	invalidator := &recordingInvalidator{}
+	usecase := NewUserUsecase(suite.mockRepo, suite.jwtSecret, WithCacheInvalidator(invalidator))
+	existingUser := &domain.User{ID: 1, Name: "John Doe", Email: "john@example.com"}
+
+	suite.mockRepo.On("GetByID", bypassedCtx, uint(1)).Return(existingUser, nil)
+	suite.mockRepo.On("GetByEmail", suite.ctx, "new@example.com").Return(nil, nil)
+	suite.mockRepo.On("Update", suite.ctx, mock.AnythingOfType("*domain.User")).Return(nil)
+
+	_, err := usecase.UpdateUser(suite.ctx, 1, &domain.UpdateUserRequest{Email: "new@example.com"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"john@example.com", "new@example.com"}, invalidator.emails[1])
+}
+
 func (suite *UserUsecaseTestSuite) TestDeleteUser_UserNotFound() {
 	userID := uint(999)
 
@@ -314,6 +353,46 @@ func (suite *UserUsecaseTestSuite) TestDeleteUser_UserNotFound() {
 }
 
 // Test GetAllUsers
+func (suite *UserUsecaseTestSuite) TestCreateUsers_Success() {
+	reqs := []*domain.UserRequest{
+		{Name: "Alice", Email: "alice@example.com", Password: "password123"},
+		{Name: "Bob", Email: "bob@example.com", Password: "password123"},
+	}
+
+	suite.mockRepo.On("ExistingEmails", suite.ctx, []string{"alice@example.com", "bob@example.com"}).Return([]string{}, nil)
+	suite.mockRepo.On("CreateBatch", suite.ctx, mock.MatchedBy(func(users []*domain.User) bool {
+		return len(users) == 2 && users[0].Password != "password123" && users[1].Role == domain.RoleUser
+	})).Run(func(args mock.Arguments) {
+		for i, user := range args.Get(1).([]*domain.User) {
+			user.ID = uint(i + 1)
+		}
+	}).Return(nil)
+
+	result, err := suite.usecase.CreateUsers(suite.ctx, reqs)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), result, 2)
+	assert.Equal(suite.T(), uint(2), result[1].ID)
+	assert.Equal(suite.T(), "bob@example.com", result[1].Email)
+}
+
+func (suite *UserUsecaseTestSuite) TestCreateUsers_Conflicts() {
+	_, err := suite.usecase.CreateUsers(suite.ctx, []*domain.UserRequest{
+		{Name: "A", Email: "same@example.com", Password: "password123"},
+		{Name: "B", Email: "same@example.com", Password: "password123"},
+	})
+	assert.EqualError(suite.T(), err, "duplicate email in request: same@example.com")
+	assert.ErrorIs(suite.T(), err, domain.ErrDuplicateEmail)
+
+	suite.mockRepo.On("ExistingEmails", suite.ctx, []string{"taken@example.com"}).Return([]string{"taken@example.com"}, nil)
+	_, err = suite.usecase.CreateUsers(suite.ctx, []*domain.UserRequest{
+		{Name: "C", Email: "taken@example.com", Password: "password123"},
+	})
+	assert.EqualError(suite.T(), err, "user with this email already exists")
+	assert.ErrorIs(suite.T(), err, domain.ErrEmailTaken)
+	suite.mockRepo.AssertNotCalled(suite.T(), "CreateBatch", mock.Anything, mock.Anything)
+}
+
 func (suite *UserUsecaseTestSuite) TestGetAllUsers_Success() {
 	limit := 10
 	offset := 0
@@ -355,4 +434,227 @@ func (suite *UserUsecaseTestSuite) TestGetAllUsers_RepositoryError() {
 // Run the test suite
 func TestUserUsecaseTestSuite(t *testing.T) {
 	suite.Run(t, new(UserUsecaseTestSuite))
-} 
\ No newline at end of file
+}
+
+// recordingInvalidator records InvalidateUser calls by user ID
+type recordingInvalidator struct {
+	emails map[uint][]string
+}
+
+func (r *recordingInvalidator) InvalidateUser(ctx context.Context, id uint, emails ...string) {
+	if r.emails == nil {
+		r.emails = make(map[uint][]string)
+	}
+	r.emails[id] = append(r.emails[id], emails...)
+}
+
+// TestUserUsecase_WithMemoryRepository runs a user's lifecycle against the
+// in-memory repository instead of mocks
+func TestUserUsecase_WithMemoryRepository(t *testing.T) {
+	uc := NewUserUsecase(memory.NewUserRepository(), "test-secret")
+	ctx := context.Background()
+
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	_, err = uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	assert.EqualError(t, err, "user with this email already exists")
+
+	login, err := uc.Login(ctx, &domain.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, login.Token)
+
+	updated, err := uc.UpdateUser(ctx, registered.ID, &domain.UpdateUserRequest{Name: "Alice Smith", Version: registered.Version})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice Smith", updated.Name)
+
+	_, err = uc.UpdateUser(ctx, registered.ID, &domain.UpdateUserRequest{Name: "Stale", Version: registered.Version})
+	var conflict *domain.VersionConflictError
+	assert.ErrorAs(t, err, &conflict)
+
+	require.NoError(t, uc.DeleteUser(ctx, registered.ID))
+	_, err = uc.GetProfile(ctx, registered.ID)
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestUserUsecase_PublicIDs(t *testing.T) {
+	repo := memory.NewUserRepository()
+	uc := NewUserUsecase(repo, "test-secret", WithPublicIDs())
+	ctx := context.Background()
+
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	require.NotEmpty(t, registered.PublicID)
+	assert.Equal(t, registered.PublicID, registered.ExternalID())
+
+	id, err := uc.ResolveUserID(ctx, registered.PublicID)
+	require.NoError(t, err)
+	assert.Equal(t, registered.ID, id)
+
+	_, err = uc.ResolveUserID(ctx, "1")
+	assert.EqualError(t, err, "user not found", "numeric IDs don't resolve alongside public IDs")
+
+	login, err := uc.Login(ctx, &domain.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	claims, err := utils.ValidateJWT(login.Token, "test-secret")
+	require.NoError(t, err)
+	assert.Equal(t, registered.PublicID, claims.Subject)
+
+	t.Run("numeric IDs by default", func(t *testing.T) {
+		uc := NewUserUsecase(repo, "test-secret")
+
+		user, err := uc.GetUserByID(ctx, registered.ID)
+		require.NoError(t, err)
+		assert.Empty(t, user.PublicID)
+
+		id, err := uc.ResolveUserID(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), id)
+
+		_, err = uc.ResolveUserID(ctx, registered.PublicID)
+		assert.EqualError(t, err, "Invalid user ID")
+	})
+}
+
+func TestUserUsecase_WelcomeEmailWithVerification(t *testing.T) {
+	renderer, err := email.NewRenderer(email.Brand{Name: "Acme", URL: "https://acme.example"})
+	require.NoError(t, err)
+	emails := memory.NewEmailRepository()
+	users := memory.NewUserRepository()
+	verifier := NewEmailVerifier("test-secret", 48*time.Hour, "https://acme.example/verify")
+	bus := events.NewBus()
+	NewWelcomeEmail(NewEmailOutbox(renderer), emails, "https://acme.example/login", verifier).Subscribe(bus)
+	uc := NewUserUsecase(users, "test-secret",
+		WithEventBus(bus),
+		WithEmailVerification(verifier),
+	)
+	ctx := context.Background()
+
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	queued, err := emails.GetDue(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "alice@example.com", queued[0].To)
+	assert.Equal(t, "welcome", queued[0].Template)
+	assert.Contains(t, queued[0].Text, "Sign in: https://acme.example/login")
+	assert.Contains(t, queued[0].Text, "expires in 2 days")
+
+	user, err := users.GetByID(ctx, registered.ID)
+	require.NoError(t, err)
+	assert.Contains(t, queued[0].Text, "Verify email: "+verifier.Link(user))
+
+	// Users created by an admin don't get a welcome email
+	_, err = uc.CreateUser(ctx, &domain.UserRequest{Name: "Bob", Email: "bob@example.com", Password: "password123"})
+	require.NoError(t, err)
+	queued, err = emails.GetDue(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Len(t, queued, 1)
+
+	require.NoError(t, uc.VerifyEmail(ctx, verifier.Token(user)))
+	user, err = users.GetByID(ctx, registered.ID)
+	require.NoError(t, err)
+	require.NotNil(t, user.EmailVerifiedAt)
+	assert.NoError(t, uc.VerifyEmail(ctx, verifier.Token(user)), "verifying again succeeds")
+
+	assert.ErrorIs(t, uc.VerifyEmail(ctx, "1.99999999999.forged"), domain.ErrInvalidVerificationToken)
+	assert.ErrorIs(t, uc.VerifyEmail(ctx, "garbage"), domain.ErrInvalidVerificationToken)
+}
+
+func TestUserUsecase_EmailChangeNeedsVerification(t *testing.T) {
+	renderer, err := email.NewRenderer(email.Brand{Name: "Acme", URL: "https://acme.example"})
+	require.NoError(t, err)
+	emails := memory.NewEmailRepository()
+	users := memory.NewUserRepository()
+	verifier := NewEmailVerifier("test-secret", 48*time.Hour, "https://acme.example/verify")
+	bus := events.NewBus()
+	NewVerificationEmail(NewEmailOutbox(renderer), emails, verifier).Subscribe(bus)
+	uc := NewUserUsecase(users, "test-secret",
+		WithEventBus(bus),
+		WithEmailVerification(verifier),
+	)
+	ctx := context.Background()
+
+	registered, err := uc.Register(ctx, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	user, err := users.GetByID(ctx, registered.ID)
+	require.NoError(t, err)
+	oldToken := verifier.Token(user)
+	require.NoError(t, uc.VerifyEmail(ctx, oldToken))
+
+	// Other changes keep the address verified and send nothing
+	_, err = uc.UpdateUser(ctx, registered.ID, &domain.UpdateUserRequest{Name: "Alice Liddell"})
+	require.NoError(t, err)
+	user, err = users.GetByID(ctx, registered.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, user.EmailVerifiedAt)
+	queued, err := emails.GetDue(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, queued)
+
+	_, err = uc.UpdateUser(ctx, registered.ID, &domain.UpdateUserRequest{Email: "alice@wonderland.example"})
+	require.NoError(t, err)
+	user, err = users.GetByID(ctx, registered.ID)
+	require.NoError(t, err)
+	assert.Nil(t, user.EmailVerifiedAt, "the new address is unverified")
+
+	queued, err = emails.GetDue(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "alice@wonderland.example", queued[0].To)
+	assert.Equal(t, "verification", queued[0].Template)
+	assert.Contains(t, queued[0].Text, verifier.Link(user))
+
+	assert.ErrorIs(t, uc.VerifyEmail(ctx, oldToken), domain.ErrInvalidVerificationToken, "links to the old address don't verify the new one")
+	require.NoError(t, uc.VerifyEmail(ctx, verifier.Token(user)))
+	user, err = users.GetByID(ctx, registered.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, user.EmailVerifiedAt)
+}
+
+func TestUserUsecase_Locale(t *testing.T) {
+	bundle := i18n.NewBundle()
+	require.NoError(t, bundle.LoadFS(email.Messages, "locales"))
+	require.NoError(t, bundle.LoadFS(fstest.MapFS{"es.yaml": {Data: []byte(`welcome.subject: "Bienvenido a {{.App.Name}}"`)}}, "."))
+	renderer, err := email.NewRenderer(email.Brand{Name: "Acme", URL: "https://acme.example"}, email.WithBundle(bundle))
+	require.NoError(t, err)
+	emails := memory.NewEmailRepository()
+	bus := events.NewBus()
+	NewWelcomeEmail(NewEmailOutbox(renderer), emails, "https://acme.example/login", nil).Subscribe(bus)
+	uc := NewUserUsecase(memory.NewUserRepository(), "test-secret", WithEventBus(bus))
+	ctx := context.Background()
+
+	// Users registering in a supported language get their emails in it
+	spanish := i18n.NewContext(ctx, bundle.Localizer("es-MX,es;q=0.8"))
+	alice, err := uc.Register(spanish, &domain.UserRequest{Name: "Alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, "es", alice.Locale)
+	queued, err := emails.GetDue(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "Bienvenido a Acme", queued[0].Subject)
+
+	english := i18n.NewContext(ctx, bundle.Localizer("fr"))
+	bob, err := uc.Register(english, &domain.UserRequest{Name: "Bob", Email: "bob@example.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.Empty(t, bob.Locale)
+
+	carol, err := uc.Register(spanish, &domain.UserRequest{Name: "Carol", Email: "carol@example.com", Password: "password123", Locale: "pt_br"})
+	require.NoError(t, err)
+	assert.Equal(t, "pt-BR", carol.Locale)
+
+	_, err = uc.Register(ctx, &domain.UserRequest{Name: "Dave", Email: "dave@example.com", Password: "password123", Locale: "not a locale"})
+	assert.EqualError(t, err, "invalid locale")
+
+	updated, err := uc.UpdateUser(ctx, alice.ID, &domain.UpdateUserRequest{Locale: "en-GB"})
+	require.NoError(t, err)
+	assert.Equal(t, "en-GB", updated.Locale)
+	_, err = uc.UpdateUser(ctx, alice.ID, &domain.UpdateUserRequest{Locale: "??"})
+	assert.EqualError(t, err, "invalid locale")
+}
+
+func TestUserUsecase_VerifyEmailWithoutVerification(t *testing.T) {
+	uc := NewUserUsecase(memory.NewUserRepository(), "test-secret")
+	assert.ErrorIs(t, uc.VerifyEmail(context.Background(), "1.2.3"), domain.ErrInvalidVerificationToken)
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+)
+
+// VerificationEmail queues a new verification link for every user who
+// changes their email address, which is unverified until they follow it
+type VerificationEmail struct {
+	outbox   *EmailOutbox
+	emails   repository.EmailRepository
+	verifier *EmailVerifier
+}
+
+// NewVerificationEmail creates verification emails with links from
+// verifier, queued in emails
+func NewVerificationEmail(outbox *EmailOutbox, emails repository.EmailRepository, verifier *EmailVerifier) *VerificationEmail {
+	return &VerificationEmail{outbox: outbox, emails: emails, verifier: verifier}
+}
+
+// Subscribe sends the verification email when a user's email address
+// changes on bus
+func (v *VerificationEmail) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "verification_email", v.send)
+}
+
+// send queues the verification email of a user's new address
+func (v *VerificationEmail) send(ctx context.Context, e events.UserUpdated) error {
+	if e.PreviousEmail == "" {
+		return nil
+	}
+	user := e.User
+	data := email.VerificationData{Name: user.Name, VerifyURL: v.verifier.Link(user), ExpiresIn: v.verifier.TTL()}
+	if _, err := v.outbox.Queue(ctx, v.emails, user.Email, user.Locale, data); err != nil {
+		return fmt.Errorf("failed to queue verification email for user %d: %w", user.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/cache"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+)
+
+// VerificationReminderJob emails one user a reminder to verify their email
+// address
+const VerificationReminderJob = "user.verification_reminder"
+
+// verificationReminderBatchSize is how many users a scan reads at once
+const verificationReminderBatchSize = 500
+
+// VerificationReminders reminds users who haven't verified their email
+// address: a reminder after every interval since they registered or were
+// last reminded, up to a maximum. Run scans for users due a reminder on a
+// schedule and queues a VerificationReminderJob for each, which queues the
+// email in the outbox.
+type VerificationReminders struct {
+	users    repository.UserRepository
+	emails   repository.EmailRepository
+	outbox   *EmailOutbox
+	verifier *EmailVerifier
+	queue    jobs.Enqueuer
+	interval time.Duration
+	max      int
+	now      func() time.Time
+	logger   *slog.Logger
+}
+
+// NewVerificationReminders creates reminders sent interval after
+// registration and again every interval, at most max per user. Users who
+// registered more than max+1 intervals ago are never reminded, so turning
+// reminders on doesn't email everyone who never verified.
+func NewVerificationReminders(users repository.UserRepository, emails repository.EmailRepository, outbox *EmailOutbox, verifier *EmailVerifier, queue jobs.Enqueuer, interval time.Duration, max int) *VerificationReminders {
+	return &VerificationReminders{
+		users:    users,
+		emails:   emails,
+		outbox:   outbox,
+		verifier: verifier,
+		queue:    queue,
+		interval: interval,
+		max:      max,
+		now:      time.Now,
+		logger:   slog.Default().With("worker", "VerificationReminders"),
+	}
+}
+
+// verificationReminder is the payload of a verification reminder job
+type verificationReminder struct {
+	UserID uint `json:"user_id"`
+}
+
+// RegisterJobs registers the reminder job handler with pool
+func (r *VerificationReminders) RegisterJobs(pool *jobs.Pool) {
+	pool.Register(VerificationReminderJob, r.remind)
+}
+
+// Run queues a reminder job for every user due one. It is a scheduled job;
+// when ctx is canceled it stops between batches. A user whose job has not
+// run by the next scan is queued again, and the duplicate job does nothing.
+func (r *VerificationReminders) Run(ctx context.Context) error {
+	now := r.now()
+	registeredAfter := now.Add(-time.Duration(r.max+1) * r.interval)
+	remindBefore := now.Add(-r.interval)
+
+	var afterID uint
+	queued := 0
+	for ctx.Err() == nil {
+		users, err := r.users.ListUnverified(ctx, registeredAfter, remindBefore, r.max, afterID, verificationReminderBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list unverified users: %w", err)
+		}
+		for _, user := range users {
+			if _, err := r.queue.Enqueue(ctx, VerificationReminderJob, verificationReminder{UserID: user.ID}); err != nil {
+				return fmt.Errorf("failed to queue verification reminder: %w", err)
+			}
+			queued++
+			afterID = user.ID
+		}
+		if len(users) < verificationReminderBatchSize {
+			break
+		}
+	}
+
+	if queued > 0 {
+		r.logger.Info("Queued verification reminders", "count", queued)
+	}
+	return ctx.Err()
+}
+
+// remind queues the reminder email of one user, unless they verified their
+// address or were reminded since the job was queued. The reminder is
+// counted before the email is queued, so a failure to queue it skips the
+// reminder rather than risk sending it twice.
+func (r *VerificationReminders) remind(ctx context.Context, job *domain.Job) error {
+	var payload verificationReminder
+	if err := jobs.DecodePayload(job, &payload); err != nil {
+		return err
+	}
+	user, err := r.users.GetByID(cache.WithBypass(ctx), payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !r.due(user) {
+		return nil
+	}
+
+	marked, err := r.users.MarkVerificationReminded(ctx, user, r.now())
+	if err != nil {
+		return fmt.Errorf("failed to record verification reminder: %w", err)
+	}
+	if !marked {
+		return nil
+	}
+
+	_, err = r.outbox.Queue(ctx, r.emails, user.Email, user.Locale, email.VerificationData{
+		Name:      user.Name,
+		VerifyURL: r.verifier.Link(user),
+		ExpiresIn: r.verifier.TTL(),
+	})
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("failed to queue verification reminder: %w", err))
+	}
+	logger.FromContext(ctx).Info("Queued verification reminder", "user_id", user.ID, "reminder", user.VerificationReminders)
+	return nil
+}
+
+// due reports whether user is still owed a reminder
+func (r *VerificationReminders) due(user *domain.User) bool {
+	if user.EmailVerifiedAt != nil || user.VerificationReminders >= r.max {
+		return false
+	}
+	last := user.CreatedAt
+	if user.VerificationRemindedAt != nil {
+		last = *user.VerificationRemindedAt
+	}
+	return !r.now().Before(last.Add(r.interval))
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/jobs"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationReminders(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com"}
+	require.NoError(t, repos.Users.Create(ctx, alice))
+	verified := time.Now()
+	bob := &domain.User{Name: "Bob", Email: "bob@example.com", EmailVerifiedAt: &verified}
+	require.NoError(t, repos.Users.Create(ctx, bob))
+
+	renderer, err := email.NewRenderer(email.Brand{Name: "Acme", URL: "https://acme.example"})
+	require.NoError(t, err)
+	verifier := NewEmailVerifier("test-secret", 48*time.Hour, "https://acme.example/verify")
+	reminders := NewVerificationReminders(repos.Users, repos.Emails, NewEmailOutbox(renderer), verifier, jobs.NewQueue(repos.Jobs), 24*time.Hour, 2)
+	var clock atomic.Int64
+	reminders.now = func() time.Time { return time.Unix(0, clock.Load()) }
+	setClock := func(sinceRegistration time.Duration) { clock.Store(alice.CreatedAt.Add(sinceRegistration).UnixNano()) }
+
+	pool := jobs.NewPool(repos.Jobs, jobs.WithPollInterval(10*time.Millisecond))
+	reminders.RegisterJobs(pool)
+	go pool.Start()
+	defer pool.Stop()
+
+	sent := func() []*domain.Email {
+		emails, err := repos.Emails.GetDue(ctx, time.Now().Add(time.Hour), 10)
+		require.NoError(t, err)
+		return emails
+	}
+	reminded := func() int {
+		user, err := repos.Users.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		return user.VerificationReminders
+	}
+
+	// Not due before the first interval
+	setClock(23 * time.Hour)
+	require.NoError(t, reminders.Run(ctx))
+
+	// Scanning twice before the job runs still sends one reminder
+	setClock(25 * time.Hour)
+	require.NoError(t, reminders.Run(ctx))
+	require.NoError(t, reminders.Run(ctx))
+	require.Eventually(t, func() bool { return reminded() == 1 }, 2*time.Second, 10*time.Millisecond)
+	emails := sent()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "alice@example.com", emails[0].To)
+	assert.Equal(t, "verification", emails[0].Template)
+	assert.Contains(t, emails[0].Text, "https://acme.example/verify?token=")
+
+	// Then every interval, up to the maximum
+	setClock(50 * time.Hour)
+	require.NoError(t, reminders.Run(ctx))
+	require.Eventually(t, func() bool { return reminded() == 2 }, 2*time.Second, 10*time.Millisecond)
+
+	setClock(75 * time.Hour)
+	require.NoError(t, reminders.Run(ctx))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, sent(), 2)
+	assert.Equal(t, 2, reminded())
+}
+
+func TestVerificationReminders_SkipsOldUsers(t *testing.T) {
+	repos := memory.NewRepositories()
+	ctx := context.Background()
+	alice := &domain.User{Name: "Alice", Email: "alice@example.com"}
+	require.NoError(t, repos.Users.Create(ctx, alice))
+
+	reminders := NewVerificationReminders(repos.Users, repos.Emails, nil, nil, jobs.NewQueue(repos.Jobs), 24*time.Hour, 2)
+	reminders.now = func() time.Time { return alice.CreatedAt.Add(73 * time.Hour) }
+	require.NoError(t, reminders.Run(ctx))
+
+	job, err := repos.Jobs.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, job, "users who registered more than max+1 intervals ago are not reminded")
+}
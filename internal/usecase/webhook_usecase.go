@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	eventbus "github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/events"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webhook"
+)
+
+// Limits of a subscription's delivery policy
+const (
+	maxWebhookAttempts          = 25
+	maxWebhookRetryDelaySeconds = 24 * 60 * 60
+	maxWebhookTimeoutSeconds    = 60
+)
+
+// WebhookUsecase defines the interface for webhook business logic
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, createdBy uint, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscriptionResponse, error)
+	GetSubscription(ctx context.Context, id uint) (*domain.WebhookSubscriptionResponse, error)
+	GetAllSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscriptionResponse, error)
+	UpdateSubscription(ctx context.Context, id uint, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscriptionResponse, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+	GetDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]*domain.WebhookDelivery, error)
+	Dispatch(ctx context.Context, event string, data interface{}) error
+}
+
+// webhookUsecase implements WebhookUsecase interface
+type webhookUsecase struct {
+	webhookRepo repository.WebhookRepository
+	source      string
+}
+
+// NewWebhookUsecase creates a new webhook usecase; source is the CloudEvents
+// source of the events it delivers
+func NewWebhookUsecase(webhookRepo repository.WebhookRepository, source string) WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo: webhookRepo,
+		source:      source,
+	}
+}
+
+// CreateSubscription registers a new webhook subscription
+func (u *webhookUsecase) CreateSubscription(ctx context.Context, createdBy uint, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscriptionResponse, error) {
+	if err := validateWebhookRequest(req); err != nil {
+		return nil, err
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to generate secret: %w", err))
+	}
+
+	sub := &domain.WebhookSubscription{
+		URL:       req.URL,
+		Events:    strings.Join(req.Events, ","),
+		Secret:    secret,
+		Active:    true,
+		CreatedBy: createdBy,
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+	applyDeliveryPolicy(sub, req)
+
+	if err := u.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to create webhook: %w", err))
+	}
+
+	// The secret is only revealed once, at creation time
+	response := toWebhookSubscriptionResponse(sub)
+	response.Secret = sub.Secret
+	return response, nil
+}
+
+// GetSubscription gets a webhook subscription by ID
+func (u *webhookUsecase) GetSubscription(ctx context.Context, id uint) (*domain.WebhookSubscriptionResponse, error) {
+	sub, err := u.webhookRepo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get webhook: %w", err))
+	}
+	if sub == nil {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	return toWebhookSubscriptionResponse(sub), nil
+}
+
+// GetAllSubscriptions gets all webhook subscriptions with pagination
+func (u *webhookUsecase) GetAllSubscriptions(ctx context.Context, limit, offset int) ([]*domain.WebhookSubscriptionResponse, error) {
+	subs, err := u.webhookRepo.GetAllSubscriptions(ctx, limit, offset)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get webhooks: %w", err))
+	}
+
+	responses := make([]*domain.WebhookSubscriptionResponse, 0, len(subs))
+	for _, sub := range subs {
+		responses = append(responses, toWebhookSubscriptionResponse(sub))
+	}
+	return responses, nil
+}
+
+// UpdateSubscription updates a webhook subscription's URL, events, active
+// flag and delivery policy. Reactivating a subscription clears the failures
+// it was disabled for.
+func (u *webhookUsecase) UpdateSubscription(ctx context.Context, id uint, req *domain.WebhookSubscriptionRequest) (*domain.WebhookSubscriptionResponse, error) {
+	if err := validateWebhookRequest(req); err != nil {
+		return nil, err
+	}
+
+	sub, err := u.webhookRepo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get webhook: %w", err))
+	}
+	if sub == nil {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	sub.URL = req.URL
+	sub.Events = strings.Join(req.Events, ",")
+	if req.Active != nil {
+		if *req.Active && !sub.Active {
+			sub.ConsecutiveFailures = 0
+			sub.DisabledAt = nil
+		}
+		sub.Active = *req.Active
+	}
+	applyDeliveryPolicy(sub, req)
+
+	if err := u.webhookRepo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to update webhook: %w", err))
+	}
+
+	return toWebhookSubscriptionResponse(sub), nil
+}
+
+// DeleteSubscription deletes a webhook subscription
+func (u *webhookUsecase) DeleteSubscription(ctx context.Context, id uint) error {
+	sub, err := u.webhookRepo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get webhook: %w", err))
+	}
+	if sub == nil {
+		return domain.ErrWebhookNotFound
+	}
+
+	if err := u.webhookRepo.DeleteSubscription(ctx, id); err != nil {
+		return internalError(ctx, fmt.Errorf("failed to delete webhook: %w", err))
+	}
+	return nil
+}
+
+// GetDeliveries gets the delivery history of a webhook subscription
+func (u *webhookUsecase) GetDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	sub, err := u.webhookRepo.GetSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get webhook: %w", err))
+	}
+	if sub == nil {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	deliveries, err := u.webhookRepo.GetDeliveriesBySubscription(ctx, subscriptionID, limit, offset)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("failed to get deliveries: %w", err))
+	}
+	return deliveries, nil
+}
+
+// Dispatch queues a delivery of the event to every active subscription registered for it.
+// Deliveries are sent asynchronously by the webhook worker, as CloudEvents
+// in the structured JSON format.
+func (u *webhookUsecase) Dispatch(ctx context.Context, event string, data interface{}) error {
+	subs, err := u.webhookRepo.GetActiveSubscriptions(ctx)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to get webhooks: %w", err))
+	}
+
+	now := time.Now()
+	payload, err := webhookPayload(u.source, event, data)
+	if err != nil {
+		return internalError(ctx, fmt.Errorf("failed to encode webhook payload: %w", err))
+	}
+
+	for _, sub := range subs {
+		if !subscribesTo(sub, event) {
+			continue
+		}
+
+		delivery := &domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			Event:          event,
+			Payload:        string(payload),
+			Status:         domain.DeliveryStatusPending,
+			NextAttemptAt:  now,
+		}
+		if err := u.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			return internalError(ctx, fmt.Errorf("failed to queue webhook delivery: %w", err))
+		}
+	}
+
+	return nil
+}
+
+// SubscribeUserWebhooks delivers the user lifecycle events published on bus
+// to webhook subscribers
+func SubscribeUserWebhooks(bus *eventbus.Bus, webhooks WebhookUsecase) {
+	eventbus.SubscribeUserEvents(bus, "webhooks", func(ctx context.Context, e eventbus.UserEvent) error {
+		return webhooks.Dispatch(ctx, e.EventName(), e.Change().Response)
+	})
+}
+
+// webhookPayload encodes the CloudEvent delivered for an event. Every
+// subscription gets the same event ID, so a receiver subscribed twice can
+// drop the duplicate.
+func webhookPayload(source, event string, data interface{}) ([]byte, error) {
+	cloudEvent, err := events.New(source, event, "", data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cloudEvent)
+}
+
+// validateWebhookRequest validates the subscription URL and event names
+func validateWebhookRequest(req *domain.WebhookSubscriptionRequest) error {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return domain.NewError(domain.ErrInvalid, "invalid webhook url")
+	}
+
+	if len(req.Events) == 0 {
+		return domain.NewError(domain.ErrInvalid, "at least one event is required")
+	}
+
+	for _, event := range req.Events {
+		if !isKnownWebhookEvent(event) {
+			return domain.Errorf(domain.ErrInvalid, "unknown webhook event: %s", event)
+		}
+	}
+
+	if req.MaxAttempts != nil && (*req.MaxAttempts < 0 || *req.MaxAttempts > maxWebhookAttempts) {
+		return domain.Errorf(domain.ErrInvalid, "invalid webhook max_attempts: must be between 1 and %d, or 0 for the default", maxWebhookAttempts)
+	}
+	if req.RetryDelaySeconds != nil && (*req.RetryDelaySeconds < 0 || *req.RetryDelaySeconds > maxWebhookRetryDelaySeconds) {
+		return domain.Errorf(domain.ErrInvalid, "invalid webhook retry_delay_seconds: must be between 1 and %d, or 0 for the default", maxWebhookRetryDelaySeconds)
+	}
+	if req.TimeoutSeconds != nil && (*req.TimeoutSeconds < 0 || *req.TimeoutSeconds > maxWebhookTimeoutSeconds) {
+		return domain.Errorf(domain.ErrInvalid, "invalid webhook timeout_seconds: must be between 1 and %d, or 0 for the default", maxWebhookTimeoutSeconds)
+	}
+	return nil
+}
+
+// applyDeliveryPolicy copies the delivery policy fields set in the request
+func applyDeliveryPolicy(sub *domain.WebhookSubscription, req *domain.WebhookSubscriptionRequest) {
+	if req.MaxAttempts != nil {
+		sub.MaxAttempts = *req.MaxAttempts
+	}
+	if req.RetryDelaySeconds != nil {
+		sub.RetryDelaySeconds = *req.RetryDelaySeconds
+	}
+	if req.TimeoutSeconds != nil {
+		sub.TimeoutSeconds = *req.TimeoutSeconds
+	}
+}
+
+// isKnownWebhookEvent reports whether the event can be subscribed to
+func isKnownWebhookEvent(event string) bool {
+	if event == domain.EventWildcard {
+		return true
+	}
+	for _, known := range domain.WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribesTo reports whether the subscription is registered for the event
+func subscribesTo(sub *domain.WebhookSubscription, event string) bool {
+	for _, e := range strings.Split(sub.Events, ",") {
+		if e == event || e == domain.EventWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// toWebhookSubscriptionResponse converts a subscription to its response payload
+func toWebhookSubscriptionResponse(sub *domain.WebhookSubscription) *domain.WebhookSubscriptionResponse {
+	return &domain.WebhookSubscriptionResponse{
+		ID:        sub.ID,
+		URL:       sub.URL,
+		Events:    strings.Split(sub.Events, ","),
+		Active:    sub.Active,
+		CreatedAt: domain.Timestamp(sub.CreatedAt),
+
+		MaxAttempts:         sub.MaxAttempts,
+		RetryDelaySeconds:   sub.RetryDelaySeconds,
+		TimeoutSeconds:      sub.TimeoutSeconds,
+		ConsecutiveFailures: sub.ConsecutiveFailures,
+		DisabledAt:          (*domain.Timestamp)(sub.DisabledAt),
+	}
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/mocks"
+	"github.com/aungmyozaw92/go-api-setup/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookUsecaseTestSuite struct {
+	suite.Suite
+	mockRepo *mocks.MockWebhookRepository
+	usecase  WebhookUsecase
+	ctx      context.Context
+}
+
+func (suite *WebhookUsecaseTestSuite) SetupTest() {
+	suite.mockRepo = new(mocks.MockWebhookRepository)
+	suite.usecase = NewWebhookUsecase(suite.mockRepo, "/go-api-setup")
+	suite.ctx = context.Background()
+}
+
+func (suite *WebhookUsecaseTestSuite) TearDownTest() {
+	suite.mockRepo.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookUsecaseTestSuite) TestCreateSubscription_Success() {
+	req := &domain.WebhookSubscriptionRequest{
+		URL:    "https://example.com/hooks",
+		Events: []string{domain.EventUserCreated, domain.EventUserDeleted},
+	}
+
+	suite.mockRepo.On("CreateSubscription", suite.ctx, mock.AnythingOfType("*domain.WebhookSubscription")).Return(nil).Run(func(args mock.Arguments) {
+		sub := args.Get(1).(*domain.WebhookSubscription)
+		sub.ID = 1
+	})
+
+	result, err := suite.usecase.CreateSubscription(suite.ctx, 7, req)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint(1), result.ID)
+	assert.Equal(suite.T(), req.Events, result.Events)
+	assert.True(suite.T(), result.Active)
+	assert.NotEmpty(suite.T(), result.Secret, "secret is returned on creation")
+}
+
+func (suite *WebhookUsecaseTestSuite) TestCreateSubscription_ValidationErrors() {
+	tests := []struct {
+		name        string
+		req         *domain.WebhookSubscriptionRequest
+		expectedErr string
+	}{
+		{
+			name:        "invalid url",
+			req:         &domain.WebhookSubscriptionRequest{URL: "ftp://example.com", Events: []string{domain.EventUserCreated}},
+			expectedErr: "invalid webhook url",
+		},
+		{
+			name:        "no events",
+			req:         &domain.WebhookSubscriptionRequest{URL: "https://example.com"},
+			expectedErr: "at least one event is required",
+		},
+		{
+			name:        "unknown event",
+			req:         &domain.WebhookSubscriptionRequest{URL: "https://example.com", Events: []string{"order.created"}},
+			expectedErr: "unknown webhook event: order.created",
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			result, err := suite.usecase.CreateSubscription(suite.ctx, 1, tt.req)
+
+			assert.Nil(suite.T(), result)
+			assert.EqualError(suite.T(), err, tt.expectedErr)
+		})
+	}
+}
+
+func (suite *WebhookUsecaseTestSuite) TestDispatch_QueuesMatchingSubscriptions() {
+	subs := []*domain.WebhookSubscription{
+		{ID: 1, Events: domain.EventUserCreated, Active: true},
+		{ID: 2, Events: domain.EventUserDeleted, Active: true},
+		{ID: 3, Events: domain.EventWildcard, Active: true},
+	}
+	suite.mockRepo.On("GetActiveSubscriptions", suite.ctx).Return(subs, nil)
+
+	var queued []*domain.WebhookDelivery
+	suite.mockRepo.On("CreateDelivery", suite.ctx, mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil).Run(func(args mock.Arguments) {
+		queued = append(queued, args.Get(1).(*domain.WebhookDelivery))
+	})
+
+	err := suite.usecase.Dispatch(suite.ctx, domain.EventUserCreated, map[string]interface{}{"id": 42})
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), queued, 2)
+	assert.Equal(suite.T(), uint(1), queued[0].SubscriptionID)
+	assert.Equal(suite.T(), uint(3), queued[1].SubscriptionID)
+	assert.Equal(suite.T(), domain.DeliveryStatusPending, queued[0].Status)
+
+	var payload events.Event
+	assert.NoError(suite.T(), json.Unmarshal([]byte(queued[0].Payload), &payload))
+	assert.Equal(suite.T(), events.SpecVersion, payload.SpecVersion)
+	assert.Equal(suite.T(), "/go-api-setup", payload.Source)
+	assert.Equal(suite.T(), domain.EventUserCreated, payload.Type)
+	assert.NotEmpty(suite.T(), payload.ID)
+	assert.JSONEq(suite.T(), `{"id": 42}`, string(payload.Data))
+	assert.Equal(suite.T(), queued[0].Payload, queued[1].Payload, "subscribers get the same event")
+}
+
+func (suite *WebhookUsecaseTestSuite) TestUpdateSubscription_ReactivatesWithPolicy() {
+	disabledAt := time.Now()
+	sub := &domain.WebhookSubscription{ID: 1, URL: "https://example.com/hooks", Events: domain.EventUserCreated, ConsecutiveFailures: 50, DisabledAt: &disabledAt}
+	suite.mockRepo.On("GetSubscriptionByID", suite.ctx, uint(1)).Return(sub, nil)
+	suite.mockRepo.On("UpdateSubscription", suite.ctx, sub).Return(nil)
+
+	active, maxAttempts, timeout := true, 3, 5
+	result, err := suite.usecase.UpdateSubscription(suite.ctx, 1, &domain.WebhookSubscriptionRequest{
+		URL:            sub.URL,
+		Events:         []string{domain.EventUserCreated},
+		Active:         &active,
+		MaxAttempts:    &maxAttempts,
+		TimeoutSeconds: &timeout,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), result.Active)
+	assert.Zero(suite.T(), result.ConsecutiveFailures, "reactivation clears the failures")
+	assert.Nil(suite.T(), result.DisabledAt)
+	assert.Equal(suite.T(), 3, result.MaxAttempts)
+	assert.Equal(suite.T(), 5, result.TimeoutSeconds)
+	assert.Zero(suite.T(), result.RetryDelaySeconds)
+}
+
+func (suite *WebhookUsecaseTestSuite) TestCreateSubscription_InvalidPolicy() {
+	timeout := 600
+	result, err := suite.usecase.CreateSubscription(suite.ctx, 1, &domain.WebhookSubscriptionRequest{
+		URL:            "https://example.com/hooks",
+		Events:         []string{domain.EventUserCreated},
+		TimeoutSeconds: &timeout,
+	})
+
+	assert.Nil(suite.T(), result)
+	assert.EqualError(suite.T(), err, "invalid webhook timeout_seconds: must be between 1 and 60, or 0 for the default")
+}
+
+func (suite *WebhookUsecaseTestSuite) TestDeleteSubscription_NotFound() {
+	suite.mockRepo.On("GetSubscriptionByID", suite.ctx, uint(99)).Return(nil, nil)
+
+	err := suite.usecase.DeleteSubscription(suite.ctx, 99)
+
+	assert.EqualError(suite.T(), err, "webhook not found")
+}
+
+// Run the test suite
+func TestWebhookUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookUsecaseTestSuite))
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/events"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+)
+
+// WelcomeEmail queues a welcome email for every user who signs up, linking
+// to the login page and, with email verification, to their verification
+// link
+type WelcomeEmail struct {
+	outbox   *EmailOutbox
+	emails   repository.EmailRepository
+	loginURL string
+	verifier *EmailVerifier
+}
+
+// NewWelcomeEmail creates welcome emails linking to loginURL, queued in
+// emails; verifier is nil when email verification is off
+func NewWelcomeEmail(outbox *EmailOutbox, emails repository.EmailRepository, loginURL string, verifier *EmailVerifier) *WelcomeEmail {
+	return &WelcomeEmail{outbox: outbox, emails: emails, loginURL: loginURL, verifier: verifier}
+}
+
+// Subscribe sends the welcome email when a user signs up on bus; users
+// created by an admin don't get one
+func (w *WelcomeEmail) Subscribe(bus *events.Bus) {
+	events.Subscribe(bus, "welcome_email", w.send)
+}
+
+// send queues the welcome email of a new user
+func (w *WelcomeEmail) send(ctx context.Context, e events.UserCreated) error {
+	if !e.Registered {
+		return nil
+	}
+	user := e.User
+	data := email.WelcomeData{Name: user.Name, LoginURL: w.loginURL}
+	if w.verifier != nil {
+		data.VerifyURL = w.verifier.Link(user)
+		data.VerifyExpiresIn = w.verifier.TTL()
+	}
+	if _, err := w.outbox.Queue(ctx, w.emails, user.Email, user.Locale, data); err != nil {
+		return fmt.Errorf("failed to queue welcome email for user %d: %w", user.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package worker
+
+import "sync"
+
+// consumers is embedded by queue-backed workers to process each batch of due
+// items with up to concurrency goroutines, and to let Stop drain the batch in
+// progress instead of abandoning it
+type consumers struct {
+	concurrency int
+
+	mu       sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+}
+
+func newConsumers(concurrency int) consumers {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return consumers{concurrency: concurrency}
+}
+
+// process calls fn for every item, up to concurrency at once, and returns the
+// first error once all calls have returned
+func process[T any](c *consumers, items []T, fn func(T) error) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	slots := make(chan struct{}, c.concurrency)
+	for _, item := range items {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(item T) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if err := fn(item); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(item)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// begin marks the start of a batch, reporting false once draining has
+// started; a true result must be paired with end
+func (c *consumers) begin() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.draining {
+		return false
+	}
+	c.inFlight.Add(1)
+	return true
+}
+
+// end marks the end of a batch
+func (c *consumers) end() {
+	c.inFlight.Done()
+}
+
+// drain stops new batches from starting and waits for the one in progress
+func (c *consumers) drain() {
+	c.mu.Lock()
+	c.draining = true
+	c.mu.Unlock()
+	c.inFlight.Wait()
+}
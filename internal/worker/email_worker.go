@@ -1,51 +1,186 @@
 package worker
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
 )
 
-// EmailWorker handles email processing
+// maxEmailBackoff caps the delay between send attempts
+const maxEmailBackoff = time.Hour
+
+// emailBatchSize is the number of due emails sent per tick
+const emailBatchSize = 50
+
+// emailClaimLease is how long a claimed email is held by the instance
+// sending it before another may claim it again; it exceeds a run's timeout
+const emailClaimLease = 5 * time.Minute
+
+// defaultEmailInterval is how often the outbox is checked unless configured
+const defaultEmailInterval = 30 * time.Second
+
+// EmailWorker sends the emails queued in the outbox. Each email is claimed
+// before it is sent, so instances polling the same outbox don't send it twice.
+// In database-per-tenant mode it polls every tenant's outbox after the shared
+// one.
 type EmailWorker struct {
-	ticker *time.Ticker
-	done   chan bool
+	runReporter
+	trigger
+	consumers
+	tenantScope
+	emailRepo   repository.EmailRepository
+	sender      email.Sender
+	interval    time.Duration
+	maxAttempts int
+	retryDelay  time.Duration
+	ticker      *time.Ticker
+	done        chan bool
+	logger      *slog.Logger
 }
 
-// NewEmailWorker creates a new email worker
-func NewEmailWorker() *EmailWorker {
+// NewEmailWorker creates a new email worker checking the outbox every interval
+// (default 30s) and sending up to concurrency emails at once. Failed sends are
+// retried with exponential backoff starting at retryDelay, up to maxAttempts.
+func NewEmailWorker(emailRepo repository.EmailRepository, sender email.Sender, interval time.Duration, concurrency, maxAttempts int, retryDelay time.Duration) *EmailWorker {
+	if interval <= 0 {
+		interval = defaultEmailInterval
+	}
 	return &EmailWorker{
-		done: make(chan bool),
+		trigger:     newTrigger(),
+		consumers:   newConsumers(concurrency),
+		emailRepo:   emailRepo,
+		sender:      sender,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		done:        make(chan bool),
+		logger:      slog.Default().With("worker", "EmailWorker"),
 	}
 }
 
 // Start begins email processing (implements Worker interface)
 func (w *EmailWorker) Start() {
-	w.ticker = time.NewTicker(30 * time.Second)
-	
-	log.Println("📧 Starting email worker (every 30 seconds)")
-	
+	w.ticker = time.NewTicker(w.interval)
+
+	w.logger.Info("Starting email worker", "interval", w.interval, "concurrency", w.concurrency)
+
 	for {
 		select {
 		case <-w.ticker.C:
-			// Example: Process pending emails
-			log.Printf("📧 Processing pending emails...")
-			// Add your email logic here
+			w.run()
+		case <-w.triggered():
+			w.run()
 		case <-w.done:
-			log.Println("🛑 Stopping email worker")
+			w.logger.Info("Stopping email worker")
 			return
 		}
 	}
 }
 
-// Stop gracefully stops the email worker (implements Worker interface)
+// Stop gracefully stops the email worker, waiting for the emails being sent
+// (implements Worker interface)
 func (w *EmailWorker) Stop() {
 	if w.ticker != nil {
 		w.ticker.Stop()
 	}
 	close(w.done)
+	w.drain()
+}
+
+// run sends a batch of due emails unless the worker is stopping
+func (w *EmailWorker) run() {
+	if !w.begin() {
+		return
+	}
+	defer w.end()
+	w.recordRun(w.Name(), w.processDueEmails)
 }
 
 // Name returns the worker name (implements Worker interface)
 func (w *EmailWorker) Name() string {
 	return "EmailWorker"
-} 
\ No newline at end of file
+}
+
+// processDueEmails sends every pending email whose next attempt is due, in
+// the shared outbox and each tenant's. It fails when emails can't be loaded
+// or recorded; failed sends are retried later and don't fail the run.
+func (w *EmailWorker) processDueEmails() error {
+	ctx := reporting.WithTag(context.Background(), "worker", w.Name())
+	return w.forEachDatabase(ctx, w.sendDueEmails)
+}
+
+// sendDueEmails sends the due emails of the outbox ctx is routed to
+func (w *EmailWorker) sendDueEmails(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	emails, err := w.emailRepo.GetDue(ctx, time.Now(), emailBatchSize)
+	if err != nil {
+		w.logger.Error("Error getting due emails", "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+
+	return process(&w.consumers, emails, func(msg *domain.Email) error {
+		return w.send(ctx, msg)
+	})
+}
+
+// send claims and attempts a single email and records the outcome,
+// returning repository errors. Emails claimed by another instance are
+// skipped.
+func (w *EmailWorker) send(ctx context.Context, msg *domain.Email) error {
+	claimed, err := w.emailRepo.Claim(ctx, msg, time.Now(), time.Now().Add(emailClaimLease))
+	if err != nil {
+		w.logger.Error("Error claiming email", "email_id", msg.ID, "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	err = w.sender.Send(ctx, &email.Envelope{
+		To:      msg.To,
+		Message: email.Message{Subject: msg.Subject, HTML: msg.HTML, Text: msg.Text},
+	})
+	if err != nil {
+		msg.LastError = err.Error()
+		if msg.Attempts >= w.maxAttempts {
+			msg.Status = domain.EmailStatusFailed
+			w.logger.Warn("Email failed permanently", "email_id", msg.ID, "template", msg.Template, "attempts", msg.Attempts, "error", err)
+			reporting.ReportError(ctx, err)
+		} else {
+			msg.NextAttemptAt = time.Now().Add(w.backoff(msg.Attempts))
+		}
+	} else {
+		now := time.Now()
+		msg.Status = domain.EmailStatusSent
+		msg.LastError = ""
+		msg.SentAt = &now
+	}
+
+	if err := w.emailRepo.Update(ctx, msg); err != nil {
+		w.logger.Error("Error updating email", "email_id", msg.ID, "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt after the given number of attempts
+func (w *EmailWorker) backoff(attempts int) time.Duration {
+	delay := w.retryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxEmailBackoff {
+			return maxEmailBackoff
+		}
+	}
+	return delay
+}
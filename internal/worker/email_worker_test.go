@@ -0,0 +1,215 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeSender records sent emails and fails for the listed recipients
+type fakeSender struct {
+	sent    []*email.Envelope
+	failFor map[string]bool
+}
+
+func (s *fakeSender) Send(ctx context.Context, envelope *email.Envelope) error {
+	if s.failFor[envelope.To] {
+		return errors.New("mailbox unavailable")
+	}
+	s.sent = append(s.sent, envelope)
+	return nil
+}
+
+func queueEmail(t *testing.T, repo *memory.EmailRepository, to string) *domain.Email {
+	msg := &domain.Email{To: to, Template: "welcome", Subject: "Welcome", HTML: "<p>Hi</p>", Text: "Hi", Status: domain.EmailStatusPending, NextAttemptAt: time.Now()}
+	require.NoError(t, repo.Create(context.Background(), msg))
+	return msg
+}
+
+func TestEmailWorker_ProcessDueEmails(t *testing.T) {
+	repo := memory.NewEmailRepository()
+	sender := &fakeSender{failFor: map[string]bool{"bounce@example.com": true}}
+	w := NewEmailWorker(repo, sender, time.Second, 1, 2, time.Minute)
+
+	ok := queueEmail(t, repo, "alice@example.com")
+	bounce := queueEmail(t, repo, "bounce@example.com")
+
+	require.NoError(t, w.processDueEmails())
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "alice@example.com", sender.sent[0].To)
+	assert.Equal(t, "Welcome", sender.sent[0].Subject)
+	assert.Equal(t, "<p>Hi</p>", sender.sent[0].HTML)
+
+	sent, err := repo.GetByID(context.Background(), ok.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EmailStatusSent, sent.Status)
+	assert.Equal(t, 1, sent.Attempts)
+	assert.NotNil(t, sent.SentAt)
+
+	failed, err := repo.GetByID(context.Background(), bounce.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EmailStatusPending, failed.Status, "retried later")
+	assert.Equal(t, "mailbox unavailable", failed.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), failed.NextAttemptAt, 5*time.Second)
+
+	// The last attempt marks the email failed
+	failed.NextAttemptAt = time.Now()
+	require.NoError(t, repo.Update(context.Background(), failed))
+	require.NoError(t, w.processDueEmails())
+
+	failed, err = repo.GetByID(context.Background(), bounce.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EmailStatusFailed, failed.Status)
+	assert.Equal(t, 2, failed.Attempts)
+	assert.Len(t, sender.sent, 1, "sent emails are not sent again")
+}
+
+func TestEmailWorker_SkipsEmailsClaimedElsewhere(t *testing.T) {
+	repo := memory.NewEmailRepository()
+	sender := &fakeSender{}
+	w := NewEmailWorker(repo, sender, time.Second, 1, 3, time.Minute)
+	other := NewEmailWorker(repo, sender, time.Second, 1, 3, time.Minute)
+	queueEmail(t, repo, "alice@example.com")
+
+	// Both instances load the email, but only the first to claim it sends it
+	due, err := repo.GetDue(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.NoError(t, other.processDueEmails())
+	require.NoError(t, w.send(context.Background(), due[0]))
+
+	assert.Len(t, sender.sent, 1)
+}
+
+// blockingSender counts concurrent sends and holds each until released
+type blockingSender struct {
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	sent      int
+	started   chan struct{}
+	release   chan struct{}
+}
+
+func (s *blockingSender) Send(ctx context.Context, envelope *email.Envelope) error {
+	s.mu.Lock()
+	s.active++
+	s.maxActive = max(s.maxActive, s.active)
+	s.mu.Unlock()
+
+	s.started <- struct{}{}
+	<-s.release
+
+	s.mu.Lock()
+	s.active--
+	s.sent++
+	s.mu.Unlock()
+	return nil
+}
+
+func TestEmailWorker_SendsConcurrentlyAndDrainsOnStop(t *testing.T) {
+	repo := memory.NewEmailRepository()
+	sender := &blockingSender{started: make(chan struct{}, 3), release: make(chan struct{})}
+	w := NewEmailWorker(repo, sender, time.Hour, 2, 5, time.Minute)
+	for _, to := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		queueEmail(t, repo, to)
+	}
+
+	go w.Start()
+	w.RunNow()
+	<-sender.started
+	<-sender.started
+
+	stopped := make(chan struct{})
+	go func() {
+		w.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while emails were being sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sender.release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the batch finished")
+	}
+	assert.Equal(t, 2, sender.maxActive)
+	assert.Equal(t, 3, sender.sent, "the whole batch is sent before stopping")
+}
+
+// tenantDBs is a TenantDatabases over already open databases
+type tenantDBs map[string]*gorm.DB
+
+func (t tenantDBs) Tenants() []string {
+	tenants := make([]string, 0, len(t))
+	for tenantID := range t {
+		tenants = append(tenants, tenantID)
+	}
+	return tenants
+}
+
+func (t tenantDBs) DB(ctx context.Context, tenantID string) (*gorm.DB, error) {
+	if db, ok := t[tenantID]; ok && db != nil {
+		return db, nil
+	}
+	return nil, errors.New("tenant database unavailable")
+}
+
+// newSQLiteDB opens a migrated SQLite database private to the test
+func newSQLiteDB(t *testing.T, name string) *gorm.DB {
+	db, err := database.Connect(&config.DatabaseConfig{
+		Driver:     database.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), name+".db"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, database.MigrateUp(context.Background(), db, database.DriverSQLite))
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestEmailWorker_SendsTenantEmails(t *testing.T) {
+	shared, acme := newSQLiteDB(t, "shared"), newSQLiteDB(t, "acme")
+	repo := repository.NewEmailRepository(shared)
+	sender := &fakeSender{}
+	manager := NewManager(WithTenants(tenantDBs{"acme": acme, "globex": nil}))
+	w := NewEmailWorker(repo, sender, time.Second, 1, 3, time.Minute)
+	manager.AddWorker(w)
+
+	// Queued during an acme request, the email lands in acme's outbox
+	ctx := database.WithTenant(context.Background(), "acme", acme)
+	msg := &domain.Email{To: "alice@acme.example", Template: "welcome", Subject: "Welcome", Text: "Hi", Status: domain.EmailStatusPending, NextAttemptAt: time.Now()}
+	require.NoError(t, repo.Create(ctx, msg))
+	require.NoError(t, repo.Create(context.Background(), &domain.Email{To: "bob@example.com", Template: "welcome", Subject: "Welcome", Text: "Hi", Status: domain.EmailStatusPending, NextAttemptAt: time.Now()}))
+
+	err := w.processDueEmails()
+	assert.ErrorContains(t, err, "tenant globex", "an unavailable tenant fails the run")
+
+	recipients := make([]string, 0, len(sender.sent))
+	for _, envelope := range sender.sent {
+		recipients = append(recipients, envelope.To)
+	}
+	assert.ElementsMatch(t, []string{"bob@example.com", "alice@acme.example"}, recipients, "the shared and tenant outboxes are both sent")
+
+	sent, err := repo.GetByID(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EmailStatusSent, sent.Status)
+}
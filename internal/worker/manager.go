@@ -1,10 +1,17 @@
 package worker
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"runtime/debug"
 	"sync"
+	"time"
 
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
 	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/aungmyozaw92/go-api-setup/pkg/lock"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
 )
 
 // Worker interface for all background workers
@@ -18,68 +25,207 @@ type Worker interface {
 type Manager struct {
 	workers []Worker
 	wg      sync.WaitGroup
+	logger  *slog.Logger
+	stats   *statsTracker
+
+	scheduler     *Scheduler
+	schedulerOpts []SchedulerOption
+	schedules     map[string]string
+	tenants       TenantDatabases
+
+	mu     sync.Mutex
+	states map[string]string
+}
+
+// ManagerOption configures optional Manager behaviour
+type ManagerOption func(*Manager)
+
+// WithMetrics exports the runs of instrumented workers to Prometheus
+func WithMetrics(metrics *Metrics) ManagerOption {
+	return func(m *Manager) {
+		m.stats.metrics = metrics
+	}
+}
+
+// WithFailureThreshold sets how many consecutive failed runs make a worker
+// unhealthy (default 3)
+func WithFailureThreshold(threshold int) ManagerOption {
+	return func(m *Manager) {
+		if threshold > 0 {
+			m.stats.threshold = threshold
+		}
+	}
+}
+
+// WithLocker runs scheduled jobs under distributed locks taken from locker,
+// so each job runs on one replica at a time (see WithSchedulerLocker)
+func WithLocker(locker lock.Locker, ttl time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.schedulerOpts = append(m.schedulerOpts, WithSchedulerLocker(locker, ttl))
+	}
+}
+
+// WithSchedules overrides the cron specs of scheduled jobs, by job name, so
+// they can be set from configuration
+func WithSchedules(schedules map[string]string) ManagerOption {
+	return func(m *Manager) {
+		m.schedules = schedules
+	}
+}
+
+// WithTenants makes tenant-aware workers, and the jobs scheduled through
+// ForEachDatabase, process every tenant's database after the shared one
+func WithTenants(tenants TenantDatabases) ManagerOption {
+	return func(m *Manager) {
+		m.tenants = tenants
+	}
 }
 
 // NewManager creates a new worker manager
-func NewManager() *Manager {
-	return &Manager{
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
 		workers: make([]Worker, 0),
+		logger:  slog.Default(),
+		stats:   newStatsTracker(),
+		states:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
-// AddWorker adds a worker to the manager
+// AddWorker adds a worker to the manager. Instrumented workers report
+// their runs to it, and tenant-aware workers get the tenant databases.
 func (m *Manager) AddWorker(worker Worker) {
+	if instrumented, ok := worker.(Instrumented); ok {
+		instrumented.SetRunRecorder(m.stats.recorderFor(worker.Name()))
+	}
+	if aware, ok := worker.(TenantAware); ok && m.tenants != nil {
+		aware.SetTenants(m.tenants)
+	}
 	m.workers = append(m.workers, worker)
-	log.Printf("➕ Added worker: %s", worker.Name())
+	m.setState(worker.Name(), StateStopped)
+	m.logger.Info("Added worker", "worker", worker.Name())
 }
 
 // StartAll starts all registered workers
 func (m *Manager) StartAll() {
-	log.Println("🚀 Starting all workers...")
+	m.logger.Info("Starting all workers")
 	
 	for _, worker := range m.workers {
 		m.wg.Add(1)
+		m.setState(worker.Name(), StateRunning)
 		go func(w Worker) {
 			defer m.wg.Done()
-			log.Printf("▶️  Starting worker: %s", w.Name())
+			defer m.setState(w.Name(), StateStopped)
+			defer m.recoverWorker(w)
+			m.logger.Info("Starting worker", "worker", w.Name())
 			w.Start()
 		}(worker)
 	}
 	
-	log.Printf("✅ Started %d workers", len(m.workers))
+	m.logger.Info("Started workers", "count", len(m.workers))
+}
+
+// Schedule runs job on a cron schedule. spec is used unless WithSchedules
+// configured another one for name. The jobs share one Scheduler worker,
+// added on the first call, and report their runs under their own names.
+func (m *Manager) Schedule(name, spec string, job Job) error {
+	if configured, ok := m.schedules[name]; ok {
+		spec = configured
+	}
+	if m.scheduler == nil {
+		m.scheduler = NewScheduler(m.schedulerOpts...)
+		m.AddWorker(m.scheduler)
+	}
+	return m.scheduler.Add(name, spec, job)
+}
+
+// ForEachDatabase wraps job to run on the shared database and then on every
+// tenant's database configured with WithTenants, for jobs whose data lives in
+// each of them
+func (m *Manager) ForEachDatabase(job Job) Job {
+	return func(ctx context.Context) error {
+		return forEachDatabase(ctx, m.tenants, job)
+	}
+}
+
+// recoverWorker logs and reports a panic that ended a worker, so one failing
+// worker doesn't take the whole server down
+func (m *Manager) recoverWorker(w Worker) {
+	if recovered := recover(); recovered != nil {
+		m.logger.Error("Worker panicked", "worker", w.Name(), "panic", recovered, "stack", string(debug.Stack()))
+		reporting.ReportPanic(reporting.WithTag(context.Background(), "worker", w.Name()), recovered)
+	}
+}
+
+// Stats returns the run statistics of every instrumented worker that has
+// run, sorted by name
+func (m *Manager) Stats() []Stats {
+	return m.stats.snapshot()
+}
+
+// Healthy returns an error when a worker is persistently failing: its last
+// runs, as many as the failure threshold, all failed
+func (m *Manager) Healthy() error {
+	return m.stats.healthy()
 }
 
 // StopAll stops all workers gracefully
 func (m *Manager) StopAll() {
-	log.Println("🛑 Stopping all workers...")
+	m.logger.Info("Stopping all workers")
 	
 	for _, worker := range m.workers {
 		worker.Stop()
-		log.Printf("⏹️  Stopped worker: %s", worker.Name())
+		m.logger.Info("Stopped worker", "worker", worker.Name())
 	}
 	
 	m.wg.Wait()
-	log.Println("✅ All workers stopped")
+	m.logger.Info("All workers stopped")
 }
 
-// SetupDefaultWorkers creates default workers for the application
-func SetupDefaultWorkers(userRepo repository.UserRepository) *Manager {
-	manager := NewManager()
+// SetupDefaultWorkers creates default workers for the application, honoring
+// the enable flags, intervals and schedules in cfg.Worker. It fails when a
+// configured schedule is not a valid cron spec.
+func SetupDefaultWorkers(cfg *config.Config, userRepo repository.UserRepository, emailRepo repository.EmailRepository, webhookRepo repository.WebhookRepository, sender email.Sender, opts ...ManagerOption) (*Manager, error) {
+	manager := NewManager(append([]ManagerOption{WithSchedules(cfg.Worker.Schedules)}, opts...)...)
 	
-	// Add user monitoring worker
-	userMonitor := NewUserMonitor(userRepo)
-	manager.AddWorker(userMonitor)
+	// Log the user count on a schedule
+	if cfg.Worker.UserMonitorEnabled {
+		userMonitor := NewUserMonitor(userRepo, cfg.Worker.UserMonitorInterval)
+		if err := manager.Schedule("user_count", "@every "+userMonitor.interval.String(), manager.ForEachDatabase(userMonitor.CountUsers)); err != nil {
+			return nil, err
+		}
+	}
 	
-	// Add email processing worker
-	emailWorker := NewEmailWorker()
-	manager.AddWorker(emailWorker)
+	// Send the emails queued in the outbox; every replica polls it, and each
+	// email is claimed by one of them
+	if cfg.Worker.EmailEnabled {
+		emailWorker := NewEmailWorker(emailRepo, sender, cfg.Worker.EmailInterval, cfg.Worker.EmailConcurrency, cfg.Email.MaxAttempts, cfg.Email.RetryDelay)
+		manager.AddWorker(emailWorker)
+	}
 	
-	// Add more workers here as needed:
-	// cleanupWorker := NewCleanupWorker(db)
-	// manager.AddWorker(cleanupWorker)
+	// Deliver queued webhook events, each claimed by one replica
+	if cfg.Worker.WebhookEnabled {
+		webhookWorker := NewWebhookWorker(webhookRepo, cfg.Worker.WebhookInterval, cfg.Webhook.Timeout, cfg.Worker.WebhookConcurrency, cfg.Webhook.MaxAttempts, cfg.Webhook.RetryDelay, cfg.Webhook.DisableAfter)
+		manager.AddWorker(webhookWorker)
+	}
+	
+	// Purge users soft-deleted longer than the retention period, nightly
+	if cfg.Worker.UserCleanupEnabled {
+		cleanup := NewUserCleanup(userRepo, cfg.Worker.UserRetention, cfg.Worker.UserCleanupBatchSize, manager.stats.metrics)
+		if err := manager.Schedule("user_cleanup", "0 3 * * *", manager.ForEachDatabase(cleanup.Run)); err != nil {
+			return nil, err
+		}
+	}
 	
+	// Add more workers here as needed:
 	// analyticsWorker := NewAnalyticsWorker(analyticsRepo)
 	// manager.AddWorker(analyticsWorker)
 	
-	return manager
+	// Periodic jobs are better declared as schedules:
+	// manager.Schedule("report", "0 2 * * *", report.Run)
+	
+	return manager, nil
 } 
\ No newline at end of file
@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorker runs once per result when started
+type fakeWorker struct {
+	runReporter
+	results []error
+}
+
+func (w *fakeWorker) Start() {
+	for _, err := range w.results {
+		w.recordRun(w.Name(), func() error { return err })
+	}
+}
+
+func (w *fakeWorker) Stop()        {}
+func (w *fakeWorker) Name() string { return "FakeWorker" }
+
+func TestManager_Stats(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	manager := NewManager(WithMetrics(NewMetrics(reg)))
+	manager.AddWorker(&fakeWorker{results: []error{errors.New("db down"), nil, errors.New("timeout")}})
+	manager.StartAll()
+	manager.StopAll()
+
+	stats := manager.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, "FakeWorker", stats[0].Name)
+	assert.Equal(t, uint64(3), stats[0].Runs)
+	assert.Equal(t, uint64(2), stats[0].Errors)
+	assert.Equal(t, 1, stats[0].ConsecutiveFailures)
+	assert.Equal(t, "timeout", stats[0].LastError)
+	assert.False(t, stats[0].LastSuccess.IsZero())
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(manager.stats.metrics.runs.WithLabelValues("FakeWorker")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(manager.stats.metrics.errors.WithLabelValues("FakeWorker")))
+	assert.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(manager.stats.metrics.lastSuccess.WithLabelValues("FakeWorker")), 5)
+}
+
+func TestManager_Healthy(t *testing.T) {
+	failing := errors.New("db down")
+
+	manager := NewManager(WithFailureThreshold(2))
+	manager.AddWorker(&fakeWorker{results: []error{nil, failing}})
+	manager.StartAll()
+	manager.StopAll()
+	assert.NoError(t, manager.Healthy(), "a single failure is tolerated")
+
+	manager = NewManager(WithFailureThreshold(2))
+	manager.AddWorker(&fakeWorker{results: []error{nil, failing, failing}})
+	manager.StartAll()
+	manager.StopAll()
+	err := manager.Healthy()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FakeWorker (2 consecutive failures: db down)")
+}
+
+// alertRecorder records the alerts it is sent
+type alertRecorder struct {
+	alerts []alert.Alert
+}
+
+func (r *alertRecorder) Notify(_ context.Context, a alert.Alert) { r.alerts = append(r.alerts, a) }
+func (r *alertRecorder) Flush(time.Duration) bool                { return true }
+
+func TestManager_AlertsOnFailures(t *testing.T) {
+	recorder := &alertRecorder{}
+	alert.SetDefault(recorder)
+	defer alert.SetDefault(nil)
+
+	failing := errors.New("db down")
+	manager := NewManager(WithFailureThreshold(2))
+	manager.AddWorker(&fakeWorker{results: []error{failing, nil, failing, failing, failing, nil}})
+	manager.StartAll()
+	manager.StopAll()
+
+	require.Len(t, recorder.alerts, 2, "one alert on reaching the threshold and one on recovery")
+	assert.Equal(t, alert.LevelCritical, recorder.alerts[0].Level)
+	assert.Equal(t, "FakeWorker failed 2 times in a row", recorder.alerts[0].Text)
+	assert.Equal(t, []alert.Field{{Name: "Last error", Value: "db down"}}, recorder.alerts[0].Fields)
+	assert.Equal(t, "Worker recovered", recorder.alerts[1].Title)
+	assert.Equal(t, "FakeWorker succeeded after 3 failed runs", recorder.alerts[1].Text)
+}
+
+func TestManager_RecoversWorkerPanic(t *testing.T) {
+	manager := NewManager()
+	manager.AddWorker(panickingWorker{})
+
+	assert.NotPanics(t, func() {
+		manager.StartAll()
+		manager.StopAll()
+	})
+}
+
+// panickingWorker panics when started
+type panickingWorker struct{}
+
+func (panickingWorker) Start()       { panic("boom") }
+func (panickingWorker) Stop()        {}
+func (panickingWorker) Name() string { return "PanickingWorker" }
+
+// triggeredWorker runs once per RunNow until stopped
+type triggeredWorker struct {
+	runReporter
+	trigger
+	done chan struct{}
+}
+
+func newTriggeredWorker() *triggeredWorker {
+	return &triggeredWorker{trigger: newTrigger(), done: make(chan struct{})}
+}
+
+func (w *triggeredWorker) Start() {
+	for {
+		select {
+		case <-w.triggered():
+			w.recordRun(w.Name(), func() error { return nil })
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *triggeredWorker) Stop()        { close(w.done) }
+func (w *triggeredWorker) Name() string { return "TriggeredWorker" }
+
+func TestManager_Statuses(t *testing.T) {
+	manager := NewManager()
+	manager.AddWorker(newTriggeredWorker())
+	require.NoError(t, manager.Schedule("cleanup", "@hourly", func(ctx context.Context) error {
+		return errors.New("db down")
+	}))
+
+	statuses := manager.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "Scheduler", statuses[0].Name)
+	assert.Equal(t, StateStopped, statuses[0].State)
+
+	manager.StartAll()
+	defer manager.StopAll()
+	require.NoError(t, manager.RunNow("cleanup"))
+	require.NoError(t, manager.RunNow("TriggeredWorker"))
+
+	require.Eventually(t, func() bool {
+		statuses = manager.Statuses()
+		return len(statuses[0].Jobs) == 1 && statuses[1].Runs == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateRunning, statuses[0].State)
+	assert.Equal(t, "cleanup", statuses[0].Jobs[0].Name)
+	assert.Equal(t, "db down", statuses[0].Jobs[0].LastError)
+	assert.Equal(t, "TriggeredWorker", statuses[1].Name)
+	assert.Equal(t, StateRunning, statuses[1].State)
+}
+
+func TestManager_RunNow(t *testing.T) {
+	manager := NewManager()
+	manager.AddWorker(newTriggeredWorker())
+	manager.AddWorker(&fakeWorker{})
+	release := make(chan struct{})
+	require.NoError(t, manager.Schedule("slow", "@hourly", func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+
+	assert.ErrorIs(t, manager.RunNow("TriggeredWorker"), ErrNotRunning)
+
+	manager.StartAll()
+	defer manager.StopAll()
+	assert.ErrorIs(t, manager.RunNow("missing"), ErrUnknownWorker)
+	assert.ErrorIs(t, manager.RunNow("FakeWorker"), ErrNotTriggerable)
+
+	require.NoError(t, manager.RunNow("slow"))
+	assert.ErrorIs(t, manager.RunNow("slow"), ErrAlreadyRunning)
+	close(release)
+}
+
+func TestSetupDefaultWorkers_HonorsConfig(t *testing.T) {
+	names := func(manager *Manager) []string {
+		var names []string
+		for _, status := range manager.Statuses() {
+			names = append(names, status.Name)
+		}
+		return names
+	}
+
+	cfg := &config.Config{Worker: config.WorkerConfig{
+		UserMonitorEnabled:  true,
+		UserMonitorInterval: time.Minute,
+		EmailEnabled:        true,
+		EmailInterval:       5 * time.Second,
+		WebhookEnabled:      true,
+	}}
+	manager, err := SetupDefaultWorkers(cfg, nil, memory.NewEmailRepository(), memory.NewWebhookRepository(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EmailWorker", "Scheduler", "WebhookWorker"}, names(manager))
+	assert.Equal(t, 5*time.Second, manager.workers[1].(*EmailWorker).interval)
+	assert.Equal(t, cron.ConstantDelaySchedule{Delay: time.Minute}, manager.scheduler.cron.Entries()[0].Schedule)
+
+	cfg.Worker.EmailEnabled = false
+	cfg.Worker.WebhookEnabled = false
+	cfg.Worker.UserMonitorEnabled = false
+	manager, err = SetupDefaultWorkers(cfg, nil, memory.NewEmailRepository(), memory.NewWebhookRepository(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, names(manager))
+
+	cfg.Worker.UserMonitorEnabled = true
+	cfg.Worker.Schedules = map[string]string{"user_count": "not a spec"}
+	_, err = SetupDefaultWorkers(cfg, nil, memory.NewEmailRepository(), memory.NewWebhookRepository(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,266 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/lock"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"github.com/robfig/cron/v3"
+)
+
+// minLockHold is how long a job's distributed lock is kept after a short run,
+// so replicas whose schedule fires a moment later skip that run too
+const minLockHold = 5 * time.Second
+
+// Job is a unit of scheduled work. The context is canceled when the
+// scheduler stops.
+type Job func(ctx context.Context) error
+
+// Scheduler runs jobs on cron schedules. Specs are standard five-field cron
+// expressions ("0 2 * * *" for 02:00 every night) or descriptors such as
+// "@hourly" and "@every 10s", evaluated in the server's local time unless
+// prefixed with CRON_TZ=<zone>. A run is skipped while the job's previous run
+// is still going, whether it was scheduled or started with RunJob. Each job
+// reports its runs under its own name.
+//
+// With a Locker, each run first takes the lock "job:<name>" shared by every
+// replica; a run whose lock is held elsewhere is skipped.
+type Scheduler struct {
+	runReporter
+	locker  lock.Locker
+	lockTTL time.Duration
+	cron    *cron.Cron
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	stopped bool
+	jobs    map[string]*scheduledJob
+}
+
+// scheduledJob is a job and the lock held while it runs
+type scheduledJob struct {
+	running sync.Mutex
+	run     func()
+}
+
+// SchedulerOption configures optional Scheduler behaviour
+type SchedulerOption func(*Scheduler)
+
+// WithSchedulerLocker runs each job under a distributed lock taken from
+// locker, so only one replica runs it at a time. The lock is taken for ttl
+// and extended while the job runs; ttl bounds how long a crashed replica
+// blocks the job.
+func WithSchedulerLocker(locker lock.Locker, ttl time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.locker = locker
+		if ttl > 0 {
+			s.lockTTL = ttl
+		}
+	}
+}
+
+// NewScheduler creates a scheduler with no jobs
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	logger := slog.Default().With("worker", "Scheduler")
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		lockTTL: time.Minute,
+		cron:    cron.New(cron.WithChain(cron.SkipIfStillRunning(cronLogger{logger}))),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		logger:  logger,
+		jobs:    make(map[string]*scheduledJob),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add schedules job under name. It returns an error for an invalid spec.
+func (s *Scheduler) Add(name, spec string, job Job) error {
+	scheduled := &scheduledJob{
+		run: func() { s.runExclusive(name, job) },
+	}
+	_, err := s.cron.AddFunc(spec, func() {
+		if scheduled.running.TryLock() {
+			defer scheduled.running.Unlock()
+			scheduled.run()
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for job %s: %w", spec, name, err)
+	}
+	s.mu.Lock()
+	s.jobs[name] = scheduled
+	s.mu.Unlock()
+	s.logger.Info("Scheduled job", "job", name, "schedule", spec)
+	return nil
+}
+
+// RunJob runs the named job now, in the background. It returns
+// ErrUnknownWorker for a job that isn't scheduled and ErrAlreadyRunning while
+// the job runs.
+func (s *Scheduler) RunJob(name string) error {
+	s.mu.Lock()
+	scheduled, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrUnknownWorker
+	}
+	if !scheduled.running.TryLock() {
+		return ErrAlreadyRunning
+	}
+
+	go func() {
+		defer scheduled.running.Unlock()
+		scheduled.run()
+	}()
+	return nil
+}
+
+// hasJob reports whether a job is scheduled under name
+func (s *Scheduler) hasJob(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[name]
+	return ok
+}
+
+// runExclusive runs job and records the run, under the job's distributed
+// lock when a Locker is configured
+func (s *Scheduler) runExclusive(name string, job Job) {
+	if s.locker == nil {
+		s.recordRun(name, func() error { return s.run(name, job) })
+		return
+	}
+
+	held, err := s.locker.TryAcquire(s.ctx, "job:"+name, s.lockTTL)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		s.logger.Debug("Job skipped, running on another instance", "job", name)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to acquire lock: %w", err)
+		s.logger.Error("Job not run", "job", name, "error", err)
+		reporting.ReportError(reporting.WithTag(s.ctx, "job", name), err)
+		s.recordRun(name, func() error { return err })
+		return
+	}
+
+	start := time.Now()
+	stopExtending := s.keepLock(name, held)
+	s.recordRun(name, func() error { return s.run(name, job) })
+	stopExtending()
+
+	ctx := context.WithoutCancel(s.ctx)
+	if remaining := minLockHold - time.Since(start); remaining > 0 {
+		err = held.Extend(ctx, remaining)
+	} else {
+		err = held.Release(ctx)
+	}
+	if err != nil && !errors.Is(err, lock.ErrNotAcquired) {
+		s.logger.Warn("Failed to release job lock", "job", name, "error", err)
+	}
+}
+
+// keepLock extends held every third of the lock TTL until the returned
+// function is called
+func (s *Scheduler) keepLock(name string, held lock.Lock) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(s.lockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := held.Extend(s.ctx, s.lockTTL); err != nil {
+					s.logger.Warn("Failed to extend job lock", "job", name, "error", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// run calls job, turning a panic into a failed run so it doesn't stop the
+// scheduler
+func (s *Scheduler) run(name string, job Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.logger.Error("Job panicked", "job", name, "panic", recovered, "stack", string(debug.Stack()))
+			reporting.ReportPanic(reporting.WithTag(s.ctx, "job", name), recovered)
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	if err := job(s.ctx); err != nil {
+		s.logger.Error("Job failed", "job", name, "error", err)
+		reporting.ReportError(reporting.WithTag(s.ctx, "job", name), err)
+		return err
+	}
+	return nil
+}
+
+// Start runs the scheduled jobs until Stop is called (implements Worker interface)
+func (s *Scheduler) Start() {
+	// A Stop that comes first wins, so the cron loop is never left running
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.logger.Info("Starting scheduler", "jobs", len(s.cron.Entries()))
+	s.cron.Start()
+	s.mu.Unlock()
+
+	<-s.done
+}
+
+// Stop stops scheduling jobs, cancels running ones and waits for them to
+// return (implements Worker interface)
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.cancel()
+	<-s.cron.Stop().Done()
+	close(s.done)
+	s.logger.Info("Stopped scheduler")
+}
+
+// Name returns the worker name (implements Worker interface)
+func (s *Scheduler) Name() string {
+	return "Scheduler"
+}
+
+// cronLogger adapts slog to the cron package's logger
+type cronLogger struct {
+	logger *slog.Logger
+}
+
+func (l cronLogger) Info(msg string, keysAndValues ...any) {
+	l.logger.Debug("Scheduler: "+msg, keysAndValues...)
+}
+
+func (l cronLogger) Error(err error, msg string, keysAndValues ...any) {
+	l.logger.Error("Scheduler: "+msg, append(keysAndValues, "error", err)...)
+}
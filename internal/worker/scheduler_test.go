@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/aungmyozaw92/go-api-setup/pkg/lock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_RunsJobs(t *testing.T) {
+	manager := NewManager()
+	ran := make(chan struct{}, 10)
+	require.NoError(t, manager.Schedule("tick", "@every 1s", func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}))
+	require.NoError(t, manager.Schedule("broken", "@every 1s", func(ctx context.Context) error {
+		panic("boom")
+	}))
+
+	manager.StartAll()
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job did not run")
+	}
+	// Give the panicking job, scheduled for the same second, time to finish
+	time.Sleep(100 * time.Millisecond)
+	manager.StopAll()
+
+	stats := map[string]Stats{}
+	for _, s := range manager.Stats() {
+		stats[s.Name] = s
+	}
+	assert.GreaterOrEqual(t, stats["tick"].Runs, uint64(1))
+	assert.Zero(t, stats["tick"].Errors)
+	assert.GreaterOrEqual(t, stats["broken"].Errors, uint64(1))
+	assert.Equal(t, "job panicked: boom", stats["broken"].LastError)
+}
+
+func TestScheduler_CancelsRunningJobsOnStop(t *testing.T) {
+	scheduler := NewScheduler()
+	started := make(chan struct{})
+	require.NoError(t, scheduler.Add("long", "@every 1s", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	go scheduler.Start()
+	select {
+	case <-started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job did not run")
+	}
+	scheduler.Stop()
+}
+
+func TestManager_Schedule(t *testing.T) {
+	job := func(ctx context.Context) error { return nil }
+
+	manager := NewManager()
+	err := manager.Schedule("cleanup", "not a spec", job)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid schedule "not a spec" for job cleanup`)
+
+	manager = NewManager(WithSchedules(map[string]string{"cleanup": "0 3 * * *"}))
+	require.NoError(t, manager.Schedule("cleanup", "not a spec", job), "the configured spec replaces the default")
+	require.NoError(t, manager.Schedule("report", "@daily", job))
+	assert.Len(t, manager.workers, 1, "jobs share one scheduler")
+
+	// Stopping before the scheduler goroutine starts leaves nothing running
+	manager.StartAll()
+	manager.StopAll()
+	assert.True(t, manager.scheduler.stopped)
+}
+
+func TestScheduler_LockedJobsRunOnOneReplica(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	locker := lock.NewRedisLocker(client, "")
+
+	release := make(chan struct{})
+	var runs atomic.Int32
+	job := func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	}
+
+	// Two replicas scheduling the same job
+	first := NewScheduler(WithSchedulerLocker(locker, time.Minute))
+	second := NewScheduler(WithSchedulerLocker(locker, time.Minute))
+	require.NoError(t, first.Add("report", "@hourly", job))
+	require.NoError(t, second.Add("report", "@hourly", job))
+
+	require.NoError(t, first.RunJob("report"))
+	require.Eventually(t, func() bool { return server.Exists("lock:job:report") }, time.Second, 5*time.Millisecond)
+
+	// The second replica skips the run while the first holds the lock
+	second.runExclusive("report", job)
+	assert.Equal(t, int32(1), runs.Load())
+
+	// Wait for the first replica's run to finish
+	close(release)
+	first.jobs["report"].running.Lock()
+	first.jobs["report"].running.Unlock()
+	assert.True(t, server.Exists("lock:job:report"), "short runs keep the lock for minLockHold")
+	assert.InDelta(t, minLockHold.Seconds(), server.TTL("lock:job:report").Seconds(), 1)
+}
@@ -0,0 +1,241 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/alert"
+	"github.com/aungmyozaw92/go-api-setup/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultFailureThreshold is the number of consecutive failed runs after
+// which a worker counts as persistently failing
+const defaultFailureThreshold = 3
+
+// RunRecorder records the runs of workers
+type RunRecorder interface {
+	RecordRun(worker string, duration time.Duration, err error)
+}
+
+// Instrumented is implemented by workers that report each run, so the
+// Manager can track their run counts, durations and failures
+type Instrumented interface {
+	SetRunRecorder(recorder RunRecorder)
+}
+
+// runReporter is embedded by workers to implement Instrumented
+type runReporter struct {
+	recorder RunRecorder
+}
+
+// SetRunRecorder sets where the worker reports its runs (implements Instrumented)
+func (r *runReporter) SetRunRecorder(recorder RunRecorder) {
+	r.recorder = recorder
+}
+
+// recordRun runs fn as one run of the named worker and reports it
+func (r *runReporter) recordRun(name string, fn func() error) {
+	start := time.Now()
+	err := fn()
+	if r.recorder != nil {
+		r.recorder.RecordRun(name, time.Since(start), err)
+	}
+}
+
+// Stats describes the runs of a worker
+type Stats struct {
+	Name                string
+	Runs                uint64
+	Errors              uint64
+	ConsecutiveFailures int
+	LastRun             time.Time
+	LastDuration        time.Duration
+	LastSuccess         time.Time
+	LastError           string
+}
+
+// Metrics exports worker runs to Prometheus
+type Metrics struct {
+	runs        *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	lastSuccess *prometheus.GaugeVec
+	rowsPurged  *prometheus.CounterVec
+}
+
+// NewMetrics creates worker metrics and registers them with reg
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	labels := []string{"worker"}
+	m := &Metrics{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "worker_runs_total",
+			Help:      "Background worker runs, by worker.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "worker_errors_total",
+			Help:      "Background worker runs that failed, by worker.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Name:      "worker_run_duration_seconds",
+			Help:      "Background worker run duration, by worker.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Name:      "worker_last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful run, by worker.",
+		}, labels),
+		rowsPurged: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "worker_rows_purged_total",
+			Help:      "Rows permanently deleted by cleanup workers, by table.",
+		}, []string{"table"}),
+	}
+	reg.MustRegister(m.runs, m.errors, m.duration, m.lastSuccess, m.rowsPurged)
+	return m
+}
+
+// addRowsPurged counts rows deleted from table; m may be nil
+func (m *Metrics) addRowsPurged(table string, n int64) {
+	if m == nil || n == 0 {
+		return
+	}
+	m.rowsPurged.WithLabelValues(table).Add(float64(n))
+}
+
+// statsTracker keeps the Stats of every worker and feeds Metrics
+type statsTracker struct {
+	mu        sync.Mutex
+	stats     map[string]*Stats
+	owners    map[string]string
+	metrics   *Metrics
+	threshold int
+	now       func() time.Time
+}
+
+func newStatsTracker() *statsTracker {
+	return &statsTracker{
+		stats:     make(map[string]*Stats),
+		owners:    make(map[string]string),
+		threshold: defaultFailureThreshold,
+		now:       time.Now,
+	}
+}
+
+// RecordRun records one run of a worker (implements RunRecorder)
+func (t *statsTracker) RecordRun(worker string, duration time.Duration, err error) {
+	now := t.now()
+
+	t.mu.Lock()
+	stats, ok := t.stats[worker]
+	if !ok {
+		stats = &Stats{Name: worker}
+		t.stats[worker] = stats
+	}
+	stats.Runs++
+	stats.LastRun = now
+	stats.LastDuration = duration
+	failures := stats.ConsecutiveFailures
+	if err != nil {
+		stats.Errors++
+		stats.ConsecutiveFailures++
+		stats.LastError = err.Error()
+	} else {
+		stats.ConsecutiveFailures = 0
+		stats.LastSuccess = now
+	}
+	t.mu.Unlock()
+
+	// Alert when the worker starts failing persistently and when it recovers
+	switch {
+	case err != nil && failures+1 == t.threshold:
+		alert.Send(context.Background(), alert.Alert{
+			Key:    "worker:" + worker,
+			Level:  alert.LevelCritical,
+			Title:  "Worker failing",
+			Text:   fmt.Sprintf("%s failed %d times in a row", worker, failures+1),
+			Fields: []alert.Field{{Name: "Last error", Value: err.Error()}},
+		})
+	case err == nil && failures >= t.threshold:
+		alert.Send(context.Background(), alert.Alert{
+			Key:   "worker:" + worker + ":recovered",
+			Level: alert.LevelInfo,
+			Title: "Worker recovered",
+			Text:  fmt.Sprintf("%s succeeded after %d failed runs", worker, failures),
+		})
+	}
+
+	if t.metrics != nil {
+		t.metrics.runs.WithLabelValues(worker).Inc()
+		t.metrics.duration.WithLabelValues(worker).Observe(duration.Seconds())
+		if err != nil {
+			t.metrics.errors.WithLabelValues(worker).Inc()
+		} else {
+			t.metrics.lastSuccess.WithLabelValues(worker).Set(float64(now.Unix()))
+		}
+	}
+}
+
+// recorderFor returns a recorder for the runs of the named worker. Runs it
+// records under other names, such as a Scheduler's jobs, are remembered as
+// belonging to the worker.
+func (t *statsTracker) recorderFor(owner string) RunRecorder {
+	return ownedRecorder{tracker: t, owner: owner}
+}
+
+// ownedRecorder records runs on behalf of one worker
+type ownedRecorder struct {
+	tracker *statsTracker
+	owner   string
+}
+
+// RecordRun records the run and its owner (implements RunRecorder)
+func (r ownedRecorder) RecordRun(name string, duration time.Duration, err error) {
+	r.tracker.mu.Lock()
+	r.tracker.owners[name] = r.owner
+	r.tracker.mu.Unlock()
+	r.tracker.RecordRun(name, duration, err)
+}
+
+// owner returns the worker that recorded runs under name, or ""
+func (t *statsTracker) owner(name string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.owners[name]
+}
+
+// snapshot returns a copy of every worker's Stats, sorted by name
+func (t *statsTracker) snapshot() []Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	all := make([]Stats, 0, len(t.stats))
+	for _, stats := range t.stats {
+		all = append(all, *stats)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// healthy returns an error naming the workers whose last threshold runs
+// all failed
+func (t *statsTracker) healthy() error {
+	var failing []string
+	for _, stats := range t.snapshot() {
+		if stats.ConsecutiveFailures >= t.threshold {
+			failing = append(failing, fmt.Sprintf("%s (%d consecutive failures: %s)", stats.Name, stats.ConsecutiveFailures, stats.LastError))
+		}
+	}
+	if len(failing) > 0 {
+		return fmt.Errorf("workers failing: %s", strings.Join(failing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"errors"
+	"sort"
+)
+
+// Worker states
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+)
+
+// Errors returned by Manager.RunNow
+var (
+	ErrUnknownWorker  = errors.New("unknown worker")
+	ErrNotTriggerable = errors.New("worker does not support immediate runs")
+	ErrNotRunning     = errors.New("worker is not running")
+	ErrAlreadyRunning = errors.New("job is already running")
+)
+
+// Status describes a registered worker: its state and the runs it recorded
+// under its own name. Jobs lists the runs it recorded under other names,
+// such as the jobs of a Scheduler or the job types of a job pool.
+type Status struct {
+	Stats
+	State string
+	Jobs  []Stats
+}
+
+// Triggerable is implemented by workers that can run immediately, outside
+// their schedule
+type Triggerable interface {
+	RunNow()
+}
+
+// trigger is embedded by ticker-driven workers to implement Triggerable:
+// their loop also selects on triggered
+type trigger struct {
+	ch chan struct{}
+}
+
+func newTrigger() trigger {
+	return trigger{ch: make(chan struct{}, 1)}
+}
+
+// RunNow asks the worker's loop for a run; a request made while one is
+// already pending is dropped (implements Triggerable)
+func (t trigger) RunNow() {
+	select {
+	case t.ch <- struct{}{}:
+	default:
+	}
+}
+
+// triggered delivers the requests made with RunNow
+func (t trigger) triggered() <-chan struct{} {
+	return t.ch
+}
+
+// Statuses returns the status of every registered worker, sorted by name
+func (m *Manager) Statuses() []Status {
+	byName := make(map[string]*Status, len(m.workers))
+	for _, w := range m.workers {
+		byName[w.Name()] = &Status{Stats: Stats{Name: w.Name()}, State: m.state(w.Name())}
+	}
+	for _, stats := range m.Stats() {
+		if status, ok := byName[stats.Name]; ok {
+			status.Stats = stats
+		} else if status, ok := byName[m.stats.owner(stats.Name)]; ok {
+			status.Jobs = append(status.Jobs, stats)
+		}
+	}
+
+	statuses := make([]Status, 0, len(byName))
+	for _, status := range byName {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// RunNow triggers an immediate run of the named worker, or of the scheduled
+// job with that name. Workers run in the background; RunNow doesn't wait.
+func (m *Manager) RunNow(name string) error {
+	for _, w := range m.workers {
+		if w.Name() != name {
+			continue
+		}
+		triggerable, ok := w.(Triggerable)
+		if !ok {
+			return ErrNotTriggerable
+		}
+		if m.state(name) != StateRunning {
+			return ErrNotRunning
+		}
+		triggerable.RunNow()
+		return nil
+	}
+
+	if m.scheduler != nil && m.scheduler.hasJob(name) {
+		if m.state(m.scheduler.Name()) != StateRunning {
+			return ErrNotRunning
+		}
+		return m.scheduler.RunJob(name)
+	}
+	return ErrUnknownWorker
+}
+
+func (m *Manager) setState(name, state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[name] = state
+}
+
+func (m *Manager) state(name string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.states[name]
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"gorm.io/gorm"
+)
+
+// TenantDatabases lists the tenants of database-per-tenant mode and opens
+// their databases
+type TenantDatabases interface {
+	Tenants() []string
+	DB(ctx context.Context, tenantID string) (*gorm.DB, error)
+}
+
+// TenantAware is implemented by workers whose data also lives in every
+// tenant's database, so the Manager can hand them the tenant databases
+type TenantAware interface {
+	SetTenants(tenants TenantDatabases)
+}
+
+// tenantScope is embedded by workers to implement TenantAware
+type tenantScope struct {
+	tenants TenantDatabases
+}
+
+// SetTenants makes the worker process every tenant's database after the
+// shared one (implements TenantAware)
+func (s *tenantScope) SetTenants(tenants TenantDatabases) {
+	s.tenants = tenants
+}
+
+// forEachDatabase runs fn on the shared database and then on each tenant's
+func (s *tenantScope) forEachDatabase(ctx context.Context, fn func(ctx context.Context) error) error {
+	return forEachDatabase(ctx, s.tenants, fn)
+}
+
+// forEachDatabase runs fn with ctx, routed to the shared database, and then
+// with ctx routed to each tenant's database in turn. A failing database
+// doesn't stop the others; the errors are joined. With no tenants fn only
+// runs on the shared database.
+func forEachDatabase(ctx context.Context, tenants TenantDatabases, fn func(ctx context.Context) error) error {
+	errs := []error{fn(ctx)}
+	if tenants == nil {
+		return errs[0]
+	}
+	for _, tenantID := range tenants.Tenants() {
+		if ctx.Err() != nil {
+			break
+		}
+		db, err := tenants.DB(ctx, tenantID)
+		if err == nil {
+			err = fn(database.WithTenant(ctx, tenantID, db))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+)
+
+// defaultUserCleanupBatchSize is how many users a cleanup deletes per
+// statement unless configured
+const defaultUserCleanupBatchSize = 500
+
+// UserCleanup permanently deletes users that were soft-deleted longer than
+// the retention period ago. It purges in batches so no statement holds locks
+// on many rows, and runs on a schedule through its Run job. Run purges the
+// database ctx is routed to; in database-per-tenant mode it is scheduled
+// through Manager.ForEachDatabase to purge every tenant's.
+type UserCleanup struct {
+	userRepo  repository.UserRepository
+	retention time.Duration
+	batchSize int
+	metrics   *Metrics
+	logger    *slog.Logger
+}
+
+// NewUserCleanup creates a cleanup purging users soft-deleted more than
+// retention ago, batchSize at a time (default 500). metrics may be nil.
+func NewUserCleanup(userRepo repository.UserRepository, retention time.Duration, batchSize int, metrics *Metrics) *UserCleanup {
+	if batchSize <= 0 {
+		batchSize = defaultUserCleanupBatchSize
+	}
+	return &UserCleanup{
+		userRepo:  userRepo,
+		retention: retention,
+		batchSize: batchSize,
+		metrics:   metrics,
+		logger:    slog.Default().With("worker", "UserCleanup"),
+	}
+}
+
+// Run purges every user soft-deleted before the retention cutoff. It is a
+// Job; when ctx is canceled it stops between batches.
+func (c *UserCleanup) Run(ctx context.Context) error {
+	cutoff := time.Now().Add(-c.retention)
+
+	var total int64
+	for ctx.Err() == nil {
+		purged, err := c.userRepo.PurgeDeleted(ctx, cutoff, c.batchSize)
+		total += purged
+		c.metrics.addRowsPurged("users", purged)
+		if err != nil {
+			return err
+		}
+		if purged < int64(c.batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		c.logger.Info("Purged soft-deleted users", "count", total, "deleted_before", cutoff)
+	}
+	return ctx.Err()
+}
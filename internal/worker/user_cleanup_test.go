@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserCleanup_PurgesInBatches(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewUserRepository()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.Create(ctx, &domain.User{Name: "User", Email: fmt.Sprintf("user-%d@example.com", i)}))
+	}
+	require.NoError(t, repo.Delete(ctx, 1))
+	require.NoError(t, repo.Delete(ctx, 2))
+	require.NoError(t, repo.Delete(ctx, 3))
+
+	// A zero retention purges every soft-deleted user
+	metrics := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, NewUserCleanup(repo, 0, 2, metrics).Run(ctx))
+
+	all, err := repo.Unscoped().GetAll(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 2, "live users are kept")
+	assert.Equal(t, uint(4), all[0].ID)
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.rowsPurged.WithLabelValues("users")))
+
+	// Recently deleted users are kept until the retention has passed
+	require.NoError(t, repo.Delete(ctx, 4))
+	require.NoError(t, NewUserCleanup(repo, time.Hour, 2, nil).Run(ctx))
+	all, err = repo.Unscoped().GetAll(ctx, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestUserCleanup_BatchesUntilShortBatch(t *testing.T) {
+	repo := &mocks.MockUserRepository{}
+	repo.On("PurgeDeleted", mock.Anything, mock.Anything, 10).Return(int64(10), nil).Twice()
+	repo.On("PurgeDeleted", mock.Anything, mock.Anything, 10).Return(int64(3), nil).Once()
+
+	require.NoError(t, NewUserCleanup(repo, 30*24*time.Hour, 10, nil).Run(context.Background()))
+	repo.AssertNumberOfCalls(t, "PurgeDeleted", 3)
+
+	cutoff := repo.Calls[0].Arguments.Get(1).(time.Time)
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), cutoff, time.Minute)
+}
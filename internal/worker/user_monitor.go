@@ -2,52 +2,90 @@ package worker
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
 )
 
+// defaultUserMonitorInterval is how often the user count is logged unless
+// configured
+const defaultUserMonitorInterval = 10 * time.Second
+
 // UserMonitor handles user count monitoring
 type UserMonitor struct {
+	runReporter
+	trigger
+	tenantScope
 	userRepo repository.UserRepository
+	interval time.Duration
 	ticker   *time.Ticker
 	done     chan bool
+	logger   *slog.Logger
 }
 
-// NewUserMonitor creates a new user monitor
-func NewUserMonitor(userRepo repository.UserRepository) *UserMonitor {
+// NewUserMonitor creates a new user monitor logging the user count every
+// interval (default 10s)
+func NewUserMonitor(userRepo repository.UserRepository, interval time.Duration) *UserMonitor {
+	if interval <= 0 {
+		interval = defaultUserMonitorInterval
+	}
 	return &UserMonitor{
+		trigger:  newTrigger(),
 		userRepo: userRepo,
+		interval: interval,
 		done:     make(chan bool),
+		logger:   slog.Default().With("worker", "UserCountMonitor"),
 	}
 }
 
 // Start begins the user count monitoring (implements Worker interface)
 func (m *UserMonitor) Start() {
-	m.ticker = time.NewTicker(10 * time.Second)
+	m.ticker = time.NewTicker(m.interval)
 	
-	log.Println("📊 Starting user count monitoring (every 10 seconds)")
+	m.logger.Info("Starting user count monitoring", "interval", m.interval)
 	
 	for {
 		select {
 		case <-m.ticker.C:
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			count, err := m.userRepo.Count(ctx)
-			cancel()
-			
-			if err != nil {
-				log.Printf("❌ Error getting user count: %v", err)
-			} else {
-				log.Printf("👥 Current user count: %d", count)
-			}
+			m.recordRun(m.Name(), m.logUserCount)
+		case <-m.triggered():
+			m.recordRun(m.Name(), m.logUserCount)
 		case <-m.done:
-			log.Println("🛑 Stopping user count monitoring")
+			m.logger.Info("Stopping user count monitoring")
 			return
 		}
 	}
 }
 
+// logUserCount logs the current number of users
+func (m *UserMonitor) logUserCount() error {
+	ctx := context.Background()
+	if err := m.forEachDatabase(ctx, m.CountUsers); err != nil {
+		m.logger.Error("Error getting user count", "error", err)
+		reporting.ReportError(reporting.WithTag(ctx, "worker", m.Name()), err)
+		return err
+	}
+	return nil
+}
+
+// CountUsers logs the current number of users in the database ctx is routed
+// to. It is a Job, so it can run on a Scheduler instead of the monitor's own
+// ticker.
+func (m *UserMonitor) CountUsers(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	count, err := m.userRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	m.logger.Info("Current user count", "count", count, "tenant", database.TenantID(ctx))
+	return nil
+}
+
 // Stop gracefully stops the user monitoring (implements Worker interface)
 func (m *UserMonitor) Stop() {
 	if m.ticker != nil {
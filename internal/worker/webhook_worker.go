@@ -0,0 +1,314 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository"
+	"github.com/aungmyozaw92/go-api-setup/pkg/events"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reporting"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webhook"
+)
+
+// maxWebhookBackoff caps the delay between delivery attempts
+const maxWebhookBackoff = time.Hour
+
+// defaultWebhookInterval is how often due deliveries are sent unless configured
+const defaultWebhookInterval = 15 * time.Second
+
+// webhookClaimMargin is how long a claimed delivery is held beyond its
+// timeout, for recording the outcome, before another instance may claim it
+// again
+const webhookClaimMargin = time.Minute
+
+// webhookBatchSize is the number of due deliveries processed per tick
+const webhookBatchSize = 50
+
+// WebhookWorker delivers queued webhook events to subscribers. Each delivery
+// is claimed before it is sent, so instances polling the same table don't
+// send it twice. In database-per-tenant mode it polls every tenant's
+// deliveries after the shared ones.
+type WebhookWorker struct {
+	runReporter
+	trigger
+	consumers
+	tenantScope
+	webhookRepo  repository.WebhookRepository
+	client       *http.Client
+	interval     time.Duration
+	timeout      time.Duration
+	maxAttempts  int
+	retryDelay   time.Duration
+	disableAfter int
+	ticker       *time.Ticker
+	done         chan bool
+	logger       *slog.Logger
+}
+
+// NewWebhookWorker creates a new webhook delivery worker sending due
+// deliveries every interval (default 15s), up to concurrency at once, each
+// timing out after timeout. Failed deliveries are retried with exponential
+// backoff starting at retryDelay, up to maxAttempts; subscriptions may
+// override all three. A subscription is disabled after disableAfter
+// consecutive failed attempts (0 never disables).
+func NewWebhookWorker(webhookRepo repository.WebhookRepository, interval, timeout time.Duration, concurrency, maxAttempts int, retryDelay time.Duration, disableAfter int) *WebhookWorker {
+	if interval <= 0 {
+		interval = defaultWebhookInterval
+	}
+	return &WebhookWorker{
+		trigger:      newTrigger(),
+		consumers:    newConsumers(concurrency),
+		webhookRepo:  webhookRepo,
+		client:       &http.Client{},
+		interval:     interval,
+		timeout:      timeout,
+		maxAttempts:  maxAttempts,
+		retryDelay:   retryDelay,
+		disableAfter: disableAfter,
+		done:         make(chan bool),
+		logger:       slog.Default().With("worker", "WebhookWorker"),
+	}
+}
+
+// Start begins webhook delivery processing (implements Worker interface)
+func (w *WebhookWorker) Start() {
+	w.ticker = time.NewTicker(w.interval)
+
+	w.logger.Info("Starting webhook worker", "interval", w.interval, "concurrency", w.concurrency)
+
+	for {
+		select {
+		case <-w.ticker.C:
+			w.run()
+		case <-w.triggered():
+			w.run()
+		case <-w.done:
+			w.logger.Info("Stopping webhook worker")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the webhook worker, waiting for the deliveries in
+// progress (implements Worker interface)
+func (w *WebhookWorker) Stop() {
+	if w.ticker != nil {
+		w.ticker.Stop()
+	}
+	close(w.done)
+	w.drain()
+}
+
+// run sends a batch of due deliveries unless the worker is stopping
+func (w *WebhookWorker) run() {
+	if !w.begin() {
+		return
+	}
+	defer w.end()
+	w.recordRun(w.Name(), w.processDueDeliveries)
+}
+
+// Name returns the worker name (implements Worker interface)
+func (w *WebhookWorker) Name() string {
+	return "WebhookWorker"
+}
+
+// processDueDeliveries sends every pending delivery whose next attempt is
+// due, in the shared database and each tenant's. It fails when deliveries
+// can't be loaded or recorded; failed sends are retried later and don't fail
+// the run.
+func (w *WebhookWorker) processDueDeliveries() error {
+	ctx := reporting.WithTag(context.Background(), "worker", w.Name())
+	return w.forEachDatabase(ctx, w.deliverDue)
+}
+
+// deliverDue sends the due deliveries of the database ctx is routed to. Each
+// delivery gets its own deadline, so a batch of slow endpoints can't run
+// later deliveries out of time.
+func (w *WebhookWorker) deliverDue(ctx context.Context) error {
+	loadCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	deliveries, err := w.webhookRepo.GetDueDeliveries(loadCtx, time.Now(), webhookBatchSize)
+	cancel()
+	if err != nil {
+		w.logger.Error("Error getting due webhook deliveries", "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+
+	return process(&w.consumers, deliveries, func(delivery *domain.WebhookDelivery) error {
+		return w.deliver(ctx, delivery)
+	})
+}
+
+// deliver claims and attempts a single delivery and records the outcome,
+// returning repository errors. Deliveries claimed by another instance are
+// skipped. The delivery runs under its own deadline, the endpoint's timeout
+// plus time to record the outcome, for as long as it is claimed.
+func (w *WebhookWorker) deliver(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	lookupCtx, cancel := context.WithTimeout(ctx, webhookClaimMargin)
+	sub, err := w.webhookRepo.GetSubscriptionByID(lookupCtx, delivery.SubscriptionID)
+	cancel()
+	if err != nil {
+		w.logger.Error("Error getting webhook", "subscription_id", delivery.SubscriptionID, "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+
+	hold := webhookClaimMargin
+	if sub != nil {
+		hold += w.timeoutFor(sub)
+	}
+	ctx, cancel = context.WithTimeout(ctx, hold)
+	defer cancel()
+
+	claimed, err := w.webhookRepo.ClaimDelivery(ctx, delivery, time.Now(), time.Now().Add(hold))
+	if err != nil {
+		w.logger.Error("Error claiming webhook delivery", "delivery_id", delivery.ID, "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	if sub == nil || !sub.Active {
+		delivery.Status = domain.DeliveryStatusFailed
+		delivery.LastError = "subscription deleted or inactive"
+		return w.updateDelivery(ctx, delivery)
+	}
+
+	statusCode, sendErr := w.send(ctx, sub, delivery)
+	if sendErr != nil && ctx.Err() != nil {
+		// Our own context ended, not the endpoint's timeout: try again
+		// without counting the attempt or the failure against the endpoint
+		w.logger.Info("Webhook delivery interrupted, requeued", "delivery_id", delivery.ID, "error", sendErr)
+		delivery.Attempts--
+		delivery.NextAttemptAt = time.Now()
+		return w.updateDelivery(context.WithoutCancel(ctx), delivery)
+	}
+
+	delivery.ResponseStatus = statusCode
+	if sendErr != nil {
+		delivery.LastError = sendErr.Error()
+		if delivery.Attempts >= w.maxAttemptsFor(sub) {
+			delivery.Status = domain.DeliveryStatusFailed
+			w.logger.Warn("Webhook delivery failed permanently", "delivery_id", delivery.ID, "attempts", delivery.Attempts, "error", sendErr)
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(w.backoff(w.retryDelayFor(sub), delivery.Attempts))
+		}
+	} else {
+		now := time.Now()
+		delivery.Status = domain.DeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	}
+
+	if err := w.updateDelivery(ctx, delivery); err != nil {
+		return err
+	}
+
+	disabled, err := w.webhookRepo.RecordDeliveryResult(ctx, sub.ID, sendErr == nil, w.disableAfter, time.Now())
+	if err != nil {
+		w.logger.Error("Error recording webhook delivery result", "subscription_id", sub.ID, "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+	if disabled {
+		w.logger.Warn("Webhook disabled after consecutive failures", "subscription_id", sub.ID, "url", sub.URL, "failures", w.disableAfter, "error", sendErr)
+	}
+	return nil
+}
+
+// updateDelivery saves the outcome of a delivery attempt
+func (w *WebhookWorker) updateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if err := w.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		w.logger.Error("Error updating webhook delivery", "delivery_id", delivery.ID, "error", err)
+		reporting.ReportError(ctx, err)
+		return err
+	}
+	return nil
+}
+
+// maxAttemptsFor returns how many times deliveries to sub are attempted
+func (w *WebhookWorker) maxAttemptsFor(sub *domain.WebhookSubscription) int {
+	if sub.MaxAttempts > 0 {
+		return sub.MaxAttempts
+	}
+	return w.maxAttempts
+}
+
+// retryDelayFor returns the delay before the first retry of deliveries to sub
+func (w *WebhookWorker) retryDelayFor(sub *domain.WebhookSubscription) time.Duration {
+	if sub.RetryDelaySeconds > 0 {
+		return time.Duration(sub.RetryDelaySeconds) * time.Second
+	}
+	return w.retryDelay
+}
+
+// timeoutFor returns how long a delivery to sub may take
+func (w *WebhookWorker) timeoutFor(sub *domain.WebhookSubscription) time.Duration {
+	if sub.TimeoutSeconds > 0 {
+		return time.Duration(sub.TimeoutSeconds) * time.Second
+	}
+	return w.timeout
+}
+
+// send POSTs the signed payload to the subscriber, returning the response status
+func (w *WebhookWorker) send(ctx context.Context, sub *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	now := time.Now()
+
+	timeout := w.timeoutFor(sub)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", events.ContentType)
+	req.Header.Set("User-Agent", "go-api-setup-webhooks/1.0")
+	req.Header.Set(webhook.HeaderEvent, delivery.Event)
+	req.Header.Set(webhook.HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(sub.Secret, now, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+			return 0, fmt.Errorf("timed out after %s", timeout)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt after the given number
+// of attempts, starting from base
+func (w *WebhookWorker) backoff(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxWebhookBackoff {
+			return maxWebhookBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/internal/repository/memory"
+	"github.com/aungmyozaw92/go-api-setup/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queueDelivery(t *testing.T, repo *memory.WebhookRepository, sub *domain.WebhookSubscription) *domain.WebhookDelivery {
+	delivery := &domain.WebhookDelivery{SubscriptionID: sub.ID, Event: domain.EventUserCreated, Payload: `{"event":"user.created"}`, Status: domain.DeliveryStatusPending, NextAttemptAt: time.Now()}
+	require.NoError(t, repo.CreateDelivery(context.Background(), delivery))
+	return delivery
+}
+
+func getDelivery(t *testing.T, repo *memory.WebhookRepository, subID uint) *domain.WebhookDelivery {
+	deliveries, err := repo.GetDeliveriesBySubscription(context.Background(), subID, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	return deliveries[0]
+}
+
+func TestWebhookWorker_SignsDeliveries(t *testing.T) {
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = webhook.Verify("s3cret", r.Header.Get(webhook.HeaderSignature), r.Header.Get(webhook.HeaderTimestamp), body, time.Minute)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := memory.NewWebhookRepository()
+	sub := &domain.WebhookSubscription{URL: server.URL, Events: domain.EventWildcard, Secret: "s3cret", Active: true, ConsecutiveFailures: 2}
+	require.NoError(t, repo.CreateSubscription(context.Background(), sub))
+	queueDelivery(t, repo, sub)
+
+	w := NewWebhookWorker(repo, time.Second, time.Second, 1, 3, time.Minute, 5)
+	require.NoError(t, w.processDueDeliveries())
+
+	assert.NoError(t, verifyErr)
+	delivery := getDelivery(t, repo, sub.ID)
+	assert.Equal(t, domain.DeliveryStatusSucceeded, delivery.Status)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+
+	stored, err := repo.GetSubscriptionByID(context.Background(), sub.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.ConsecutiveFailures, "a success resets the failures")
+}
+
+func TestWebhookWorker_SkipsDeliveriesClaimedElsewhere(t *testing.T) {
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := memory.NewWebhookRepository()
+	sub := &domain.WebhookSubscription{URL: server.URL, Events: domain.EventWildcard, Secret: "s3cret", Active: true}
+	require.NoError(t, repo.CreateSubscription(context.Background(), sub))
+	queueDelivery(t, repo, sub)
+
+	// Both instances load the delivery, but only the first to claim it sends it
+	due, err := repo.GetDueDeliveries(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	w := NewWebhookWorker(repo, time.Second, time.Second, 1, 3, time.Minute, 5)
+	other := NewWebhookWorker(repo, time.Second, time.Second, 1, 3, time.Minute, 5)
+	require.NoError(t, other.processDueDeliveries())
+	require.NoError(t, w.deliver(context.Background(), due[0]))
+
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 1, getDelivery(t, repo, sub.ID).Attempts)
+}
+
+func TestWebhookWorker_InterruptedDeliveryIsNotAFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := memory.NewWebhookRepository()
+	sub := &domain.WebhookSubscription{URL: server.URL, Events: domain.EventWildcard, Secret: "s3cret", Active: true, ConsecutiveFailures: 2}
+	require.NoError(t, repo.CreateSubscription(context.Background(), sub))
+	delivery := queueDelivery(t, repo, sub)
+
+	// The worker's context ends before the endpoint answers
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := NewWebhookWorker(repo, time.Second, time.Second, 1, 1, time.Minute, 3)
+	require.NoError(t, w.deliver(ctx, delivery))
+
+	stored := getDelivery(t, repo, sub.ID)
+	assert.Equal(t, domain.DeliveryStatusPending, stored.Status, "retried although it was the last attempt")
+	assert.Zero(t, stored.Attempts)
+	storedSub, err := repo.GetSubscriptionByID(context.Background(), sub.ID)
+	require.NoError(t, err)
+	assert.True(t, storedSub.Active)
+	assert.Equal(t, 2, storedSub.ConsecutiveFailures, "the endpoint isn't blamed")
+}
+
+func TestWebhookWorker_PerEndpointPolicy(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	repo := memory.NewWebhookRepository()
+	sub := &domain.WebhookSubscription{URL: slow.URL, Events: domain.EventWildcard, Secret: "s3cret", Active: true, MaxAttempts: 1, TimeoutSeconds: 1}
+	require.NoError(t, repo.CreateSubscription(context.Background(), sub))
+	queueDelivery(t, repo, sub)
+
+	// The worker defaults would wait a minute and retry up to 5 times
+	w := NewWebhookWorker(repo, time.Second, time.Minute, 1, 5, time.Minute, 0)
+	start := time.Now()
+	require.NoError(t, w.processDueDeliveries())
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	delivery := getDelivery(t, repo, sub.ID)
+	assert.Equal(t, domain.DeliveryStatusFailed, delivery.Status, "the subscription allows a single attempt")
+	assert.Equal(t, "timed out after 1s", delivery.LastError)
+}
+
+func TestWebhookWorker_DisablesFailingEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	repo := memory.NewWebhookRepository()
+	sub := &domain.WebhookSubscription{URL: server.URL, Events: domain.EventWildcard, Secret: "s3cret", Active: true}
+	require.NoError(t, repo.CreateSubscription(context.Background(), sub))
+	for range 3 {
+		queueDelivery(t, repo, sub)
+	}
+
+	w := NewWebhookWorker(repo, time.Second, time.Second, 1, 5, time.Minute, 2)
+	require.NoError(t, w.processDueDeliveries())
+
+	stored, err := repo.GetSubscriptionByID(context.Background(), sub.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Active)
+	assert.NotNil(t, stored.DisabledAt)
+	assert.Equal(t, 2, stored.ConsecutiveFailures)
+
+	deliveries, err := repo.GetDeliveriesBySubscription(context.Background(), sub.ID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, domain.DeliveryStatusFailed, deliveries[0].Status, "deliveries to a disabled endpoint are dropped")
+	assert.Equal(t, "subscription deleted or inactive", deliveries[0].LastError)
+	assert.Equal(t, domain.DeliveryStatusPending, deliveries[1].Status)
+	assert.Equal(t, "unexpected status 502", deliveries[1].LastError)
+}
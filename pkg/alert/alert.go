@@ -0,0 +1,98 @@
+// Package alert posts operational alerts, such as a worker that keeps
+// failing, a spike in server errors or a new admin account, to a chat
+// channel, so small teams without a full alerting stack still hear about
+// them.
+//
+// Code that notices such an event calls Send; nothing is posted until main
+// installs a Notifier with SetDefault.
+package alert
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of an alert
+type Level string
+
+// Alert levels
+const (
+	LevelInfo     Level = "info"
+	LevelWarning  Level = "warning"
+	LevelCritical Level = "critical"
+)
+
+// Alert is one message for the operators
+type Alert struct {
+	// Key identifies the condition alerted about: repeats of a key within
+	// the notifier's cooldown are dropped. Empty uses Title.
+	Key   string
+	Level Level
+	Title string
+	Text  string
+	// Fields are shown as a table below Text, in order
+	Fields []Field
+}
+
+// Field is a named detail of an alert
+type Field struct {
+	Name  string
+	Value string
+}
+
+// key returns the key repeats of the alert are recognized by
+func (a *Alert) key() string {
+	if a.Key != "" {
+		return a.Key
+	}
+	return a.Title
+}
+
+// Notifier posts alerts
+type Notifier interface {
+	// Notify queues a to be posted without waiting for it
+	Notify(ctx context.Context, a Alert)
+	// Flush waits up to timeout for queued alerts to be posted
+	Flush(timeout time.Duration) bool
+}
+
+// nopNotifier drops every alert
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, Alert) {}
+func (nopNotifier) Flush(time.Duration) bool      { return true }
+
+// Nop returns a Notifier that drops every alert
+func Nop() Notifier {
+	return nopNotifier{}
+}
+
+// holder lets a Notifier of any type be stored atomically
+type holder struct {
+	notifier Notifier
+}
+
+var defaultNotifier atomic.Pointer[holder]
+
+func init() {
+	defaultNotifier.Store(&holder{notifier: Nop()})
+}
+
+// SetDefault makes n the notifier used by Send; nil restores Nop
+func SetDefault(n Notifier) {
+	if n == nil {
+		n = Nop()
+	}
+	defaultNotifier.Store(&holder{notifier: n})
+}
+
+// Default returns the notifier installed with SetDefault
+func Default() Notifier {
+	return defaultNotifier.Load().notifier
+}
+
+// Send queues a with the default notifier
+func Send(ctx context.Context, a Alert) {
+	Default().Notify(ctx, a)
+}
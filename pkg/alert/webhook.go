@@ -0,0 +1,328 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+)
+
+// Format is the message format of a chat webhook
+type Format string
+
+// Webhook formats
+const (
+	// FormatAuto detects the format from the webhook URL
+	FormatAuto    Format = "auto"
+	FormatSlack   Format = "slack"
+	FormatDiscord Format = "discord"
+)
+
+// defaultQueueSize is how many alerts can wait to be posted before new
+// ones are dropped
+const defaultQueueSize = 100
+
+// WebhookConfig holds settings for a Webhook notifier
+type WebhookConfig struct {
+	// URL is a Slack or Discord incoming webhook URL
+	URL string
+	// Format is the message format; empty or FormatAuto detects it from
+	// the URL's host. Set FormatSlack for Slack-compatible chats such as
+	// Mattermost.
+	Format Format
+	// Cooldown drops repeats of an alert key for this long after it was
+	// posted; zero posts every alert
+	Cooldown time.Duration
+	// Source names the instance sending the alerts in their footer; empty
+	// uses the host name
+	Source string
+	// HTTPClient posts the alerts; nil uses a client with a 10s timeout
+	HTTPClient *http.Client
+}
+
+// Webhook posts alerts to a Slack or Discord incoming webhook from a
+// background goroutine, so callers never wait on the chat service
+type Webhook struct {
+	url      string
+	format   Format
+	cooldown time.Duration
+	source   string
+	client   *http.Client
+	now      func() time.Time
+
+	queue   chan queuedAlert
+	pending sync.WaitGroup
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// queuedAlert is an alert waiting to be posted
+type queuedAlert struct {
+	ctx   context.Context
+	alert Alert
+	at    time.Time
+}
+
+// NewWebhook creates a notifier posting to cfg.URL and starts its sender
+func NewWebhook(cfg WebhookConfig) (*Webhook, error) {
+	format, err := ResolveFormat(cfg.URL, cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	source := cfg.Source
+	if source == "" {
+		source, _ = os.Hostname()
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	w := &Webhook{
+		url:      cfg.URL,
+		format:   format,
+		cooldown: cfg.Cooldown,
+		source:   source,
+		client:   client,
+		now:      time.Now,
+		queue:    make(chan queuedAlert, defaultQueueSize),
+		sent:     make(map[string]time.Time),
+	}
+	go w.run()
+	return w, nil
+}
+
+// ResolveFormat checks a webhook URL and returns the format alerts to it are
+// posted in: format, or for FormatAuto and empty the one detected from the
+// URL
+func ResolveFormat(rawURL string, format Format) (Format, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", errors.New("alert webhook URL must be an http or https URL")
+	}
+	switch format {
+	case "", FormatAuto:
+		return detectFormat(u)
+	case FormatSlack, FormatDiscord:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown alert webhook format %q", format)
+}
+
+// detectFormat tells the format of a webhook from its URL
+func detectFormat(u *url.URL) (Format, error) {
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "hooks.slack.com":
+		return FormatSlack, nil
+	case (host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com")) &&
+		strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return FormatDiscord, nil
+	}
+	return "", fmt.Errorf("cannot tell whether %s is a Slack or Discord webhook; set the format", host)
+}
+
+// Format returns the message format alerts are posted in
+func (w *Webhook) Format() Format {
+	return w.format
+}
+
+// Notify queues a to be posted, unless an alert with the same key was
+// posted within the cooldown or the queue is full (implements Notifier)
+func (w *Webhook) Notify(ctx context.Context, a Alert) {
+	now := w.now()
+	key := a.key()
+
+	w.mu.Lock()
+	if last, ok := w.sent[key]; ok && now.Sub(last) < w.cooldown {
+		w.mu.Unlock()
+		return
+	}
+	w.sent[key] = now
+	w.mu.Unlock()
+
+	w.pending.Add(1)
+	select {
+	case w.queue <- queuedAlert{ctx: context.WithoutCancel(ctx), alert: a, at: now}:
+	default:
+		w.pending.Done()
+		logger.FromContext(ctx).Warn("Dropped alert, too many waiting to be posted", "title", a.Title)
+	}
+}
+
+// Flush waits up to timeout for queued alerts to be posted (implements
+// Notifier)
+func (w *Webhook) Flush(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		w.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// run posts queued alerts one at a time
+func (w *Webhook) run() {
+	for q := range w.queue {
+		if err := w.post(q.ctx, q.alert, q.at); err != nil {
+			logger.FromContext(q.ctx).Warn("Failed to post alert", "title", q.alert.Title, "error", err)
+		}
+		w.pending.Done()
+	}
+}
+
+// post sends one alert to the webhook
+func (w *Webhook) post(ctx context.Context, a Alert, at time.Time) error {
+	var message any
+	if w.format == FormatDiscord {
+		message = w.discordMessage(a, at)
+	} else {
+		message = w.slackMessage(a, at)
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("alert webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// slackMessage is the body of a Slack incoming webhook
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Text   string       `json:"text,omitempty"`
+	Fields []slackField `json:"fields,omitempty"`
+	Footer string       `json:"footer,omitempty"`
+	Ts     int64        `json:"ts"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// slackIcons and slackColors mark the level of Slack messages
+var (
+	slackIcons  = map[Level]string{LevelInfo: ":information_source:", LevelWarning: ":warning:", LevelCritical: ":rotating_light:"}
+	slackColors = map[Level]string{LevelInfo: "#439fe0", LevelWarning: "warning", LevelCritical: "danger"}
+)
+
+func (w *Webhook) slackMessage(a Alert, at time.Time) slackMessage {
+	text := "*" + a.Title + "*"
+	if icon, ok := slackIcons[a.Level]; ok {
+		text = icon + " " + text
+	}
+	attachment := slackAttachment{
+		Color:  slackColors[a.Level],
+		Text:   truncate(a.Text, 3000),
+		Footer: w.source,
+		Ts:     at.Unix(),
+	}
+	for _, f := range a.Fields {
+		attachment.Fields = append(attachment.Fields, slackField{Title: f.Name, Value: truncate(f.Value, 1024), Short: len(f.Value) <= 40})
+	}
+	return slackMessage{Text: text, Attachments: []slackAttachment{attachment}}
+}
+
+// discordMessage is the body of a Discord webhook
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+	// AllowedMentions stops text from the alert, such as an error message,
+	// pinging @everyone
+	AllowedMentions discordMentions `json:"allowed_mentions"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+type discordMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// discordColors mark the level of Discord embeds
+var discordColors = map[Level]int{LevelInfo: 0x439fe0, LevelWarning: 0xdaa038, LevelCritical: 0xa30200}
+
+func (w *Webhook) discordMessage(a Alert, at time.Time) discordMessage {
+	embed := discordEmbed{
+		Title:       truncate(a.Title, 256),
+		Description: truncate(a.Text, 4096),
+		Color:       discordColors[a.Level],
+		Timestamp:   at.UTC().Format(time.RFC3339),
+	}
+	if w.source != "" {
+		embed.Footer = &discordFooter{Text: truncate(w.source, 2048)}
+	}
+	for i, f := range a.Fields {
+		if i == 25 {
+			break
+		}
+		value := f.Value
+		if value == "" {
+			value = "-"
+		}
+		embed.Fields = append(embed.Fields, discordField{Name: truncate(f.Name, 256), Value: truncate(value, 1024), Inline: len(f.Value) <= 40})
+	}
+	return discordMessage{Embeds: []discordEmbed{embed}, AllowedMentions: discordMentions{Parse: []string{}}}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,169 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chat records the messages posted to a test webhook
+type chat struct {
+	mu       sync.Mutex
+	messages []map[string]any
+	status   int
+}
+
+func newChat(t *testing.T) (*chat, *httptest.Server) {
+	t.Helper()
+	c := &chat{status: http.StatusNoContent}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var message map[string]any
+		assert.NoError(t, json.Unmarshal(body, &message))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.messages = append(c.messages, message)
+		w.WriteHeader(c.status)
+	}))
+	t.Cleanup(server.Close)
+	return c, server
+}
+
+func (c *chat) received() []map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.messages
+}
+
+var workerAlert = Alert{
+	Key:    "worker:email",
+	Level:  LevelCritical,
+	Title:  "Worker failing",
+	Text:   "email failed 3 times in a row",
+	Fields: []Field{{Name: "Last error", Value: "smtp: connection refused"}},
+}
+
+func TestWebhook_Slack(t *testing.T) {
+	chat, server := newChat(t)
+	w, err := NewWebhook(WebhookConfig{URL: server.URL, Format: FormatSlack, Source: "api-1"})
+	require.NoError(t, err)
+
+	w.Notify(context.Background(), workerAlert)
+	require.True(t, w.Flush(time.Second))
+
+	require.Len(t, chat.received(), 1)
+	message := chat.received()[0]
+	assert.Equal(t, ":rotating_light: *Worker failing*", message["text"])
+	attachment := message["attachments"].([]any)[0].(map[string]any)
+	assert.Equal(t, "danger", attachment["color"])
+	assert.Equal(t, "email failed 3 times in a row", attachment["text"])
+	assert.Equal(t, "api-1", attachment["footer"])
+	assert.Equal(t, []any{map[string]any{"title": "Last error", "value": "smtp: connection refused", "short": true}}, attachment["fields"])
+}
+
+func TestWebhook_Discord(t *testing.T) {
+	chat, server := newChat(t)
+	w, err := NewWebhook(WebhookConfig{URL: server.URL, Format: FormatDiscord, Source: "api-1"})
+	require.NoError(t, err)
+
+	w.Notify(context.Background(), workerAlert)
+	require.True(t, w.Flush(time.Second))
+
+	require.Len(t, chat.received(), 1)
+	message := chat.received()[0]
+	embed := message["embeds"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Worker failing", embed["title"])
+	assert.Equal(t, "email failed 3 times in a row", embed["description"])
+	assert.Equal(t, float64(0xa30200), embed["color"])
+	assert.Equal(t, map[string]any{"text": "api-1"}, embed["footer"])
+	assert.Len(t, embed["fields"], 1)
+	assert.Equal(t, map[string]any{"parse": []any{}}, message["allowed_mentions"])
+}
+
+func TestWebhook_Cooldown(t *testing.T) {
+	chat, server := newChat(t)
+	w, err := NewWebhook(WebhookConfig{URL: server.URL, Format: FormatSlack, Cooldown: 15 * time.Minute})
+	require.NoError(t, err)
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	w.Notify(context.Background(), workerAlert)
+	w.Notify(context.Background(), workerAlert)
+	w.Notify(context.Background(), Alert{Title: "Admin user created"})
+	require.True(t, w.Flush(time.Second))
+	assert.Len(t, chat.received(), 2, "repeats within the cooldown are dropped")
+
+	now = now.Add(15 * time.Minute)
+	w.Notify(context.Background(), workerAlert)
+	require.True(t, w.Flush(time.Second))
+	assert.Len(t, chat.received(), 3)
+}
+
+func TestWebhook_PostError(t *testing.T) {
+	chat, server := newChat(t)
+	chat.status = http.StatusBadRequest
+	w, err := NewWebhook(WebhookConfig{URL: server.URL, Format: FormatSlack})
+	require.NoError(t, err)
+
+	err = w.post(context.Background(), workerAlert, time.Now())
+	assert.ErrorContains(t, err, "alert webhook returned 400")
+}
+
+func TestNewWebhook_Format(t *testing.T) {
+	tests := []struct {
+		url    string
+		format Format
+		want   Format
+		err    bool
+	}{
+		{url: "https://hooks.slack.com/services/T0/B0/xyz", want: FormatSlack},
+		{url: "https://discord.com/api/webhooks/1/abc", format: FormatAuto, want: FormatDiscord},
+		{url: "https://ptb.discord.com/api/webhooks/1/abc", want: FormatDiscord},
+		{url: "https://chat.example.com/hooks/abc", format: FormatSlack, want: FormatSlack},
+		{url: "https://chat.example.com/hooks/abc", err: true},
+		{url: "https://discord.com/channels/1", err: true},
+		{url: "https://hooks.slack.com/services/x", format: "teams", err: true},
+		{url: "ftp://hooks.slack.com/services/x", err: true},
+		{url: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			w, err := NewWebhook(WebhookConfig{URL: tt.url, Format: tt.format})
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, w.Format())
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 5))
+	assert.Equal(t, "lon…", truncate("longer", 4))
+	assert.Equal(t, "ñañ…", truncate("ñañañ", 4))
+}
+
+func TestSend_Default(t *testing.T) {
+	chat, server := newChat(t)
+	w, err := NewWebhook(WebhookConfig{URL: server.URL, Format: FormatSlack})
+	require.NoError(t, err)
+
+	Send(context.Background(), workerAlert)
+	SetDefault(w)
+	defer SetDefault(nil)
+	Send(context.Background(), workerAlert)
+	require.True(t, Default().Flush(time.Second))
+	assert.Len(t, chat.received(), 1, "alerts sent before SetDefault are dropped")
+}
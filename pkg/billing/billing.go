@@ -0,0 +1,114 @@
+// Package billing manages customers and subscriptions at a payment
+// provider, Stripe. Users subscribe on the provider's hosted checkout page
+// and manage their subscription on its customer portal; the provider reports
+// subscription changes back through signed webhook events.
+package billing
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Provider creates customers and the hosted pages users subscribe and
+// manage their subscription on. Methods are safe for concurrent use.
+type Provider interface {
+	// CreateCustomer creates a customer and returns its ID
+	CreateCustomer(ctx context.Context, params *CustomerParams) (string, error)
+	// CreateCheckoutSession creates a checkout page subscribing the customer
+	// to a price and returns its URL
+	CreateCheckoutSession(ctx context.Context, params *CheckoutParams) (string, error)
+	// CreatePortalSession creates a customer portal page and returns its URL
+	CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error)
+	// CancelSubscription cancels a subscription at the end of the period
+	// already paid for
+	CancelSubscription(ctx context.Context, subscriptionID string) (*Subscription, error)
+}
+
+// CustomerParams describes a customer to create
+type CustomerParams struct {
+	Email string
+	Name  string
+	// Metadata is stored with the customer, e.g. the user ID
+	Metadata map[string]string
+	// IdempotencyKey makes retries of the same request return the customer
+	// created first rather than creating another
+	IdempotencyKey string
+}
+
+// CheckoutParams describes a checkout page
+type CheckoutParams struct {
+	CustomerID string
+	PriceID    string
+	// SuccessURL and CancelURL are where the user is sent after paying or
+	// going back
+	SuccessURL string
+	CancelURL  string
+	// Metadata is stored with the subscription the checkout creates
+	Metadata map[string]string
+}
+
+// Subscription is a customer's subscription
+type Subscription struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Status            string            `json:"status"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	Metadata          map[string]string `json:"metadata"`
+	Items             struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+			// CurrentPeriodEnd is reported per item by API versions from
+			// 2025-03-31 on
+			CurrentPeriodEnd int64 `json:"current_period_end"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// PriceID returns the price the subscription is for
+func (s *Subscription) PriceID() string {
+	if len(s.Items.Data) == 0 {
+		return ""
+	}
+	return s.Items.Data[0].Price.ID
+}
+
+// PeriodEnd returns when the period paid for ends, or the zero time when
+// the subscription doesn't say
+func (s *Subscription) PeriodEnd() time.Time {
+	end := s.CurrentPeriodEnd
+	if end == 0 && len(s.Items.Data) > 0 {
+		end = s.Items.Data[0].CurrentPeriodEnd
+	}
+	if end == 0 {
+		return time.Time{}
+	}
+	return time.Unix(end, 0).UTC()
+}
+
+// APIError is an error response of the provider's API
+type APIError struct {
+	StatusCode int
+	// Type and Code classify the error, such as "invalid_request_error" and
+	// "resource_missing"
+	Type    string
+	Code    string
+	Message string
+}
+
+// Error describes the failure (implements error)
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("billing: Stripe responded %d: %s (code %s)", e.StatusCode, e.Message, e.Code)
+	}
+	return fmt.Sprintf("billing: Stripe responded %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether retrying the request may succeed: Stripe failed
+// or asked to slow down, rather than rejecting the request
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == 429 || e.StatusCode >= 500
+}
@@ -0,0 +1,163 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultStripeBaseURL is Stripe's REST API
+const DefaultStripeBaseURL = "https://api.stripe.com"
+
+// StripeConfig holds the settings for calling Stripe's API
+type StripeConfig struct {
+	// SecretKey is the API key, sk_live_... or sk_test_...
+	SecretKey string
+	// BaseURL overrides DefaultStripeBaseURL
+	BaseURL string
+	// HTTPClient overrides the client calling the API
+	HTTPClient *http.Client
+}
+
+// Stripe manages customers and subscriptions with Stripe's API
+type Stripe struct {
+	secretKey string
+	baseURL   string
+	client    *http.Client
+}
+
+// NewStripe creates a provider calling Stripe's API
+func NewStripe(cfg StripeConfig) (*Stripe, error) {
+	if cfg.SecretKey == "" {
+		return nil, errors.New("billing: Stripe secret key is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultStripeBaseURL
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Stripe{
+		secretKey: cfg.SecretKey,
+		baseURL:   strings.TrimSuffix(cfg.BaseURL, "/"),
+		client:    client,
+	}, nil
+}
+
+// stripeObject is the part of Stripe's responses the provider reads
+type stripeObject struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// stripeError is the body of Stripe's error responses
+type stripeError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CreateCustomer creates a customer (implements Provider)
+func (s *Stripe) CreateCustomer(ctx context.Context, params *CustomerParams) (string, error) {
+	form := url.Values{"email": {params.Email}, "name": {params.Name}}
+	setMetadata(form, "metadata", params.Metadata)
+	var customer stripeObject
+	if err := s.post(ctx, "/v1/customers", form, params.IdempotencyKey, &customer); err != nil {
+		return "", err
+	}
+	return customer.ID, nil
+}
+
+// CreateCheckoutSession creates a subscription checkout session (implements
+// Provider)
+func (s *Stripe) CreateCheckoutSession(ctx context.Context, params *CheckoutParams) (string, error) {
+	form := url.Values{
+		"mode":                    {"subscription"},
+		"customer":                {params.CustomerID},
+		"line_items[0][price]":    {params.PriceID},
+		"line_items[0][quantity]": {"1"},
+		"success_url":             {params.SuccessURL},
+		"cancel_url":              {params.CancelURL},
+	}
+	setMetadata(form, "subscription_data[metadata]", params.Metadata)
+	var session stripeObject
+	if err := s.post(ctx, "/v1/checkout/sessions", form, "", &session); err != nil {
+		return "", err
+	}
+	return session.URL, nil
+}
+
+// CreatePortalSession creates a billing portal session (implements Provider)
+func (s *Stripe) CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error) {
+	form := url.Values{"customer": {customerID}, "return_url": {returnURL}}
+	var session stripeObject
+	if err := s.post(ctx, "/v1/billing_portal/sessions", form, "", &session); err != nil {
+		return "", err
+	}
+	return session.URL, nil
+}
+
+// CancelSubscription sets the subscription to cancel at the end of its
+// period (implements Provider)
+func (s *Stripe) CancelSubscription(ctx context.Context, subscriptionID string) (*Subscription, error) {
+	form := url.Values{"cancel_at_period_end": {"true"}}
+	var sub Subscription
+	if err := s.post(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), form, "", &sub); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// post sends a form-encoded request to path and decodes the response into
+// out
+func (s *Stripe) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("billing: calling Stripe: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("billing: reading Stripe response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+		var parsed stripeError
+		if json.Unmarshal(body, &parsed) == nil && parsed.Error.Message != "" {
+			apiErr.Type = parsed.Error.Type
+			apiErr.Code = parsed.Error.Code
+			apiErr.Message = parsed.Error.Message
+		}
+		return apiErr
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("billing: decoding Stripe response: %w", err)
+	}
+	return nil
+}
+
+// setMetadata adds metadata to form as Stripe's key[name]=value parameters
+func setMetadata(form url.Values, key string, metadata map[string]string) {
+	for name, value := range metadata {
+		form.Set(key+"["+name+"]", value)
+	}
+}
@@ -0,0 +1,101 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripe_Requests(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, r)
+		switch r.URL.Path {
+		case "/v1/customers":
+			w.Write([]byte(`{"id":"cus_123","object":"customer"}`))
+		case "/v1/checkout/sessions":
+			w.Write([]byte(`{"id":"cs_123","url":"https://checkout.stripe.com/c/pay/cs_123"}`))
+		case "/v1/billing_portal/sessions":
+			w.Write([]byte(`{"id":"bps_123","url":"https://billing.stripe.com/p/session/bps_123"}`))
+		case "/v1/subscriptions/sub_123":
+			w.Write([]byte(`{"id":"sub_123","customer":"cus_123","status":"active","cancel_at_period_end":true,
+				"items":{"data":[{"price":{"id":"price_pro"},"current_period_end":1717243200}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	stripe, err := NewStripe(StripeConfig{SecretKey: "sk_test_123", BaseURL: server.URL})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	customerID, err := stripe.CreateCustomer(ctx, &CustomerParams{
+		Email: "ada@example.com", Name: "Ada", Metadata: map[string]string{"user_id": "42"}, IdempotencyKey: "customer-42",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cus_123", customerID)
+	user, _, ok := requests[0].BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "sk_test_123", user)
+	assert.Equal(t, "customer-42", requests[0].Header.Get("Idempotency-Key"))
+	assert.Equal(t, "ada@example.com", requests[0].PostForm.Get("email"))
+	assert.Equal(t, "42", requests[0].PostForm.Get("metadata[user_id]"))
+
+	checkoutURL, err := stripe.CreateCheckoutSession(ctx, &CheckoutParams{
+		CustomerID: "cus_123", PriceID: "price_pro", SuccessURL: "https://app.example/billing?done=1", CancelURL: "https://app.example/billing",
+		Metadata: map[string]string{"user_id": "42"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://checkout.stripe.com/c/pay/cs_123", checkoutURL)
+	assert.Equal(t, "subscription", requests[1].PostForm.Get("mode"))
+	assert.Equal(t, "price_pro", requests[1].PostForm.Get("line_items[0][price]"))
+	assert.Equal(t, "42", requests[1].PostForm.Get("subscription_data[metadata][user_id]"))
+	assert.Empty(t, requests[1].Header.Get("Idempotency-Key"))
+
+	portalURL, err := stripe.CreatePortalSession(ctx, "cus_123", "https://app.example/billing")
+	require.NoError(t, err)
+	assert.Equal(t, "https://billing.stripe.com/p/session/bps_123", portalURL)
+	assert.Equal(t, "https://app.example/billing", requests[2].PostForm.Get("return_url"))
+
+	sub, err := stripe.CancelSubscription(ctx, "sub_123")
+	require.NoError(t, err)
+	assert.Equal(t, "true", requests[3].PostForm.Get("cancel_at_period_end"))
+	assert.True(t, sub.CancelAtPeriodEnd)
+	assert.Equal(t, "price_pro", sub.PriceID())
+	assert.Equal(t, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), sub.PeriodEnd(), "the period end is read from the item")
+}
+
+func TestStripe_Errors(t *testing.T) {
+	status := http.StatusBadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":{"type":"invalid_request_error","code":"resource_missing","message":"No such customer: 'cus_gone'"}}`))
+	}))
+	defer server.Close()
+
+	stripe, err := NewStripe(StripeConfig{SecretKey: "sk_test_123", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = stripe.CreatePortalSession(context.Background(), "cus_gone", "https://app.example/billing")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "resource_missing", apiErr.Code)
+	assert.False(t, apiErr.Temporary())
+	assert.EqualError(t, err, "billing: Stripe responded 400: No such customer: 'cus_gone' (code resource_missing)")
+
+	status = http.StatusTooManyRequests
+	_, err = stripe.CreatePortalSession(context.Background(), "cus_gone", "https://app.example/billing")
+	require.True(t, errors.As(err, &apiErr))
+	assert.True(t, apiErr.Temporary())
+
+	_, err = NewStripe(StripeConfig{})
+	assert.EqualError(t, err, "billing: Stripe secret key is required")
+}
@@ -0,0 +1,48 @@
+package billing
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_123","type":"customer.subscription.updated"}`)
+	now := time.Unix(1717243200, 0)
+	signature := SignPayload(payload, "whsec_test", now)
+
+	assert.NoError(t, VerifySignature(payload, signature, "whsec_test", DefaultTolerance, now.Add(time.Minute)))
+	assert.NoError(t, VerifySignature(payload, "v1=00ff,"+signature+",v0=abc", "whsec_test", DefaultTolerance, now),
+		"one matching signature is enough while a secret is rolled")
+
+	assert.ErrorIs(t, VerifySignature(payload, signature, "whsec_other", DefaultTolerance, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature([]byte(`{"id":"evt_124"}`), signature, "whsec_test", DefaultTolerance, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(payload, signature, "whsec_test", DefaultTolerance, now.Add(10*time.Minute)), ErrInvalidSignature,
+		"old requests are rejected as replays")
+	assert.ErrorIs(t, VerifySignature(payload, "t="+strconv.FormatInt(now.Unix(), 10), "whsec_test", DefaultTolerance, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(payload, "", "whsec_test", DefaultTolerance, now), ErrInvalidSignature)
+}
+
+func TestParseWebhook(t *testing.T) {
+	payload := []byte(`{"id":"evt_123","type":"customer.subscription.updated","created":1717243200,
+		"data":{"object":{"id":"sub_123","customer":"cus_123","status":"past_due","current_period_end":1719835200,
+		"items":{"data":[{"price":{"id":"price_pro"}}]}}}}`)
+
+	event, err := ParseWebhook(payload, SignPayload(payload, "whsec_test", time.Now()), "whsec_test")
+	require.NoError(t, err)
+	assert.Equal(t, EventSubscriptionUpdated, event.Type)
+	assert.Equal(t, time.Unix(1717243200, 0).UTC(), event.CreatedAt())
+
+	sub, err := event.Subscription()
+	require.NoError(t, err)
+	assert.Equal(t, "cus_123", sub.Customer)
+	assert.Equal(t, "past_due", sub.Status)
+	assert.Equal(t, "price_pro", sub.PriceID())
+	assert.Equal(t, time.Unix(1719835200, 0).UTC(), sub.PeriodEnd())
+
+	_, err = ParseWebhook(payload, SignPayload(payload, "whsec_test", time.Now()), "whsec_other")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
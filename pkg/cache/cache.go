@@ -0,0 +1,22 @@
+// Package cache provides a backend-agnostic key/value cache used to take hot
+// lookups off the database.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Get when the key is not cached
+var ErrMiss = errors.New("cache: miss")
+
+// Cache stores opaque values by key with a per-entry TTL
+type Cache interface {
+	// Get returns the value stored under key, or ErrMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key for ttl; a zero ttl means no expiry
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the given keys. Deleting a missing key is not an error.
+	Delete(ctx context.Context, keys ...string) error
+}
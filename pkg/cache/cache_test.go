@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCache exercises the behaviour every Cache implementation must share
+func testCache(t *testing.T, c Cache) {
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+
+	value, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	require.NoError(t, c.Delete(ctx, "a", "b", "missing"))
+	_, err = c.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrMiss)
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestMemoryCache(t *testing.T) {
+	testCache(t, NewMemoryCache())
+}
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	c := NewMemoryCache()
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	now = now.Add(time.Minute)
+
+	_, err := c.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestRedisCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	c, err := NewRedisCache(context.Background(), RedisConfig{Addr: server.Addr(), KeyPrefix: "app:"})
+	require.NoError(t, err)
+	defer c.Close()
+
+	testCache(t, c)
+
+	require.NoError(t, c.Set(context.Background(), "a", []byte("1"), time.Minute))
+	assert.True(t, server.Exists("app:a"), "keys are prefixed")
+	server.FastForward(time.Minute)
+	_, err = c.Get(context.Background(), "a")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestNewRedisCache_Unreachable(t *testing.T) {
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	_, err := NewRedisCache(context.Background(), RedisConfig{Addr: addr})
+
+	assert.Error(t, err)
+}
+
+func TestInstrumentedCache_Stats(t *testing.T) {
+	c := NewInstrumentedCache(NewMemoryCache())
+	ctx := context.Background()
+
+	assert.Equal(t, Stats{}, c.Stats())
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "b")
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.InDelta(t, 0.75, stats.HitRatio, 1e-9)
+}
+
+func TestBypass(t *testing.T) {
+	assert.False(t, IsBypassed(context.Background()))
+	assert.True(t, IsBypassed(WithBypass(context.Background())))
+}
@@ -0,0 +1,18 @@
+package cache
+
+import "context"
+
+type bypassKey struct{}
+
+// WithBypass marks ctx so cache-aside readers skip cached values and reload
+// from the source of truth. Freshly loaded values are still written back, so
+// a bypassed read also repairs a stale entry.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// IsBypassed reports whether ctx was marked with WithBypass
+func IsBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
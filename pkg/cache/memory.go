@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process Cache for single-instance deployments and
+// tests. Entries are not shared between instances, so use Redis when running
+// more than one replica.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a new in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value stored under key, or ErrMiss when absent or expired
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrMiss
+	}
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+		return nil, ErrMiss
+	}
+	return entry.value, nil
+}
+
+// Set stores a copy of value under key for ttl
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return nil
+}
+
+// Delete removes the given keys
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	return nil
+}
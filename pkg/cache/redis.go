@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig holds connection settings for the Redis cache
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// KeyPrefix namespaces every key so several apps can share one Redis
+	KeyPrefix string
+}
+
+// RedisCache stores entries in Redis
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCache connects to Redis and verifies the connection with a PING
+func NewRedisCache(ctx context.Context, cfg RedisConfig) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("cache: failed to connect to redis at %s: %w", cfg.Addr, err)
+	}
+
+	return &RedisCache{
+		client: client,
+		prefix: cfg.KeyPrefix,
+	}, nil
+}
+
+// Get returns the value stored under key, or ErrMiss
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// Set stores value under key for ttl
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+// Delete removes the given keys
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+// Ping checks that Redis answers
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Close closes the underlying Redis client
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
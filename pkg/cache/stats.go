@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/reqstats"
+)
+
+// Stats is a snapshot of cache effectiveness counters
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Errors uint64 `json:"errors"`
+	// HitRatio is hits / (hits + misses), or 0 before the first lookup
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// InstrumentedCache wraps a Cache and counts hits, misses, and errors. The
+// time spent in each call is added to the request's stats.
+type InstrumentedCache struct {
+	Cache
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// NewInstrumentedCache wraps c with hit/miss counters
+func NewInstrumentedCache(c Cache) *InstrumentedCache {
+	return &InstrumentedCache{Cache: c}
+}
+
+// Get returns the value stored under key and records the outcome
+func (c *InstrumentedCache) Get(ctx context.Context, key string) ([]byte, error) {
+	defer trackCall(ctx, time.Now())
+	value, err := c.Cache.Get(ctx, key)
+	switch {
+	case err == nil:
+		c.hits.Add(1)
+	case errors.Is(err, ErrMiss):
+		c.misses.Add(1)
+	default:
+		c.errors.Add(1)
+	}
+	return value, err
+}
+
+// Set stores value under key, counting backend failures
+func (c *InstrumentedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	defer trackCall(ctx, time.Now())
+	err := c.Cache.Set(ctx, key, value, ttl)
+	if err != nil {
+		c.errors.Add(1)
+	}
+	return err
+}
+
+// Delete removes the given keys, counting backend failures
+func (c *InstrumentedCache) Delete(ctx context.Context, keys ...string) error {
+	defer trackCall(ctx, time.Now())
+	err := c.Cache.Delete(ctx, keys...)
+	if err != nil {
+		c.errors.Add(1)
+	}
+	return err
+}
+
+// trackCall adds a cache call that began at start to the request's stats
+func trackCall(ctx context.Context, start time.Time) {
+	reqstats.FromContext(ctx).Add("cache", time.Since(start))
+}
+
+// Stats returns the current counters
+func (c *InstrumentedCache) Stats() Stats {
+	stats := Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
@@ -0,0 +1,135 @@
+// Package crypto provides envelope encryption for sensitive column values.
+//
+// Every value is encrypted with its own random data key (AES-256-GCM). The
+// data key is in turn encrypted ("wrapped") by a KeyWrapper holding the
+// key-encryption key, which never leaves the wrapper: a local Keyring loaded
+// from config, or a KMS client implementing the same interface.
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ciphertextPrefix marks and versions values produced by Envelope.Encrypt
+const ciphertextPrefix = "enc:v1:"
+
+// dataKeySize is the size of each per-value AES-256 data key
+const dataKeySize = 32
+
+// ErrMalformedCiphertext is returned by Decrypt for values it did not produce
+var ErrMalformedCiphertext = errors.New("malformed ciphertext")
+
+// KeyWrapper encrypts and decrypts data keys with a key-encryption key
+type KeyWrapper interface {
+	// WrapKey encrypts dataKey, returning the ID of the key used
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped by the key with keyID
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Envelope encrypts values with fresh data keys wrapped by a KeyWrapper
+type Envelope struct {
+	wrapper KeyWrapper
+}
+
+// NewEnvelope creates an envelope encrypter backed by wrapper
+func NewEnvelope(wrapper KeyWrapper) *Envelope {
+	return &Envelope{wrapper: wrapper}
+}
+
+// Encrypt seals plaintext and returns it as
+// "enc:v1:<key ID>:<wrapped data key>:<nonce+ciphertext>", base64 encoded
+func (e *Envelope) Encrypt(ctx context.Context, plaintext []byte) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	sealed, err := seal(dataKey, plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	keyID, wrapped, err := e.wrapper.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return ciphertextPrefix + keyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	if !strings.HasPrefix(ciphertext, ciphertextPrefix) {
+		return nil, ErrMalformedCiphertext
+	}
+	parts := strings.Split(strings.TrimPrefix(ciphertext, ciphertextPrefix), ":")
+	if len(parts) != 3 {
+		return nil, ErrMalformedCiphertext
+	}
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+
+	dataKey, err := e.wrapper.UnwrapKey(ctx, parts[0], wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return open(dataKey, sealed)
+}
+
+// seal encrypts plaintext with AES-GCM under key, prefixing a random nonce
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open reverses seal
+func open(key, sealed []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// newGCM creates an AES-GCM cipher for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey returns a key spec with a repeated byte as key material
+func testKey(id string, b byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), dataKeySize)))
+}
+
+func newTestEnvelope(t *testing.T, specs ...string) *Envelope {
+	keyring, err := NewKeyring(specs)
+	require.NoError(t, err)
+	return NewEnvelope(keyring)
+}
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	envelope := newTestEnvelope(t, testKey("k1", 1))
+	ctx := context.Background()
+
+	first, err := envelope.Encrypt(ctx, []byte("+1 555 0100"))
+	require.NoError(t, err)
+	second, err := envelope.Encrypt(ctx, []byte("+1 555 0100"))
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "enc:v1:k1:"))
+	assert.NotContains(t, first, "555")
+	assert.NotEqual(t, first, second, "every value gets its own data key and nonce")
+
+	plaintext, err := envelope.Decrypt(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, "+1 555 0100", string(plaintext))
+}
+
+func TestEnvelope_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	old := newTestEnvelope(t, testKey("k1", 1))
+	ciphertext, err := old.Encrypt(ctx, []byte("secret"))
+	require.NoError(t, err)
+
+	rotated := newTestEnvelope(t, testKey("k2", 2), testKey("k1", 1))
+	plaintext, err := rotated.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	fresh, err := rotated.Encrypt(ctx, []byte("secret"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(fresh, "enc:v1:k2:"), "new values use the primary key")
+
+	_, err = newTestEnvelope(t, testKey("k2", 2)).Decrypt(ctx, ciphertext)
+	assert.ErrorContains(t, err, `unknown encryption key "k1"`)
+}
+
+func TestEnvelope_RejectsTamperedCiphertext(t *testing.T) {
+	envelope := newTestEnvelope(t, testKey("k1", 1))
+	ctx := context.Background()
+
+	ciphertext, err := envelope.Encrypt(ctx, []byte("secret"))
+	require.NoError(t, err)
+
+	tampered := ciphertext[:len(ciphertext)-2] + "AA"
+	if tampered == ciphertext {
+		tampered = ciphertext[:len(ciphertext)-2] + "BB"
+	}
+	_, err = envelope.Decrypt(ctx, tampered)
+	assert.Error(t, err)
+
+	_, err = envelope.Decrypt(ctx, "plain text")
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+}
+
+func TestNewKeyring_Validation(t *testing.T) {
+	_, err := NewKeyring(nil)
+	assert.Error(t, err)
+
+	_, err = NewKeyring([]string{"k1:" + base64.StdEncoding.EncodeToString([]byte("short"))})
+	assert.ErrorContains(t, err, "must be 32 bytes")
+
+	_, err = NewKeyring([]string{"no-separator"})
+	assert.Error(t, err)
+
+	_, err = NewKeyring([]string{testKey("k1", 1), testKey("k1", 2)})
+	assert.ErrorContains(t, err, "duplicate")
+}
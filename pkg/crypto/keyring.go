@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Keyring is a KeyWrapper holding key-encryption keys loaded from config. The
+// first key wraps new data keys; the others are kept to unwrap values written
+// before a key rotation.
+type Keyring struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewKeyring parses keys given as "<id>:<base64 32-byte key>", primary first
+func NewKeyring(specs []string) (*Keyring, error) {
+	if len(specs) == 0 {
+		return nil, errors.New("at least one encryption key is required")
+	}
+
+	keyring := &Keyring{keys: make(map[string][]byte, len(specs))}
+	for _, spec := range specs {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid encryption key %q: expected <id>:<base64 key>", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != dataKeySize {
+			return nil, fmt.Errorf("invalid encryption key %q: must be %d bytes, base64 encoded", id, dataKeySize)
+		}
+		if _, dup := keyring.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate encryption key ID %q", id)
+		}
+
+		keyring.keys[id] = key
+		if keyring.primary == "" {
+			keyring.primary = id
+		}
+	}
+	return keyring, nil
+}
+
+// WrapKey encrypts dataKey with the primary key
+func (k *Keyring) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := seal(k.keys[k.primary], dataKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return k.primary, wrapped, nil
+}
+
+// UnwrapKey decrypts a data key with the key it was wrapped by
+func (k *Keyring) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", keyID)
+	}
+	return open(key, wrapped)
+}
@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/gorm/schema"
+)
+
+// ErrNoEnvelope is returned when an encrypted column is written or read
+// before SetFieldEnvelope has configured the encryption keys
+var ErrNoEnvelope = errors.New("field encryption is not configured")
+
+// fieldEnvelope encrypts columns tagged serializer:encrypted
+var fieldEnvelope atomic.Pointer[Envelope]
+
+// SetFieldEnvelope configures the envelope used by the "encrypted" serializer.
+// GORM serializers are registered globally, so this is process-wide.
+func SetFieldEnvelope(envelope *Envelope) {
+	fieldEnvelope.Store(envelope)
+}
+
+func init() {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+}
+
+// EncryptedSerializer encrypts string fields tagged
+// `gorm:"serializer:encrypted"` before they are written and decrypts them when
+// read. Empty strings are stored as-is so optional columns stay empty.
+type EncryptedSerializer struct{}
+
+// Scan decrypts the column value into the field
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var ciphertext string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		ciphertext = v
+	case []byte:
+		ciphertext = string(v)
+	default:
+		return fmt.Errorf("encrypted column %s: unsupported type %T", field.Name, dbValue)
+	}
+
+	if ciphertext == "" {
+		return field.Set(ctx, dst, "")
+	}
+
+	envelope := fieldEnvelope.Load()
+	if envelope == nil {
+		return ErrNoEnvelope
+	}
+	plaintext, err := envelope.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return fmt.Errorf("encrypted column %s: %w", field.Name, err)
+	}
+	return field.Set(ctx, dst, string(plaintext))
+}
+
+// Value encrypts the field for storage
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted column %s: only string fields are supported", field.Name)
+	}
+	if plaintext == "" {
+		return "", nil
+	}
+
+	envelope := fieldEnvelope.Load()
+	if envelope == nil {
+		return nil, ErrNoEnvelope
+	}
+	return envelope.Encrypt(ctx, []byte(plaintext))
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"gorm.io/gorm"
+)
+
+// ErrBackupUnsupported is returned when the configured database can't be
+// backed up or restored, such as an in-memory SQLite database
+var ErrBackupUnsupported = errors.New("backup not supported for this database")
+
+// runTool runs a client tool such as mysqldump with extra environment
+// variables, wiring its stdin and stdout; swapped out in tests
+var runTool = func(ctx context.Context, env []string, stdin io.Reader, stdout io.Writer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// BackupExtension is the file extension of the dumps Backup writes for the
+// driver: a SQL script for MySQL and Postgres, a database file for SQLite
+func BackupExtension(driver string) string {
+	if driver == DriverSQLite {
+		return ".db"
+	}
+	return ".sql"
+}
+
+// Backup writes a consistent dump of the database to w. MySQL and Postgres
+// are dumped with mysqldump and pg_dump, which must be on the PATH; SQLite
+// is copied with VACUUM INTO.
+func Backup(ctx context.Context, db *gorm.DB, cfg *config.DatabaseConfig, w io.Writer) error {
+	switch cfg.Driver {
+	case DriverMySQL:
+		return runTool(ctx, []string{"MYSQL_PWD=" + cfg.Password}, nil, w, "mysqldump",
+			append(mysqlToolArgs(cfg), "--single-transaction", "--routines", "--no-tablespaces", cfg.DBName)...)
+	case DriverPostgres:
+		return runTool(ctx, postgresToolEnv(cfg), nil, w, "pg_dump",
+			append(postgresToolArgs(cfg), "--clean", "--if-exists", "--no-owner")...)
+	case DriverSQLite:
+		return backupSQLite(ctx, db, cfg, w)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedDriver, cfg.Driver)
+	}
+}
+
+// Restore replaces the database's contents with a dump written by Backup.
+// MySQL and Postgres dumps are replayed with mysql and psql. A SQLite dump
+// replaces the database file, so db must not be used afterwards.
+func Restore(ctx context.Context, db *gorm.DB, cfg *config.DatabaseConfig, r io.Reader) error {
+	switch cfg.Driver {
+	case DriverMySQL:
+		return runTool(ctx, []string{"MYSQL_PWD=" + cfg.Password}, r, io.Discard, "mysql",
+			append(mysqlToolArgs(cfg), cfg.DBName)...)
+	case DriverPostgres:
+		return runTool(ctx, postgresToolEnv(cfg), r, io.Discard, "psql",
+			append(postgresToolArgs(cfg), "--quiet", "-v", "ON_ERROR_STOP=1", "--single-transaction")...)
+	case DriverSQLite:
+		return restoreSQLite(db, cfg, r)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedDriver, cfg.Driver)
+	}
+}
+
+// mysqlToolArgs returns the connection flags for mysqldump and mysql; the
+// password is passed in MYSQL_PWD so it doesn't show up in ps
+func mysqlToolArgs(cfg *config.DatabaseConfig) []string {
+	return []string{"--host=" + cfg.Host, "--port=" + cfg.Port, "--user=" + cfg.User}
+}
+
+// postgresToolArgs returns the connection flags for pg_dump and psql
+func postgresToolArgs(cfg *config.DatabaseConfig) []string {
+	return []string{"--host=" + cfg.Host, "--port=" + cfg.Port, "--username=" + cfg.User, "--dbname=" + cfg.DBName}
+}
+
+// postgresToolEnv passes the password and SSL mode to pg_dump and psql
+func postgresToolEnv(cfg *config.DatabaseConfig) []string {
+	env := []string{"PGPASSWORD=" + cfg.Password}
+	if cfg.SSLMode != "" {
+		env = append(env, "PGSSLMODE="+cfg.SSLMode)
+	}
+	return env
+}
+
+// backupSQLite snapshots the database into a temporary file with VACUUM
+// INTO, which is consistent even while other connections write, and copies
+// it to w
+func backupSQLite(ctx context.Context, db *gorm.DB, cfg *config.DatabaseConfig, w io.Writer) error {
+	if cfg.SQLitePath == ":memory:" {
+		return ErrBackupUnsupported
+	}
+
+	dir, err := os.MkdirTemp("", "sqlite-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, "backup.db")
+	if err := db.WithContext(ctx).Exec("VACUUM INTO ?", snapshot).Error; err != nil {
+		return fmt.Errorf("failed to snapshot sqlite database: %w", err)
+	}
+
+	f, err := os.Open(snapshot)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// sqliteHeader starts every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// restoreSQLite closes db and atomically replaces the database file with
+// the dump
+func restoreSQLite(db *gorm.DB, cfg *config.DatabaseConfig, r io.Reader) error {
+	if cfg.SQLitePath == ":memory:" {
+		return ErrBackupUnsupported
+	}
+
+	// Refuse to replace the database with anything but a database
+	br := bufio.NewReader(r)
+	if header, err := br.Peek(len(sqliteHeader)); err != nil || string(header) != sqliteHeader {
+		return errors.New("restore file is not a SQLite database")
+	}
+	r = br
+
+	tmp, err := os.CreateTemp(filepath.Dir(cfg.SQLitePath), filepath.Base(cfg.SQLitePath)+".restore-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := closeDB(db); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cfg.SQLitePath)
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestore_SQLite(t *testing.T) {
+	cfg := &config.DatabaseConfig{Driver: DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), "app.db")}
+	db, err := Connect(cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, db.Exec("CREATE TABLE notes (body TEXT)").Error)
+	require.NoError(t, db.Exec("INSERT INTO notes VALUES ('kept')").Error)
+
+	var dump bytes.Buffer
+	require.NoError(t, Backup(ctx, db, cfg, &dump))
+
+	require.NoError(t, db.Exec("DELETE FROM notes").Error)
+	assert.Error(t, Restore(ctx, db, cfg, strings.NewReader("not a database")))
+
+	require.NoError(t, Restore(ctx, db, cfg, &dump))
+
+	db, err = Connect(cfg)
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	var body string
+	require.NoError(t, db.Raw("SELECT body FROM notes").Scan(&body).Error)
+	assert.Equal(t, "kept", body)
+
+	memCfg := &config.DatabaseConfig{Driver: DriverSQLite, SQLitePath: ":memory:"}
+	assert.ErrorIs(t, Backup(ctx, db, memCfg, io.Discard), ErrBackupUnsupported)
+}
+
+func TestBackup_ClientTools(t *testing.T) {
+	var calls []string
+	var envs [][]string
+	original := runTool
+	runTool = func(ctx context.Context, env []string, stdin io.Reader, stdout io.Writer, name string, args ...string) error {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		envs = append(envs, env)
+		return nil
+	}
+	t.Cleanup(func() { runTool = original })
+
+	cfg := &config.DatabaseConfig{Host: "db", Port: "3306", User: "app", Password: "s3cret", DBName: "shop"}
+	ctx := context.Background()
+
+	cfg.Driver = DriverMySQL
+	require.NoError(t, Backup(ctx, nil, cfg, io.Discard))
+	require.NoError(t, Restore(ctx, nil, cfg, strings.NewReader("")))
+
+	cfg.Driver, cfg.Port, cfg.SSLMode = DriverPostgres, "5432", "require"
+	require.NoError(t, Backup(ctx, nil, cfg, io.Discard))
+	require.NoError(t, Restore(ctx, nil, cfg, strings.NewReader("")))
+
+	assert.Equal(t, []string{
+		"mysqldump --host=db --port=3306 --user=app --single-transaction --routines --no-tablespaces shop",
+		"mysql --host=db --port=3306 --user=app shop",
+		"pg_dump --host=db --port=5432 --username=app --dbname=shop --clean --if-exists --no-owner",
+		"psql --host=db --port=5432 --username=app --dbname=shop --quiet -v ON_ERROR_STOP=1 --single-transaction",
+	}, calls)
+	for _, call := range calls {
+		assert.NotContains(t, call, "s3cret", "passwords stay out of the process list")
+	}
+	assert.Equal(t, []string{"PGPASSWORD=s3cret", "PGSSLMODE=require"}, envs[2])
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"gorm.io/gorm"
+)
+
+// Supported database drivers
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// ErrUnsupportedDriver is returned by Connect for an unknown cfg.Driver
+var ErrUnsupportedDriver = errors.New("unsupported database driver")
+
+// timeZone returns the zone timestamps are stored in, defaulting to UTC
+func timeZone(cfg *config.DatabaseConfig) string {
+	if cfg.TimeZone == "" {
+		return "UTC"
+	}
+	return cfg.TimeZone
+}
+
+// Connect opens a database connection for the driver selected by cfg.Driver.
+// Errors are wrapped as "failed to connect to <driver> database: ..." so
+// callers see the same shape regardless of driver.
+func Connect(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case DriverMySQL:
+		dialector = mysqlDialector(cfg)
+	case DriverPostgres:
+		dialector = postgresDialector(cfg)
+	case DriverSQLite:
+		dialector = sqliteDialector(cfg)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedDriver, cfg.Driver)
+	}
+
+	loc, err := time.LoadLocation(timeZone(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s database: invalid time zone: %w", cfg.Driver, err)
+	}
+
+	logLevel, err := parseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Driver, err)
+	}
+
+	replicas, err := replicaDialectors(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Driver, err)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:                 newQueryLogger(cfg.SlowQueryThreshold).LogMode(logLevel),
+		PrepareStmt:            cfg.PrepareStmt,
+		SkipDefaultTransaction: cfg.SkipDefaultTransaction,
+		CreateBatchSize:        cfg.CreateBatchSize,
+		// Timestamps are written in the configured zone (UTC by default)
+		// rather than the server's local time
+		NowFunc: func() time.Time {
+			return time.Now().In(loc)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Driver, err)
+	}
+
+	if cfg.Driver == DriverSQLite {
+		// Foreign keys are off by default in SQLite
+		if err := db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+			return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Driver, err)
+		}
+	}
+
+	if len(replicas) > 0 {
+		if err := useReplicas(db, replicas); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Driver, err)
+		}
+		slog.Info("Routing reads to replicas", "driver", cfg.Driver, "replicas", len(replicas))
+	}
+
+	slog.Info("Connected to database", "driver", cfg.Driver)
+	return db, nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnect_SQLiteInMemory(t *testing.T) {
+	db, err := Connect(&config.DatabaseConfig{Driver: DriverSQLite, SQLitePath: ":memory:"})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	assert.NoError(t, MigrateUp(context.Background(), db, DriverSQLite))
+	assert.NoError(t, sqlDB.Ping())
+}
+
+func TestConnect_UnsupportedDriver(t *testing.T) {
+	_, err := Connect(&config.DatabaseConfig{Driver: "oracle"})
+
+	assert.ErrorIs(t, err, ErrUnsupportedDriver)
+}
+
+func TestConnect_TimeZone(t *testing.T) {
+	db := newSQLiteDB(t)
+	assert.Equal(t, time.UTC, db.NowFunc().Location(), "timestamps default to UTC")
+
+	db, err := Connect(&config.DatabaseConfig{Driver: DriverSQLite, SQLitePath: ":memory:", TimeZone: "Asia/Yangon"})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+	assert.Equal(t, "Asia/Yangon", db.NowFunc().Location().String())
+
+	_, err = Connect(&config.DatabaseConfig{Driver: DriverSQLite, SQLitePath: ":memory:", TimeZone: "Nowhere/Special"})
+	assert.ErrorContains(t, err, "invalid time zone")
+}
+
+func TestStatsAndPing(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	latency, err := Ping(context.Background(), db)
+	require.NoError(t, err)
+	assert.Positive(t, latency)
+
+	stats, err := Stats(db)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, stats.OpenConnections, 1)
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	_, err = Ping(context.Background(), db)
+	assert.Error(t, err)
+}
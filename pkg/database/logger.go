@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	applogger "github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/aungmyozaw92/go-api-setup/pkg/reqstats"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryLogger is a GORM logger that stays quiet for normal traffic: it logs
+// failed queries and queries slower than slowThreshold, with bound parameters
+// left as placeholders so user data (emails, password hashes) never reaches
+// the logs. Records go to the request's logger so they carry its fields.
+type queryLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+	// base overrides the logger taken from the context
+	base *slog.Logger
+}
+
+// newQueryLogger creates a query logger; a zero slowThreshold disables slow
+// query logging and leaves only errors
+func newQueryLogger(slowThreshold time.Duration) *queryLogger {
+	return &queryLogger{
+		level:         logger.Warn,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// parseLogLevel parses a DB_LOG_LEVEL value; empty is logger.Warn
+func parseLogLevel(level string) (logger.LogLevel, error) {
+	switch level {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "", "warn":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// logger returns the logger for a query run with ctx
+func (l *queryLogger) logger(ctx context.Context) *slog.Logger {
+	base := l.base
+	if base == nil {
+		base = applogger.FromContext(ctx)
+	}
+	return base.With("component", "gorm")
+}
+
+// LogMode returns a copy of the logger at the given level
+func (l *queryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info logs GORM informational messages
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn logs GORM warnings
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error logs GORM errors
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace logs a finished query when it failed or exceeded the slow threshold,
+// and adds its time to the request's stats
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	reqstats.FromContext(ctx).Add("db", elapsed)
+	if l.level <= logger.Silent {
+		return
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.logger(ctx).ErrorContext(ctx, "query failed", "error", err, "elapsed", elapsed, "rows", rows, "sql", sql)
+	case l.slowThreshold > 0 && elapsed >= l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger(ctx).WarnContext(ctx, "slow query", "elapsed", elapsed, "threshold", l.slowThreshold, "rows", rows, "sql", sql)
+	case l.level >= logger.Info:
+		sql, rows := fc()
+		l.logger(ctx).InfoContext(ctx, "query", "elapsed", elapsed, "rows", rows, "sql", sql)
+	}
+}
+
+// ParamsFilter drops bound parameters so logged SQL keeps its placeholders
+func (l *queryLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// lineWriter collects each log record written to it as a line
+type lineWriter struct {
+	lines *[]string
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	*w.lines = append(*w.lines, string(p))
+	return len(p), nil
+}
+
+// capturingLogger returns a query logger whose output is collected in lines
+func capturingLogger(slowThreshold time.Duration, lines *[]string) *queryLogger {
+	l := newQueryLogger(slowThreshold)
+	l.base = logger.New(lineWriter{lines: lines}, slog.LevelDebug, logger.FormatText)
+	return l
+}
+
+func TestQueryLogger_SlowQueriesAreRedacted(t *testing.T) {
+	db := newSQLiteDB(t)
+	require.NoError(t, MigrateUp(context.Background(), db, DriverSQLite))
+
+	var lines []string
+	db = db.Session(&gorm.Session{Logger: capturingLogger(time.Nanosecond, &lines)})
+
+	var user domain.User
+	db.Where("email = ?", "secret@example.com").First(&user)
+
+	require.NotEmpty(t, lines)
+	assert.Contains(t, lines[0], "slow query")
+	assert.Contains(t, lines[0], "email = ?")
+	assert.NotContains(t, lines[0], "secret@example.com")
+}
+
+func TestQueryLogger_QuietBelowThreshold(t *testing.T) {
+	db := newSQLiteDB(t)
+	require.NoError(t, MigrateUp(context.Background(), db, DriverSQLite))
+
+	var lines []string
+	db = db.Session(&gorm.Session{Logger: capturingLogger(time.Hour, &lines)})
+
+	var user domain.User
+	db.Where("email = ?", "alice@example.com").First(&user)
+	assert.Empty(t, lines, "record not found is not an error worth logging")
+
+	db.Exec("SELECT * FROM missing_table")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "query failed")
+}
+
+func TestQueryLogger_InfoLogsEveryQuery(t *testing.T) {
+	db := newSQLiteDB(t)
+	require.NoError(t, MigrateUp(context.Background(), db, DriverSQLite))
+
+	level, err := parseLogLevel("info")
+	require.NoError(t, err)
+	var lines []string
+	db = db.Session(&gorm.Session{Logger: capturingLogger(time.Hour, &lines).LogMode(level)})
+
+	var user domain.User
+	db.Where("email = ?", "alice@example.com").First(&user)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "msg=query")
+	assert.NotContains(t, lines[0], "alice@example.com")
+
+	_, err = parseLogLevel("verbose")
+	assert.EqualError(t, err, `unknown log level "verbose"`)
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+)
+
+// migrationsFS holds the versioned SQL migrations, one directory per driver.
+// Add a new migration by creating the next NNNNN_description.sql file in
+// every driver directory.
+//
+//go:embed migrations
+var migrationsFS embed.FS
+
+// gooseDialects maps our driver names onto goose dialects
+var gooseDialects = map[string]goose.Dialect{
+	DriverMySQL:    goose.DialectMySQL,
+	DriverPostgres: goose.DialectPostgres,
+	DriverSQLite:   goose.DialectSQLite3,
+}
+
+// newMigrationProvider builds a goose provider for the driver's migrations
+func newMigrationProvider(db *gorm.DB, driver string) (*goose.Provider, error) {
+	dialect, ok := gooseDialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedDriver, driver)
+	}
+
+	migrations, err := fs.Sub(migrationsFS, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return goose.NewProvider(dialect, sqlDB, migrations)
+}
+
+// MigrateUp applies all pending migrations
+func MigrateUp(ctx context.Context, db *gorm.DB, driver string) error {
+	slog.Info("Running database migrations")
+
+	provider, err := newMigrationProvider(db, driver)
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	for _, result := range results {
+		slog.Info("Applied migration", "migration", result.Source.Path, "duration", result.Duration)
+	}
+	slog.Info("Database migrations completed")
+	return nil
+}
+
+// MigrateDown rolls back the most recently applied migration
+func MigrateDown(ctx context.Context, db *gorm.DB, driver string) error {
+	provider, err := newMigrationProvider(db, driver)
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration: %w", err)
+	}
+
+	result, err := provider.Down(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration: %w", err)
+	}
+
+	slog.Info("Rolled back migration", "migration", result.Source.Path, "duration", result.Duration)
+	return nil
+}
+
+// MigrationStatus reports every known migration and whether it is applied
+func MigrationStatus(ctx context.Context, db *gorm.DB, driver string) ([]*goose.MigrationStatus, error) {
+	provider, err := newMigrationProvider(db, driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration status: %w", err)
+	}
+	return statuses, nil
+}
+
+// PendingMigrations counts the known migrations not yet applied to db
+func PendingMigrations(ctx context.Context, db *gorm.DB, driver string) (int, error) {
+	statuses, err := MigrationStatus(ctx, db, driver)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if status.State == goose.StatePending {
+			pending++
+		}
+	}
+	return pending, nil
+}
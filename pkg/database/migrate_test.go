@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := Connect(&config.DatabaseConfig{
+		Driver:     DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "migrate.db"),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestMigrate_UpStatusDown(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	statuses, err := MigrationStatus(ctx, db, DriverSQLite)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	assert.Equal(t, goose.StatePending, statuses[0].State)
+
+	pending, err := PendingMigrations(ctx, db, DriverSQLite)
+	require.NoError(t, err)
+	assert.Equal(t, len(statuses), pending)
+
+	require.NoError(t, MigrateUp(ctx, db, DriverSQLite))
+	assert.True(t, db.Migrator().HasTable("users"))
+	assert.True(t, db.Migrator().HasTable("files"))
+
+	// Running up again is a no-op
+	require.NoError(t, MigrateUp(ctx, db, DriverSQLite))
+
+	statuses, err = MigrationStatus(ctx, db, DriverSQLite)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.Equal(t, goose.StateApplied, status.State, status.Source.Path)
+	}
+	pending, err = PendingMigrations(ctx, db, DriverSQLite)
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+
+	for range statuses {
+		require.NoError(t, MigrateDown(ctx, db, DriverSQLite))
+	}
+	assert.False(t, db.Migrator().HasTable("users"))
+}
+
+func TestMigrate_EveryDriverHasMigrations(t *testing.T) {
+	sqlite, err := migrationsFS.ReadDir("migrations/" + DriverSQLite)
+	require.NoError(t, err)
+
+	for _, driver := range []string{DriverMySQL, DriverPostgres} {
+		entries, err := migrationsFS.ReadDir("migrations/" + driver)
+		require.NoError(t, err)
+		require.Len(t, entries, len(sqlite), driver)
+		for i := range entries {
+			assert.Equal(t, sqlite[i].Name(), entries[i].Name(), driver)
+		}
+	}
+}
+
+func TestMigrate_UnsupportedDriver(t *testing.T) {
+	err := MigrateUp(context.Background(), newSQLiteDB(t), "oracle")
+
+	assert.ErrorIs(t, err, ErrUnsupportedDriver)
+}
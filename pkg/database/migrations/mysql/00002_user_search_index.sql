@@ -0,0 +1,7 @@
+-- +goose Up
+-- Full-text index backing UserRepository.Search. InnoDB skips tokens shorter
+-- than innodb_ft_min_token_size (3 by default).
+ALTER TABLE users ADD FULLTEXT INDEX idx_users_search (name, email);
+
+-- +goose Down
+ALTER TABLE users DROP INDEX idx_users_search;
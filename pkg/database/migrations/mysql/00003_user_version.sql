@@ -0,0 +1,7 @@
+-- +goose Up
+-- Optimistic locking: UserRepository.Update only writes when the version it
+-- read is still current, then increments it.
+ALTER TABLE users ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN version;
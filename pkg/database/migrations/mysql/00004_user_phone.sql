@@ -0,0 +1,7 @@
+-- +goose Up
+-- Phone numbers are PII and stored envelope-encrypted (see pkg/crypto), so
+-- the column holds opaque ciphertext rather than a searchable number.
+ALTER TABLE users ADD COLUMN phone TEXT;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN phone;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Opaque, non-enumerable user IDs exposed in URLs when USER_ID_FORMAT is
+-- uuidv7 or ulid. Rows created before this migration are backfilled at
+-- startup (repository.BackfillPublicIDs).
+ALTER TABLE users ADD COLUMN public_id VARCHAR(36);
+CREATE UNIQUE INDEX idx_users_public_id ON users (public_id);
+
+-- +goose Down
+DROP INDEX idx_users_public_id ON users;
+ALTER TABLE users DROP COLUMN public_id;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Who-did-what record of every mutating API request, written by
+-- middleware.AuditLog
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    actor_id BIGINT UNSIGNED NULL,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    status INT NOT NULL,
+    payload_hash VARCHAR(64) NULL,
+    request_id VARCHAR(128) NULL,
+    client_ip VARCHAR(45) NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_audit_logs_actor_id (actor_id),
+    INDEX idx_audit_logs_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Persistent background job queue consumed by jobs.Pool
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    run_at DATETIME(3) NOT NULL,
+    locked_at DATETIME(3) NULL,
+    finished_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_jobs_type (type),
+    INDEX idx_jobs_status_run_at (status, run_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Outbox of rendered emails, sent by the EmailWorker
+CREATE TABLE IF NOT EXISTS emails (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    recipient VARCHAR(255) NOT NULL,
+    template VARCHAR(100) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    next_attempt_at DATETIME(3) NULL,
+    sent_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_emails_recipient (recipient),
+    INDEX idx_emails_status (status),
+    INDEX idx_emails_next_attempt_at (next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS emails;
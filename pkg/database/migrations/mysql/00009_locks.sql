@@ -0,0 +1,11 @@
+-- +goose Up
+-- Leases taken by workers so only one instance runs each scheduled job
+CREATE TABLE IF NOT EXISTS locks (
+    name VARCHAR(191) NOT NULL,
+    owner VARCHAR(64) NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS locks;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Consumers claim higher-priority jobs first; see jobs.WithPriority
+ALTER TABLE jobs ADD COLUMN priority INT NOT NULL DEFAULT 0;
+CREATE INDEX idx_jobs_status_priority_run_at ON jobs (status, priority, run_at);
+
+-- +goose Down
+DROP INDEX idx_jobs_status_priority_run_at ON jobs;
+ALTER TABLE jobs DROP COLUMN priority;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Per-endpoint delivery policy (0 uses the worker's defaults), and the count
+-- of consecutive failed attempts after which an endpoint is disabled.
+ALTER TABLE webhook_subscriptions ADD COLUMN max_attempts BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE webhook_subscriptions ADD COLUMN retry_delay_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE webhook_subscriptions ADD COLUMN timeout_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE webhook_subscriptions ADD COLUMN consecutive_failures BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE webhook_subscriptions ADD COLUMN disabled_at DATETIME(3) NULL;
+
+-- +goose Down
+ALTER TABLE webhook_subscriptions DROP COLUMN disabled_at;
+ALTER TABLE webhook_subscriptions DROP COLUMN consecutive_failures;
+ALTER TABLE webhook_subscriptions DROP COLUMN timeout_seconds;
+ALTER TABLE webhook_subscriptions DROP COLUMN retry_delay_seconds;
+ALTER TABLE webhook_subscriptions DROP COLUMN max_attempts;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Per-user channel choices for each notification type; types without a row
+-- use their default channels
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_notification_preferences_user_type_channel (user_id, type, channel)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_preferences;
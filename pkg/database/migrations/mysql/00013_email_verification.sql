@@ -0,0 +1,6 @@
+-- +goose Up
+-- Set when the user follows the link in their verification email
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME(3) NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN email_verified_at;
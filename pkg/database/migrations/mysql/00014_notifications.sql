@@ -0,0 +1,15 @@
+-- +goose Up
+-- In-app inbox entries, written by the in_app notification channel
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    read_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_notifications_user_id (user_id, read_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Phone verification and SMS two-factor login. Codes are stored hashed and
+-- replaced whenever a new one is sent for the same purpose.
+ALTER TABLE users ADD COLUMN phone_verified_at DATETIME(3) NULL;
+ALTER TABLE users ADD COLUMN two_factor_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS verification_codes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    purpose VARCHAR(50) NOT NULL,
+    code_hash VARCHAR(255) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    INDEX idx_verification_codes_user_purpose (user_id, purpose)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS verification_codes;
+ALTER TABLE users DROP COLUMN two_factor_enabled;
+ALTER TABLE users DROP COLUMN phone_verified_at;
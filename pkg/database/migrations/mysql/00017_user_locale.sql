@@ -0,0 +1,6 @@
+-- +goose Up
+-- Language of the user's emails, such as "es"; NULL uses the default
+ALTER TABLE users ADD COLUMN locale VARCHAR(35) NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN locale;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Reminders sent to users who haven't verified their email address
+ALTER TABLE users ADD COLUMN verification_reminders INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN verification_reminded_at DATETIME(3) NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN verification_reminded_at;
+ALTER TABLE users DROP COLUMN verification_reminders;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Billing plan of each user, kept in sync with their subscription
+ALTER TABLE users ADD COLUMN plan VARCHAR(50) NOT NULL DEFAULT 'free';
+
+-- Stripe customer and subscription of each user
+CREATE TABLE IF NOT EXISTS billing_accounts (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    subscription_id VARCHAR(255) NULL,
+    plan VARCHAR(50) NULL,
+    status VARCHAR(30) NULL,
+    current_period_end DATETIME(3) NULL,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    synced_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_billing_accounts_user_id (user_id),
+    UNIQUE INDEX idx_billing_accounts_customer_id (customer_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS billing_accounts;
+ALTER TABLE users DROP COLUMN plan;
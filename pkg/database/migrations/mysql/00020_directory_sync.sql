@@ -0,0 +1,9 @@
+-- +goose Up
+-- ID of each user in the external directory (LDAP or SCIM) they are synced
+-- from, NULL for local users
+ALTER TABLE users ADD COLUMN directory_id VARCHAR(255) NULL;
+CREATE UNIQUE INDEX idx_users_directory_id ON users (directory_id);
+
+-- +goose Down
+DROP INDEX idx_users_directory_id ON users;
+ALTER TABLE users DROP COLUMN directory_id;
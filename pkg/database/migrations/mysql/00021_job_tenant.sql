@@ -0,0 +1,7 @@
+-- +goose Up
+-- Tenant the job was enqueued for; jobs.Pool runs it against that tenant's
+-- database. Empty for jobs on the shared database.
+ALTER TABLE jobs ADD COLUMN tenant VARCHAR(48) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN tenant;
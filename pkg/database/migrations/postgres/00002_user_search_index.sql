@@ -0,0 +1,7 @@
+-- +goose Up
+-- Full-text index backing UserRepository.Search; the expression must match
+-- the one used in the query for the planner to pick it up.
+CREATE INDEX IF NOT EXISTS idx_users_search ON users USING GIN (to_tsvector('simple', name || ' ' || email));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_search;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Who-did-what record of every mutating API request, written by
+-- middleware.AuditLog
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    status INTEGER NOT NULL,
+    payload_hash VARCHAR(64),
+    request_id VARCHAR(128),
+    client_ip VARCHAR(45),
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_logs;
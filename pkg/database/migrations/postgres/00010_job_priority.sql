@@ -0,0 +1,8 @@
+-- +goose Up
+-- Consumers claim higher-priority jobs first; see jobs.WithPriority
+ALTER TABLE jobs ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_jobs_status_priority_run_at ON jobs (status, priority, run_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_status_priority_run_at;
+ALTER TABLE jobs DROP COLUMN priority;
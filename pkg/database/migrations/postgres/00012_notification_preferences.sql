@@ -0,0 +1,16 @@
+-- +goose Up
+-- Per-user channel choices for each notification type; types without a row
+-- use their default channels
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_preferences_user_type_channel ON notification_preferences (user_id, type, channel);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_preferences;
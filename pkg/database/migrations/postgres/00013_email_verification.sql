@@ -0,0 +1,6 @@
+-- +goose Up
+-- Set when the user follows the link in their verification email
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Language of the user's emails, such as "es"; NULL uses the default
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35);
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
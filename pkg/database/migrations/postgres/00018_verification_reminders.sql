@@ -0,0 +1,8 @@
+-- +goose Up
+-- Reminders sent to users who haven't verified their email address
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_reminders INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_reminded_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS verification_reminded_at;
+ALTER TABLE users DROP COLUMN IF EXISTS verification_reminders;
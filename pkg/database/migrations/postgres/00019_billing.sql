@@ -0,0 +1,24 @@
+-- +goose Up
+-- Billing plan of each user, kept in sync with their subscription
+ALTER TABLE users ADD COLUMN IF NOT EXISTS plan VARCHAR(50) NOT NULL DEFAULT 'free';
+
+-- Stripe customer and subscription of each user
+CREATE TABLE IF NOT EXISTS billing_accounts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    subscription_id VARCHAR(255),
+    plan VARCHAR(50),
+    status VARCHAR(30),
+    current_period_end TIMESTAMPTZ,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    synced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_billing_accounts_user_id ON billing_accounts (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_billing_accounts_customer_id ON billing_accounts (customer_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS billing_accounts;
+ALTER TABLE users DROP COLUMN IF EXISTS plan;
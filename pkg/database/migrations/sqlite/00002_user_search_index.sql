@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQLite is for development and tests only: UserRepository.Search falls back
+-- to LIKE there, so no index is needed. This file keeps versions aligned.
+SELECT 1;
+
+-- +goose Down
+SELECT 1;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Outbox of rendered emails, sent by the EmailWorker
+CREATE TABLE IF NOT EXISTS emails (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    recipient VARCHAR(255) NOT NULL,
+    template VARCHAR(100) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at DATETIME,
+    sent_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_emails_recipient ON emails (recipient);
+CREATE INDEX IF NOT EXISTS idx_emails_status ON emails (status);
+CREATE INDEX IF NOT EXISTS idx_emails_next_attempt_at ON emails (next_attempt_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS emails;
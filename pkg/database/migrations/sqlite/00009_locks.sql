@@ -0,0 +1,10 @@
+-- +goose Up
+-- Leases taken by workers so only one instance runs each scheduled job
+CREATE TABLE IF NOT EXISTS locks (
+    name VARCHAR(191) PRIMARY KEY,
+    owner VARCHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS locks;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- In-app inbox entries, written by the in_app notification channel
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    read_at DATETIME,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, read_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS notifications;
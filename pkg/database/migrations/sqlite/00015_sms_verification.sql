@@ -0,0 +1,21 @@
+-- +goose Up
+-- Phone verification and SMS two-factor login. Codes are stored hashed and
+-- replaced whenever a new one is sent for the same purpose.
+ALTER TABLE users ADD COLUMN phone_verified_at DATETIME;
+ALTER TABLE users ADD COLUMN two_factor_enabled NUMERIC NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS verification_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL,
+    purpose VARCHAR(50) NOT NULL,
+    code_hash VARCHAR(255) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_verification_codes_user_purpose ON verification_codes (user_id, purpose);
+
+-- +goose Down
+DROP TABLE IF EXISTS verification_codes;
+ALTER TABLE users DROP COLUMN two_factor_enabled;
+ALTER TABLE users DROP COLUMN phone_verified_at;
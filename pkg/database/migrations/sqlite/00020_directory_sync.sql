@@ -0,0 +1,9 @@
+-- +goose Up
+-- ID of each user in the external directory (LDAP or SCIM) they are synced
+-- from, NULL for local users
+ALTER TABLE users ADD COLUMN directory_id VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_directory_id ON users (directory_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_directory_id;
+ALTER TABLE users DROP COLUMN directory_id;
@@ -2,47 +2,24 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"net/url"
 
 	"github.com/aungmyozaw92/go-api-setup/internal/config"
-	"github.com/aungmyozaw92/go-api-setup/internal/domain"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-// NewMySQLConnection creates a new MySQL database connection
-func NewMySQLConnection(cfg *config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+// mysqlDialector builds the MySQL dialector from the connection settings.
+// DATETIME values are zoneless, so loc tells the driver which zone they
+// were written in.
+func mysqlDialector(cfg *config.DatabaseConfig) gorm.Dialector {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=%s",
 		cfg.User,
 		cfg.Password,
 		cfg.Host,
 		cfg.Port,
 		cfg.DBName,
+		url.QueryEscape(timeZone(cfg)),
 	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	log.Println("Connected to MySQL database successfully")
-	return db, nil
+	return mysql.Open(dsn)
 }
-
-// AutoMigrate runs database migrations
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
-	
-	err := db.AutoMigrate(
-		&domain.User{},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	log.Println("Database migrations completed successfully")
-	return nil
-} 
\ No newline at end of file
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// postgresDialector builds the PostgreSQL dialector from the connection settings
+func postgresDialector(cfg *config.DatabaseConfig) gorm.Dialector {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.DBName,
+		cfg.SSLMode,
+		timeZone(cfg),
+	)
+	return postgres.Open(dsn)
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ErrReplicasUnsupported is returned by Connect when read replicas are
+// configured for a driver that cannot have them
+var ErrReplicasUnsupported = errors.New("read replicas are not supported for this driver")
+
+// replicaDialectors builds one dialector per configured replica host. A
+// replica without an explicit port uses the primary's port.
+func replicaDialectors(cfg *config.DatabaseConfig) ([]gorm.Dialector, error) {
+	dialectors := make([]gorm.Dialector, 0, len(cfg.ReplicaHosts))
+	for _, hostport := range cfg.ReplicaHosts {
+		replica := *cfg
+		replica.Host, replica.Port = hostport, cfg.Port
+		if host, port, err := net.SplitHostPort(hostport); err == nil {
+			replica.Host, replica.Port = host, port
+		}
+
+		switch cfg.Driver {
+		case DriverMySQL:
+			dialectors = append(dialectors, mysqlDialector(&replica))
+		case DriverPostgres:
+			dialectors = append(dialectors, postgresDialector(&replica))
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrReplicasUnsupported, cfg.Driver)
+		}
+	}
+	return dialectors, nil
+}
+
+// useReplicas routes queries to the replicas, picked at random per query.
+// Writes, raw Exec calls and transactions stay on the primary; use
+// db.Clauses(dbresolver.Write) to force a read onto the primary when it must
+// see a write that may not have replicated yet.
+func useReplicas(db *gorm.DB, replicas []gorm.Dialector) error {
+	return db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}))
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+func TestReplicaDialectors_HostAndPort(t *testing.T) {
+	cfg := &config.DatabaseConfig{
+		Driver:       DriverMySQL,
+		Host:         "primary",
+		Port:         "3306",
+		User:         "root",
+		DBName:       "app",
+		ReplicaHosts: []string{"replica-1", "replica-2:3307"},
+	}
+
+	dialectors, err := replicaDialectors(cfg)
+
+	require.NoError(t, err)
+	require.Len(t, dialectors, 2)
+	assert.Contains(t, dialectors[0].(*mysql.Dialector).DSN, "tcp(replica-1:3306)")
+	assert.Contains(t, dialectors[1].(*mysql.Dialector).DSN, "tcp(replica-2:3307)")
+}
+
+func TestConnect_SQLiteRejectsReplicas(t *testing.T) {
+	_, err := Connect(&config.DatabaseConfig{
+		Driver:       DriverSQLite,
+		SQLitePath:   ":memory:",
+		ReplicaHosts: []string{"replica"},
+	})
+
+	assert.ErrorIs(t, err, ErrReplicasUnsupported)
+}
+
+func TestUseReplicas_RoutesReadsAwayFromPrimary(t *testing.T) {
+	ctx := context.Background()
+	primary := newSQLiteDB(t)
+	replicaCfg := &config.DatabaseConfig{Driver: DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), "replica.db")}
+	replica, err := Connect(replicaCfg)
+	require.NoError(t, err)
+	replicaDB, _ := replica.DB()
+	defer replicaDB.Close()
+	require.NoError(t, MigrateUp(ctx, primary, DriverSQLite))
+	require.NoError(t, MigrateUp(ctx, replica, DriverSQLite))
+
+	require.NoError(t, useReplicas(primary, []gorm.Dialector{sqliteDialector(replicaCfg)}))
+
+	// The replica never receives the write, so a routed read cannot see it
+	require.NoError(t, primary.Create(&domain.User{Name: "Alice", Email: "alice@example.com", Password: "hashed"}).Error)
+
+	var count int64
+	require.NoError(t, primary.Model(&domain.User{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	require.NoError(t, primary.Clauses(dbresolver.Write).Model(&domain.User{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
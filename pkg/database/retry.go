@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"gorm.io/gorm"
+)
+
+// connect is swapped out in tests
+var connect = Connect
+
+// ConnectWithRetry calls Connect until it succeeds, doubling the delay between
+// attempts from cfg.ConnectRetryInterval up to cfg.ConnectRetryMaxInterval.
+// It gives up once cfg.ConnectMaxWait has elapsed (zero means a single
+// attempt) or ctx is cancelled. Configuration errors are not retried.
+func ConnectWithRetry(ctx context.Context, cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	deadline := time.Now().Add(cfg.ConnectMaxWait)
+	delay := cfg.ConnectRetryInterval
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	for attempt := 1; ; attempt++ {
+		db, err := connect(cfg)
+		if err == nil {
+			return db, nil
+		}
+		if errors.Is(err, ErrUnsupportedDriver) || errors.Is(err, ErrReplicasUnsupported) {
+			return nil, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("giving up after %d attempt(s): %w", attempt, err)
+		}
+		if delay > remaining {
+			delay = remaining
+		}
+
+		slog.Warn("Database not ready, retrying", "attempt", attempt, "error", err, "delay", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("giving up after %d attempt(s): %w", attempt, errors.Join(ctx.Err(), err))
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if cfg.ConnectRetryMaxInterval > 0 && delay > cfg.ConnectRetryMaxInterval {
+			delay = cfg.ConnectRetryMaxInterval
+		}
+	}
+}
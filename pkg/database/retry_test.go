@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// stubConnect replaces connect with fn for the duration of the test
+func stubConnect(t *testing.T, fn func(cfg *config.DatabaseConfig) (*gorm.DB, error)) {
+	original := connect
+	connect = fn
+	t.Cleanup(func() { connect = original })
+}
+
+func TestConnectWithRetry_SucceedsAfterFailures(t *testing.T) {
+	attempts := 0
+	stubConnect(t, func(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return &gorm.DB{}, nil
+	})
+
+	db, err := ConnectWithRetry(context.Background(), &config.DatabaseConfig{
+		ConnectMaxWait:       time.Second,
+		ConnectRetryInterval: time.Millisecond,
+	})
+
+	require.NoError(t, err)
+	assert.NotNil(t, db)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestConnectWithRetry_GivesUpAfterMaxWait(t *testing.T) {
+	stubConnect(t, func(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	start := time.Now()
+	_, err := ConnectWithRetry(context.Background(), &config.DatabaseConfig{
+		ConnectMaxWait:          50 * time.Millisecond,
+		ConnectRetryInterval:    5 * time.Millisecond,
+		ConnectRetryMaxInterval: 10 * time.Millisecond,
+	})
+
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestConnectWithRetry_DoesNotRetryConfigErrors(t *testing.T) {
+	_, err := ConnectWithRetry(context.Background(), &config.DatabaseConfig{
+		Driver:         "oracle",
+		ConnectMaxWait: time.Minute,
+	})
+
+	assert.ErrorIs(t, err, ErrUnsupportedDriver)
+}
+
+func TestConnectWithRetry_StopsOnCancel(t *testing.T) {
+	stubConnect(t, func(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+		return nil, errors.New("connection refused")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ConnectWithRetry(ctx, &config.DatabaseConfig{
+		ConnectMaxWait:       time.Minute,
+		ConnectRetryInterval: time.Minute,
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
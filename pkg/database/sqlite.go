@@ -0,0 +1,19 @@
+package database
+
+import (
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteDialector builds the SQLite dialector. Use ":memory:" as the path for
+// a throwaway in-memory database. The driver is pure Go, so no CGO toolchain
+// is needed.
+func sqliteDialector(cfg *config.DatabaseConfig) gorm.Dialector {
+	dsn := cfg.SQLitePath
+	if dsn == ":memory:" {
+		// A shared-cache database keeps every pooled connection on the same data
+		dsn = "file::memory:?cache=shared"
+	}
+	return sqlite.Open(dsn)
+}
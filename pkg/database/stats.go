@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PoolStats is a JSON-friendly snapshot of the connection pool's sql.DBStats
+type PoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationMS     float64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+}
+
+// Stats returns the primary connection pool's statistics
+func Stats(db *gorm.DB) (PoolStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return PoolStats{}, err
+	}
+
+	stats := sqlDB.Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMS:     float64(stats.WaitDuration) / float64(time.Millisecond),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}, nil
+}
+
+// PublishStats exposes the pool statistics through expvar under name
+func PublishStats(name string, db *gorm.DB) {
+	expvar.Publish(name, expvar.Func(func() any {
+		stats, err := Stats(db)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		return stats
+	}))
+}
+
+// Ping checks that the primary database answers, returning the round-trip latency
+func Ping(ctx context.Context, db *gorm.DB) (time.Duration, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	err = sqlDB.PingContext(ctx)
+	return time.Since(start), err
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"gorm.io/gorm"
+)
+
+// TenantModeDatabase gives every tenant its own database
+const TenantModeDatabase = "database"
+
+// Errors returned by TenantPool.DB
+var (
+	// ErrInvalidTenant is returned for tenant IDs that cannot name a database
+	ErrInvalidTenant = errors.New("invalid tenant ID")
+	// ErrUnknownTenant is returned for valid tenant IDs that aren't in the
+	// configured tenant list
+	ErrUnknownTenant = errors.New("unknown tenant")
+)
+
+// tenantIDPattern keeps tenant IDs safe to embed in database and file names
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,47}$`)
+
+// TenantPool lazily opens one database per tenant and keeps the connections
+// for reuse. Only the configured tenants can be opened, and at most maxOpen
+// of them at once. It is safe for concurrent use.
+type TenantPool struct {
+	base    config.DatabaseConfig
+	migrate bool
+	tenants map[string]bool
+	maxOpen int
+
+	mu    sync.Mutex
+	conns map[string]*tenantConn
+}
+
+// tenantConn is a tenant's connection, opened at most once
+type tenantConn struct {
+	ready chan struct{}
+	db    *gorm.DB
+	err   error
+	// lastUsed is when the connection was last handed out, guarded by the
+	// pool's mutex
+	lastUsed time.Time
+}
+
+// NewTenantPool creates a pool for the tenants in cfg.Tenants, whose
+// databases share cfg apart from their name. New tenant databases are
+// migrated on first use when cfg.AutoMigrate is set. Once cfg.TenantMaxOpen
+// databases are open, the least recently used one is closed to make room
+// (no limit when zero).
+func NewTenantPool(cfg *config.DatabaseConfig) *TenantPool {
+	base := *cfg
+	// Replicas are configured for the shared database only
+	base.ReplicaHosts = nil
+
+	tenants := make(map[string]bool, len(cfg.Tenants))
+	for _, tenantID := range cfg.Tenants {
+		tenants[tenantID] = true
+	}
+	return &TenantPool{
+		base:    base,
+		migrate: cfg.AutoMigrate,
+		tenants: tenants,
+		maxOpen: cfg.TenantMaxOpen,
+		conns:   make(map[string]*tenantConn),
+	}
+}
+
+// Tenants returns the configured tenant IDs, sorted
+func (p *TenantPool) Tenants() []string {
+	tenants := make([]string, 0, len(p.tenants))
+	for tenantID := range p.tenants {
+		tenants = append(tenants, tenantID)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// DB returns the tenant's database, connecting (and migrating) it on first
+// use. A failed connection is not cached, so the next request retries it.
+// Tenants outside the configured list return ErrUnknownTenant without
+// touching any database.
+func (p *TenantPool) DB(ctx context.Context, tenantID string) (*gorm.DB, error) {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTenant, tenantID)
+	}
+	if !p.tenants[tenantID] {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, tenantID)
+	}
+
+	p.mu.Lock()
+	conn, ok := p.conns[tenantID]
+	if !ok {
+		p.evictLocked()
+		conn = &tenantConn{ready: make(chan struct{})}
+		p.conns[tenantID] = conn
+	}
+	conn.lastUsed = time.Now()
+	p.mu.Unlock()
+
+	if !ok {
+		conn.db, conn.err = p.open(ctx, tenantID)
+		if conn.err != nil {
+			p.mu.Lock()
+			delete(p.conns, tenantID)
+			p.mu.Unlock()
+		}
+		close(conn.ready)
+	}
+
+	select {
+	case <-conn.ready:
+		return conn.db, conn.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// evictLocked closes the least recently used open database when the pool is
+// full, so a new one can be opened. Connections still opening are never
+// evicted. The caller holds p.mu.
+func (p *TenantPool) evictLocked() {
+	if p.maxOpen <= 0 || len(p.conns) < p.maxOpen {
+		return
+	}
+
+	var oldestID string
+	var oldest *tenantConn
+	for tenantID, conn := range p.conns {
+		select {
+		case <-conn.ready:
+		default:
+			continue
+		}
+		if oldest == nil || conn.lastUsed.Before(oldest.lastUsed) {
+			oldestID, oldest = tenantID, conn
+		}
+	}
+	if oldest == nil {
+		return
+	}
+
+	delete(p.conns, oldestID)
+	// Queries already running on it finish before it closes
+	go func() {
+		if err := closeDB(oldest.db); err != nil {
+			slog.Warn("Failed to close tenant database", "tenant", oldestID, "error", err)
+		}
+	}()
+	slog.Info("Closed least recently used tenant database", "tenant", oldestID)
+}
+
+// open connects to the tenant's database and applies pending migrations
+func (p *TenantPool) open(ctx context.Context, tenantID string) (*gorm.DB, error) {
+	cfg := p.tenantConfig(tenantID)
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+	}
+
+	if p.migrate {
+		if err := MigrateUp(ctx, db, cfg.Driver); err != nil {
+			closeDB(db)
+			return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+
+	slog.Info("Opened tenant database", "tenant", tenantID)
+	return db, nil
+}
+
+// tenantConfig derives a tenant's connection settings: the database is
+// TenantDBPrefix+tenantID, or a file of that name beside SQLitePath
+func (p *TenantPool) tenantConfig(tenantID string) *config.DatabaseConfig {
+	cfg := p.base
+	name := cfg.TenantDBPrefix + tenantID
+	cfg.DBName = name
+	if cfg.Driver == DriverSQLite {
+		cfg.SQLitePath = filepath.Join(filepath.Dir(cfg.SQLitePath), name+".db")
+	}
+	return &cfg
+}
+
+// Close closes every open tenant database
+func (p *TenantPool) Close() error {
+	p.mu.Lock()
+	conns := p.conns
+	p.conns = make(map[string]*tenantConn)
+	p.mu.Unlock()
+
+	var errs []string
+	for tenantID, conn := range conns {
+		<-conn.ready
+		if conn.db == nil {
+			continue
+		}
+		if err := closeDB(conn.db); err != nil {
+			errs = append(errs, fmt.Sprintf("tenant %s: %v", tenantID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// closeDB closes the connection pool behind db
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// tenantContextKey is the context key under which the request's tenant is stored
+type tenantContextKey struct{}
+
+// tenant is the tenant a request was routed to
+type tenant struct {
+	id string
+	db *gorm.DB
+}
+
+// WithTenant returns a context routed to the tenant's database
+func WithTenant(ctx context.Context, tenantID string, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant{id: tenantID, db: db})
+}
+
+// WithoutTenant returns a context routed to the shared database, for data
+// such as the job queue that lives outside the tenant databases
+func WithoutTenant(ctx context.Context) context.Context {
+	if TenantDB(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantContextKey{}, tenant{})
+}
+
+// TenantID returns the tenant the context is routed to, or "" outside
+// multi-tenant mode
+func TenantID(ctx context.Context) string {
+	t, _ := ctx.Value(tenantContextKey{}).(tenant)
+	return t.id
+}
+
+// TenantDB returns the tenant database the context is routed to, or nil
+// when queries should use the shared database
+func TenantDB(ctx context.Context) *gorm.DB {
+	t, _ := ctx.Value(tenantContextKey{}).(tenant)
+	return t.db
+}
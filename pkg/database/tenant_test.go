@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTenantPool_LazyMigratedDatabases(t *testing.T) {
+	dir := t.TempDir()
+	pool := NewTenantPool(&config.DatabaseConfig{
+		Driver:         DriverSQLite,
+		SQLitePath:     filepath.Join(dir, "shared.db"),
+		AutoMigrate:    true,
+		TenantDBPrefix: "tenant_",
+		Tenants:        []string{"globex", "acme"},
+	})
+	t.Cleanup(func() { pool.Close() })
+	ctx := context.Background()
+	assert.Equal(t, []string{"acme", "globex"}, pool.Tenants())
+
+	acme, err := pool.DB(ctx, "acme")
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "tenant_acme.db"))
+	assert.True(t, acme.Migrator().HasTable("users"), "tenant databases are migrated on first use")
+
+	again, err := pool.DB(ctx, "acme")
+	require.NoError(t, err)
+	assert.Same(t, acme, again, "connections are reused")
+
+	globex, err := pool.DB(ctx, "globex")
+	require.NoError(t, err)
+	require.NoError(t, acme.Exec("INSERT INTO users (name, email, password) VALUES ('A', 'a@example.com', 'x')").Error)
+
+	var count int64
+	require.NoError(t, globex.Table("users").Count(&count).Error)
+	assert.Zero(t, count, "tenants do not share rows")
+}
+
+func TestTenantPool_InvalidTenant(t *testing.T) {
+	pool := NewTenantPool(&config.DatabaseConfig{Driver: DriverSQLite})
+
+	for _, id := range []string{"", "Acme", "../etc", "a b", "-acme"} {
+		_, err := pool.DB(context.Background(), id)
+		assert.ErrorIs(t, err, ErrInvalidTenant, id)
+	}
+}
+
+func TestTenantPool_UnknownTenant(t *testing.T) {
+	dir := t.TempDir()
+	pool := NewTenantPool(&config.DatabaseConfig{
+		Driver:         DriverSQLite,
+		SQLitePath:     filepath.Join(dir, "shared.db"),
+		TenantDBPrefix: "tenant_",
+		Tenants:        []string{"acme"},
+	})
+	t.Cleanup(func() { pool.Close() })
+
+	_, err := pool.DB(context.Background(), "globex")
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+	assert.NoFileExists(t, filepath.Join(dir, "tenant_globex.db"), "unknown tenants never reach the database")
+}
+
+func TestTenantPool_EvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	pool := NewTenantPool(&config.DatabaseConfig{
+		Driver:         DriverSQLite,
+		SQLitePath:     filepath.Join(dir, "shared.db"),
+		TenantDBPrefix: "tenant_",
+		Tenants:        []string{"acme", "globex", "initech"},
+		TenantMaxOpen:  2,
+	})
+	t.Cleanup(func() { pool.Close() })
+	ctx := context.Background()
+
+	acme, err := pool.DB(ctx, "acme")
+	require.NoError(t, err)
+	_, err = pool.DB(ctx, "globex")
+	require.NoError(t, err)
+	again, err := pool.DB(ctx, "acme")
+	require.NoError(t, err)
+	assert.Same(t, acme, again)
+
+	// globex is the least recently used, so it makes room for initech
+	_, err = pool.DB(ctx, "initech")
+	require.NoError(t, err)
+	pool.mu.Lock()
+	assert.Len(t, pool.conns, 2)
+	assert.Contains(t, pool.conns, "acme")
+	assert.NotContains(t, pool.conns, "globex")
+	pool.mu.Unlock()
+
+	// It is opened again on its next use
+	_, err = pool.DB(ctx, "globex")
+	require.NoError(t, err)
+}
+
+func TestTenantPool_FailedConnectionIsRetried(t *testing.T) {
+	attempts := 0
+	stubConnect(t, func(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+		attempts++
+		assert.Equal(t, "tenant_acme", cfg.DBName)
+		assert.Empty(t, cfg.ReplicaHosts, "replicas belong to the shared database")
+		return nil, errors.New("connection refused")
+	})
+	pool := NewTenantPool(&config.DatabaseConfig{
+		Driver:         DriverMySQL,
+		TenantDBPrefix: "tenant_",
+		ReplicaHosts:   []string{"replica"},
+		Tenants:        []string{"acme"},
+	})
+
+	_, err := pool.DB(context.Background(), "acme")
+	assert.Error(t, err)
+	_, err = pool.DB(context.Background(), "acme")
+	assert.Error(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestTenantContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, TenantID(ctx))
+	assert.Nil(t, TenantDB(ctx))
+
+	db := &gorm.DB{}
+	ctx = WithTenant(ctx, "acme", db)
+	assert.Equal(t, "acme", TenantID(ctx))
+	assert.Same(t, db, TenantDB(ctx))
+}
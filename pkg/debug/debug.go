@@ -0,0 +1,24 @@
+// Package debug serves the Go runtime's profiling (net/http/pprof) and
+// exported variable (expvar) endpoints, so CPU and heap profiles can be
+// captured from running deployments.
+package debug
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+)
+
+// Handler serves pprof under /debug/pprof/ and expvar at /debug/vars. The
+// pprof index only links its profiles at that path, so mount the handler
+// elsewhere with http.StripPrefix.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
@@ -0,0 +1,31 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	handler := Handler()
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{"/debug/pprof/", "heap"},
+		{"/debug/pprof/goroutine?debug=1", "goroutine profile"},
+		{"/debug/vars", `"memstats"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.contains)
+		})
+	}
+}
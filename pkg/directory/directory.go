@@ -0,0 +1,38 @@
+// Package directory reads the users of an external identity provider's
+// directory, over LDAP or SCIM, so they can be synced to local accounts
+package directory
+
+import (
+	"context"
+	"strings"
+)
+
+// User is a user as listed by a directory
+type User struct {
+	// ID identifies the user in the directory and doesn't change when they
+	// are renamed, such as an LDAP entryUUID or a SCIM id
+	ID    string
+	Email string
+	Name  string
+	// Active is false for users the directory lists but has disabled
+	Active bool
+	// Groups are the names of the groups the user is a member of
+	Groups []string
+}
+
+// InGroup reports whether the user is a member of group, ignoring case
+func (u User) InGroup(group string) bool {
+	for _, g := range u.Groups {
+		if strings.EqualFold(g, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Source lists the users of a directory
+type Source interface {
+	// Users returns every user in the directory. Users without an email
+	// address are left out, since they can't be matched to local accounts.
+	Users(ctx context.Context) ([]User, error)
+}
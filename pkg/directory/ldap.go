@@ -0,0 +1,181 @@
+package directory
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAP attribute and filter defaults, used for zero LDAPConfig fields
+const (
+	DefaultLDAPFilter         = "(objectClass=person)"
+	DefaultLDAPIDAttribute    = "entryUUID"
+	DefaultLDAPEmailAttribute = "mail"
+	DefaultLDAPNameAttribute  = "cn"
+	DefaultLDAPGroupAttribute = "memberOf"
+)
+
+// adAccountDisabled is the userAccountControl flag Active Directory sets on
+// disabled accounts
+const adAccountDisabled = 0x2
+
+// LDAPConfig holds settings for an LDAP source
+type LDAPConfig struct {
+	// URL is the server's address, such as ldaps://ldap.example.com:636
+	URL string
+	// BindDN and BindPassword are the credentials the search runs as
+	BindDN       string
+	BindPassword string
+	// BaseDN is where users are searched for, including its subtree
+	BaseDN string
+	// Filter selects the users to sync; empty uses DefaultLDAPFilter
+	Filter string
+	// IDAttribute holds the user's immutable ID, such as entryUUID or,
+	// for Active Directory, objectGUID. Binary values are hex encoded.
+	IDAttribute    string
+	EmailAttribute string
+	NameAttribute  string
+	// GroupAttribute lists the DNs of the user's groups; the groups are
+	// named by the first value of their DN, such as "admins" for
+	// cn=admins,ou=groups,dc=example,dc=com
+	GroupAttribute string
+	// StartTLS upgrades an ldap:// connection to TLS before binding
+	StartTLS bool
+	// PageSize is how many users are requested at once; zero uses
+	// DefaultPageSize
+	PageSize int
+	// Timeout limits connecting and each request; zero uses 30s
+	Timeout time.Duration
+}
+
+// LDAP lists users from an LDAP directory, such as OpenLDAP or Active
+// Directory
+type LDAP struct {
+	cfg LDAPConfig
+}
+
+// NewLDAP creates a source searching the directory at cfg.URL
+func NewLDAP(cfg LDAPConfig) (*LDAP, error) {
+	if cfg.URL == "" || cfg.BaseDN == "" {
+		return nil, errors.New("directory: LDAP URL and base DN are required")
+	}
+	if cfg.Filter == "" {
+		cfg.Filter = DefaultLDAPFilter
+	}
+	if cfg.IDAttribute == "" {
+		cfg.IDAttribute = DefaultLDAPIDAttribute
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = DefaultLDAPEmailAttribute
+	}
+	if cfg.NameAttribute == "" {
+		cfg.NameAttribute = DefaultLDAPNameAttribute
+	}
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = DefaultLDAPGroupAttribute
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = DefaultPageSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &LDAP{cfg: cfg}, nil
+}
+
+// Users binds and searches the directory for users (implements Source). A
+// connection is opened for each call, since syncs are hours apart.
+func (l *LDAP) Users(ctx context.Context) ([]User, error) {
+	conn, err := ldap.DialURL(l.cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: l.cfg.Timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("directory: connecting to LDAP server: %w", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(l.cfg.Timeout)
+	// Closing the connection makes a search in progress fail
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if l.cfg.StartTLS {
+		u, err := url.Parse(l.cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("directory: parsing LDAP URL: %w", err)
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}); err != nil {
+			return nil, fmt.Errorf("directory: starting TLS: %w", err)
+		}
+	}
+	if l.cfg.BindDN != "" {
+		if err := conn.Bind(l.cfg.BindDN, l.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("directory: binding to LDAP server: %w", err)
+		}
+	}
+
+	request := ldap.NewSearchRequest(
+		l.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		l.cfg.Filter,
+		[]string{l.cfg.IDAttribute, l.cfg.EmailAttribute, l.cfg.NameAttribute, l.cfg.GroupAttribute, "userAccountControl"},
+		nil,
+	)
+	result, err := conn.SearchWithPaging(request, uint32(l.cfg.PageSize))
+	if err != nil {
+		return nil, fmt.Errorf("directory: searching LDAP users: %w", err)
+	}
+
+	users := make([]User, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		if user, ok := l.user(entry); ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// user converts a search result entry to a User, reporting false when it
+// has no ID or email address
+func (l *LDAP) user(entry *ldap.Entry) (User, bool) {
+	id := entry.GetRawAttributeValue(l.cfg.IDAttribute)
+	email := entry.GetAttributeValue(l.cfg.EmailAttribute)
+	if len(id) == 0 || email == "" {
+		return User{}, false
+	}
+
+	user := User{
+		ID:     string(id),
+		Email:  email,
+		Name:   entry.GetAttributeValue(l.cfg.NameAttribute),
+		Active: true,
+	}
+	if !utf8.Valid(id) {
+		user.ID = hex.EncodeToString(id)
+	}
+	if user.Name == "" {
+		user.Name = email
+	}
+	if control, err := strconv.ParseInt(entry.GetAttributeValue("userAccountControl"), 10, 64); err == nil {
+		user.Active = control&adAccountDisabled == 0
+	}
+	for _, dn := range entry.GetAttributeValues(l.cfg.GroupAttribute) {
+		user.Groups = append(user.Groups, groupName(dn))
+	}
+	return user, true
+}
+
+// groupName returns the value of the first attribute of a group's DN, or
+// the DN itself when it isn't one
+func groupName(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return dn
+	}
+	return parsed.RDNs[0].Attributes[0].Value
+}
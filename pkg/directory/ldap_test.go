@@ -0,0 +1,50 @@
+package directory
+
+import (
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDAP_User(t *testing.T) {
+	source, err := NewLDAP(LDAPConfig{URL: "ldap://localhost", BaseDN: "dc=example,dc=com"})
+	require.NoError(t, err)
+
+	user, ok := source.user(ldap.NewEntry("uid=ada,ou=people,dc=example,dc=com", map[string][]string{
+		"entryUUID": {"6f1c0b3e-1d2a-4c5b-9e8f-7a6b5c4d3e2f"},
+		"mail":      {"ada@example.com"},
+		"cn":        {"Ada Lovelace"},
+		"memberOf":  {"cn=Admins,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"},
+	}))
+	require.True(t, ok)
+	assert.Equal(t, User{
+		ID:     "6f1c0b3e-1d2a-4c5b-9e8f-7a6b5c4d3e2f",
+		Email:  "ada@example.com",
+		Name:   "Ada Lovelace",
+		Active: true,
+		Groups: []string{"Admins", "staff"},
+	}, user)
+
+	_, ok = source.user(ldap.NewEntry("uid=svc,dc=example,dc=com", map[string][]string{"entryUUID": {"1"}}))
+	assert.False(t, ok, "entries without an email address are left out")
+}
+
+func TestLDAP_ActiveDirectoryUser(t *testing.T) {
+	source, err := NewLDAP(LDAPConfig{URL: "ldaps://dc.example.com", BaseDN: "dc=example,dc=com", IDAttribute: "objectGUID"})
+	require.NoError(t, err)
+
+	user, ok := source.user(ldap.NewEntry("cn=Grace,dc=example,dc=com", map[string][]string{
+		"objectGUID":         {string([]byte{0xff, 0x01, 0xfe})},
+		"mail":               {"grace@example.com"},
+		"userAccountControl": {"514"},
+	}))
+	require.True(t, ok)
+	assert.Equal(t, "ff01fe", user.ID, "binary IDs are hex encoded")
+	assert.Equal(t, "grace@example.com", user.Name)
+	assert.False(t, user.Active, "disabled accounts are inactive")
+
+	_, err = NewLDAP(LDAPConfig{URL: "ldap://localhost"})
+	assert.Error(t, err)
+}
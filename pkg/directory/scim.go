@@ -0,0 +1,183 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPageSize is how many users are requested at once when none is set
+const DefaultPageSize = 200
+
+// SCIMConfig holds settings for a SCIM source
+type SCIMConfig struct {
+	// BaseURL is the SCIM 2.0 service's base URL; users are read from
+	// BaseURL + "/Users"
+	BaseURL string
+	// Token is the bearer token the service is called with
+	Token string
+	// Filter is an optional SCIM filter for the users to sync, such as
+	// `userType eq "Employee"`
+	Filter string
+	// PageSize is how many users are requested at once; zero uses
+	// DefaultPageSize
+	PageSize int
+	// HTTPClient calls the service; nil uses a client with a 30s timeout
+	HTTPClient *http.Client
+}
+
+// SCIM lists users from a SCIM 2.0 service (RFC 7644), such as Okta, Entra
+// ID or OneLogin
+type SCIM struct {
+	cfg    SCIMConfig
+	client *http.Client
+}
+
+// NewSCIM creates a source reading from cfg.BaseURL
+func NewSCIM(cfg SCIMConfig) (*SCIM, error) {
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("directory: SCIM base URL must be an http or https URL")
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = DefaultPageSize
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &SCIM{cfg: cfg, client: client}, nil
+}
+
+// scimListResponse is a page of a SCIM list (RFC 7644 section 3.4.2)
+type scimListResponse struct {
+	TotalResults int        `json:"totalResults"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	StartIndex   int        `json:"startIndex"`
+	Resources    []scimUser `json:"Resources"`
+}
+
+// scimUser holds the attributes of a SCIM user resource that are synced
+type scimUser struct {
+	ID          string `json:"id"`
+	UserName    string `json:"userName"`
+	DisplayName string `json:"displayName"`
+	Name        struct {
+		Formatted  string `json:"formatted"`
+		GivenName  string `json:"givenName"`
+		FamilyName string `json:"familyName"`
+	} `json:"name"`
+	Active *bool `json:"active"`
+	Emails []struct {
+		Value   string `json:"value"`
+		Primary bool   `json:"primary"`
+	} `json:"emails"`
+	Groups []struct {
+		Value   string `json:"value"`
+		Display string `json:"display"`
+	} `json:"groups"`
+}
+
+// Users pages through the service's users (implements Source)
+func (s *SCIM) Users(ctx context.Context) ([]User, error) {
+	var users []User
+	for start := 1; ; {
+		page, err := s.page(ctx, start)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range page.Resources {
+			if user, ok := resource.user(); ok {
+				users = append(users, user)
+			}
+		}
+		start += len(page.Resources)
+		if len(page.Resources) == 0 || start > page.TotalResults {
+			return users, nil
+		}
+	}
+}
+
+// page requests the page of users starting at the 1-based index start
+func (s *SCIM) page(ctx context.Context, start int) (*scimListResponse, error) {
+	query := url.Values{}
+	query.Set("startIndex", strconv.Itoa(start))
+	query.Set("count", strconv.Itoa(s.cfg.PageSize))
+	if s.cfg.Filter != "" {
+		query.Set("filter", s.cfg.Filter)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.BaseURL+"/Users?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/scim+json")
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("directory: listing SCIM users: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("directory: SCIM service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	var page scimListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("directory: decoding SCIM users: %w", err)
+	}
+	return &page, nil
+}
+
+// user converts the resource to a User, reporting false when it has no
+// email address. The primary email is used, falling back to the first one
+// and then to a userName that is an email address.
+func (r scimUser) user() (User, bool) {
+	email := ""
+	for _, e := range r.Emails {
+		if e.Primary {
+			email = e.Value
+			break
+		}
+	}
+	if email == "" && len(r.Emails) > 0 {
+		email = r.Emails[0].Value
+	}
+	if email == "" && strings.Contains(r.UserName, "@") {
+		email = r.UserName
+	}
+	if r.ID == "" || email == "" {
+		return User{}, false
+	}
+
+	name := r.DisplayName
+	if name == "" {
+		name = r.Name.Formatted
+	}
+	if name == "" {
+		name = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+	}
+	if name == "" {
+		name = r.UserName
+	}
+
+	user := User{ID: r.ID, Email: email, Name: name, Active: r.Active == nil || *r.Active}
+	for _, g := range r.Groups {
+		if g.Display != "" {
+			user.Groups = append(user.Groups, g.Display)
+		} else if g.Value != "" {
+			user.Groups = append(user.Groups, g.Value)
+		}
+	}
+	return user, true
+}
@@ -0,0 +1,62 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCIM_PagesThroughUsers(t *testing.T) {
+	resources := []map[string]any{
+		{"id": "1", "userName": "ada", "displayName": "Ada Lovelace", "emails": []map[string]any{
+			{"value": "ada@home.example", "primary": false}, {"value": "ada@example.com", "primary": true},
+		}, "groups": []map[string]any{{"value": "g1", "display": "Admins"}}},
+		{"id": "2", "userName": "grace@example.com", "name": map[string]any{"givenName": "Grace", "familyName": "Hopper"}, "active": false},
+		{"id": "3", "userName": "service-account"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/scim/v2/Users", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, `userType eq "Employee"`, r.URL.Query().Get("filter"))
+		start, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+		end := min(start-1+count, len(resources))
+		json.NewEncoder(w).Encode(map[string]any{
+			"totalResults": len(resources),
+			"startIndex":   start,
+			"Resources":    resources[start-1 : end],
+		})
+	}))
+	defer server.Close()
+
+	source, err := NewSCIM(SCIMConfig{BaseURL: server.URL + "/scim/v2/", Token: "token", Filter: `userType eq "Employee"`, PageSize: 2})
+	require.NoError(t, err)
+	users, err := source.Users(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, users, 2, "users without an email address are left out")
+	assert.Equal(t, User{ID: "1", Email: "ada@example.com", Name: "Ada Lovelace", Active: true, Groups: []string{"Admins"}}, users[0])
+	assert.Equal(t, User{ID: "2", Email: "grace@example.com", Name: "Grace Hopper", Active: false}, users[1])
+	assert.True(t, users[0].InGroup("admins"))
+}
+
+func TestSCIM_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	source, err := NewSCIM(SCIMConfig{BaseURL: server.URL})
+	require.NoError(t, err)
+	_, err = source.Users(context.Background())
+	assert.ErrorContains(t, err, "SCIM service returned 401: invalid token")
+
+	_, err = NewSCIM(SCIMConfig{BaseURL: "idp.example.com/scim"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package email
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// WelcomeData is the input of the welcome email sent after registration.
+// VerifyURL is an optional email verification link, valid for
+// VerifyExpiresIn.
+type WelcomeData struct {
+	Name            string
+	LoginURL        string
+	VerifyURL       string
+	VerifyExpiresIn time.Duration
+}
+
+// Template returns the template name (implements Data)
+func (WelcomeData) Template() string { return "welcome" }
+
+// Validate checks the fields the template needs (implements Data)
+func (d WelcomeData) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return errors.New("name is required")
+	}
+	if d.VerifyURL != "" {
+		if d.VerifyExpiresIn <= 0 {
+			return errors.New("expiry must be positive")
+		}
+		if err := validateURL("verification URL", d.VerifyURL); err != nil {
+			return err
+		}
+	}
+	return validateURL("login URL", d.LoginURL)
+}
+
+// PasswordResetData is the input of the password reset email
+type PasswordResetData struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn time.Duration
+}
+
+// Template returns the template name (implements Data)
+func (PasswordResetData) Template() string { return "password_reset" }
+
+// Validate checks the fields the template needs (implements Data)
+func (d PasswordResetData) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return errors.New("name is required")
+	}
+	if d.ExpiresIn <= 0 {
+		return errors.New("expiry must be positive")
+	}
+	return validateURL("reset URL", d.ResetURL)
+}
+
+// VerificationData is the input of the email address verification email.
+// Code is an optional one-time code shown next to the link.
+type VerificationData struct {
+	Name      string
+	VerifyURL string
+	Code      string
+	ExpiresIn time.Duration
+}
+
+// Template returns the template name (implements Data)
+func (VerificationData) Template() string { return "verification" }
+
+// Validate checks the fields the template needs (implements Data)
+func (d VerificationData) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return errors.New("name is required")
+	}
+	if d.ExpiresIn <= 0 {
+		return errors.New("expiry must be positive")
+	}
+	return validateURL("verification URL", d.VerifyURL)
+}
+
+// NotificationData is the input of the generic email sent for a user
+// notification. ActionURL is an optional link to the notification's subject.
+type NotificationData struct {
+	Name      string
+	Subject   string
+	Message   string
+	ActionURL string
+}
+
+// Template returns the template name (implements Data)
+func (NotificationData) Template() string { return "notification" }
+
+// Validate checks the fields the template needs (implements Data)
+func (d NotificationData) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return errors.New("name is required")
+	}
+	if strings.TrimSpace(d.Subject) == "" {
+		return errors.New("subject is required")
+	}
+	if strings.TrimSpace(d.Message) == "" {
+		return errors.New("message is required")
+	}
+	if d.ActionURL == "" {
+		return nil
+	}
+	return validateURL("action URL", d.ActionURL)
+}
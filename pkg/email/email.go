@@ -0,0 +1,242 @@
+// Package email renders transactional emails from embedded templates. Every
+// email has an HTML body and a plain-text alternative, each wrapped in a
+// shared layout, and a subject defined alongside its body.
+//
+// A template named "welcome" lives in templates/welcome.html and
+// templates/welcome.txt; both define a "subject" and a "content" block, which
+// layout.html and layout.txt place in the page. Templates see the sender's
+// Brand as .App and the email's Data as .Data.
+//
+// Templates hold no text of their own: {{.T "welcome.intro"}} translates a
+// message of locales/en.yaml into the recipient's language, and
+// {{.Expiry .Data.ExpiresIn}} spells out a link lifetime.
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Messages holds the English texts of the templates, in locales/en.yaml.
+// A bundle passed to WithBundle must load them before any translations:
+//
+//	bundle.LoadFS(email.Messages, "locales")
+//
+//go:embed locales
+var Messages embed.FS
+
+// Brand describes the application sending the emails, for layouts and links
+type Brand struct {
+	Name         string
+	URL          string
+	SupportEmail string
+}
+
+// Message is a rendered email
+type Message struct {
+	Subject string
+	HTML    string
+	Text    string
+	// Language is the language the email is written in
+	Language string
+}
+
+// Data is the input of one email template. Validate reports missing or
+// malformed fields before anything is rendered.
+type Data interface {
+	Template() string
+	Validate() error
+}
+
+// view is what the templates are executed with
+type view struct {
+	App  Brand
+	Data Data
+	// Lang is the language the email is written in
+	Lang      string
+	localizer *i18n.Localizer
+}
+
+// T translates the message id. Its text sees .App and .Data, and pairs adds
+// more values to it as key, value, key, value.
+func (v view) T(id string, pairs ...interface{}) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("message %s has an odd number of arguments", id)
+	}
+	data := map[string]interface{}{"App": v.App, "Data": v.Data}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("message %s has a non-string argument name", id)
+		}
+		data[key] = pairs[i+1]
+	}
+	return v.localizer.T(id, data), nil
+}
+
+// Expiry describes a link lifetime in words, e.g. "1 hour" or "30 minutes"
+func (v view) Expiry(d time.Duration) string {
+	return formatExpiry(v.localizer, d)
+}
+
+// RendererOption configures a Renderer
+type RendererOption func(*Renderer)
+
+// WithBundle translates emails with bundle, which must hold Messages. By
+// default emails are in English only.
+func WithBundle(bundle *i18n.Bundle) RendererOption {
+	return func(r *Renderer) {
+		r.bundle = bundle
+	}
+}
+
+// Renderer renders emails from the embedded templates
+type Renderer struct {
+	brand  Brand
+	bundle *i18n.Bundle
+	html   map[string]*htmltemplate.Template
+	text   map[string]*texttemplate.Template
+}
+
+// NewRenderer parses every embedded template. It fails when a template is
+// malformed or lacks its HTML or text version.
+func NewRenderer(brand Brand, opts ...RendererOption) (*Renderer, error) {
+	if brand.Name == "" {
+		return nil, errors.New("email brand name is required")
+	}
+
+	r := &Renderer{
+		brand: brand,
+		html:  make(map[string]*htmltemplate.Template),
+		text:  make(map[string]*texttemplate.Template),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.bundle == nil {
+		r.bundle = i18n.NewBundle()
+		if err := r.bundle.LoadFS(Messages, "locales"); err != nil {
+			return nil, fmt.Errorf("failed to load email messages: %w", err)
+		}
+	}
+
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name, ext, _ := strings.Cut(entry.Name(), ".")
+		if name == "layout" {
+			continue
+		}
+
+		switch ext {
+		case "html":
+			tmpl, err := htmltemplate.New(name).ParseFS(templateFS, "templates/layout.html", "templates/"+entry.Name())
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse email template %s: %w", entry.Name(), err)
+			}
+			r.html[name] = tmpl
+		case "txt":
+			tmpl, err := texttemplate.New(name).ParseFS(templateFS, "templates/layout.txt", "templates/"+entry.Name())
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse email template %s: %w", entry.Name(), err)
+			}
+			r.text[name] = tmpl
+		}
+	}
+
+	for name := range r.html {
+		if _, ok := r.text[name]; !ok {
+			return nil, fmt.Errorf("email template %s has no plain-text version", name)
+		}
+	}
+	for name := range r.text {
+		if _, ok := r.html[name]; !ok {
+			return nil, fmt.Errorf("email template %s has no HTML version", name)
+		}
+	}
+	return r, nil
+}
+
+// Render validates data and renders the email of its template in the
+// supported language best fitting the preferences, such as the recipient's
+// locale; English when none fits
+func (r *Renderer) Render(data Data, preferences ...string) (*Message, error) {
+	name := data.Template()
+	htmlTmpl, ok := r.html[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	if err := data.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s email data: %w", name, err)
+	}
+
+	localizer := r.bundle.Localizer(preferences...)
+	v := view{App: r.brand, Data: data, Lang: localizer.Language(), localizer: localizer}
+	var subject, html, text bytes.Buffer
+	if err := r.text[name].ExecuteTemplate(&subject, "subject", v); err != nil {
+		return nil, fmt.Errorf("failed to render %s email subject: %w", name, err)
+	}
+	if err := htmlTmpl.ExecuteTemplate(&html, "layout", v); err != nil {
+		return nil, fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+	if err := r.text[name].ExecuteTemplate(&text, "layout", v); err != nil {
+		return nil, fmt.Errorf("failed to render %s email text: %w", name, err)
+	}
+
+	return &Message{
+		Subject:  strings.TrimSpace(subject.String()),
+		HTML:     html.String(),
+		Text:     text.String(),
+		Language: v.Lang,
+	}, nil
+}
+
+// Templates returns the names of the available templates, sorted
+func (r *Renderer) Templates() []string {
+	names := make([]string, 0, len(r.html))
+	for name := range r.html {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatExpiry describes a link lifetime in words in the localizer's
+// language, e.g. "1 hour" or "30 minutes"
+func formatExpiry(l *i18n.Localizer, d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return l.Plural("duration.days", int(d/(24*time.Hour)), nil)
+	case d >= time.Hour && d%time.Hour == 0:
+		return l.Plural("duration.hours", int(d/time.Hour), nil)
+	default:
+		return l.Plural("duration.minutes", int(d.Round(time.Minute)/time.Minute), nil)
+	}
+}
+
+// validateURL checks that a link in an email is an absolute http(s) URL
+func validateURL(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute http(s) URL", field)
+	}
+	return nil
+}
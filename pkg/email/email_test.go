@@ -0,0 +1,158 @@
+package email
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	r, err := NewRenderer(Brand{Name: "Acme", URL: "https://acme.example", SupportEmail: "help@acme.example"})
+	require.NoError(t, err)
+	return r
+}
+
+func TestRenderer_Templates(t *testing.T) {
+	assert.Equal(t, []string{"notification", "password_reset", "verification", "welcome"}, newTestRenderer(t).Templates())
+
+	_, err := NewRenderer(Brand{})
+	assert.EqualError(t, err, "email brand name is required")
+}
+
+func TestRenderer_Render(t *testing.T) {
+	r := newTestRenderer(t)
+
+	tests := []struct {
+		name    string
+		data    Data
+		subject string
+		link    string
+		text    []string
+	}{
+		{
+			name:    "welcome",
+			data:    WelcomeData{Name: "Alice", LoginURL: "https://acme.example/login"},
+			subject: "Welcome to Acme",
+			link:    "https://acme.example/login",
+			text:    []string{"Hi Alice,", "Sign in: https://acme.example/login"},
+		},
+		{
+			name:    "welcome with verification",
+			data:    WelcomeData{Name: "Alice", LoginURL: "https://acme.example/login", VerifyURL: "https://acme.example/verify?token=abc", VerifyExpiresIn: 48 * time.Hour},
+			subject: "Welcome to Acme",
+			link:    "https://acme.example/verify?token=abc",
+			text:    []string{"expires in 2 days", "Verify email: https://acme.example/verify?token=abc", "Sign in: https://acme.example/login"},
+		},
+		{
+			name:    "password reset",
+			data:    PasswordResetData{Name: "Alice", ResetURL: "https://acme.example/reset?token=abc", ExpiresIn: time.Hour},
+			subject: "Reset your Acme password",
+			link:    "https://acme.example/reset?token=abc",
+			text:    []string{"expires in 1 hour", "Reset password: https://acme.example/reset?token=abc"},
+		},
+		{
+			name:    "verification",
+			data:    VerificationData{Name: "Alice", VerifyURL: "https://acme.example/verify?token=abc", Code: "482913", ExpiresIn: 30 * time.Minute},
+			subject: "Verify your email address",
+			link:    "https://acme.example/verify?token=abc",
+			text:    []string{"expires in 30 minutes", "Or enter this code: 482913"},
+		},
+		{
+			name:    "notification",
+			data:    NotificationData{Name: "Alice", Subject: "New sign-in to your account", Message: "We noticed a new sign-in from Firefox on Linux.", ActionURL: "https://acme.example/security"},
+			subject: "New sign-in to your account",
+			link:    "https://acme.example/security",
+			text:    []string{"new sign-in from Firefox on Linux.", "View details: https://acme.example/security"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := r.Render(tt.data)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.subject, msg.Subject)
+			assert.Contains(t, msg.HTML, "<title>"+tt.subject+"</title>")
+			assert.Contains(t, msg.HTML, `href="`+tt.link+`"`)
+			assert.Contains(t, msg.HTML, "help@acme.example", "wrapped in the layout")
+			for _, want := range tt.text {
+				assert.Contains(t, msg.Text, want)
+			}
+			assert.Contains(t, msg.Text, "https://acme.example\n")
+			assert.NotContains(t, msg.Text, "<")
+		})
+	}
+}
+
+func TestRenderer_EscapesHTML(t *testing.T) {
+	msg, err := newTestRenderer(t).Render(WelcomeData{Name: `<script>alert("x")</script>`, LoginURL: "https://acme.example/login"})
+	require.NoError(t, err)
+
+	assert.NotContains(t, msg.HTML, "<script>")
+	assert.Contains(t, msg.HTML, "&lt;script&gt;")
+	assert.Contains(t, msg.Text, `<script>alert("x")</script>`, "plain text is not escaped")
+}
+
+func TestRenderer_ValidatesData(t *testing.T) {
+	r := newTestRenderer(t)
+
+	tests := []struct {
+		name string
+		data Data
+		err  string
+	}{
+		{"missing name", WelcomeData{LoginURL: "https://acme.example/login"}, "invalid welcome email data: name is required"},
+		{"relative URL", WelcomeData{Name: "Alice", LoginURL: "/login"}, "invalid welcome email data: login URL must be an absolute http(s) URL"},
+		{"script URL", PasswordResetData{Name: "Alice", ResetURL: "javascript:alert(1)", ExpiresIn: time.Hour}, "invalid password_reset email data: reset URL must be an absolute http(s) URL"},
+		{"no expiry", VerificationData{Name: "Alice", VerifyURL: "https://acme.example/verify"}, "invalid verification email data: expiry must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Render(tt.data)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRenderer_RenderTranslated(t *testing.T) {
+	bundle := i18n.NewBundle()
+	require.NoError(t, bundle.LoadFS(Messages, "locales"))
+	require.NoError(t, bundle.LoadFS(fstest.MapFS{"es.yaml": {Data: []byte(`
+welcome.subject: "Bienvenido a {{.App.Name}}"
+email.greeting: "Hola {{.Data.Name}}:"
+welcome.verify: "Confirma tu dirección de correo. El enlace caduca en {{.Expiry}}."
+duration.days:
+  one: "1 día"
+  other: "{{.Count}} días"
+`)}}, "."))
+	r, err := NewRenderer(Brand{Name: "Acme", URL: "https://acme.example"}, WithBundle(bundle))
+	require.NoError(t, err)
+	data := WelcomeData{Name: "Alice", LoginURL: "https://acme.example/login", VerifyURL: "https://acme.example/verify", VerifyExpiresIn: 48 * time.Hour}
+
+	msg, err := r.Render(data, "", "es-MX,es;q=0.9")
+	require.NoError(t, err)
+	assert.Equal(t, "es", msg.Language)
+	assert.Equal(t, "Bienvenido a Acme", msg.Subject)
+	assert.Contains(t, msg.HTML, `<html lang="es">`)
+	assert.Contains(t, msg.Text, "Hola Alice:")
+	assert.Contains(t, msg.Text, "El enlace caduca en 2 días.")
+	assert.Contains(t, msg.Text, "Sign in: https://acme.example/login", "untranslated texts fall back to English")
+
+	msg, err = r.Render(data, "fr")
+	require.NoError(t, err)
+	assert.Equal(t, "en", msg.Language)
+	assert.Equal(t, "Welcome to Acme", msg.Subject)
+}
+
+func TestFormatExpiry(t *testing.T) {
+	l := newTestRenderer(t).bundle.Localizer()
+	assert.Equal(t, "1 hour", formatExpiry(l, time.Hour))
+	assert.Equal(t, "2 days", formatExpiry(l, 48*time.Hour))
+	assert.Equal(t, "90 minutes", formatExpiry(l, 90*time.Minute))
+	assert.Equal(t, "1 minute", formatExpiry(l, time.Minute))
+}
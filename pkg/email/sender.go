@@ -0,0 +1,39 @@
+package email
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Envelope is a rendered email addressed to a recipient
+type Envelope struct {
+	To string
+	Message
+}
+
+// Sender delivers emails. The email worker calls Send from several goroutines
+// at once.
+type Sender interface {
+	Send(ctx context.Context, envelope *Envelope) error
+}
+
+// LogSender logs emails instead of sending them, for development and for
+// deployments without a mail provider
+type LogSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender creates a sender logging to logger
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+// Send logs the recipient, subject and plain-text body (implements Sender)
+func (s *LogSender) Send(ctx context.Context, envelope *Envelope) error {
+	s.logger.InfoContext(ctx, "Email not sent, no mail provider configured",
+		"to", envelope.To,
+		"subject", envelope.Subject,
+		"text", envelope.Text,
+	)
+	return nil
+}
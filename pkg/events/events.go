@@ -0,0 +1,129 @@
+// Package events publishes domain events, such as user.created, to a
+// message broker so other services can react to them. Publishers are
+// interchangeable: Kafka, NATS and RabbitMQ in production, LogPublisher in
+// development and Recorder in tests.
+//
+// Events are CloudEvents 1.0 (https://cloudevents.io), so consumers can
+// use the standard SDKs and tooling. Each broker receives the event in the
+// structured JSON format as the message body:
+//
+//	{"specversion": "1.0", "id": "0190...", "source": "/go-api-setup",
+//	 "type": "user.created", "subject": "42", "time": "2024-05-01T12:00:00Z",
+//	 "datacontenttype": "application/json", "data": {...}}
+//
+// Events can be published more than once, e.g. when a broker accepted an
+// event but the acknowledgement was lost, so consumers should drop the IDs
+// they have already seen.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/ids"
+)
+
+// SpecVersion is the CloudEvents version events conform to
+const SpecVersion = "1.0"
+
+// ContentType is the media type of an event in the CloudEvents structured
+// JSON format, for the transports that label their messages
+const ContentType = "application/cloudevents+json"
+
+// Event is a domain event in the CloudEvents format
+type Event struct {
+	SpecVersion string `json:"specversion"`
+	// ID identifies the event within its source, so consumers can drop
+	// duplicates
+	ID string `json:"id"`
+	// Source is a URI reference to the service the event happened in
+	Source string `json:"source"`
+	// Type names what happened, such as "user.created"
+	Type string `json:"type"`
+	// Subject is the key of the entity the event is about, such as a user
+	// ID; brokers that partition messages use it to keep the events of one
+	// entity in order
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// New creates an event of type eventType that happened now in source, about
+// subject, with data encoded as its JSON payload
+func New(source, eventType, subject string, data interface{}) (*Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("events: failed to encode %s data: %w", eventType, err)
+	}
+	return &Event{
+		SpecVersion:     SpecVersion,
+		ID:              ids.NewUUIDv7(),
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            payload,
+	}, nil
+}
+
+// Publisher publishes events to a broker. Publish returns once the broker
+// has accepted the event and is safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+	// Close flushes pending events and releases the broker connection
+	Close() error
+}
+
+// LogPublisher logs events instead of publishing them, for development
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+// NewLogPublisher creates a publisher logging to logger
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+// Publish logs the event (implements Publisher)
+func (p *LogPublisher) Publish(ctx context.Context, event *Event) error {
+	p.logger.InfoContext(ctx, "Event not published, no broker configured", "id", event.ID, "source", event.Source, "type", event.Type, "subject", event.Subject)
+	return nil
+}
+
+// Close does nothing (implements Publisher)
+func (p *LogPublisher) Close() error { return nil }
+
+// Recorder keeps the events published to it, for tests
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+	// Err, when set, is returned by Publish without recording the event
+	Err error
+}
+
+// Publish records event (implements Publisher)
+func (r *Recorder) Publish(ctx context.Context, event *Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// Close does nothing (implements Publisher)
+func (r *Recorder) Close() error { return nil }
+
+// Events returns the events published so far, oldest first
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
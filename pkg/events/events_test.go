@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userCreated is the event the tests publish
+var userCreated = &Event{
+	SpecVersion:     SpecVersion,
+	ID:              "0190a4b2-7c1e-7000-8000-000000000001",
+	Source:          "/go-api-setup",
+	Type:            "user.created",
+	Subject:         "42",
+	Time:            time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	DataContentType: "application/json",
+	Data:            json.RawMessage(`{"id":42,"email":"ada@example.com"}`),
+}
+
+func TestEvent_JSON(t *testing.T) {
+	body, err := json.Marshal(userCreated)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"id": "0190a4b2-7c1e-7000-8000-000000000001",
+		"source": "/go-api-setup",
+		"type": "user.created",
+		"subject": "42",
+		"time": "2024-05-01T12:00:00Z",
+		"datacontenttype": "application/json",
+		"data": {"id": 42, "email": "ada@example.com"}
+	}`, string(body))
+}
+
+func TestNew(t *testing.T) {
+	event, err := New("/go-api-setup", "user.deleted", "", map[string]int{"id": 7})
+	require.NoError(t, err)
+	assert.Equal(t, SpecVersion, event.SpecVersion)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "/go-api-setup", event.Source)
+	assert.Equal(t, "user.deleted", event.Type)
+	assert.WithinDuration(t, time.Now(), event.Time, time.Second)
+	assert.JSONEq(t, `{"id": 7}`, string(event.Data))
+
+	body, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "subject", "an event without subject omits it")
+
+	other, err := New("/go-api-setup", "user.deleted", "", nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, event.ID, other.ID)
+
+	_, err = New("/go-api-setup", "user.deleted", "", func() {})
+	assert.Error(t, err)
+}
+
+func TestRecorder(t *testing.T) {
+	r := &Recorder{}
+	require.NoError(t, r.Publish(context.Background(), userCreated))
+	assert.Equal(t, []Event{*userCreated}, r.Events())
+
+	r.Err = errors.New("broker down")
+	assert.Error(t, r.Publish(context.Background(), userCreated))
+	assert.Len(t, r.Events(), 1)
+}
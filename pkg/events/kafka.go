@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaConfig holds settings for the Kafka publisher
+type KafkaConfig struct {
+	// Brokers are the host:port addresses of the cluster's bootstrap brokers
+	Brokers []string
+	// Topic receives every event
+	Topic string
+}
+
+// kafkaWriter is the part of *kafka.Writer the publisher uses
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaPublisher publishes events to one Kafka topic, keyed by their
+// subject so the events of an entity land on one partition, in order
+type KafkaPublisher struct {
+	writer kafkaWriter
+}
+
+// NewKafka creates a publisher writing to cfg.Topic. Brokers are dialed
+// when the first event is published.
+func NewKafka(cfg KafkaConfig) (*KafkaPublisher, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("events: kafka needs at least one broker")
+	}
+	if cfg.Topic == "" {
+		return nil, errors.New("events: kafka topic is required")
+	}
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// Publish is called once per event; don't hold it for a batch
+		BatchTimeout: 10 * time.Millisecond,
+	}}, nil
+}
+
+// Publish writes the event and waits for the in-sync replicas to
+// acknowledge it (implements Publisher)
+func (p *KafkaPublisher) Publish(ctx context.Context, event *Event) error {
+	msg, err := kafkaMessage(event)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, msg)
+}
+
+// Close flushes pending writes and closes the connections (implements
+// Publisher)
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// kafkaMessage encodes event as a Kafka message in the CloudEvents
+// structured mode, with its type and ID also in headers so consumers can
+// filter without decoding the body
+func kafkaMessage(event *Event) (kafka.Message, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	return kafka.Message{
+		Key:   []byte(event.Subject),
+		Value: body,
+		Headers: []kafka.Header{
+			{Key: "event-type", Value: []byte(event.Type)},
+			{Key: "event-id", Value: []byte(event.ID)},
+			{Key: "content-type", Value: []byte(ContentType)},
+		},
+		Time: event.Time,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKafkaWriter records the messages written to it
+type fakeKafkaWriter struct {
+	messages []kafka.Message
+}
+
+func (w *fakeKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error { return nil }
+
+func TestKafkaPublisher_Publish(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	p := &KafkaPublisher{writer: writer}
+	require.NoError(t, p.Publish(context.Background(), userCreated))
+
+	require.Len(t, writer.messages, 1)
+	msg := writer.messages[0]
+	assert.Equal(t, "42", string(msg.Key))
+	assert.Contains(t, string(msg.Value), `"type":"user.created"`)
+	assert.Contains(t, msg.Headers, kafka.Header{Key: "event-type", Value: []byte("user.created")})
+	assert.Contains(t, msg.Headers, kafka.Header{Key: "event-id", Value: []byte(userCreated.ID)})
+	assert.Contains(t, msg.Headers, kafka.Header{Key: "content-type", Value: []byte(ContentType)})
+	assert.Equal(t, userCreated.Time, msg.Time)
+}
+
+func TestNewKafka_Validation(t *testing.T) {
+	_, err := NewKafka(KafkaConfig{Topic: "events"})
+	assert.Error(t, err)
+	_, err = NewKafka(KafkaConfig{Brokers: []string{"localhost:9092"}})
+	assert.Error(t, err)
+
+	p, err := NewKafka(KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "events"})
+	require.NoError(t, err)
+	assert.NoError(t, p.Close())
+}
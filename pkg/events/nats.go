@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSConfig holds settings for the NATS publisher
+type NATSConfig struct {
+	// URL is the server to connect to, such as nats://localhost:4222;
+	// several can be given separated by commas
+	URL string
+	// SubjectPrefix is prepended to the event type to form the subject, so
+	// "events" publishes user.created on events.user.created
+	SubjectPrefix string
+	// Name identifies the connection in the server's monitoring
+	Name string
+}
+
+// natsConn is the part of *nats.Conn the publisher uses
+type natsConn interface {
+	PublishMsg(msg *nats.Msg) error
+	FlushWithContext(ctx context.Context) error
+	Drain() error
+}
+
+// NATSPublisher publishes each event on a subject named after its type, so
+// consumers subscribe to the types they want, e.g. "events.user.>"
+type NATSPublisher struct {
+	conn   natsConn
+	prefix string
+}
+
+// NewNATS connects to cfg.URL. The connection reconnects by itself when the
+// server goes away.
+func NewNATS(cfg NATSConfig) (*NATSPublisher, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("events: nats URL is required")
+	}
+	conn, err := nats.Connect(cfg.URL, nats.Name(cfg.Name), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("events: failed to connect to nats: %w", err)
+	}
+	return &NATSPublisher{conn: conn, prefix: cfg.SubjectPrefix}, nil
+}
+
+// Publish sends the event and waits for the server to have received it
+// (implements Publisher)
+func (p *NATSPublisher) Publish(ctx context.Context, event *Event) error {
+	msg, err := p.message(event)
+	if err != nil {
+		return err
+	}
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	// Core NATS publishes are fire-and-forget; a round trip to the server
+	// at least surfaces a lost connection
+	return p.conn.FlushWithContext(ctx)
+}
+
+// Close publishes buffered events and closes the connection (implements
+// Publisher)
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+// message encodes event as a NATS message on its subject
+func (p *NATSPublisher) message(event *Event) (*nats.Msg, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	subject := event.Type
+	if p.prefix != "" {
+		subject = p.prefix + "." + subject
+	}
+	msg := nats.NewMsg(subject)
+	msg.Data = body
+	msg.Header.Set("Content-Type", ContentType)
+	// JetStream streams drop a message whose ID they have seen recently
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	return msg, nil
+}
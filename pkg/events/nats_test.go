@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNATSConn records the messages published on it
+type fakeNATSConn struct {
+	messages []*nats.Msg
+	flushes  int
+}
+
+func (c *fakeNATSConn) PublishMsg(msg *nats.Msg) error {
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+func (c *fakeNATSConn) FlushWithContext(context.Context) error {
+	c.flushes++
+	return nil
+}
+
+func (c *fakeNATSConn) Drain() error { return nil }
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	conn := &fakeNATSConn{}
+	p := &NATSPublisher{conn: conn, prefix: "events"}
+	require.NoError(t, p.Publish(context.Background(), userCreated))
+
+	require.Len(t, conn.messages, 1)
+	msg := conn.messages[0]
+	assert.Equal(t, "events.user.created", msg.Subject)
+	assert.Equal(t, userCreated.ID, msg.Header.Get(nats.MsgIdHdr))
+	assert.Contains(t, string(msg.Data), `"subject":"42"`)
+	assert.Equal(t, 1, conn.flushes, "publishing waits for the server")
+
+	p.prefix = ""
+	require.NoError(t, p.Publish(context.Background(), userCreated))
+	assert.Equal(t, "user.created", conn.messages[1].Subject)
+}
+
+func TestNewNATS_Errors(t *testing.T) {
+	_, err := NewNATS(NATSConfig{})
+	assert.Error(t, err)
+	_, err = NewNATS(NATSConfig{URL: "nats://127.0.0.1:1"})
+	assert.ErrorContains(t, err, "failed to connect to nats")
+}
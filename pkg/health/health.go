@@ -0,0 +1,163 @@
+// Package health runs the readiness checks registered by the application's
+// components (database, cache, workers, ...) and serves their results.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Statuses of a check and of the whole report
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusReady    = "ready"
+	StatusNotReady = "not ready"
+)
+
+// DefaultTimeout bounds each check when the registry has no timeout set
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc reports whether a component can serve traffic. It must return
+// once ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of one check
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of every check; the service is ready when all are up
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Ready reports whether every check passed
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+// Registry holds named checks
+type Registry struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]CheckFunc
+}
+
+// NewRegistry creates a registry that gives each check timeout to finish;
+// zero uses DefaultTimeout
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Registry{timeout: timeout, checks: make(map[string]CheckFunc)}
+}
+
+// Register adds a check under name, replacing any check of that name
+func (r *Registry) Register(name string, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// Names returns the names of the registered checks, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check runs every check concurrently, each bounded by the registry's
+// timeout, and collects the results
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make(map[string]CheckFunc, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	r.mu.RUnlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	report := Report{Status: StatusReady, Checks: make(map[string]Result, len(checks))}
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			result := r.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusNotReady
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+// run runs one check, failing it when it outlives the timeout or panics
+func (r *Registry) run(ctx context.Context, check CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("check panicked: %v", recovered)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", r.timeout)
+	}
+
+	result := Result{
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Handler serves the report as JSON: 200 when ready, 503 otherwise
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context())
+
+		statusCode := http.StatusOK
+		if !report.Ready() {
+			statusCode = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	registry := NewRegistry(50 * time.Millisecond)
+	registry.Register("database", func(ctx context.Context) error { return nil })
+	registry.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") })
+	registry.Register("broker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	registry.Register("workers", func(ctx context.Context) error { panic("boom") })
+
+	start := time.Now()
+	report := registry.Check(context.Background())
+	assert.Less(t, time.Since(start), time.Second, "checks run concurrently and are bounded by the timeout")
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, StatusNotReady, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["database"].Status)
+	assert.Equal(t, Result{Status: StatusDown, Error: "connection refused"}, withoutLatency(report.Checks["cache"]))
+	assert.Equal(t, StatusDown, report.Checks["broker"].Status)
+	assert.Contains(t, report.Checks["workers"].Error, "check panicked: boom")
+	assert.Equal(t, []string{"broker", "cache", "database", "workers"}, registry.Names())
+}
+
+func TestRegistry_CheckTimesOutUncooperativeCheck(t *testing.T) {
+	registry := NewRegistry(20 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	registry.Register("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	report := registry.Check(context.Background())
+	assert.Equal(t, "check timed out after 20ms", report.Checks["stuck"].Error)
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.Register("database", func(ctx context.Context) error { return nil })
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, StatusReady, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["database"].Status)
+
+	registry.Register("database", func(ctx context.Context) error { return errors.New("down") })
+	rec = httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"not ready"`)
+}
+
+func withoutLatency(result Result) Result {
+	result.LatencyMS = 0
+	return result
+}
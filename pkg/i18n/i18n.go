@@ -0,0 +1,199 @@
+// Package i18n translates user-facing text with go-i18n. A Bundle holds the
+// messages of every language, loaded from YAML or JSON message files; a
+// Localizer picks the best of them for a user's locale or Accept-Language
+// header and falls back to English.
+//
+// Message files are named after their language, such as es.yaml or
+// active.pt-BR.json, and map message IDs to translations:
+//
+//	welcome.subject: "Bienvenido a {{.App.Name}}"
+//	duration.days:
+//	  one: "1 día"
+//	  other: "{{.Count}} días"
+//
+// Translations are Go templates executed with the data passed by the caller.
+// Messages without an ID of their own are keyed by their English text.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLanguage is the language messages fall back to
+var DefaultLanguage = language.English
+
+// Bundle holds the messages of every language. Load messages before
+// creating localizers; a Bundle is safe for concurrent use once loaded.
+type Bundle struct {
+	bundle *goi18n.Bundle
+}
+
+// NewBundle creates an empty bundle reading YAML and JSON message files
+func NewBundle() *Bundle {
+	bundle := goi18n.NewBundle(DefaultLanguage)
+	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
+	bundle.RegisterUnmarshalFunc("yaml", yaml.Unmarshal)
+	bundle.RegisterUnmarshalFunc("yml", yaml.Unmarshal)
+	return &Bundle{bundle: bundle}
+}
+
+// LoadFS loads every message file in dir of fsys. Messages loaded later
+// replace earlier ones with the same language and ID, so translations
+// loaded from disk can override embedded defaults.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		switch path.Ext(entry.Name()) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		name := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if _, err := b.bundle.ParseMessageFileBytes(data, name); err != nil {
+			return fmt.Errorf("failed to load messages from %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// LoadDir loads every message file in the directory dir
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// Languages returns the languages the bundle has messages for, sorted
+func (b *Bundle) Languages() []string {
+	tags := b.bundle.LanguageTags()
+	languages := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		languages = append(languages, tag.String())
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Match returns the supported language that best fits the preferences, which
+// are language tags or Accept-Language headers, most preferred first. It
+// returns DefaultLanguage when none fits.
+func (b *Bundle) Match(preferences ...string) string {
+	supported := b.bundle.LanguageTags()
+	matcher := language.NewMatcher(supported)
+	for _, preference := range preferences {
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+		if _, index, confidence := matcher.Match(tags...); confidence != language.No {
+			return supported[index].String()
+		}
+	}
+	return DefaultLanguage.String()
+}
+
+// Localizer creates a localizer for the best supported language of the
+// preferences (see Match)
+func (b *Bundle) Localizer(preferences ...string) *Localizer {
+	lang := b.Match(preferences...)
+	return &Localizer{
+		localizer: goi18n.NewLocalizer(b.bundle, lang, DefaultLanguage.String()),
+		lang:      lang,
+	}
+}
+
+// Localizer translates messages into one language. A nil Localizer returns
+// message IDs untranslated, which suits messages keyed by their English
+// text.
+type Localizer struct {
+	localizer *goi18n.Localizer
+	lang      string
+}
+
+// Language returns the language messages are translated into
+func (l *Localizer) Language() string {
+	if l == nil {
+		return DefaultLanguage.String()
+	}
+	return l.lang
+}
+
+// T translates the message id, executing it with data. Messages missing in
+// the language come from DefaultLanguage, and messages missing there too
+// are returned as id.
+func (l *Localizer) T(id string, data map[string]interface{}) string {
+	return l.localize(&goi18n.LocalizeConfig{MessageID: id, TemplateData: data}, id)
+}
+
+// Plural translates the message id in the plural form for count. data gets
+// count as "Count" unless it sets "Count" itself.
+func (l *Localizer) Plural(id string, count int, data map[string]interface{}) string {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	if _, ok := data["Count"]; !ok {
+		data["Count"] = count
+	}
+	return l.localize(&goi18n.LocalizeConfig{MessageID: id, PluralCount: count, TemplateData: data}, id)
+}
+
+// localize runs config, returning fallback when the message is unknown
+func (l *Localizer) localize(config *goi18n.LocalizeConfig, fallback string) string {
+	if l == nil {
+		return fallback
+	}
+	text, err := l.localizer.Localize(config)
+	if err != nil {
+		var notFound *goi18n.MessageNotFoundErr
+		if errors.As(err, &notFound) && text != "" {
+			// Found in the fallback language
+			return text
+		}
+		return fallback
+	}
+	return text
+}
+
+// localizerKey is the context key of the request's Localizer
+type localizerKey struct{}
+
+// NewContext returns a copy of ctx carrying l
+func NewContext(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the Localizer of ctx, or nil when there is none
+func FromContext(ctx context.Context) *Localizer {
+	l, _ := ctx.Value(localizerKey{}).(*Localizer)
+	return l
+}
+
+// Canonical returns the canonical form of the language tag s, such as
+// "pt-BR" for "pt_br", or an error when s is not a language tag
+func Canonical(s string) (string, error) {
+	tag, err := language.Parse(strings.ReplaceAll(strings.TrimSpace(s), "_", "-"))
+	if err != nil {
+		return "", fmt.Errorf("invalid language tag %q", s)
+	}
+	return tag.String(), nil
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b := NewBundle()
+	require.NoError(t, b.LoadFS(fstest.MapFS{
+		"locales/en.yaml": {Data: []byte(`
+greeting: "Hello {{.Name}}"
+duration.days:
+  one: "1 day"
+  other: "{{.Count}} days"
+only.english: "Only in English"
+`)},
+		"locales/es.yaml": {Data: []byte(`
+greeting: "Hola {{.Name}}"
+duration.days:
+  one: "1 día"
+  other: "{{.Count}} días"
+"Invalid request body": "Cuerpo de solicitud no válido"
+`)},
+		"locales/README.md": {Data: []byte("not a message file")},
+	}, "locales"))
+	return b
+}
+
+func TestBundle_Match(t *testing.T) {
+	b := newTestBundle(t)
+	assert.Equal(t, []string{"en", "es"}, b.Languages())
+
+	assert.Equal(t, "es", b.Match("es-MX"))
+	assert.Equal(t, "es", b.Match("fr-CH, fr;q=0.9, es;q=0.8, en;q=0.5"))
+	assert.Equal(t, "en", b.Match("de"), "unsupported languages fall back to English")
+	assert.Equal(t, "en", b.Match("", "not a tag"))
+	assert.Equal(t, "es", b.Match("", "es"), "later preferences are used when earlier ones are empty")
+}
+
+func TestLocalizer_Translate(t *testing.T) {
+	b := newTestBundle(t)
+	es := b.Localizer("es")
+
+	assert.Equal(t, "es", es.Language())
+	assert.Equal(t, "Hola Ana", es.T("greeting", map[string]interface{}{"Name": "Ana"}))
+	assert.Equal(t, "1 día", es.Plural("duration.days", 1, nil))
+	assert.Equal(t, "3 días", es.Plural("duration.days", 3, nil))
+	assert.Equal(t, "Only in English", es.T("only.english", nil), "missing translations fall back to English")
+	assert.Equal(t, "Cuerpo de solicitud no válido", es.T("Invalid request body", nil))
+	assert.Equal(t, "User not found", es.T("User not found", nil), "unknown messages are returned as is")
+
+	en := b.Localizer("en-US")
+	assert.Equal(t, "Invalid request body", en.T("Invalid request body", nil))
+	assert.Equal(t, "2 days", en.Plural("duration.days", 2, nil))
+
+	var none *Localizer
+	assert.Equal(t, "en", none.Language())
+	assert.Equal(t, "Invalid request body", none.T("Invalid request body", nil))
+}
+
+func TestBundle_LoadDir(t *testing.T) {
+	b := newTestBundle(t)
+	dir := t.TempDir()
+	// Translations on disk override embedded ones and add languages
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "es.yaml"), []byte(`greeting: "¡Hola, {{.Name}}!"`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "active.pt-BR.json"), []byte(`{"greeting": "Olá {{.Name}}"}`), 0o644))
+	require.NoError(t, b.LoadDir(dir))
+
+	assert.Equal(t, "¡Hola, Ana!", b.Localizer("es").T("greeting", map[string]interface{}{"Name": "Ana"}))
+	assert.Equal(t, "Olá Ana", b.Localizer("pt-BR").T("greeting", map[string]interface{}{"Name": "Ana"}))
+	assert.Equal(t, "1 día", b.Localizer("es").Plural("duration.days", 1, nil), "other messages are kept")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr.yaml"), []byte("greeting: [unclosed"), 0o644))
+	assert.Error(t, b.LoadDir(dir))
+	assert.Error(t, b.LoadDir(filepath.Join(dir, "missing")))
+}
+
+func TestContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+	l := newTestBundle(t).Localizer("es")
+	assert.Same(t, l, FromContext(NewContext(context.Background(), l)))
+}
+
+func TestCanonical(t *testing.T) {
+	tag, err := Canonical("pt_br")
+	require.NoError(t, err)
+	assert.Equal(t, "pt-BR", tag)
+	_, err = Canonical("not a tag")
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+// Package ids generates opaque, non-enumerable identifiers that are safe to
+// expose in URLs in place of auto-increment keys.
+package ids
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported public ID formats
+const (
+	// FormatInt exposes the auto-increment key (the default)
+	FormatInt = "int"
+	// FormatUUIDv7 exposes time-ordered UUIDs (RFC 9562)
+	FormatUUIDv7 = "uuidv7"
+	// FormatULID exposes time-ordered ULIDs
+	FormatULID = "ulid"
+)
+
+// ErrUnsupportedFormat is returned by NewGenerator for an unknown format
+var ErrUnsupportedFormat = errors.New("unsupported ID format")
+
+// NewGenerator returns the generator for format. FormatInt has no public
+// IDs of its own, so it returns UUIDv7 to keep records ready for a switch.
+func NewGenerator(format string) (func() string, error) {
+	switch format {
+	case FormatInt, FormatUUIDv7:
+		return NewUUIDv7, nil
+	case FormatULID:
+		return NewULID, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// NewUUIDv7 returns a new UUIDv7 string such as
+// "01920f3e-8a1b-7c2d-9e3f-4a5b6c7d8e9f"
+func NewUUIDv7() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a new 26-character ULID: a 48-bit millisecond timestamp
+// followed by 80 random bits, Crockford base32 encoded
+func NewULID() string {
+	var id [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	for i := 5; i >= 0; i-- {
+		id[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic(fmt.Sprintf("ids: failed to read random bytes: %v", err))
+	}
+
+	// 128 bits encode as 26 characters of 5 bits; the first carries 3 bits
+	var out [26]byte
+	hi := uint64(id[0])<<56 | uint64(id[1])<<48 | uint64(id[2])<<40 | uint64(id[3])<<32 |
+		uint64(id[4])<<24 | uint64(id[5])<<16 | uint64(id[6])<<8 | uint64(id[7])
+	lo := uint64(id[8])<<56 | uint64(id[9])<<48 | uint64(id[10])<<40 | uint64(id[11])<<32 |
+		uint64(id[12])<<24 | uint64(id[13])<<16 | uint64(id[14])<<8 | uint64(id[15])
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// Valid reports whether id is well-formed for format, so malformed path
+// parameters can be rejected without a database lookup
+func Valid(format, id string) bool {
+	switch format {
+	case FormatUUIDv7:
+		parsed, err := uuid.Parse(id)
+		return err == nil && len(id) == 36 && parsed.Version() == 7
+	case FormatULID:
+		if len(id) != 26 || id[0] > '7' {
+			return false
+		}
+		for i := 0; i < len(id); i++ {
+			if !isCrockford(id[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// isCrockford reports whether c is an upper-case Crockford base32 digit
+func isCrockford(c byte) bool {
+	for i := 0; i < len(crockford); i++ {
+		if crockford[i] == c {
+			return true
+		}
+	}
+	return false
+}
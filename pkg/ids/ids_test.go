@@ -0,0 +1,41 @@
+package ids
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGenerator(t *testing.T) {
+	for _, format := range []string{FormatInt, FormatUUIDv7, FormatULID} {
+		generate, err := NewGenerator(format)
+		require.NoError(t, err, format)
+		assert.NotEqual(t, generate(), generate(), format)
+	}
+
+	_, err := NewGenerator("serial")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid(FormatUUIDv7, NewUUIDv7()))
+	assert.False(t, Valid(FormatUUIDv7, "6ba7b810-9dad-11d1-80b4-00c04fd430c8"), "UUIDv1 is rejected")
+	assert.False(t, Valid(FormatUUIDv7, "42"))
+
+	assert.True(t, Valid(FormatULID, NewULID()))
+	assert.True(t, Valid(FormatULID, "01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	assert.False(t, Valid(FormatULID, "01ARZ3NDEKTSV4RRFFQ69G5FAU"), "U is not a Crockford digit")
+	assert.False(t, Valid(FormatULID, "81ARZ3NDEKTSV4RRFFQ69G5FAV"), "overflows 128 bits")
+
+	assert.False(t, Valid(FormatInt, "42"))
+}
+
+func TestNewULID_TimeOrdered(t *testing.T) {
+	first := NewULID()
+	require.Len(t, first, 26)
+
+	// The first 10 characters encode the millisecond timestamp
+	later := NewULID()
+	assert.LessOrEqual(t, first[:10], later[:10])
+}
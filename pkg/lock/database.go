@@ -0,0 +1,87 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lockRow is a row of the locks table
+type lockRow struct {
+	Name      string `gorm:"primaryKey"`
+	Owner     string
+	ExpiresAt time.Time
+}
+
+func (lockRow) TableName() string {
+	return "locks"
+}
+
+// DatabaseLocker keeps locks as rows of the locks table, for deployments
+// without Redis. Expiry is judged by each instance's clock, so keep clocks
+// in sync and TTLs well above the expected skew.
+type DatabaseLocker struct {
+	db *gorm.DB
+}
+
+// NewDatabaseLocker creates a locker using the locks table in db
+func NewDatabaseLocker(db *gorm.DB) *DatabaseLocker {
+	return &DatabaseLocker{db: db}
+}
+
+// TryAcquire takes the named lock for ttl: it inserts the row, or takes over
+// a row whose lease has expired (implements Locker)
+func (l *DatabaseLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	db := l.db.WithContext(ctx)
+	result := db.Model(&lockRow{}).
+		Where("name = ? AND expires_at < ?", name, now).
+		Updates(map[string]interface{}{"owner": token, "expires_at": now.Add(ttl)})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		result = db.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&lockRow{Name: name, Owner: token, ExpiresAt: now.Add(ttl)})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, ErrNotAcquired
+		}
+	}
+	return &databaseLock{db: l.db, name: name, token: token}, nil
+}
+
+// databaseLock is a lock held as a row of the locks table
+type databaseLock struct {
+	db    *gorm.DB
+	name  string
+	token string
+}
+
+func (l *databaseLock) Extend(ctx context.Context, ttl time.Duration) error {
+	result := l.db.WithContext(ctx).Model(&lockRow{}).
+		Where("name = ? AND owner = ?", l.name, l.token).
+		Update("expires_at", time.Now().UTC().Add(ttl))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotAcquired
+	}
+	return nil
+}
+
+func (l *databaseLock) Release(ctx context.Context) error {
+	return l.db.WithContext(ctx).
+		Where("name = ? AND owner = ?", l.name, l.token).
+		Delete(&lockRow{}).Error
+}
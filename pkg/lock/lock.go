@@ -0,0 +1,43 @@
+// Package lock provides named, expiring locks shared by every instance of the
+// application, so work such as a scheduled job runs on one replica at a time.
+//
+// Locks are leases: a holder that dies loses its lock once the TTL passes,
+// so holders of long-running work extend the lease while they run.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrNotAcquired is returned when the lock is held by someone else, or when
+// an extended lock turns out to have expired and been taken over
+var ErrNotAcquired = errors.New("lock: held by another owner")
+
+// Locker hands out named locks
+type Locker interface {
+	// TryAcquire takes the named lock for ttl without waiting. It returns
+	// ErrNotAcquired while another owner holds it.
+	TryAcquire(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is a held lock
+type Lock interface {
+	// Extend makes the lock expire ttl from now. It returns ErrNotAcquired
+	// when the lock was lost.
+	Extend(ctx context.Context, ttl time.Duration) error
+	// Release gives the lock up; releasing a lost lock is not an error
+	Release(ctx context.Context) error
+}
+
+// newToken returns a random owner token identifying one acquisition
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,77 @@
+package lock
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/aungmyozaw92/go-api-setup/internal/config"
+	"github.com/aungmyozaw92/go-api-setup/pkg/database"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLocker exercises the behaviour every Locker must share. expire makes
+// locks taken for ttl expire.
+func testLocker(t *testing.T, l Locker, ttl time.Duration, expire func()) {
+	ctx := context.Background()
+
+	first, err := l.TryAcquire(ctx, "job:report", ttl)
+	require.NoError(t, err)
+
+	_, err = l.TryAcquire(ctx, "job:report", ttl)
+	assert.ErrorIs(t, err, ErrNotAcquired, "held locks can't be taken")
+
+	other, err := l.TryAcquire(ctx, "job:other", ttl)
+	require.NoError(t, err, "locks are independent")
+	require.NoError(t, other.Release(ctx))
+
+	require.NoError(t, first.Extend(ctx, ttl))
+	require.NoError(t, first.Release(ctx))
+
+	second, err := l.TryAcquire(ctx, "job:report", ttl)
+	require.NoError(t, err, "released locks can be taken")
+
+	expire()
+	third, err := l.TryAcquire(ctx, "job:report", ttl)
+	require.NoError(t, err, "expired locks can be taken over")
+
+	assert.ErrorIs(t, second.Extend(ctx, ttl), ErrNotAcquired, "a lost lock can't be extended")
+	require.NoError(t, second.Release(ctx), "releasing a lost lock is not an error")
+
+	_, err = l.TryAcquire(ctx, "job:report", ttl)
+	assert.ErrorIs(t, err, ErrNotAcquired, "releasing a lost lock leaves the new owner's")
+	require.NoError(t, third.Release(ctx))
+}
+
+func TestRedisLocker(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	l := NewRedisLocker(client, "app:")
+	testLocker(t, l, time.Minute, func() { server.FastForward(2 * time.Minute) })
+
+	held, err := l.TryAcquire(context.Background(), "job:keys", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, server.Exists("app:lock:job:keys"), "keys are prefixed")
+	require.NoError(t, held.Release(context.Background()))
+}
+
+func TestDatabaseLocker(t *testing.T) {
+	db, err := database.Connect(&config.DatabaseConfig{
+		Driver:     database.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, database.MigrateUp(context.Background(), db, database.DriverSQLite))
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	ttl := 250 * time.Millisecond
+	testLocker(t, NewDatabaseLocker(db), ttl, func() { time.Sleep(2 * ttl) })
+}
@@ -0,0 +1,78 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Scripts that only touch the key while it still holds the caller's token
+var (
+	extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisLocker keeps locks in Redis as keys set with SET NX and a TTL
+type RedisLocker struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisLocker creates a locker storing each lock under prefix+"lock:"+name
+func NewRedisLocker(client redis.UniversalClient, prefix string) *RedisLocker {
+	return &RedisLocker{client: client, prefix: prefix + "lock:"}
+}
+
+// Ping checks that Redis answers
+func (l *RedisLocker) Ping(ctx context.Context) error {
+	return l.client.Ping(ctx).Err()
+}
+
+// TryAcquire takes the named lock for ttl (implements Locker)
+func (l *RedisLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.prefix + name
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotAcquired
+	}
+	return &redisLock{client: l.client, key: key, token: token}, nil
+}
+
+// redisLock is a lock held in Redis
+type redisLock struct {
+	client redis.UniversalClient
+	key    string
+	token  string
+}
+
+func (l *redisLock) Extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := extendScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrNotAcquired
+	}
+	return nil
+}
+
+func (l *redisLock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+}
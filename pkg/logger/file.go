@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"io"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileConfig configures a rotating log file
+type FileConfig struct {
+	Path string
+	// MaxSizeMB is the size in megabytes at which the file is rotated
+	MaxSizeMB int
+	// MaxAgeDays removes rotated files older than this many days; 0 keeps
+	// them regardless of age
+	MaxAgeDays int
+	// MaxBackups is the number of rotated files kept; 0 keeps them all
+	// (subject to MaxAgeDays)
+	MaxBackups int
+	// Compress gzips rotated files
+	Compress bool
+}
+
+// NewFile returns a writer appending to cfg.Path that rotates the file once
+// it reaches cfg.MaxSizeMB, renaming it with a UTC timestamp, and prunes old
+// rotations by age and count in the background
+func NewFile(cfg FileConfig) io.WriteCloser {
+	return &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxAge:     cfg.MaxAgeDays,
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+	}
+}
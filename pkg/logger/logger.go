@@ -0,0 +1,77 @@
+// Package logger builds the application's structured logger on log/slog and
+// carries request-scoped loggers through contexts, so every layer logs with
+// the request and user IDs of the request it serves.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ParseLevel parses a level name such as "debug", "info", "warn" or "error",
+// case-insensitively
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unsupported log level %q", name)
+	}
+	return level, nil
+}
+
+// ParseFormat validates an output format name, case-insensitively
+func ParseFormat(name string) (string, error) {
+	switch format := strings.ToLower(name); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported log format %q", name)
+	}
+}
+
+// New creates a logger writing records at or above level to w, as
+// logfmt-style text or, with FormatJSON, one JSON object per line
+func New(w io.Writer, level slog.Leveler, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// Discard returns a logger that drops every record, for tests
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+// contextKey is the context key under which the request's logger is stored
+type contextKey struct{}
+
+// NewContext returns a context carrying l; code handling the request picks
+// it up with FromContext
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or slog.Default() when
+// there is none
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// With returns a context whose logger adds the given attributes to every
+// record, e.g. With(ctx, "user_id", id)
+func With(ctx context.Context, args ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(args...))
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, slog.LevelInfo, FormatJSON)
+
+	l.Debug("hidden")
+	l.Info("user created", "user_id", 7)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record), "only the info record is written")
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "user created", record["msg"])
+	assert.Equal(t, 7.0, record["user_id"])
+}
+
+func TestContextLogger(t *testing.T) {
+	assert.Same(t, slog.Default(), FromContext(context.Background()))
+
+	var buf bytes.Buffer
+	ctx := NewContext(context.Background(), New(&buf, slog.LevelInfo, FormatText))
+	ctx = With(ctx, "request_id", "req-1")
+	ctx = With(ctx, "user_id", 7)
+
+	FromContext(ctx).Warn("slow request")
+	assert.Contains(t, buf.String(), `level=WARN msg="slow request" request_id=req-1 user_id=7`)
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	level, err = ParseLevel("warn")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("JSON")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestNewFile_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file := NewFile(FileConfig{Path: path, MaxSizeMB: 1})
+	defer file.Close()
+
+	l := New(file, slog.LevelInfo, FormatJSON)
+	padding := strings.Repeat("x", 1024)
+	for i := 0; i < 3*1024; i++ {
+		l.Info("filler", "padding", padding)
+	}
+	require.NoError(t, file.Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(entries), 3, "the current file and its rotations")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(1024*1024))
+}
@@ -0,0 +1,66 @@
+// Package mailer delivers rendered emails through a mail provider: an SMTP
+// server, SendGrid or Amazon SES. Every Mailer is an email.Sender, so the
+// email worker sends the outbox through whichever provider is configured.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+)
+
+// Mailer delivers emails through a mail provider. Send is safe for
+// concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, envelope *email.Envelope) error
+}
+
+// defaultTimeout bounds each API call of the HTTP-based mailers, for
+// contexts without a deadline
+const defaultTimeout = 30 * time.Second
+
+// parseFrom parses the sender address, such as "Go API <no-reply@example.com>"
+func parseFrom(from string) (*mail.Address, error) {
+	if from == "" {
+		return nil, errors.New("mailer: from address is required")
+	}
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid from address %q: %w", from, err)
+	}
+	return addr, nil
+}
+
+// parseRecipient parses an envelope's recipient address
+func parseRecipient(to string) (*mail.Address, error) {
+	addr, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid recipient %q: %w", to, err)
+	}
+	return addr, nil
+}
+
+// newHTTPClient returns client, or a client with the default timeout when nil
+func newHTTPClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: defaultTimeout}
+}
+
+// checkResponse turns a provider API's error response into an error quoting
+// the start of its body
+func checkResponse(provider string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("mailer: %s responded %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+)
+
+// DefaultSendGridEndpoint is SendGrid's v3 mail send API
+const DefaultSendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridConfig holds the settings for sending through SendGrid's API
+type SendGridConfig struct {
+	APIKey string
+	From   string
+	// Endpoint overrides DefaultSendGridEndpoint, e.g. for SendGrid's EU region
+	Endpoint string
+	// HTTPClient overrides the client calling the API
+	HTTPClient *http.Client
+}
+
+// SendGridMailer sends emails through SendGrid's v3 API
+type SendGridMailer struct {
+	apiKey   string
+	from     *mail.Address
+	endpoint string
+	client   *http.Client
+}
+
+// NewSendGrid creates a mailer sending through SendGrid
+func NewSendGrid(cfg SendGridConfig) (*SendGridMailer, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("mailer: SendGrid API key is required")
+	}
+	from, err := parseFrom(cfg.From)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultSendGridEndpoint
+	}
+	return &SendGridMailer{
+		apiKey:   cfg.APIKey,
+		from:     from,
+		endpoint: cfg.Endpoint,
+		client:   newHTTPClient(cfg.HTTPClient),
+	}, nil
+}
+
+// sendGridAddress is an address in SendGrid's API
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridContent is a body in SendGrid's API
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridPersonalization lists the recipients of a message
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+// sendGridMessage is the request body of the mail send API
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// Send posts the email to SendGrid's mail send API (implements Mailer)
+func (m *SendGridMailer) Send(ctx context.Context, envelope *email.Envelope) error {
+	to, err := parseRecipient(envelope.To)
+	if err != nil {
+		return err
+	}
+
+	msg := sendGridMessage{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: to.Address, Name: to.Name}}}},
+		From:             sendGridAddress{Email: m.from.Address, Name: m.from.Name},
+		Subject:          envelope.Subject,
+	}
+	// SendGrid requires the plain-text body before the HTML one
+	if envelope.Text != "" {
+		msg.Content = append(msg.Content, sendGridContent{Type: "text/plain", Value: envelope.Text})
+	}
+	if envelope.HTML != "" {
+		msg.Content = append(msg.Content, sendGridContent{Type: "text/html", Value: envelope.HTML})
+	}
+	if len(msg.Content) == 0 {
+		return errors.New("mailer: email has no body")
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mailer: calling SendGrid: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse("SendGrid", resp)
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendGridMailer_Send(t *testing.T) {
+	var got sendGridMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer SG.key", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	m, err := NewSendGrid(SendGridConfig{APIKey: "SG.key", From: "Go API <no-reply@example.com>", Endpoint: server.URL})
+	require.NoError(t, err)
+	err = m.Send(context.Background(), &email.Envelope{
+		To:      "ada@example.com",
+		Message: email.Message{Subject: "Welcome", HTML: "<p>Hello</p>", Text: "Hello"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, sendGridMessage{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: "ada@example.com"}}}},
+		From:             sendGridAddress{Email: "no-reply@example.com", Name: "Go API"},
+		Subject:          "Welcome",
+		Content: []sendGridContent{
+			{Type: "text/plain", Value: "Hello"},
+			{Type: "text/html", Value: "<p>Hello</p>"},
+		},
+	}, got)
+}
+
+func TestSendGridMailer_SendFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors":[{"message":"The provided authorization grant is invalid"}]}`))
+	}))
+	defer server.Close()
+
+	m, err := NewSendGrid(SendGridConfig{APIKey: "SG.wrong", From: "no-reply@example.com", Endpoint: server.URL})
+	require.NoError(t, err)
+	err = m.Send(context.Background(), &email.Envelope{To: "ada@example.com", Message: email.Message{Text: "Hello"}})
+	assert.EqualError(t, err, `mailer: SendGrid responded 401: {"errors":[{"message":"The provided authorization grant is invalid"}]}`)
+}
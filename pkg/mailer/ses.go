@@ -0,0 +1,217 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// SESConfig holds the settings for sending through Amazon SES
+type SESConfig struct {
+	Region string
+	// AccessKeyID and SecretAccessKey are optional; when empty, credentials
+	// are read from the AWS environment variables, shared credentials file,
+	// or IAM role
+	AccessKeyID     string
+	SecretAccessKey string
+	From            string
+	// ConfigurationSet names an SES configuration set, for event publishing
+	ConfigurationSet string
+	// Endpoint overrides the regional SES API endpoint
+	Endpoint string
+	// HTTPClient overrides the client calling the API
+	HTTPClient *http.Client
+}
+
+// SESMailer sends emails through the SES v2 API
+type SESMailer struct {
+	region           string
+	creds            *credentials.Credentials
+	from             *mail.Address
+	configurationSet string
+	endpoint         string
+	client           *http.Client
+	now              func() time.Time
+}
+
+// NewSES creates a mailer sending through Amazon SES
+func NewSES(cfg SESConfig) (*SESMailer, error) {
+	if cfg.Region == "" {
+		return nil, errors.New("mailer: SES region is required")
+	}
+	from, err := parseFrom(cfg.From)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://email." + cfg.Region + ".amazonaws.com"
+	}
+
+	creds := credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	if cfg.AccessKeyID == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		})
+	}
+
+	return &SESMailer{
+		region:           cfg.Region,
+		creds:            creds,
+		from:             from,
+		configurationSet: cfg.ConfigurationSet,
+		endpoint:         strings.TrimSuffix(cfg.Endpoint, "/"),
+		client:           newHTTPClient(cfg.HTTPClient),
+		now:              time.Now,
+	}, nil
+}
+
+// sesContent is a text in the SES API
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+// sesMessage is the request body of the SES v2 SendEmail API
+type sesMessage struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text *sesContent `json:"Text,omitempty"`
+				HTML *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+		} `json:"Simple"`
+	} `json:"Content"`
+	ConfigurationSetName string `json:"ConfigurationSetName,omitempty"`
+}
+
+// Send calls the SES SendEmail API (implements Mailer)
+func (m *SESMailer) Send(ctx context.Context, envelope *email.Envelope) error {
+	to, err := parseRecipient(envelope.To)
+	if err != nil {
+		return err
+	}
+	if envelope.Text == "" && envelope.HTML == "" {
+		return errors.New("mailer: email has no body")
+	}
+
+	var msg sesMessage
+	msg.FromEmailAddress = m.from.String()
+	msg.Destination.ToAddresses = []string{to.String()}
+	msg.Content.Simple.Subject = sesContent{Data: envelope.Subject, Charset: "UTF-8"}
+	if envelope.Text != "" {
+		msg.Content.Simple.Body.Text = &sesContent{Data: envelope.Text, Charset: "UTF-8"}
+	}
+	if envelope.HTML != "" {
+		msg.Content.Simple.Body.HTML = &sesContent{Data: envelope.HTML, Charset: "UTF-8"}
+	}
+	msg.ConfigurationSetName = m.configurationSet
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	creds, err := m.creds.Get()
+	if err != nil {
+		return fmt.Errorf("mailer: loading AWS credentials: %w", err)
+	}
+	signV4(req, body, creds, m.region, "ses", m.now())
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mailer: calling SES: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse("SES", resp)
+}
+
+// signV4 signs req with AWS Signature Version 4, signing every header set
+// on it plus the host and date
+func signV4(req *http.Request, body []byte, creds credentials.Value, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	// Canonical headers: lowercase names, sorted, with the host
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
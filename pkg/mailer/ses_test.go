@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSignV4 checks the signer against the "get-vanilla" case of AWS's
+// Signature Version 4 test suite
+func TestSignV4(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	creds := credentials.Value{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestSESMailer_Send(t *testing.T) {
+	var got sesMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"), r.Header.Get("Authorization"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date, ")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(`{"MessageId":"0100"}`))
+	}))
+	defer server.Close()
+
+	m, err := NewSES(SESConfig{
+		Region:           "eu-west-1",
+		AccessKeyID:      "AKID",
+		SecretAccessKey:  "secret",
+		From:             "no-reply@example.com",
+		ConfigurationSet: "transactional",
+		Endpoint:         server.URL,
+	})
+	require.NoError(t, err)
+	err = m.Send(context.Background(), &email.Envelope{
+		To:      "ada@example.com",
+		Message: email.Message{Subject: "Welcome", Text: "Hello"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "<no-reply@example.com>", got.FromEmailAddress)
+	assert.Equal(t, []string{"<ada@example.com>"}, got.Destination.ToAddresses)
+	assert.Equal(t, "Welcome", got.Content.Simple.Subject.Data)
+	require.NotNil(t, got.Content.Simple.Body.Text)
+	assert.Equal(t, "Hello", got.Content.Simple.Body.Text.Data)
+	assert.Nil(t, got.Content.Simple.Body.HTML)
+	assert.Equal(t, "transactional", got.ConfigurationSetName)
+}
@@ -0,0 +1,238 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+)
+
+// SMTPConfig holds the settings for sending through an SMTP server
+type SMTPConfig struct {
+	Host string
+	// Port defaults to 587 (submission with STARTTLS); port 465 connects
+	// over TLS from the start
+	Port string
+	// Username and Password authenticate with PLAIN auth, which is only used
+	// over TLS or to localhost; empty sends without authenticating
+	Username string
+	Password string
+	From     string
+	// TLSConfig overrides the TLS settings, e.g. to trust a private CA
+	TLSConfig *tls.Config
+}
+
+// SMTPMailer sends emails through an SMTP server, one connection per email
+type SMTPMailer struct {
+	addr      string
+	host      string
+	implicit  bool
+	auth      smtp.Auth
+	from      *mail.Address
+	tlsConfig *tls.Config
+}
+
+// NewSMTP creates a mailer sending through the SMTP server in cfg
+func NewSMTP(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("mailer: SMTP host is required")
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	from, err := parseFrom(cfg.From)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := cfg.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig = tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = cfg.Host
+	}
+
+	m := &SMTPMailer{
+		addr:      net.JoinHostPort(cfg.Host, cfg.Port),
+		host:      cfg.Host,
+		implicit:  cfg.Port == "465",
+		from:      from,
+		tlsConfig: tlsConfig,
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m, nil
+}
+
+// Send delivers the email in one SMTP session (implements Mailer)
+func (m *SMTPMailer) Send(ctx context.Context, envelope *email.Envelope) error {
+	to, err := parseRecipient(envelope.To)
+	if err != nil {
+		return err
+	}
+	msg, err := buildMessage(m.from, to, envelope, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("mailer: connecting to SMTP server: %w", err)
+	}
+	if m.implicit {
+		conn = tls.Client(conn, m.tlsConfig)
+	}
+	// The SMTP client has no notion of contexts; the deadline bounds the
+	// whole session instead
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mailer: SMTP handshake: %w", err)
+	}
+	defer client.Close()
+
+	if err := m.deliver(client, to.Address, msg); err != nil {
+		return fmt.Errorf("mailer: SMTP: %w", err)
+	}
+	return client.Quit()
+}
+
+// deliver upgrades the session to TLS when offered, authenticates and
+// transfers msg
+func (m *SMTPMailer) deliver(client *smtp.Client, to string, msg []byte) error {
+	if !m.implicit {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(m.tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if m.auth != nil {
+		if err := client.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// buildMessage formats envelope as a MIME message, with the plain-text and
+// HTML bodies as alternatives when it has both
+func buildMessage(from, to *mail.Address, envelope *email.Envelope, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", envelope.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+
+	var parts []textproto.MIMEHeader
+	var bodies []string
+	if envelope.Text != "" {
+		parts = append(parts, partHeader("text/plain"))
+		bodies = append(bodies, envelope.Text)
+	}
+	if envelope.HTML != "" {
+		parts = append(parts, partHeader("text/html"))
+		bodies = append(bodies, envelope.HTML)
+	}
+
+	switch len(parts) {
+	case 0:
+		return nil, errors.New("mailer: email has no body")
+	case 1:
+		header("Content-Type", parts[0].Get("Content-Type"))
+		header("Content-Transfer-Encoding", parts[0].Get("Content-Transfer-Encoding"))
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, bodies[0]); err != nil {
+			return nil, err
+		}
+	default:
+		mw := multipart.NewWriter(&buf)
+		header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+		buf.WriteString("\r\n")
+		for i, part := range parts {
+			w, err := mw.CreatePart(part)
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(w, bodies[i]); err != nil {
+				return nil, err
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// partHeader is the header of a quoted-printable UTF-8 body
+func partHeader(contentType string) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}
+}
+
+// writeQuotedPrintable writes body to w in quoted-printable encoding
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndexByte(from, '@'); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
@@ -0,0 +1,150 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one SMTP session without TLS or auth and records
+// the commands and message it receives
+type fakeSMTPServer struct {
+	addr     string
+	commands chan []string
+	data     chan string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	s := &fakeSMTPServer{addr: lis.Addr().String(), commands: make(chan []string, 1), data: make(chan string, 1)}
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		var commands []string
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			commands = append(commands, line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case line == "DATA":
+				reply("354 go ahead")
+				var msg strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					msg.WriteString(l)
+				}
+				s.data <- msg.String()
+				reply("250 queued")
+			case line == "QUIT":
+				reply("221 bye")
+				s.commands <- commands
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return s
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	host, port, err := net.SplitHostPort(server.addr)
+	require.NoError(t, err)
+
+	m, err := NewSMTP(SMTPConfig{Host: host, Port: port, From: "Go API <no-reply@example.com>"})
+	require.NoError(t, err)
+	err = m.Send(context.Background(), &email.Envelope{
+		To:      "Ada <ada@example.com>",
+		Message: email.Message{Subject: "Welcome, Ada ✓", HTML: "<p>Hello</p>", Text: "Hello"},
+	})
+	require.NoError(t, err)
+
+	commands := <-server.commands
+	assert.Contains(t, commands, "MAIL FROM:<no-reply@example.com>")
+	assert.Contains(t, commands, "RCPT TO:<ada@example.com>")
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-server.data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome, Ada ✓", subject)
+	assert.Equal(t, `"Go API" <no-reply@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, `"Ada" <ada@example.com>`, msg.Header.Get("To"))
+	assert.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", "Hello"},
+		{"text/html; charset=utf-8", "<p>Hello</p>"},
+	} {
+		part, err := parts.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, want.contentType, part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, want.body, string(body))
+	}
+}
+
+func TestBuildMessage_SingleBody(t *testing.T) {
+	from := &mail.Address{Address: "no-reply@example.com"}
+	to := &mail.Address{Address: "ada@example.com"}
+	msg, err := buildMessage(from, to, &email.Envelope{Message: email.Message{Subject: "Hi", Text: "Hello"}}, time.Unix(0, 0))
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(msg)))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", parsed.Header.Get("Content-Type"))
+	assert.Equal(t, "quoted-printable", parsed.Header.Get("Content-Transfer-Encoding"))
+
+	_, err = buildMessage(from, to, &email.Envelope{Message: email.Message{Subject: "Hi"}}, time.Unix(0, 0))
+	assert.EqualError(t, err, "mailer: email has no body")
+}
+
+func TestNewSMTP_Validates(t *testing.T) {
+	_, err := NewSMTP(SMTPConfig{From: "no-reply@example.com"})
+	assert.EqualError(t, err, "mailer: SMTP host is required")
+
+	_, err = NewSMTP(SMTPConfig{Host: "smtp.example.com"})
+	assert.EqualError(t, err, "mailer: from address is required")
+
+	_, err = NewSMTP(SMTPConfig{Host: "smtp.example.com", From: "not an address"})
+	assert.ErrorContains(t, err, `mailer: invalid from address "not an address"`)
+}
@@ -0,0 +1,131 @@
+// Package reporting sends unexpected errors and panics to an error tracker
+// such as Sentry, together with the request and user they happened for.
+//
+// Middleware attaches that context with WithTag, WithUser and WithRequest;
+// code that hits an unexpected failure calls ReportError with its context.
+// Nothing is sent until main installs a reporter with SetDefault.
+package reporting
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorReporter sends errors to an error tracker
+type ErrorReporter interface {
+	// ReportError sends err with the context attached to ctx
+	ReportError(ctx context.Context, err error)
+	// ReportPanic sends a value recovered from a panic with the context
+	// attached to ctx; call it from the deferred function that recovered
+	// so the report's stack trace points at the panic
+	ReportPanic(ctx context.Context, recovered any)
+	// Flush waits up to timeout for queued reports to be sent
+	Flush(timeout time.Duration) bool
+}
+
+// nopReporter drops every report
+type nopReporter struct{}
+
+func (nopReporter) ReportError(context.Context, error) {}
+func (nopReporter) ReportPanic(context.Context, any)   {}
+func (nopReporter) Flush(time.Duration) bool           { return true }
+
+// Nop returns an ErrorReporter that drops every report
+func Nop() ErrorReporter {
+	return nopReporter{}
+}
+
+// holder lets an ErrorReporter of any type be stored atomically
+type holder struct {
+	reporter ErrorReporter
+}
+
+var defaultReporter atomic.Pointer[holder]
+
+func init() {
+	defaultReporter.Store(&holder{reporter: Nop()})
+}
+
+// SetDefault makes r the reporter used by ReportError and ReportPanic; nil
+// restores Nop
+func SetDefault(r ErrorReporter) {
+	if r == nil {
+		r = Nop()
+	}
+	defaultReporter.Store(&holder{reporter: r})
+}
+
+// Default returns the reporter installed with SetDefault
+func Default() ErrorReporter {
+	return defaultReporter.Load().reporter
+}
+
+// ReportError sends err to the default reporter
+func ReportError(ctx context.Context, err error) {
+	if err != nil {
+		Default().ReportError(ctx, err)
+	}
+}
+
+// ReportPanic sends a recovered panic value to the default reporter
+func ReportPanic(ctx context.Context, recovered any) {
+	Default().ReportPanic(ctx, recovered)
+}
+
+// User identifies the user a report happened for
+type User struct {
+	ID    string
+	Email string
+}
+
+// Scope is the context attached to reports
+type Scope struct {
+	Tags    map[string]string
+	User    User
+	Request *http.Request
+}
+
+// scopeKey is the context key under which the Scope is stored
+type scopeKey struct{}
+
+// ScopeFromContext returns the Scope attached to ctx; the zero Scope when
+// there is none
+func ScopeFromContext(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	return scope
+}
+
+// withScope returns a context carrying a copy of ctx's Scope changed by update
+func withScope(ctx context.Context, update func(*Scope)) context.Context {
+	scope := ScopeFromContext(ctx)
+	scope.Tags = maps.Clone(scope.Tags)
+	update(&scope)
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// WithTag returns a context whose reports carry the tag, e.g. a request ID
+func WithTag(ctx context.Context, key, value string) context.Context {
+	return withScope(ctx, func(scope *Scope) {
+		if scope.Tags == nil {
+			scope.Tags = make(map[string]string)
+		}
+		scope.Tags[key] = value
+	})
+}
+
+// WithUser returns a context whose reports name the user
+func WithUser(ctx context.Context, id, email string) context.Context {
+	return withScope(ctx, func(scope *Scope) {
+		scope.User = User{ID: id, Email: email}
+	})
+}
+
+// WithRequest returns a context whose reports describe the HTTP request
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	return withScope(ctx, func(scope *Scope) {
+		scope.Request = r
+	})
+}
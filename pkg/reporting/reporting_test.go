@@ -0,0 +1,82 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScope(t *testing.T) {
+	assert.Equal(t, Scope{}, ScopeFromContext(context.Background()))
+
+	parent := WithTag(context.Background(), "request_id", "req-1")
+	child := WithUser(WithTag(parent, "tenant", "acme"), "7", "alice@example.com")
+
+	assert.Equal(t, map[string]string{"request_id": "req-1"}, ScopeFromContext(parent).Tags, "children don't change their parent's scope")
+	scope := ScopeFromContext(child)
+	assert.Equal(t, map[string]string{"request_id": "req-1", "tenant": "acme"}, scope.Tags)
+	assert.Equal(t, User{ID: "7", Email: "alice@example.com"}, scope.User)
+}
+
+func newMockSentry(t *testing.T) (*SentryReporter, *sentry.MockTransport) {
+	transport := &sentry.MockTransport{}
+	reporter, err := newSentry(sentry.ClientOptions{
+		Dsn:              "https://public@sentry.example.com/1",
+		Transport:        transport,
+		AttachStacktrace: true,
+	})
+	require.NoError(t, err)
+	return reporter, transport
+}
+
+func TestSentryReporter_ReportError(t *testing.T) {
+	reporter, transport := newMockSentry(t)
+
+	ctx := WithUser(WithTag(context.Background(), "request_id", "req-1"), "7", "alice@example.com")
+	ctx = WithRequest(ctx, httptest.NewRequest("GET", "/api/users/7", nil))
+	reporter.ReportError(ctx, errors.New("failed to get user: connection refused"))
+	require.True(t, reporter.Flush(time.Second))
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "failed to get user: connection refused", events[0].Exception[0].Value)
+	assert.Equal(t, "req-1", events[0].Tags["request_id"])
+	assert.Equal(t, "7", events[0].User.ID)
+	assert.Contains(t, events[0].Request.URL, "/api/users/7")
+}
+
+func TestSentryReporter_ReportPanic(t *testing.T) {
+	reporter, transport := newMockSentry(t)
+
+	func() {
+		defer func() {
+			reporter.ReportPanic(WithTag(context.Background(), "worker", "WebhookWorker"), recover())
+		}()
+		panic("boom")
+	}()
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "boom", events[0].Message)
+	assert.Equal(t, "WebhookWorker", events[0].Tags["worker"])
+}
+
+func TestDefault(t *testing.T) {
+	reporter, transport := newMockSentry(t)
+	SetDefault(reporter)
+	t.Cleanup(func() { SetDefault(nil) })
+
+	ReportError(context.Background(), nil)
+	ReportError(context.Background(), errors.New("unexpected"))
+	assert.Len(t, transport.Events(), 1, "nil errors are not reported")
+
+	SetDefault(nil)
+	ReportError(context.Background(), errors.New("dropped"))
+	assert.Len(t, transport.Events(), 1)
+}
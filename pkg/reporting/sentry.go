@@ -0,0 +1,74 @@
+package reporting
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryConfig holds settings for the Sentry reporter
+type SentryConfig struct {
+	DSN         string
+	Environment string
+	Release     string
+	// SampleRate is the fraction of errors sent, from 0 to 1
+	SampleRate float64
+}
+
+// SentryReporter sends reports to Sentry
+type SentryReporter struct {
+	hub *sentry.Hub
+}
+
+// NewSentry creates a reporter sending to the Sentry project of cfg.DSN
+func NewSentry(cfg SentryConfig) (*SentryReporter, error) {
+	return newSentry(sentry.ClientOptions{
+		Dsn:              cfg.DSN,
+		Environment:      cfg.Environment,
+		Release:          cfg.Release,
+		SampleRate:       cfg.SampleRate,
+		AttachStacktrace: true,
+	})
+}
+
+// newSentry creates a reporter from client options; tests pass a mock transport
+func newSentry(opts sentry.ClientOptions) (*SentryReporter, error) {
+	client, err := sentry.NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SentryReporter{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// ReportError sends err to Sentry with the context attached to ctx
+func (r *SentryReporter) ReportError(ctx context.Context, err error) {
+	r.hubFor(ctx).CaptureException(err)
+}
+
+// ReportPanic sends a recovered panic value to Sentry with the context
+// attached to ctx
+func (r *SentryReporter) ReportPanic(ctx context.Context, recovered any) {
+	r.hubFor(ctx).RecoverWithContext(ctx, recovered)
+}
+
+// Flush waits up to timeout for queued reports to be sent
+func (r *SentryReporter) Flush(timeout time.Duration) bool {
+	return r.hub.Flush(timeout)
+}
+
+// hubFor returns a hub whose scope carries the context attached to ctx
+func (r *SentryReporter) hubFor(ctx context.Context) *sentry.Hub {
+	hub := r.hub.Clone()
+	scope := ScopeFromContext(ctx)
+	hub.ConfigureScope(func(s *sentry.Scope) {
+		s.SetTags(scope.Tags)
+		if scope.User != (User{}) {
+			s.SetUser(sentry.User{ID: scope.User.ID, Email: scope.User.Email})
+		}
+		if scope.Request != nil {
+			s.SetRequest(scope.Request)
+		}
+	})
+	return hub
+}
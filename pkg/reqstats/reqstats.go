@@ -0,0 +1,96 @@
+// Package reqstats accumulates where a request spent its time (database
+// queries, cache calls, ...) and who made it, for the slow request log and
+// the audit log.
+// Components add to the Stats carried by the request context; outside a
+// request there are none and adding is a no-op.
+package reqstats
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Span totals the calls made to one component
+type Span struct {
+	Component string
+	Calls     int
+	Duration  time.Duration
+}
+
+// Stats accumulates the spans and user of a request. A nil *Stats ignores
+// every call.
+type Stats struct {
+	mu    sync.Mutex
+	user  string
+	spans map[string]*Span
+}
+
+// contextKey is the context key under which the Stats are stored
+type contextKey struct{}
+
+// NewContext returns a context carrying fresh Stats, and the Stats
+func NewContext(ctx context.Context) (context.Context, *Stats) {
+	stats := &Stats{spans: make(map[string]*Span)}
+	return context.WithValue(ctx, contextKey{}, stats), stats
+}
+
+// FromContext returns the Stats carried by ctx, or nil
+func FromContext(ctx context.Context) *Stats {
+	stats, _ := ctx.Value(contextKey{}).(*Stats)
+	return stats
+}
+
+// Add records a call to component that took d
+func (s *Stats) Add(component string, d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	span, ok := s.spans[component]
+	if !ok {
+		span = &Span{Component: component}
+		s.spans[component] = span
+	}
+	span.Calls++
+	span.Duration += d
+}
+
+// SetUser records the authenticated user making the request
+func (s *Stats) SetUser(id string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = id
+}
+
+// User returns the user set with SetUser, or ""
+func (s *Stats) User() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.user
+}
+
+// Spans returns the recorded spans sorted by component
+func (s *Stats) Spans() []Span {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spans := make([]Span, 0, len(s.spans))
+	for _, span := range s.spans {
+		spans = append(spans, *span)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Component < spans[j].Component })
+	return spans
+}
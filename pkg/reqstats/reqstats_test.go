@@ -0,0 +1,37 @@
+package reqstats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	ctx, stats := NewContext(context.Background())
+	assert.Same(t, stats, FromContext(ctx))
+
+	FromContext(ctx).Add("db", 30*time.Millisecond)
+	FromContext(ctx).Add("cache", time.Millisecond)
+	FromContext(ctx).Add("db", 20*time.Millisecond)
+	FromContext(ctx).SetUser("7")
+
+	assert.Equal(t, []Span{
+		{Component: "cache", Calls: 1, Duration: time.Millisecond},
+		{Component: "db", Calls: 2, Duration: 50 * time.Millisecond},
+	}, stats.Spans())
+	assert.Equal(t, "7", stats.User())
+}
+
+func TestStats_NoRequest(t *testing.T) {
+	stats := FromContext(context.Background())
+	assert.Nil(t, stats)
+
+	assert.NotPanics(t, func() {
+		stats.Add("db", time.Millisecond)
+		stats.SetUser("7")
+	})
+	assert.Empty(t, stats.Spans())
+	assert.Empty(t, stats.User())
+}
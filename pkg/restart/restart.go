@@ -0,0 +1,144 @@
+// Package restart hands a server's listening sockets to a new process of the
+// same binary, so a deploy can replace the binary without refusing a single
+// connection: the new process accepts on the inherited sockets, and once it
+// is ready the old one stops accepting and finishes its requests in flight.
+//
+// The old process calls Start, passing its listeners by name; the new one
+// gets them back from Listen and calls Ready when it serves them, which
+// sends the old process SIGTERM.
+package restart
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Environment variables describing the inherited listeners to the new process
+const (
+	// envListeners names the inherited listeners, comma-separated, in the
+	// order of their file descriptors from 3
+	envListeners = "RESTART_LISTENERS"
+	// envParent is the process ID of the process to stop once ready
+	envParent = "RESTART_PARENT_PID"
+)
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   map[string]net.Listener
+	parentPID   int
+)
+
+// inherit takes over the listeners passed by the parent, once
+func inherit() {
+	inheritOnce.Do(func() {
+		names := os.Getenv(envListeners)
+		parentPID, _ = strconv.Atoi(os.Getenv(envParent))
+		// Don't pass these on to processes this one starts
+		os.Unsetenv(envListeners)
+		os.Unsetenv(envParent)
+		if names == "" {
+			return
+		}
+
+		inherited = make(map[string]net.Listener)
+		for i, name := range strings.Split(names, ",") {
+			f := os.NewFile(uintptr(3+i), name)
+			lis, err := net.FileListener(f)
+			f.Close()
+			if err != nil {
+				slog.Error("Failed to inherit listener", "listener", name, "error", err)
+				continue
+			}
+			inherited[name] = lis
+		}
+	})
+}
+
+// Inherited reports whether this process was started by Start to take over
+// another's listeners
+func Inherited() bool {
+	inherit()
+	return parentPID != 0
+}
+
+// Listen returns the listener inherited under name, or else listens on addr
+func Listen(name, network, addr string) (net.Listener, error) {
+	inherit()
+	inheritMu.Lock()
+	lis, ok := inherited[name]
+	delete(inherited, name)
+	inheritMu.Unlock()
+	if ok {
+		return lis, nil
+	}
+	return net.Listen(network, addr)
+}
+
+// Start starts a new process of the binary at os.Args[0], which a deploy
+// has replaced, with the same arguments and environment, handing it
+// listeners by name. This process should keep serving until the new one
+// calls Ready. The returned process can be waited on to learn whether the
+// new process exited instead.
+func Start(listeners map[string]net.Listener) (*os.Process, error) {
+	names := make([]string, 0, len(listeners))
+	for name := range listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]*os.File, 0, len(names))
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, name := range names {
+		filer, ok := listeners[name].(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, fmt.Errorf("listener %s can't be passed to another process", name)
+		}
+		f, err := filer.File()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pass listener %s: %w", name, err)
+		}
+		files = append(files, f)
+	}
+
+	binary, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(binary, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		envListeners+"="+strings.Join(names, ","),
+		envParent+"="+strconv.Itoa(os.Getpid()),
+	)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", binary, err)
+	}
+	return cmd.Process, nil
+}
+
+// Ready tells the process that started this one, if any, to shut down now
+// that this one serves the listeners it inherited
+func Ready() error {
+	if !Inherited() {
+		return nil
+	}
+	parent, err := os.FindProcess(parentPID)
+	if err != nil {
+		return err
+	}
+	return parent.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,88 @@
+package restart
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test binary doubles as the new process Start runs
+const envHelper = "RESTART_TEST_HELPER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envHelper) != "" {
+		os.Exit(runHelper())
+	}
+	os.Exit(m.Run())
+}
+
+// runHelper takes over the "http" listener, reports ready and answers one
+// connection
+func runHelper() int {
+	if !Inherited() {
+		return 2
+	}
+	lis, err := Listen("http", "tcp", "127.0.0.1:0")
+	if err != nil {
+		return 3
+	}
+	if err := Ready(); err != nil {
+		return 4
+	}
+	conn, err := lis.Accept()
+	if err != nil {
+		return 5
+	}
+	defer conn.Close()
+	io.WriteString(conn, "hello from the new process")
+	return 0
+}
+
+func TestStart(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+
+	// Ready signals this process to stop
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	t.Setenv(envHelper, "1")
+	proc, err := Start(map[string]net.Listener{"http": lis})
+	require.NoError(t, err)
+
+	select {
+	case <-stop:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the new process did not report ready")
+	}
+	// The old process stops accepting; the socket stays open in the new one
+	require.NoError(t, lis.Close())
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "hello from the new process", string(reply))
+
+	state, err := proc.Wait()
+	require.NoError(t, err)
+	assert.True(t, state.Success(), state.String())
+}
+
+func TestListen_WithoutInheritedListeners(t *testing.T) {
+	assert.False(t, Inherited())
+	lis, err := Listen("http", "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	lis.Close()
+}
@@ -0,0 +1,218 @@
+package siem
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Exporter defaults, used for zero ExporterConfig fields
+const (
+	DefaultBufferSize    = 10000
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 5 * time.Second
+	DefaultMaxAttempts   = 5
+	DefaultRetryBackoff  = time.Second
+)
+
+// maxRetryBackoff caps the wait between attempts at writing a batch
+const maxRetryBackoff = time.Minute
+
+// ExporterConfig holds settings for an Exporter
+type ExporterConfig struct {
+	// BufferSize is how many events can wait to be written before new ones
+	// are dropped
+	BufferSize int
+	// BatchSize is how many events are written at once; a batch is written
+	// when it is full or FlushInterval after its first event, whichever is
+	// first
+	BatchSize     int
+	FlushInterval time.Duration
+	// MaxAttempts is how often a batch is written before it is dropped
+	MaxAttempts int
+	// RetryBackoff is the wait before the second attempt, doubling for each
+	// attempt after it up to a minute
+	RetryBackoff time.Duration
+	// Source names this instance in events; empty uses the host name
+	Source string
+	// Logger reports batches that are dropped; nil uses slog.Default()
+	Logger *slog.Logger
+}
+
+// Exporter buffers events and writes them to a sink in batches from a
+// background goroutine. Export never blocks: when the buffer is full the
+// event is dropped and counted.
+type Exporter struct {
+	sink   Sink
+	cfg    ExporterConfig
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Event
+	stop   chan struct{}
+	done   chan struct{}
+
+	dropped atomic.Int64
+}
+
+// NewExporter creates an exporter writing to sink and starts its writer.
+// Close it to write the buffered events at shutdown.
+func NewExporter(sink Sink, cfg ExporterConfig) *Exporter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.Source == "" {
+		cfg.Source, _ = os.Hostname()
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	e := &Exporter{
+		sink:   sink,
+		cfg:    cfg,
+		logger: logger,
+		queue:  make(chan Event, cfg.BufferSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Export queues event to be written. Events exported after Close, or while
+// the buffer is full, are dropped. A nil Exporter drops every event.
+func (e *Exporter) Export(event Event) {
+	if e == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.Time = event.Time.UTC()
+	if event.Source == "" {
+		event.Source = e.cfg.Source
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		e.dropped.Add(1)
+		return
+	}
+	select {
+	case e.queue <- event:
+	default:
+		// Only warn for the first event dropped, not for each of a burst
+		if e.dropped.Add(1) == 1 {
+			e.logger.Warn("Dropped SIEM event, too many waiting to be written", "type", event.Type)
+		}
+	}
+}
+
+// Dropped returns how many events were dropped, because the buffer was full
+// or their batch kept failing
+func (e *Exporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Close stops accepting events, writes the buffered ones and closes the
+// sink. Batches failing during shutdown get one more attempt, without
+// waiting, rather than the full backoff.
+func (e *Exporter) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.stop)
+	close(e.queue)
+	e.mu.Unlock()
+
+	<-e.done
+	return e.sink.Close()
+}
+
+// run collects queued events into batches and writes them
+func (e *Exporter) run() {
+	defer close(e.done)
+	batch := make([]Event, 0, e.cfg.BatchSize)
+	timer := time.NewTimer(e.cfg.FlushInterval)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-e.queue:
+			if !ok {
+				e.write(batch)
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(e.cfg.FlushInterval)
+			}
+			batch = append(batch, event)
+			if len(batch) < e.cfg.BatchSize {
+				continue
+			}
+			timer.Stop()
+		case <-timer.C:
+		}
+		e.write(batch)
+		batch = batch[:0]
+	}
+}
+
+// write writes a batch, retrying with backoff until it succeeds, runs out
+// of attempts or the exporter is closed
+func (e *Exporter) write(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+	backoff := e.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := e.sink.Write(context.Background(), batch)
+		if err == nil {
+			return
+		}
+		if attempt >= e.cfg.MaxAttempts || e.stopping() {
+			e.dropped.Add(int64(len(batch)))
+			e.logger.Error("Dropped SIEM events, writing them failed", "events", len(batch), "attempts", attempt, "error", err)
+			return
+		}
+		e.logger.Warn("Failed to write SIEM events, retrying", "events", len(batch), "attempt", attempt, "retry_in", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-e.stop:
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// stopping reports whether Close was called
+func (e *Exporter) stopping() bool {
+	select {
+	case <-e.stop:
+		return true
+	default:
+		return false
+	}
+}
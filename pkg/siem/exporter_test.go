@@ -0,0 +1,102 @@
+package siem
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink records the batches written to it, failing the first
+// failures writes
+type memorySink struct {
+	mu       sync.Mutex
+	batches  [][]Event
+	failures int
+	attempts int
+	closed   bool
+}
+
+func (s *memorySink) Write(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("collector unavailable")
+	}
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) written() [][]Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]Event(nil), s.batches...)
+}
+
+func TestExporter_Batches(t *testing.T) {
+	sink := &memorySink{}
+	exporter := NewExporter(sink, ExporterConfig{BatchSize: 2, FlushInterval: 20 * time.Millisecond, Source: "api-1", Logger: logger.Discard()})
+
+	exporter.Export(Event{Type: "audit.request"})
+	exporter.Export(Event{Type: "audit.request"})
+	exporter.Export(Event{Type: "auth.login_failed", Source: "worker-1"})
+
+	// A full batch is written at once, the rest after the flush interval
+	require.Eventually(t, func() bool { return len(sink.written()) == 2 }, time.Second, 5*time.Millisecond)
+	batches := sink.written()
+	assert.Len(t, batches[0], 2)
+	require.Len(t, batches[1], 1)
+	assert.Equal(t, "api-1", batches[0][0].Source)
+	assert.Equal(t, "worker-1", batches[1][0].Source)
+	assert.False(t, batches[0][0].Time.IsZero())
+
+	require.NoError(t, exporter.Close())
+	assert.True(t, sink.closed)
+	exporter.Export(Event{Type: "audit.request"})
+	assert.Equal(t, int64(1), exporter.Dropped(), "events exported after Close are dropped")
+}
+
+func TestExporter_RetriesFailedBatches(t *testing.T) {
+	sink := &memorySink{failures: 2}
+	exporter := NewExporter(sink, ExporterConfig{BatchSize: 1, RetryBackoff: time.Millisecond, MaxAttempts: 3, Logger: logger.Discard()})
+	exporter.Export(Event{Type: "auth.login_failed"})
+	require.Eventually(t, func() bool { return len(sink.written()) == 1 }, time.Second, 5*time.Millisecond)
+	sink.mu.Lock()
+	assert.Equal(t, 3, sink.attempts)
+	sink.failures = 3
+	sink.mu.Unlock()
+	exporter.Export(Event{Type: "auth.login_failed"})
+	require.Eventually(t, func() bool { return exporter.Dropped() == 1 }, time.Second, 5*time.Millisecond, "batches failing every attempt are dropped")
+	require.NoError(t, exporter.Close())
+}
+
+func TestExporter_DropsWhenBufferIsFull(t *testing.T) {
+	sink := &memorySink{}
+	exporter := NewExporter(sink, ExporterConfig{BufferSize: 2, BatchSize: 100, FlushInterval: time.Hour, Logger: logger.Discard()})
+	for i := 0; i < 200; i++ {
+		exporter.Export(Event{Type: "audit.request"})
+	}
+	assert.Positive(t, exporter.Dropped())
+
+	// Close writes what was buffered
+	require.NoError(t, exporter.Close())
+	var written int64
+	for _, batch := range sink.written() {
+		written += int64(len(batch))
+	}
+	assert.Equal(t, int64(200), written+exporter.Dropped())
+}
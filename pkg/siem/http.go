@@ -0,0 +1,79 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPConfig holds settings for an HTTP sink
+type HTTPConfig struct {
+	// URL is the collector's endpoint batches are posted to
+	URL string
+	// Headers are sent with every request, such as the collector's
+	// Authorization header
+	Headers map[string]string
+	// HTTPClient posts the batches; nil uses a client with a 30s timeout
+	HTTPClient *http.Client
+}
+
+// HTTP posts batches of events to a log collector as newline-delimited JSON,
+// which collectors such as Vector, Fluent Bit, Logstash and Elastic
+// ingest
+type HTTP struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTP creates a sink posting to cfg.URL
+func NewHTTP(cfg HTTPConfig) (*HTTP, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("siem: collector URL must be an http or https URL")
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTP{url: cfg.URL, headers: cfg.Headers, client: client}, nil
+}
+
+// Write posts the batch in one request (implements Sink)
+func (h *HTTP) Write(ctx context.Context, events []Event) error {
+	body, err := appendNDJSON(nil, events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for name, value := range h.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("siem: posting to collector: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("siem: collector returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close does nothing; requests don't hold connections open (implements Sink)
+func (h *HTTP) Close() error {
+	return nil
+}
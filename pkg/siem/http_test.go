@@ -0,0 +1,41 @@
+package siem
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_PostsNDJSON(t *testing.T) {
+	var lines []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		lines = strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTP(HTTPConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), []Event{{Type: "audit.request"}, {Type: "auth.login_failed"}}))
+	require.Len(t, lines, 2)
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, "auth.login_failed", event.Type)
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorContains(t, sink.Write(context.Background(), []Event{{Type: "audit.request"}}), "collector returned 503")
+
+	_, err = NewHTTP(HTTPConfig{URL: "collector:8080"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package siem
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/ids"
+	"github.com/aungmyozaw92/go-api-setup/pkg/storage"
+)
+
+// Object stores each batch of events as a gzipped newline-delimited JSON
+// object, for SIEMs that load logs from a bucket, such as Splunk's and
+// Elastic's S3 inputs
+type Object struct {
+	store  storage.Storage
+	prefix string
+}
+
+// NewObject creates a sink storing batches in store under prefix. Objects
+// are keyed <prefix>/<yyyy>/<mm>/<dd>/<ULID>.ndjson.gz, so they list in the
+// order they were written.
+func NewObject(store storage.Storage, prefix string) *Object {
+	return &Object{store: store, prefix: strings.Trim(prefix, "/")}
+}
+
+// Write stores the batch as one object (implements Sink)
+func (o *Object) Write(ctx context.Context, events []Event) error {
+	body, err := appendNDJSON(nil, events)
+	if err != nil {
+		return err
+	}
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(body); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	key := path.Join(o.prefix, events[0].Time.UTC().Format("2006/01/02"), ids.NewULID()+".ndjson.gz")
+	if err := o.store.Put(ctx, key, &compressed, "application/gzip"); err != nil {
+		return fmt.Errorf("siem: storing %s: %w", key, err)
+	}
+	return nil
+}
+
+// Close does nothing; the store outlives the sink (implements Sink)
+func (o *Object) Close() error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package siem
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObject_StoresGzippedBatches(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir, "http://localhost/storage/", "secret")
+	require.NoError(t, err)
+	sink := NewObject(store, "/siem/")
+
+	at := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, sink.Write(context.Background(), []Event{{Time: at, Type: "audit.request"}, {Time: at, Type: "audit.request"}}))
+
+	matches, err := filepath.Glob(filepath.Join(dir, "siem", "2026", "10", "15", "*.ndjson.gz"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	f, err := os.Open(matches[0])
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(body), "\n"))
+	assert.Contains(t, string(body), `"type":"audit.request"`)
+}
@@ -0,0 +1,69 @@
+// Package siem ships audit log entries and security events, such as failed
+// logins, to a security information and event management system. An
+// Exporter buffers events in memory and writes them to a Sink in batches
+// from a background goroutine, retrying failed batches, so recording an
+// event never waits on the SIEM.
+//
+// Sinks are a syslog server (RFC 5424), an HTTP collector taking
+// newline-delimited JSON, or a bucket the batches are stored in as gzipped
+// newline-delimited JSON objects.
+package siem
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Severity is how urgent an event is for the security team
+type Severity string
+
+// Event severities
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Event is one audit or security event
+type Event struct {
+	// Time is when the event happened; the exporter sets it when zero
+	Time time.Time `json:"time"`
+	// Type classifies the event, such as "audit.request" or
+	// "auth.login_failed"
+	Type     string   `json:"type"`
+	Severity Severity `json:"severity"`
+	// Source names the instance the event happened on; the exporter sets
+	// it when empty
+	Source  string `json:"source"`
+	Message string `json:"message"`
+	// ActorID is the user who acted, empty for anonymous requests
+	ActorID   string `json:"actor_id,omitempty"`
+	ClientIP  string `json:"client_ip,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Attributes are the details of the event, such as the request's route
+	// and status
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Sink writes batches of events to a SIEM
+type Sink interface {
+	// Write sends a batch; on failure the exporter retries the whole batch,
+	// so SIEMs may see an event twice
+	Write(ctx context.Context, events []Event) error
+	// Close releases the sink's connections
+	Close() error
+}
+
+// appendNDJSON appends the events to buf as newline-delimited JSON
+func appendNDJSON(buf []byte, events []Event) ([]byte, error) {
+	for i := range events {
+		line, err := json.Marshal(&events[i])
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	return buf, nil
+}
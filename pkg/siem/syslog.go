@@ -0,0 +1,164 @@
+package siem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// facilityLogAudit is the syslog facility of audit events
+const facilityLogAudit = 13
+
+// syslogSeverities maps event severities to syslog's
+var syslogSeverities = map[Severity]int{
+	SeverityInfo:     6, // informational
+	SeverityWarning:  4, // warning
+	SeverityCritical: 2, // critical
+}
+
+// SyslogConfig holds settings for a Syslog sink
+type SyslogConfig struct {
+	// Network is "udp" or "tcp"
+	Network string
+	// Addr is the server's host:port
+	Addr string
+	// AppName names the application in messages; empty uses "go-api-setup"
+	AppName string
+	// Timeout bounds connecting and writing; zero uses 10s
+	Timeout time.Duration
+}
+
+// Syslog writes events to a syslog server as RFC 5424 messages with the
+// event as JSON in the message. Over TCP, messages are framed by octet
+// counting (RFC 6587).
+type Syslog struct {
+	cfg      SyslogConfig
+	hostname string
+	pid      string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslog creates a sink writing to the syslog server at cfg.Addr. It
+// connects on the first write.
+func NewSyslog(cfg SyslogConfig) (*Syslog, error) {
+	if cfg.Network != "udp" && cfg.Network != "tcp" {
+		return nil, fmt.Errorf("siem: syslog network must be udp or tcp, got %q", cfg.Network)
+	}
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		return nil, fmt.Errorf("siem: syslog address must be host:port: %w", err)
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = "go-api-setup"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	return &Syslog{cfg: cfg, hostname: hostname, pid: strconv.Itoa(os.Getpid())}, nil
+}
+
+// Write sends one message per event (implements Sink)
+func (s *Syslog) Write(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		dialer := net.Dialer{Timeout: s.cfg.Timeout}
+		conn, err := dialer.DialContext(ctx, s.cfg.Network, s.cfg.Addr)
+		if err != nil {
+			return fmt.Errorf("siem: connecting to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(s.cfg.Timeout)); err != nil {
+		return s.reset(err)
+	}
+	for i := range events {
+		msg, err := s.format(&events[i])
+		if err != nil {
+			return err
+		}
+		if s.cfg.Network == "tcp" {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		if _, err := s.conn.Write(msg); err != nil {
+			// The batch is retried in full, on a new connection
+			return s.reset(err)
+		}
+	}
+	return nil
+}
+
+// reset drops the connection after a failure so the retry reconnects
+func (s *Syslog) reset(err error) error {
+	s.conn.Close()
+	s.conn = nil
+	return fmt.Errorf("siem: writing to syslog: %w", err)
+}
+
+// format builds the RFC 5424 message of event
+func (s *Syslog) format(event *Event) ([]byte, error) {
+	severity, ok := syslogSeverities[event.Severity]
+	if !ok {
+		severity = syslogSeverities[SeverityInfo]
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	header := fmt.Sprintf("<%d>1 %s %s %s %s %s - ",
+		facilityLogAudit*8+severity,
+		event.Time.UTC().Format(time.RFC3339Nano),
+		headerField(s.hostname, 255),
+		headerField(s.cfg.AppName, 48),
+		s.pid,
+		headerField(event.Type, 32),
+	)
+	return append([]byte(header), body...), nil
+}
+
+// Close closes the connection (implements Sink)
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// headerField makes value a valid RFC 5424 header field: printable ASCII
+// without spaces, at most n characters, "-" when empty
+func headerField(value string, n int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > n {
+		value = value[:n]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,100 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslog_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	sink, err := NewSyslog(SyslogConfig{Network: "udp", Addr: server.LocalAddr().String(), AppName: "api"})
+	require.NoError(t, err)
+	defer sink.Close()
+	at := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, sink.Write(context.Background(), []Event{
+		{Time: at, Type: "auth.login_failed", Severity: SeverityWarning, Message: "Failed login"},
+	}))
+
+	buf := make([]byte, 4096)
+	require.NoError(t, server.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := server.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+
+	// log audit (13) * 8 + warning (4)
+	assert.True(t, strings.HasPrefix(msg, "<108>1 2026-10-15T12:00:00Z "), msg)
+	fields := strings.SplitN(msg, " ", 8)
+	require.Len(t, fields, 8)
+	assert.Equal(t, "api", fields[3])
+	assert.Equal(t, "auth.login_failed", fields[5])
+	assert.Equal(t, "-", fields[6])
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(fields[7]), &event))
+	assert.Equal(t, "Failed login", event.Message)
+}
+
+func TestSyslog_TCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var messages []string
+		for len(messages) < 2 {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			messages = append(messages, string(msg))
+		}
+		received <- messages
+	}()
+
+	sink, err := NewSyslog(SyslogConfig{Network: "tcp", Addr: listener.Addr().String()})
+	require.NoError(t, err)
+	defer sink.Close()
+	require.NoError(t, sink.Write(context.Background(), []Event{
+		{Time: time.Now(), Type: "audit.request", Severity: SeverityInfo},
+		{Time: time.Now(), Type: "audit.request", Severity: SeverityInfo},
+	}))
+
+	select {
+	case messages := <-received:
+		for _, msg := range messages {
+			assert.True(t, strings.HasPrefix(msg, "<110>1 "), msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("syslog messages not received")
+	}
+}
+
+func TestNewSyslog_RejectsBadSettings(t *testing.T) {
+	_, err := NewSyslog(SyslogConfig{Network: "tls", Addr: "siem:6514"})
+	assert.ErrorContains(t, err, "udp or tcp")
+	_, err = NewSyslog(SyslogConfig{Network: "udp", Addr: "siem"})
+	assert.ErrorContains(t, err, "host:port")
+}
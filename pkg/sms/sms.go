@@ -0,0 +1,105 @@
+// Package sms sends text messages through an SMS provider. Senders are
+// interchangeable: Twilio in production, LogSender in development and
+// Recorder in tests.
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+)
+
+// ErrInvalidNumber is returned for phone numbers that are not in E.164
+// format, such as "+14155550100"
+var ErrInvalidNumber = errors.New("sms: phone number must be in E.164 format, such as +14155550100")
+
+// e164 matches an E.164 phone number: a plus sign and up to 15 digits
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// Message is a text message addressed to a phone number in E.164 format
+type Message struct {
+	To   string
+	Body string
+}
+
+// Sender delivers text messages. Send is safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ValidateNumber checks that number is in E.164 format
+func ValidateNumber(number string) error {
+	if !e164.MatchString(number) {
+		return ErrInvalidNumber
+	}
+	return nil
+}
+
+// APIError is an error response of an SMS provider's API
+type APIError struct {
+	Provider   string
+	StatusCode int
+	// Code is the provider's own error code, when it sent one
+	Code    int
+	Message string
+}
+
+// Error describes the failure (implements error)
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("sms: %s responded %d: %s (code %d)", e.Provider, e.StatusCode, e.Message, e.Code)
+	}
+	return fmt.Sprintf("sms: %s responded %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Temporary reports whether sending again may succeed: the provider failed
+// or asked to slow down, rather than rejecting the message
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+// LogSender logs text messages instead of sending them, for development and
+// for deployments without an SMS provider
+type LogSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender creates a sender logging to logger
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+// Send logs the recipient and body (implements Sender)
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	s.logger.InfoContext(ctx, "SMS not sent, no SMS provider configured", "to", msg.To, "body", msg.Body)
+	return nil
+}
+
+// Recorder keeps the messages sent to it instead of sending them, for tests
+type Recorder struct {
+	mu       sync.Mutex
+	messages []Message
+	// Err, when set, is returned by Send without recording the message
+	Err error
+}
+
+// Send records msg (implements Sender)
+func (r *Recorder) Send(ctx context.Context, msg *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.messages = append(r.messages, *msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (r *Recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message(nil), r.messages...)
+}
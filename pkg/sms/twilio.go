@@ -0,0 +1,118 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTwilioBaseURL is Twilio's REST API
+const DefaultTwilioBaseURL = "https://api.twilio.com"
+
+// TwilioConfig holds the settings for sending through Twilio's Messages API
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	// From is the sending number in E.164 format. MessagingServiceSID sends
+	// through a messaging service instead, which picks the number; one of
+	// the two is required.
+	From                string
+	MessagingServiceSID string
+	// BaseURL overrides DefaultTwilioBaseURL
+	BaseURL string
+	// HTTPClient overrides the client calling the API
+	HTTPClient *http.Client
+}
+
+// Twilio sends text messages through Twilio
+type Twilio struct {
+	accountSID          string
+	authToken           string
+	from                string
+	messagingServiceSID string
+	endpoint            string
+	client              *http.Client
+}
+
+// NewTwilio creates a sender using Twilio's Messages API
+func NewTwilio(cfg TwilioConfig) (*Twilio, error) {
+	if cfg.AccountSID == "" || cfg.AuthToken == "" {
+		return nil, errors.New("sms: Twilio account SID and auth token are required")
+	}
+	if cfg.From == "" && cfg.MessagingServiceSID == "" {
+		return nil, errors.New("sms: Twilio from number or messaging service SID is required")
+	}
+	if cfg.From != "" {
+		if err := ValidateNumber(cfg.From); err != nil {
+			return nil, fmt.Errorf("sms: invalid Twilio from number %q", cfg.From)
+		}
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultTwilioBaseURL
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Twilio{
+		accountSID:          cfg.AccountSID,
+		authToken:           cfg.AuthToken,
+		from:                cfg.From,
+		messagingServiceSID: cfg.MessagingServiceSID,
+		endpoint:            strings.TrimSuffix(cfg.BaseURL, "/") + "/2010-04-01/Accounts/" + url.PathEscape(cfg.AccountSID) + "/Messages.json",
+		client:              client,
+	}, nil
+}
+
+// twilioError is the body of Twilio's error responses
+type twilioError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Send creates the message with Twilio (implements Sender)
+func (t *Twilio) Send(ctx context.Context, msg *Message) error {
+	if err := ValidateNumber(msg.To); err != nil {
+		return err
+	}
+	if msg.Body == "" {
+		return errors.New("sms: message has no body")
+	}
+
+	form := url.Values{"To": {msg.To}, "Body": {msg.Body}}
+	if t.messagingServiceSID != "" {
+		form.Set("MessagingServiceSid", t.messagingServiceSID)
+	} else {
+		form.Set("From", t.from)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms: calling Twilio: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &APIError{Provider: "Twilio", StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	var parsed twilioError
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		apiErr.Code = parsed.Code
+		apiErr.Message = parsed.Message
+	}
+	return apiErr
+}
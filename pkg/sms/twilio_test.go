@@ -0,0 +1,74 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwilio_Send(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		got = r
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	sender, err := NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15005550006", BaseURL: server.URL})
+	require.NoError(t, err)
+	require.NoError(t, sender.Send(context.Background(), &Message{To: "+14155550100", Body: "Your code is 123456"}))
+
+	require.NotNil(t, got)
+	assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", got.URL.Path)
+	user, pass, ok := got.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "AC123", user)
+	assert.Equal(t, "secret", pass)
+	assert.Equal(t, "+14155550100", got.PostForm.Get("To"))
+	assert.Equal(t, "+15005550006", got.PostForm.Get("From"))
+	assert.Equal(t, "Your code is 123456", got.PostForm.Get("Body"))
+}
+
+func TestTwilio_Errors(t *testing.T) {
+	status := http.StatusBadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code":21211,"message":"The 'To' number is not a valid phone number.","status":400}`))
+	}))
+	defer server.Close()
+
+	sender, err := NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "secret", MessagingServiceSID: "MG123", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), &Message{To: "+14155550100", Body: "hi"})
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 21211, apiErr.Code)
+	assert.False(t, apiErr.Temporary(), "rejected messages are not retried")
+	assert.EqualError(t, err, "sms: Twilio responded 400: The 'To' number is not a valid phone number. (code 21211)")
+
+	status = http.StatusServiceUnavailable
+	err = sender.Send(context.Background(), &Message{To: "+14155550100", Body: "hi"})
+	require.True(t, errors.As(err, &apiErr))
+	assert.True(t, apiErr.Temporary())
+
+	assert.ErrorIs(t, sender.Send(context.Background(), &Message{To: "555-0100", Body: "hi"}), ErrInvalidNumber)
+
+	_, err = NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "secret"})
+	assert.EqualError(t, err, "sms: Twilio from number or messaging service SID is required")
+}
+
+func TestValidateNumber(t *testing.T) {
+	assert.NoError(t, ValidateNumber("+14155550100"))
+	assert.NoError(t, ValidateNumber("+959123456789"))
+	for _, number := range []string{"", "14155550100", "+0123456789", "+1 415 555 0100", "+1234567890123456"} {
+		assert.ErrorIs(t, ValidateNumber(number), ErrInvalidNumber, number)
+	}
+}
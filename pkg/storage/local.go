@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage stores objects as files under a base directory. Signed URLs
+// point at Handler, which must be mounted at baseURL.
+type LocalStorage struct {
+	baseDir       string
+	baseURL       string
+	secret        []byte
+	maxObjectSize int64
+}
+
+// LocalOption configures a LocalStorage
+type LocalOption func(*LocalStorage)
+
+// WithMaxObjectSize limits the size of the objects SignedUploadURL signs for
+func WithMaxObjectSize(n int64) LocalOption {
+	return func(s *LocalStorage) {
+		s.maxObjectSize = n
+	}
+}
+
+// NewLocalStorage creates a local disk storage rooted at baseDir, creating the
+// directory if needed. baseURL is the public URL Handler is served under and
+// secret signs the URLs returned by SignedURL.
+func NewLocalStorage(baseDir, baseURL, secret string, opts ...LocalOption) (*LocalStorage, error) {
+	if secret == "" {
+		return nil, errors.New("storage: signing secret is required")
+	}
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: failed to create base directory: %w", err)
+	}
+
+	s := &LocalStorage{
+		baseDir: baseDir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Put writes the object to a temporary file and renames it into place so
+// readers never observe a partial object
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get opens the file stored under key
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// Delete removes the file stored under key
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL returns an HMAC-signed download URL for Handler
+func (s *LocalStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(http.MethodGet, key, expires, noSize))
+
+	return s.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// SignedUploadURL returns an HMAC-signed upload URL for Handler; the size is
+// part of the signature
+func (s *LocalStorage) SignedUploadURL(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (*SignedUpload, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	if size < 0 || (s.maxObjectSize > 0 && size > s.maxObjectSize) {
+		return nil, fmt.Errorf("storage: upload size %d is out of range", size)
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("signature", s.sign(http.MethodPut, key, expires, size))
+
+	return &SignedUpload{
+		URL:     s.baseURL + "/" + key + "?" + query.Encode(),
+		Headers: map[string]string{"Content-Type": contentType},
+	}, nil
+}
+
+// Handler serves signed GET and PUT requests for objects. The object key is
+// the request path, so mount it with http.StripPrefix. Objects are always
+// served as attachments, so an uploaded HTML file can't run in our origin.
+func (s *LocalStorage) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		query := r.URL.Query()
+		size := int64(noSize)
+		if r.Method == http.MethodPut {
+			var err error
+			if size, err = strconv.ParseInt(query.Get("size"), 10, 64); err != nil {
+				http.Error(w, "invalid or missing size", http.StatusForbidden)
+				return
+			}
+		}
+		if err := s.verify(r.Method, key, query.Get("expires"), size, query.Get("signature")); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			path, err := s.path(key)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if _, err := os.Stat(path); err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", "attachment")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			http.ServeFile(w, r, path)
+		case http.MethodPut:
+			// The body must be exactly the signed size; the server reads no
+			// more than Content-Length and fails a shorter body
+			if r.ContentLength != size {
+				http.Error(w, "Content-Length must match the signed size", http.StatusBadRequest)
+				return
+			}
+			body := http.MaxBytesReader(w, r.Body, size)
+			if err := s.Put(r.Context(), key, body, r.Header.Get("Content-Type")); err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					http.Error(w, "object too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "failed to store object", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// path maps a key to a file path inside baseDir
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.baseDir, filepath.FromSlash(key)), nil
+}
+
+// noSize is the size signed into download URLs, which don't limit it
+const noSize = -1
+
+// sign computes the signature for method, key, expiry and upload size
+func (s *LocalStorage) sign(method, key string, expires, size int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, key, expires)
+	if size != noSize {
+		fmt.Fprintf(mac, "\n%d", size)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks a signed URL's signature and expiry
+func (s *LocalStorage) verify(method, key, expiresStr string, size int64, signature string) error {
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil {
+		return errors.New("invalid or missing expiry")
+	}
+	if time.Now().Unix() > expires {
+		return errors.New("signed URL has expired")
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(method, key, expires, size))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStorage(t *testing.T) *LocalStorage {
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost/storage/", "secret")
+	require.NoError(t, err)
+	return s
+}
+
+func TestLocalStorage_PutGetDelete(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "files/1/abc", strings.NewReader("hello"), "text/plain"))
+
+	rc, err := s.Get(ctx, "files/1/abc")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "hello", string(content))
+
+	require.NoError(t, s.Delete(ctx, "files/1/abc"))
+	_, err = s.Get(ctx, "files/1/abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, s.Delete(ctx, "files/1/abc"), "deleting a missing object is not an error")
+}
+
+func TestLocalStorage_RejectsInvalidKeys(t *testing.T) {
+	s := newTestStorage(t)
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "files/../../secret", "files//x", `files\x`} {
+		err := s.Put(context.Background(), key, strings.NewReader("x"), "")
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestLocalStorage_SignedURL(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	require.NoError(t, s.Put(ctx, "files/1/abc", strings.NewReader("<script>alert(1)</script>"), "text/html"))
+
+	signed, err := s.SignedURL(ctx, "files/1/abc", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "http://localhost/storage/files/1/abc?"))
+
+	t.Run("valid signature is served as an attachment", func(t *testing.T) {
+		rr := serveSigned(t, s, http.MethodGet, signed, "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "<script>alert(1)</script>", rr.Body.String())
+		assert.Equal(t, "application/octet-stream", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment", rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	})
+
+	t.Run("signature is bound to the method", func(t *testing.T) {
+		rr := serveSigned(t, s, http.MethodPut, signed+"&size=0", "")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("tampered key", func(t *testing.T) {
+		rr := serveSigned(t, s, http.MethodGet, strings.Replace(signed, "files/1/abc", "files/2/abc", 1), "")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired, err := s.SignedURL(ctx, "files/1/abc", -time.Minute)
+		require.NoError(t, err)
+		rr := serveSigned(t, s, http.MethodGet, expired, "")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestLocalStorage_SignedUploadURL(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost/storage", "secret", WithMaxObjectSize(4))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	signed, err := s.SignedUploadURL(ctx, "files/1/abc", "text/plain", 3, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Content-Type": "text/plain"}, signed.Headers)
+
+	t.Run("body of another size is refused", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serveSigned(t, s, http.MethodPut, signed.URL, "abcd").Code)
+		assert.Equal(t, http.StatusBadRequest, serveSigned(t, s, http.MethodPut, signed.URL, "ab").Code)
+		_, err := s.Get(ctx, "files/1/abc")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("size is part of the signature", func(t *testing.T) {
+		tampered := strings.Replace(signed.URL, "size=3", "size=4", 1)
+		assert.Equal(t, http.StatusForbidden, serveSigned(t, s, http.MethodPut, tampered, "abcd").Code)
+	})
+
+	t.Run("body of the signed size is stored", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serveSigned(t, s, http.MethodPut, signed.URL, "abc").Code)
+		rc, err := s.Get(ctx, "files/1/abc")
+		require.NoError(t, err)
+		defer rc.Close()
+		content, _ := io.ReadAll(rc)
+		assert.Equal(t, "abc", string(content))
+	})
+
+	t.Run("sizes over the max object size aren't signed", func(t *testing.T) {
+		_, err := s.SignedUploadURL(ctx, "files/1/abc", "text/plain", 5, time.Minute)
+		assert.Error(t, err)
+	})
+}
+
+// serveSigned sends a request for a signed URL to the storage's handler
+func serveSigned(t *testing.T, s *LocalStorage, method, rawURL, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, strings.TrimPrefix(u.Path, "/storage")+"?"+u.RawQuery, strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rr, req)
+	return rr
+}
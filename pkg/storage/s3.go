@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+// Server-side encryption modes for S3Config.ServerSideEncryption
+const (
+	SSENone = ""
+	SSES3   = "AES256"
+	SSEKMS  = "aws:kms"
+)
+
+// S3Config holds the settings for an S3-compatible backend (AWS S3, MinIO, ...)
+type S3Config struct {
+	// Endpoint is the S3 host, e.g. "s3.amazonaws.com" or "minio:9000"
+	Endpoint string
+	Region   string
+	Bucket   string
+	// AccessKeyID and SecretAccessKey are optional; when empty, credentials are
+	// read from the AWS environment variables, shared credentials file, or IAM role
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+	// PathStyle forces path-style addressing, which MinIO typically requires
+	PathStyle bool
+	// ServerSideEncryption is one of SSENone, SSES3, or SSEKMS
+	ServerSideEncryption string
+	KMSKeyID             string
+	// PartSize is the multipart upload part size in bytes. Uploads are
+	// streamed in parts of this size, so large files are never fully buffered.
+	PartSize uint64
+}
+
+// DefaultS3PartSize is the multipart part size used when S3Config.PartSize is
+// unset. Streamed uploads buffer one part in memory, so keep it modest.
+const DefaultS3PartSize = 16 << 20
+
+// S3Storage stores objects in an S3-compatible bucket
+type S3Storage struct {
+	client   *minio.Client
+	bucket   string
+	sse      encrypt.ServerSide
+	partSize uint64
+}
+
+// NewS3Storage creates an S3 storage backend
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("storage: S3 bucket is required")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "s3.amazonaws.com"
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = DefaultS3PartSize
+	}
+
+	sse, err := serverSideEncryption(cfg.ServerSideEncryption, cfg.KMSKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	if cfg.AccessKeyID == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		})
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to create S3 client: %w", err)
+	}
+
+	return &S3Storage{
+		client:   client,
+		bucket:   cfg.Bucket,
+		sse:      sse,
+		partSize: cfg.PartSize,
+	}, nil
+}
+
+// Put uploads the object, using multipart upload for anything larger than one part
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, -1, minio.PutObjectOptions{
+		ContentType:          contentType,
+		PartSize:             s.partSize,
+		ServerSideEncryption: s.sse,
+	})
+	return err
+}
+
+// Get opens the object. The object is stat'ed first so a missing key is
+// reported here rather than on the first read. SSE-S3 and SSE-KMS objects
+// are decrypted transparently.
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, mapS3Error(err)
+	}
+	return obj, nil
+}
+
+// Delete removes the object
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// SignedURL returns a pre-signed S3 download URL
+func (s *S3Storage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// SignedUploadURL returns a pre-signed S3 upload URL. Content-Length and
+// Content-Type are signed headers, so S3 refuses a body of another size, as
+// are the server-side encryption headers, so direct uploads are encrypted
+// like Put's.
+func (s *S3Storage) SignedUploadURL(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (*SignedUpload, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.FormatInt(size, 10))
+	if s.sse != nil {
+		s.sse.Marshal(header)
+	}
+
+	u, err := s.client.PresignHeader(ctx, http.MethodPut, s.bucket, key, expiry, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string, len(header))
+	for name := range header {
+		headers[name] = header.Get(name)
+	}
+	return &SignedUpload{URL: u.String(), Headers: headers}, nil
+}
+
+// serverSideEncryption builds the SSE option for the configured mode
+func serverSideEncryption(mode, kmsKeyID string) (encrypt.ServerSide, error) {
+	switch mode {
+	case SSENone:
+		return nil, nil
+	case SSES3:
+		return encrypt.NewSSE(), nil
+	case SSEKMS:
+		return encrypt.NewSSEKMS(kmsKeyID, nil)
+	default:
+		return nil, fmt.Errorf("storage: unsupported server-side encryption %q", mode)
+	}
+}
+
+// mapS3Error converts missing-object errors to ErrNotFound
+func mapS3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestS3Storage(t *testing.T, handler http.HandlerFunc) *S3Storage {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	s, err := NewS3Storage(S3Config{
+		Endpoint:        strings.TrimPrefix(server.URL, "http://"),
+		Region:          "us-east-1",
+		Bucket:          "uploads",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func TestNewS3Storage_Validation(t *testing.T) {
+	_, err := NewS3Storage(S3Config{})
+	assert.Error(t, err, "bucket is required")
+
+	_, err = NewS3Storage(S3Config{Bucket: "uploads", ServerSideEncryption: "rot13"})
+	assert.Error(t, err, "unknown encryption mode")
+
+	_, err = NewS3Storage(S3Config{Bucket: "uploads", ServerSideEncryption: SSEKMS, KMSKeyID: "key-id"})
+	assert.NoError(t, err)
+}
+
+func TestS3Storage_PutUsesMultipartUpload(t *testing.T) {
+	var gotSSE, gotContentType string
+	var parts int
+	completed := false
+	s := newTestS3Storage(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			gotSSE = r.Header.Get("X-Amz-Server-Side-Encryption")
+			gotContentType = r.Header.Get("Content-Type")
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>uploads</Bucket><Key>files/1/abc</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut && query.Get("uploadId") == "upload-1":
+			parts++
+			w.Header().Set("ETag", `"etag"`)
+		case r.Method == http.MethodPost && query.Get("uploadId") == "upload-1":
+			completed = true
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>uploads</Bucket><Key>files/1/abc</Key><ETag>"etag"</ETag></CompleteMultipartUploadResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	s.sse, _ = serverSideEncryption(SSES3, "")
+
+	err := s.Put(context.Background(), "files/1/abc", strings.NewReader("hello"), "text/plain")
+
+	require.NoError(t, err)
+	assert.Equal(t, SSES3, gotSSE)
+	assert.Equal(t, "text/plain", gotContentType)
+	assert.Equal(t, 1, parts)
+	assert.True(t, completed)
+}
+
+func TestS3Storage_GetMissingObject(t *testing.T) {
+	s := newTestS3Storage(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := s.Get(context.Background(), "files/1/missing")
+
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestS3Storage_SignedURL(t *testing.T) {
+	s := newTestS3Storage(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	signed, err := s.SignedURL(context.Background(), "files/1/abc", 15*time.Minute)
+
+	require.NoError(t, err)
+	assert.Contains(t, signed, "/uploads/files/1/abc?")
+	assert.Contains(t, signed, "X-Amz-Signature=")
+	assert.Contains(t, signed, "X-Amz-Expires=900")
+}
+
+func TestS3Storage_SignedUploadURL(t *testing.T) {
+	s := newTestS3Storage(t, func(w http.ResponseWriter, r *http.Request) {})
+	s.sse, _ = serverSideEncryption(SSES3, "")
+
+	signed, err := s.SignedUploadURL(context.Background(), "files/1/abc", "text/plain", 42, 15*time.Minute)
+
+	require.NoError(t, err)
+	assert.Contains(t, signed.URL, "/uploads/files/1/abc?")
+	assert.Contains(t, signed.URL, "X-Amz-SignedHeaders=content-length%3Bcontent-type%3Bhost%3Bx-amz-server-side-encryption")
+	assert.Equal(t, map[string]string{
+		"Content-Type":                 "text/plain",
+		"Content-Length":               "42",
+		"X-Amz-Server-Side-Encryption": SSES3,
+	}, signed.Headers)
+}
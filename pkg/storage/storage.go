@@ -0,0 +1,55 @@
+// Package storage provides a backend-agnostic object storage abstraction used
+// for user uploads, imports, and exports.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested object does not exist
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey is returned when a key is empty or escapes the storage root
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Storage stores and retrieves objects by key
+type Storage interface {
+	// Put stores the contents of r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get opens the object stored under key. Callers must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that allows a GET of key without further
+	// authentication until expiry elapses
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// SignedUploadURL returns a URL that allows a PUT of exactly size bytes
+	// of contentType to key without further authentication until expiry
+	// elapses. Uploads of any other size are refused.
+	SignedUploadURL(ctx context.Context, key, contentType string, size int64, expiry time.Duration) (*SignedUpload, error)
+}
+
+// SignedUpload is a pre-signed upload URL and the headers the client must
+// send with its PUT, as they are part of the signature
+type SignedUpload struct {
+	URL     string
+	Headers map[string]string
+}
+
+// validateKey rejects empty keys and keys that could escape the storage root
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
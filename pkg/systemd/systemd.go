@@ -0,0 +1,86 @@
+// Package systemd implements the parts of the sd_notify protocol a service
+// needs under systemd with Type=notify: reporting readiness and shutdown,
+// and pinging the watchdog so a hung process is restarted. Outside systemd
+// every call is a no-op.
+package systemd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// States sent with Notify
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Notify sends state to the service manager over $NOTIFY_SOCKET. It reports
+// false without error when the process is not run by systemd.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// A leading @ names a socket in the abstract namespace
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns how often systemd expects a watchdog ping
+// (WatchdogSec), or zero when the watchdog is off or meant for another
+// process
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid WATCHDOG_USEC " + strconv.Quote(usec))
+	}
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// RunWatchdog pings the watchdog at half its interval until ctx is done.
+// It returns at once when the watchdog is off.
+func RunWatchdog(ctx context.Context) error {
+	interval, err := WatchdogInterval()
+	if err != nil || interval == 0 {
+		return err
+	}
+
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := Notify(Watchdog); err != nil {
+				return err
+			}
+		}
+	}
+}
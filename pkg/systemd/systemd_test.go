@@ -0,0 +1,89 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listen stands in for systemd, returning the socket NOTIFY_SOCKET names
+func listen(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func receive(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	conn := listen(t)
+
+	sent, err := Notify(Ready)
+	require.NoError(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, Ready, receive(t, conn))
+}
+
+func TestNotify_OutsideSystemd(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := Notify(Ready)
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	interval, err := WatchdogInterval()
+	require.NoError(t, err)
+	assert.Zero(t, interval)
+
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	interval, err = WatchdogInterval()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+
+	t.Setenv("WATCHDOG_PID", "1")
+	interval, err = WatchdogInterval()
+	require.NoError(t, err)
+	assert.Zero(t, interval, "the watchdog is meant for another process")
+
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "soon")
+	_, err = WatchdogInterval()
+	assert.Error(t, err)
+}
+
+func TestRunWatchdog(t *testing.T) {
+	conn := listen(t)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- RunWatchdog(ctx) }()
+
+	assert.Equal(t, Watchdog, receive(t, conn))
+	assert.Equal(t, Watchdog, receive(t, conn))
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -26,20 +26,40 @@ func CheckPassword(password, hashedPassword string) error {
 type JWTClaims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
+	Role   string `json:"role,omitempty"`
+	// Tenant binds the token to the tenant that issued it in multi-tenant mode
+	Tenant string `json:"tenant,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // GenerateJWT generates a JWT token for a user
 func GenerateJWT(userID uint, email, secretKey string) (string, error) {
-	claims := JWTClaims{
+	return GenerateJWTWithRole(userID, email, "", secretKey)
+}
+
+// GenerateJWTWithRole generates a JWT token for a user carrying their role
+func GenerateJWTWithRole(userID uint, email, role, secretKey string) (string, error) {
+	return GenerateTenantJWT(userID, email, role, "", secretKey)
+}
+
+// GenerateTenantJWT generates a JWT token for a user of the given tenant;
+// an empty tenant issues a token for the shared database
+func GenerateTenantJWT(userID uint, email, role, tenant, secretKey string) (string, error) {
+	return GenerateClaimsJWT(JWTClaims{
 		UserID: userID,
 		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-	}
+		Role:   role,
+		Tenant: tenant,
+	}, secretKey)
+}
+
+// GenerateClaimsJWT signs claims, setting their expiry (24 hours), issue and
+// not-before times; the subject and other registered claims are kept
+func GenerateClaimsJWT(claims JWTClaims, secretKey string) (string, error) {
+	now := time.Now()
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(24 * time.Hour))
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secretKey))
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The auth hot path: registration hashes a password, login checks one and
+// signs a token, and every authenticated request validates a token. Run
+// with: go test -run='^$' -bench=. -benchmem ./pkg/utils
+//
+// Baselines are recorded in TESTING.md. bcrypt dominates and each step of
+// cost doubles it, which BenchmarkCheckPassword_Cost shows.
+
+// benchSecret signs the benchmarks' tokens
+const benchSecret = "benchmark-secret-key"
+
+func BenchmarkHashPassword(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := HashPassword("secret123"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheckPassword(b *testing.B) {
+	hashed, err := HashPassword("secret123")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := CheckPassword("secret123", hashed); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCheckPassword_Cost measures CheckPassword at bcrypt costs around
+// the default, to price a change of cost before making it
+func BenchmarkCheckPassword_Cost(b *testing.B) {
+	for _, cost := range []int{bcrypt.DefaultCost - 2, bcrypt.DefaultCost, bcrypt.DefaultCost + 2} {
+		hashed, err := bcrypt.GenerateFromPassword([]byte("secret123"), cost)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("cost=%d", cost), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := CheckPassword("secret123", string(hashed)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateJWT(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := GenerateJWTWithRole(1, "ada@example.com", "user", benchSecret); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateJWT(b *testing.B) {
+	token, err := GenerateJWTWithRole(1, "ada@example.com", "user", benchSecret)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ValidateJWT(token, benchSecret); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+)
+
+// ErrInvalidSignature is returned for received webhook requests that weren't
+// signed with the provider's secret, or were signed too long ago
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+// Provider describes how a service signs the webhooks it sends, and where
+// its requests carry the event type and delivery ID
+type Provider struct {
+	// Name is the provider's name in the receiving URL, /hooks/{name}
+	Name string
+	// Verify checks the request's signature over its raw body
+	Verify func(header http.Header, body []byte, secret string, tolerance time.Duration) error
+	// EventHeader names the header carrying the event type; when empty
+	// the payload's top-level EventField does
+	EventHeader string
+	EventField  string
+	// DeliveryHeader names the header carrying the delivery's unique ID;
+	// when empty the payload's top-level DeliveryField does
+	DeliveryHeader string
+	DeliveryField  string
+}
+
+// Providers whose signing schemes are known. Any other provider name is
+// assumed to sign its requests like this service's own deliveries.
+var (
+	GitHub = Provider{
+		Name:           "github",
+		Verify:         verifyGitHub,
+		EventHeader:    "X-GitHub-Event",
+		DeliveryHeader: "X-GitHub-Delivery",
+	}
+	Stripe = Provider{
+		Name:          "stripe",
+		Verify:        verifyStripe,
+		EventField:    "type",
+		DeliveryField: "id",
+	}
+)
+
+// LookupProvider returns the provider named name: GitHub, Stripe, or a
+// provider signing like Sign, with the X-Webhook-* headers
+func LookupProvider(name string) Provider {
+	switch name {
+	case GitHub.Name:
+		return GitHub
+	case Stripe.Name:
+		return Stripe
+	}
+	return Provider{
+		Name:           name,
+		Verify:         verifyStandard,
+		EventHeader:    HeaderEvent,
+		DeliveryHeader: HeaderDelivery,
+	}
+}
+
+// verifyGitHub checks the X-Hub-Signature-256 header, "sha256=<hex
+// HMAC-SHA256 of the body>". GitHub signs no timestamp, so tolerance
+// doesn't apply.
+func verifyGitHub(header http.Header, body []byte, secret string, _ time.Duration) error {
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), signaturePrefix)
+	if !ok {
+		return ErrInvalidSignature
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyStripe checks the Stripe-Signature header
+func verifyStripe(header http.Header, body []byte, secret string, tolerance time.Duration) error {
+	if err := billing.VerifySignature(body, header.Get(billing.SignatureHeader), secret, tolerance, time.Now()); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyStandard checks the X-Webhook-Signature and X-Webhook-Timestamp
+// headers set by this service's deliveries
+func verifyStandard(header http.Header, body []byte, secret string, tolerance time.Duration) error {
+	if err := Verify(secret, header.Get(HeaderSignature), header.Get(HeaderTimestamp), body, tolerance); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aungmyozaw92/go-api-setup/pkg/billing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviders_Verify(t *testing.T) {
+	secret := "s3cret"
+	body := []byte(`{"action":"opened"}`)
+	now := time.Now()
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	github := http.Header{"X-Hub-Signature-256": {"sha256=" + hex.EncodeToString(mac.Sum(nil))}}
+	stripe := http.Header{billing.SignatureHeader: {billing.SignPayload(body, secret, now)}}
+	standard := http.Header{
+		HeaderSignature: {Sign(secret, now, body)},
+		HeaderTimestamp: {strconv.FormatInt(now.Unix(), 10)},
+	}
+	staleStandard := http.Header{
+		HeaderSignature: {Sign(secret, now.Add(-time.Hour), body)},
+		HeaderTimestamp: {strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)},
+	}
+
+	tests := []struct {
+		name     string
+		provider Provider
+		header   http.Header
+		secret   string
+		wantErr  bool
+	}{
+		{"github", LookupProvider("github"), github, secret, false},
+		{"github with another secret", LookupProvider("github"), github, "other", true},
+		{"github without signature", LookupProvider("github"), http.Header{}, secret, true},
+		{"stripe", LookupProvider("stripe"), stripe, secret, false},
+		{"stripe signed like github", LookupProvider("stripe"), github, secret, true},
+		{"standard", LookupProvider("acme"), standard, secret, false},
+		{"standard signed too long ago", LookupProvider("acme"), staleStandard, secret, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.provider.Verify(tt.header, body, tt.secret, 5*time.Minute)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.Equal(t, "acme", LookupProvider("acme").Name)
+	assert.Equal(t, HeaderEvent, LookupProvider("acme").EventHeader)
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every webhook delivery
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// signaturePrefix identifies the signing scheme in the signature header
+const signaturePrefix = "sha256="
+
+// GenerateSecret generates a random signing secret for a subscription
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign computes the signature header value for a payload sent at timestamp.
+// The signed message is "<unix timestamp>.<body>" so receivers can reject replays.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value against the payload, rejecting
+// timestamps older than tolerance (zero disables the check)
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration) error {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return errors.New("unsupported signature scheme")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	sentAt := time.Unix(unix, 0)
+
+	if tolerance > 0 && time.Since(sentAt) > tolerance {
+		return errors.New("timestamp outside tolerance")
+	}
+
+	expected := Sign(secret, sentAt, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
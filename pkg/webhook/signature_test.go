@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	secret1, err := GenerateSecret()
+	require.NoError(t, err)
+	secret2, err := GenerateSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(secret1, "whsec_"))
+	assert.NotEqual(t, secret1, secret2)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"event":"user.created"}`)
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign(secret, now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		wantErr   bool
+	}{
+		{
+			name:      "valid signature",
+			secret:    secret,
+			signature: signature,
+			timestamp: timestamp,
+			body:      body,
+			wantErr:   false,
+		},
+		{
+			name:      "wrong secret",
+			secret:    "whsec_other",
+			signature: signature,
+			timestamp: timestamp,
+			body:      body,
+			wantErr:   true,
+		},
+		{
+			name:      "tampered body",
+			secret:    secret,
+			signature: signature,
+			timestamp: timestamp,
+			body:      []byte(`{"event":"user.deleted"}`),
+			wantErr:   true,
+		},
+		{
+			name:      "stale timestamp",
+			secret:    secret,
+			signature: Sign(secret, now.Add(-time.Hour), body),
+			timestamp: strconv.FormatInt(now.Add(-time.Hour).Unix(), 10),
+			body:      body,
+			wantErr:   true,
+		},
+		{
+			name:      "unsupported scheme",
+			secret:    secret,
+			signature: "md5=abc",
+			timestamp: timestamp,
+			body:      body,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.signature, tt.timestamp, tt.body, 5*time.Minute)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
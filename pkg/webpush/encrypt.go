@@ -0,0 +1,124 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// recordSize is the aes128gcm record size; payloads are sent as one record
+const recordSize = 4096
+
+// MaxPayloadSize is the largest payload Send accepts: one record less the
+// GCM tag and the padding delimiter
+const MaxPayloadSize = recordSize - 16 - 1
+
+// Encrypt encrypts payload for the subscription keys with a fresh key pair
+// and salt, returning the aes128gcm-encoded message body
+func Encrypt(keys *Keys, payload []byte) ([]byte, error) {
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	return encrypt(keys, payload, serverKey, salt)
+}
+
+// encrypt implements RFC 8291 with the given application server key pair and
+// salt
+func encrypt(keys *Keys, payload []byte, serverKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("webpush: payload is %d bytes, more than %d", len(payload), MaxPayloadSize)
+	}
+	uaKey, authSecret, err := keys.decode()
+	if err != nil {
+		return nil, err
+	}
+	uaPublic := uaKey.Bytes()
+	sharedSecret, err := serverKey.ECDH(uaKey)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: key agreement: %w", err)
+	}
+
+	// The input keying material mixes the shared secret with the auth
+	// secret and both public keys
+	serverPublic := serverKey.PublicKey().Bytes()
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, serverPublic...)
+	ikm, err := expand(hkdf.Extract(sha256.New, sharedSecret, authSecret), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	contentKey, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, key ID length and the server's public key
+	// as key ID; then the single record, ended by the last-record delimiter
+	body := make([]byte, 0, 16+4+1+len(serverPublic)+len(payload)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, recordSize)
+	body = append(body, byte(len(serverPublic)))
+	body = append(body, serverPublic...)
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
+
+// Validate checks that the keys are a P-256 public key and a 16-byte auth
+// secret, so messages can be encrypted for them
+func (k *Keys) Validate() error {
+	_, _, err := k.decode()
+	return err
+}
+
+// decode returns the browser's public key and the auth secret
+func (k *Keys) decode() (*ecdh.PublicKey, []byte, error) {
+	public, err := decodeBase64(k.P256dh)
+	if err != nil {
+		return nil, nil, errors.New("webpush: subscription p256dh key is not base64url")
+	}
+	key, err := ecdh.P256().NewPublicKey(public)
+	if err != nil {
+		return nil, nil, errors.New("webpush: subscription p256dh key is not a P-256 public key")
+	}
+	auth, err := decodeBase64(k.Auth)
+	if err != nil || len(auth) != 16 {
+		return nil, nil, errors.New("webpush: subscription auth secret must be 16 bytes, base64url-encoded")
+	}
+	return key, auth, nil
+}
+
+// expand reads length bytes of HKDF-Expand output
+func expand(prk, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
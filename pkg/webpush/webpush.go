@@ -0,0 +1,232 @@
+// Package webpush sends Web Push messages to browser push subscriptions.
+// Payloads are encrypted for the subscription as RFC 8291 describes and the
+// push service is told who is sending them with a VAPID token (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrGone is returned when the push service no longer knows the
+// subscription, because it expired or the user unsubscribed. The
+// subscription should be deleted.
+var ErrGone = errors.New("webpush: subscription has expired or was unsubscribed")
+
+// DefaultTTL is how long push services keep messages for offline browsers
+// when Config.TTL is not set
+const DefaultTTL = 24 * time.Hour
+
+// vapidTokenLifetime is how long VAPID tokens are valid; push services
+// reject tokens valid for more than 24 hours
+const vapidTokenLifetime = 12 * time.Hour
+
+// Subscription is a browser's push subscription, in the shape of
+// PushSubscription.toJSON()
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     Keys   `json:"keys"`
+}
+
+// Keys are the subscription's keys, base64url-encoded
+type Keys struct {
+	// P256dh is the browser's P-256 public key
+	P256dh string `json:"p256dh"`
+	// Auth is the 16-byte authentication secret
+	Auth string `json:"auth"`
+}
+
+// Config holds the settings for sending push messages
+type Config struct {
+	// VAPIDPublicKey and VAPIDPrivateKey are the application server's key
+	// pair, base64url-encoded as GenerateVAPIDKeys returns them. Browsers
+	// subscribe with the public key.
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	// Subject is a mailto: or https: URL push services can contact the
+	// sender at
+	Subject string
+	// TTL overrides DefaultTTL
+	TTL time.Duration
+	// HTTPClient overrides the client calling push services
+	HTTPClient *http.Client
+}
+
+// Sender sends push messages. Send is safe for concurrent use.
+type Sender struct {
+	key       *ecdsa.PrivateKey
+	publicKey string
+	subject   string
+	ttl       time.Duration
+	client    *http.Client
+}
+
+// NewSender creates a sender signing with the configured VAPID keys
+func NewSender(cfg Config) (*Sender, error) {
+	key, err := parseVAPIDKeys(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(cfg.Subject, "mailto:") && !strings.HasPrefix(cfg.Subject, "https://") {
+		return nil, errors.New("webpush: VAPID subject must be a mailto: or https: URL")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Sender{
+		key:       key,
+		publicKey: strings.TrimRight(cfg.VAPIDPublicKey, "="),
+		subject:   cfg.Subject,
+		ttl:       cfg.TTL,
+		client:    client,
+	}, nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (s *Sender) PublicKey() string {
+	return s.publicKey
+}
+
+// Send encrypts payload for the subscription and posts it to its push
+// service. It returns ErrGone when the subscription should be deleted and
+// an *APIError when the push service rejects the message.
+func (s *Sender) Send(ctx context.Context, sub *Subscription, payload []byte) error {
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return errors.New("webpush: invalid subscription endpoint")
+	}
+	body, err := Encrypt(&sub.Keys, payload)
+	if err != nil {
+		return err
+	}
+	token, err := s.vapidToken(endpoint.Scheme+"://"+endpoint.Host, time.Now().Add(vapidTokenLifetime))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(s.ttl.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+s.publicKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webpush: calling push service: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+}
+
+// vapidToken returns the VAPID JWT for push services at audience (the
+// endpoint's origin)
+func (s *Sender) vapidToken(audience string, expires time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": expires.Unix(),
+		"sub": s.subject,
+	})
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("webpush: signing VAPID token: %w", err)
+	}
+	return signed, nil
+}
+
+// APIError is an error response of a push service
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error describes the failure (implements error)
+func (e *APIError) Error() string {
+	return fmt.Sprintf("webpush: push service responded %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether sending again may succeed: the push service
+// failed or asked to slow down, rather than rejecting the message
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// GenerateVAPIDKeys returns a new VAPID key pair, base64url-encoded: the
+// uncompressed P-256 public key and the private scalar
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// parseVAPIDKeys decodes a key pair in the form GenerateVAPIDKeys returns,
+// checking that the public key belongs to the private key
+func parseVAPIDKeys(publicKey, privateKey string) (*ecdsa.PrivateKey, error) {
+	if publicKey == "" || privateKey == "" {
+		return nil, errors.New("webpush: VAPID public and private keys are required")
+	}
+	scalar, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, errors.New("webpush: VAPID private key is not base64url")
+	}
+	key, err := ecdh.P256().NewPrivateKey(scalar)
+	if err != nil {
+		return nil, errors.New("webpush: VAPID private key is not a P-256 private key")
+	}
+	public, err := decodeBase64(publicKey)
+	if err != nil {
+		return nil, errors.New("webpush: VAPID public key is not base64url")
+	}
+	point := key.PublicKey().Bytes()
+	if !bytes.Equal(public, point) {
+		return nil, errors.New("webpush: VAPID public key does not match the private key")
+	}
+
+	// The uncompressed point is 0x04 || X || Y
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(point[1:33]),
+			Y:     new(big.Int).SetBytes(point[33:]),
+		},
+		D: new(big.Int).SetBytes(scalar),
+	}, nil
+}
+
+// decodeBase64 decodes base64url with or without padding, which browsers
+// and key generators differ on
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,212 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/hkdf"
+)
+
+func b64(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// TestEncrypt_RFC8291 checks the example of RFC 8291 appendix A
+func TestEncrypt_RFC8291(t *testing.T) {
+	serverKey, err := ecdh.P256().NewPrivateKey(b64(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+	keys := &Keys{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+
+	body, err := encrypt(keys, []byte("When I grow up, I want to be a watermelon"), serverKey, b64(t, "DGv6ra1nlYgDCS1FRnbzlw"))
+	require.NoError(t, err)
+	assert.Equal(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(body))
+}
+
+func TestEncrypt_Errors(t *testing.T) {
+	browser := newBrowser(t)
+
+	_, err := Encrypt(&browser.keys, make([]byte, MaxPayloadSize+1))
+	assert.Error(t, err)
+	_, err = Encrypt(&Keys{P256dh: "bm90IGEga2V5", Auth: browser.keys.Auth}, []byte("hi"))
+	assert.Error(t, err)
+	_, err = Encrypt(&Keys{P256dh: browser.keys.P256dh, Auth: "c2hvcnQ"}, []byte("hi"))
+	assert.Error(t, err)
+
+	assert.NoError(t, browser.keys.Validate())
+	assert.Error(t, (&Keys{P256dh: browser.keys.P256dh}).Validate())
+}
+
+// browser is the receiving side of a subscription
+type browser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+	keys Keys
+}
+
+func newBrowser(t *testing.T) *browser {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &browser{key: key, auth: auth, keys: Keys{
+		P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:   base64.RawURLEncoding.EncodeToString(auth),
+	}}
+}
+
+// decrypt reverses Encrypt as a browser would
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	require.Greater(t, len(body), 86)
+	salt, idLen := body[:16], int(body[20])
+	serverPublic := body[21 : 21+idLen]
+	serverKey, err := ecdh.P256().NewPublicKey(serverPublic)
+	require.NoError(t, err)
+	shared, err := b.key.ECDH(serverKey)
+	require.NoError(t, err)
+
+	read := func(prk []byte, info string, n int) []byte {
+		out := make([]byte, n)
+		_, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte(info)), out)
+		require.NoError(t, err)
+		return out
+	}
+	ikm := read(hkdf.Extract(sha256.New, shared, b.auth), "WebPush: info\x00"+string(b.key.PublicKey().Bytes())+string(serverPublic), 32)
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	block, err := aes.NewCipher(read(prk, "Content-Encoding: aes128gcm\x00", 16))
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, read(prk, "Content-Encoding: nonce\x00", 12), body[21+idLen:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func newTestSender(t *testing.T, client *http.Client) *Sender {
+	t.Helper()
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	sender, err := NewSender(Config{
+		VAPIDPublicKey:  publicKey,
+		VAPIDPrivateKey: privateKey,
+		Subject:         "mailto:ops@example.com",
+		HTTPClient:      client,
+	})
+	require.NoError(t, err)
+	return sender
+}
+
+func TestSender_Send(t *testing.T) {
+	browser := newBrowser(t)
+	var received *http.Request
+	var body []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	sender := newTestSender(t, server.Client())
+
+	err := sender.Send(context.Background(), &Subscription{Endpoint: server.URL + "/push/abc", Keys: browser.keys}, []byte(`{"title":"Hello"}`))
+	require.NoError(t, err)
+
+	require.NotNil(t, received)
+	assert.Equal(t, "/push/abc", received.URL.Path)
+	assert.Equal(t, "aes128gcm", received.Header.Get("Content-Encoding"))
+	assert.Equal(t, "86400", received.Header.Get("TTL"))
+	assert.Equal(t, `{"title":"Hello"}`, string(browser.decrypt(t, body)))
+
+	// The VAPID token is signed with the key named by k, for the endpoint's origin
+	auth := received.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(auth, "vapid t="))
+	token, publicKey, ok := strings.Cut(strings.TrimPrefix(auth, "vapid t="), ", k=")
+	require.True(t, ok)
+	assert.Equal(t, sender.PublicKey(), publicKey)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return &sender.key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "mailto:ops@example.com", claims["sub"])
+}
+
+func TestSender_SendErrors(t *testing.T) {
+	browser := newBrowser(t)
+	status := http.StatusGone
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", status)
+	}))
+	defer server.Close()
+	sender := newTestSender(t, server.Client())
+	sub := &Subscription{Endpoint: server.URL, Keys: browser.keys}
+
+	assert.ErrorIs(t, sender.Send(context.Background(), sub, []byte("hi")), ErrGone)
+
+	status = http.StatusNotFound
+	assert.ErrorIs(t, sender.Send(context.Background(), sub, []byte("hi")), ErrGone)
+
+	status = http.StatusTooManyRequests
+	var apiErr *APIError
+	require.True(t, errors.As(sender.Send(context.Background(), sub, []byte("hi")), &apiErr))
+	assert.True(t, apiErr.Temporary())
+
+	status = http.StatusBadRequest
+	require.True(t, errors.As(sender.Send(context.Background(), sub, []byte("hi")), &apiErr))
+	assert.False(t, apiErr.Temporary())
+	assert.Equal(t, "nope", apiErr.Message)
+
+	assert.Error(t, sender.Send(context.Background(), &Subscription{Endpoint: "not a url", Keys: browser.keys}, []byte("hi")))
+}
+
+func TestNewSender_Validation(t *testing.T) {
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	otherPublic, _, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing keys", Config{Subject: "mailto:ops@example.com"}},
+		{"mismatched keys", Config{VAPIDPublicKey: otherPublic, VAPIDPrivateKey: privateKey, Subject: "mailto:ops@example.com"}},
+		{"bad private key", Config{VAPIDPublicKey: publicKey, VAPIDPrivateKey: "!!", Subject: "mailto:ops@example.com"}},
+		{"bad subject", Config{VAPIDPublicKey: publicKey, VAPIDPrivateKey: privateKey, Subject: "ops@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSender(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+
+	// Padded keys are accepted too
+	sender, err := NewSender(Config{VAPIDPublicKey: publicKey + "=", VAPIDPrivateKey: privateKey + "=", Subject: "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, sender.PublicKey())
+}
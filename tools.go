@@ -0,0 +1,8 @@
+//go:build tools
+
+// Package tools tracks build-time tool dependencies so `go mod tidy` keeps them.
+package tools
+
+import (
+	_ "github.com/99designs/gqlgen"
+)